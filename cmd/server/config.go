@@ -0,0 +1,443 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/mail"
+)
+
+type Config struct {
+	LoginThrottleBaseDelay  time.Duration `mapstructure:"LOGIN_THROTTLE_BASE_DELAY"`
+	LoginThrottleMaxDelay   time.Duration `mapstructure:"LOGIN_THROTTLE_MAX_DELAY"`
+	LoginThrottleResetAfter time.Duration `mapstructure:"LOGIN_THROTTLE_RESET_AFTER"`
+	LoginLockoutThreshold   int           `mapstructure:"LOGIN_LOCKOUT_THRESHOLD"`
+	LoginLockoutDuration    time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	SuspensionSweepInterval time.Duration `mapstructure:"SUSPENSION_SWEEP_INTERVAL"`
+
+	AccountDeletionGracePeriod   time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	AccountDeletionSweepInterval time.Duration `mapstructure:"ACCOUNT_DELETION_SWEEP_INTERVAL"`
+	DeletedAccountContent        string        `mapstructure:"DELETED_ACCOUNT_CONTENT"`
+
+	AvatarDir string `mapstructure:"AVATAR_DIR"`
+
+	EmailChangeTokenTTL       time.Duration `mapstructure:"EMAIL_CHANGE_TOKEN_TTL"`
+	UsernameChangeCooldown    time.Duration `mapstructure:"USERNAME_CHANGE_COOLDOWN"`
+	UsernameReservationPeriod time.Duration `mapstructure:"USERNAME_RESERVATION_PERIOD"`
+
+	PasswordHashCost            int    `mapstructure:"PASSWORD_HASH_COST"`
+	PasswordMinLength           int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMinCharacterClasses int    `mapstructure:"PASSWORD_MIN_CHARACTER_CLASSES"`
+	PasswordAllowPersonalInfo   bool   `mapstructure:"PASSWORD_ALLOW_PERSONAL_INFO"`
+	BreachedPasswordsFile       string `mapstructure:"BREACHED_PASSWORDS_FILE"`
+
+	BaseURL string `mapstructure:"BASE_URL"`
+
+	MailDriver   string `mapstructure:"MAIL_DRIVER"`
+	MailDir      string `mapstructure:"MAIL_DIR"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+
+	UnsubscribeSecret   string        `mapstructure:"UNSUBSCRIBE_SECRET"`
+	DigestSweepInterval time.Duration `mapstructure:"DIGEST_SWEEP_INTERVAL"`
+
+	NewsletterConfirmationTTL time.Duration `mapstructure:"NEWSLETTER_CONFIRMATION_TTL"`
+	NewsletterWebhookSecret   string        `mapstructure:"NEWSLETTER_WEBHOOK_SECRET"`
+	NewsletterSweepInterval   time.Duration `mapstructure:"NEWSLETTER_SWEEP_INTERVAL"`
+
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	OutboxBatchSize     int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxMaxAttempts   int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxRetryDelay    time.Duration `mapstructure:"OUTBOX_RETRY_DELAY"`
+
+	EventDispatcher string `mapstructure:"EVENT_DISPATCHER"`
+	EventWorkers    int    `mapstructure:"EVENT_WORKERS"`
+	EventQueueSize  int    `mapstructure:"EVENT_QUEUE_SIZE"`
+
+	EventMaxAttempts   int           `mapstructure:"EVENT_MAX_ATTEMPTS"`
+	EventRetryDelay    time.Duration `mapstructure:"EVENT_RETRY_DELAY"`
+	EventMaxRetryDelay time.Duration `mapstructure:"EVENT_MAX_RETRY_DELAY"`
+	EventRetryJitter   float64       `mapstructure:"EVENT_RETRY_JITTER"`
+	EventTracing       bool          `mapstructure:"EVENT_TRACING"`
+
+	SagaMaxAttempts   int           `mapstructure:"SAGA_MAX_ATTEMPTS"`
+	SagaTimeout       time.Duration `mapstructure:"SAGA_TIMEOUT"`
+	SagaSweepInterval time.Duration `mapstructure:"SAGA_SWEEP_INTERVAL"`
+	SagaResumeAfter   time.Duration `mapstructure:"SAGA_RESUME_AFTER"`
+
+	EventDisabledReactions string `mapstructure:"EVENT_DISABLED_REACTIONS"`
+	PopularPostLikes       int    `mapstructure:"POPULAR_POST_LIKES"`
+
+	ProjectionInterval  time.Duration `mapstructure:"PROJECTION_INTERVAL"`
+	ProjectionBatchSize int           `mapstructure:"PROJECTION_BATCH_SIZE"`
+
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+}
+
+// LoginThrottleConfig returns the login throttle settings, falling back to the
+// defaults for anything that isn't set
+func (c Config) LoginThrottleConfig() application.LoginThrottleConfig {
+	cfg := application.DefaultLoginThrottleConfig()
+	if c.LoginThrottleBaseDelay > 0 {
+		cfg.BaseDelay = c.LoginThrottleBaseDelay
+	}
+	if c.LoginThrottleMaxDelay > 0 {
+		cfg.MaxDelay = c.LoginThrottleMaxDelay
+	}
+	if c.LoginThrottleResetAfter > 0 {
+		cfg.ResetAfter = c.LoginThrottleResetAfter
+	}
+	if c.LoginLockoutThreshold > 0 {
+		cfg.LockoutThreshold = c.LoginLockoutThreshold
+	}
+	if c.LoginLockoutDuration > 0 {
+		cfg.LockoutDuration = c.LoginLockoutDuration
+	}
+	return cfg
+}
+
+// SuspensionSweep returns how often expired suspensions are lifted
+func (c Config) SuspensionSweep() time.Duration {
+	if c.SuspensionSweepInterval > 0 {
+		return c.SuspensionSweepInterval
+	}
+	return time.Minute
+}
+
+// AccountDeletionConfig returns the account deletion settings, falling back to the
+// defaults for anything that isn't set. DELETED_ACCOUNT_CONTENT must be "keep" or
+// "remove"
+func (c Config) AccountDeletionConfig() (application.AccountDeletionConfig, error) {
+	cfg := application.DefaultAccountDeletionConfig()
+	if c.AccountDeletionGracePeriod > 0 {
+		cfg.GracePeriod = c.AccountDeletionGracePeriod
+	}
+
+	switch c.DeletedAccountContent {
+	case "", "keep":
+		cfg.RemoveContent = false
+	case "remove":
+		cfg.RemoveContent = true
+	default:
+		return cfg, fmt.Errorf(
+			"DELETED_ACCOUNT_CONTENT must be keep or remove, got %q",
+			c.DeletedAccountContent,
+		)
+	}
+
+	return cfg, nil
+}
+
+// AccountDeletionSweep returns how often accounts past their grace period are anonymised
+func (c Config) AccountDeletionSweep() time.Duration {
+	if c.AccountDeletionSweepInterval > 0 {
+		return c.AccountDeletionSweepInterval
+	}
+	return time.Hour
+}
+
+// AvatarDirectory returns where uploaded avatars are kept
+func (c Config) AvatarDirectory() string {
+	if c.AvatarDir != "" {
+		return c.AvatarDir
+	}
+	return "uploads/avatars"
+}
+
+// AccountChangeConfig returns the email and username change settings, falling back to
+// the defaults for anything that isn't set
+func (c Config) AccountChangeConfig() application.AccountChangeConfig {
+	cfg := application.DefaultAccountChangeConfig()
+	if c.EmailChangeTokenTTL > 0 {
+		cfg.EmailConfirmationTTL = c.EmailChangeTokenTTL
+	}
+	if c.UsernameChangeCooldown > 0 {
+		cfg.UsernameCooldown = c.UsernameChangeCooldown
+	}
+	if c.UsernameReservationPeriod > 0 {
+		cfg.UsernameReservation = c.UsernameReservationPeriod
+	}
+	return cfg
+}
+
+// PasswordCost returns the bcrypt cost new password hashes are made with. Hashes made
+// at any other cost are rehashed when their user next logs in
+func (c Config) PasswordCost() int {
+	if c.PasswordHashCost > 0 {
+		return c.PasswordHashCost
+	}
+	return 14
+}
+
+// PasswordPolicy returns the rules new passwords must follow, falling back to the
+// defaults for anything that isn't set
+func (c Config) PasswordPolicy() domain.PasswordPolicy {
+	policy := domain.DefaultPasswordPolicy()
+	if c.PasswordMinLength > 0 {
+		policy.MinLength = c.PasswordMinLength
+	}
+	if c.PasswordMinCharacterClasses > 0 {
+		policy.MinCharacterClasses = c.PasswordMinCharacterClasses
+	}
+	policy.AllowPersonalInfo = c.PasswordAllowPersonalInfo
+	return policy
+}
+
+// Mailer returns how emails are delivered. MAIL_DRIVER must be "file", which writes
+// them to MAIL_DIR, or "smtp"
+func (c Config) Mailer() (mail.Mailer, error) {
+	from := c.MailFrom
+	if from == "" {
+		from = "Blog <noreply@localhost>"
+	}
+
+	switch c.MailDriver {
+	case "", "file":
+		dir := c.MailDir
+		if dir == "" {
+			dir = "mail"
+		}
+		return mail.NewFileMailer(dir, from)
+	case "smtp":
+		if c.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST must be set when MAIL_DRIVER is smtp")
+		}
+		port := c.SMTPPort
+		if port == 0 {
+			port = 587
+		}
+		return mail.NewSMTPMailer(c.SMTPHost, port, c.SMTPUsername, c.SMTPPassword, from)
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER must be file or smtp, got %q", c.MailDriver)
+	}
+}
+
+// UnsubscribeKey returns the key that signs unsubscribe links in digests and
+// newsletters. Without UNSUBSCRIBE_SECRET a random key is used, so unsubscribe links
+// stop working when the server restarts
+func (c Config) UnsubscribeKey() ([]byte, error) {
+	if c.UnsubscribeSecret != "" {
+		return []byte(c.UnsubscribeSecret), nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DigestConfig returns the email digest settings, signing unsubscribe links with the key
+func (c Config) DigestConfig(unsubscribeKey []byte) application.DigestConfig {
+	return application.DigestConfig{
+		BaseURL:           c.baseURL(),
+		UnsubscribeSecret: unsubscribeKey,
+	}
+}
+
+// DigestSweep returns how often the background scheduler sends digests that are due
+func (c Config) DigestSweep() time.Duration {
+	if c.DigestSweepInterval > 0 {
+		return c.DigestSweepInterval
+	}
+	return 15 * time.Minute
+}
+
+// NewsletterConfig returns the newsletter settings, signing unsubscribe links with the
+// key
+func (c Config) NewsletterConfig(unsubscribeKey []byte) application.NewsletterConfig {
+	cfg := application.NewsletterConfig{
+		BaseURL:           c.baseURL(),
+		UnsubscribeSecret: unsubscribeKey,
+		ConfirmationTTL:   c.NewsletterConfirmationTTL,
+		WebhookSecret:     c.NewsletterWebhookSecret,
+	}
+	if cfg.ConfirmationTTL <= 0 {
+		cfg.ConfirmationTTL = 48 * time.Hour
+	}
+	return cfg
+}
+
+// NewsletterSweep returns how often the background scheduler sends queued newsletter
+// emails
+func (c Config) NewsletterSweep() time.Duration {
+	if c.NewsletterSweepInterval > 0 {
+		return c.NewsletterSweepInterval
+	}
+	return time.Minute
+}
+
+// OutboxRelayConfig returns how the outbox relay publishes events, falling back to the
+// defaults for anything that isn't set
+func (c Config) OutboxRelayConfig() ddd.OutboxRelayConfig {
+	cfg := ddd.DefaultOutboxRelayConfig()
+	if c.OutboxBatchSize > 0 {
+		cfg.BatchSize = c.OutboxBatchSize
+	}
+	if c.OutboxMaxAttempts > 0 {
+		cfg.MaxAttempts = c.OutboxMaxAttempts
+	}
+	if c.OutboxRetryDelay > 0 {
+		cfg.RetryDelay = c.OutboxRetryDelay
+	}
+	return cfg
+}
+
+// OutboxRelay returns how often the background scheduler publishes events waiting in
+// the outbox
+func (c Config) OutboxRelay() time.Duration {
+	if c.OutboxRelayInterval > 0 {
+		return c.OutboxRelayInterval
+	}
+	return time.Second
+}
+
+// AsyncEvents reports whether event handlers run in the background, on the workers of
+// the async dispatcher, along with its settings. EVENT_DISPATCHER must be "sync", which
+// runs handlers in the outbox relay's job, or "async", with which events still queued
+// when the process stops are lost
+func (c Config) AsyncEvents() (dddmemory.AsyncEventDispatcherConfig, bool, error) {
+	switch c.EventDispatcher {
+	case "", "sync":
+		return dddmemory.AsyncEventDispatcherConfig{}, false, nil
+	case "async":
+		cfg := dddmemory.DefaultAsyncEventDispatcherConfig()
+		if c.EventWorkers > 0 {
+			cfg.Workers = c.EventWorkers
+		}
+		if c.EventQueueSize > 0 {
+			cfg.QueueSize = c.EventQueueSize
+		}
+		return cfg, true, nil
+	default:
+		return dddmemory.AsyncEventDispatcherConfig{}, false, fmt.Errorf(
+			"EVENT_DISPATCHER must be sync or async, got %q",
+			c.EventDispatcher,
+		)
+	}
+}
+
+// EventRetryPolicy returns how event handlers are retried before their events are
+// dead-lettered, falling back to the defaults for anything that isn't set. Handlers
+// subscribed with a policy of their own don't use it
+func (c Config) EventRetryPolicy() ddd.RetryPolicy {
+	policy := ddd.DefaultRetryPolicy()
+	if c.EventMaxAttempts > 0 {
+		policy.MaxAttempts = c.EventMaxAttempts
+	}
+	if c.EventRetryDelay > 0 {
+		policy.InitialDelay = c.EventRetryDelay
+	}
+	if c.EventMaxRetryDelay > 0 {
+		policy.MaxDelay = c.EventMaxRetryDelay
+	}
+	if c.EventRetryJitter > 0 {
+		policy.Jitter = c.EventRetryJitter
+	}
+	return policy
+}
+
+// PostArchivalConfig returns how hard the saga run when a post is archived tries before
+// restoring the post's comments, falling back to the defaults for anything that isn't
+// set
+func (c Config) PostArchivalConfig() application.PostArchivalConfig {
+	cfg := application.PostArchivalConfig{
+		MaxAttempts: c.SagaMaxAttempts,
+		Timeout:     c.SagaTimeout,
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Minute
+	}
+	return cfg
+}
+
+// SagaSweep returns how often the background scheduler compensates sagas that timed out
+// and carries on those that were interrupted
+func (c Config) SagaSweep() time.Duration {
+	if c.SagaSweepInterval > 0 {
+		return c.SagaSweepInterval
+	}
+	return time.Minute
+}
+
+// SagaResume returns how long an unfinished saga has to have been left alone before the
+// background scheduler carries it on, so it doesn't race the handler still running it
+func (c Config) SagaResume() time.Duration {
+	if c.SagaResumeAfter > 0 {
+		return c.SagaResumeAfter
+	}
+	return time.Minute
+}
+
+// EventReactionsConfig returns the reactions to events the event coordinator runs,
+// falling back to the defaults for anything that isn't set. EVENT_DISABLED_REACTIONS is
+// a comma separated list of the reactions to turn off
+func (c Config) EventReactionsConfig() (application.EventReactionsConfig, error) {
+	cfg := application.DefaultEventReactionsConfig()
+	if c.PopularPostLikes > 0 {
+		cfg.PopularPostLikes = c.PopularPostLikes
+	}
+
+	for _, reaction := range strings.Split(c.EventDisabledReactions, ",") {
+		reaction = strings.TrimSpace(reaction)
+		if reaction == "" {
+			continue
+		}
+		if !slices.Contains(application.EventReactionNames(), reaction) {
+			return cfg, fmt.Errorf(
+				"EVENT_DISABLED_REACTIONS must list reactions from %s, got %q",
+				strings.Join(application.EventReactionNames(), ", "),
+				reaction,
+			)
+		}
+		cfg.Disabled = append(cfg.Disabled, reaction)
+	}
+
+	return cfg, nil
+}
+
+// Projections returns how often the background scheduler brings the read models up to
+// date with the events stored since they last ran
+func (c Config) Projections() time.Duration {
+	if c.ProjectionInterval > 0 {
+		return c.ProjectionInterval
+	}
+	return time.Second
+}
+
+// ProjectionBatch returns how many events the read models are given at a time
+func (c Config) ProjectionBatch() int {
+	if c.ProjectionBatchSize > 0 {
+		return c.ProjectionBatchSize
+	}
+	return 500
+}
+
+// Shutdown returns how long the server waits for requests and queued events to finish
+// when it's stopped
+func (c Config) Shutdown() time.Duration {
+	if c.ShutdownTimeout > 0 {
+		return c.ShutdownTimeout
+	}
+	return 30 * time.Second
+}
+
+func (c Config) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return "http://localhost:8080"
+}
@@ -1,29 +1,68 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"blog/pkg/clock"
+	"blog/pkg/config"
+	"blog/pkg/ddd"
 	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/ddd/services"
+	"blog/pkg/scheduler"
 
 	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/infrastructure/avatars"
 	"blog/internal/infrastructure/events"
+	"blog/internal/infrastructure/notifications"
+	"blog/internal/infrastructure/passwords"
 	"blog/internal/infrastructure/persistence/sqlite"
 	httphandler "blog/internal/interfaces/http"
+
+	"go.uber.org/zap"
 )
 
 func main() {
-	eventDispatcher := dddmemory.NewInMemoryEventDispatcher(nil)
+	cfg, err := config.New[Config](".env")
+	if err != nil {
+		panic(err)
+	}
 
-	commentEventHandler := events.NewCommentEventHandler()
-	postEventHandler := events.NewPostEventHandler()
-	ratingEventHandler := events.NewRatingEventHandler()
-	userEventHandler := events.NewUserEventHandler()
+	deletionConfig, err := cfg.AccountDeletionConfig()
+	if err != nil {
+		panic(err)
+	}
 
-	commentEventHandler.Register(eventDispatcher)
-	postEventHandler.Register(eventDispatcher)
-	ratingEventHandler.Register(eventDispatcher)
-	userEventHandler.Register(eventDispatcher)
+	unsubscribeKey, err := cfg.UnsubscribeKey()
+	if err != nil {
+		panic(err)
+	}
+	if cfg.UnsubscribeSecret == "" {
+		log.Println("UNSUBSCRIBE_SECRET is not set, unsubscribe links will break on restart")
+	}
+
+	mailer, err := cfg.Mailer()
+	if err != nil {
+		panic(err)
+	}
+
+	eventReactionsConfig, err := cfg.EventReactionsConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Sync()
 
 	db, err := sqlite.NewDB()
 	if err != nil {
@@ -34,25 +73,339 @@ func main() {
 		panic("failed db ping")
 	}
 
+	// By default event handlers run in the outbox relay's background job. The async
+	// dispatcher hands them to its workers instead, after which the relay marks the event
+	// published, so events still queued when the process stops are lost. Either way a
+	// failing handler is retried by its retry policy, and the event is then dead-lettered
+	// for an admin to replay or discard
+	asyncEventsConfig, asyncEvents, err := cfg.AsyncEvents()
+	if err != nil {
+		panic(err)
+	}
+
+	var baseDispatcher ddd.EventDispatcher = dddmemory.NewInMemoryEventDispatcher(logger.Sugar())
+	drainEvents := func(ctx context.Context) error { return nil }
+	if asyncEvents {
+		asyncDispatcher := dddmemory.NewAsyncEventDispatcher(logger.Sugar(), asyncEventsConfig)
+		baseDispatcher = asyncDispatcher
+		drainEvents = asyncDispatcher.Shutdown
+	}
+
+	deadLetterStore := sqlite.NewDeadLetterStore(db.DB)
+	retryingDispatcher := ddd.NewRetryingEventDispatcher(
+		baseDispatcher,
+		deadLetterStore,
+		cfg.EventRetryPolicy(),
+	)
+
+	// Every event and each run of its handlers is logged and counted, and traced when
+	// tracing is on. Handler middlewares wrap each attempt, inside the retries
+	eventMetrics := dddmemory.NewInMemoryEventMetrics()
+	eventMiddlewares := []ddd.EventMiddleware{
+		ddd.LoggingMiddleware(logger.Sugar()),
+		ddd.MetricsMiddleware(eventMetrics),
+		ddd.RecoveryMiddleware(),
+		ddd.ValidationMiddleware(),
+	}
+	if cfg.EventTracing {
+		eventMiddlewares = append(
+			[]ddd.EventMiddleware{ddd.TracingMiddleware(ddd.NewLogTracer(logger.Sugar()))},
+			eventMiddlewares...,
+		)
+	}
+	eventDispatcher := ddd.NewMiddlewareEventDispatcher(retryingDispatcher, eventMiddlewares...)
+
 	commentRepo := sqlite.NewCommentRepository(db.DB)
 	postRepo := sqlite.NewPostRepository(db.DB)
 	ratingRepo := sqlite.NewRatingRepository(db.DB)
 	userRepo := sqlite.NewUserRepository(db.DB)
+	roleRepo := sqlite.NewRoleRepository(db.DB)
+	sessionRepo := sqlite.NewSessionRepository(db.DB)
+	impersonationRepo := sqlite.NewImpersonationRepository(db.DB)
+	followRepo := sqlite.NewFollowRepository(db.DB)
+	bookmarkRepo := sqlite.NewBookmarkRepository(db.DB)
+	bookmarkFolderRepo := sqlite.NewBookmarkFolderRepository(db.DB)
+	notificationRepo := sqlite.NewNotificationRepository(db.DB)
+	digestSubscriptionRepo := sqlite.NewDigestSubscriptionRepository(db.DB)
+	newsletterRepo := sqlite.NewNewsletterRepository(db.DB)
+	loginThrottleStore := sqlite.NewLoginThrottleStore(db.DB)
+	outboxStore := sqlite.NewOutboxStore(db.DB)
+	postSummaryStore := sqlite.NewPostSummaryStore(db.DB)
+
+	avatarStore, err := avatars.NewFileStore(cfg.AvatarDirectory())
+	if err != nil {
+		panic(err)
+	}
+
+	passwordHasher, err := passwords.NewBcryptHasher(cfg.PasswordCost())
+	if err != nil {
+		panic(err)
+	}
+
+	// Checking against breached passwords is optional, as the list is large
+	var breachedPasswords domain.BreachedPasswordList
+	if cfg.BreachedPasswordsFile != "" {
+		breachFile, err := passwords.OpenBreachFile(cfg.BreachedPasswordsFile)
+		if err != nil {
+			panic(err)
+		}
+		defer breachFile.Close()
+
+		breachedPasswords = breachFile
+	}
+
+	sessionStore := sqlite.NewSessionStore(db.DB, 5*time.Minute)
+	defer sessionStore.StopCleanup()
+
+	authorizer := application.NewAuthorizer(userRepo, roleRepo, domain.DefaultPolicy())
 
 	commentService := application.NewCommentService(
 		commentRepo,
 		userRepo,
 		postRepo,
-		eventDispatcher,
+		authorizer,
+	)
+	postService := application.NewPostService(postRepo, userRepo, postSummaryStore, authorizer)
+	ratingService := application.NewRatingService(
+		ratingRepo,
+		userRepo,
+		postRepo,
+		authorizer,
+	)
+	loginThrottle := application.NewLoginThrottle(
+		loginThrottleStore,
+		clock.New(),
+		cfg.LoginThrottleConfig(),
+	)
+	userService := application.NewUserService(
+		userRepo,
+		roleRepo,
+		sessionRepo,
+		postRepo,
+		commentRepo,
+		ratingRepo,
+		followRepo,
+		bookmarkRepo,
+		bookmarkFolderRepo,
+		notificationRepo,
+		digestSubscriptionRepo,
+		avatarStore,
+		loginThrottle,
+		application.NewPasswords(passwordHasher, cfg.PasswordPolicy(), breachedPasswords),
+		deletionConfig,
+		cfg.AccountChangeConfig(),
+		notifications.NewLogNotifier(),
+	)
+	roleService := application.NewRoleService(roleRepo)
+	sessionService := application.NewSessionService(sessionRepo, userRepo)
+	impersonationService := application.NewImpersonationService(
+		impersonationRepo,
+		userRepo,
+		authorizer,
+	)
+	followService := application.NewFollowService(followRepo, userRepo)
+	feedService := application.NewFanOutOnReadFeedService(followRepo, postRepo)
+	bookmarkService := application.NewBookmarkService(
+		bookmarkRepo,
+		bookmarkFolderRepo,
+		postRepo,
+	)
+
+	notificationService := application.NewNotificationService(
+		notificationRepo,
+		postRepo,
+		commentRepo,
 	)
-	postService := application.NewPostService(postRepo, userRepo, eventDispatcher)
-	ratingService := application.NewRatingService(ratingRepo, userRepo, postRepo, eventDispatcher)
-	userService := application.NewUserService(userRepo, eventDispatcher)
 
-	router := httphandler.NewRouter(postService, userService, commentService, ratingService)
+	digestService := application.NewDigestService(
+		digestSubscriptionRepo,
+		userRepo,
+		postRepo,
+		commentRepo,
+		ratingRepo,
+		followRepo,
+		mailer,
+		cfg.DigestConfig(unsubscribeKey),
+	)
 
-	log.Println("Starting server on :8080...")
-	if err := http.ListenAndServe(":8080", router); err != nil {
-		panic(err)
+	newsletterService := application.NewNewsletterService(
+		newsletterRepo,
+		userRepo,
+		postRepo,
+		mailer,
+		cfg.NewsletterConfig(unsubscribeKey),
+	)
+
+	// Reactions that only apply to some events of a type, or whose sagas raise events of
+	// their own, run in the event coordinator, which is subscribed along with the handlers
+	eventCoordinator := services.NewEventCoordinator()
+	application.NewEventReactions(
+		postRepo,
+		userRepo,
+		ratingRepo,
+		notificationService,
+		eventReactionsConfig,
+	).Register(eventCoordinator)
+
+	deadLetterService := application.NewDeadLetterService(deadLetterStore, retryingDispatcher)
+	eventService := application.NewEventService(eventMetrics, retryingDispatcher, eventCoordinator)
+
+	// Comments and ratings notify users, so their handlers need the notification service.
+	// New posts go out to newsletter subscribers
+	commentEventHandler := events.NewCommentEventHandler(notificationService)
+	postEventHandler := events.NewPostEventHandler(newsletterService)
+	ratingEventHandler := events.NewRatingEventHandler(notificationService)
+	userEventHandler := events.NewUserEventHandler()
+	roleEventHandler := events.NewRoleEventHandler()
+	impersonationEventHandler := events.NewImpersonationEventHandler()
+	followEventHandler := events.NewFollowEventHandler()
+	bookmarkEventHandler := events.NewBookmarkEventHandler()
+	notificationEventHandler := events.NewNotificationEventHandler()
+	newsletterEventHandler := events.NewNewsletterEventHandler()
+
+	commentEventHandler.Register(eventDispatcher)
+	postEventHandler.Register(eventDispatcher)
+	ratingEventHandler.Register(eventDispatcher)
+	userEventHandler.Register(eventDispatcher)
+	roleEventHandler.Register(eventDispatcher)
+	impersonationEventHandler.Register(eventDispatcher)
+	followEventHandler.Register(eventDispatcher)
+	bookmarkEventHandler.Register(eventDispatcher)
+	notificationEventHandler.Register(eventDispatcher)
+	newsletterEventHandler.Register(eventDispatcher)
+	eventCoordinator.Subscribe(eventDispatcher)
+
+	// Archiving a post archives its comments and notifies their commenters, in a saga
+	// kept in the database so a restart carries it on. Sagas interrupted by the last
+	// shutdown are carried on before any new events are relayed, and after that those
+	// left alone for SAGA_RESUME_AFTER, so the handlers running them aren't raced
+	sagaManager := services.NewSagaManager(sqlite.NewSagaStore(db.DB), eventDispatcher)
+	sagaManager.Register(application.NewPostArchivalSaga(
+		postRepo,
+		commentRepo,
+		notificationService,
+		cfg.PostArchivalConfig(),
+	))
+	sagaManager.Subscribe(eventDispatcher)
+
+	if resumed, err := sagaManager.Resume(context.Background(), time.Now()); err != nil {
+		log.Printf("Failed to carry on every interrupted saga: %v", err)
+	} else if resumed > 0 {
+		log.Printf("Carried on %d interrupted sagas", resumed)
+	}
+
+	// The repositories write events to the outbox along with their changes, and the relay
+	// publishes them to the handlers above
+	outboxRelay := ddd.NewOutboxRelay(outboxStore, eventDispatcher, cfg.OutboxRelayConfig())
+
+	// The read models are projected from the outbox, which keeps every event once it's
+	// published, so they can be rebuilt from it
+	projector := ddd.NewProjector(
+		outboxStore,
+		sqlite.NewCheckpointStore(db.DB),
+		cfg.ProjectionBatch(),
+	)
+	projector.Register(application.NewPostSummaryProjection(postSummaryStore, postRepo, userRepo))
+	projectionService := application.NewProjectionService(projector)
+
+	jobs := scheduler.New()
+	jobs.Every("relay-outbox", cfg.OutboxRelay(), func(ctx context.Context) error {
+		_, err := outboxRelay.Relay(ctx, time.Now())
+		return err
+	})
+	jobs.Every("run-sagas", cfg.SagaSweep(), func(ctx context.Context) error {
+		timedOut, err := sagaManager.FireTimeouts(ctx, time.Now())
+		if timedOut > 0 {
+			log.Printf("Compensating %d timed out sagas", timedOut)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = sagaManager.Resume(ctx, time.Now().Add(-cfg.SagaResume()))
+		return err
+	})
+	jobs.Every("run-projections", cfg.Projections(), func(ctx context.Context) error {
+		_, err := projector.Run(ctx, time.Now())
+		return err
+	})
+	jobs.Every("lift-expired-suspensions", cfg.SuspensionSweep(), func(ctx context.Context) error {
+		lifted, err := userService.LiftExpiredSuspensions(ctx, time.Now())
+		if lifted > 0 {
+			log.Printf("Lifted %d expired suspensions", lifted)
+		}
+		return err
+	})
+	jobs.Every("anonymise-deleted-accounts", cfg.AccountDeletionSweep(), func(ctx context.Context) error {
+		anonymised, err := userService.AnonymiseDueAccounts(ctx, time.Now())
+		if anonymised > 0 {
+			log.Printf("Anonymised %d deleted accounts", anonymised)
+		}
+		return err
+	})
+	jobs.Every("send-email-digests", cfg.DigestSweep(), func(ctx context.Context) error {
+		sent, err := digestService.SendDueDigests(time.Now())
+		if sent > 0 {
+			log.Printf("Sent %d email digests", sent)
+		}
+		return err
+	})
+	jobs.Every("send-newsletters", cfg.NewsletterSweep(), func(ctx context.Context) error {
+		sent, err := newsletterService.SendQueued(time.Now())
+		if sent > 0 {
+			log.Printf("Sent %d newsletter emails", sent)
+		}
+		return err
+	})
+	jobs.Start()
+
+	router := httphandler.NewRouter(
+		postService,
+		userService,
+		commentService,
+		ratingService,
+		sessionService,
+		roleService,
+		impersonationService,
+		followService,
+		feedService,
+		bookmarkService,
+		notificationService,
+		digestService,
+		newsletterService,
+		deadLetterService,
+		eventService,
+		projectionService,
+		authorizer,
+		sessionStore,
+	)
+
+	server := &http.Server{
+		Addr:    ":8080",
+		Handler: router,
+	}
+
+	go func() {
+		log.Println("Starting server on :8080...")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			panic(err)
+		}
+	}()
+
+	stopped, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-stopped.Done()
+
+	// Stop taking requests, then stop the jobs so the relay dispatches no more events,
+	// then let the dispatcher finish the events it has queued
+	log.Println("Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown())
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Failed to stop the server cleanly: %v", err)
+	}
+	jobs.Stop()
+	if err := drainEvents(ctx); err != nil {
+		log.Printf("Failed to handle every queued event: %v", err)
 	}
 }
@@ -3,20 +3,20 @@ module blog
 go 1.24.5
 
 require (
+	github.com/alexedwards/scs/v2 v2.9.0
+	github.com/jmoiron/sqlx v1.4.0
 	github.com/joho/godotenv v1.5.1
+	github.com/mattn/go-sqlite3 v1.14.32
 	github.com/spf13/viper v1.20.1
 	go.uber.org/zap v1.27.0
 )
 
 require (
 	github.com/Masterminds/squirrel v1.5.4 // indirect
-	github.com/alexedwards/scs/v2 v2.9.0 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
-	github.com/jmoiron/sqlx v1.4.0 // indirect
 	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
 	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
-	github.com/mattn/go-sqlite3 v1.14.32 // indirect
 )
 
 require (
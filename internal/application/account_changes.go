@@ -0,0 +1,244 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+// AccountNotifier sends users the emails that go with changes to their account
+type AccountNotifier interface {
+	// SendEmailChangeConfirmation asks the new address to confirm the change with the token
+	SendEmailChangeConfirmation(username, newEmail, token string, expiresAt time.Time) error
+	// SendEmailChangedNotice tells the old address that the account has moved away from it
+	SendEmailChangedNotice(username, oldEmail, newEmail string) error
+}
+
+// AccountChangeConfig controls how users change their email and username
+type AccountChangeConfig struct {
+	// EmailConfirmationTTL is how long the new address has to confirm an email change
+	EmailConfirmationTTL time.Duration
+	// UsernameCooldown is how long a user must wait between username changes
+	UsernameCooldown time.Duration
+	// UsernameReservation is how long an old username stays reserved, and its profile
+	// URL redirects, after it is changed
+	UsernameReservation time.Duration
+}
+
+// DefaultAccountChangeConfig gives a day to confirm an email change, allows a username
+// change every 30 days and reserves old usernames for 90 days
+func DefaultAccountChangeConfig() AccountChangeConfig {
+	return AccountChangeConfig{
+		EmailConfirmationTTL: 24 * time.Hour,
+		UsernameCooldown:     30 * 24 * time.Hour,
+		UsernameReservation:  90 * 24 * time.Hour,
+	}
+}
+
+// RequestEmailChange sends a confirmation token to the new address. The email doesn't
+// change until the token is confirmed. The password is checked again, as whoever
+// controls the email can reset the account
+func (s *UserService) RequestEmailChange(
+	ctx context.Context, userID, password, newEmail string,
+) error {
+	domainUserID := domain.NewUserID(userID)
+
+	user, err := s.userRepo.FindByID(domainUserID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwords.Verify(user.PasswordHash(), password); err != nil {
+		return err
+	}
+
+	if newEmail != user.Email() {
+		if exists, err := s.userRepo.EmailExists(newEmail); exists || err != nil {
+			if err != nil {
+				return err
+			}
+			return domain.ErrEmailTaken
+		}
+	}
+
+	token, err := newEmailChangeToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.changeConfig.EmailConfirmationTTL)
+	if err := user.RequestEmailChange(newEmail, hashEmailChangeToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdatePendingEmailChange(user); err != nil {
+		return err
+	}
+
+	if err := s.notifier.SendEmailChangeConfirmation(
+		user.Username(),
+		newEmail,
+		token,
+		expiresAt,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange moves the account to the address the token was sent to, and lets
+// the old address know
+func (s *UserService) ConfirmEmailChange(ctx context.Context, token string) error {
+	tokenHash := hashEmailChangeToken(token)
+
+	user, err := s.userRepo.FindByEmailChangeToken(tokenHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidEmailChangeToken
+		}
+		return err
+	}
+
+	oldEmail := user.Email()
+	now := time.Now()
+
+	if err := user.ConfirmEmailChange(tokenHash, now); err != nil {
+		return err
+	}
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.ChangeEmail(user, domain.IdentityChange{
+		UserID:    user.GetID(),
+		Kind:      domain.IdentityKindEmail,
+		OldValue:  oldEmail,
+		NewValue:  user.Email(),
+		ChangedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	if err := s.notifier.SendEmailChangedNotice(user.Username(), oldEmail, user.Email()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ChangeUsername renames the user. The old username stays reserved, with its profile
+// URL redirecting to the new one, for the reservation period
+func (s *UserService) ChangeUsername(
+	ctx context.Context, userID, newUsername string,
+) (*UserDTO, error) {
+	domainUserID := domain.NewUserID(userID)
+
+	user, err := s.userRepo.FindByID(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if newUsername != user.Username() {
+		if exists, err := s.userRepo.UsernameExists(newUsername); exists || err != nil {
+			if err != nil {
+				return nil, err
+			}
+			return nil, domain.ErrUsernameTaken
+		}
+	}
+
+	oldUsername := user.Username()
+	now := time.Now()
+
+	if err := user.ChangeUsername(newUsername, now, s.changeConfig.UsernameCooldown); err != nil {
+		return nil, err
+	}
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	reservedUntil := now.Add(s.changeConfig.UsernameReservation)
+	if err := s.userRepo.ChangeUsername(user, domain.IdentityChange{
+		UserID:        domainUserID,
+		Kind:          domain.IdentityKindUsername,
+		OldValue:      oldUsername,
+		NewValue:      newUsername,
+		ChangedAt:     now,
+		ReservedUntil: &reservedUntil,
+	}); err != nil {
+		return nil, err
+	}
+
+	// The throttle is keyed by username, so the old one's failures go with it
+	if err := s.loginThrottle.Reset(oldUsername); err != nil {
+		return nil, err
+	}
+
+	userDTO := UserDTO{}
+	userDTO.FromDomain(user)
+	return &userDTO, nil
+}
+
+// NextUsernameChange returns when the user may next change their username, or nil if
+// they may change it now
+func (s *UserService) NextUsernameChange(userID string) (*time.Time, error) {
+	user, err := s.userRepo.FindByID(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return user.NextUsernameChange(s.changeConfig.UsernameCooldown), nil
+}
+
+// GetIdentityHistory returns the user's past email and username changes, oldest first
+func (s *UserService) GetIdentityHistory(userID string) ([]IdentityChangeDTO, error) {
+	changes, err := s.userRepo.FindIdentityHistory(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	changeDTOs := []IdentityChangeDTO{}
+	for i := range changes {
+		changeDTO := IdentityChangeDTO{}
+		changeDTO.FromDomain(&changes[i])
+		changeDTOs = append(changeDTOs, changeDTO)
+	}
+
+	return changeDTOs, nil
+}
+
+// ResolveRenamedUsername returns the current username of the user who changed away from
+// the given one, while it is still reserved
+func (s *UserService) ResolveRenamedUsername(oldUsername string) (string, error) {
+	user, err := s.userRepo.FindByReservedUsername(oldUsername, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if user.IsDeactivated() {
+		return "", domain.ErrUserNotFound
+	}
+
+	return user.Username(), nil
+}
+
+func newEmailChangeToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// hashEmailChangeToken hashes a token for storage. The token is random, so a plain
+// SHA-256 is enough to stop a database leak from exposing usable tokens
+func hashEmailChangeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
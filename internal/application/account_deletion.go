@@ -0,0 +1,269 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+// AccountDeletionConfig controls what happens when a user deletes their account
+type AccountDeletionConfig struct {
+	// GracePeriod is how long the account stays deactivated, and can be restored by
+	// logging in, before it is anonymised
+	GracePeriod time.Duration
+	// RemoveContent deletes the user's posts, comments and ratings when the account is
+	// anonymised. Otherwise they are kept under the anonymised username
+	RemoveContent bool
+}
+
+// DefaultAccountDeletionConfig gives a 30 day grace period and keeps the user's content
+func DefaultAccountDeletionConfig() AccountDeletionConfig {
+	return AccountDeletionConfig{
+		GracePeriod:   30 * 24 * time.Hour,
+		RemoveContent: false,
+	}
+}
+
+// UserDataExport holds everything stored about a user, for them to download
+type UserDataExport struct {
+	Profile         UserDTO
+	History         []IdentityChangeDTO
+	Posts           []PostDTO
+	Comments        []CommentDTO
+	Ratings         []RatingDTO
+	Bookmarks       []BookmarkDTO
+	BookmarkFolders []BookmarkFolderDTO
+}
+
+// ExportUserData gathers the user's profile and everything they've written or rated
+func (s *UserService) ExportUserData(userID string) (*UserDataExport, error) {
+	domainUserID := domain.NewUserID(userID)
+
+	user, err := s.userRepo.FindByID(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.GetIdentityHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.FindByAuthor(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.FindByUser(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := s.ratingRepo.FindByUser(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks, err := s.bookmarkRepo.FindByUser(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := s.folderRepo.FindByUser(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := UserDataExport{
+		History:         history,
+		Posts:           []PostDTO{},
+		Comments:        []CommentDTO{},
+		Ratings:         []RatingDTO{},
+		Bookmarks:       []BookmarkDTO{},
+		BookmarkFolders: []BookmarkFolderDTO{},
+	}
+
+	export.Profile.FromDomain(user)
+	// The hash is a credential rather than the user's data, so it stays behind
+	export.Profile.PasswordHash = ""
+
+	for i := range posts {
+		postDTO := PostDTO{}
+		postDTO.FromDomain(&posts[i])
+		export.Posts = append(export.Posts, postDTO)
+	}
+
+	for i := range comments {
+		commentDTO := CommentDTO{}
+		commentDTO.FromDomain(&comments[i])
+		export.Comments = append(export.Comments, commentDTO)
+	}
+
+	for i := range ratings {
+		ratingDTO := RatingDTO{}
+		ratingDTO.FromDomain(&ratings[i])
+		export.Ratings = append(export.Ratings, ratingDTO)
+	}
+
+	for i := range bookmarks {
+		bookmarkDTO, err := newBookmarkDTO(s.postRepo, &bookmarks[i])
+		if err != nil {
+			return nil, err
+		}
+		export.Bookmarks = append(export.Bookmarks, *bookmarkDTO)
+	}
+
+	for i := range folders {
+		folderDTO := BookmarkFolderDTO{}
+		folderDTO.FromDomain(&folders[i])
+		export.BookmarkFolders = append(export.BookmarkFolders, folderDTO)
+	}
+
+	return &export, nil
+}
+
+// RequestAccountDeletion deactivates the user's account and ends their sessions. The
+// account is anonymised once the grace period is over, unless the user logs back in
+// first. The password is checked again, as the request can't be undone after that
+func (s *UserService) RequestAccountDeletion(
+	ctx context.Context, userID, password string,
+) (*UserDTO, error) {
+	domainUserID := domain.NewUserID(userID)
+
+	user, err := s.userRepo.FindByID(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.passwords.Verify(user.PasswordHash(), password); err != nil {
+		return nil, err
+	}
+
+	if err := user.RequestDeletion(s.deletionConfig.GracePeriod); err != nil {
+		return nil, err
+	}
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdateDeletion(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionRepo.RevokeAllForUser(domainUserID); err != nil {
+		return nil, err
+	}
+
+	userDTO := UserDTO{}
+	userDTO.FromDomain(user)
+	return &userDTO, nil
+}
+
+// AnonymiseDueAccounts anonymises every account whose deletion grace period ended before
+// the given time, returning how many were anonymised
+func (s *UserService) AnonymiseDueAccounts(ctx context.Context, at time.Time) (int, error) {
+	users, err := s.userRepo.FindDueForAnonymisation(at)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range users {
+		if err := s.anonymise(ctx, &users[i], at); err != nil {
+			return i, err
+		}
+	}
+
+	return len(users), nil
+}
+
+func (s *UserService) anonymise(ctx context.Context, user *domain.User, at time.Time) error {
+	previousUsername := user.Username()
+
+	username, err := s.anonymousUsername()
+	if err != nil {
+		return err
+	}
+
+	if err := user.Anonymise(username, at, s.deletionConfig.RemoveContent); err != nil {
+		return err
+	}
+
+	if s.deletionConfig.RemoveContent {
+		if err := s.postRepo.DeleteByAuthor(user.GetID()); err != nil {
+			return err
+		}
+
+		if err := s.commentRepo.DeleteByUser(user.GetID()); err != nil {
+			return err
+		}
+
+		if err := s.ratingRepo.DeleteByUser(user.GetID()); err != nil {
+			return err
+		}
+	}
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.Anonymise(user); err != nil {
+		return err
+	}
+
+	// Who the user followed, and who followed them, goes with the account, as do
+	// their reading list, notifications and digest subscription
+	if err := s.followRepo.DeleteByUser(user.GetID()); err != nil {
+		return err
+	}
+
+	if err := s.bookmarkRepo.DeleteByUser(user.GetID()); err != nil {
+		return err
+	}
+
+	if err := s.folderRepo.DeleteByUser(user.GetID()); err != nil {
+		return err
+	}
+
+	if err := s.notificationRepo.DeleteByUser(user.GetID()); err != nil {
+		return err
+	}
+
+	if err := s.digestRepo.DeleteByUser(user.GetID()); err != nil {
+		return err
+	}
+
+	if err := s.avatarStore.Delete(user.GetID()); err != nil {
+		return err
+	}
+
+	// Don't leave the old username behind in the throttle
+	if err := s.loginThrottle.Reset(previousUsername); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// anonymousUsername picks an unused "deleted-user-xxxx" username
+func (s *UserService) anonymousUsername() (string, error) {
+	for range 5 {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+
+		username := "deleted-user-" + hex.EncodeToString(suffix)
+
+		exists, err := s.userRepo.UsernameExists(username)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return username, nil
+		}
+	}
+
+	return "", errors.New("couldn't find an unused anonymous username")
+}
@@ -0,0 +1,57 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"blog/internal/domain"
+)
+
+// Authorizer checks actions against the authorization policy on behalf of a user
+type Authorizer struct {
+	userRepo domain.UserRepository
+	roleRepo domain.RoleRepository
+	policy   *domain.Policy
+}
+
+func NewAuthorizer(
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	policy *domain.Policy,
+) *Authorizer {
+	return &Authorizer{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+		policy:   policy,
+	}
+}
+
+// Authorize returns an error wrapping domain.ErrForbidden unless the user may perform
+// the action on the resource. resource may be nil for actions that don't target an
+// existing aggregate. Suspended and banned users are denied everything
+func (a *Authorizer) Authorize(
+	actorID string,
+	action domain.Action,
+	resource domain.OwnedResource,
+) error {
+	user, err := a.userRepo.FindByID(domain.NewUserID(actorID))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return fmt.Errorf("%w: unknown user", domain.ErrForbidden)
+		}
+		return err
+	}
+
+	if err := user.CheckStanding(time.Now()); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrForbidden, err)
+	}
+
+	// Resolve the permissions granted by the user's roles
+	roles, err := a.roleRepo.FindByNames(user.UserRoles())
+	if err != nil {
+		return err
+	}
+
+	return a.policy.Authorize(user.Actor(roles), action, resource)
+}
@@ -0,0 +1,309 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+// BookmarkService manages users' reading lists. Bookmarks and folders are private, so
+// another user's are reported as not found rather than forbidden
+type BookmarkService struct {
+	bookmarkRepo domain.BookmarkRepository
+	folderRepo   domain.BookmarkFolderRepository
+	postRepo     domain.PostRepository
+}
+
+func NewBookmarkService(
+	bookmarkRepo domain.BookmarkRepository,
+	folderRepo domain.BookmarkFolderRepository,
+	postRepo domain.PostRepository,
+) *BookmarkService {
+	return &BookmarkService{
+		bookmarkRepo: bookmarkRepo,
+		folderRepo:   folderRepo,
+		postRepo:     postRepo,
+	}
+}
+
+// GetBookmarks lists the user's bookmarks newest first, only those in the folder if
+// folderID isn't empty
+func (s *BookmarkService) GetBookmarks(userID, folderID string) ([]BookmarkDTO, error) {
+	var bookmarks []domain.Bookmark
+	var err error
+	if folderID == "" {
+		bookmarks, err = s.bookmarkRepo.FindByUser(domain.NewUserID(userID))
+	} else {
+		var folder *domain.BookmarkFolder
+		folder, err = s.findFolder(userID, folderID)
+		if err == nil {
+			bookmarks, err = s.bookmarkRepo.FindByFolder(folder.GetID())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarkDTOs := []BookmarkDTO{}
+	for i := range bookmarks {
+		bookmarkDTO, err := newBookmarkDTO(s.postRepo, &bookmarks[i])
+		if err != nil {
+			return nil, err
+		}
+		bookmarkDTOs = append(bookmarkDTOs, *bookmarkDTO)
+	}
+
+	return bookmarkDTOs, nil
+}
+
+func (s *BookmarkService) GetBookmark(userID, bookmarkID string) (*BookmarkDTO, error) {
+	bookmark, err := s.findBookmark(userID, bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBookmarkDTO(s.postRepo, bookmark)
+}
+
+// CreateBookmark saves the post for the user, in the folder unless folderID is empty.
+// Only published posts can be bookmarked
+func (s *BookmarkService) CreateBookmark(
+	ctx context.Context,
+	userID, postID, folderID, note string,
+) (*BookmarkDTO, error) {
+	post, err := s.postRepo.FindByID(domain.NewPostID(postID))
+	if err != nil {
+		return nil, err
+	}
+	if post.Archived() {
+		return nil, domain.ErrPostNotFound
+	}
+
+	if folderID != "" {
+		if _, err := s.findFolder(userID, folderID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create the bookmark
+	bookmark, err := domain.NewBookmark(
+		domain.NewUserID(userID),
+		post.GetID(),
+		domain.NewBookmarkFolderID(folderID),
+		note,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist
+	bookmark.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if _, err := s.bookmarkRepo.Create(bookmark); err != nil {
+		return nil, err
+	}
+
+	return newBookmarkDTO(s.postRepo, bookmark)
+}
+
+// UpdateBookmark replaces the bookmark's folder and note. An empty folderID unfiles it
+func (s *BookmarkService) UpdateBookmark(
+	ctx context.Context,
+	userID, bookmarkID, folderID, note string,
+) (*BookmarkDTO, error) {
+	bookmark, err := s.findBookmark(userID, bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+
+	domainFolderID := domain.NewBookmarkFolderID(folderID)
+	if domainFolderID != bookmark.FolderID() {
+		if folderID != "" {
+			if _, err := s.findFolder(userID, folderID); err != nil {
+				return nil, err
+			}
+		}
+
+		bookmark.MoveToFolder(domainFolderID)
+
+		// Persist
+		bookmark.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := s.bookmarkRepo.UpdateFolder(bookmark); err != nil {
+			return nil, err
+		}
+	}
+
+	if note != bookmark.Note() {
+		if err := bookmark.UpdateNote(note); err != nil {
+			return nil, err
+		}
+
+		// Persist
+		bookmark.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := s.bookmarkRepo.UpdateNote(bookmark); err != nil {
+			return nil, err
+		}
+	}
+
+	return newBookmarkDTO(s.postRepo, bookmark)
+}
+
+func (s *BookmarkService) RemoveBookmark(ctx context.Context, userID, bookmarkID string) error {
+	bookmark, err := s.findBookmark(userID, bookmarkID)
+	if err != nil {
+		return err
+	}
+
+	bookmark.Remove()
+
+	// Persist
+	bookmark.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.bookmarkRepo.RemoveBookmark(bookmark); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetFolders lists the user's bookmark folders by name
+func (s *BookmarkService) GetFolders(userID string) ([]BookmarkFolderDTO, error) {
+	folders, err := s.folderRepo.FindByUser(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	folderDTOs := []BookmarkFolderDTO{}
+	for i := range folders {
+		folderDTO := BookmarkFolderDTO{}
+		folderDTO.FromDomain(&folders[i])
+		folderDTOs = append(folderDTOs, folderDTO)
+	}
+
+	return folderDTOs, nil
+}
+
+func (s *BookmarkService) CreateFolder(
+	ctx context.Context, userID, name string,
+) (*BookmarkFolderDTO, error) {
+	// Create the folder
+	folder, err := domain.NewBookmarkFolder(domain.NewUserID(userID), name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist
+	folder.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if _, err := s.folderRepo.Create(folder); err != nil {
+		return nil, err
+	}
+
+	folderDTO := BookmarkFolderDTO{}
+	folderDTO.FromDomain(folder)
+
+	return &folderDTO, nil
+}
+
+func (s *BookmarkService) RenameFolder(
+	ctx context.Context,
+	userID, folderID, name string,
+) (*BookmarkFolderDTO, error) {
+	folder, err := s.findFolder(userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := folder.Rename(name); err != nil {
+		return nil, err
+	}
+
+	// Persist
+	folder.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.folderRepo.Rename(folder); err != nil {
+		return nil, err
+	}
+
+	folderDTO := BookmarkFolderDTO{}
+	folderDTO.FromDomain(folder)
+
+	return &folderDTO, nil
+}
+
+// DeleteFolder deletes the folder, leaving its bookmarks unfiled
+func (s *BookmarkService) DeleteFolder(ctx context.Context, userID, folderID string) error {
+	folder, err := s.findFolder(userID, folderID)
+	if err != nil {
+		return err
+	}
+
+	bookmarks, err := s.bookmarkRepo.FindByFolder(folder.GetID())
+	if err != nil {
+		return err
+	}
+
+	for i := range bookmarks {
+		bookmarks[i].MoveToFolder("")
+
+		// Persist
+		bookmarks[i].SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := s.bookmarkRepo.UpdateFolder(&bookmarks[i]); err != nil {
+			return err
+		}
+	}
+
+	folder.Delete()
+
+	// Persist
+	folder.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.folderRepo.Delete(folder); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// findBookmark returns the user's bookmark, or ErrBookmarkNotFound if it belongs to
+// someone else
+func (s *BookmarkService) findBookmark(userID, bookmarkID string) (*domain.Bookmark, error) {
+	bookmark, err := s.bookmarkRepo.FindByID(domain.NewBookmarkID(bookmarkID))
+	if err != nil {
+		return nil, err
+	}
+	if bookmark.OwnerID() != domain.NewUserID(userID) {
+		return nil, domain.ErrBookmarkNotFound
+	}
+	return bookmark, nil
+}
+
+// findFolder returns the user's folder, or ErrBookmarkFolderNotFound if it belongs to
+// someone else
+func (s *BookmarkService) findFolder(userID, folderID string) (*domain.BookmarkFolder, error) {
+	folder, err := s.folderRepo.FindByID(domain.NewBookmarkFolderID(folderID))
+	if err != nil {
+		return nil, err
+	}
+	if folder.OwnerID() != domain.NewUserID(userID) {
+		return nil, domain.ErrBookmarkFolderNotFound
+	}
+	return folder, nil
+}
+
+// newBookmarkDTO includes the bookmarked post while it's still published
+func newBookmarkDTO(postRepo domain.PostRepository, bookmark *domain.Bookmark) (*BookmarkDTO, error) {
+	bookmarkDTO := BookmarkDTO{}
+	bookmarkDTO.FromDomain(bookmark)
+
+	post, err := postRepo.FindByID(bookmark.PostID())
+	if err != nil && !errors.Is(err, domain.ErrPostNotFound) {
+		return nil, err
+	}
+
+	if post != nil && !post.Archived() {
+		postDTO := PostDTO{}
+		postDTO.FromDomain(post)
+		bookmarkDTO.Available = true
+		bookmarkDTO.Post = &postDTO
+	}
+
+	return &bookmarkDTO, nil
+}
@@ -0,0 +1,100 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+func newTestBookmarkService(t *testing.T) (*BookmarkService, *memory.PostRepository) {
+	t.Helper()
+
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	postRepo := memory.NewPostRepository(outbox)
+	service := NewBookmarkService(
+		memory.NewBookmarkRepository(outbox),
+		memory.NewBookmarkFolderRepository(outbox),
+		postRepo,
+	)
+	return service, postRepo
+}
+
+func newTestPost(t *testing.T, postRepo *memory.PostRepository) *domain.Post {
+	t.Helper()
+
+	post, err := domain.NewPost("bob", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	postRepo.Create(post)
+	return post
+}
+
+func TestBookmarkOfArchivedPostIsUnavailable(t *testing.T) {
+	service, postRepo := newTestBookmarkService(t)
+	post := newTestPost(t, postRepo)
+
+	bookmark, err := service.CreateBookmark(context.Background(), "alice", post.GetID().String(), "", "")
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+	if !bookmark.Available || bookmark.Post == nil {
+		t.Fatalf("CreateBookmark() = %#v, want the post available", bookmark)
+	}
+
+	post.Archive()
+	postRepo.Archive(post)
+
+	bookmarks, err := service.GetBookmarks("alice", "")
+	if err != nil {
+		t.Fatalf("GetBookmarks() error = %v", err)
+	}
+	if len(bookmarks) != 1 {
+		t.Fatalf("GetBookmarks() returned %d bookmarks, want the archived one kept", len(bookmarks))
+	}
+	if bookmarks[0].Available || bookmarks[0].Post != nil {
+		t.Errorf("GetBookmarks() = %#v, want it marked unavailable", bookmarks[0])
+	}
+
+	if _, err := service.CreateBookmark(context.Background(), "carol", post.GetID().String(), "", ""); !errors.Is(err, domain.ErrPostNotFound) {
+		t.Errorf("CreateBookmark() of an archived post error = %v, want ErrPostNotFound", err)
+	}
+}
+
+func TestDeleteBookmarkFolderUnfilesBookmarks(t *testing.T) {
+	service, postRepo := newTestBookmarkService(t)
+	post := newTestPost(t, postRepo)
+
+	folder, err := service.CreateFolder(context.Background(), "alice", "Later")
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+
+	if _, err := service.CreateBookmark(context.Background(), "carol", post.GetID().String(), folder.ID, ""); !errors.Is(err, domain.ErrBookmarkFolderNotFound) {
+		t.Errorf("CreateBookmark() in another user's folder error = %v, want ErrBookmarkFolderNotFound", err)
+	}
+
+	bookmark, err := service.CreateBookmark(context.Background(), "alice", post.GetID().String(), folder.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+
+	if err := service.DeleteFolder(context.Background(), "carol", folder.ID); !errors.Is(err, domain.ErrBookmarkFolderNotFound) {
+		t.Errorf("DeleteFolder() by another user error = %v, want ErrBookmarkFolderNotFound", err)
+	}
+	if err := service.DeleteFolder(context.Background(), "alice", folder.ID); err != nil {
+		t.Fatalf("DeleteFolder() error = %v", err)
+	}
+
+	got, err := service.GetBookmark("alice", bookmark.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark() error = %v", err)
+	}
+	if got.FolderID != "" {
+		t.Errorf("GetBookmark() folder = %q, want the bookmark unfiled", got.FolderID)
+	}
+}
@@ -1,31 +1,31 @@
 package application
 
 import (
+	"context"
 	"errors"
-	"log"
 
 	"blog/internal/domain"
 	"blog/pkg/ddd"
 )
 
 type CommentService struct {
-	commentRepo     domain.CommentRepository
-	userRepo        domain.UserRepository
-	postRepo        domain.PostRepository
-	eventDispatcher ddd.EventDispatcher
+	commentRepo domain.CommentRepository
+	userRepo    domain.UserRepository
+	postRepo    domain.PostRepository
+	authorizer  *Authorizer
 }
 
 func NewCommentService(
 	commentRepo domain.CommentRepository,
 	userRepo domain.UserRepository,
 	postRepo domain.PostRepository,
-	eventDispatcher ddd.EventDispatcher,
+	authorizer *Authorizer,
 ) *CommentService {
 	return &CommentService{
-		commentRepo:     commentRepo,
-		userRepo:        userRepo,
-		postRepo:        postRepo,
-		eventDispatcher: eventDispatcher,
+		commentRepo: commentRepo,
+		userRepo:    userRepo,
+		postRepo:    postRepo,
+		authorizer:  authorizer,
 	}
 }
 
@@ -78,6 +78,7 @@ func (s *CommentService) GetCommentsByPost(postID string) ([]*CommentDTO, error)
 }
 
 func (s *CommentService) CreateComment(
+	ctx context.Context,
 	postID string,
 	commenterID string,
 	content string,
@@ -101,6 +102,11 @@ func (s *CommentService) CreateComment(
 		return nil, errors.New("user does not exist")
 	}
 
+	// Check that the user may comment
+	if err := s.authorizer.Authorize(commenterID, domain.ActionCreateComment, nil); err != nil {
+		return nil, err
+	}
+
 	// Create the comment
 	comment, err := domain.NewComment(domainPostID, domainCommenterID, content)
 	if err != nil {
@@ -108,15 +114,11 @@ func (s *CommentService) CreateComment(
 	}
 
 	// Persist
+	comment.SetEventMetadata(ddd.EventMetadataFrom(ctx))
 	if _, err := s.commentRepo.Create(comment); err != nil {
 		return nil, err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(comment); err != nil {
-		return nil, err
-	}
-
 	commentDTO := CommentDTO{}
 	commentDTO.FromDomain(comment)
 
@@ -124,6 +126,8 @@ func (s *CommentService) CreateComment(
 }
 
 func (s *CommentService) EditComment(
+	ctx context.Context,
+	actorID string,
 	commentID string,
 	content string,
 ) error {
@@ -143,17 +147,17 @@ func (s *CommentService) EditComment(
 		return err
 	}
 
-	if err := comment.Edit(content); err != nil {
+	if err := s.authorizer.Authorize(actorID, domain.ActionEditComment, comment); err != nil {
 		return err
 	}
 
-	// Persist
-	if err := s.commentRepo.UpdateContent(domainCommentID, content); err != nil {
+	if err := comment.Edit(content); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(comment); err != nil {
+	// Persist
+	comment.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.commentRepo.UpdateContent(comment); err != nil {
 		return err
 	}
 
@@ -161,6 +165,8 @@ func (s *CommentService) EditComment(
 }
 
 func (s *CommentService) ArchiveComment(
+	ctx context.Context,
+	actorID string,
 	commentID string,
 ) error {
 	domainCommentID := domain.NewCommentID(commentID)
@@ -178,29 +184,18 @@ func (s *CommentService) ArchiveComment(
 	if err != nil {
 		return err
 	}
-	comment.Archive()
 
-	// Persist
-	if err := s.commentRepo.Archive(domainCommentID); err != nil {
+	if err := s.authorizer.Authorize(actorID, domain.ActionArchiveComment, comment); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(comment); err != nil {
+	comment.Archive()
+
+	// Persist
+	comment.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.commentRepo.Archive(comment); err != nil {
 		return err
 	}
 
 	return nil
 }
-
-// Helper method to dispatch events for any aggregate with AggregateBase
-func (s *CommentService) dispatchAggregateEvents(aggregate ddd.EventAggregate) error {
-	events := aggregate.GetUncommittedEvents()
-	for _, event := range events {
-		if err := s.eventDispatcher.Dispatch(event); err != nil {
-			log.Printf("Failed to dispatch event: %v", err)
-		}
-	}
-	aggregate.MarkEventsAsCommitted()
-	return nil
-}
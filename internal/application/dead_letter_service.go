@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+// DeadLetterService lets admins deal with the events that handlers gave up on, by
+// replaying them to the handler that failed or discarding them
+type DeadLetterService struct {
+	deadLetters ddd.DeadLetterStore
+	dispatcher  *ddd.RetryingEventDispatcher
+}
+
+func NewDeadLetterService(
+	deadLetters ddd.DeadLetterStore,
+	dispatcher *ddd.RetryingEventDispatcher,
+) *DeadLetterService {
+	return &DeadLetterService{
+		deadLetters: deadLetters,
+		dispatcher:  dispatcher,
+	}
+}
+
+// GetDeadLetters returns the dead letters with the status, or all of them if the status
+// is empty
+func (s *DeadLetterService) GetDeadLetters(status string) ([]DeadLetterDTO, error) {
+	var deadLetterStatus ddd.DeadLetterStatus
+	if status != "" {
+		parsed, err := ddd.ParseDeadLetterStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		deadLetterStatus = parsed
+	}
+
+	letters, err := s.deadLetters.List(deadLetterStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	letterDTOs := []DeadLetterDTO{}
+	for _, letter := range letters {
+		letterDTO := DeadLetterDTO{}
+		letterDTO.FromDeadLetter(letter)
+		letterDTOs = append(letterDTOs, letterDTO)
+	}
+
+	return letterDTOs, nil
+}
+
+func (s *DeadLetterService) GetDeadLetter(id int64) (*DeadLetterDTO, error) {
+	letter, err := s.deadLetters.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	letterDTO := DeadLetterDTO{}
+	letterDTO.FromDeadLetter(letter)
+
+	return &letterDTO, nil
+}
+
+// ReplayDeadLetter runs the event through the handler that gave up on it once more. If
+// the handler fails again the dead letter stays pending and ddd.ErrReplayFailed is
+// returned
+func (s *DeadLetterService) ReplayDeadLetter(ctx context.Context, id int64) (*DeadLetterDTO, error) {
+	letter, err := s.dispatcher.Replay(ctx, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	letterDTO := DeadLetterDTO{}
+	letterDTO.FromDeadLetter(letter)
+
+	return &letterDTO, nil
+}
+
+// DiscardDeadLetter gives up on the event for good
+func (s *DeadLetterService) DiscardDeadLetter(id int64) error {
+	letter, err := s.deadLetters.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := letter.Discard(time.Now()); err != nil {
+		return err
+	}
+
+	return s.deadLetters.Update(letter)
+}
+
+// GetDeadLetterStats counts the dead letters by status, so admins can see how many are
+// waiting on them
+func (s *DeadLetterService) GetDeadLetterStats() (*DeadLetterStatsDTO, error) {
+	counts, err := s.deadLetters.CountByStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeadLetterStatsDTO{
+		Pending:   counts[ddd.DeadLetterStatusPending],
+		Replayed:  counts[ddd.DeadLetterStatusReplayed],
+		Discarded: counts[ddd.DeadLetterStatusDiscarded],
+	}, nil
+}
@@ -0,0 +1,222 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+// flakyHandler fails until it has been called more than failures times
+type flakyHandler struct {
+	failures int
+	calls    int
+}
+
+func (h *flakyHandler) Handle(event ddd.DomainEvent) error {
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.New("handler failed")
+	}
+	return nil
+}
+
+func (h *flakyHandler) HandleContext(ctx context.Context, event ddd.DomainEvent) error {
+	return h.Handle(event)
+}
+
+type deadLetterTest struct {
+	service    *DeadLetterService
+	dispatcher *ddd.RetryingEventDispatcher
+}
+
+// newDeadLetterTest retries handlers three times, without waiting between attempts
+func newDeadLetterTest(t *testing.T) *deadLetterTest {
+	t.Helper()
+
+	deadLetters := dddmemory.NewInMemoryDeadLetterStore()
+	dispatcher := ddd.NewRetryingEventDispatcher(
+		dddmemory.NewInMemoryEventDispatcher(nil),
+		deadLetters,
+		ddd.RetryPolicy{MaxAttempts: 3},
+	)
+	return &deadLetterTest{
+		service:    NewDeadLetterService(deadLetters, dispatcher),
+		dispatcher: dispatcher,
+	}
+}
+
+// archivePost dispatches a post being archived, as if by a request with the ID
+// "request" made by "admin"
+func (test *deadLetterTest) archivePost(t *testing.T) {
+	t.Helper()
+
+	ctx := ddd.WithEventMetadata(context.Background(), ddd.EventMetadata{
+		EventID:       "event",
+		CorrelationID: "request",
+		ActorID:       "admin",
+	})
+	event := domain.NewPostArchivedEvent("post", time.Now())
+	if err := test.dispatcher.Dispatch(ctx, event); err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+}
+
+func (test *deadLetterTest) pending(t *testing.T) []DeadLetterDTO {
+	t.Helper()
+
+	letters, err := test.service.GetDeadLetters("pending")
+	if err != nil {
+		t.Fatalf("GetDeadLetters() error = %v", err)
+	}
+	return letters
+}
+
+func TestHandlerIsRetriedBeforeDeadLettering(t *testing.T) {
+	test := newDeadLetterTest(t)
+
+	recovers := &flakyHandler{failures: 2}
+	test.dispatcher.Subscribe(domain.PostArchivedEventType.String(), "recovers", recovers.Handle)
+	test.archivePost(t)
+
+	if recovers.calls != 3 {
+		t.Errorf("handler called %d times, want 3", recovers.calls)
+	}
+	if letters := test.pending(t); len(letters) != 0 {
+		t.Errorf("GetDeadLetters() = %#v, want none for a handler that recovered", letters)
+	}
+}
+
+func TestFailingHandlerIsDeadLettered(t *testing.T) {
+	test := newDeadLetterTest(t)
+
+	fails := &flakyHandler{failures: 4}
+	succeeds := &flakyHandler{}
+	test.dispatcher.Subscribe(domain.PostArchivedEventType.String(), "fails", fails.Handle)
+	test.dispatcher.SubscribeWithPolicy(
+		domain.PostArchivedEventType.String(),
+		"succeeds",
+		ddd.RetryPolicy{MaxAttempts: 1},
+		succeeds.HandleContext,
+	)
+	test.archivePost(t)
+
+	if fails.calls != 3 || succeeds.calls != 1 {
+		t.Errorf("handlers called %d and %d times, want 3 and 1", fails.calls, succeeds.calls)
+	}
+
+	letters := test.pending(t)
+	if len(letters) != 1 {
+		t.Fatalf("GetDeadLetters() returned %d dead letters, want 1", len(letters))
+	}
+	letter := letters[0]
+	if letter.EventType != domain.PostArchivedEventType.String() ||
+		letter.Attempts != 3 ||
+		letter.LastError != "handler failed" {
+		t.Errorf("GetDeadLetters() = %#v, want the archived post after 3 attempts", letter)
+	}
+	if letter.Metadata.CorrelationID != "request" || letter.Metadata.ActorID != "admin" {
+		t.Errorf("GetDeadLetters() metadata = %+v, want the request's", letter.Metadata)
+	}
+
+	// Replaying while the handler still fails leaves the dead letter pending
+	if _, err := test.service.ReplayDeadLetter(context.Background(), letter.ID); !errors.Is(err, ddd.ErrReplayFailed) {
+		t.Fatalf("ReplayDeadLetter() error = %v, want ErrReplayFailed", err)
+	}
+	failed, err := test.service.GetDeadLetter(letter.ID)
+	if err != nil {
+		t.Fatalf("GetDeadLetter() error = %v", err)
+	}
+	if failed.Status != "pending" || failed.Attempts != 4 {
+		t.Errorf("GetDeadLetter() = %#v, want it pending after 4 attempts", failed)
+	}
+
+	// The handler recovers, and only it is run again
+	replayed, err := test.service.ReplayDeadLetter(context.Background(), letter.ID)
+	if err != nil {
+		t.Fatalf("ReplayDeadLetter() error = %v", err)
+	}
+	if replayed.Status != "replayed" || replayed.ResolvedAt == nil {
+		t.Errorf("ReplayDeadLetter() = %#v, want it replayed", replayed)
+	}
+	if fails.calls != 5 || succeeds.calls != 1 {
+		t.Errorf("handlers called %d and %d times, want 5 and 1", fails.calls, succeeds.calls)
+	}
+
+	if _, err := test.service.ReplayDeadLetter(context.Background(), letter.ID); !errors.Is(err, ddd.ErrDeadLetterResolved) {
+		t.Errorf("ReplayDeadLetter() again error = %v, want ErrDeadLetterResolved", err)
+	}
+}
+
+func TestDiscardDeadLetter(t *testing.T) {
+	test := newDeadLetterTest(t)
+
+	fails := &flakyHandler{failures: 10}
+	test.dispatcher.Subscribe(domain.PostArchivedEventType.String(), "fails", fails.Handle)
+	test.archivePost(t)
+	test.archivePost(t)
+
+	letters := test.pending(t)
+	if len(letters) != 2 {
+		t.Fatalf("GetDeadLetters() returned %d dead letters, want 2", len(letters))
+	}
+
+	if err := test.service.DiscardDeadLetter(letters[0].ID); err != nil {
+		t.Fatalf("DiscardDeadLetter() error = %v", err)
+	}
+	if err := test.service.DiscardDeadLetter(letters[0].ID); !errors.Is(err, ddd.ErrDeadLetterResolved) {
+		t.Errorf("DiscardDeadLetter() again error = %v, want ErrDeadLetterResolved", err)
+	}
+	if err := test.service.DiscardDeadLetter(999); !errors.Is(err, ddd.ErrDeadLetterNotFound) {
+		t.Errorf("DiscardDeadLetter() of a missing dead letter error = %v, want ErrDeadLetterNotFound", err)
+	}
+
+	stats, err := test.service.GetDeadLetterStats()
+	if err != nil {
+		t.Fatalf("GetDeadLetterStats() error = %v", err)
+	}
+	if *stats != (DeadLetterStatsDTO{Pending: 1, Discarded: 1}) {
+		t.Errorf("GetDeadLetterStats() = %+v, want 1 pending and 1 discarded", *stats)
+	}
+
+	if _, err := test.service.GetDeadLetters("lost"); !errors.Is(err, ddd.ErrUnknownDeadLetterStatus) {
+		t.Errorf("GetDeadLetters() of an unknown status error = %v, want ErrUnknownDeadLetterStatus", err)
+	}
+}
+
+func TestSubscribingWithoutAUniqueNamePanics(t *testing.T) {
+	test := newDeadLetterTest(t)
+
+	handler := &flakyHandler{}
+	test.dispatcher.Subscribe(domain.PostArchivedEventType.String(), "archived", handler.Handle)
+
+	tests := []struct {
+		name      string
+		eventType string
+		handler   string
+		wantPanic bool
+	}{
+		{"name taken", domain.PostArchivedEventType.String(), "archived", true},
+		{"no name", domain.PostArchivedEventType.String(), "", true},
+		{"name taken for another event type", domain.PostCreatedEventType.String(), "archived", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if panicked := recover() != nil; panicked != tt.wantPanic {
+					t.Errorf("Subscribe() panicked = %v, want %v", panicked, tt.wantPanic)
+				}
+			}()
+			test.dispatcher.Subscribe(tt.eventType, tt.handler, handler.Handle)
+		})
+	}
+
+	if subscriptions := test.dispatcher.Subscriptions(); len(subscriptions) != 2 {
+		t.Errorf("Subscriptions() = %+v, want the 2 with unique names", subscriptions)
+	}
+}
@@ -0,0 +1,532 @@
+package application
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/mail"
+)
+
+const (
+	// maxDigestItems is the most comments or followed posts listed in a digest
+	maxDigestItems = 10
+	// topDigestPosts is how many of the week's top posts a digest lists
+	topDigestPosts = 5
+	// topPostPeriod is how far back top posts are picked from
+	topPostPeriod = 7 * 24 * time.Hour
+	// digestUnsubscribePurpose keeps digest unsubscribe links from working for any
+	// other email
+	digestUnsubscribePurpose = "digest-unsubscribe"
+)
+
+//go:embed templates/subject.txt templates/digest.txt templates/digest.html
+var digestTemplates embed.FS
+
+var (
+	digestTextTemplate = template.Must(
+		template.New("digest.txt").
+			Funcs(template.FuncMap{"inc": func(i int) int { return i + 1 }}).
+			ParseFS(digestTemplates, "templates/subject.txt", "templates/digest.txt"),
+	)
+	digestHTMLTemplate = htmltemplate.Must(
+		htmltemplate.New("digest.html").
+			ParseFS(digestTemplates, "templates/subject.txt", "templates/digest.html"),
+	)
+)
+
+// DigestConfig controls the email digests
+type DigestConfig struct {
+	// BaseURL is where the blog is served, for the links in digests
+	BaseURL string
+	// UnsubscribeSecret signs unsubscribe links, so nobody can unsubscribe other users
+	UnsubscribeSecret []byte
+}
+
+type DigestService struct {
+	subscriptionRepo domain.DigestSubscriptionRepository
+	userRepo         domain.UserRepository
+	postRepo         domain.PostRepository
+	commentRepo      domain.CommentRepository
+	ratingRepo       domain.RatingRepository
+	followRepo       domain.FollowRepository
+	mailer           mail.Mailer
+	config           DigestConfig
+}
+
+func NewDigestService(
+	subscriptionRepo domain.DigestSubscriptionRepository,
+	userRepo domain.UserRepository,
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+	ratingRepo domain.RatingRepository,
+	followRepo domain.FollowRepository,
+	mailer mail.Mailer,
+	config DigestConfig,
+) *DigestService {
+	return &DigestService{
+		subscriptionRepo: subscriptionRepo,
+		userRepo:         userRepo,
+		postRepo:         postRepo,
+		commentRepo:      commentRepo,
+		ratingRepo:       ratingRepo,
+		followRepo:       followRepo,
+		mailer:           mailer,
+		config:           config,
+	}
+}
+
+func (s *DigestService) GetSettings(userID string) (*DigestSettingsDTO, error) {
+	subscription, err := s.subscriptionRepo.Find(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	settingsDTO := DigestSettingsDTO{}
+	settingsDTO.FromDomain(subscription)
+
+	return &settingsDTO, nil
+}
+
+// UpdateSettings subscribes the user to daily or weekly digests, or turns them off
+func (s *DigestService) UpdateSettings(userID, frequency string) (*DigestSettingsDTO, error) {
+	subscription, err := s.subscriptionRepo.Find(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := subscription.ChangeFrequency(domain.DigestFrequency(frequency)); err != nil {
+		return nil, err
+	}
+
+	// Persist
+	if err := s.subscriptionRepo.Save(subscription); err != nil {
+		return nil, err
+	}
+
+	settingsDTO := DigestSettingsDTO{}
+	settingsDTO.FromDomain(subscription)
+
+	return &settingsDTO, nil
+}
+
+// Unsubscribe turns off digests for the user the token was signed for. It works
+// without logging in, so the link in a digest unsubscribes in one click
+func (s *DigestService) Unsubscribe(token string) error {
+	userID, err := verifyUnsubscribeToken(
+		s.config.UnsubscribeSecret,
+		digestUnsubscribePurpose,
+		token,
+	)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := s.subscriptionRepo.Find(domain.NewUserID(userID))
+	if err != nil {
+		return err
+	}
+
+	subscription.Unsubscribe()
+
+	// Persist
+	return s.subscriptionRepo.Save(subscription)
+}
+
+// SendDueDigests emails a digest to every subscriber who is due one at the given time,
+// returning how many were sent. A digest with nothing in it isn't sent, but still
+// counts as the user's last digest. Users who can't be emailed are skipped, so one
+// failure doesn't hold up everyone else's digest, and the failures are returned
+// together
+func (s *DigestService) SendDueDigests(at time.Time) (int, error) {
+	subscriptions, err := s.subscriptionRepo.FindSubscribed()
+	if err != nil {
+		return 0, err
+	}
+
+	run := &digestRun{
+		at:    at,
+		names: map[domain.UserID]string{},
+	}
+
+	sent := 0
+	var errs []error
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !subscription.Due(at) {
+			continue
+		}
+
+		ok, err := s.sendDigest(run, subscription)
+		if err != nil {
+			log.Printf("Failed to send digest to %s: %v", subscription.UserID(), err)
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// digestRun holds what every digest sent at the same time shares
+type digestRun struct {
+	at       time.Time
+	names    map[domain.UserID]string
+	topPosts []DigestPostDTO
+	// topPostsLoaded is set once the top posts are loaded, as there may not be any
+	topPostsLoaded bool
+}
+
+// sendDigest builds and sends the subscriber's digest, reporting whether an email was
+// sent
+func (s *DigestService) sendDigest(
+	run *digestRun,
+	subscription *domain.DigestSubscription,
+) (bool, error) {
+	user, err := s.userRepo.FindByID(subscription.UserID())
+	if err != nil {
+		return false, err
+	}
+
+	// Deleted, suspended and banned users keep their subscription, but get no digests
+	// until they're back
+	if user.IsDeactivated() || user.CheckStanding(run.at) != nil {
+		return false, nil
+	}
+
+	digest, err := s.buildDigest(run, user, subscription)
+	if err != nil {
+		return false, err
+	}
+
+	if !digest.Empty() {
+		message, err := renderDigest(digest)
+		if err != nil {
+			return false, err
+		}
+		message.To = user.Email()
+		message.Headers = map[string]string{
+			"List-Unsubscribe":      "<" + digest.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+
+		if err := s.mailer.Send(message); err != nil {
+			return false, err
+		}
+	}
+
+	subscription.MarkSent(run.at)
+
+	// Persist
+	if err := s.subscriptionRepo.Save(subscription); err != nil {
+		return false, err
+	}
+
+	return !digest.Empty(), nil
+}
+
+func (s *DigestService) buildDigest(
+	run *digestRun,
+	user *domain.User,
+	subscription *domain.DigestSubscription,
+) (*DigestDTO, error) {
+	since := subscription.Since(run.at)
+
+	comments, err := s.digestComments(run, user.GetID(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	followedPosts, err := s.digestFollowedPosts(run, user.GetID(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	topPosts, err := s.digestTopPosts(run)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DigestDTO{
+		Username:       user.Username(),
+		Frequency:      subscription.Frequency().String(),
+		Since:          since,
+		Until:          run.at,
+		Comments:       comments,
+		FollowedPosts:  followedPosts,
+		TopPosts:       topPosts,
+		UnsubscribeURL: s.unsubscribeURL(user.GetID()),
+	}, nil
+}
+
+// digestComments returns the newest comments others left on the user's posts since
+// the given time
+func (s *DigestService) digestComments(
+	run *digestRun,
+	userID domain.UserID,
+	since time.Time,
+) ([]DigestCommentDTO, error) {
+	posts, err := s.postRepo.FindByAuthor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type postComment struct {
+		post    *domain.Post
+		comment domain.Comment
+	}
+
+	found := []postComment{}
+	for i := range posts {
+		post := &posts[i]
+		if post.Archived() {
+			continue
+		}
+
+		comments, err := s.commentRepo.FindByPost(post.GetID())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, comment := range comments {
+			if comment.Archived() || comment.CommenterID() == userID ||
+				!inDigestPeriod(comment.CreatedAt(), since, run.at) {
+				continue
+			}
+			found = append(found, postComment{post: post, comment: comment})
+		}
+	}
+
+	slices.SortFunc(found, func(a, b postComment) int {
+		return b.comment.CreatedAt().Compare(a.comment.CreatedAt())
+	})
+	if len(found) > maxDigestItems {
+		found = found[:maxDigestItems]
+	}
+
+	comments := []DigestCommentDTO{}
+	for _, f := range found {
+		commenter, err := s.userName(run, f.comment.CommenterID())
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, DigestCommentDTO{
+			PostTitle: f.post.Title(),
+			PostURL:   s.postURL(f.post.GetID()),
+			Commenter: commenter,
+			Content:   f.comment.Content(),
+			CreatedAt: f.comment.CreatedAt(),
+		})
+	}
+
+	return comments, nil
+}
+
+// digestFollowedPosts returns the newest posts by the authors the user follows since
+// the given time
+func (s *DigestService) digestFollowedPosts(
+	run *digestRun,
+	userID domain.UserID,
+	since time.Time,
+) ([]DigestPostDTO, error) {
+	following, err := s.followRepo.FindFollowing(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(following) == 0 {
+		return []DigestPostDTO{}, nil
+	}
+
+	authorIDs := []domain.UserID{}
+	for _, follow := range following {
+		authorIDs = append(authorIDs, follow.FolloweeID())
+	}
+
+	// Posts come newest first, so stop at the first one from before the digest
+	posts, err := s.postRepo.FindPublishedByAuthors(authorIDs, nil, maxDigestItems)
+	if err != nil {
+		return nil, err
+	}
+
+	followedPosts := []DigestPostDTO{}
+	for i := range posts {
+		if !inDigestPeriod(posts[i].CreatedAt(), since, run.at) {
+			if posts[i].CreatedAt().After(run.at) {
+				continue
+			}
+			break
+		}
+
+		post, err := s.digestPost(run, &posts[i], 0)
+		if err != nil {
+			return nil, err
+		}
+		followedPosts = append(followedPosts, post)
+	}
+
+	return followedPosts, nil
+}
+
+// digestTopPosts returns the posts of the past week with the highest score, the same
+// for every digest in the run. Posts nobody liked more than disliked aren't top posts
+func (s *DigestService) digestTopPosts(run *digestRun) ([]DigestPostDTO, error) {
+	if run.topPostsLoaded {
+		return run.topPosts, nil
+	}
+
+	posts, err := s.postRepo.All()
+	if err != nil {
+		return nil, err
+	}
+
+	type scoredPost struct {
+		post  *domain.Post
+		score int
+	}
+
+	since := run.at.Add(-topPostPeriod)
+
+	scored := []scoredPost{}
+	for i := range posts {
+		post := &posts[i]
+		if post.Archived() || !inDigestPeriod(post.CreatedAt(), since, run.at) {
+			continue
+		}
+
+		ratings, err := s.ratingRepo.FindByPost(post.GetID())
+		if err != nil {
+			return nil, err
+		}
+
+		score := 0
+		for _, rating := range ratings {
+			switch rating.RatingType() {
+			case domain.RatingTypeLike:
+				score++
+			case domain.RatingTypeDislike:
+				score--
+			}
+		}
+		if score > 0 {
+			scored = append(scored, scoredPost{post: post, score: score})
+		}
+	}
+
+	// Highest score first, and the newest post first between equal scores
+	slices.SortFunc(scored, func(a, b scoredPost) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return b.post.CreatedAt().Compare(a.post.CreatedAt())
+	})
+	if len(scored) > topDigestPosts {
+		scored = scored[:topDigestPosts]
+	}
+
+	topPosts := []DigestPostDTO{}
+	for _, sp := range scored {
+		post, err := s.digestPost(run, sp.post, sp.score)
+		if err != nil {
+			return nil, err
+		}
+		topPosts = append(topPosts, post)
+	}
+
+	run.topPosts = topPosts
+	run.topPostsLoaded = true
+
+	return topPosts, nil
+}
+
+func (s *DigestService) digestPost(
+	run *digestRun,
+	post *domain.Post,
+	score int,
+) (DigestPostDTO, error) {
+	author, err := s.userName(run, post.AuthorID())
+	if err != nil {
+		return DigestPostDTO{}, err
+	}
+
+	return DigestPostDTO{
+		Title:     post.Title(),
+		URL:       s.postURL(post.GetID()),
+		Author:    author,
+		CreatedAt: post.CreatedAt(),
+		Score:     score,
+	}, nil
+}
+
+// userName returns the name a user is shown by in digests, looking each user up once
+// per run
+func (s *DigestService) userName(run *digestRun, userID domain.UserID) (string, error) {
+	if name, ok := run.names[userID]; ok {
+		return name, nil
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	name := displayName(user)
+	run.names[userID] = name
+
+	return name, nil
+}
+
+func (s *DigestService) postURL(postID domain.PostID) string {
+	return strings.TrimSuffix(s.config.BaseURL, "/") +
+		"/api/v1/posts/" + url.PathEscape(postID.String())
+}
+
+func (s *DigestService) unsubscribeURL(userID domain.UserID) string {
+	return strings.TrimSuffix(s.config.BaseURL, "/") +
+		"/api/v1/digests/unsubscribe?token=" + url.QueryEscape(s.unsubscribeToken(userID))
+}
+
+func (s *DigestService) unsubscribeToken(userID domain.UserID) string {
+	return signUnsubscribeToken(
+		s.config.UnsubscribeSecret,
+		digestUnsubscribePurpose,
+		userID.String(),
+	)
+}
+
+// inDigestPeriod reports whether the time is after since and no later than until
+func inDigestPeriod(t, since, until time.Time) bool {
+	return t.After(since) && !t.After(until)
+}
+
+// renderDigest renders the digest's subject and its plain text and HTML bodies
+func renderDigest(digest *DigestDTO) (mail.Message, error) {
+	var subject, text, html bytes.Buffer
+
+	if err := digestTextTemplate.ExecuteTemplate(&subject, "subject", digest); err != nil {
+		return mail.Message{}, err
+	}
+	if err := digestTextTemplate.ExecuteTemplate(&text, "digest.txt", digest); err != nil {
+		return mail.Message{}, err
+	}
+	if err := digestHTMLTemplate.ExecuteTemplate(&html, "digest.html", digest); err != nil {
+		return mail.Message{}, err
+	}
+
+	return mail.Message{
+		Subject: subject.String(),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,174 @@
+package application
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/mail"
+)
+
+// recordingMailer keeps the emails it is asked to send, or fails with err when set
+type recordingMailer struct {
+	sent []mail.Message
+	err  error
+}
+
+func (m *recordingMailer) Send(message mail.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+func TestSendDueDigests(t *testing.T) {
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	userRepo := memory.NewUserRepository(outbox)
+	postRepo := memory.NewPostRepository(outbox)
+	commentRepo := memory.NewCommentRepository(outbox)
+	ratingRepo := memory.NewRatingRepository(outbox)
+	followRepo := memory.NewFollowRepository(outbox)
+	subscriptionRepo := memory.NewDigestSubscriptionRepository()
+	mailer := &recordingMailer{}
+
+	service := NewDigestService(
+		subscriptionRepo,
+		userRepo,
+		postRepo,
+		commentRepo,
+		ratingRepo,
+		followRepo,
+		mailer,
+		DigestConfig{BaseURL: "https://blog.example.com/", UnsubscribeSecret: []byte("secret")},
+	)
+
+	ids := map[string]domain.UserID{}
+	for _, username := range []string{"alice", "bob", "carol"} {
+		user, err := domain.NewUser(
+			username+"@example.com",
+			username,
+			"hash",
+			"",
+			[]domain.UserRole{domain.UserRoleAuthor},
+		)
+		if err != nil {
+			t.Fatalf("NewUser() failed: %v", err)
+		}
+		userRepo.Create(user)
+		ids[username] = user.GetID()
+	}
+
+	now := time.Date(2025, 1, 8, 9, 0, 0, 0, time.UTC)
+
+	// Alice's post gets comments from Bob, one too old for the digest, and one from
+	// Alice herself
+	postRepo.Create(domain.RebuildPost("alices-post", ids["alice"], "Alice's post", "c", now.Add(-72*time.Hour), nil, nil, nil))
+	for i, comment := range []struct {
+		commenterID domain.UserID
+		content     string
+		createdAt   time.Time
+	}{
+		{ids["bob"], "Great <b>post</b>", now.Add(-time.Hour)},
+		{ids["bob"], "Too old", now.Add(-48 * time.Hour)},
+		{ids["alice"], "Thanks", now.Add(-30 * time.Minute)},
+	} {
+		// Comments are loaded from their streams, so each starts its stream with the
+		// event it was created by, as the migration to the event store did
+		id := domain.NewCommentID(string(rune('a' + i)))
+		stored := domain.RebuildComment(
+			id,
+			"alices-post",
+			comment.commenterID,
+			comment.content,
+			comment.createdAt,
+			nil,
+			nil,
+		)
+		stored.RecordEvent(domain.NewCommentCreatedEvent(
+			id,
+			"alices-post",
+			comment.commenterID,
+			comment.content,
+			comment.createdAt,
+			nil,
+			nil,
+		))
+		commentRepo.Create(stored)
+	}
+
+	// Alice follows Carol, whose newest post is liked
+	follow, err := domain.NewFollow(ids["alice"], ids["carol"])
+	if err != nil {
+		t.Fatalf("NewFollow() failed: %v", err)
+	}
+	followRepo.Create(follow)
+	postRepo.Create(domain.RebuildPost("carols-post", ids["carol"], "Carol's post", "c", now.Add(-2*time.Hour), nil, nil, nil))
+	ratingRepo.Create(domain.NewRating("carols-post", ids["bob"], domain.RatingTypeLike))
+
+	if _, err := service.UpdateSettings(ids["alice"].String(), "daily"); err != nil {
+		t.Fatalf("UpdateSettings() error = %v", err)
+	}
+	if _, err := service.UpdateSettings(ids["bob"].String(), "hourly"); !errors.Is(err, domain.ErrUnknownDigestFrequency) {
+		t.Errorf("UpdateSettings() error = %v, want ErrUnknownDigestFrequency", err)
+	}
+
+	sent, err := service.SendDueDigests(now)
+	if err != nil {
+		t.Fatalf("SendDueDigests() error = %v", err)
+	}
+	if sent != 1 || len(mailer.sent) != 1 {
+		t.Fatalf("SendDueDigests() sent %d digests, want 1", sent)
+	}
+
+	message := mailer.sent[0]
+	if message.To != "alice@example.com" || message.Subject != "Your daily digest" {
+		t.Errorf("SendDueDigests() sent %q to %q", message.Subject, message.To)
+	}
+	for _, want := range []string{"Great <b>post</b>", "Carol's post", "(1)"} {
+		if !strings.Contains(message.Text, want) {
+			t.Errorf("digest text is missing %q:\n%s", want, message.Text)
+		}
+	}
+	for _, unwanted := range []string{"Too old", "Thanks"} {
+		if strings.Contains(message.Text, unwanted) {
+			t.Errorf("digest text has %q:\n%s", unwanted, message.Text)
+		}
+	}
+	if !strings.Contains(message.HTML, "Great &lt;b&gt;post&lt;/b&gt;") {
+		t.Errorf("digest HTML doesn't escape comments:\n%s", message.HTML)
+	}
+
+	// Alice isn't due another digest until tomorrow
+	if sent, err := service.SendDueDigests(now.Add(time.Hour)); sent != 0 || err != nil {
+		t.Errorf("SendDueDigests() an hour later sent %d, error = %v", sent, err)
+	}
+
+	// The link in the header unsubscribes Alice, but not once it's tampered with
+	link := strings.Trim(message.Headers["List-Unsubscribe"], "<>")
+	unsubscribeURL, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("List-Unsubscribe %q isn't a URL: %v", link, err)
+	}
+	token := unsubscribeURL.Query().Get("token")
+
+	if err := service.Unsubscribe(token + "x"); !errors.Is(err, domain.ErrInvalidUnsubscribeToken) {
+		t.Errorf("Unsubscribe() with a tampered token error = %v, want ErrInvalidUnsubscribeToken", err)
+	}
+	if err := service.Unsubscribe(token); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+
+	settings, err := service.GetSettings(ids["alice"].String())
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if settings.Frequency != "off" {
+		t.Errorf("GetSettings() frequency = %s after unsubscribing, want off", settings.Frequency)
+	}
+}
@@ -1,9 +1,12 @@
 package application
 
 import (
+	"encoding/json"
 	"time"
 
 	"blog/internal/domain"
+	"blog/pkg/ddd"
+	"blog/pkg/ddd/services"
 )
 
 type PostDTO struct {
@@ -14,12 +17,13 @@ type PostDTO struct {
 	CreatedAt    time.Time  `json:"created_at"`
 	LastEditedAt *time.Time `json:"last_edited_at"`
 	ArchivedAt   *time.Time `json:"archived_at"`
+	PopularAt    *time.Time `json:"popular_at"`
 }
 
 func NewPostDTO(
 	id, authorID, title, content string,
 	createdAt time.Time,
-	lastEditedAt, archivedAt *time.Time,
+	lastEditedAt, archivedAt, popularAt *time.Time,
 ) *PostDTO {
 	return &PostDTO{
 		ID:           id,
@@ -29,6 +33,7 @@ func NewPostDTO(
 		CreatedAt:    createdAt,
 		LastEditedAt: lastEditedAt,
 		ArchivedAt:   archivedAt,
+		PopularAt:    popularAt,
 	}
 }
 
@@ -40,6 +45,7 @@ func (dto *PostDTO) FromDomain(post *domain.Post) {
 	dto.CreatedAt = post.CreatedAt()
 	dto.LastEditedAt = post.LastEditedAt()
 	dto.ArchivedAt = post.ArchivedAt()
+	dto.PopularAt = post.PopularAt()
 }
 
 func (dto PostDTO) ToDomain() *domain.Post {
@@ -51,9 +57,37 @@ func (dto PostDTO) ToDomain() *domain.Post {
 		dto.CreatedAt,
 		dto.LastEditedAt,
 		dto.ArchivedAt,
+		dto.PopularAt,
 	)
 }
 
+// PostSummaryDTO is a post as it's listed, without its content
+type PostSummaryDTO struct {
+	ID             string     `json:"id"`
+	AuthorID       string     `json:"author_id"`
+	AuthorUsername string     `json:"author_username"`
+	Title          string     `json:"title"`
+	CommentCount   int        `json:"comment_count"`
+	Likes          int        `json:"likes"`
+	Dislikes       int        `json:"dislikes"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	ArchivedAt     *time.Time `json:"archived_at"`
+}
+
+func (dto *PostSummaryDTO) FromDomain(summary *domain.PostSummary) {
+	dto.ID = summary.PostID.String()
+	dto.AuthorID = summary.AuthorID.String()
+	dto.AuthorUsername = summary.AuthorUsername
+	dto.Title = summary.Title
+	dto.CommentCount = summary.CommentCount
+	dto.Likes = summary.Likes
+	dto.Dislikes = summary.Dislikes
+	dto.CreatedAt = summary.CreatedAt
+	dto.LastActivityAt = summary.LastActivityAt
+	dto.ArchivedAt = summary.ArchivedAt
+}
+
 type CommentDTO struct {
 	ID            string     `json:"id"`
 	PostID        string     `json:"post_id"`
@@ -104,28 +138,67 @@ func (dto CommentDTO) ToDomain() *domain.Comment {
 }
 
 type UserDTO struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"password_hash"`
-	Username     string    `json:"username"`
-	Description  string    `json:"description"`
-	UserRoles    []string  `json:"user_roles"`
-	JoinDate     time.Time `json:"join_date"`
+	ID                   string           `json:"id"`
+	Email                string           `json:"email"`
+	PasswordHash         string           `json:"password_hash"`
+	Username             string           `json:"username"`
+	DisplayName          string           `json:"display_name"`
+	Description          string           `json:"description"`
+	Location             string           `json:"location"`
+	Links                []ProfileLinkDTO `json:"links"`
+	AvatarVersion        string           `json:"avatar_version"`
+	UserRoles            []string         `json:"user_roles"`
+	JoinDate             time.Time        `json:"join_date"`
+	LockedUntil          *time.Time       `json:"locked_until"`
+	Status               string           `json:"status"`
+	StatusReason         string           `json:"status_reason"`
+	SuspendedUntil       *time.Time       `json:"suspended_until"`
+	DeletionRequestedAt  *time.Time       `json:"deletion_requested_at"`
+	DeleteAfter          *time.Time       `json:"delete_after"`
+	AnonymisedAt         *time.Time       `json:"anonymised_at"`
+	PendingEmail         string           `json:"pending_email"`
+	EmailChangeExpiresAt *time.Time       `json:"email_change_expires_at"`
+	UsernameChangedAt    *time.Time       `json:"username_changed_at"`
+	// FollowerCount and FollowingCount aren't part of the user aggregate, the user
+	// service fills them in
+	FollowerCount  int `json:"follower_count"`
+	FollowingCount int `json:"following_count"`
 }
 
 func NewUserDTO(
-	id, email, username, passwordHash, description string,
+	id, email, username, passwordHash, displayName, description, location string,
+	links []ProfileLinkDTO,
+	avatarVersion string,
 	userRoles []string,
 	joinDate time.Time,
+	lockedUntil *time.Time,
+	status, statusReason string,
+	suspendedUntil, deletionRequestedAt, deleteAfter, anonymisedAt *time.Time,
+	pendingEmail string,
+	emailChangeExpiresAt, usernameChangedAt *time.Time,
 ) *UserDTO {
 	return &UserDTO{
-		ID:           id,
-		Email:        email,
-		PasswordHash: passwordHash,
-		Username:     username,
-		Description:  description,
-		UserRoles:    userRoles,
-		JoinDate:     joinDate,
+		ID:                   id,
+		Email:                email,
+		PasswordHash:         passwordHash,
+		Username:             username,
+		DisplayName:          displayName,
+		Description:          description,
+		Location:             location,
+		Links:                links,
+		AvatarVersion:        avatarVersion,
+		UserRoles:            userRoles,
+		JoinDate:             joinDate,
+		LockedUntil:          lockedUntil,
+		Status:               status,
+		StatusReason:         statusReason,
+		SuspendedUntil:       suspendedUntil,
+		DeletionRequestedAt:  deletionRequestedAt,
+		DeleteAfter:          deleteAfter,
+		AnonymisedAt:         anonymisedAt,
+		PendingEmail:         pendingEmail,
+		EmailChangeExpiresAt: emailChangeExpiresAt,
+		UsernameChangedAt:    usernameChangedAt,
 	}
 }
 
@@ -139,9 +212,32 @@ func (dto *UserDTO) FromDomain(user *domain.User) {
 	dto.Email = user.Email()
 	dto.Username = user.Username()
 	dto.PasswordHash = user.PasswordHash()
+	dto.DisplayName = user.DisplayName()
 	dto.Description = user.Description()
+	dto.Location = user.Location()
+	dto.AvatarVersion = user.AvatarVersion()
+
+	dto.Links = []ProfileLinkDTO{}
+	for _, link := range user.Links() {
+		dto.Links = append(dto.Links, ProfileLinkDTO{Kind: link.Kind.String(), URL: link.URL})
+	}
 	dto.UserRoles = roles
 	dto.JoinDate = user.JoinDate()
+	dto.LockedUntil = user.LockedUntil()
+	dto.Status = user.Status().String()
+	dto.StatusReason = user.StatusReason()
+	dto.SuspendedUntil = user.SuspendedUntil()
+	dto.DeletionRequestedAt = user.DeletionRequestedAt()
+	dto.DeleteAfter = user.DeleteAfter()
+	dto.AnonymisedAt = user.AnonymisedAt()
+	dto.UsernameChangedAt = user.UsernameChangedAt()
+
+	// The token hash stays behind, the address is all the user needs to see
+	if change := user.PendingEmailChange(); change != nil {
+		expiresAt := change.ExpiresAt
+		dto.PendingEmail = change.NewEmail
+		dto.EmailChangeExpiresAt = &expiresAt
+	}
 }
 
 func (dto *UserDTO) ToDomain() *domain.User {
@@ -150,17 +246,68 @@ func (dto *UserDTO) ToDomain() *domain.User {
 		roles = append(roles, domain.UserRole(role))
 	}
 
+	links := []domain.ProfileLink{}
+	for _, link := range dto.Links {
+		links = append(links, domain.ProfileLink{Kind: domain.LinkKind(link.Kind), URL: link.URL})
+	}
+
+	profile := domain.Profile{
+		DisplayName: dto.DisplayName,
+		Description: dto.Description,
+		Location:    dto.Location,
+		Links:       links,
+	}
+
+	var pendingEmailChange *domain.EmailChange
+	if dto.PendingEmail != "" && dto.EmailChangeExpiresAt != nil {
+		pendingEmailChange = &domain.EmailChange{
+			NewEmail:  dto.PendingEmail,
+			ExpiresAt: *dto.EmailChangeExpiresAt,
+		}
+	}
+
 	return domain.RebuildUser(
 		domain.NewUserID(dto.ID),
 		dto.Email,
 		dto.PasswordHash,
 		dto.Username,
-		dto.Description,
+		profile,
+		dto.AvatarVersion,
 		roles,
 		dto.JoinDate,
+		dto.LockedUntil,
+		domain.UserStatus(dto.Status),
+		dto.StatusReason,
+		dto.SuspendedUntil,
+		dto.DeletionRequestedAt,
+		dto.DeleteAfter,
+		dto.AnonymisedAt,
+		pendingEmailChange,
+		dto.UsernameChangedAt,
 	)
 }
 
+type IdentityChangeDTO struct {
+	Kind          string     `json:"kind"`
+	OldValue      string     `json:"old_value"`
+	NewValue      string     `json:"new_value"`
+	ChangedAt     time.Time  `json:"changed_at"`
+	ReservedUntil *time.Time `json:"reserved_until"`
+}
+
+func (dto *IdentityChangeDTO) FromDomain(change *domain.IdentityChange) {
+	dto.Kind = change.Kind.String()
+	dto.OldValue = change.OldValue
+	dto.NewValue = change.NewValue
+	dto.ChangedAt = change.ChangedAt
+	dto.ReservedUntil = change.ReservedUntil
+}
+
+type ProfileLinkDTO struct {
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+}
+
 type RatingDTO struct {
 	ID         string     `json:"id"`
 	PostID     string     `json:"post_id"`
@@ -204,3 +351,354 @@ func (dto RatingDTO) ToDomain() *domain.Rating {
 		dto.UpdatedAt,
 	)
 }
+
+type SessionDTO struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	ImpersonatorID string    `json:"impersonator_id,omitempty"`
+	UserAgent      string    `json:"user_agent"`
+	Device         string    `json:"device"`
+	IPAddress      string    `json:"ip_address"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Current        bool      `json:"current"`
+}
+
+func (dto *SessionDTO) FromDomain(session *domain.Session) {
+	dto.ID = session.ID().String()
+	dto.UserID = session.UserID().String()
+	dto.ImpersonatorID = session.ImpersonatorID().String()
+	dto.UserAgent = session.UserAgent()
+	dto.Device = session.Device()
+	dto.IPAddress = session.IPAddress()
+	dto.CreatedAt = session.CreatedAt()
+	dto.LastSeenAt = session.LastSeenAt()
+	dto.ExpiresAt = session.ExpiresAt()
+}
+
+type RoleDTO struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	BuiltIn     bool      `json:"built_in"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (dto *RoleDTO) FromDomain(role *domain.Role) {
+	permissions := []string{}
+	for _, permission := range role.Permissions() {
+		permissions = append(permissions, permission.String())
+	}
+
+	dto.Name = role.Name().String()
+	dto.Description = role.Description()
+	dto.Permissions = permissions
+	dto.BuiltIn = role.BuiltIn()
+	dto.CreatedAt = role.CreatedAt()
+}
+
+type ImpersonationDTO struct {
+	ID        string     `json:"id"`
+	AdminID   string     `json:"admin_id"`
+	UserID    string     `json:"user_id"`
+	Reason    string     `json:"reason"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Active    bool       `json:"active"`
+}
+
+func (dto *ImpersonationDTO) FromDomain(impersonation *domain.Impersonation) {
+	dto.ID = impersonation.GetID().String()
+	dto.AdminID = impersonation.AdminID().String()
+	dto.UserID = impersonation.UserID().String()
+	dto.Reason = impersonation.Reason()
+	dto.StartedAt = impersonation.StartedAt()
+	dto.EndedAt = impersonation.EndedAt()
+	dto.Active = impersonation.Active()
+}
+
+type FollowDTO struct {
+	ID         string    `json:"id"`
+	FollowerID string    `json:"follower_id"`
+	FolloweeID string    `json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (dto *FollowDTO) FromDomain(follow *domain.Follow) {
+	dto.ID = follow.GetID().String()
+	dto.FollowerID = follow.FollowerID().String()
+	dto.FolloweeID = follow.FolloweeID().String()
+	dto.CreatedAt = follow.CreatedAt()
+}
+
+// BookmarkDTO is a saved post. Post is nil and Available false once the post has been
+// archived or deleted, so the bookmark stays in the list marked as no longer available
+type BookmarkDTO struct {
+	ID        string     `json:"id"`
+	PostID    string     `json:"post_id"`
+	FolderID  string     `json:"folder_id"`
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	Available bool       `json:"available"`
+	Post      *PostDTO   `json:"post"`
+}
+
+func (dto *BookmarkDTO) FromDomain(bookmark *domain.Bookmark) {
+	dto.ID = bookmark.GetID().String()
+	dto.PostID = bookmark.PostID().String()
+	dto.FolderID = bookmark.FolderID().String()
+	dto.Note = bookmark.Note()
+	dto.CreatedAt = bookmark.CreatedAt()
+	dto.UpdatedAt = bookmark.UpdatedAt()
+}
+
+type BookmarkFolderDTO struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (dto *BookmarkFolderDTO) FromDomain(folder *domain.BookmarkFolder) {
+	dto.ID = folder.GetID().String()
+	dto.Name = folder.Name()
+	dto.CreatedAt = folder.CreatedAt()
+}
+
+// NotificationDTO tells the recipient that the actor acted on the post. CommentID is
+// empty for notifications about ratings
+type NotificationDTO struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	ActorID   string     `json:"actor_id"`
+	PostID    string     `json:"post_id"`
+	CommentID string     `json:"comment_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+func (dto *NotificationDTO) FromDomain(notification *domain.Notification) {
+	dto.ID = notification.GetID().String()
+	dto.Type = notification.Type().String()
+	dto.ActorID = notification.ActorID().String()
+	dto.PostID = notification.PostID().String()
+	dto.CommentID = notification.CommentID().String()
+	dto.CreatedAt = notification.CreatedAt()
+	dto.ReadAt = notification.ReadAt()
+}
+
+// NotificationInboxDTO is a page of the user's notifications, with how many of all
+// their notifications are unread
+type NotificationInboxDTO struct {
+	UnreadCount   int               `json:"unread_count"`
+	Notifications []NotificationDTO `json:"notifications"`
+}
+
+type NotificationPreferencesDTO struct {
+	Muted []string `json:"muted"`
+}
+
+func (dto *NotificationPreferencesDTO) FromDomain(preferences *domain.NotificationPreferences) {
+	dto.Muted = []string{}
+	for _, notificationType := range preferences.Muted() {
+		dto.Muted = append(dto.Muted, notificationType.String())
+	}
+}
+
+type DigestSettingsDTO struct {
+	Frequency  string     `json:"frequency"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+}
+
+func (dto *DigestSettingsDTO) FromDomain(subscription *domain.DigestSubscription) {
+	dto.Frequency = subscription.Frequency().String()
+	dto.LastSentAt = subscription.LastSentAt()
+}
+
+// DigestDTO is the activity in an email digest, from Since until Until
+type DigestDTO struct {
+	Username       string
+	Frequency      string
+	Since          time.Time
+	Until          time.Time
+	Comments       []DigestCommentDTO
+	FollowedPosts  []DigestPostDTO
+	TopPosts       []DigestPostDTO
+	UnsubscribeURL string
+}
+
+// Empty reports whether the digest has nothing to tell the user
+func (dto DigestDTO) Empty() bool {
+	return len(dto.Comments) == 0 && len(dto.FollowedPosts) == 0 && len(dto.TopPosts) == 0
+}
+
+// DigestCommentDTO is a comment on one of the user's posts
+type DigestCommentDTO struct {
+	PostTitle string
+	PostURL   string
+	Commenter string
+	Content   string
+	CreatedAt time.Time
+}
+
+// DigestPostDTO is a post in a digest. Score is likes minus dislikes, and is only set
+// for top posts
+type DigestPostDTO struct {
+	Title     string
+	URL       string
+	Author    string
+	CreatedAt time.Time
+	Score     int
+}
+
+// NewsletterConfirmationDTO is the email asking a reader to confirm their newsletter
+// subscription. Author is empty for subscriptions to the whole blog
+type NewsletterConfirmationDTO struct {
+	Author     string
+	ConfirmURL string
+	ExpiresAt  time.Time
+}
+
+// NewsletterPostDTO is a new post emailed to a newsletter subscriber. AuthorOnly is set
+// when the subscriber only gets the author's posts
+type NewsletterPostDTO struct {
+	Title          string
+	URL            string
+	Author         string
+	Excerpt        string
+	CreatedAt      time.Time
+	AuthorOnly     bool
+	UnsubscribeURL string
+}
+
+// DeadLetterDTO is an event a handler gave up on. Payload is the event's fields as JSON
+type DeadLetterDTO struct {
+	ID           int64             `json:"id"`
+	Subscription string            `json:"subscription"`
+	EventType    string            `json:"event_type"`
+	EventVersion int               `json:"event_version"`
+	Metadata     ddd.EventMetadata `json:"metadata"`
+	Payload      json.RawMessage   `json:"payload"`
+	OccurredOn   time.Time         `json:"occurred_on"`
+	Attempts     int               `json:"attempts"`
+	LastError    string            `json:"last_error"`
+	Status       string            `json:"status"`
+	FailedAt     time.Time         `json:"failed_at"`
+	ResolvedAt   *time.Time        `json:"resolved_at"`
+}
+
+func (dto *DeadLetterDTO) FromDeadLetter(letter ddd.DeadLetter) {
+	dto.ID = letter.ID
+	dto.Subscription = letter.Subscription
+	dto.EventType = letter.EventType
+	dto.EventVersion = letter.EventVersion
+	dto.Metadata = letter.Metadata
+	dto.Payload = json.RawMessage(letter.Payload)
+	dto.OccurredOn = letter.OccurredOn
+	dto.Attempts = letter.Attempts
+	dto.LastError = letter.LastError
+	dto.Status = letter.Status.String()
+	dto.FailedAt = letter.FailedAt
+	dto.ResolvedAt = letter.ResolvedAt
+}
+
+// DeadLetterStatsDTO counts the dead letters by status
+type DeadLetterStatsDTO struct {
+	Pending   int `json:"pending"`
+	Replayed  int `json:"replayed"`
+	Discarded int `json:"discarded"`
+}
+
+// EventMetricDTO reports on the dispatch of an event type, or on one of its handlers if
+// Handler is set. Durations are in milliseconds
+type EventMetricDTO struct {
+	EventType         string  `json:"event_type"`
+	Handler           string  `json:"handler,omitempty"`
+	Count             int     `json:"count"`
+	Failures          int     `json:"failures"`
+	AverageDurationMs float64 `json:"average_duration_ms"`
+	MaxDurationMs     float64 `json:"max_duration_ms"`
+}
+
+func (dto *EventMetricDTO) FromEventMetric(metric ddd.EventMetric) {
+	dto.EventType = metric.EventType
+	dto.Handler = metric.Handler
+	dto.Count = metric.Count
+	dto.Failures = metric.Failures
+	if metric.Count > 0 {
+		dto.AverageDurationMs = milliseconds(metric.TotalDuration) / float64(metric.Count)
+	}
+	dto.MaxDurationMs = milliseconds(metric.MaxDuration)
+}
+
+// EventTopologyDTO lists the handlers subscribed to a type of event
+type EventTopologyDTO struct {
+	EventType     string                 `json:"event_type"`
+	Description   string                 `json:"description,omitempty"`
+	Subscriptions []EventSubscriptionDTO `json:"subscriptions"`
+}
+
+func (dto *EventTopologyDTO) FromEventType(eventType string) {
+	dto.EventType = eventType
+	if metadata, registered := ddd.EventRegistry.GetMetadata(eventType); registered {
+		dto.Description = metadata.Description
+	}
+	dto.Subscriptions = []EventSubscriptionDTO{}
+}
+
+// EventSubscriptionDTO describes a handler subscribed to the dispatcher, named as its
+// dead letters are, or a handler, route or saga the event coordinator runs. Conditions
+// describe when routes run, and the coordinator's subscriptions list what they run
+type EventSubscriptionDTO struct {
+	Name        string                 `json:"name"`
+	Kind        string                 `json:"kind"`
+	Condition   string                 `json:"condition,omitempty"`
+	MaxAttempts int                    `json:"max_attempts,omitempty"`
+	Runs        []EventSubscriptionDTO `json:"runs,omitempty"`
+}
+
+func (dto *EventSubscriptionDTO) FromSubscription(subscription ddd.Subscription) {
+	dto.Name = subscription.Name
+	dto.Kind = string(services.SubscriptionKindHandler)
+	dto.MaxAttempts = subscription.Policy.Attempts()
+}
+
+func (dto *EventSubscriptionDTO) FromCoordinatorSubscription(subscription services.Subscription) {
+	dto.Name = subscription.Name
+	dto.Kind = string(subscription.Kind)
+	dto.Condition = subscription.Condition
+}
+
+// ProjectionDTO is how far a projection has got through the event log. Behind is how
+// many positions the log's latest event is past the projection's checkpoint
+type ProjectionDTO struct {
+	Name       string    `json:"name"`
+	EventTypes []string  `json:"event_types"`
+	Position   int64     `json:"position"`
+	Head       int64     `json:"head"`
+	Behind     int64     `json:"behind"`
+	LastError  string    `json:"last_error,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (dto *ProjectionDTO) FromProjectionStatus(status ddd.ProjectionStatus) {
+	dto.Name = status.Name
+	dto.EventTypes = status.EventTypes
+	dto.Position = status.Checkpoint.Position
+	dto.Head = status.Head
+	dto.Behind = max(status.Head-status.Checkpoint.Position, 0)
+	dto.LastError = status.Checkpoint.LastError
+	dto.UpdatedAt = status.Checkpoint.UpdatedAt
+}
+
+// ProjectionRebuildDTO is a projection after it was rebuilt, with how many events it
+// was given
+type ProjectionRebuildDTO struct {
+	Projection ProjectionDTO `json:"projection"`
+	Projected  int           `json:"projected"`
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,266 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+	"blog/pkg/ddd/services"
+)
+
+// The reactions to events that run in the event coordinator, by the names
+// EVENT_DISABLED_REACTIONS turns them off with
+const (
+	// AnnouncementCommentsReaction tells admins of comments on posts by other admins
+	AnnouncementCommentsReaction = "announcement-comments"
+	// PopularPostsReaction tells authors when their posts become popular
+	PopularPostsReaction = "popular-posts"
+)
+
+// EventReactionNames returns the name of every reaction
+func EventReactionNames() []string {
+	return []string{
+		AnnouncementCommentsReaction,
+		PopularPostsReaction,
+	}
+}
+
+type EventReactionsConfig struct {
+	// Disabled names the reactions that aren't registered
+	Disabled []string
+	// PopularPostLikes is how many likes make a post popular
+	PopularPostLikes int
+}
+
+func DefaultEventReactionsConfig() EventReactionsConfig {
+	return EventReactionsConfig{
+		Disabled:         []string{},
+		PopularPostLikes: 10,
+	}
+}
+
+// Enabled reports whether the reaction is registered
+func (c EventReactionsConfig) Enabled(reaction string) bool {
+	return !slices.Contains(c.Disabled, reaction)
+}
+
+// EventReactions are the side effects of events that only apply to some events of a
+// type, routed by the event router, or that raise events of their own. Those are recorded
+// on the aggregates they're about, so they're relayed from the outbox like any other
+type EventReactions struct {
+	postRepo            domain.PostRepository
+	userRepo            domain.UserRepository
+	ratingRepo          domain.RatingRepository
+	notificationService *NotificationService
+	config              EventReactionsConfig
+}
+
+func NewEventReactions(
+	postRepo domain.PostRepository,
+	userRepo domain.UserRepository,
+	ratingRepo domain.RatingRepository,
+	notificationService *NotificationService,
+	config EventReactionsConfig,
+) *EventReactions {
+	return &EventReactions{
+		postRepo:            postRepo,
+		userRepo:            userRepo,
+		ratingRepo:          ratingRepo,
+		notificationService: notificationService,
+		config:              config,
+	}
+}
+
+// Register registers the enabled reactions with the coordinator, which should then be
+// subscribed to the dispatcher
+func (r EventReactions) Register(coordinator *services.EventCoordinator) {
+	router := services.NewEventRouter()
+
+	if r.config.Enabled(AnnouncementCommentsReaction) {
+		router.AddEventRoute(domain.CommentCreatedEventType.String(), services.EventRoute{
+			Description: "the post's author is an admin",
+			Match:       r.IsAnnouncement,
+			Handler:     r.HandleAnnouncementCommented,
+		})
+	}
+
+	if r.config.Enabled(PopularPostsReaction) {
+		coordinator.RegisterSaga(NewPopularPostSaga(
+			r.postRepo,
+			r.ratingRepo,
+			r.config.PopularPostLikes,
+		))
+		coordinator.RegisterContextHandler(
+			domain.PostBecamePopularEventType.String(),
+			r.HandlePostBecamePopular,
+		)
+	}
+
+	coordinator.RegisterRouter(router)
+}
+
+// IsAnnouncement reports whether the comment is on a post by an admin. Comments on posts
+// that have gone, or whose authors have, aren't
+func (r EventReactions) IsAnnouncement(ctx context.Context, event ddd.DomainEvent) (bool, error) {
+	e, ok := event.(*domain.CommentCreatedEvent)
+	if !ok {
+		return false, errors.New("invalid event type")
+	}
+
+	post, err := r.postRepo.FindByID(e.PostID)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	author, err := r.userRepo.FindByID(post.AuthorID())
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(author.UserRoles(), domain.UserRoleAdmin), nil
+}
+
+// HandleAnnouncementCommented tells every admin of the comment
+func (r EventReactions) HandleAnnouncementCommented(
+	ctx context.Context,
+	event ddd.DomainEvent,
+) error {
+	e, ok := event.(*domain.CommentCreatedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	users, err := r.userRepo.All()
+	if err != nil {
+		return err
+	}
+
+	adminIDs := []string{}
+	for _, user := range users {
+		if slices.Contains(user.UserRoles(), domain.UserRoleAdmin) {
+			adminIDs = append(adminIDs, user.GetID().String())
+		}
+	}
+
+	return r.notificationService.NotifyAnnouncementCommented(
+		ctx,
+		e.CommentID.String(),
+		e.PostID.String(),
+		e.CommenterID.String(),
+		adminIDs,
+	)
+}
+
+// HandlePostBecamePopular tells the post's author
+func (r EventReactions) HandlePostBecamePopular(ctx context.Context, event ddd.DomainEvent) error {
+	e, ok := event.(*domain.PostBecamePopularEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	return r.notificationService.NotifyPostPopular(ctx, e.PostID.String(), e.LikedBy.String())
+}
+
+// PopularPostSaga makes a post popular once it has at least the popular threshold's
+// likes, which records a PostBecamePopularEvent on the post. Likes relayed together can
+// take a post past the threshold before any of them is counted, so any like from the
+// threshold on will do, and the post is only made popular once
+type PopularPostSaga struct {
+	postRepo   domain.PostRepository
+	ratingRepo domain.RatingRepository
+	likes      int
+}
+
+func NewPopularPostSaga(
+	postRepo domain.PostRepository,
+	ratingRepo domain.RatingRepository,
+	likes int,
+) *PopularPostSaga {
+	return &PopularPostSaga{
+		postRepo:   postRepo,
+		ratingRepo: ratingRepo,
+		likes:      likes,
+	}
+}
+
+// GetHandledEventTypes implements services.Saga
+func (s *PopularPostSaga) GetHandledEventTypes() []string {
+	return []string{
+		domain.RatingCreatedEventType.String(),
+		domain.RatingChangedEventType.String(),
+	}
+}
+
+// Handle implements services.Saga, counting the post's likes when it's liked. It raises
+// no events of its own, as the post's are relayed from the outbox. Posts that have gone
+// are skipped
+func (s *PopularPostSaga) Handle(
+	ctx context.Context,
+	event ddd.DomainEvent,
+) ([]ddd.DomainEvent, error) {
+	var postID domain.PostID
+	var likedBy domain.UserID
+
+	switch e := event.(type) {
+	case *domain.RatingCreatedEvent:
+		if e.RatingType != domain.RatingTypeLike {
+			return nil, nil
+		}
+		postID, likedBy = e.PostID, e.UserID
+	case *domain.RatingChangedEvent:
+		if e.NewRatingType != domain.RatingTypeLike {
+			return nil, nil
+		}
+
+		// The event doesn't say which post was rated
+		rating, err := s.ratingRepo.FindByID(e.RatingID)
+		if errors.Is(err, domain.ErrRatingNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		postID, likedBy = rating.PostID(), rating.UserID()
+	default:
+		return nil, errors.New("invalid event type")
+	}
+
+	ratings, err := s.ratingRepo.FindByPost(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	likes := 0
+	for _, rating := range ratings {
+		if rating.RatingType() == domain.RatingTypeLike {
+			likes++
+		}
+	}
+	if likes < s.likes {
+		return nil, nil
+	}
+
+	post, err := s.postRepo.FindByID(postID)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if post.Popular() {
+		return nil, nil
+	}
+	post.MarkPopular(likedBy, likes)
+
+	// Persist
+	post.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	return nil, s.postRepo.MarkPopular(post)
+}
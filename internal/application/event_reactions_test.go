@@ -0,0 +1,265 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/ddd/services"
+
+	"go.uber.org/zap"
+)
+
+type eventReactionsTest struct {
+	notifications *NotificationService
+	dispatcher    ddd.EventDispatcher
+	outbox        *dddmemory.InMemoryOutboxStore
+	postRepo      *memory.PostRepository
+	userRepo      *memory.UserRepository
+	ratingRepo    *memory.RatingRepository
+	users         map[string]domain.UserID
+}
+
+// newEventReactionsTest registers the reactions with a coordinator subscribed to the
+// dispatcher, as the server does, for the admins alice and dave, and the authors bob and
+// carol
+func newEventReactionsTest(t *testing.T, config EventReactionsConfig) *eventReactionsTest {
+	t.Helper()
+
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	test := &eventReactionsTest{
+		dispatcher: dddmemory.NewInMemoryEventDispatcher(zap.NewNop().Sugar()),
+		outbox:     outbox,
+		postRepo:   memory.NewPostRepository(outbox),
+		userRepo:   memory.NewUserRepository(outbox),
+		ratingRepo: memory.NewRatingRepository(outbox),
+		users:      map[string]domain.UserID{},
+	}
+	test.notifications = NewNotificationService(
+		memory.NewNotificationRepository(outbox),
+		test.postRepo,
+		memory.NewCommentRepository(outbox),
+	)
+
+	for username, role := range map[string]domain.UserRole{
+		"alice": domain.UserRoleAdmin,
+		"bob":   domain.UserRoleAuthor,
+		"carol": domain.UserRoleAuthor,
+		"dave":  domain.UserRoleAdmin,
+	} {
+		user, err := domain.NewUser(
+			username+"@example.com",
+			username,
+			"hash",
+			"",
+			[]domain.UserRole{role},
+		)
+		if err != nil {
+			t.Fatalf("NewUser() failed: %v", err)
+		}
+		test.userRepo.Create(user)
+		test.users[username] = user.GetID()
+	}
+
+	coordinator := services.NewEventCoordinator()
+	NewEventReactions(
+		test.postRepo,
+		test.userRepo,
+		test.ratingRepo,
+		test.notifications,
+		config,
+	).Register(coordinator)
+	coordinator.Subscribe(test.dispatcher)
+
+	return test
+}
+
+func (test *eventReactionsTest) post(t *testing.T, author string) domain.PostID {
+	t.Helper()
+
+	post, err := domain.NewPost(test.users[author], "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	test.postRepo.Create(post)
+	return post.GetID()
+}
+
+func (test *eventReactionsTest) comment(t *testing.T, postID domain.PostID, commenter string) {
+	t.Helper()
+
+	comment, err := domain.NewComment(postID, test.users[commenter], "Nice post")
+	if err != nil {
+		t.Fatalf("NewComment() failed: %v", err)
+	}
+	test.dispatch(t, comment.GetUncommittedEvents())
+}
+
+func (test *eventReactionsTest) like(t *testing.T, postID domain.PostID, raters ...string) {
+	t.Helper()
+
+	for _, rater := range raters {
+		rating := domain.NewRating(postID, test.users[rater], domain.RatingTypeLike)
+		test.ratingRepo.Create(rating)
+	}
+	relayOutbox(t, test.outbox, test.dispatcher)
+}
+
+// outboxed counts the events of the type added to the outbox
+func (test *eventReactionsTest) outboxed(eventType domain.EventType) int {
+	outboxed := 0
+	for _, message := range test.outbox.Messages() {
+		if message.EventType == eventType.String() {
+			outboxed++
+		}
+	}
+	return outboxed
+}
+
+func (test *eventReactionsTest) dispatch(t *testing.T, events []ddd.DomainEvent) {
+	t.Helper()
+
+	for _, event := range events {
+		if err := test.dispatcher.Dispatch(context.Background(), event); err != nil {
+			t.Fatalf("Dispatch() failed: %v", err)
+		}
+	}
+}
+
+// relayOutbox publishes the events the repositories have added to the outbox, as the
+// server's relay does, until the handlers stop raising more
+func relayOutbox(
+	t *testing.T,
+	outbox *dddmemory.InMemoryOutboxStore,
+	dispatcher ddd.EventDispatcher,
+) {
+	t.Helper()
+
+	relay := ddd.NewOutboxRelay(outbox, dispatcher, ddd.DefaultOutboxRelayConfig())
+	for {
+		published, err := relay.Relay(context.Background(), time.Now())
+		if err != nil {
+			t.Fatalf("Relay() failed: %v", err)
+		}
+		if published == 0 {
+			break
+		}
+	}
+
+	for _, message := range outbox.Messages() {
+		if message.LastError != "" {
+			t.Fatalf("publishing %s failed: %s", message.EventType, message.LastError)
+		}
+	}
+}
+
+func (test *eventReactionsTest) notified(
+	t *testing.T,
+	user string,
+	notificationType domain.NotificationType,
+) int {
+	t.Helper()
+
+	inbox, err := test.notifications.GetNotifications(test.users[user].String(), false)
+	if err != nil {
+		t.Fatalf("GetNotifications() error = %v", err)
+	}
+
+	notified := 0
+	for _, notification := range inbox.Notifications {
+		if notification.Type == notificationType.String() {
+			notified++
+		}
+	}
+	return notified
+}
+
+func TestCommentsOnAdminPostsNotifyOtherAdmins(t *testing.T) {
+	test := newEventReactionsTest(t, DefaultEventReactionsConfig())
+
+	announcement := test.post(t, "alice")
+	test.comment(t, announcement, "bob")
+	test.comment(t, announcement, "carol")
+
+	// The post's author hears about it as about any other comment
+	if got := test.notified(t, "dave", domain.NotificationTypeAnnouncementCommented); got != 2 {
+		t.Errorf("dave was notified of %d comments, want 2", got)
+	}
+	if got := test.notified(t, "alice", domain.NotificationTypeAnnouncementCommented); got != 0 {
+		t.Errorf("alice was notified of %d comments on her own post, want 0", got)
+	}
+
+	// Comments on other authors' posts aren't routed to the admins
+	test.comment(t, test.post(t, "bob"), "carol")
+	if got := test.notified(t, "dave", domain.NotificationTypeAnnouncementCommented); got != 2 {
+		t.Errorf("dave was notified of %d comments, want 2", got)
+	}
+}
+
+func TestLikingPostToThresholdMakesItPopularAndNotifiesAuthor(t *testing.T) {
+	test := newEventReactionsTest(t, EventReactionsConfig{PopularPostLikes: 2})
+
+	post := test.post(t, "bob")
+	test.like(t, post, "alice")
+	if got := test.notified(t, "bob", domain.NotificationTypePostPopular); got != 0 {
+		t.Errorf("bob was notified %d times after 1 like, want 0", got)
+	}
+
+	test.like(t, post, "carol")
+	if got := test.notified(t, "bob", domain.NotificationTypePostPopular); got != 1 {
+		t.Errorf("bob was notified %d times after 2 likes, want 1", got)
+	}
+	if got, err := test.postRepo.FindByID(post); err != nil || !got.Popular() {
+		t.Errorf("FindByID() = %+v, %v, want the post made popular", got, err)
+	}
+
+	// Likes past the threshold don't make the post popular again
+	test.like(t, post, "dave")
+	if got := test.outboxed(domain.PostBecamePopularEventType); got != 1 {
+		t.Errorf("%d PostBecamePopular events were added to the outbox, want 1", got)
+	}
+
+	// Reaching the threshold again doesn't tell the author again
+	test.dispatch(t, []ddd.DomainEvent{
+		domain.NewPostBecamePopularEvent(post, test.users["dave"], 2),
+	})
+	if got := test.notified(t, "bob", domain.NotificationTypePostPopular); got != 1 {
+		t.Errorf("bob was notified %d times, want 1", got)
+	}
+}
+
+func TestLikesRelayedTogetherPastThresholdMakePostPopular(t *testing.T) {
+	test := newEventReactionsTest(t, EventReactionsConfig{PopularPostLikes: 2})
+
+	// Every like is counted after the post already has all three
+	post := test.post(t, "bob")
+	test.like(t, post, "alice", "carol", "dave")
+
+	if got := test.outboxed(domain.PostBecamePopularEventType); got != 1 {
+		t.Errorf("%d PostBecamePopular events were added to the outbox, want 1", got)
+	}
+	if got := test.notified(t, "bob", domain.NotificationTypePostPopular); got != 1 {
+		t.Errorf("bob was notified %d times after 3 likes, want 1", got)
+	}
+}
+
+func TestDisabledReactionsAreNotRegistered(t *testing.T) {
+	test := newEventReactionsTest(t, EventReactionsConfig{
+		Disabled:         []string{AnnouncementCommentsReaction, PopularPostsReaction},
+		PopularPostLikes: 1,
+	})
+
+	test.comment(t, test.post(t, "alice"), "bob")
+	test.like(t, test.post(t, "carol"), "bob")
+
+	if got := test.notified(t, "dave", domain.NotificationTypeAnnouncementCommented); got != 0 {
+		t.Errorf("dave was notified of %d comments, want 0", got)
+	}
+	if got := test.notified(t, "carol", domain.NotificationTypePostPopular); got != 0 {
+		t.Errorf("carol was notified %d times, want 0", got)
+	}
+}
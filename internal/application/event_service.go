@@ -0,0 +1,88 @@
+package application
+
+import (
+	"blog/pkg/ddd"
+	"blog/pkg/ddd/services"
+)
+
+// EventMetricsSource reports the event metrics gathered so far
+type EventMetricsSource interface {
+	Snapshot() []ddd.EventMetric
+}
+
+// EventSubscriptionSource lists the handlers subscribed to the event dispatcher
+type EventSubscriptionSource interface {
+	Subscriptions() []ddd.Subscription
+}
+
+// EventCoordinatorSource lists the handlers, routes and sagas the event coordinator runs
+type EventCoordinatorSource interface {
+	Subscriptions() []services.Subscription
+}
+
+// EventService lets admins see how the domain events and their handlers are doing
+type EventService struct {
+	metrics     EventMetricsSource
+	dispatcher  EventSubscriptionSource
+	coordinator EventCoordinatorSource
+}
+
+func NewEventService(
+	metrics EventMetricsSource,
+	dispatcher EventSubscriptionSource,
+	coordinator EventCoordinatorSource,
+) *EventService {
+	return &EventService{
+		metrics:     metrics,
+		dispatcher:  dispatcher,
+		coordinator: coordinator,
+	}
+}
+
+// GetEventMetrics returns how often each event type was dispatched and each of its
+// handlers ran, how often they failed and how long they took
+func (s *EventService) GetEventMetrics() []EventMetricDTO {
+	metricDTOs := []EventMetricDTO{}
+	for _, metric := range s.metrics.Snapshot() {
+		metricDTO := EventMetricDTO{}
+		metricDTO.FromEventMetric(metric)
+		metricDTOs = append(metricDTOs, metricDTO)
+	}
+	return metricDTOs
+}
+
+// GetEventTopology lists the handlers subscribed to each type of event, in order of
+// event type. The event coordinator's subscription lists the handlers, routes and sagas
+// it runs for the event in turn
+func (s *EventService) GetEventTopology() []EventTopologyDTO {
+	coordinated := map[string][]EventSubscriptionDTO{}
+	for _, subscription := range s.coordinator.Subscriptions() {
+		subscriptionDTO := EventSubscriptionDTO{}
+		subscriptionDTO.FromCoordinatorSubscription(subscription)
+		coordinated[subscription.EventType] = append(
+			coordinated[subscription.EventType],
+			subscriptionDTO,
+		)
+	}
+
+	topologyDTOs := []EventTopologyDTO{}
+	for _, subscription := range s.dispatcher.Subscriptions() {
+		if len(topologyDTOs) == 0 ||
+			topologyDTOs[len(topologyDTOs)-1].EventType != subscription.EventType {
+			topologyDTO := EventTopologyDTO{}
+			topologyDTO.FromEventType(subscription.EventType)
+			topologyDTOs = append(topologyDTOs, topologyDTO)
+		}
+
+		subscriptionDTO := EventSubscriptionDTO{}
+		subscriptionDTO.FromSubscription(subscription)
+		if subscription.Handler == services.EventCoordinatorName {
+			subscriptionDTO.Kind = "coordinator"
+			subscriptionDTO.Runs = coordinated[subscription.EventType]
+		}
+
+		topologyDTO := &topologyDTOs[len(topologyDTOs)-1]
+		topologyDTO.Subscriptions = append(topologyDTO.Subscriptions, subscriptionDTO)
+	}
+	return topologyDTOs
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/ddd/services"
+)
+
+type eventServiceTest struct {
+	service     *EventService
+	deadLetters *DeadLetterService
+	dispatcher  *ddd.MiddlewareEventDispatcher
+	coordinator *services.EventCoordinator
+}
+
+// newEventServiceTest wraps a retrying dispatcher in the metrics, recovery and
+// validation middlewares, as the server does, with an event coordinator for the topology
+func newEventServiceTest(t *testing.T) *eventServiceTest {
+	t.Helper()
+
+	deadLetters := dddmemory.NewInMemoryDeadLetterStore()
+	retrying := ddd.NewRetryingEventDispatcher(
+		dddmemory.NewInMemoryEventDispatcher(nil),
+		deadLetters,
+		ddd.RetryPolicy{MaxAttempts: 2},
+	)
+	metrics := dddmemory.NewInMemoryEventMetrics()
+	coordinator := services.NewEventCoordinator()
+	return &eventServiceTest{
+		service:     NewEventService(metrics, retrying, coordinator),
+		deadLetters: NewDeadLetterService(deadLetters, retrying),
+		dispatcher: ddd.NewMiddlewareEventDispatcher(
+			retrying,
+			ddd.MetricsMiddleware(metrics),
+			ddd.RecoveryMiddleware(),
+			ddd.ValidationMiddleware(),
+		),
+		coordinator: coordinator,
+	}
+}
+
+func (test *eventServiceTest) metric(t *testing.T, handler string) EventMetricDTO {
+	t.Helper()
+
+	for _, metric := range test.service.GetEventMetrics() {
+		if metric.EventType == domain.PostArchivedEventType.String() && metric.Handler == handler {
+			return metric
+		}
+	}
+	t.Fatalf("GetEventMetrics() has no metric for %q", handler)
+	return EventMetricDTO{}
+}
+
+type unregisteredEvent struct{}
+
+func (e unregisteredEvent) OccurredOn() time.Time { return time.Now() }
+func (e unregisteredEvent) EventType() string     { return "Unregistered" }
+
+func TestEventMetricsCountEachAttempt(t *testing.T) {
+	test := newEventServiceTest(t)
+
+	fails := &flakyHandler{failures: 1}
+	test.dispatcher.Subscribe(domain.PostArchivedEventType.String(), "fails", fails.Handle)
+
+	event := domain.NewPostArchivedEvent("post", time.Now())
+	if err := test.dispatcher.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+
+	dispatched := test.metric(t, "")
+	if dispatched.Count != 1 || dispatched.Failures != 0 {
+		t.Errorf("dispatch metric = %+v, want 1 dispatch without failures", dispatched)
+	}
+	handled := test.metric(t, "fails")
+	if handled.Count != 2 || handled.Failures != 1 {
+		t.Errorf("handler metric = %+v, want 2 attempts with 1 failure", handled)
+	}
+}
+
+func TestMiddlewareKeepsSubscriptionNameForDeadLetters(t *testing.T) {
+	test := newEventServiceTest(t)
+
+	// The handler's own middleware runs inside the dispatcher's recovery, so its panic
+	// fails the attempt
+	panics := func(info ddd.HandlerInfo, next ddd.ContextEventHandlerFunc) ddd.ContextEventHandlerFunc {
+		return func(ctx context.Context, event ddd.DomainEvent) error {
+			panic("middleware failed")
+		}
+	}
+	handler := &flakyHandler{}
+	test.dispatcher.SubscribeWith(
+		domain.PostArchivedEventType.String(),
+		"archived",
+		handler.HandleContext,
+		panics,
+	)
+
+	event := domain.NewPostArchivedEvent("post", time.Now())
+	if err := test.dispatcher.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+
+	letters, err := test.deadLetters.GetDeadLetters("pending")
+	if err != nil {
+		t.Fatalf("GetDeadLetters() error = %v", err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("GetDeadLetters() returned %d dead letters, want 1", len(letters))
+	}
+	want := "PostArchived:archived"
+	if letters[0].Subscription != want {
+		t.Errorf("dead letter subscription = %q, want %q", letters[0].Subscription, want)
+	}
+	if handler.calls != 0 {
+		t.Errorf("handler called %d times, want 0", handler.calls)
+	}
+}
+
+func TestValidationMiddlewareRefusesUnregisteredEvents(t *testing.T) {
+	test := newEventServiceTest(t)
+
+	if err := test.dispatcher.Dispatch(context.Background(), unregisteredEvent{}); err == nil {
+		t.Error("Dispatch() of an unregistered event succeeded, want an error")
+	}
+	if metric := test.service.GetEventMetrics(); len(metric) != 1 || metric[0].Failures != 1 {
+		t.Errorf("GetEventMetrics() = %+v, want the failed dispatch", metric)
+	}
+}
+
+func TestEventTopologyListsWhatTheCoordinatorRuns(t *testing.T) {
+	test := newEventServiceTest(t)
+
+	handler := &flakyHandler{}
+	test.dispatcher.Subscribe(domain.CommentCreatedEventType.String(), "commented", handler.Handle)
+
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	postRepo := memory.NewPostRepository(outbox)
+	NewEventReactions(
+		postRepo,
+		memory.NewUserRepository(outbox),
+		memory.NewRatingRepository(outbox),
+		NewNotificationService(
+			memory.NewNotificationRepository(outbox),
+			postRepo,
+			memory.NewCommentRepository(outbox),
+		),
+		DefaultEventReactionsConfig(),
+	).Register(test.coordinator)
+	test.coordinator.Subscribe(test.dispatcher)
+
+	topology := map[string]EventTopologyDTO{}
+	for _, topologyDTO := range test.service.GetEventTopology() {
+		topology[topologyDTO.EventType] = topologyDTO
+	}
+
+	want := []EventSubscriptionDTO{
+		{
+			Name:        "CommentCreated:commented",
+			Kind:        "handler",
+			MaxAttempts: 2,
+		},
+		{
+			Name:        "CommentCreated:" + services.EventCoordinatorName,
+			Kind:        "coordinator",
+			MaxAttempts: 2,
+			Runs: []EventSubscriptionDTO{{
+				Name:      "application.EventReactions.HandleAnnouncementCommented",
+				Kind:      "route",
+				Condition: "the post's author is an admin",
+			}},
+		},
+	}
+	if got := topology["CommentCreated"].Subscriptions; !reflect.DeepEqual(got, want) {
+		t.Errorf("CommentCreated subscriptions = %+v, want %+v", got, want)
+	}
+
+	// The saga's events cascade to a handler of their own
+	if got := topology["RatingCreated"].Subscriptions; len(got) != 1 ||
+		len(got[0].Runs) != 1 || got[0].Runs[0].Name != "application.PopularPostSaga" {
+		t.Errorf("RatingCreated subscriptions = %+v, want the popular post saga", got)
+	}
+	if got := topology["PostBecamePopular"]; got.Description == "" || len(got.Subscriptions) != 1 {
+		t.Errorf("PostBecamePopular topology = %+v, want the coordinator's handler", got)
+	}
+}
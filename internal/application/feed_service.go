@@ -0,0 +1,131 @@
+package application
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"blog/internal/domain"
+)
+
+const (
+	// DefaultFeedPageSize is how many posts a feed page holds when no limit is asked for
+	DefaultFeedPageSize = 20
+	// MaxFeedPageSize is the most posts a feed page can hold
+	MaxFeedPageSize = 100
+)
+
+// FeedService builds a user's home feed of published posts by the authors they follow,
+// newest first. Pages are chained with opaque cursors, so how the feed is stored can
+// change without the API changing
+type FeedService interface {
+	// GetFeed returns up to limit posts starting after the cursor. An empty cursor
+	// starts at the newest post, and a limit of 0 uses DefaultFeedPageSize
+	GetFeed(userID, cursor string, limit int) (*FeedPageDTO, error)
+}
+
+// FeedPageDTO is one page of a feed. NextCursor fetches the next page, and is empty on
+// the last one
+type FeedPageDTO struct {
+	Posts      []PostDTO `json:"posts"`
+	NextCursor string    `json:"next_cursor"`
+}
+
+// FanOutOnReadFeedService builds feeds when they are read, by querying the posts of
+// every followed author. Nothing is stored per user, which keeps following and posting
+// cheap at the cost of heavier reads
+type FanOutOnReadFeedService struct {
+	followRepo domain.FollowRepository
+	postRepo   domain.PostRepository
+}
+
+func NewFanOutOnReadFeedService(
+	followRepo domain.FollowRepository,
+	postRepo domain.PostRepository,
+) *FanOutOnReadFeedService {
+	return &FanOutOnReadFeedService{
+		followRepo: followRepo,
+		postRepo:   postRepo,
+	}
+}
+
+func (s *FanOutOnReadFeedService) GetFeed(
+	userID, cursor string,
+	limit int,
+) (*FeedPageDTO, error) {
+	after, err := decodeFeedCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = feedPageSize(limit)
+
+	follows, err := s.followRepo.FindFollowing(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	authorIDs := []domain.UserID{}
+	for _, follow := range follows {
+		authorIDs = append(authorIDs, follow.FolloweeID())
+	}
+
+	// Ask for one more post than the page holds, to tell whether there's a next page
+	posts, err := s.postRepo.FindPublishedByAuthors(authorIDs, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := FeedPageDTO{Posts: []PostDTO{}}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		page.NextCursor = encodeFeedCursor(posts[limit-1].Position())
+	}
+
+	for i := range posts {
+		postDTO := PostDTO{}
+		postDTO.FromDomain(&posts[i])
+		page.Posts = append(page.Posts, postDTO)
+	}
+
+	return &page, nil
+}
+
+// feedPageSize clamps the requested page size between 1 and MaxFeedPageSize
+func feedPageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultFeedPageSize
+	}
+	return min(limit, MaxFeedPageSize)
+}
+
+// encodeFeedCursor encodes the position of the last post on a page
+func encodeFeedCursor(position domain.PostPosition) string {
+	cursor := position.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + position.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+// decodeFeedCursor returns the position a cursor points at, or nil for an empty cursor
+func decodeFeedCursor(cursor string) (*domain.PostPosition, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidFeedCursor
+	}
+
+	createdAt, id, found := strings.Cut(string(data), "|")
+	if !found || id == "" {
+		return nil, domain.ErrInvalidFeedCursor
+	}
+
+	position := domain.PostPosition{ID: domain.NewPostID(id)}
+	position.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, domain.ErrInvalidFeedCursor
+	}
+
+	return &position, nil
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+func TestFanOutOnReadFeedPaging(t *testing.T) {
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	followRepo := memory.NewFollowRepository(outbox)
+	postRepo := memory.NewPostRepository(outbox)
+
+	for _, followeeID := range []domain.UserID{"bob", "carol"} {
+		follow, err := domain.NewFollow("alice", followeeID)
+		if err != nil {
+			t.Fatalf("NewFollow() failed: %v", err)
+		}
+		followRepo.Create(follow)
+	}
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	archivedAt := start.Add(time.Hour)
+	posts := []struct {
+		id         domain.PostID
+		authorID   domain.UserID
+		createdAt  time.Time
+		archivedAt *time.Time
+	}{
+		{id: "p1", authorID: "bob", createdAt: start},
+		{id: "p2", authorID: "carol", createdAt: start.Add(time.Minute)},
+		// p3 and p4 were posted at the same moment, so they're ordered by ID
+		{id: "p3", authorID: "bob", createdAt: start.Add(2 * time.Minute)},
+		{id: "p4", authorID: "carol", createdAt: start.Add(2 * time.Minute)},
+		{id: "p5", authorID: "bob", createdAt: start.Add(3 * time.Minute)},
+		{id: "archived", authorID: "bob", createdAt: start.Add(4 * time.Minute), archivedAt: &archivedAt},
+		{id: "unfollowed", authorID: "dave", createdAt: start.Add(5 * time.Minute)},
+	}
+	for _, post := range posts {
+		postRepo.Create(domain.RebuildPost(
+			post.id, post.authorID, "Title", "Content", post.createdAt, nil, post.archivedAt, nil,
+		))
+	}
+
+	feed := NewFanOutOnReadFeedService(followRepo, postRepo)
+
+	gotIDs := []string{}
+	cursor := ""
+	for pages := 1; ; pages++ {
+		page, err := feed.GetFeed("alice", cursor, 2)
+		if err != nil {
+			t.Fatalf("GetFeed() page %d error = %v", pages, err)
+		}
+		for _, post := range page.Posts {
+			gotIDs = append(gotIDs, post.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if pages > 3 {
+			t.Fatalf("GetFeed() didn't run out of pages")
+		}
+		cursor = page.NextCursor
+	}
+
+	wantIDs := []string{"p5", "p4", "p3", "p2", "p1"}
+	if !slices.Equal(gotIDs, wantIDs) {
+		t.Errorf("GetFeed() posts = %v, want %v", gotIDs, wantIDs)
+	}
+
+	if _, err := feed.GetFeed("alice", "not a cursor", 2); !errors.Is(err, domain.ErrInvalidFeedCursor) {
+		t.Errorf("GetFeed() with a bad cursor error = %v, want ErrInvalidFeedCursor", err)
+	}
+}
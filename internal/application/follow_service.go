@@ -0,0 +1,114 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type FollowService struct {
+	followRepo domain.FollowRepository
+	userRepo   domain.UserRepository
+}
+
+func NewFollowService(
+	followRepo domain.FollowRepository,
+	userRepo domain.UserRepository,
+) *FollowService {
+	return &FollowService{
+		followRepo: followRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Follow makes the follower follow the followee. Suspended and banned users can't
+// follow anyone, and accounts waiting to be deleted can't be followed
+func (s *FollowService) Follow(
+	ctx context.Context, followerID, followeeID string,
+) (*FollowDTO, error) {
+	follower, err := s.userRepo.FindByID(domain.NewUserID(followerID))
+	if err != nil {
+		return nil, err
+	}
+	if err := follower.CheckStanding(time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrForbidden, err)
+	}
+
+	followee, err := s.userRepo.FindByID(domain.NewUserID(followeeID))
+	if err != nil {
+		return nil, err
+	}
+	if followee.IsDeactivated() {
+		return nil, domain.ErrUserNotFound
+	}
+
+	// Follow the user
+	follow, err := domain.NewFollow(follower.GetID(), followee.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist
+	follow.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if _, err := s.followRepo.Create(follow); err != nil {
+		return nil, err
+	}
+
+	followDTO := FollowDTO{}
+	followDTO.FromDomain(follow)
+
+	return &followDTO, nil
+}
+
+func (s *FollowService) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	follow, err := s.followRepo.Find(
+		domain.NewUserID(followerID),
+		domain.NewUserID(followeeID),
+	)
+	if err != nil {
+		return err
+	}
+
+	follow.Unfollow()
+
+	// Persist
+	follow.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.followRepo.Delete(follow); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetFollowers lists the users following the user, newest first
+func (s *FollowService) GetFollowers(userID string) ([]FollowDTO, error) {
+	follows, err := s.followRepo.FindFollowers(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return followDTOs(follows), nil
+}
+
+// GetFollowing lists the users the user follows, newest first
+func (s *FollowService) GetFollowing(userID string) ([]FollowDTO, error) {
+	follows, err := s.followRepo.FindFollowing(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return followDTOs(follows), nil
+}
+
+func followDTOs(follows []domain.Follow) []FollowDTO {
+	followDTOs := []FollowDTO{}
+	for i := range follows {
+		followDTO := FollowDTO{}
+		followDTO.FromDomain(&follows[i])
+		followDTOs = append(followDTOs, followDTO)
+	}
+	return followDTOs
+}
@@ -0,0 +1,145 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type ImpersonationService struct {
+	impersonationRepo domain.ImpersonationRepository
+	userRepo          domain.UserRepository
+	authorizer        *Authorizer
+}
+
+func NewImpersonationService(
+	impersonationRepo domain.ImpersonationRepository,
+	userRepo domain.UserRepository,
+	authorizer *Authorizer,
+) *ImpersonationService {
+	return &ImpersonationService{
+		impersonationRepo: impersonationRepo,
+		userRepo:          userRepo,
+		authorizer:        authorizer,
+	}
+}
+
+// StartImpersonation records the admin starting to act as the user. Users who can
+// manage or impersonate users can't be impersonated themselves, so impersonation
+// never grants an admin more than they already have
+func (s *ImpersonationService) StartImpersonation(
+	ctx context.Context,
+	adminID, userID, reason string,
+) (*ImpersonationDTO, error) {
+	// Check that the admin may impersonate users
+	if err := s.authorizer.Authorize(adminID, domain.ActionImpersonate, nil); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+	if user.AnonymisedAt() != nil {
+		return nil, domain.ErrUserAnonymised
+	}
+
+	for _, action := range []domain.Action{domain.ActionManageUsers, domain.ActionImpersonate} {
+		err := s.authorizer.Authorize(userID, action, nil)
+		if err == nil {
+			return nil, domain.ErrCannotImpersonateAdmin
+		}
+		if !errors.Is(err, domain.ErrForbidden) {
+			return nil, err
+		}
+	}
+
+	// Start the impersonation
+	impersonation, err := domain.StartImpersonation(
+		domain.NewUserID(adminID),
+		user.GetID(),
+		reason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist
+	impersonation.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if _, err := s.impersonationRepo.Create(impersonation); err != nil {
+		return nil, err
+	}
+
+	impersonationDTO := ImpersonationDTO{}
+	impersonationDTO.FromDomain(impersonation)
+
+	return &impersonationDTO, nil
+}
+
+// StopImpersonation ends the impersonation, returning it so the session can be handed
+// back to the admin
+func (s *ImpersonationService) StopImpersonation(
+	ctx context.Context,
+	impersonationID string,
+) (*ImpersonationDTO, error) {
+	impersonation, err := s.impersonationRepo.FindByID(domain.NewImpersonationID(impersonationID))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if err := impersonation.Stop(now); err != nil {
+		return nil, err
+	}
+
+	// Persist
+	impersonation.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.impersonationRepo.End(impersonation); err != nil {
+		return nil, err
+	}
+
+	impersonationDTO := ImpersonationDTO{}
+	impersonationDTO.FromDomain(impersonation)
+
+	return &impersonationDTO, nil
+}
+
+func (s *ImpersonationService) GetImpersonation(impersonationID string) (*ImpersonationDTO, error) {
+	impersonation, err := s.impersonationRepo.FindByID(domain.NewImpersonationID(impersonationID))
+	if err != nil {
+		return nil, err
+	}
+
+	impersonationDTO := ImpersonationDTO{}
+	impersonationDTO.FromDomain(impersonation)
+
+	return &impersonationDTO, nil
+}
+
+// GetImpersonations lists impersonations newest first, of every user when userID is
+// empty
+func (s *ImpersonationService) GetImpersonations(userID string) ([]ImpersonationDTO, error) {
+	var impersonations []domain.Impersonation
+	var err error
+	if userID == "" {
+		impersonations, err = s.impersonationRepo.All()
+	} else {
+		impersonations, err = s.impersonationRepo.FindByUser(domain.NewUserID(userID))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	impersonationDTOs := []ImpersonationDTO{}
+	for i := range impersonations {
+		impersonationDTO := ImpersonationDTO{}
+		impersonationDTO.FromDomain(&impersonations[i])
+		impersonationDTOs = append(impersonationDTOs, impersonationDTO)
+	}
+
+	return impersonationDTOs, nil
+}
@@ -0,0 +1,155 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/clock"
+)
+
+var ErrLoginThrottled = errors.New("too many failed login attempts")
+
+// LoginThrottledError is returned when a login attempt is refused by the throttle
+// It matches ErrLoginThrottled with errors.Is
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLoginThrottled, e.RetryAfter)
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrLoginThrottled
+}
+
+type LoginThrottleConfig struct {
+	// BaseDelay is how long the first failure blocks further attempts, doubling on each failure after
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between attempts
+	MaxDelay time.Duration
+	// ResetAfter forgets failures once no attempt has failed for this long
+	ResetAfter time.Duration
+	// LockoutThreshold is the number of failures against a username that locks the account
+	LockoutThreshold int
+	// LockoutDuration is how long an account stays locked
+	LockoutDuration time.Duration
+}
+
+func DefaultLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		ResetAfter:       time.Hour,
+		LockoutThreshold: 10,
+		LockoutDuration:  15 * time.Minute,
+	}
+}
+
+// LoginThrottle tracks failed logins per username and per client IP and applies an
+// exponential backoff to both
+type LoginThrottle struct {
+	store  domain.LoginThrottleStore
+	clock  clock.Clock
+	config LoginThrottleConfig
+}
+
+func NewLoginThrottle(
+	store domain.LoginThrottleStore,
+	clock clock.Clock,
+	config LoginThrottleConfig,
+) *LoginThrottle {
+	return &LoginThrottle{
+		store:  store,
+		clock:  clock,
+		config: config,
+	}
+}
+
+// Check returns a LoginThrottledError if either the username or the IP is currently blocked
+func (t *LoginThrottle) Check(username, ipAddress string) error {
+	now := t.clock.Now()
+
+	var retryAfter time.Duration
+	for _, key := range throttleKeys(username, ipAddress) {
+		attempts, err := t.store.Get(key)
+		if err != nil {
+			return err
+		}
+
+		if attempts.Blocked(now) {
+			retryAfter = max(retryAfter, attempts.BlockedUntil.Sub(now))
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// RecordFailure registers a failed attempt against the username and the IP, and returns
+// the number of consecutive failures for the username. Failures recorded at the same
+// time, e.g. by concurrent logins, are all counted
+func (t *LoginThrottle) RecordFailure(username, ipAddress string) (int, error) {
+	now := t.clock.Now()
+
+	usernameFailures := 0
+	for i, key := range throttleKeys(username, ipAddress) {
+		// Failures older than ResetAfter are forgotten
+		attempts, err := t.store.RecordFailure(key, now, now.Add(-t.config.ResetAfter), t.backoff)
+		if err != nil {
+			return 0, err
+		}
+
+		if i == 0 {
+			usernameFailures = attempts.Failures
+		}
+	}
+
+	return usernameFailures, nil
+}
+
+// Reset clears the failures against the username, after a successful login or an admin unlock
+// The IP keeps its history so one valid account can't be used to reset it
+func (t *LoginThrottle) Reset(username string) error {
+	return t.store.Delete(usernameThrottleKey(username))
+}
+
+// ShouldLockOut reports whether the given number of failures should lock the account
+func (t *LoginThrottle) ShouldLockOut(failures int) bool {
+	return t.config.LockoutThreshold > 0 && failures >= t.config.LockoutThreshold
+}
+
+// LockoutUntil returns when an account locked now should be unlocked
+func (t *LoginThrottle) LockoutUntil() time.Time {
+	return t.clock.Now().Add(t.config.LockoutDuration)
+}
+
+// Now returns the current time according to the throttle's clock
+func (t *LoginThrottle) Now() time.Time {
+	return t.clock.Now()
+}
+
+func (t *LoginThrottle) backoff(failures int) time.Duration {
+	delay := t.config.BaseDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= t.config.MaxDelay {
+			return t.config.MaxDelay
+		}
+	}
+	return min(delay, t.config.MaxDelay)
+}
+
+func throttleKeys(username, ipAddress string) []string {
+	return []string{usernameThrottleKey(username), "ip:" + ipAddress}
+}
+
+func usernameThrottleKey(username string) string {
+	return "username:" + strings.ToLower(username)
+}
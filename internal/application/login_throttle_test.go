@@ -0,0 +1,154 @@
+package application
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"blog/internal/infrastructure/persistence/memory"
+	"blog/pkg/clock"
+)
+
+func newTestLoginThrottle() (*LoginThrottle, *clock.Fake) {
+	fakeClock := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	throttle := NewLoginThrottle(
+		memory.NewLoginThrottleStore(),
+		fakeClock,
+		LoginThrottleConfig{
+			BaseDelay:        time.Second,
+			MaxDelay:         8 * time.Second,
+			ResetAfter:       time.Hour,
+			LockoutThreshold: 5,
+			LockoutDuration:  15 * time.Minute,
+		},
+	)
+	return throttle, fakeClock
+}
+
+func TestLoginThrottleBackoff(t *testing.T) {
+	throttle, fakeClock := newTestLoginThrottle()
+
+	wantDelays := []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		8 * time.Second,
+	}
+	for i, want := range wantDelays {
+		if err := throttle.Check("alice", "10.0.0.1"); err != nil {
+			t.Fatalf("attempt %d: Check() = %v, want nil", i+1, err)
+		}
+
+		failures, err := throttle.RecordFailure("alice", "10.0.0.1")
+		if err != nil {
+			t.Fatalf("attempt %d: RecordFailure() failed: %v", i+1, err)
+		}
+		if failures != i+1 {
+			t.Errorf("attempt %d: RecordFailure() = %d, want %d", i+1, failures, i+1)
+		}
+
+		var throttledErr *LoginThrottledError
+		err = throttle.Check("alice", "10.0.0.1")
+		if !errors.As(err, &throttledErr) || !errors.Is(err, ErrLoginThrottled) {
+			t.Fatalf("attempt %d: Check() = %v, want LoginThrottledError", i+1, err)
+		}
+		if throttledErr.RetryAfter != want {
+			t.Errorf("attempt %d: RetryAfter = %s, want %s", i+1, throttledErr.RetryAfter, want)
+		}
+
+		fakeClock.Advance(want)
+	}
+
+	if !throttle.ShouldLockOut(len(wantDelays)) {
+		t.Errorf("ShouldLockOut(%d) = false, want true", len(wantDelays))
+	}
+}
+
+func TestLoginThrottleKeys(t *testing.T) {
+	throttle, _ := newTestLoginThrottle()
+
+	if _, err := throttle.RecordFailure("alice", "10.0.0.1"); err != nil {
+		t.Fatalf("RecordFailure() failed: %v", err)
+	}
+
+	// Both the username, regardless of case, and the IP are blocked
+	if err := throttle.Check("ALICE", "10.0.0.2"); !errors.Is(err, ErrLoginThrottled) {
+		t.Errorf("Check() same username = %v, want ErrLoginThrottled", err)
+	}
+	if err := throttle.Check("bob", "10.0.0.1"); !errors.Is(err, ErrLoginThrottled) {
+		t.Errorf("Check() same IP = %v, want ErrLoginThrottled", err)
+	}
+	if err := throttle.Check("bob", "10.0.0.2"); err != nil {
+		t.Errorf("Check() unrelated = %v, want nil", err)
+	}
+
+	// Resetting the username leaves the IP blocked
+	if err := throttle.Reset("alice"); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	if err := throttle.Check("alice", "10.0.0.2"); err != nil {
+		t.Errorf("Check() after reset = %v, want nil", err)
+	}
+	if err := throttle.Check("alice", "10.0.0.1"); !errors.Is(err, ErrLoginThrottled) {
+		t.Errorf("Check() IP after reset = %v, want ErrLoginThrottled", err)
+	}
+}
+
+func TestLoginThrottleForgetsOldFailures(t *testing.T) {
+	throttle, fakeClock := newTestLoginThrottle()
+
+	for range 3 {
+		if _, err := throttle.RecordFailure("alice", "10.0.0.1"); err != nil {
+			t.Fatalf("RecordFailure() failed: %v", err)
+		}
+	}
+
+	fakeClock.Advance(2 * time.Hour)
+
+	failures, err := throttle.RecordFailure("alice", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("RecordFailure() failed: %v", err)
+	}
+	if failures != 1 {
+		t.Errorf("RecordFailure() = %d, want 1", failures)
+	}
+}
+
+func TestLoginThrottleCountsConcurrentFailures(t *testing.T) {
+	throttle, _ := newTestLoginThrottle()
+
+	const attempts = 50
+	counts := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			failures, err := throttle.RecordFailure("alice", "10.0.0.1")
+			if err != nil {
+				t.Errorf("RecordFailure() failed: %v", err)
+			}
+			counts <- failures
+		}()
+	}
+	wg.Wait()
+	close(counts)
+
+	// Each failure was counted once, so each saw a different count
+	seen := map[int]bool{}
+	for failures := range counts {
+		if seen[failures] {
+			t.Errorf("RecordFailure() returned %d more than once", failures)
+		}
+		seen[failures] = true
+	}
+	failures, err := throttle.RecordFailure("alice", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("RecordFailure() failed: %v", err)
+	}
+	if failures != attempts+1 {
+		t.Errorf("RecordFailure() = %d, want %d", failures, attempts+1)
+	}
+}
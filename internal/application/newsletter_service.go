@@ -0,0 +1,514 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"errors"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+	"blog/pkg/mail"
+)
+
+const (
+	// maxNewsletterAttempts is how many times a post is sent to a subscriber before
+	// giving up on it
+	maxNewsletterAttempts = 5
+	// newsletterSendBatch is the most queued posts sent in one run
+	newsletterSendBatch = 100
+	// newsletterExcerptLength is how many characters of a post go in the email
+	newsletterExcerptLength = 280
+	// newsletterUnsubscribePurpose keeps newsletter unsubscribe links from working for
+	// any other email
+	newsletterUnsubscribePurpose = "newsletter-unsubscribe"
+)
+
+//go:embed templates/newsletter_*.txt templates/newsletter_*.html
+var newsletterTemplates embed.FS
+
+var (
+	newsletterTextTemplates = template.Must(
+		template.ParseFS(newsletterTemplates, "templates/newsletter_*.txt"),
+	)
+	newsletterHTMLTemplates = htmltemplate.Must(
+		htmltemplate.ParseFS(
+			newsletterTemplates,
+			"templates/newsletter_*.txt",
+			"templates/newsletter_*.html",
+		),
+	)
+)
+
+// NewsletterConfig controls the newsletter for readers without accounts
+type NewsletterConfig struct {
+	// BaseURL is where the blog is served, for the links in newsletter emails
+	BaseURL string
+	// UnsubscribeSecret signs unsubscribe links, so nobody can unsubscribe other readers
+	UnsubscribeSecret []byte
+	// ConfirmationTTL is how long a new subscriber has to confirm their address
+	ConfirmationTTL time.Duration
+	// WebhookSecret is what the mail provider sends with bounces and complaints. When
+	// it is empty all feedback is rejected
+	WebhookSecret string
+}
+
+type NewsletterService struct {
+	newsletterRepo domain.NewsletterRepository
+	userRepo       domain.UserRepository
+	postRepo       domain.PostRepository
+	mailer         mail.Mailer
+	config         NewsletterConfig
+}
+
+func NewNewsletterService(
+	newsletterRepo domain.NewsletterRepository,
+	userRepo domain.UserRepository,
+	postRepo domain.PostRepository,
+	mailer mail.Mailer,
+	config NewsletterConfig,
+) *NewsletterService {
+	return &NewsletterService{
+		newsletterRepo: newsletterRepo,
+		userRepo:       userRepo,
+		postRepo:       postRepo,
+		mailer:         mailer,
+		config:         config,
+	}
+}
+
+// Subscribe emails the address a link to confirm its subscription to every new post,
+// or only the author's when an author is given. So that nobody can find out which
+// addresses are subscribed, addresses that are already subscribed or disabled are
+// quietly left alone
+func (s *NewsletterService) Subscribe(ctx context.Context, email, authorID string) error {
+	var author *domain.UserID
+	authorName := ""
+	if authorID != "" {
+		domainAuthorID := domain.NewUserID(authorID)
+
+		// Check that the author exists
+		if exists, err := s.userRepo.Exists(domainAuthorID); !exists || err != nil {
+			if err != nil {
+				return err
+			}
+			return domain.ErrUserNotFound
+		}
+
+		user, err := s.userRepo.FindByID(domainAuthorID)
+		if err != nil {
+			return err
+		}
+		if user.IsDeactivated() {
+			return domain.ErrUserNotFound
+		}
+
+		id := user.GetID()
+		author = &id
+		authorName = displayName(user)
+	}
+
+	// Addresses that bounced or complained are never emailed again
+	subscriptions, err := s.newsletterRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	for _, subscription := range subscriptions {
+		if subscription.Disabled() {
+			log.Printf("Not subscribing disabled newsletter address %s", subscription.GetID())
+			return nil
+		}
+	}
+
+	token, err := newNewsletterToken()
+	if err != nil {
+		return err
+	}
+	tokenHash := hashNewsletterToken(token)
+	expiresAt := time.Now().Add(s.config.ConfirmationTTL)
+
+	subscriber, err := s.newsletterRepo.FindByEmailAndAuthor(email, author)
+	switch {
+	case errors.Is(err, domain.ErrNewsletterSubscriberNotFound):
+		subscriber = domain.NewNewsletterSubscriber(email, author, tokenHash, expiresAt)
+	case err != nil:
+		return err
+	default:
+		if err := subscriber.Resubscribe(tokenHash, expiresAt); err != nil {
+			if errors.Is(err, domain.ErrAlreadySubscribedToNewsletter) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	// Persist
+	subscriber.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.newsletterRepo.Save(subscriber); err != nil {
+		return err
+	}
+
+	message, err := renderNewsletterEmail("newsletter_confirm", NewsletterConfirmationDTO{
+		Author:     authorName,
+		ConfirmURL: s.url("/api/v1/newsletter/confirm?token=" + url.QueryEscape(token)),
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	message.To = subscriber.Email()
+
+	return s.mailer.Send(message)
+}
+
+// Confirm activates the subscription the token was sent for
+func (s *NewsletterService) Confirm(ctx context.Context, token string) error {
+	tokenHash := hashNewsletterToken(token)
+
+	subscriber, err := s.newsletterRepo.FindByTokenHash(tokenHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrNewsletterSubscriberNotFound) {
+			return domain.ErrInvalidNewsletterConfirmationToken
+		}
+		return err
+	}
+
+	if err := subscriber.Confirm(tokenHash, time.Now()); err != nil {
+		return err
+	}
+
+	// Persist
+	subscriber.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.newsletterRepo.Save(subscriber); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Unsubscribe stops the emails to the subscriber the token was signed for
+func (s *NewsletterService) Unsubscribe(ctx context.Context, token string) error {
+	id, err := verifyUnsubscribeToken(
+		s.config.UnsubscribeSecret,
+		newsletterUnsubscribePurpose,
+		token,
+	)
+	if err != nil {
+		return err
+	}
+
+	subscriber, err := s.newsletterRepo.FindByID(domain.NewNewsletterSubscriberID(id))
+	if err != nil {
+		if errors.Is(err, domain.ErrNewsletterSubscriberNotFound) {
+			return domain.ErrInvalidUnsubscribeToken
+		}
+		return err
+	}
+
+	subscriber.Unsubscribe(time.Now())
+
+	// Persist
+	subscriber.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.newsletterRepo.Save(subscriber); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RecordFeedback disables every subscription of an address the mail provider reported
+// as bouncing or complaining, returning how many were disabled. The secret must match
+// the configured webhook secret
+func (s *NewsletterService) RecordFeedback(
+	ctx context.Context,
+	secret, email, feedbackType string,
+) (int, error) {
+	if s.config.WebhookSecret == "" ||
+		subtle.ConstantTimeCompare([]byte(secret), []byte(s.config.WebhookSecret)) != 1 {
+		return 0, domain.ErrInvalidNewsletterWebhookSecret
+	}
+
+	reason := domain.NewsletterFeedbackType(feedbackType)
+	if !reason.Valid() {
+		return 0, domain.ErrUnknownNewsletterFeedbackType
+	}
+
+	subscriptions, err := s.newsletterRepo.FindByEmail(email)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	disabled := 0
+	for i := range subscriptions {
+		subscriber := &subscriptions[i]
+		if subscriber.Disabled() {
+			continue
+		}
+
+		if err := subscriber.Disable(reason, now); err != nil {
+			return disabled, err
+		}
+
+		// Persist
+		subscriber.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := s.newsletterRepo.Save(subscriber); err != nil {
+			return disabled, err
+		}
+
+		disabled++
+	}
+
+	return disabled, nil
+}
+
+// QueuePost queues a new post for every confirmed subscriber who gets its author's
+// posts. Queuing the same post again doesn't send it twice
+func (s *NewsletterService) QueuePost(postID string) error {
+	post, err := s.postRepo.FindByID(domain.NewPostID(postID))
+	if err != nil {
+		return err
+	}
+	if post.Archived() {
+		return nil
+	}
+
+	subscribers, err := s.newsletterRepo.FindActiveForAuthor(post.AuthorID())
+	if err != nil {
+		return err
+	}
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	deliveries := []domain.NewsletterDelivery{}
+	for _, subscriber := range subscribers {
+		deliveries = append(
+			deliveries,
+			domain.NewNewsletterDelivery(subscriber.GetID(), post.GetID(), now),
+		)
+	}
+
+	return s.newsletterRepo.QueueDeliveries(deliveries)
+}
+
+// SendQueued emails queued posts to their subscribers, returning how many were sent.
+// A failed send is retried on later runs until it has failed maxNewsletterAttempts
+// times, and the failures are returned together
+func (s *NewsletterService) SendQueued(at time.Time) (int, error) {
+	deliveries, err := s.newsletterRepo.FindPendingDeliveries(newsletterSendBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	run := &newsletterRun{
+		at:    at,
+		posts: map[domain.PostID]*NewsletterPostDTO{},
+	}
+
+	sent := 0
+	var errs []error
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		ok, err := s.deliver(run, delivery)
+		if err != nil {
+			log.Printf(
+				"Failed to send post %s to newsletter subscriber %s: %v",
+				delivery.PostID,
+				delivery.SubscriberID,
+				err,
+			)
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// newsletterRun holds what every email sent at the same time shares. A nil post was
+// archived or removed since it was queued
+type newsletterRun struct {
+	at    time.Time
+	posts map[domain.PostID]*NewsletterPostDTO
+}
+
+// deliver sends the queued post to the subscriber, reporting whether an email was sent.
+// Posts for subscribers who left, and posts that were archived, are skipped
+func (s *NewsletterService) deliver(
+	run *newsletterRun,
+	delivery *domain.NewsletterDelivery,
+) (bool, error) {
+	subscriber, err := s.newsletterRepo.FindByID(delivery.SubscriberID)
+	if err != nil && !errors.Is(err, domain.ErrNewsletterSubscriberNotFound) {
+		return false, err
+	}
+
+	post, err := s.newsletterPost(run, delivery.PostID)
+	if err != nil {
+		return false, err
+	}
+
+	if subscriber == nil || !subscriber.Active() || post == nil {
+		delivery.Skip()
+
+		// Persist
+		return false, s.newsletterRepo.SaveDelivery(delivery)
+	}
+
+	email := *post
+	email.AuthorOnly = subscriber.AuthorID() != nil
+	email.UnsubscribeURL = s.unsubscribeURL(subscriber.GetID())
+
+	message, err := renderNewsletterEmail("newsletter_post", email)
+	if err != nil {
+		return false, err
+	}
+	message.To = subscriber.Email()
+	message.Headers = map[string]string{
+		"List-Unsubscribe":      "<" + email.UnsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+
+	if err := s.mailer.Send(message); err != nil {
+		delivery.RecordFailure(err, maxNewsletterAttempts)
+
+		// Persist
+		if saveErr := s.newsletterRepo.SaveDelivery(delivery); saveErr != nil {
+			return false, errors.Join(err, saveErr)
+		}
+		return false, err
+	}
+
+	delivery.MarkSent(run.at)
+
+	// Persist
+	return true, s.newsletterRepo.SaveDelivery(delivery)
+}
+
+// newsletterPost returns the post as it appears in newsletter emails, looking each post
+// up once per run. It returns nil for posts that are no longer published
+func (s *NewsletterService) newsletterPost(
+	run *newsletterRun,
+	postID domain.PostID,
+) (*NewsletterPostDTO, error) {
+	if post, ok := run.posts[postID]; ok {
+		return post, nil
+	}
+
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil && !errors.Is(err, domain.ErrPostNotFound) {
+		return nil, err
+	}
+	if post == nil || post.Archived() {
+		run.posts[postID] = nil
+		return nil, nil
+	}
+
+	author, err := s.userRepo.FindByID(post.AuthorID())
+	if err != nil {
+		return nil, err
+	}
+
+	postDTO := &NewsletterPostDTO{
+		Title:     post.Title(),
+		URL:       s.url("/api/v1/posts/" + url.PathEscape(post.GetID().String())),
+		Author:    displayName(author),
+		Excerpt:   excerpt(post.Content(), newsletterExcerptLength),
+		CreatedAt: post.CreatedAt(),
+	}
+	run.posts[postID] = postDTO
+
+	return postDTO, nil
+}
+
+func (s *NewsletterService) url(path string) string {
+	return strings.TrimSuffix(s.config.BaseURL, "/") + path
+}
+
+func (s *NewsletterService) unsubscribeURL(id domain.NewsletterSubscriberID) string {
+	token := signUnsubscribeToken(
+		s.config.UnsubscribeSecret,
+		newsletterUnsubscribePurpose,
+		id.String(),
+	)
+	return s.url("/api/v1/newsletter/unsubscribe?token=" + url.QueryEscape(token))
+}
+
+// displayName returns the name a user is shown by in emails
+func displayName(user *domain.User) string {
+	if user.DisplayName() != "" {
+		return user.DisplayName()
+	}
+	return user.Username()
+}
+
+// excerpt returns the start of the text, cut at a word boundary once it is longer than
+// the given number of characters
+func excerpt(text string, length int) string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	runes := []rune(text)[:length]
+	cut := string(runes)
+	if i := strings.LastIndexAny(cut, " \n\t"); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimSpace(cut) + "…"
+}
+
+func newNewsletterToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// hashNewsletterToken hashes a confirmation token for storage, as the token is as good
+// as a confirmed subscription until it expires
+func hashNewsletterToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// renderNewsletterEmail renders the named email's subject and its plain text and HTML
+// bodies
+func renderNewsletterEmail(name string, data any) (mail.Message, error) {
+	var subject, text, html bytes.Buffer
+
+	if err := newsletterTextTemplates.ExecuteTemplate(&subject, name+"_subject", data); err != nil {
+		return mail.Message{}, err
+	}
+	if err := newsletterTextTemplates.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return mail.Message{}, err
+	}
+	if err := newsletterHTMLTemplates.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return mail.Message{}, err
+	}
+
+	return mail.Message{
+		Subject: subject.String(),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,273 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/events"
+	"blog/internal/infrastructure/persistence/memory"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/mail"
+)
+
+type newsletterTest struct {
+	service    *NewsletterService
+	userRepo   *memory.UserRepository
+	postRepo   *memory.PostRepository
+	mailer     *recordingMailer
+	dispatcher ddd.EventDispatcher
+	outbox     *dddmemory.InMemoryOutboxStore
+}
+
+// newNewsletterTest wires the newsletter service to the post event handler, as the
+// server does
+func newNewsletterTest(t *testing.T) *newsletterTest {
+	t.Helper()
+
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	test := &newsletterTest{
+		userRepo:   memory.NewUserRepository(outbox),
+		postRepo:   memory.NewPostRepository(outbox),
+		mailer:     &recordingMailer{},
+		dispatcher: dddmemory.NewInMemoryEventDispatcher(nil),
+		outbox:     outbox,
+	}
+	test.service = NewNewsletterService(
+		memory.NewNewsletterRepository(outbox),
+		test.userRepo,
+		test.postRepo,
+		test.mailer,
+		NewsletterConfig{
+			BaseURL:           "https://blog.example.com",
+			UnsubscribeSecret: []byte("secret"),
+			ConfirmationTTL:   time.Hour,
+			WebhookSecret:     "webhook-secret",
+		},
+	)
+
+	events.NewPostEventHandler(test.service).Register(test.dispatcher)
+	events.NewNewsletterEventHandler().Register(test.dispatcher)
+
+	return test
+}
+
+func (test *newsletterTest) author(t *testing.T, username string) domain.UserID {
+	t.Helper()
+
+	user, err := domain.NewUser(
+		username+"@example.com",
+		username,
+		"hash",
+		"",
+		[]domain.UserRole{domain.UserRoleAuthor},
+	)
+	if err != nil {
+		t.Fatalf("NewUser() failed: %v", err)
+	}
+	test.userRepo.Create(user)
+
+	return user.GetID()
+}
+
+func (test *newsletterTest) publish(t *testing.T, authorID domain.UserID, title string) {
+	t.Helper()
+
+	post, err := domain.NewPost(authorID, title, "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	test.postRepo.Create(post)
+	relayOutbox(t, test.outbox, test.dispatcher)
+}
+
+// subscribe subscribes and confirms the address, using the link in the confirmation
+// email
+func (test *newsletterTest) subscribe(t *testing.T, email string, authorID domain.UserID) {
+	t.Helper()
+
+	if err := test.service.Subscribe(context.Background(), email, authorID.String()); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	message := test.mailer.sent[len(test.mailer.sent)-1]
+	if message.To != email {
+		t.Fatalf("Subscribe() sent the confirmation to %q, want %q", message.To, email)
+	}
+
+	if err := test.service.Confirm(context.Background(), linkToken(t, message.Text, "Confirm: ")); err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+}
+
+// sendQueued sends the queued posts, returning who they were sent to
+func (test *newsletterTest) sendQueued(t *testing.T) []mail.Message {
+	t.Helper()
+
+	before := len(test.mailer.sent)
+	sent, err := test.service.SendQueued(time.Now())
+	if err != nil {
+		t.Fatalf("SendQueued() error = %v", err)
+	}
+
+	messages := test.mailer.sent[before:]
+	if sent != len(messages) {
+		t.Errorf("SendQueued() = %d, but sent %d emails", sent, len(messages))
+	}
+	return messages
+}
+
+// linkToken returns the token in the link on the line of the text with the prefix
+func linkToken(t *testing.T, text, prefix string) string {
+	t.Helper()
+
+	for _, line := range strings.Split(text, "\n") {
+		if link, ok := strings.CutPrefix(line, prefix); ok {
+			u, err := url.Parse(strings.Trim(link, "<> "))
+			if err != nil {
+				t.Fatalf("%q isn't a URL: %v", link, err)
+			}
+			return u.Query().Get("token")
+		}
+	}
+
+	t.Fatalf("no %q line in:\n%s", prefix, text)
+	return ""
+}
+
+func recipients(messages []mail.Message) []string {
+	to := []string{}
+	for _, message := range messages {
+		to = append(to, message.To)
+	}
+	return to
+}
+
+func TestNewsletterSendsNewPostsToConfirmedSubscribers(t *testing.T) {
+	test := newNewsletterTest(t)
+	alice := test.author(t, "alice")
+	bob := test.author(t, "bob")
+
+	test.subscribe(t, "reader@example.com", "")
+	test.subscribe(t, "fan@example.com", alice)
+
+	// Unconfirmed addresses get nothing
+	if err := test.service.Subscribe(context.Background(), "pending@example.com", ""); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// Subscribing again once confirmed sends no second confirmation
+	before := len(test.mailer.sent)
+	if err := test.service.Subscribe(context.Background(), "Reader@Example.com", ""); err != nil {
+		t.Fatalf("Subscribe() again error = %v", err)
+	}
+	if len(test.mailer.sent) != before {
+		t.Error("Subscribe() again sent another confirmation")
+	}
+
+	test.publish(t, bob, "Bob's post")
+	if got := recipients(test.sendQueued(t)); strings.Join(got, ",") != "reader@example.com" {
+		t.Errorf("Bob's post went to %v, want only reader@example.com", got)
+	}
+
+	test.publish(t, alice, "Alice's post")
+	messages := test.sendQueued(t)
+	if len(messages) != 2 {
+		t.Fatalf("Alice's post went to %v, want both subscribers", recipients(messages))
+	}
+	if messages[0].Subject != "New post: Alice's post" {
+		t.Errorf("SendQueued() subject = %q", messages[0].Subject)
+	}
+
+	// Everything queued has been sent
+	if got := test.sendQueued(t); len(got) != 0 {
+		t.Errorf("SendQueued() again sent to %v", recipients(got))
+	}
+
+	// The unsubscribe link in the header stops the emails
+	token := linkToken(t, "List-Unsubscribe: "+messages[0].Headers["List-Unsubscribe"], "List-Unsubscribe: ")
+	if err := test.service.Unsubscribe(context.Background(), token+"x"); !errors.Is(err, domain.ErrInvalidUnsubscribeToken) {
+		t.Errorf("Unsubscribe() with a tampered token error = %v, want ErrInvalidUnsubscribeToken", err)
+	}
+	if err := test.service.Unsubscribe(context.Background(), token); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+
+	test.publish(t, alice, "Alice's second post")
+	got := recipients(test.sendQueued(t))
+	if len(got) != 1 || got[0] == messages[0].To {
+		t.Errorf("after %s unsubscribed the post went to %v", messages[0].To, got)
+	}
+}
+
+func TestNewsletterSubscribeToUnknownAuthorFails(t *testing.T) {
+	test := newNewsletterTest(t)
+
+	err := test.service.Subscribe(context.Background(), "reader@example.com", "nobody")
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Subscribe() error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestNewsletterFeedbackDisablesAddress(t *testing.T) {
+	test := newNewsletterTest(t)
+	alice := test.author(t, "alice")
+
+	test.subscribe(t, "bounced@example.com", "")
+	test.subscribe(t, "bounced@example.com", alice)
+
+	if _, err := test.service.RecordFeedback(context.Background(), "wrong", "bounced@example.com", "bounce"); !errors.Is(err, domain.ErrInvalidNewsletterWebhookSecret) {
+		t.Errorf("RecordFeedback() error = %v, want ErrInvalidNewsletterWebhookSecret", err)
+	}
+	if _, err := test.service.RecordFeedback(context.Background(), "webhook-secret", "bounced@example.com", "spam"); !errors.Is(err, domain.ErrUnknownNewsletterFeedbackType) {
+		t.Errorf("RecordFeedback() error = %v, want ErrUnknownNewsletterFeedbackType", err)
+	}
+
+	disabled, err := test.service.RecordFeedback(context.Background(), "webhook-secret", "Bounced@Example.com", "bounce")
+	if err != nil {
+		t.Fatalf("RecordFeedback() error = %v", err)
+	}
+	if disabled != 2 {
+		t.Errorf("RecordFeedback() disabled %d subscriptions, want 2", disabled)
+	}
+
+	// A disabled address gets neither posts nor confirmations
+	test.publish(t, alice, "Alice's post")
+	if got := test.sendQueued(t); len(got) != 0 {
+		t.Errorf("SendQueued() sent to disabled address: %v", recipients(got))
+	}
+
+	before := len(test.mailer.sent)
+	if err := test.service.Subscribe(context.Background(), "bounced@example.com", ""); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if len(test.mailer.sent) != before {
+		t.Error("Subscribe() emailed a disabled address")
+	}
+}
+
+func TestNewsletterRetriesFailedSends(t *testing.T) {
+	test := newNewsletterTest(t)
+	alice := test.author(t, "alice")
+
+	test.subscribe(t, "reader@example.com", "")
+	test.publish(t, alice, "Alice's post")
+
+	test.mailer.err = errors.New("connection refused")
+	for i := 0; i < maxNewsletterAttempts-1; i++ {
+		if _, err := test.service.SendQueued(time.Now()); err == nil {
+			t.Fatal("SendQueued() with a failing mailer succeeded")
+		}
+	}
+
+	// The post is still queued until the last attempt
+	test.mailer.err = nil
+	if got := test.sendQueued(t); len(got) != 1 {
+		t.Errorf("SendQueued() after the mailer recovered sent %d emails, want 1", len(got))
+	}
+}
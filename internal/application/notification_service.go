@@ -0,0 +1,437 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type NotificationService struct {
+	notificationRepo domain.NotificationRepository
+	postRepo         domain.PostRepository
+	commentRepo      domain.CommentRepository
+}
+
+func NewNotificationService(
+	notificationRepo domain.NotificationRepository,
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		postRepo:         postRepo,
+		commentRepo:      commentRepo,
+	}
+}
+
+// NotifyCommented notifies the post's author of a new comment. Comments aren't
+// threaded, so everyone else who has commented on the post is notified of a reply
+func (s *NotificationService) NotifyCommented(
+	ctx context.Context,
+	commentID, postID, commenterID string,
+) error {
+	post, err := s.postRepo.FindByID(domain.NewPostID(postID))
+	if err != nil {
+		return err
+	}
+
+	comments, err := s.commentRepo.FindByPost(post.GetID())
+	if err != nil {
+		return err
+	}
+
+	actorID := domain.NewUserID(commenterID)
+	domainCommentID := domain.NewCommentID(commentID)
+
+	if err := s.notify(
+		ctx,
+		post.AuthorID(),
+		domain.NotificationTypePostCommented,
+		actorID,
+		post.GetID(),
+		domainCommentID,
+	); err != nil {
+		return err
+	}
+
+	// Each earlier commenter hears about the reply once, however often they commented
+	notified := map[domain.UserID]bool{post.AuthorID(): true}
+	for _, comment := range comments {
+		if comment.Archived() || notified[comment.CommenterID()] {
+			continue
+		}
+		notified[comment.CommenterID()] = true
+
+		if err := s.notify(
+			ctx,
+			comment.CommenterID(),
+			domain.NotificationTypeCommentReplied,
+			actorID,
+			post.GetID(),
+			domainCommentID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NotifyRated notifies the post's author that the post was liked or disliked
+func (s *NotificationService) NotifyRated(ctx context.Context, postID, raterID, ratingType string) error {
+	post, err := s.postRepo.FindByID(domain.NewPostID(postID))
+	if err != nil {
+		return err
+	}
+
+	return s.notify(
+		ctx,
+		post.AuthorID(),
+		domain.NotificationTypeForRating(domain.RatingType(ratingType)),
+		domain.NewUserID(raterID),
+		post.GetID(),
+		"",
+	)
+}
+
+// NotifyPostArchived tells the commenters that the post they commented on was archived,
+// along with their comments. The notification comes from whoever archived the post, or
+// its author if that isn't known. Commenters who have already been told are skipped, so
+// it's safe to call again after it failed part way
+func (s *NotificationService) NotifyPostArchived(
+	ctx context.Context,
+	postID string,
+	commenterIDs []string,
+) error {
+	post, err := s.postRepo.FindByID(domain.NewPostID(postID))
+	if err != nil {
+		return err
+	}
+
+	actorID := post.AuthorID()
+	if archivedBy := ddd.EventMetadataFrom(ctx).ActorID; archivedBy != "" {
+		actorID = domain.NewUserID(archivedBy)
+	}
+
+	for _, commenterID := range commenterIDs {
+		recipientID := domain.NewUserID(commenterID)
+
+		notified, err := s.notified(
+			recipientID,
+			domain.NotificationTypePostArchived,
+			post.GetID(),
+			"",
+		)
+		if err != nil {
+			return err
+		}
+		if notified {
+			continue
+		}
+
+		if err := s.notify(
+			ctx,
+			recipientID,
+			domain.NotificationTypePostArchived,
+			actorID,
+			post.GetID(),
+			"",
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NotifyPostPopular tells the post's author that the post became popular, from the user
+// whose like made it so. Authors are only told once about each post
+func (s *NotificationService) NotifyPostPopular(ctx context.Context, postID, likedBy string) error {
+	post, err := s.postRepo.FindByID(domain.NewPostID(postID))
+	if err != nil {
+		return err
+	}
+
+	notified, err := s.notified(post.AuthorID(), domain.NotificationTypePostPopular, post.GetID(), "")
+	if err != nil || notified {
+		return err
+	}
+
+	return s.notify(
+		ctx,
+		post.AuthorID(),
+		domain.NotificationTypePostPopular,
+		domain.NewUserID(likedBy),
+		post.GetID(),
+		"",
+	)
+}
+
+// NotifyAnnouncementCommented tells the admins of a comment on a post by another admin.
+// The post's author is told of it as of any other comment, so is skipped, along with
+// admins who were already told of the comment, so it's safe to call again after it
+// failed part way
+func (s *NotificationService) NotifyAnnouncementCommented(
+	ctx context.Context,
+	commentID, postID, commenterID string,
+	adminIDs []string,
+) error {
+	post, err := s.postRepo.FindByID(domain.NewPostID(postID))
+	if err != nil {
+		return err
+	}
+
+	domainCommentID := domain.NewCommentID(commentID)
+	for _, adminID := range adminIDs {
+		recipientID := domain.NewUserID(adminID)
+		if recipientID == post.AuthorID() {
+			continue
+		}
+
+		notified, err := s.notified(
+			recipientID,
+			domain.NotificationTypeAnnouncementCommented,
+			post.GetID(),
+			domainCommentID,
+		)
+		if err != nil {
+			return err
+		}
+		if notified {
+			continue
+		}
+
+		if err := s.notify(
+			ctx,
+			recipientID,
+			domain.NotificationTypeAnnouncementCommented,
+			domain.NewUserID(commenterID),
+			post.GetID(),
+			domainCommentID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetNotifications lists the user's notifications newest first, only the unread ones
+// when unreadOnly is set
+func (s *NotificationService) GetNotifications(
+	userID string,
+	unreadOnly bool,
+) (*NotificationInboxDTO, error) {
+	domainUserID := domain.NewUserID(userID)
+
+	notifications, err := s.notificationRepo.FindByRecipient(domainUserID, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	unreadCount, err := s.notificationRepo.CountUnread(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	inboxDTO := NotificationInboxDTO{
+		UnreadCount:   unreadCount,
+		Notifications: []NotificationDTO{},
+	}
+	for i := range notifications {
+		notificationDTO := NotificationDTO{}
+		notificationDTO.FromDomain(&notifications[i])
+		inboxDTO.Notifications = append(inboxDTO.Notifications, notificationDTO)
+	}
+
+	return &inboxDTO, nil
+}
+
+func (s *NotificationService) MarkRead(userID, notificationID string) (*NotificationDTO, error) {
+	notification, err := s.findNotification(userID, notificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.markRead([]*domain.Notification{notification}); err != nil {
+		return nil, err
+	}
+
+	notificationDTO := NotificationDTO{}
+	notificationDTO.FromDomain(notification)
+
+	return &notificationDTO, nil
+}
+
+// MarkManyRead marks the notifications read, or every unread notification when no IDs
+// are given. Nothing is marked if any of the notifications isn't the user's. It returns
+// how many notifications were unread before
+func (s *NotificationService) MarkManyRead(userID string, notificationIDs []string) (int, error) {
+	notifications := []*domain.Notification{}
+	if len(notificationIDs) == 0 {
+		unread, err := s.notificationRepo.FindByRecipient(domain.NewUserID(userID), true)
+		if err != nil {
+			return 0, err
+		}
+		for i := range unread {
+			notifications = append(notifications, &unread[i])
+		}
+	} else {
+		for _, notificationID := range notificationIDs {
+			notification, err := s.findNotification(userID, notificationID)
+			if err != nil {
+				return 0, err
+			}
+			notifications = append(notifications, notification)
+		}
+	}
+
+	marked := 0
+	for _, notification := range notifications {
+		if !notification.Read() {
+			marked++
+		}
+	}
+
+	if err := s.markRead(notifications); err != nil {
+		return 0, err
+	}
+
+	return marked, nil
+}
+
+func (s *NotificationService) GetPreferences(userID string) (*NotificationPreferencesDTO, error) {
+	preferences, err := s.notificationRepo.FindPreferences(domain.NewUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	preferencesDTO := NotificationPreferencesDTO{}
+	preferencesDTO.FromDomain(preferences)
+
+	return &preferencesDTO, nil
+}
+
+// UpdatePreferences replaces the types of notification the user has muted
+func (s *NotificationService) UpdatePreferences(
+	userID string,
+	muted []string,
+) (*NotificationPreferencesDTO, error) {
+	mutedTypes := []domain.NotificationType{}
+	for _, notificationType := range muted {
+		mutedTypes = append(mutedTypes, domain.NotificationType(notificationType))
+	}
+
+	preferences, err := domain.NewNotificationPreferences(domain.NewUserID(userID), mutedTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist
+	if err := s.notificationRepo.SavePreferences(preferences); err != nil {
+		return nil, err
+	}
+
+	preferencesDTO := NotificationPreferencesDTO{}
+	preferencesDTO.FromDomain(preferences)
+
+	return &preferencesDTO, nil
+}
+
+// notify creates a notification unless the recipient is the actor or has muted the type
+func (s *NotificationService) notify(
+	ctx context.Context,
+	recipientID domain.UserID,
+	notificationType domain.NotificationType,
+	actorID domain.UserID,
+	postID domain.PostID,
+	commentID domain.CommentID,
+) error {
+	if recipientID == actorID {
+		return nil
+	}
+
+	preferences, err := s.notificationRepo.FindPreferences(recipientID)
+	if err != nil {
+		return err
+	}
+	if preferences.Mutes(notificationType) {
+		return nil
+	}
+
+	// Notify the user
+	notification, err := domain.NewNotification(
+		recipientID,
+		notificationType,
+		actorID,
+		postID,
+		commentID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Persist
+	notification.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if _, err := s.notificationRepo.Create(notification); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// notified reports whether the user already has a notification of the type about the
+// post and comment, which is empty for notifications about the post alone
+func (s *NotificationService) notified(
+	recipientID domain.UserID,
+	notificationType domain.NotificationType,
+	postID domain.PostID,
+	commentID domain.CommentID,
+) (bool, error) {
+	notifications, err := s.notificationRepo.FindByRecipient(recipientID, false)
+	if err != nil {
+		return false, err
+	}
+
+	for _, notification := range notifications {
+		if notification.Type() == notificationType &&
+			notification.PostID() == postID &&
+			notification.CommentID() == commentID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *NotificationService) markRead(notifications []*domain.Notification) error {
+	now := time.Now()
+
+	for _, notification := range notifications {
+		notification.MarkRead(now)
+
+		// Persist
+		if err := s.notificationRepo.MarkRead(notification); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findNotification returns ErrNotificationNotFound for notifications sent to other
+// users, so their IDs can't be probed
+func (s *NotificationService) findNotification(
+	userID, notificationID string,
+) (*domain.Notification, error) {
+	notification, err := s.notificationRepo.FindByID(domain.NewNotificationID(notificationID))
+	if err != nil {
+		return nil, err
+	}
+	if notification.RecipientID() != domain.NewUserID(userID) {
+		return nil, domain.ErrNotificationNotFound
+	}
+
+	return notification, nil
+}
@@ -0,0 +1,184 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/events"
+	"blog/internal/infrastructure/persistence/memory"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type notificationTest struct {
+	service     *NotificationService
+	postRepo    *memory.PostRepository
+	commentRepo *memory.CommentRepository
+	dispatcher  ddd.EventDispatcher
+}
+
+// newNotificationTest wires the notification service to the comment and rating event
+// handlers, as the server does
+func newNotificationTest(t *testing.T) *notificationTest {
+	t.Helper()
+
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	test := &notificationTest{
+		postRepo:    memory.NewPostRepository(outbox),
+		commentRepo: memory.NewCommentRepository(outbox),
+		dispatcher:  dddmemory.NewInMemoryEventDispatcher(nil),
+	}
+	test.service = NewNotificationService(
+		memory.NewNotificationRepository(outbox),
+		test.postRepo,
+		test.commentRepo,
+	)
+
+	events.NewCommentEventHandler(test.service).Register(test.dispatcher)
+	events.NewRatingEventHandler(test.service).Register(test.dispatcher)
+	events.NewNotificationEventHandler().Register(test.dispatcher)
+
+	return test
+}
+
+func (test *notificationTest) comment(t *testing.T, postID domain.PostID, commenterID string) {
+	t.Helper()
+
+	comment, err := domain.NewComment(postID, domain.NewUserID(commenterID), "Nice post")
+	if err != nil {
+		t.Fatalf("NewComment() failed: %v", err)
+	}
+	events := comment.GetUncommittedEvents()
+	test.commentRepo.Create(comment)
+
+	for _, event := range events {
+		if err := test.dispatcher.Dispatch(context.Background(), event); err != nil {
+			t.Fatalf("Dispatch() failed: %v", err)
+		}
+	}
+}
+
+func (test *notificationTest) types(t *testing.T, userID string) []string {
+	t.Helper()
+
+	inbox, err := test.service.GetNotifications(userID, false)
+	if err != nil {
+		t.Fatalf("GetNotifications() error = %v", err)
+	}
+
+	types := []string{}
+	for _, notification := range inbox.Notifications {
+		types = append(types, notification.Type)
+	}
+	return types
+}
+
+func TestCommentsNotifyAuthorAndEarlierCommenters(t *testing.T) {
+	test := newNotificationTest(t)
+
+	post, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	test.postRepo.Create(post)
+
+	test.comment(t, post.GetID(), "alice")
+	test.comment(t, post.GetID(), "bob")
+	test.comment(t, post.GetID(), "bob")
+	test.comment(t, post.GetID(), "carol")
+
+	// Alice hears about every comment but her own, and never as a reply
+	if got := test.types(t, "alice"); len(got) != 3 || got[0] != "post_commented" {
+		t.Errorf("alice was notified of %v, want 3 post_commented", got)
+	}
+
+	// Bob hears about Carol's reply, but not his own second comment
+	if got := test.types(t, "bob"); len(got) != 1 || got[0] != "comment_replied" {
+		t.Errorf("bob was notified of %v, want [comment_replied]", got)
+	}
+
+	if got := test.types(t, "carol"); len(got) != 0 {
+		t.Errorf("carol was notified of %v, want nothing", got)
+	}
+}
+
+func TestMutedNotificationsAreNotCreated(t *testing.T) {
+	test := newNotificationTest(t)
+
+	post, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	test.postRepo.Create(post)
+
+	if _, err := test.service.UpdatePreferences("alice", []string{"post_disliked"}); err != nil {
+		t.Fatalf("UpdatePreferences() error = %v", err)
+	}
+	if _, err := test.service.UpdatePreferences("alice", []string{"post_shared"}); !errors.Is(err, domain.ErrUnknownNotificationType) {
+		t.Errorf("UpdatePreferences() error = %v, want ErrUnknownNotificationType", err)
+	}
+
+	for _, rating := range []struct {
+		userID     domain.UserID
+		ratingType domain.RatingType
+	}{
+		{"bob", domain.RatingTypeDislike},
+		{"carol", domain.RatingTypeLike},
+	} {
+		rating := domain.NewRating(post.GetID(), rating.userID, rating.ratingType)
+		for _, event := range rating.GetUncommittedEvents() {
+			test.dispatcher.Dispatch(context.Background(), event)
+		}
+	}
+
+	if got := test.types(t, "alice"); len(got) != 1 || got[0] != "post_liked" {
+		t.Errorf("alice was notified of %v, want [post_liked]", got)
+	}
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	test := newNotificationTest(t)
+
+	post, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	test.postRepo.Create(post)
+
+	test.comment(t, post.GetID(), "bob")
+	test.comment(t, post.GetID(), "carol")
+	test.comment(t, post.GetID(), "dave")
+
+	inbox, err := test.service.GetNotifications("alice", false)
+	if err != nil {
+		t.Fatalf("GetNotifications() error = %v", err)
+	}
+	if inbox.UnreadCount != 3 {
+		t.Fatalf("GetNotifications() unread = %d, want 3", inbox.UnreadCount)
+	}
+
+	if _, err := test.service.MarkRead("bob", inbox.Notifications[0].ID); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("MarkRead() by another user error = %v, want ErrNotificationNotFound", err)
+	}
+	if _, err := test.service.MarkRead("alice", inbox.Notifications[0].ID); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+
+	marked, err := test.service.MarkManyRead("alice", nil)
+	if err != nil {
+		t.Fatalf("MarkManyRead() error = %v", err)
+	}
+	if marked != 2 {
+		t.Errorf("MarkManyRead() marked %d, want 2", marked)
+	}
+
+	inbox, err = test.service.GetNotifications("alice", true)
+	if err != nil {
+		t.Fatalf("GetNotifications() error = %v", err)
+	}
+	if inbox.UnreadCount != 0 || len(inbox.Notifications) != 0 {
+		t.Errorf("GetNotifications() = %#v, want nothing unread", inbox)
+	}
+}
@@ -0,0 +1,86 @@
+package application
+
+import (
+	"errors"
+
+	"blog/internal/domain"
+)
+
+// Passwords checks new passwords against the password policy and the breached password
+// list, and hashes and verifies them
+type Passwords struct {
+	hasher   domain.PasswordHasher
+	policy   domain.PasswordPolicy
+	breached domain.BreachedPasswordList
+}
+
+// NewPasswords creates the password checks. breached may be nil to skip the breached
+// password check
+func NewPasswords(
+	hasher domain.PasswordHasher,
+	policy domain.PasswordPolicy,
+	breached domain.BreachedPasswordList,
+) *Passwords {
+	return &Passwords{
+		hasher:   hasher,
+		policy:   policy,
+		breached: breached,
+	}
+}
+
+// Check returns a domain.PasswordPolicyError unless the password satisfies the policy
+// and hasn't appeared in a breach
+func (p *Passwords) Check(password, username, email string) error {
+	err := p.policy.Check(password, username, email)
+
+	var policyErr *domain.PasswordPolicyError
+	if err != nil && !errors.As(err, &policyErr) {
+		return err
+	}
+
+	if p.breached != nil {
+		breached, err := p.breached.Contains(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			if policyErr == nil {
+				policyErr = &domain.PasswordPolicyError{}
+			}
+			policyErr.Violations = append(policyErr.Violations, domain.ErrPasswordBreached)
+		}
+	}
+
+	if policyErr != nil {
+		return policyErr
+	}
+
+	return nil
+}
+
+// Hash checks the password and hashes it for storage
+func (p *Passwords) Hash(password, username, email string) (string, error) {
+	if err := p.Check(password, username, email); err != nil {
+		return "", err
+	}
+
+	return p.hasher.Hash(password)
+}
+
+// Verify returns ErrInvalidCredentials unless the password matches the hash
+func (p *Passwords) Verify(passwordHash, password string) error {
+	if err := p.hasher.Compare(passwordHash, password); err != nil {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// Rehash returns a new hash of the password if its current hash is outdated, or an
+// empty string if it's up to date. The password must already have been verified
+func (p *Passwords) Rehash(passwordHash, password string) (string, error) {
+	if !p.hasher.NeedsRehash(passwordHash) {
+		return "", nil
+	}
+
+	return p.hasher.Hash(password)
+}
@@ -0,0 +1,96 @@
+package application
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/passwords"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestBreachFile writes a sorted breached hash file containing the given passwords
+// among some filler hashes
+func newTestBreachFile(t *testing.T, breached ...string) *passwords.BreachFile {
+	t.Helper()
+
+	lines := []string{}
+	for _, password := range append(breached, "filler-1", "filler-2", "filler-3", "filler-4") {
+		sum := sha1.Sum([]byte(password))
+		lines = append(lines, strings.ToUpper(hex.EncodeToString(sum[:]))+":3")
+	}
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	file, err := passwords.OpenBreachFile(path)
+	if err != nil {
+		t.Fatalf("OpenBreachFile() failed: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	return file
+}
+
+func newTestPasswords(t *testing.T, cost int, breached domain.BreachedPasswordList) *Passwords {
+	t.Helper()
+
+	hasher, err := passwords.NewBcryptHasher(cost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() failed: %v", err)
+	}
+
+	return NewPasswords(hasher, domain.DefaultPasswordPolicy(), breached)
+}
+
+func TestPasswordsCheckBreached(t *testing.T) {
+	breachFile := newTestBreachFile(t, "Password1", "Summer2024!", "zzzzzz99")
+	passwords := newTestPasswords(t, bcrypt.MinCost, breachFile)
+
+	for _, password := range []string{"Password1", "Summer2024!", "zzzzzz99"} {
+		if err := passwords.Check(password, "alice", "alice@example.com"); !errors.Is(err, domain.ErrPasswordBreached) {
+			t.Errorf("Check(%q) error = %v, want ErrPasswordBreached", password, err)
+		}
+	}
+
+	if err := passwords.Check("Not-In-The-List-7", "alice", "alice@example.com"); err != nil {
+		t.Errorf("Check() on unbreached password error = %v", err)
+	}
+}
+
+func TestPasswordsRehash(t *testing.T) {
+	oldPasswords := newTestPasswords(t, bcrypt.MinCost, nil)
+	newPasswords := newTestPasswords(t, bcrypt.MinCost+1, nil)
+
+	hash, err := oldPasswords.Hash("Correct-Horse-42", "alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+
+	if rehash, err := oldPasswords.Rehash(hash, "Correct-Horse-42"); err != nil || rehash != "" {
+		t.Errorf("Rehash() at the same cost = %q, %v, want no rehash", rehash, err)
+	}
+
+	rehash, err := newPasswords.Rehash(hash, "Correct-Horse-42")
+	if err != nil || rehash == "" {
+		t.Fatalf("Rehash() at a new cost = %q, %v, want a rehash", rehash, err)
+	}
+	if err := newPasswords.Verify(rehash, "Correct-Horse-42"); err != nil {
+		t.Errorf("Verify() of the rehashed password failed: %v", err)
+	}
+
+	// Hashes from another algorithm are rehashed too
+	if rehash, _ := newPasswords.Rehash("plaintext", "Correct-Horse-42"); rehash == "" {
+		t.Errorf("Rehash() of a non-bcrypt hash didn't rehash")
+	}
+}
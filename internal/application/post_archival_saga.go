@@ -0,0 +1,220 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+	"blog/pkg/ddd/services"
+)
+
+// PostArchivalSagaType is the type of the saga run when a post is archived
+const PostArchivalSagaType = "post-archival"
+
+const (
+	archivePostStep       = "archive-post"
+	archiveCommentsStep   = "archive-comments"
+	notifyCommentersStep  = "notify-commenters"
+	postIDKey             = "post_id"
+	postArchivedAtKey     = "archived_at"
+	archivedCommentsKey   = "archived_comments"
+	archivedCommentersKey = "commenters"
+)
+
+type PostArchivalConfig struct {
+	// MaxAttempts is how many times a step is tried before the post is restored
+	MaxAttempts int
+	// Timeout is how long the saga has to finish before the post is restored
+	Timeout time.Duration
+}
+
+// NewPostArchivalSaga describes the saga that archives a post's comments when the post
+// is archived, then notifies their commenters. If the commenters can't be notified the
+// post and its comments are restored, so they aren't hidden without anyone being told
+//
+// Comments archived since the post was are counted as archived by the saga, so trying
+// the step again after it archived some of them still restores them all
+//
+// Each archival of a post runs a saga of its own, so a post that's archived again after
+// being restored is archived the same way
+func NewPostArchivalSaga(
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+	notificationService *NotificationService,
+	config PostArchivalConfig,
+) services.SagaDefinition {
+	return services.SagaDefinition{
+		Type:      PostArchivalSagaType,
+		StartedBy: domain.PostArchivedEventType.String(),
+		Correlate: func(event ddd.DomainEvent) (string, map[string]string) {
+			e, ok := event.(*domain.PostArchivedEvent)
+			if !ok {
+				return "", nil
+			}
+			archivedAt := e.ArchivedAt.UTC().Format(time.RFC3339Nano)
+			return PostArchivalSagaKey(e.PostID, e.ArchivedAt), map[string]string{
+				postIDKey:         e.PostID.String(),
+				postArchivedAtKey: archivedAt,
+			}
+		},
+		Steps: []services.SagaStep{
+			{
+				// The post was archived before the saga started, so there's only
+				// its archival to undo
+				Name: archivePostStep,
+				Execute: func(ctx context.Context, state *services.SagaState) error {
+					return nil
+				},
+				Compensate: func(ctx context.Context, state *services.SagaState) error {
+					return restorePost(ctx, postRepo, state)
+				},
+			},
+			{
+				Name: archiveCommentsStep,
+				Execute: func(ctx context.Context, state *services.SagaState) error {
+					return archivePostComments(ctx, commentRepo, state)
+				},
+				Compensate: func(ctx context.Context, state *services.SagaState) error {
+					return restorePostComments(ctx, commentRepo, state)
+				},
+			},
+			{
+				Name: notifyCommentersStep,
+				Execute: func(ctx context.Context, state *services.SagaState) error {
+					return notificationService.NotifyPostArchived(
+						ctx,
+						state.Data[postIDKey],
+						splitSagaList(state.Data[archivedCommentersKey]),
+					)
+				},
+			},
+		},
+		MaxAttempts: config.MaxAttempts,
+		Timeout:     config.Timeout,
+	}
+}
+
+// PostArchivalSagaKey is the correlation key of the saga run when the post was archived
+// at archivedAt
+func PostArchivalSagaKey(postID domain.PostID, archivedAt time.Time) string {
+	return postID.String() + "/" + archivedAt.UTC().Format(time.RFC3339Nano)
+}
+
+// restorePost restores the post the saga was started for. A post that has gone since,
+// or has been restored or archived again since, is left alone
+func restorePost(
+	ctx context.Context,
+	postRepo domain.PostRepository,
+	state *services.SagaState,
+) error {
+	postArchivedAt, err := time.Parse(time.RFC3339Nano, state.Data[postArchivedAtKey])
+	if err != nil {
+		return err
+	}
+
+	post, err := postRepo.FindByID(domain.NewPostID(state.Data[postIDKey]))
+	if errors.Is(err, domain.ErrPostNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if post.ArchivedAt() == nil || !post.ArchivedAt().Equal(postArchivedAt) {
+		return nil
+	}
+	post.Restore()
+
+	// Persist
+	post.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	return postRepo.Restore(post)
+}
+
+// archivePostComments archives the post's comments, keeping the IDs of the comments and
+// their commenters in the saga's data
+func archivePostComments(
+	ctx context.Context,
+	commentRepo domain.CommentRepository,
+	state *services.SagaState,
+) error {
+	postArchivedAt, err := time.Parse(time.RFC3339Nano, state.Data[postArchivedAtKey])
+	if err != nil {
+		return err
+	}
+
+	comments, err := commentRepo.FindByPost(domain.NewPostID(state.Data[postIDKey]))
+	if err != nil {
+		return err
+	}
+
+	archived := []string{}
+	commenters := []string{}
+	for _, found := range comments {
+		if found.Archived() && found.ArchivedAt().Before(postArchivedAt) {
+			continue
+		}
+
+		if !found.Archived() {
+			// Load the comment from its events, so it's saved at the right version
+			comment, err := commentRepo.FindByID(found.GetID())
+			if err != nil {
+				return err
+			}
+			comment.Archive()
+
+			// Persist
+			comment.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+			if err := commentRepo.Archive(comment); err != nil {
+				return err
+			}
+		}
+
+		archived = append(archived, found.GetID().String())
+		if commenter := found.CommenterID().String(); !slices.Contains(commenters, commenter) {
+			commenters = append(commenters, commenter)
+		}
+	}
+
+	state.Data[archivedCommentsKey] = strings.Join(archived, ",")
+	state.Data[archivedCommentersKey] = strings.Join(commenters, ",")
+	return nil
+}
+
+// restorePostComments restores the comments the saga archived. Comments that have gone
+// since, e.g. with their commenter's account, are skipped
+func restorePostComments(
+	ctx context.Context,
+	commentRepo domain.CommentRepository,
+	state *services.SagaState,
+) error {
+	for _, commentID := range splitSagaList(state.Data[archivedCommentsKey]) {
+		comment, err := commentRepo.FindByID(domain.NewCommentID(commentID))
+		if errors.Is(err, domain.ErrCommentNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !comment.Archived() {
+			continue
+		}
+		comment.Restore()
+
+		// Persist
+		comment.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := commentRepo.Restore(comment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitSagaList(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	return strings.Split(list, ",")
+}
@@ -0,0 +1,317 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/ddd/services"
+
+	"go.uber.org/zap"
+)
+
+// failingNotificationRepository fails to create the given number of notifications
+// before it starts working
+type failingNotificationRepository struct {
+	*memory.NotificationRepository
+	failures int
+}
+
+func (r *failingNotificationRepository) Create(
+	notification *domain.Notification,
+) (*domain.Notification, error) {
+	if r.failures > 0 {
+		r.failures--
+		return nil, errors.New("notifications unavailable")
+	}
+	return r.NotificationRepository.Create(notification)
+}
+
+type postArchivalTest struct {
+	manager       *services.SagaManager
+	store         *dddmemory.InMemorySagaStore
+	dispatcher    ddd.EventDispatcher
+	postRepo      *memory.PostRepository
+	commentRepo   *memory.CommentRepository
+	notifications *failingNotificationRepository
+	post          *domain.Post
+	archived      *domain.PostArchivedEvent
+}
+
+// newPostArchivalTest runs the post archival saga in a manager subscribed to the
+// dispatcher, as the server does, with a post by alice that bob and carol commented on
+func newPostArchivalTest(t *testing.T, config PostArchivalConfig) *postArchivalTest {
+	t.Helper()
+
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	test := &postArchivalTest{
+		store:       dddmemory.NewInMemorySagaStore(),
+		dispatcher:  dddmemory.NewInMemoryEventDispatcher(zap.NewNop().Sugar()),
+		postRepo:    memory.NewPostRepository(outbox),
+		commentRepo: memory.NewCommentRepository(outbox),
+		notifications: &failingNotificationRepository{
+			NotificationRepository: memory.NewNotificationRepository(outbox),
+		},
+	}
+	notificationService := NewNotificationService(
+		test.notifications,
+		test.postRepo,
+		test.commentRepo,
+	)
+
+	test.manager = services.NewSagaManager(test.store, test.dispatcher)
+	test.manager.Register(NewPostArchivalSaga(
+		test.postRepo,
+		test.commentRepo,
+		notificationService,
+		config,
+	))
+	test.manager.Subscribe(test.dispatcher)
+
+	post, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	test.postRepo.Create(post)
+	test.post = post
+
+	for _, commenterID := range []string{"bob", "bob", "carol"} {
+		comment, err := domain.NewComment(post.GetID(), domain.NewUserID(commenterID), "Nice post")
+		if err != nil {
+			t.Fatalf("NewComment() failed: %v", err)
+		}
+		test.commentRepo.Create(comment)
+	}
+
+	return test
+}
+
+// archive archives the post as it's saved and dispatches the event, returning the
+// dispatch's error
+func (test *postArchivalTest) archive(t *testing.T) error {
+	t.Helper()
+
+	post, err := test.postRepo.FindByID(test.post.GetID())
+	if err != nil {
+		t.Fatalf("FindByID() failed: %v", err)
+	}
+	post.Archive()
+	events := post.GetUncommittedEvents()
+	test.postRepo.Archive(post)
+	test.post = post
+
+	for _, event := range events {
+		if archived, ok := event.(*domain.PostArchivedEvent); ok {
+			test.archived = archived
+		}
+		err = errors.Join(err, test.dispatcher.Dispatch(context.Background(), event))
+	}
+	return err
+}
+
+// saga returns the saga run for the post's last archival
+func (test *postArchivalTest) saga(t *testing.T) services.SagaState {
+	t.Helper()
+
+	key := PostArchivalSagaKey(test.archived.PostID, test.archived.ArchivedAt)
+	state, err := test.store.FindByCorrelation(PostArchivalSagaType, key)
+	if err != nil {
+		t.Fatalf("FindByCorrelation() error = %v", err)
+	}
+	return state
+}
+
+func (test *postArchivalTest) postArchived(t *testing.T) bool {
+	t.Helper()
+
+	post, err := test.postRepo.FindByID(test.post.GetID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	return post.ArchivedAt() != nil
+}
+
+func (test *postArchivalTest) archivedComments(t *testing.T) int {
+	t.Helper()
+
+	comments, err := test.commentRepo.FindByPost(test.post.GetID())
+	if err != nil {
+		t.Fatalf("FindByPost() error = %v", err)
+	}
+
+	archived := 0
+	for _, comment := range comments {
+		if comment.Archived() {
+			archived++
+		}
+	}
+	return archived
+}
+
+func (test *postArchivalTest) notified(t *testing.T, userID string) int {
+	t.Helper()
+
+	notifications, err := test.notifications.FindByRecipient(domain.NewUserID(userID), false)
+	if err != nil {
+		t.Fatalf("FindByRecipient() error = %v", err)
+	}
+	return len(notifications)
+}
+
+func TestArchivingPostArchivesCommentsAndNotifiesCommenters(t *testing.T) {
+	test := newPostArchivalTest(t, PostArchivalConfig{MaxAttempts: 3})
+
+	if err := test.archive(t); err != nil {
+		t.Fatalf("archiving the post failed: %v", err)
+	}
+
+	if got := test.saga(t); got.Status != services.SagaStatusCompleted || len(got.CompletedSteps) != 3 {
+		t.Errorf("saga = %+v, want every step completed", got)
+	}
+	if got := test.archivedComments(t); got != 3 {
+		t.Errorf("%d comments archived, want 3", got)
+	}
+
+	// Each commenter hears about it once, however often they commented
+	if bob, carol := test.notified(t, "bob"), test.notified(t, "carol"); bob != 1 || carol != 1 {
+		t.Errorf("bob and carol were notified %d and %d times, want once each", bob, carol)
+	}
+
+	// Delivering the event again doesn't run the finished saga again
+	saga := test.saga(t)
+	if err := test.dispatcher.Dispatch(context.Background(), test.archived); err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+	if got := test.saga(t); got.Version != saga.Version {
+		t.Errorf("saga was saved again for a repeated event")
+	}
+}
+
+func TestPostArchivalRestoresPostAndCommentsWhenNotifyingFails(t *testing.T) {
+	test := newPostArchivalTest(t, PostArchivalConfig{MaxAttempts: 2})
+	test.notifications.failures = 2
+
+	// The first failure fails the event, so the dispatcher can retry it
+	if err := test.archive(t); err == nil {
+		t.Fatal("archiving the post succeeded, want the failed step's error")
+	}
+	if got := test.saga(t); got.Status != services.SagaStatusRunning || got.Attempts != 1 {
+		t.Errorf("saga = %+v, want running after 1 failed attempt", got)
+	}
+
+	// The second uses up the saga's attempts, and it compensates
+	if err := test.manager.HandleEvent(context.Background(), test.archived); err != nil {
+		t.Fatalf("HandleEvent() error = %v", err)
+	}
+
+	got := test.saga(t)
+	if got.Status != services.SagaStatusCompensated ||
+		!got.Compensated(archiveCommentsStep) || !got.Compensated(archivePostStep) {
+		t.Errorf("saga = %+v, want the archived post and comments compensated", got)
+	}
+	if got.LastError == "" {
+		t.Error("saga lost the error it gave up on")
+	}
+	if archived := test.archivedComments(t); archived != 0 {
+		t.Errorf("%d comments still archived, want them restored", archived)
+	}
+	if test.postArchived(t) {
+		t.Error("post is still archived, want it restored")
+	}
+}
+
+func TestArchivingPostAgainAfterItWasRestoredStartsAnotherSaga(t *testing.T) {
+	test := newPostArchivalTest(t, PostArchivalConfig{MaxAttempts: 1})
+	test.notifications.failures = 1
+
+	// With a single attempt, the saga compensates as soon as notifying fails
+	if err := test.archive(t); err != nil {
+		t.Fatalf("archiving the post failed: %v", err)
+	}
+	first := test.saga(t)
+	if first.Status != services.SagaStatusCompensated || test.postArchived(t) {
+		t.Fatalf("saga = %+v, want it compensated and the post restored", first)
+	}
+
+	if err := test.archive(t); err != nil {
+		t.Fatalf("archiving the post again failed: %v", err)
+	}
+
+	second := test.saga(t)
+	if second.ID == first.ID || second.Status != services.SagaStatusCompleted {
+		t.Errorf("saga = %+v, want another saga completed", second)
+	}
+	if !test.postArchived(t) {
+		t.Error("post isn't archived, want it archived")
+	}
+	if got := test.archivedComments(t); got != 3 {
+		t.Errorf("%d comments archived, want 3", got)
+	}
+	if bob, carol := test.notified(t, "bob"), test.notified(t, "carol"); bob != 1 || carol != 1 {
+		t.Errorf("bob and carol were notified %d and %d times, want once each", bob, carol)
+	}
+}
+
+func TestPostArchivalCarriesOnWhenResumed(t *testing.T) {
+	test := newPostArchivalTest(t, PostArchivalConfig{MaxAttempts: 3})
+	test.notifications.failures = 1
+
+	if err := test.archive(t); err == nil {
+		t.Fatal("archiving the post succeeded, want the failed step's error")
+	}
+
+	resumed, err := test.manager.Resume(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if resumed != 1 {
+		t.Errorf("Resume() carried on %d sagas, want 1", resumed)
+	}
+
+	if got := test.saga(t); got.Status != services.SagaStatusCompleted || got.Attempts != 0 {
+		t.Errorf("saga = %+v, want completed", got)
+	}
+	if got := test.archivedComments(t); got != 3 {
+		t.Errorf("%d comments archived, want 3", got)
+	}
+}
+
+func TestPostArchivalIsCompensatedWhenItTimesOut(t *testing.T) {
+	test := newPostArchivalTest(t, PostArchivalConfig{MaxAttempts: 10, Timeout: time.Minute})
+	test.notifications.failures = 1
+
+	if err := test.archive(t); err == nil {
+		t.Fatal("archiving the post succeeded, want the failed step's error")
+	}
+
+	// Nothing has timed out yet
+	if fired, err := test.manager.FireTimeouts(context.Background(), time.Now()); err != nil || fired != 0 {
+		t.Fatalf("FireTimeouts() = %d, %v, want no timeouts", fired, err)
+	}
+
+	fired, err := test.manager.FireTimeouts(context.Background(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("FireTimeouts() error = %v", err)
+	}
+	if fired != 1 {
+		t.Errorf("FireTimeouts() fired %d timeouts, want 1", fired)
+	}
+
+	if got := test.saga(t); got.Status != services.SagaStatusCompensated {
+		t.Errorf("saga = %+v, want compensated", got)
+	}
+	if test.postArchived(t) {
+		t.Error("post is still archived, want it restored")
+	}
+	if got := test.archivedComments(t); got != 0 {
+		t.Errorf("%d comments still archived, want them restored", got)
+	}
+	if got := test.notified(t, "bob"); got != 0 {
+		t.Errorf("bob was notified %d times, want 0", got)
+	}
+}
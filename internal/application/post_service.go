@@ -1,32 +1,36 @@
 package application
 
 import (
+	"context"
 	"errors"
-	"log"
 
 	"blog/internal/domain"
 	"blog/pkg/ddd"
 )
 
 type PostService struct {
-	postRepo        domain.PostRepository
-	userRepo        domain.UserRepository
-	eventDispatcher ddd.EventDispatcher
+	postRepo   domain.PostRepository
+	userRepo   domain.UserRepository
+	summaries  domain.PostSummaryStore
+	authorizer *Authorizer
 }
 
 func NewPostService(
 	postRepo domain.PostRepository,
 	userRepo domain.UserRepository,
-	eventDispatcher ddd.EventDispatcher,
+	summaries domain.PostSummaryStore,
+	authorizer *Authorizer,
 ) *PostService {
 	return &PostService{
-		postRepo:        postRepo,
-		userRepo:        userRepo,
-		eventDispatcher: eventDispatcher,
+		postRepo:   postRepo,
+		userRepo:   userRepo,
+		summaries:  summaries,
+		authorizer: authorizer,
 	}
 }
 
 func (s *PostService) CreatePost(
+	ctx context.Context,
 	authorID string,
 	title string,
 	content string,
@@ -41,6 +45,11 @@ func (s *PostService) CreatePost(
 		return nil, errors.New("author does not exist")
 	}
 
+	// Check that the author may create posts
+	if err := s.authorizer.Authorize(authorID, domain.ActionCreatePost, nil); err != nil {
+		return nil, err
+	}
+
 	// Create the post
 	post, err := domain.NewPost(domainAuthorID, title, content)
 	if err != nil {
@@ -48,35 +57,33 @@ func (s *PostService) CreatePost(
 	}
 
 	// Persist
+	post.SetEventMetadata(ddd.EventMetadataFrom(ctx))
 	if _, err := s.postRepo.Create(post); err != nil {
 		return nil, err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(post); err != nil {
-		return nil, err
-	}
-
 	postDTO := PostDTO{}
 	postDTO.FromDomain(post)
 
 	return &postDTO, nil
 }
 
-func (s *PostService) GetPosts() ([]PostDTO, error) {
-	posts, err := s.postRepo.All()
+// GetPosts returns the summary of every post, most recently active first. Summaries are
+// kept up to date from events, so a change shows in them once the projection has run
+func (s *PostService) GetPosts() ([]PostSummaryDTO, error) {
+	summaries, err := s.summaries.All()
 	if err != nil {
 		return nil, err
 	}
 
-	postDTOs := []PostDTO{}
-	for i := range posts {
-		postDTO := PostDTO{}
-		postDTO.FromDomain(&posts[i])
-		postDTOs = append(postDTOs, postDTO)
+	summaryDTOs := []PostSummaryDTO{}
+	for i := range summaries {
+		summaryDTO := PostSummaryDTO{}
+		summaryDTO.FromDomain(&summaries[i])
+		summaryDTOs = append(summaryDTOs, summaryDTO)
 	}
 
-	return postDTOs, nil
+	return summaryDTOs, nil
 }
 
 func (s *PostService) GetPost(id string) (*PostDTO, error) {
@@ -93,7 +100,7 @@ func (s *PostService) GetPost(id string) (*PostDTO, error) {
 	return &postDTO, nil
 }
 
-func (s *PostService) UpdatePostTitle(postID string, newTitle string) error {
+func (s *PostService) UpdatePostTitle(ctx context.Context, actorID, postID, newTitle string) error {
 	domainPostID := domain.NewPostID(postID)
 
 	// Check that the post exists
@@ -110,24 +117,27 @@ func (s *PostService) UpdatePostTitle(postID string, newTitle string) error {
 		return err
 	}
 
-	if err := post.EditTitle(newTitle); err != nil {
+	if err := s.authorizer.Authorize(actorID, domain.ActionEditPost, post); err != nil {
 		return err
 	}
 
-	// Persist
-	if err := s.postRepo.UpdateTitle(domainPostID, newTitle); err != nil {
+	if err := post.EditTitle(newTitle); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(post); err != nil {
+	// Persist
+	post.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.postRepo.UpdateTitle(post); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *PostService) UpdatePostContent(postID string, newContent string) error {
+func (s *PostService) UpdatePostContent(
+	ctx context.Context,
+	actorID, postID, newContent string,
+) error {
 	domainPostID := domain.NewPostID(postID)
 
 	// Check that the post exists
@@ -144,24 +154,24 @@ func (s *PostService) UpdatePostContent(postID string, newContent string) error
 		return err
 	}
 
-	if err := post.EditContent(newContent); err != nil {
+	if err := s.authorizer.Authorize(actorID, domain.ActionEditPost, post); err != nil {
 		return err
 	}
 
-	// Persist
-	if err := s.postRepo.UpdateContent(domainPostID, newContent); err != nil {
+	if err := post.EditContent(newContent); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(post); err != nil {
+	// Persist
+	post.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.postRepo.UpdateContent(post); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *PostService) ArchivePost(postID string) error {
+func (s *PostService) ArchivePost(ctx context.Context, actorID, postID string) error {
 	domainPostID := domain.NewPostID(postID)
 
 	// Make sure the post exists first
@@ -178,29 +188,17 @@ func (s *PostService) ArchivePost(postID string) error {
 		return err
 	}
 
-	post.Archive()
-
-	// Persist
-	if err := s.postRepo.Archive(domainPostID); err != nil {
+	if err := s.authorizer.Authorize(actorID, domain.ActionArchivePost, post); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(post); err != nil {
+	post.Archive()
+
+	// Persist
+	post.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.postRepo.Archive(post); err != nil {
 		return err
 	}
 
 	return nil
 }
-
-// Helper method to dispatch events for any aggregate with AggregateBase
-func (s *PostService) dispatchAggregateEvents(aggregate ddd.EventAggregate) error {
-	events := aggregate.GetUncommittedEvents()
-	for _, event := range events {
-		if err := s.eventDispatcher.Dispatch(event); err != nil {
-			log.Printf("Failed to dispatch event: %v", err)
-		}
-	}
-	aggregate.MarkEventsAsCommitted()
-	return nil
-}
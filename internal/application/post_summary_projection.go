@@ -0,0 +1,211 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+// PostSummariesProjection is the name the post summaries are rebuilt by
+const PostSummariesProjection = "post_summaries"
+
+// PostSummaryProjection keeps the post summaries the post list is read from up to date
+// from the events of posts, comments, ratings and the posts' authors
+type PostSummaryProjection struct {
+	summaries domain.PostSummaryStore
+	postRepo  domain.PostRepository
+	userRepo  domain.UserRepository
+}
+
+func NewPostSummaryProjection(
+	summaries domain.PostSummaryStore,
+	postRepo domain.PostRepository,
+	userRepo domain.UserRepository,
+) *PostSummaryProjection {
+	return &PostSummaryProjection{
+		summaries: summaries,
+		postRepo:  postRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Name implements ddd.Projection
+func (p *PostSummaryProjection) Name() string {
+	return PostSummariesProjection
+}
+
+// EventTypes implements ddd.Projection
+func (p *PostSummaryProjection) EventTypes() []string {
+	return []string{
+		domain.PostCreatedEventType.String(),
+		domain.PostTitleEditedEventType.String(),
+		domain.PostContentEditedEventType.String(),
+		domain.PostArchivedEventType.String(),
+		domain.PostRestoredEventType.String(),
+		domain.CommentCreatedEventType.String(),
+		domain.CommentArchivedEventType.String(),
+		domain.CommentRestoredEventType.String(),
+		domain.RatingCreatedEventType.String(),
+		domain.RatingChangedEventType.String(),
+		domain.RatingRemovedEventType.String(),
+		domain.UserUsernameChangedEventType.String(),
+		domain.UserAnonymisedEventType.String(),
+	}
+}
+
+// Reset implements ddd.Projection
+func (p *PostSummaryProjection) Reset(ctx context.Context) (int64, error) {
+	return p.summaries.Reset()
+}
+
+// Project implements ddd.Projection. Comments, ratings and edits on posts that have no
+// summary, because they've been deleted along with their authors, are skipped
+func (p *PostSummaryProjection) Project(ctx context.Context, event ddd.DomainEvent) error {
+	switch e := event.(type) {
+	case *domain.PostCreatedEvent:
+		return p.projectPostCreated(e)
+	case *domain.PostTitleEditedEvent:
+		return p.update(e.PostID, func(summary *domain.PostSummary) {
+			summary.Title = e.NewTitle
+			summary.Touch(e.OccurredOn())
+		})
+	case *domain.PostContentEditedEvent:
+		return p.update(e.PostID, func(summary *domain.PostSummary) {
+			summary.Touch(e.OccurredOn())
+		})
+	case *domain.PostArchivedEvent:
+		return p.update(e.PostID, func(summary *domain.PostSummary) {
+			archivedAt := e.ArchivedAt
+			summary.ArchivedAt = &archivedAt
+		})
+	case *domain.PostRestoredEvent:
+		return p.update(e.PostID, func(summary *domain.PostSummary) {
+			summary.ArchivedAt = nil
+		})
+	case *domain.CommentCreatedEvent:
+		err := p.summaries.SaveComment(domain.PostSummaryComment{
+			CommentID:   e.CommentID,
+			PostID:      e.PostID,
+			CommenterID: e.CommenterID,
+		})
+		if err != nil {
+			return err
+		}
+		return p.touch(e.PostID, e.OccurredOn())
+	case *domain.CommentArchivedEvent:
+		return p.archiveComment(e.CommentID, true)
+	case *domain.CommentRestoredEvent:
+		return p.archiveComment(e.CommentID, false)
+	case *domain.RatingCreatedEvent:
+		err := p.summaries.SaveRating(domain.PostSummaryRating{
+			RatingID:   e.RatingID,
+			PostID:     e.PostID,
+			UserID:     e.UserID,
+			RatingType: e.RatingType,
+		})
+		if err != nil {
+			return err
+		}
+		return p.touch(e.PostID, e.OccurredOn())
+	case *domain.RatingChangedEvent:
+		rating, err := p.summaries.FindRating(e.RatingID)
+		if errors.Is(err, domain.ErrRatingNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rating.RatingType = e.NewRatingType
+		if err := p.summaries.SaveRating(*rating); err != nil {
+			return err
+		}
+		return p.touch(rating.PostID, e.OccurredOn())
+	case *domain.RatingRemovedEvent:
+		return p.summaries.DeleteRating(e.RatingID)
+	case *domain.UserUsernameChangedEvent:
+		return p.summaries.RenameAuthor(e.UserID, e.NewUsername)
+	case *domain.UserAnonymisedEvent:
+		// Removing an account's content doesn't raise events for each post, comment and
+		// rating removed
+		if e.ContentRemoved {
+			if err := p.summaries.DeleteByUser(e.UserID); err != nil {
+				return err
+			}
+		}
+		return p.summaries.RenameAuthor(e.UserID, e.Username)
+	default:
+		return errors.New("invalid event type")
+	}
+}
+
+// projectPostCreated adds the post's summary. The event doesn't say who wrote the post,
+// so the author is looked up, and the author's username is the one they have now, to be
+// brought up to date by any later username changes
+func (p *PostSummaryProjection) projectPostCreated(e *domain.PostCreatedEvent) error {
+	post, err := p.postRepo.FindByID(e.PostID)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	username := ""
+	author, err := p.userRepo.FindByID(post.AuthorID())
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	if author != nil {
+		username = author.Username()
+	}
+
+	return p.summaries.Save(&domain.PostSummary{
+		PostID:         e.PostID,
+		AuthorID:       post.AuthorID(),
+		AuthorUsername: username,
+		Title:          e.Title,
+		CreatedAt:      e.CreatedAt,
+		LastActivityAt: e.CreatedAt,
+		ArchivedAt:     e.ArchivedAt,
+	})
+}
+
+func (p *PostSummaryProjection) archiveComment(commentID domain.CommentID, archived bool) error {
+	comment, err := p.summaries.FindComment(commentID)
+	if errors.Is(err, domain.ErrCommentNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	comment.Archived = archived
+	return p.summaries.SaveComment(*comment)
+}
+
+// touch moves the post's last activity on to the given time
+func (p *PostSummaryProjection) touch(postID domain.PostID, at time.Time) error {
+	return p.update(postID, func(summary *domain.PostSummary) {
+		summary.Touch(at)
+	})
+}
+
+func (p *PostSummaryProjection) update(
+	postID domain.PostID,
+	fn func(summary *domain.PostSummary),
+) error {
+	summary, err := p.summaries.FindByID(postID)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fn(summary)
+	return p.summaries.Save(summary)
+}
@@ -0,0 +1,216 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type postSummaryTest struct {
+	log       *dddmemory.InMemoryEventLog
+	projector *ddd.Projector
+	summaries *memory.PostSummaryStore
+	postRepo  *memory.PostRepository
+	users     map[string]domain.UserID
+}
+
+// newPostSummaryTest projects the post summaries from an event log, as the server does
+// from the outbox, for the users bob and carol
+func newPostSummaryTest(t *testing.T) *postSummaryTest {
+	t.Helper()
+
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	test := &postSummaryTest{
+		log:       dddmemory.NewInMemoryEventLog(),
+		summaries: memory.NewPostSummaryStore(),
+		postRepo:  memory.NewPostRepository(outbox),
+		users:     map[string]domain.UserID{},
+	}
+
+	userRepo := memory.NewUserRepository(outbox)
+	for _, username := range []string{"bob", "carol"} {
+		user, err := domain.NewUser(
+			username+"@example.com",
+			username,
+			"hash",
+			"",
+			[]domain.UserRole{domain.UserRoleAuthor},
+		)
+		if err != nil {
+			t.Fatalf("NewUser() failed: %v", err)
+		}
+		userRepo.Create(user)
+		test.users[username] = user.GetID()
+	}
+
+	// A batch size of 2 makes the projector read the log in several batches
+	test.projector = ddd.NewProjector(test.log, dddmemory.NewInMemoryCheckpointStore(), 2)
+	test.projector.Register(NewPostSummaryProjection(test.summaries, test.postRepo, userRepo))
+
+	return test
+}
+
+// record adds the events the aggregate has raised to the log
+func (test *postSummaryTest) record(t *testing.T, id string, aggregate ddd.EventAggregate) {
+	t.Helper()
+
+	if err := test.log.Append(id, aggregate.GetUncommittedEvents()...); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	aggregate.MarkEventsAsCommitted()
+}
+
+func (test *postSummaryTest) run(t *testing.T) int {
+	t.Helper()
+
+	projected, err := test.projector.Run(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	return projected
+}
+
+func (test *postSummaryTest) summary(t *testing.T, postID domain.PostID) *domain.PostSummary {
+	t.Helper()
+
+	summary, err := test.summaries.FindByID(postID)
+	if err != nil {
+		t.Fatalf("FindByID() failed: %v", err)
+	}
+	return summary
+}
+
+// discussedPost records a post by bob with two comments by carol, one of them archived,
+// and a like from each of them, one first given as a dislike
+func (test *postSummaryTest) discussedPost(t *testing.T) domain.PostID {
+	t.Helper()
+
+	post, err := domain.NewPost(test.users["bob"], "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	test.record(t, post.GetID().String(), post)
+	test.postRepo.Create(post)
+
+	for _, content := range []string{"Nice post", "Spam"} {
+		comment, err := domain.NewComment(post.GetID(), test.users["carol"], content)
+		if err != nil {
+			t.Fatalf("NewComment() failed: %v", err)
+		}
+		if content == "Spam" {
+			comment.Archive()
+		}
+		test.record(t, comment.GetID().String(), comment)
+	}
+
+	like := domain.NewRating(post.GetID(), test.users["carol"], domain.RatingTypeLike)
+	test.record(t, like.GetID().String(), like)
+
+	dislike := domain.NewRating(post.GetID(), test.users["bob"], domain.RatingTypeDislike)
+	dislike.ChangeRating(domain.RatingTypeLike)
+	test.record(t, dislike.GetID().String(), dislike)
+
+	post.EditTitle("New title")
+	test.record(t, post.GetID().String(), post)
+
+	return post.GetID()
+}
+
+func TestPostSummariesCountCommentsAndRatings(t *testing.T) {
+	test := newPostSummaryTest(t)
+	postID := test.discussedPost(t)
+
+	test.run(t)
+	summary := test.summary(t, postID)
+	if summary.Title != "New title" || summary.AuthorUsername != "bob" {
+		t.Errorf("summary is of %q by %q, want \"New title\" by \"bob\"",
+			summary.Title, summary.AuthorUsername)
+	}
+	if summary.CommentCount != 1 || summary.Likes != 2 || summary.Dislikes != 0 {
+		t.Errorf("summary counts %d comments, %d likes and %d dislikes, want 1, 2 and 0",
+			summary.CommentCount, summary.Likes, summary.Dislikes)
+	}
+
+	// Renaming the author renames them on their posts
+	test.log.Append(
+		test.users["bob"].String(),
+		domain.NewUserUsernameChangedEvent(test.users["bob"], "bob", "robert"),
+	)
+	test.run(t)
+	if got := test.summary(t, postID).AuthorUsername; got != "robert" {
+		t.Errorf("summary is by %q, want \"robert\"", got)
+	}
+}
+
+func TestRebuildingPostSummariesReplaysTheLog(t *testing.T) {
+	test := newPostSummaryTest(t)
+	postID := test.discussedPost(t)
+	test.run(t)
+	want := *test.summary(t, postID)
+
+	// Running again projects nothing, as the checkpoint is at the end of the log
+	if projected := test.run(t); projected != 0 {
+		t.Errorf("Run() projected %d events again, want 0", projected)
+	}
+
+	if _, err := test.summaries.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	projected, err := test.projector.Rebuild(
+		context.Background(),
+		PostSummariesProjection,
+		time.Now(),
+	)
+	if err != nil {
+		t.Fatalf("Rebuild() failed: %v", err)
+	}
+	if projected != 8 {
+		t.Errorf("Rebuild() projected %d events, want 8", projected)
+	}
+	if got := *test.summary(t, postID); got != want {
+		t.Errorf("rebuilt summary = %+v, want %+v", got, want)
+	}
+
+	_, err = test.projector.Rebuild(context.Background(), "posts", time.Now())
+	if !errors.Is(err, ddd.ErrProjectionNotFound) {
+		t.Errorf("Rebuild() of an unknown projection error = %v, want %v",
+			err, ddd.ErrProjectionNotFound)
+	}
+}
+
+func TestRemovingAnAccountsContentRemovesItFromSummaries(t *testing.T) {
+	test := newPostSummaryTest(t)
+	postID := test.discussedPost(t)
+
+	test.log.Append(
+		test.users["carol"].String(),
+		domain.NewUserAnonymisedEvent(test.users["carol"], "deleted-user-1234", true),
+	)
+	test.run(t)
+
+	summary := test.summary(t, postID)
+	if summary.CommentCount != 0 || summary.Likes != 1 {
+		t.Errorf("summary counts %d comments and %d likes, want 0 and 1",
+			summary.CommentCount, summary.Likes)
+	}
+
+	test.log.Append(
+		test.users["bob"].String(),
+		domain.NewUserAnonymisedEvent(test.users["bob"], "deleted-user-5678", true),
+	)
+	test.run(t)
+
+	summaries, err := test.summaries.All()
+	if err != nil {
+		t.Fatalf("All() failed: %v", err)
+	}
+	if len(summaries) != 0 {
+		t.Errorf("%d summaries are left, want 0", len(summaries))
+	}
+}
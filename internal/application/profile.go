@@ -0,0 +1,215 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"slices"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+// ProfileDTO is the public view of a user, shown on their profile page
+type ProfileDTO struct {
+	UserID         string           `json:"user_id"`
+	Username       string           `json:"username"`
+	DisplayName    string           `json:"display_name"`
+	Description    string           `json:"description"`
+	Location       string           `json:"location"`
+	Links          []ProfileLinkDTO `json:"links"`
+	AvatarVersion  string           `json:"-"`
+	JoinDate       time.Time        `json:"join_date"`
+	PostCount      int              `json:"post_count"`
+	CommentCount   int              `json:"comment_count"`
+	FollowerCount  int              `json:"follower_count"`
+	FollowingCount int              `json:"following_count"`
+	Posts          []PostDTO        `json:"posts"`
+	Comments       []CommentDTO     `json:"comments"`
+}
+
+// GetProfile returns the user's public profile with their published posts and comments,
+// newest first. Accounts waiting to be deleted are hidden
+func (s *UserService) GetProfile(username string) (*ProfileDTO, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDeactivated() {
+		return nil, domain.ErrUserNotFound
+	}
+
+	posts, err := s.postRepo.FindByAuthor(user.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.FindByUser(user.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	userDTO := UserDTO{}
+	userDTO.FromDomain(user)
+	if err := s.fillFollowCounts(&userDTO); err != nil {
+		return nil, err
+	}
+
+	profile := ProfileDTO{
+		UserID:         userDTO.ID,
+		Username:       userDTO.Username,
+		DisplayName:    userDTO.DisplayName,
+		Description:    userDTO.Description,
+		Location:       userDTO.Location,
+		Links:          userDTO.Links,
+		AvatarVersion:  userDTO.AvatarVersion,
+		JoinDate:       userDTO.JoinDate,
+		FollowerCount:  userDTO.FollowerCount,
+		FollowingCount: userDTO.FollowingCount,
+		Posts:          []PostDTO{},
+		Comments:       []CommentDTO{},
+	}
+
+	for i := range posts {
+		if posts[i].Archived() {
+			continue
+		}
+		postDTO := PostDTO{}
+		postDTO.FromDomain(&posts[i])
+		profile.Posts = append(profile.Posts, postDTO)
+	}
+
+	for i := range comments {
+		if comments[i].Archived() {
+			continue
+		}
+		commentDTO := CommentDTO{}
+		commentDTO.FromDomain(&comments[i])
+		profile.Comments = append(profile.Comments, commentDTO)
+	}
+
+	slices.SortFunc(profile.Posts, func(a, b PostDTO) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	slices.SortFunc(profile.Comments, func(a, b CommentDTO) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	profile.PostCount = len(profile.Posts)
+	profile.CommentCount = len(profile.Comments)
+
+	return &profile, nil
+}
+
+func (s *UserService) UpdateProfile(
+	ctx context.Context,
+	userID, displayName, description, location string,
+	links []ProfileLinkDTO,
+) error {
+	domainUserID := domain.NewUserID(userID)
+
+	// Convert parameters to domain variables
+	domainLinks := []domain.ProfileLink{}
+	for _, link := range links {
+		domainLink, err := domain.NewProfileLink(domain.LinkKind(link.Kind), link.URL)
+		if err != nil {
+			return err
+		}
+		domainLinks = append(domainLinks, domainLink)
+	}
+
+	profile, err := domain.NewProfile(displayName, description, location, domainLinks)
+	if err != nil {
+		return err
+	}
+
+	// Get the user and update their profile
+	user, err := s.userRepo.FindByID(domainUserID)
+	if err != nil {
+		return err
+	}
+
+	user.UpdateProfile(profile)
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdateProfile(user); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetAvatar stores the image as the user's new avatar, replacing the old one
+func (s *UserService) SetAvatar(
+	ctx context.Context, userID string, image io.Reader,
+) (*UserDTO, error) {
+	domainUserID := domain.NewUserID(userID)
+
+	user, err := s.userRepo.FindByID(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := newAvatarVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.avatarStore.Save(domainUserID, version, image); err != nil {
+		return nil, err
+	}
+
+	user.SetAvatar(version)
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdateAvatar(user); err != nil {
+		return nil, err
+	}
+
+	userDTO := UserDTO{}
+	userDTO.FromDomain(user)
+	return &userDTO, nil
+}
+
+func (s *UserService) RemoveAvatar(ctx context.Context, userID string) error {
+	domainUserID := domain.NewUserID(userID)
+
+	user, err := s.userRepo.FindByID(domainUserID)
+	if err != nil {
+		return err
+	}
+
+	if err := user.RemoveAvatar(); err != nil {
+		return err
+	}
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdateAvatar(user); err != nil {
+		return err
+	}
+
+	if err := s.avatarStore.Delete(domainUserID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// OpenAvatar returns one size of a stored avatar. The caller must close it
+func (s *UserService) OpenAvatar(userID, version string, size int) (io.ReadCloser, error) {
+	return s.avatarStore.Open(domain.NewUserID(userID), version, size)
+}
+
+func newAvatarVersion() (string, error) {
+	version := make([]byte, 8)
+	if _, err := rand.Read(version); err != nil {
+		return "", errors.New("couldn't generate an avatar version")
+	}
+	return hex.EncodeToString(version), nil
+}
@@ -0,0 +1,63 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+// ProjectionService lets admins see how far the read models have got through the event
+// log, and rebuild them from it
+type ProjectionService struct {
+	projector *ddd.Projector
+}
+
+func NewProjectionService(projector *ddd.Projector) *ProjectionService {
+	return &ProjectionService{
+		projector: projector,
+	}
+}
+
+// GetProjections returns each projection's checkpoint, and how far behind the event log
+// it is
+func (s *ProjectionService) GetProjections() ([]ProjectionDTO, error) {
+	statuses, err := s.projector.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	projectionDTOs := []ProjectionDTO{}
+	for _, status := range statuses {
+		projectionDTO := ProjectionDTO{}
+		projectionDTO.FromProjectionStatus(status)
+		projectionDTOs = append(projectionDTOs, projectionDTO)
+	}
+	return projectionDTOs, nil
+}
+
+// RebuildProjection resets the projection's read model and projects the events in the
+// log again. A projection that fails part way is left partly rebuilt, and carries on
+// from where it failed on the next scheduled run
+func (s *ProjectionService) RebuildProjection(
+	ctx context.Context,
+	name string,
+) (*ProjectionRebuildDTO, error) {
+	projected, err := s.projector.Rebuild(ctx, name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.projector.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	rebuildDTO := ProjectionRebuildDTO{Projected: projected}
+	for _, status := range statuses {
+		if status.Name == name {
+			rebuildDTO.Projection.FromProjectionStatus(status)
+		}
+	}
+	return &rebuildDTO, nil
+}
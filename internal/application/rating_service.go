@@ -1,31 +1,31 @@
 package application
 
 import (
+	"context"
 	"errors"
-	"log"
 
 	"blog/internal/domain"
 	"blog/pkg/ddd"
 )
 
 type RatingService struct {
-	ratingRepo      domain.RatingRepository
-	userRepo        domain.UserRepository
-	postRepo        domain.PostRepository
-	eventDispatcher ddd.EventDispatcher
+	ratingRepo domain.RatingRepository
+	userRepo   domain.UserRepository
+	postRepo   domain.PostRepository
+	authorizer *Authorizer
 }
 
 func NewRatingService(
 	ratingRepo domain.RatingRepository,
 	userRepo domain.UserRepository,
 	postRepo domain.PostRepository,
-	eventDispatcher ddd.EventDispatcher,
+	authorizer *Authorizer,
 ) *RatingService {
 	return &RatingService{
-		ratingRepo:      ratingRepo,
-		userRepo:        userRepo,
-		postRepo:        postRepo,
-		eventDispatcher: eventDispatcher,
+		ratingRepo: ratingRepo,
+		userRepo:   userRepo,
+		postRepo:   postRepo,
+		authorizer: authorizer,
 	}
 }
 
@@ -62,6 +62,7 @@ func (s RatingService) GetRating(ratingID string) (*RatingDTO, error) {
 }
 
 func (s *RatingService) CreateRating(
+	ctx context.Context,
 	postID string,
 	userID string,
 	ratingType string,
@@ -85,9 +86,17 @@ func (s *RatingService) CreateRating(
 		return nil, errors.New("user does not exist")
 	}
 
+	// Check that the user may rate posts
+	if err := s.authorizer.Authorize(userID, domain.ActionCreateRating, nil); err != nil {
+		return nil, err
+	}
+
 	// Check if rating already exists for this user/post combination
-	if exists, err := s.ratingRepo.ExistsOnPostByUser(domainPostID, domainUserID); !exists ||
+	if exists, err := s.ratingRepo.ExistsOnPostByUser(domainPostID, domainUserID); exists ||
 		err != nil {
+		if err != nil {
+			return nil, err
+		}
 		return nil, errors.New("rating already exists for this user and post")
 	}
 
@@ -95,15 +104,11 @@ func (s *RatingService) CreateRating(
 	rating := domain.NewRating(domainPostID, domainUserID, domain.RatingType(ratingType))
 
 	// Persist
+	rating.SetEventMetadata(ddd.EventMetadataFrom(ctx))
 	if _, err := s.ratingRepo.Create(rating); err != nil {
 		return nil, err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(rating); err != nil {
-		return nil, err
-	}
-
 	ratingDTO := RatingDTO{}
 	ratingDTO.FromDomain(rating)
 
@@ -111,6 +116,8 @@ func (s *RatingService) CreateRating(
 }
 
 func (s *RatingService) UpdateRating(
+	ctx context.Context,
+	actorID string,
 	ratingID string,
 	newRatingType string,
 ) error {
@@ -131,15 +138,15 @@ func (s *RatingService) UpdateRating(
 		return err
 	}
 
-	rating.ChangeRating(domainRatingType)
-
-	// Persist
-	if err := s.ratingRepo.ChangeRating(domainRatingID, domainRatingType); err != nil {
+	if err := s.authorizer.Authorize(actorID, domain.ActionChangeRating, rating); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(rating); err != nil {
+	rating.ChangeRating(domainRatingType)
+
+	// Persist
+	rating.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.ratingRepo.ChangeRating(rating); err != nil {
 		return err
 	}
 
@@ -147,6 +154,8 @@ func (s *RatingService) UpdateRating(
 }
 
 func (s *RatingService) RemoveRating(
+	ctx context.Context,
+	actorID string,
 	ratingID string,
 ) error {
 	domainRatingID := domain.NewRatingID(ratingID)
@@ -165,29 +174,17 @@ func (s *RatingService) RemoveRating(
 		return err
 	}
 
-	rating.RemoveRating()
-
-	// Persist (delete the rating)
-	if err := s.ratingRepo.RemoveRating(domainRatingID); err != nil {
+	if err := s.authorizer.Authorize(actorID, domain.ActionRemoveRating, rating); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(rating); err != nil {
+	rating.RemoveRating()
+
+	// Persist (delete the rating)
+	rating.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.ratingRepo.RemoveRating(rating); err != nil {
 		return err
 	}
 
 	return nil
 }
-
-// Helper method to dispatch events for any aggregate with AggregateBase
-func (s *RatingService) dispatchAggregateEvents(aggregate ddd.EventAggregate) error {
-	events := aggregate.GetUncommittedEvents()
-	for _, event := range events {
-		if err := s.eventDispatcher.Dispatch(event); err != nil {
-			log.Printf("Failed to dispatch event: %v", err)
-		}
-	}
-	aggregate.MarkEventsAsCommitted()
-	return nil
-}
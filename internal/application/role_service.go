@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type RoleService struct {
+	roleRepo domain.RoleRepository
+}
+
+func NewRoleService(
+	roleRepo domain.RoleRepository,
+) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+	}
+}
+
+func (s *RoleService) GetRoles() ([]RoleDTO, error) {
+	roles, err := s.roleRepo.All()
+	if err != nil {
+		return nil, err
+	}
+
+	roleDTOs := []RoleDTO{}
+	for i := range roles {
+		roleDTO := RoleDTO{}
+		roleDTO.FromDomain(&roles[i])
+		roleDTOs = append(roleDTOs, roleDTO)
+	}
+
+	return roleDTOs, nil
+}
+
+func (s *RoleService) GetRole(name string) (*RoleDTO, error) {
+	role, err := s.roleRepo.FindByName(domain.UserRole(name))
+	if err != nil {
+		return nil, err
+	}
+
+	roleDTO := RoleDTO{}
+	roleDTO.FromDomain(role)
+
+	return &roleDTO, nil
+}
+
+// GetPermissions returns every permission that can be granted to a role
+func (s *RoleService) GetPermissions() []string {
+	permissions := []string{}
+	for _, permission := range domain.Permissions() {
+		permissions = append(permissions, permission.String())
+	}
+	return permissions
+}
+
+func (s *RoleService) CreateRole(
+	ctx context.Context,
+	name, description string,
+	permissions []string,
+) (*RoleDTO, error) {
+	domainName := domain.UserRole(name)
+
+	// Make sure the name isn't taken
+	if exists, err := s.roleRepo.Exists(domainName); exists || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrRoleAlreadyExists
+	}
+
+	// Create the role
+	role, err := domain.NewRole(domainName, description, toDomainPermissions(permissions))
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist
+	role.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if _, err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+
+	roleDTO := RoleDTO{}
+	roleDTO.FromDomain(role)
+
+	return &roleDTO, nil
+}
+
+func (s *RoleService) SetRolePermissions(
+	ctx context.Context, name string, permissions []string,
+) error {
+	// Get the role and update its permissions
+	role, err := s.roleRepo.FindByName(domain.UserRole(name))
+	if err != nil {
+		return err
+	}
+
+	if err := role.SetPermissions(toDomainPermissions(permissions)); err != nil {
+		return err
+	}
+
+	// Persist
+	role.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.roleRepo.UpdatePermissions(role); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *RoleService) DeleteRole(ctx context.Context, name string) error {
+	role, err := s.roleRepo.FindByName(domain.UserRole(name))
+	if err != nil {
+		return err
+	}
+
+	// Roles can only be deleted once nobody has them
+	if inUse, err := s.roleRepo.InUse(role.Name()); inUse || err != nil {
+		if err != nil {
+			return err
+		}
+		return domain.ErrRoleInUse
+	}
+
+	if err := role.Delete(); err != nil {
+		return err
+	}
+
+	// Persist
+	role.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.roleRepo.Delete(role); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func toDomainPermissions(permissions []string) []domain.Permission {
+	domainPermissions := []domain.Permission{}
+	for _, permission := range permissions {
+		domainPermissions = append(domainPermissions, domain.Permission(permission))
+	}
+	return domainPermissions
+}
@@ -0,0 +1,53 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+func TestRoleChangesAreTracedToTheRequestThatMadeThem(t *testing.T) {
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	service := NewRoleService(memory.NewRoleRepository(memory.NewUserRepository(outbox), outbox))
+
+	// As if by a request with the ID "request" made by "admin"
+	ctx := ddd.WithEventMetadata(context.Background(), ddd.EventMetadata{
+		CorrelationID: "request",
+		ActorID:       "admin",
+	})
+	permissions := []string{domain.PermissionCreatePost.String()}
+	if _, err := service.CreateRole(ctx, "MODERATOR", "Moderates posts", permissions); err != nil {
+		t.Fatalf("CreateRole() failed: %v", err)
+	}
+	permissions = append(permissions, domain.PermissionEditAnyPost.String())
+	if err := service.SetRolePermissions(ctx, "MODERATOR", permissions); err != nil {
+		t.Fatalf("SetRolePermissions() failed: %v", err)
+	}
+	if err := service.DeleteRole(ctx, "MODERATOR"); err != nil {
+		t.Fatalf("DeleteRole() failed: %v", err)
+	}
+
+	eventTypes := map[string]bool{}
+	for _, message := range outbox.Messages() {
+		eventTypes[message.EventType] = true
+
+		metadata := message.Metadata
+		if metadata.CorrelationID != "request" || metadata.CausationID != "request" ||
+			metadata.ActorID != "admin" || metadata.EventID == "" {
+			t.Errorf("%s metadata = %+v, want the request's", message.EventType, metadata)
+		}
+	}
+	for _, eventType := range []domain.EventType{
+		domain.RoleCreatedEventType,
+		domain.RolePermissionsChangedEventType,
+		domain.RoleDeletedEventType,
+	} {
+		if !eventTypes[eventType.String()] {
+			t.Errorf("no %s event was added to the outbox", eventType)
+		}
+	}
+}
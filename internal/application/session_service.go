@@ -0,0 +1,172 @@
+package application
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"blog/internal/domain"
+)
+
+type SessionService struct {
+	sessionRepo domain.SessionRepository
+	userRepo    domain.UserRepository
+}
+
+func NewSessionService(
+	sessionRepo domain.SessionRepository,
+	userRepo domain.UserRepository,
+) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// StartSession records the device metadata for a freshly authenticated session token
+func (s *SessionService) StartSession(
+	token, userID, userAgent, ipAddress string,
+	expiresAt time.Time,
+) (*SessionDTO, error) {
+	return s.StartImpersonationSession(token, userID, "", userAgent, ipAddress, expiresAt)
+}
+
+// StartImpersonationSession records a session token in which the impersonator acts as
+// the user. An empty impersonatorID starts an ordinary session
+func (s *SessionService) StartImpersonationSession(
+	token, userID, impersonatorID, userAgent, ipAddress string,
+	expiresAt time.Time,
+) (*SessionDTO, error) {
+	domainUserID := domain.NewUserID(userID)
+
+	// Ensure the user exists
+	if exists, err := s.userRepo.Exists(domainUserID); !exists || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("user doesn't exist")
+	}
+
+	session := domain.NewImpersonationSession(
+		domainUserID,
+		domain.NewUserID(impersonatorID),
+		userAgent,
+		describeDevice(userAgent),
+		ipAddress,
+		expiresAt,
+	)
+
+	// Persist
+	if _, err := s.sessionRepo.Create(token, session); err != nil {
+		return nil, err
+	}
+
+	sessionDTO := SessionDTO{}
+	sessionDTO.FromDomain(session)
+
+	return &sessionDTO, nil
+}
+
+func (s *SessionService) GetSessionByToken(token string) (*SessionDTO, error) {
+	session, err := s.sessionRepo.FindByToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionDTO := SessionDTO{}
+	sessionDTO.FromDomain(session)
+
+	return &sessionDTO, nil
+}
+
+// GetUserSessions lists the active sessions of a user. currentToken is used to
+// flag the session making the request, and may be empty.
+func (s *SessionService) GetUserSessions(userID, currentToken string) ([]SessionDTO, error) {
+	domainUserID := domain.NewUserID(userID)
+
+	sessions, err := s.sessionRepo.FindByUser(domainUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentID domain.SessionID
+	if currentToken != "" {
+		if current, err := s.sessionRepo.FindByToken(currentToken); err == nil {
+			currentID = current.ID()
+		}
+	}
+
+	sessionDTOs := []SessionDTO{}
+	for i := range sessions {
+		sessionDTO := SessionDTO{}
+		sessionDTO.FromDomain(&sessions[i])
+		sessionDTO.Current = sessions[i].ID() == currentID
+		sessionDTOs = append(sessionDTOs, sessionDTO)
+	}
+
+	return sessionDTOs, nil
+}
+
+// RevokeSession ends one of the user's sessions. Sessions belonging to another
+// user are reported as not found.
+func (s *SessionService) RevokeSession(userID, sessionID string) error {
+	domainUserID := domain.NewUserID(userID)
+	domainSessionID := domain.NewSessionID(sessionID)
+
+	session, err := s.sessionRepo.FindByID(domainSessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID() != domainUserID {
+		return domain.ErrSessionNotFound
+	}
+
+	return s.sessionRepo.Revoke(domainSessionID)
+}
+
+func (s *SessionService) RevokeAllSessions(userID string) error {
+	domainUserID := domain.NewUserID(userID)
+	return s.sessionRepo.RevokeAllForUser(domainUserID)
+}
+
+// describeDevice gives a short, human readable summary of a user agent, e.g.
+// "Firefox on Linux"
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	os := "unknown OS"
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os x") || strings.Contains(ua, "macintosh"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	return browser + " on " + os
+}
@@ -0,0 +1,118 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/memory"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+// newTestSessionService starts the sessions laptop and phone for alice and desktop for
+// bob, returning their IDs by token
+func newTestSessionService(t *testing.T) (*SessionService, map[string]string, map[string]string) {
+	t.Helper()
+
+	userRepo := memory.NewUserRepository(dddmemory.NewInMemoryOutboxStore())
+	service := NewSessionService(
+		memory.NewSessionRepository(memory.NewSessionStore()),
+		userRepo,
+	)
+
+	users := map[string]string{}
+	for _, username := range []string{"alice", "bob"} {
+		user, err := domain.NewUser(
+			username+"@example.com",
+			username,
+			"hash",
+			"",
+			[]domain.UserRole{domain.UserRoleAuthor},
+		)
+		if err != nil {
+			t.Fatalf("NewUser() failed: %v", err)
+		}
+		userRepo.Create(user)
+		users[username] = user.GetID().String()
+	}
+
+	sessions := map[string]string{}
+	for token, username := range map[string]string{
+		"laptop":  "alice",
+		"phone":   "alice",
+		"desktop": "bob",
+	} {
+		session, err := service.StartSession(
+			token,
+			users[username],
+			"curl/8.0",
+			"10.0.0.1",
+			time.Now().Add(time.Hour),
+		)
+		if err != nil {
+			t.Fatalf("StartSession() failed: %v", err)
+		}
+		sessions[token] = session.ID
+	}
+
+	return service, users, sessions
+}
+
+func TestRevokingOneSession(t *testing.T) {
+	service, users, sessions := newTestSessionService(t)
+
+	// A session can't be revoked through another user
+	err := service.RevokeSession(users["bob"], sessions["laptop"])
+	if !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("RevokeSession() of another user's session error = %v, want %v",
+			err, domain.ErrSessionNotFound)
+	}
+
+	if err := service.RevokeSession(users["alice"], sessions["laptop"]); err != nil {
+		t.Fatalf("RevokeSession() failed: %v", err)
+	}
+
+	remaining, err := service.GetUserSessions(users["alice"], "phone")
+	if err != nil {
+		t.Fatalf("GetUserSessions() failed: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != sessions["phone"] || !remaining[0].Current {
+		t.Errorf("GetUserSessions() = %+v, want only the current phone session", remaining)
+	}
+}
+
+func TestRevokingAllSessions(t *testing.T) {
+	service, users, _ := newTestSessionService(t)
+
+	if err := service.RevokeAllSessions(users["alice"]); err != nil {
+		t.Fatalf("RevokeAllSessions() failed: %v", err)
+	}
+
+	for username, want := range map[string]int{"alice": 0, "bob": 1} {
+		sessions, err := service.GetUserSessions(users[username], "")
+		if err != nil {
+			t.Fatalf("GetUserSessions() failed: %v", err)
+		}
+		if len(sessions) != want {
+			t.Errorf("%s has %d sessions, want %d", username, len(sessions), want)
+		}
+	}
+}
+
+func TestListingAnotherUsersSessionsFlagsNoneCurrent(t *testing.T) {
+	service, users, _ := newTestSessionService(t)
+
+	sessions, err := service.GetUserSessions(users["alice"], "")
+	if err != nil {
+		t.Fatalf("GetUserSessions() failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("GetUserSessions() = %d sessions, want 2", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current {
+			t.Errorf("session %s is current, want none", session.ID)
+		}
+	}
+}
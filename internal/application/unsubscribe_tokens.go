@@ -0,0 +1,48 @@
+package application
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"blog/internal/domain"
+)
+
+// signUnsubscribeToken signs the ID for one kind of email, so the link only
+// unsubscribes that ID from that kind of email. The token never expires, as
+// unsubscribe links have to keep working in old emails
+func signUnsubscribeToken(secret []byte, purpose, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id)) + "." +
+		base64.RawURLEncoding.EncodeToString(unsubscribeSignature(secret, purpose, id))
+}
+
+// verifyUnsubscribeToken returns the ID the token was signed for, or
+// ErrInvalidUnsubscribeToken if it wasn't signed with the secret for the purpose
+func verifyUnsubscribeToken(secret []byte, purpose, token string) (string, error) {
+	encodedID, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", domain.ErrInvalidUnsubscribeToken
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(encodedID)
+	if err != nil {
+		return "", domain.ErrInvalidUnsubscribeToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return "", domain.ErrInvalidUnsubscribeToken
+	}
+
+	if !hmac.Equal(signature, unsubscribeSignature(secret, purpose, string(id))) {
+		return "", domain.ErrInvalidUnsubscribeToken
+	}
+
+	return string(id), nil
+}
+
+func unsubscribeSignature(secret []byte, purpose, id string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose + ":" + id))
+	return mac.Sum(nil)
+}
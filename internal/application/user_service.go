@@ -1,32 +1,80 @@
 package application
 
 import (
+	"context"
 	"errors"
-	"log"
+	"fmt"
 	"slices"
+	"time"
 
 	"blog/internal/domain"
 	"blog/pkg/ddd"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
 type UserService struct {
-	userRepo        domain.UserRepository
-	eventDispatcher ddd.EventDispatcher
+	userRepo         domain.UserRepository
+	roleRepo         domain.RoleRepository
+	sessionRepo      domain.SessionRepository
+	postRepo         domain.PostRepository
+	commentRepo      domain.CommentRepository
+	ratingRepo       domain.RatingRepository
+	followRepo       domain.FollowRepository
+	bookmarkRepo     domain.BookmarkRepository
+	folderRepo       domain.BookmarkFolderRepository
+	notificationRepo domain.NotificationRepository
+	digestRepo       domain.DigestSubscriptionRepository
+	avatarStore      domain.AvatarStore
+	loginThrottle    *LoginThrottle
+	passwords        *Passwords
+	deletionConfig   AccountDeletionConfig
+	changeConfig     AccountChangeConfig
+	notifier         AccountNotifier
 }
 
 func NewUserService(
 	userRepo domain.UserRepository,
-	eventDispatcher ddd.EventDispatcher,
+	roleRepo domain.RoleRepository,
+	sessionRepo domain.SessionRepository,
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+	ratingRepo domain.RatingRepository,
+	followRepo domain.FollowRepository,
+	bookmarkRepo domain.BookmarkRepository,
+	folderRepo domain.BookmarkFolderRepository,
+	notificationRepo domain.NotificationRepository,
+	digestRepo domain.DigestSubscriptionRepository,
+	avatarStore domain.AvatarStore,
+	loginThrottle *LoginThrottle,
+	passwords *Passwords,
+	deletionConfig AccountDeletionConfig,
+	changeConfig AccountChangeConfig,
+	notifier AccountNotifier,
 ) *UserService {
 	return &UserService{
-		userRepo:        userRepo,
-		eventDispatcher: eventDispatcher,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		sessionRepo:      sessionRepo,
+		postRepo:         postRepo,
+		commentRepo:      commentRepo,
+		ratingRepo:       ratingRepo,
+		followRepo:       followRepo,
+		bookmarkRepo:     bookmarkRepo,
+		folderRepo:       folderRepo,
+		notificationRepo: notificationRepo,
+		digestRepo:       digestRepo,
+		avatarStore:      avatarStore,
+		loginThrottle:    loginThrottle,
+		passwords:        passwords,
+		deletionConfig:   deletionConfig,
+		changeConfig:     changeConfig,
+		notifier:         notifier,
 	}
 }
 
 func (s *UserService) CreateUser(
+	ctx context.Context,
 	email, password, username string,
 	userRoles []string,
 ) (*UserDTO, error) {
@@ -35,50 +83,46 @@ func (s *UserService) CreateUser(
 		if err != nil {
 			return nil, err
 		}
-		return nil, errors.New("email already in use")
+		return nil, domain.ErrEmailTaken
 	}
 
 	if exists, err := s.userRepo.UsernameExists(username); exists || err != nil {
 		if err != nil {
 			return nil, err
 		}
-		return nil, errors.New("username already in use")
+		return nil, domain.ErrUsernameTaken
 	}
 
-	// Hash the password before passing it into the domain
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	// Check and hash the password before passing it into the domain
+	passwordHash, err := s.passwords.Hash(password, username, email)
 	if err != nil {
 		return nil, err
 	}
 
-	domainUserRoles := []domain.UserRole{}
-	for _, role := range userRoles {
-		domainUserRoles = append(domainUserRoles, domain.UserRole(role))
+	domainUserRoles, err := s.toKnownRoles(userRoles)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create the user
-	user, err := domain.NewUser(email, username, string(passwordHash), "", domainUserRoles)
+	user, err := domain.NewUser(email, username, passwordHash, "", domainUserRoles)
 	if err != nil {
 		return nil, err
 	}
 
 	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
 	if _, err := s.userRepo.Create(user); err != nil {
 		return nil, err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(user); err != nil {
-		return nil, err
-	}
-
 	userDTO := UserDTO{}
 	userDTO.FromDomain(user)
 
 	return &userDTO, nil
 }
 
-func (s *UserService) SetUserRoles(userID string, userRoles []string) error {
+func (s *UserService) SetUserRoles(ctx context.Context, userID string, userRoles []string) error {
 	domainUserID := domain.NewUserID(userID)
 
 	// Ensure the user exists
@@ -89,10 +133,10 @@ func (s *UserService) SetUserRoles(userID string, userRoles []string) error {
 		return errors.New("user doesn't exist")
 	}
 
-	// Convert parameters to domain variables
-	domainUserRoles := []domain.UserRole{}
-	for _, role := range userRoles {
-		domainUserRoles = append(domainUserRoles, domain.UserRole(role))
+	// Convert parameters to domain variables, rejecting roles that haven't been defined
+	domainUserRoles, err := s.toKnownRoles(userRoles)
+	if err != nil {
+		return err
 	}
 
 	// Get the user
@@ -117,19 +161,15 @@ func (s *UserService) SetUserRoles(userID string, userRoles []string) error {
 	}
 
 	// Persist
-	if err := s.userRepo.UpdateRoles(domainUserID, user.UserRoles()); err != nil {
-		return err
-	}
-
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(user); err != nil {
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdateRoles(user); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *UserService) UpdateDescription(userID, description string) error {
+func (s *UserService) UpdatePassword(ctx context.Context, userID, password string) error {
 	domainUserID := domain.NewUserID(userID)
 
 	// Ensure the user exists
@@ -146,24 +186,150 @@ func (s *UserService) UpdateDescription(userID, description string) error {
 		return err
 	}
 
-	if err := user.UpdateDescription(description); err != nil {
+	// Check and hash the password before passing it into the domain
+	passwordHash, err := s.passwords.Hash(password, user.Username(), user.Email())
+	if err != nil {
 		return err
 	}
 
-	// Persist
-	if err := s.userRepo.UpdateDescription(domainUserID, description); err != nil {
+	if err := user.UpdatePasswordHash(passwordHash); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(user); err != nil {
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdatePasswordHash(user); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *UserService) UpdatePassword(userID, password string) error {
+func (s *UserService) ValidatePassword(username, password string) (*UserDTO, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.passwords.Verify(user.PasswordHash(), password); err != nil {
+		return nil, err
+	}
+
+	userDTO := UserDTO{}
+	userDTO.FromDomain(user)
+	return &userDTO, nil
+}
+
+// Login validates the user's credentials, backing off repeated failures and locking the
+// account once too many have been made against it
+func (s *UserService) Login(
+	ctx context.Context, username, password, ipAddress string,
+) (*UserDTO, error) {
+	// Refuse the attempt outright while the username or IP is backing off
+	if err := s.loginThrottle.Check(username, ipAddress); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if user == nil {
+		if _, err := s.loginThrottle.RecordFailure(username, ipAddress); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.IsLockedOut(s.loginThrottle.Now()) {
+		return nil, domain.ErrUserLockedOut
+	}
+
+	if err := s.passwords.Verify(user.PasswordHash(), password); err != nil {
+		failures, err := s.loginThrottle.RecordFailure(username, ipAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		if !s.loginThrottle.ShouldLockOut(failures) {
+			return nil, ErrInvalidCredentials
+		}
+
+		// Lock the account, the lock takes over from the username backoff
+		user.LockOut(s.loginThrottle.LockoutUntil(), failures)
+
+		user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := s.userRepo.UpdateLockedUntil(user); err != nil {
+			return nil, err
+		}
+
+		if err := s.loginThrottle.Reset(username); err != nil {
+			return nil, err
+		}
+
+		return nil, domain.ErrUserLockedOut
+	}
+
+	// Suspended and banned users can't log in, even with the right password
+	now := s.loginThrottle.Now()
+	if err := user.CheckStanding(now); err != nil && !errors.Is(err, domain.ErrUserDeactivated) {
+		return nil, err
+	}
+
+	// Logging in during the deletion grace period restores the account
+	if user.DeletionRequestedAt() != nil {
+		if err := user.CancelDeletion(); err != nil {
+			return nil, err
+		}
+
+		user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := s.userRepo.UpdateDeletion(user); err != nil {
+			return nil, err
+		}
+	}
+
+	// A lock that has expired is lifted on the next successful login
+	if user.LockedUntil() != nil {
+		user.Unlock()
+
+		user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := s.userRepo.UpdateLockedUntil(user); err != nil {
+			return nil, err
+		}
+	}
+
+	// As is a suspension that has run out but hasn't been swept up yet
+	if user.Status() == domain.UserStatusSuspended {
+		if err := s.reinstate(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	// The password is only known at login, so that's when an outdated hash is replaced
+	passwordHash, err := s.passwords.Rehash(user.PasswordHash(), password)
+	if err != nil {
+		return nil, err
+	}
+	if passwordHash != "" {
+		user.RehashPassword(passwordHash)
+
+		user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+		if err := s.userRepo.UpdatePasswordHash(user); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.loginThrottle.Reset(username); err != nil {
+		return nil, err
+	}
+
+	userDTO := UserDTO{}
+	userDTO.FromDomain(user)
+	return &userDTO, nil
+}
+
+func (s *UserService) UnlockUser(ctx context.Context, userID string) error {
 	domainUserID := domain.NewUserID(userID)
 
 	// Ensure the user exists
@@ -174,48 +340,117 @@ func (s *UserService) UpdatePassword(userID, password string) error {
 		return errors.New("user doesn't exist")
 	}
 
-	// Hash the password before passing it into the domain
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	// Get the user and lift the lock
+	user, err := s.userRepo.FindByID(domainUserID)
 	if err != nil {
 		return err
 	}
 
-	// Get the user and update the description
+	user.Unlock()
+
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdateLockedUntil(user); err != nil {
+		return err
+	}
+
+	// Clear the failures so the user doesn't have to wait out the backoff
+	if err := s.loginThrottle.Reset(user.Username()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SuspendUser stops the user from logging in or acting until the given time, and ends
+// their active sessions
+func (s *UserService) SuspendUser(
+	ctx context.Context, userID string, until time.Time, reason string,
+) error {
+	domainUserID := domain.NewUserID(userID)
+
+	// Get the user and suspend them
 	user, err := s.userRepo.FindByID(domainUserID)
 	if err != nil {
 		return err
 	}
 
-	if err := user.UpdatePasswordHash(string(passwordHash)); err != nil {
+	if err := user.Suspend(until, reason); err != nil {
 		return err
 	}
 
 	// Persist
-	if err := s.userRepo.UpdatePasswordHash(domainUserID, string(passwordHash)); err != nil {
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdateStatus(user); err != nil {
 		return err
 	}
 
-	// Dispatch the events
-	if err := s.dispatchAggregateEvents(user); err != nil {
+	if err := s.sessionRepo.RevokeAllForUser(domainUserID); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *UserService) ValidatePassword(username, password string) (*UserDTO, error) {
-	user, err := s.userRepo.FindByUsername(username)
+// BanUser stops the user from logging in or acting until they are reinstated, and ends
+// their active sessions
+func (s *UserService) BanUser(ctx context.Context, userID, reason string) error {
+	domainUserID := domain.NewUserID(userID)
+
+	// Get the user and ban them
+	user, err := s.userRepo.FindByID(domainUserID)
 	if err != nil {
-		return nil, errors.New("invalid username or password")
+		return err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash()), []byte(password)); err != nil {
-		return nil, errors.New("invalid username or password")
+	if err := user.Ban(reason); err != nil {
+		return err
 	}
 
-	userDTO := UserDTO{}
-	userDTO.FromDomain(user)
-	return &userDTO, nil
+	// Persist
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	if err := s.userRepo.UpdateStatus(user); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.RevokeAllForUser(domainUserID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReinstateUser lifts the user's suspension or ban
+func (s *UserService) ReinstateUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.FindByID(domain.NewUserID(userID))
+	if err != nil {
+		return err
+	}
+
+	if err := s.reinstate(ctx, user); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LiftExpiredSuspensions reinstates every user whose suspension ended before the given
+// time, returning how many were reinstated
+func (s *UserService) LiftExpiredSuspensions(ctx context.Context, at time.Time) (int, error) {
+	users, err := s.userRepo.FindExpiredSuspensions(at)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range users {
+		user := &users[i]
+
+		if err := s.reinstate(ctx, user); err != nil {
+			return i, err
+		}
+	}
+
+	return len(users), nil
 }
 
 func (s *UserService) GetAllUsers() ([]UserDTO, error) {
@@ -228,6 +463,9 @@ func (s *UserService) GetAllUsers() ([]UserDTO, error) {
 	for _, user := range users {
 		userDTO := UserDTO{}
 		userDTO.FromDomain(&user)
+		if err := s.fillFollowCounts(&userDTO); err != nil {
+			return nil, err
+		}
 		userDTOs = append(userDTOs, userDTO)
 	}
 
@@ -243,6 +481,9 @@ func (s *UserService) GetUserByID(userID string) (*UserDTO, error) {
 
 	userDTO := UserDTO{}
 	userDTO.FromDomain(user)
+	if err := s.fillFollowCounts(&userDTO); err != nil {
+		return nil, err
+	}
 	return &userDTO, nil
 }
 
@@ -254,17 +495,58 @@ func (s *UserService) GetUserByUsername(username string) (*UserDTO, error) {
 
 	userDTO := UserDTO{}
 	userDTO.FromDomain(user)
+	if err := s.fillFollowCounts(&userDTO); err != nil {
+		return nil, err
+	}
 	return &userDTO, nil
 }
 
-// Helper method to dispatch events for any aggregate with AggregateBase
-func (s *UserService) dispatchAggregateEvents(aggregate ddd.EventAggregate) error {
-	events := aggregate.GetUncommittedEvents()
-	for _, event := range events {
-		if err := s.eventDispatcher.Dispatch(event); err != nil {
-			log.Printf("Failed to dispatch event: %v", err)
-		}
+// fillFollowCounts sets how many followers the user has and how many users they follow
+func (s *UserService) fillFollowCounts(userDTO *UserDTO) error {
+	userID := domain.NewUserID(userDTO.ID)
+
+	followerCount, err := s.followRepo.CountFollowers(userID)
+	if err != nil {
+		return err
+	}
+
+	followingCount, err := s.followRepo.CountFollowing(userID)
+	if err != nil {
+		return err
 	}
-	aggregate.MarkEventsAsCommitted()
+
+	userDTO.FollowerCount = followerCount
+	userDTO.FollowingCount = followingCount
 	return nil
 }
+
+// reinstate lifts the user's suspension or ban and persists it, leaving the events for
+// the caller to dispatch
+func (s *UserService) reinstate(ctx context.Context, user *domain.User) error {
+	if err := user.Reinstate(); err != nil {
+		return err
+	}
+
+	user.SetEventMetadata(ddd.EventMetadataFrom(ctx))
+	return s.userRepo.UpdateStatus(user)
+}
+
+// toKnownRoles converts role names to domain roles, failing with domain.ErrUnknownRole
+// if any of them doesn't exist
+func (s *UserService) toKnownRoles(userRoles []string) ([]domain.UserRole, error) {
+	domainUserRoles := []domain.UserRole{}
+	for _, role := range userRoles {
+		domainRole := domain.UserRole(role)
+
+		exists, err := s.roleRepo.Exists(domainRole)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownRole, role)
+		}
+
+		domainUserRoles = append(domainUserRoles, domainRole)
+	}
+	return domainUserRoles, nil
+}
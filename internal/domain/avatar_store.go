@@ -0,0 +1,18 @@
+package domain
+
+import "io"
+
+// AvatarSizes are the square sizes, in pixels, generated for every uploaded avatar
+var AvatarSizes = []int{32, 64, 128, 256}
+
+// AvatarStore turns uploaded images into avatars of every size in AvatarSizes and keeps
+// them. Each upload is stored under a new version so cached copies of the old one are
+// never served in its place
+type AvatarStore interface {
+	// Save fails with ErrInvalidAvatar if the image can't be decoded
+	Save(userID UserID, version string, image io.Reader) error
+	// Open fails with ErrAvatarNotFound if the version or size doesn't exist
+	Open(userID UserID, version string, size int) (io.ReadCloser, error)
+	// Delete removes every version of the user's avatar
+	Delete(userID UserID) error
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"blog/pkg/ddd"
+
+	"github.com/google/uuid"
+)
+
+const maxBookmarkNoteLength = 500
+
+// Bookmark is a post a user saved to read later, optionally filed in one of their
+// folders with a note
+type Bookmark struct {
+	*ddd.AggregateBase
+	userID    UserID
+	postID    PostID
+	folderID  BookmarkFolderID
+	note      string
+	createdAt time.Time
+	updatedAt *time.Time
+}
+
+// NewBookmark saves the post for the user. An empty folderID leaves it unfiled
+func NewBookmark(
+	userID UserID,
+	postID PostID,
+	folderID BookmarkFolderID,
+	note string,
+) (*Bookmark, error) {
+	if utf8.RuneCountInString(note) > maxBookmarkNoteLength {
+		return nil, ErrBookmarkNoteTooLong
+	}
+
+	now := time.Now()
+
+	bookmark := &Bookmark{
+		AggregateBase: &ddd.AggregateBase{},
+		userID:        userID,
+		postID:        postID,
+		folderID:      folderID,
+		note:          note,
+		createdAt:     now,
+		updatedAt:     nil,
+	}
+
+	newID := NewBookmarkID(uuid.New().String())
+	bookmark.SetID(newID)
+
+	event := NewBookmarkCreatedEvent(bookmark.GetID(), userID, postID, folderID, note, now)
+	bookmark.RecordEvent(event)
+
+	return bookmark, nil
+}
+
+func (a Bookmark) GetID() BookmarkID {
+	return BookmarkID(a.AggregateBase.GetID())
+}
+
+func (a *Bookmark) SetID(id BookmarkID) {
+	if id == "" {
+		return
+	}
+	a.AggregateBase.SetID(string(id))
+}
+
+func (a Bookmark) UserID() UserID             { return a.userID }
+func (a Bookmark) PostID() PostID             { return a.postID }
+func (a Bookmark) FolderID() BookmarkFolderID { return a.folderID }
+func (a Bookmark) Note() string               { return a.note }
+func (a Bookmark) CreatedAt() time.Time       { return a.createdAt }
+func (a Bookmark) UpdatedAt() *time.Time      { return a.updatedAt }
+
+// OwnerID returns the user the bookmark belongs to
+func (a Bookmark) OwnerID() UserID { return a.userID }
+
+func (a *Bookmark) UpdateNote(note string) error {
+	if utf8.RuneCountInString(note) > maxBookmarkNoteLength {
+		return ErrBookmarkNoteTooLong
+	}
+
+	now := time.Now()
+	a.note = note
+	a.updatedAt = &now
+
+	event := NewBookmarkNoteUpdatedEvent(a.GetID(), note, now)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// MoveToFolder files the bookmark in the folder, or unfiles it if folderID is empty
+func (a *Bookmark) MoveToFolder(folderID BookmarkFolderID) {
+	now := time.Now()
+	a.folderID = folderID
+	a.updatedAt = &now
+
+	event := NewBookmarkMovedEvent(a.GetID(), folderID, now)
+	a.RecordEvent(event)
+}
+
+func (a *Bookmark) Remove() {
+	event := NewBookmarkRemovedEvent(a.GetID())
+	a.RecordEvent(event)
+}
+
+func RebuildBookmark(
+	id BookmarkID,
+	userID UserID,
+	postID PostID,
+	folderID BookmarkFolderID,
+	note string,
+	createdAt time.Time,
+	updatedAt *time.Time,
+) *Bookmark {
+	bookmark := &Bookmark{
+		AggregateBase: &ddd.AggregateBase{},
+		userID:        userID,
+		postID:        postID,
+		folderID:      folderID,
+		note:          note,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}
+
+	bookmark.SetID(id)
+	return bookmark
+}
@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+const (
+	BookmarkCreatedEventType       EventType = "BookmarkCreated"
+	BookmarkNoteUpdatedEventType   EventType = "BookmarkNoteUpdated"
+	BookmarkMovedEventType         EventType = "BookmarkMoved"
+	BookmarkRemovedEventType       EventType = "BookmarkRemoved"
+	BookmarkFolderCreatedEventType EventType = "BookmarkFolderCreated"
+	BookmarkFolderRenamedEventType EventType = "BookmarkFolderRenamed"
+	BookmarkFolderDeletedEventType EventType = "BookmarkFolderDeleted"
+)
+
+type BookmarkCreatedEvent struct {
+	ddd.EventBase
+
+	BookmarkID BookmarkID
+	UserID     UserID
+	PostID     PostID
+	FolderID   BookmarkFolderID
+	Note       string
+	CreatedAt  time.Time
+}
+
+func NewBookmarkCreatedEvent(
+	id BookmarkID,
+	userID UserID,
+	postID PostID,
+	folderID BookmarkFolderID,
+	note string,
+	createdAt time.Time,
+) *BookmarkCreatedEvent {
+	return &BookmarkCreatedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
+		BookmarkID: id,
+		UserID:     userID,
+		PostID:     postID,
+		FolderID:   folderID,
+		Note:       note,
+		CreatedAt:  createdAt,
+	}
+}
+
+func (e BookmarkCreatedEvent) EventType() string { return string(BookmarkCreatedEventType) }
+
+type BookmarkNoteUpdatedEvent struct {
+	ddd.EventBase
+
+	BookmarkID BookmarkID
+	Note       string
+	UpdatedAt  time.Time
+}
+
+func NewBookmarkNoteUpdatedEvent(
+	id BookmarkID,
+	note string,
+	updatedAt time.Time,
+) *BookmarkNoteUpdatedEvent {
+	return &BookmarkNoteUpdatedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
+		BookmarkID: id,
+		Note:       note,
+		UpdatedAt:  updatedAt,
+	}
+}
+
+func (e BookmarkNoteUpdatedEvent) EventType() string {
+	return string(BookmarkNoteUpdatedEventType)
+}
+
+type BookmarkMovedEvent struct {
+	ddd.EventBase
+
+	BookmarkID BookmarkID
+	FolderID   BookmarkFolderID
+	UpdatedAt  time.Time
+}
+
+func NewBookmarkMovedEvent(
+	id BookmarkID,
+	folderID BookmarkFolderID,
+	updatedAt time.Time,
+) *BookmarkMovedEvent {
+	return &BookmarkMovedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
+		BookmarkID: id,
+		FolderID:   folderID,
+		UpdatedAt:  updatedAt,
+	}
+}
+
+func (e BookmarkMovedEvent) EventType() string { return string(BookmarkMovedEventType) }
+
+type BookmarkRemovedEvent struct {
+	ddd.EventBase
+
+	BookmarkID BookmarkID
+}
+
+func NewBookmarkRemovedEvent(
+	id BookmarkID,
+) *BookmarkRemovedEvent {
+	return &BookmarkRemovedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
+		BookmarkID: id,
+	}
+}
+
+func (e BookmarkRemovedEvent) EventType() string { return string(BookmarkRemovedEventType) }
+
+type BookmarkFolderCreatedEvent struct {
+	ddd.EventBase
+
+	FolderID  BookmarkFolderID
+	UserID    UserID
+	Name      string
+	CreatedAt time.Time
+}
+
+func NewBookmarkFolderCreatedEvent(
+	id BookmarkFolderID,
+	userID UserID,
+	name string,
+	createdAt time.Time,
+) *BookmarkFolderCreatedEvent {
+	return &BookmarkFolderCreatedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		FolderID:  id,
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: createdAt,
+	}
+}
+
+func (e BookmarkFolderCreatedEvent) EventType() string {
+	return string(BookmarkFolderCreatedEventType)
+}
+
+type BookmarkFolderRenamedEvent struct {
+	ddd.EventBase
+
+	FolderID BookmarkFolderID
+	Name     string
+}
+
+func NewBookmarkFolderRenamedEvent(
+	id BookmarkFolderID,
+	name string,
+) *BookmarkFolderRenamedEvent {
+	return &BookmarkFolderRenamedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		FolderID:  id,
+		Name:      name,
+	}
+}
+
+func (e BookmarkFolderRenamedEvent) EventType() string {
+	return string(BookmarkFolderRenamedEventType)
+}
+
+type BookmarkFolderDeletedEvent struct {
+	ddd.EventBase
+
+	FolderID BookmarkFolderID
+}
+
+func NewBookmarkFolderDeletedEvent(
+	id BookmarkFolderID,
+) *BookmarkFolderDeletedEvent {
+	return &BookmarkFolderDeletedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		FolderID:  id,
+	}
+}
+
+func (e BookmarkFolderDeletedEvent) EventType() string {
+	return string(BookmarkFolderDeletedEventType)
+}
+
+func init() {
+	ddd.EventRegistry.Register(
+		BookmarkCreatedEvent{},
+		"Raised when a user bookmarks a post",
+	)
+
+	ddd.EventRegistry.Register(
+		BookmarkNoteUpdatedEvent{},
+		"Raised when a bookmark's note is changed",
+	)
+
+	ddd.EventRegistry.Register(
+		BookmarkMovedEvent{},
+		"Raised when a bookmark is moved to another folder or unfiled",
+	)
+
+	ddd.EventRegistry.Register(
+		BookmarkRemovedEvent{},
+		"Raised when a bookmark is removed",
+	)
+
+	ddd.EventRegistry.Register(
+		BookmarkFolderCreatedEvent{},
+		"Raised when a user creates a bookmark folder",
+	)
+
+	ddd.EventRegistry.Register(
+		BookmarkFolderRenamedEvent{},
+		"Raised when a bookmark folder is renamed",
+	)
+
+	ddd.EventRegistry.Register(
+		BookmarkFolderDeletedEvent{},
+		"Raised when a bookmark folder is deleted",
+	)
+}
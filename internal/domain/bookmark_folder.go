@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blog/pkg/ddd"
+
+	"github.com/google/uuid"
+)
+
+const maxBookmarkFolderNameLength = 50
+
+// BookmarkFolder groups a user's bookmarks. Folder names are unique per user
+type BookmarkFolder struct {
+	*ddd.AggregateBase
+	userID    UserID
+	name      string
+	createdAt time.Time
+}
+
+func NewBookmarkFolder(userID UserID, name string) (*BookmarkFolder, error) {
+	name, err := validateBookmarkFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	folder := &BookmarkFolder{
+		AggregateBase: &ddd.AggregateBase{},
+		userID:        userID,
+		name:          name,
+		createdAt:     now,
+	}
+
+	newID := NewBookmarkFolderID(uuid.New().String())
+	folder.SetID(newID)
+
+	event := NewBookmarkFolderCreatedEvent(folder.GetID(), userID, name, now)
+	folder.RecordEvent(event)
+
+	return folder, nil
+}
+
+func (a BookmarkFolder) GetID() BookmarkFolderID {
+	return BookmarkFolderID(a.AggregateBase.GetID())
+}
+
+func (a *BookmarkFolder) SetID(id BookmarkFolderID) {
+	if id == "" {
+		return
+	}
+	a.AggregateBase.SetID(string(id))
+}
+
+func (a BookmarkFolder) UserID() UserID       { return a.userID }
+func (a BookmarkFolder) Name() string         { return a.name }
+func (a BookmarkFolder) CreatedAt() time.Time { return a.createdAt }
+
+// OwnerID returns the user the folder belongs to
+func (a BookmarkFolder) OwnerID() UserID { return a.userID }
+
+func (a *BookmarkFolder) Rename(name string) error {
+	name, err := validateBookmarkFolderName(name)
+	if err != nil {
+		return err
+	}
+
+	a.name = name
+
+	event := NewBookmarkFolderRenamedEvent(a.GetID(), name)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// Delete records the folder being deleted. Its bookmarks aren't deleted with it, they
+// become unfiled
+func (a *BookmarkFolder) Delete() {
+	event := NewBookmarkFolderDeletedEvent(a.GetID())
+	a.RecordEvent(event)
+}
+
+func RebuildBookmarkFolder(
+	id BookmarkFolderID,
+	userID UserID,
+	name string,
+	createdAt time.Time,
+) *BookmarkFolder {
+	folder := &BookmarkFolder{
+		AggregateBase: &ddd.AggregateBase{},
+		userID:        userID,
+		name:          name,
+		createdAt:     createdAt,
+	}
+
+	folder.SetID(id)
+	return folder
+}
+
+// validateBookmarkFolderName returns the name trimmed of surrounding whitespace
+func validateBookmarkFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrBookmarkFolderNameEmpty
+	}
+	if utf8.RuneCountInString(name) > maxBookmarkFolderNameLength {
+		return "", ErrBookmarkFolderNameTooLong
+	}
+	return name, nil
+}
@@ -0,0 +1,11 @@
+package domain
+
+type BookmarkFolderID string
+
+func NewBookmarkFolderID(id string) BookmarkFolderID {
+	return BookmarkFolderID(id)
+}
+
+func (id BookmarkFolderID) String() string {
+	return string(id)
+}
@@ -0,0 +1,11 @@
+package domain
+
+type BookmarkID string
+
+func NewBookmarkID(id string) BookmarkID {
+	return BookmarkID(id)
+}
+
+func (id BookmarkID) String() string {
+	return string(id)
+}
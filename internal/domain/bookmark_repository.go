@@ -0,0 +1,31 @@
+package domain
+
+type BookmarkRepository interface {
+	All() ([]Bookmark, error)
+	FindByID(id BookmarkID) (*Bookmark, error)
+	// FindByUser returns the user's bookmarks, newest first
+	FindByUser(userID UserID) ([]Bookmark, error)
+	// FindByFolder returns the folder's bookmarks, newest first
+	FindByFolder(folderID BookmarkFolderID) ([]Bookmark, error)
+	Exists(id BookmarkID) (bool, error)
+	ExistsOnPostByUser(postID PostID, userID UserID) (bool, error)
+	Create(bookmark *Bookmark) (*Bookmark, error)
+	UpdateNote(bookmark *Bookmark) error
+	// UpdateFolder files the bookmark in its folder, or unfiles it if it has none
+	UpdateFolder(bookmark *Bookmark) error
+	RemoveBookmark(bookmark *Bookmark) error
+	DeleteByUser(userID UserID) error
+}
+
+type BookmarkFolderRepository interface {
+	FindByID(id BookmarkFolderID) (*BookmarkFolder, error)
+	// FindByUser returns the user's folders ordered by name
+	FindByUser(userID UserID) ([]BookmarkFolder, error)
+	// Create fails with ErrBookmarkFolderNameTaken if the user already has a folder with
+	// the name
+	Create(folder *BookmarkFolder) (*BookmarkFolder, error)
+	// Rename fails with ErrBookmarkFolderNameTaken like Create
+	Rename(folder *BookmarkFolder) error
+	Delete(folder *BookmarkFolder) error
+	DeleteByUser(userID UserID) error
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewBookmark(t *testing.T) {
+	tests := []struct {
+		name    string
+		note    string
+		wantErr error
+	}{
+		{
+			name: "Test Bookmark Without Note",
+		},
+		{
+			name: "Test Bookmark With Note",
+			note: "read this before the meeting",
+		},
+		{
+			name:    "Test Note Too Long Fails",
+			note:    strings.Repeat("a", 501),
+			wantErr: ErrBookmarkNoteTooLong,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookmark, gotErr := NewBookmark("alice", "post", "", tt.note)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("NewBookmark() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if bookmark.Note() != tt.note || bookmark.FolderID() != "" {
+				t.Errorf("NewBookmark() = %#v", bookmark)
+			}
+			if _, ok := bookmark.GetUncommittedEvents()[0].(*BookmarkCreatedEvent); !ok {
+				t.Errorf("NewBookmark() recorded %T", bookmark.GetUncommittedEvents()[0])
+			}
+		})
+	}
+}
+
+func TestNewBookmarkFolder(t *testing.T) {
+	tests := []struct {
+		name     string
+		folder   string
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "Test Folder Name Is Trimmed",
+			folder:   "  Go  ",
+			wantName: "Go",
+		},
+		{
+			name:    "Test Blank Name Fails",
+			folder:  "   ",
+			wantErr: ErrBookmarkFolderNameEmpty,
+		},
+		{
+			name:    "Test Long Name Fails",
+			folder:  strings.Repeat("a", 51),
+			wantErr: ErrBookmarkFolderNameTooLong,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder, gotErr := NewBookmarkFolder("alice", tt.folder)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("NewBookmarkFolder() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if folder.Name() != tt.wantName {
+				t.Errorf("NewBookmarkFolder() name = %q, want %q", folder.Name(), tt.wantName)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"blog/pkg/ddd"
@@ -8,8 +9,10 @@ import (
 	"github.com/google/uuid"
 )
 
+// Comment is event sourced: its state is rebuilt from its events, and every change is
+// made by raising one
 type Comment struct {
-	*ddd.AggregateBase
+	*ddd.EventSourcedAggregate
 	postID        PostID
 	commenterID   UserID
 	content       string
@@ -23,23 +26,29 @@ func NewComment(postID PostID, commenterID UserID, content string) (*Comment, er
 		return nil, ErrCommentCannotBeEmpty
 	}
 
-	now := time.Now()
-
 	comment := &Comment{
-		AggregateBase: &ddd.AggregateBase{},
-		postID:        postID,
-		commenterID:   commenterID,
-		content:       content,
-		createdAt:     now,
-		lastUpdatedAt: nil,
-		archivedAt:    nil,
+		EventSourcedAggregate: ddd.NewEventSourcedAggregate(),
 	}
 
 	newID := NewCommentID(uuid.New().String())
-	comment.SetID(newID)
+	event := NewCommentCreatedEvent(newID, postID, commenterID, content, time.Now(), nil, nil)
+	comment.raise(event)
+
+	return comment, nil
+}
 
-	event := NewCommentCreatedEvent(comment.GetID(), postID, commenterID, content, now, nil, nil)
-	comment.RecordEvent(event)
+// LoadComment rebuilds a comment from the events in its stream
+func LoadComment(history []ddd.DomainEvent) (*Comment, error) {
+	if len(history) == 0 {
+		return nil, ErrCommentNotFound
+	}
+
+	comment := &Comment{
+		EventSourcedAggregate: ddd.NewEventSourcedAggregate(),
+	}
+	if err := comment.Replay(comment.apply, history); err != nil {
+		return nil, err
+	}
 
 	return comment, nil
 }
@@ -63,29 +72,72 @@ func (a Comment) LastUpdatedAt() *time.Time { return a.lastUpdatedAt }
 func (a Comment) Archived() bool            { return a.archivedAt != nil }
 func (a Comment) ArchivedAt() *time.Time    { return a.archivedAt }
 
+// OwnerID returns the user the comment belongs to, for authorization
+func (a Comment) OwnerID() UserID { return a.commenterID }
+
 func (a *Comment) Edit(content string) error {
 	if content == "" {
 		return ErrCommentCannotBeEmpty
 	}
 
-	now := time.Now()
-	a.content = content
-	a.lastUpdatedAt = &now
-
-	event := NewCommentEditedEvent(a.GetID(), content, now)
-	a.RecordEvent(event)
+	event := NewCommentEditedEvent(a.GetID(), content, time.Now())
+	a.raise(event)
 
 	return nil
 }
 
 func (a *Comment) Archive() {
-	now := time.Now()
-	a.archivedAt = &now
+	event := NewCommentArchivedEvent(a.GetID(), time.Now())
+	a.raise(event)
+}
+
+// Restore brings back an archived comment. Restoring a comment that isn't archived does
+// nothing
+func (a *Comment) Restore() {
+	if a.archivedAt == nil {
+		return
+	}
+
+	event := NewCommentRestoredEvent(a.GetID(), time.Now())
+	a.raise(event)
+}
 
-	event := NewCommentArchivedEvent(a.GetID(), now)
-	a.RecordEvent(event)
+// raise applies and records an event the comment raised itself, which it always knows
+// how to apply
+func (a *Comment) raise(event ddd.DomainEvent) {
+	if err := a.Raise(a.apply, event); err != nil {
+		panic(err)
+	}
+}
+
+func (a *Comment) apply(event ddd.DomainEvent) error {
+	switch e := event.(type) {
+	case *CommentCreatedEvent:
+		a.SetID(e.CommentID)
+		a.postID = e.PostID
+		a.commenterID = e.CommenterID
+		a.content = e.Content
+		a.createdAt = e.CreatedAt
+		a.lastUpdatedAt = e.LastUpdatedAt
+		a.archivedAt = e.ArchivedAt
+	case *CommentEditedEvent:
+		lastUpdatedAt := e.LastUpdatedAt
+		a.content = e.Content
+		a.lastUpdatedAt = &lastUpdatedAt
+	case *CommentArchivedEvent:
+		archivedAt := e.ArchivedAt
+		a.archivedAt = &archivedAt
+	case *CommentRestoredEvent:
+		a.archivedAt = nil
+	default:
+		return fmt.Errorf("comment cannot apply %s event", event.EventType())
+	}
+	return nil
 }
 
+// RebuildComment restores a comment from its stored state, for reading. A comment that
+// is going to be changed is loaded from its events with LoadComment instead, so the
+// version of its stream is known
 func RebuildComment(
 	id CommentID,
 	postID PostID,
@@ -96,13 +148,13 @@ func RebuildComment(
 	archivedAt *time.Time,
 ) *Comment {
 	comment := &Comment{
-		AggregateBase: &ddd.AggregateBase{},
-		postID:        postID,
-		commenterID:   commenterID,
-		content:       content,
-		createdAt:     createdAt,
-		lastUpdatedAt: lastUpdatedAt,
-		archivedAt:    archivedAt,
+		EventSourcedAggregate: ddd.NewEventSourcedAggregate(),
+		postID:                postID,
+		commenterID:           commenterID,
+		content:               content,
+		createdAt:             createdAt,
+		lastUpdatedAt:         lastUpdatedAt,
+		archivedAt:            archivedAt,
 	}
 	comment.SetID(id)
 	return comment
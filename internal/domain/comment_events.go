@@ -10,9 +10,12 @@ const (
 	CommentCreatedEventType  EventType = "CommentCreated"
 	CommentEditedEventType   EventType = "CommentEdited"
 	CommentArchivedEventType EventType = "CommentArchived"
+	CommentRestoredEventType EventType = "CommentRestored"
 )
 
 type CommentCreatedEvent struct {
+	ddd.EventBase
+
 	CommentID     CommentID
 	PostID        PostID
 	CommenterID   UserID
@@ -20,7 +23,6 @@ type CommentCreatedEvent struct {
 	CreatedAt     time.Time
 	LastUpdatedAt *time.Time
 	ArchivedAt    *time.Time
-	occurredOn    time.Time
 }
 
 func NewCommentCreatedEvent(
@@ -33,6 +35,7 @@ func NewCommentCreatedEvent(
 	archivedAt *time.Time,
 ) *CommentCreatedEvent {
 	return &CommentCreatedEvent{
+		EventBase:     ddd.NewEventBase(time.Now()),
 		CommentID:     id,
 		PostID:        postID,
 		CommenterID:   commenterID,
@@ -40,18 +43,17 @@ func NewCommentCreatedEvent(
 		CreatedAt:     createdAt,
 		LastUpdatedAt: lastUpdatedAt,
 		ArchivedAt:    archivedAt,
-		occurredOn:    time.Now(),
 	}
 }
 
-func (e CommentCreatedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e CommentCreatedEvent) EventType() string     { return string(CommentCreatedEventType) }
+func (e CommentCreatedEvent) EventType() string { return string(CommentCreatedEventType) }
 
 type CommentEditedEvent struct {
+	ddd.EventBase
+
 	CommentID     CommentID
 	Content       string
 	LastUpdatedAt time.Time
-	occurredOn    time.Time
 }
 
 func NewCommentEditedEvent(
@@ -60,20 +62,20 @@ func NewCommentEditedEvent(
 	lastUpdatedAt time.Time,
 ) *CommentEditedEvent {
 	return &CommentEditedEvent{
+		EventBase:     ddd.NewEventBase(time.Now()),
 		CommentID:     commentID,
 		Content:       content,
 		LastUpdatedAt: lastUpdatedAt,
-		occurredOn:    time.Now(),
 	}
 }
 
-func (e CommentEditedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e CommentEditedEvent) EventType() string     { return string(CommentEditedEventType) }
+func (e CommentEditedEvent) EventType() string { return string(CommentEditedEventType) }
 
 type CommentArchivedEvent struct {
+	ddd.EventBase
+
 	CommentID  CommentID
 	ArchivedAt time.Time
-	occurredOn time.Time
 }
 
 func NewCommentArchivedEvent(
@@ -81,14 +83,33 @@ func NewCommentArchivedEvent(
 	archivedAt time.Time,
 ) *CommentArchivedEvent {
 	return &CommentArchivedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
 		CommentID:  commentID,
 		ArchivedAt: archivedAt,
-		occurredOn: time.Now(),
 	}
 }
 
-func (e CommentArchivedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e CommentArchivedEvent) EventType() string     { return string(CommentArchivedEventType) }
+func (e CommentArchivedEvent) EventType() string { return string(CommentArchivedEventType) }
+
+type CommentRestoredEvent struct {
+	ddd.EventBase
+
+	CommentID  CommentID
+	RestoredAt time.Time
+}
+
+func NewCommentRestoredEvent(
+	commentID CommentID,
+	restoredAt time.Time,
+) *CommentRestoredEvent {
+	return &CommentRestoredEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
+		CommentID:  commentID,
+		RestoredAt: restoredAt,
+	}
+}
+
+func (e CommentRestoredEvent) EventType() string { return string(CommentRestoredEventType) }
 
 func init() {
 	ddd.EventRegistry.Register(
@@ -105,4 +126,9 @@ func init() {
 		CommentArchivedEvent{},
 		"Raised when a comment is archived",
 	)
+
+	ddd.EventRegistry.Register(
+		CommentRestoredEvent{},
+		"Raised when an archived comment is restored",
+	)
 }
@@ -7,6 +7,8 @@ type CommentRepository interface {
 	FindByPost(postID PostID) ([]Comment, error)
 	Exists(id CommentID) (bool, error)
 	Create(comment *Comment) (*Comment, error)
-	UpdateContent(id CommentID, newContent string) error
-	Archive(id CommentID) error
+	UpdateContent(comment *Comment) error
+	Archive(comment *Comment) error
+	Restore(comment *Comment) error
+	DeleteByUser(userID UserID) error
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -174,18 +175,44 @@ func TestComment_Archive(t *testing.T) {
 	}
 }
 
+func TestComment_Restore(t *testing.T) {
+	a, err := NewComment("1", "2", "3")
+	if err != nil {
+		t.Fatalf("NewComment() failed: %v", err)
+	}
+
+	a.Restore()
+	if len(a.GetUncommittedEvents()) != 1 {
+		t.Errorf("Restore() raised an event for a comment that isn't archived")
+	}
+
+	a.Archive()
+	a.Restore()
+	if a.Archived() || a.ArchivedAt() != nil {
+		t.Errorf("Restore() failed to restore comment")
+	}
+
+	got, err := LoadComment(a.GetUncommittedEvents())
+	if err != nil {
+		t.Fatalf("LoadComment() failed: %v", err)
+	}
+	if got.Archived() {
+		t.Errorf("LoadComment() did not apply the restore")
+	}
+}
+
 func TestRebuildComment(t *testing.T) {
 	now := time.Now()
 	later := now.Add(time.Hour * 1)
 
 	desiredComment := &Comment{
-		AggregateBase: &ddd.AggregateBase{},
-		postID:        "2",
-		commenterID:   "3",
-		content:       "4",
-		createdAt:     now,
-		lastUpdatedAt: &later,
-		archivedAt:    &time.Time{},
+		EventSourcedAggregate: ddd.NewEventSourcedAggregate(),
+		postID:                "2",
+		commenterID:           "3",
+		content:               "4",
+		createdAt:             now,
+		lastUpdatedAt:         &later,
+		archivedAt:            &time.Time{},
 	}
 	desiredComment.SetID("1")
 
@@ -230,3 +257,67 @@ func TestRebuildComment(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadComment(t *testing.T) {
+	original, err := NewComment("1", "2", "3")
+	if err != nil {
+		t.Fatalf("NewComment() failed: %v", err)
+	}
+	original.Edit("abcd")
+	original.Archive()
+	history := original.GetUncommittedEvents()
+
+	got, err := LoadComment(history)
+	if err != nil {
+		t.Fatalf("LoadComment() failed: %v", err)
+	}
+
+	if got.GetID() != original.GetID() || got.PostID() != "1" || got.CommenterID() != "2" {
+		t.Errorf("LoadComment() = %#v, want the comment that raised the events", got)
+	}
+	if got.Content() != "abcd" || got.LastUpdatedAt() == nil || !got.Archived() {
+		t.Errorf("LoadComment() did not apply the edit and archive")
+	}
+	if got.Version() != len(history) {
+		t.Errorf("Version() = %d, want %d", got.Version(), len(history))
+	}
+	if len(got.GetUncommittedEvents()) != 0 {
+		t.Errorf("LoadComment() recorded the events it replayed")
+	}
+}
+
+func TestLoadCommentWithoutEvents(t *testing.T) {
+	if _, err := LoadComment(nil); !errors.Is(err, ErrCommentNotFound) {
+		t.Errorf("LoadComment() error = %v, want %v", err, ErrCommentNotFound)
+	}
+}
+
+func TestLoadCommentRejectsOtherEvents(t *testing.T) {
+	history := []ddd.DomainEvent{
+		NewPostArchivedEvent("1", time.Now()),
+	}
+	if _, err := LoadComment(history); err == nil {
+		t.Error("LoadComment() succeeded unexpectedly")
+	}
+}
+
+func TestComment_MarkEventsAsCommitted(t *testing.T) {
+	a, err := NewComment("1", "2", "3")
+	if err != nil {
+		t.Fatalf("NewComment() failed: %v", err)
+	}
+	if a.Version() != 0 {
+		t.Fatalf("Version() = %d before the comment is stored, want 0", a.Version())
+	}
+
+	a.MarkEventsAsCommitted()
+	a.Edit("abcd")
+	if a.Version() != 1 {
+		t.Errorf("Version() = %d, want 1", a.Version())
+	}
+
+	a.MarkEventsAsCommitted()
+	if a.Version() != 2 {
+		t.Errorf("Version() = %d, want 2", a.Version())
+	}
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+type DigestFrequency string
+
+const (
+	DigestFrequencyOff    DigestFrequency = "off"
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+func (f DigestFrequency) String() string {
+	return string(f)
+}
+
+// DigestFrequencies returns every frequency a user can choose
+func DigestFrequencies() []DigestFrequency {
+	return []DigestFrequency{
+		DigestFrequencyOff,
+		DigestFrequencyDaily,
+		DigestFrequencyWeekly,
+	}
+}
+
+func (f DigestFrequency) Valid() bool {
+	return slices.Contains(DigestFrequencies(), f)
+}
+
+// Period returns how much activity each digest covers, or zero when digests are off
+func (f DigestFrequency) Period() time.Duration {
+	switch f {
+	case DigestFrequencyDaily:
+		return 24 * time.Hour
+	case DigestFrequencyWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// DigestSubscription is how often a user wants an email digest of activity. Digests
+// are opt-in, so users start with them off
+type DigestSubscription struct {
+	userID     UserID
+	frequency  DigestFrequency
+	lastSentAt *time.Time
+}
+
+func NewDigestSubscription(userID UserID) *DigestSubscription {
+	return &DigestSubscription{
+		userID:     userID,
+		frequency:  DigestFrequencyOff,
+		lastSentAt: nil,
+	}
+}
+
+func (s DigestSubscription) UserID() UserID             { return s.userID }
+func (s DigestSubscription) Frequency() DigestFrequency { return s.frequency }
+func (s DigestSubscription) LastSentAt() *time.Time     { return s.lastSentAt }
+
+// ChangeFrequency returns ErrUnknownDigestFrequency unless the frequency is off, daily
+// or weekly
+func (s *DigestSubscription) ChangeFrequency(frequency DigestFrequency) error {
+	if !frequency.Valid() {
+		return ErrUnknownDigestFrequency
+	}
+
+	s.frequency = frequency
+	return nil
+}
+
+func (s *DigestSubscription) Unsubscribe() {
+	s.frequency = DigestFrequencyOff
+}
+
+// Due reports whether a digest should be sent at the given time, i.e. a full period
+// has passed since the last one
+func (s DigestSubscription) Due(at time.Time) bool {
+	if s.frequency == DigestFrequencyOff {
+		return false
+	}
+	if s.lastSentAt == nil {
+		return true
+	}
+	return !at.Before(s.lastSentAt.Add(s.frequency.Period()))
+}
+
+// Since returns when the activity in a digest sent at the given time starts. That's
+// the last digest, or one period back for the first one
+func (s DigestSubscription) Since(at time.Time) time.Time {
+	if s.lastSentAt != nil {
+		return *s.lastSentAt
+	}
+	return at.Add(-s.frequency.Period())
+}
+
+func (s *DigestSubscription) MarkSent(at time.Time) {
+	s.lastSentAt = &at
+}
+
+func RebuildDigestSubscription(
+	userID UserID,
+	frequency DigestFrequency,
+	lastSentAt *time.Time,
+) *DigestSubscription {
+	return &DigestSubscription{
+		userID:     userID,
+		frequency:  frequency,
+		lastSentAt: lastSentAt,
+	}
+}
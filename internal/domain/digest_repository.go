@@ -0,0 +1,10 @@
+package domain
+
+type DigestSubscriptionRepository interface {
+	// Find returns the user's subscription, which is off if they've never set one
+	Find(userID UserID) (*DigestSubscription, error)
+	// FindSubscribed returns every subscription that isn't off
+	FindSubscribed() ([]DigestSubscription, error)
+	Save(subscription *DigestSubscription) error
+	DeleteByUser(userID UserID) error
+}
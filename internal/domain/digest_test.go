@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDigestSubscriptionDue(t *testing.T) {
+	now := time.Date(2025, 1, 8, 9, 0, 0, 0, time.UTC)
+	dayAgo := now.Add(-24 * time.Hour)
+	hourAgo := now.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		frequency  DigestFrequency
+		lastSentAt *time.Time
+		wantDue    bool
+		wantSince  time.Time
+	}{
+		{
+			name:      "Test Off Is Never Due",
+			frequency: DigestFrequencyOff,
+			wantDue:   false,
+		},
+		{
+			name:      "Test First Daily Digest Covers A Day",
+			frequency: DigestFrequencyDaily,
+			wantDue:   true,
+			wantSince: dayAgo,
+		},
+		{
+			name:       "Test Daily Digest Due A Day Later",
+			frequency:  DigestFrequencyDaily,
+			lastSentAt: &dayAgo,
+			wantDue:    true,
+			wantSince:  dayAgo,
+		},
+		{
+			name:       "Test Daily Digest Not Due Within A Day",
+			frequency:  DigestFrequencyDaily,
+			lastSentAt: &hourAgo,
+			wantDue:    false,
+			wantSince:  hourAgo,
+		},
+		{
+			name:       "Test Weekly Digest Not Due After A Day",
+			frequency:  DigestFrequencyWeekly,
+			lastSentAt: &dayAgo,
+			wantDue:    false,
+			wantSince:  dayAgo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := RebuildDigestSubscription("alice", tt.frequency, tt.lastSentAt)
+
+			if got := subscription.Due(now); got != tt.wantDue {
+				t.Errorf("Due() = %v, want %v", got, tt.wantDue)
+			}
+			if !tt.wantSince.IsZero() && !subscription.Since(now).Equal(tt.wantSince) {
+				t.Errorf("Since() = %v, want %v", subscription.Since(now), tt.wantSince)
+			}
+		})
+	}
+}
+
+func TestDigestSubscriptionChangeFrequency(t *testing.T) {
+	subscription := NewDigestSubscription("alice")
+	if subscription.Frequency() != DigestFrequencyOff {
+		t.Fatalf("NewDigestSubscription() frequency = %s, want off", subscription.Frequency())
+	}
+
+	if err := subscription.ChangeFrequency("hourly"); !errors.Is(err, ErrUnknownDigestFrequency) {
+		t.Errorf("ChangeFrequency() error = %v, want ErrUnknownDigestFrequency", err)
+	}
+
+	if err := subscription.ChangeFrequency(DigestFrequencyWeekly); err != nil {
+		t.Fatalf("ChangeFrequency() error = %v", err)
+	}
+	subscription.Unsubscribe()
+	if subscription.Frequency() != DigestFrequencyOff {
+		t.Errorf("Unsubscribe() left frequency %s", subscription.Frequency())
+	}
+}
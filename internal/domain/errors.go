@@ -3,6 +3,9 @@ package domain
 import "errors"
 
 var (
+	// Authorization
+	ErrForbidden = errors.New("forbidden")
+
 	// Comment
 	ErrCommentNotFound      = errors.New("comment not found")
 	ErrCommentCannotBeEmpty = errors.New("comment cannot be empty")
@@ -15,8 +18,100 @@ var (
 	// Rating
 	ErrRatingNotFound = errors.New("rating now found")
 
+	// Role
+	ErrRoleNotFound                 = errors.New("role not found")
+	ErrRoleAlreadyExists            = errors.New("role already exists")
+	ErrInvalidRoleName              = errors.New("role name must be 2-32 uppercase letters, digits or underscores")
+	ErrUnknownRole                  = errors.New("unknown role")
+	ErrUnknownPermission            = errors.New("unknown permission")
+	ErrBuiltInRole                  = errors.New("built-in roles cannot be deleted")
+	ErrRoleInUse                    = errors.New("role is still assigned to users")
+	ErrAdminRoleRequiresManageUsers = errors.New("the admin role must keep the users:manage permission")
+
+	// Session
+	ErrSessionNotFound = errors.New("session not found")
+
+	// Impersonation
+	ErrImpersonationNotFound       = errors.New("impersonation not found")
+	ErrCannotImpersonateSelf       = errors.New("admins cannot impersonate themselves")
+	ErrCannotImpersonateAdmin      = errors.New("users who can manage users cannot be impersonated")
+	ErrAlreadyImpersonating        = errors.New("already impersonating a user")
+	ErrNotImpersonating            = errors.New("not impersonating a user")
+	ErrImpersonationEnded          = errors.New("impersonation has already ended")
+	ErrForbiddenWhileImpersonating = errors.New("not allowed while impersonating a user")
+
 	// User
 	ErrUserNotFound       = errors.New("user not found")
 	ErrDescriptionTooLong = errors.New("description cannot exceed 255 character limit")
 	ErrMissingUserRoles   = errors.New("cannot create user without a role")
+	ErrUserLockedOut      = errors.New("user account is temporarily locked")
+	ErrUserSuspended      = errors.New("user account is suspended")
+	ErrUserBanned         = errors.New("user account is banned")
+	ErrUserNotRestricted  = errors.New("user account is neither suspended nor banned")
+	ErrMissingReason      = errors.New("a reason is required")
+	ErrSuspensionInPast   = errors.New("suspension must end in the future")
+	ErrUserDeactivated    = errors.New("user account is deactivated pending deletion")
+	ErrDeletionRequested  = errors.New("account deletion has already been requested")
+	ErrNoDeletionRequest  = errors.New("account deletion hasn't been requested")
+	ErrDeletionNotDue     = errors.New("account deletion grace period hasn't ended")
+	ErrUserAnonymised     = errors.New("user account has been anonymised")
+
+	// Password
+	ErrWeakPassword                 = errors.New("password doesn't meet the password policy")
+	ErrPasswordTooShort             = errors.New("password is too short")
+	ErrPasswordTooLong              = errors.New("password cannot exceed 72 bytes")
+	ErrPasswordTooSimple            = errors.New("password doesn't mix enough kinds of characters")
+	ErrPasswordContainsPersonalInfo = errors.New("password cannot contain the username or email")
+	ErrPasswordBreached             = errors.New("password has appeared in a data breach")
+
+	// Identity
+	ErrEmailTaken              = errors.New("email already in use")
+	ErrUsernameTaken           = errors.New("username already in use")
+	ErrEmailUnchanged          = errors.New("new email is the same as the current one")
+	ErrUsernameUnchanged       = errors.New("new username is the same as the current one")
+	ErrNoEmailChangePending    = errors.New("no email change is waiting to be confirmed")
+	ErrEmailChangeExpired      = errors.New("email change confirmation has expired")
+	ErrInvalidEmailChangeToken = errors.New("invalid email change confirmation token")
+	ErrUsernameChangeTooSoon   = errors.New("username was changed too recently")
+
+	// Profile
+	ErrDisplayNameTooLong  = errors.New("display name cannot exceed 50 characters")
+	ErrLocationTooLong     = errors.New("location cannot exceed 100 characters")
+	ErrTooManyProfileLinks = errors.New("profile cannot have more than 5 links")
+	ErrInvalidProfileLink  = errors.New("profile link must be an http(s) URL on the site it names")
+	ErrInvalidAvatar       = errors.New("avatar must be a PNG, JPEG or GIF image")
+	ErrAvatarNotFound      = errors.New("avatar not found")
+
+	// Follow
+	ErrCannotFollowSelf  = errors.New("users cannot follow themselves")
+	ErrAlreadyFollowing  = errors.New("already following this user")
+	ErrFollowNotFound    = errors.New("not following this user")
+	ErrInvalidFeedCursor = errors.New("invalid feed cursor")
+
+	// Bookmark
+	ErrBookmarkNotFound          = errors.New("bookmark not found")
+	ErrAlreadyBookmarked         = errors.New("post is already bookmarked")
+	ErrBookmarkNoteTooLong       = errors.New("bookmark note cannot exceed 500 characters")
+	ErrBookmarkFolderNotFound    = errors.New("bookmark folder not found")
+	ErrBookmarkFolderNameEmpty   = errors.New("bookmark folder name cannot be empty")
+	ErrBookmarkFolderNameTooLong = errors.New("bookmark folder name cannot exceed 50 characters")
+	ErrBookmarkFolderNameTaken   = errors.New("bookmark folder name already in use")
+
+	// Notification
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrUnknownNotificationType = errors.New("unknown notification type")
+	ErrCannotNotifySelf        = errors.New("users are not notified of their own activity")
+
+	// Digest
+	ErrUnknownDigestFrequency  = errors.New("digest frequency must be off, daily or weekly")
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe link")
+
+	// Newsletter
+	ErrNewsletterSubscriberNotFound       = errors.New("newsletter subscriber not found")
+	ErrAlreadySubscribedToNewsletter      = errors.New("already subscribed to the newsletter")
+	ErrNewsletterAddressDisabled          = errors.New("newsletter address is disabled")
+	ErrInvalidNewsletterConfirmationToken = errors.New("invalid newsletter confirmation link")
+	ErrNewsletterConfirmationExpired      = errors.New("newsletter confirmation link has expired")
+	ErrUnknownNewsletterFeedbackType      = errors.New("newsletter feedback type must be bounce or complaint")
+	ErrInvalidNewsletterWebhookSecret     = errors.New("invalid newsletter webhook secret")
 )
@@ -0,0 +1,253 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+// eventsForRoundTrip returns an event of every type the domain raises, with every field
+// set
+func eventsForRoundTrip() []ddd.DomainEvent {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	later := at.Add(time.Hour)
+	authorID := UserID("author")
+
+	return []ddd.DomainEvent{
+		NewBookmarkCreatedEvent("bookmark", "user", "post", "folder", "note", at),
+		NewBookmarkNoteUpdatedEvent("bookmark", "note", at),
+		NewBookmarkMovedEvent("bookmark", "folder", at),
+		NewBookmarkRemovedEvent("bookmark"),
+		NewBookmarkFolderCreatedEvent("folder", "user", "Reading", at),
+		NewBookmarkFolderRenamedEvent("folder", "Later"),
+		NewBookmarkFolderDeletedEvent("folder"),
+		NewCommentCreatedEvent("comment", "post", "user", "content", at, &later, &later),
+		NewCommentEditedEvent("comment", "content", at),
+		NewCommentArchivedEvent("comment", at),
+		NewCommentRestoredEvent("comment", at),
+		NewUserFollowedEvent("follow", "follower", "followee", at),
+		NewUserUnfollowedEvent("follow", "follower", "followee"),
+		NewImpersonationStartedEvent("impersonation", "admin", "user", "support", at),
+		NewImpersonationStoppedEvent("impersonation", "admin", "user", 90*time.Minute),
+		NewNewsletterSubscribedEvent("subscriber", &authorID, at),
+		NewNewsletterSubscriptionConfirmedEvent("subscriber", at),
+		NewNewsletterUnsubscribedEvent("subscriber", at),
+		NewNewsletterSubscriberDisabledEvent("subscriber", NewsletterFeedbackBounce, at),
+		NewNotificationCreatedEvent(
+			"notification",
+			"recipient",
+			NotificationTypePostCommented,
+			"actor",
+			"post",
+			"comment",
+			at,
+		),
+		NewNotificationReadEvent("notification", "recipient", at),
+		NewPostCreatedEvent("post", "title", "content", at, &later, &later),
+		NewPostTitleEditedEvent("post", "title"),
+		NewPostContentEditedEvent("post", "content"),
+		NewPostArchivedEvent("post", at),
+		NewPostRestoredEvent("post", at),
+		NewPostBecamePopularEvent("post", "bob", 10),
+		NewRatingCreatedEvent("rating", "post", "user", RatingTypeLike, at, &later),
+		NewRatingChangedEvent("rating", RatingTypeLike, at),
+		NewRatingRemovedEvent("rating"),
+		NewRoleCreatedEvent("MODERATOR", "description", []Permission{PermissionCreatePost}, at),
+		NewRolePermissionsChangedEvent("MODERATOR", []Permission{PermissionCreatePost}),
+		NewRoleDeletedEvent("MODERATOR"),
+		NewUserCreatedEvent(
+			"user",
+			"user@example.com",
+			"user",
+			"hash",
+			"description",
+			[]UserRole{UserRoleAuthor},
+			at,
+		),
+		NewUserRoleAddedEvent("user", UserRoleEditor),
+		NewUserRoleRemovedEvent("user", UserRoleEditor),
+		NewUserProfileUpdatedEvent(
+			"user",
+			"User",
+			"description",
+			"location",
+			[]ProfileLink{{Kind: LinkKindGitHub, URL: "https://github.com/user"}},
+		),
+		NewUserAvatarChangedEvent("user", "1"),
+		NewUserAvatarRemovedEvent("user"),
+		NewUserPasswordUpdatedEvent("user", "description"),
+		NewUserPasswordRehashedEvent("user"),
+		NewUserLockedOutEvent("user", at, 5),
+		NewUserUnlockedEvent("user"),
+		NewUserSuspendedEvent("user", at, "reason"),
+		NewUserBannedEvent("user", "reason"),
+		NewUserReinstatedEvent("user", UserStatusSuspended),
+		NewUserDeletionRequestedEvent("user", at),
+		NewUserDeletionCancelledEvent("user"),
+		NewUserAnonymisedEvent("user", "deleted-user-1", true),
+		NewUserEmailChangeRequestedEvent("user", "old@example.com", "new@example.com", at),
+		NewUserEmailChangedEvent("user", "old@example.com", "new@example.com"),
+		NewUserUsernameChangedEvent("user", "old", "new"),
+	}
+}
+
+func TestEventsRoundTrip(t *testing.T) {
+	covered := map[string]bool{}
+	for _, event := range eventsForRoundTrip() {
+		covered[event.EventType()] = true
+
+		t.Run(event.EventType(), func(t *testing.T) {
+			envelope, err := ddd.EventRegistry.Encode("aggregate", event)
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+
+			// Store the envelope as it would be sent or written
+			encoded, err := json.Marshal(envelope)
+			if err != nil {
+				t.Fatalf("Marshal() failed: %v", err)
+			}
+			var stored ddd.EventEnvelope
+			if err := json.Unmarshal(encoded, &stored); err != nil {
+				t.Fatalf("Unmarshal() failed: %v", err)
+			}
+
+			if stored.Type != event.EventType() || stored.Version != 1 || stored.AggregateID != "aggregate" {
+				t.Errorf("envelope = %+v, want %s version 1 of aggregate", stored, event.EventType())
+			}
+
+			got, err := ddd.EventRegistry.Decode(stored)
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+
+			if reflect.TypeOf(got) != reflect.TypeOf(event) {
+				t.Fatalf("Decode() = %T, want %T", got, event)
+			}
+			if !got.OccurredOn().Equal(event.OccurredOn()) {
+				t.Errorf("OccurredOn() = %v, want %v", got.OccurredOn(), event.OccurredOn())
+			}
+			assertExportedFieldsEqual(t, got, event)
+		})
+	}
+
+	for _, metadata := range ddd.EventRegistry.ListEvents() {
+		if !covered[metadata.Type] {
+			t.Errorf("%s is registered but not round tripped", metadata.Type)
+		}
+	}
+}
+
+func assertExportedFieldsEqual(t *testing.T, got, want ddd.DomainEvent) {
+	t.Helper()
+
+	gotValue := reflect.ValueOf(got).Elem()
+	wantValue := reflect.ValueOf(want).Elem()
+	for i := 0; i < wantValue.NumField(); i++ {
+		field := wantValue.Type().Field(i)
+		// The embedded event base is checked through OccurredOn
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		gotField := gotValue.Field(i).Interface()
+		wantField := wantValue.Field(i).Interface()
+		if !reflect.DeepEqual(gotField, wantField) {
+			t.Errorf("%s = %#v, want %#v", field.Name, gotField, wantField)
+		}
+	}
+}
+
+type testRenamedEvent struct {
+	ddd.EventBase
+
+	Name  string
+	Count int
+}
+
+func (e testRenamedEvent) EventType() string { return "TestRenamed" }
+
+func TestEventUpcasting(t *testing.T) {
+	registry := ddd.NewEventRegistry()
+	registry.Register(testRenamedEvent{}, "Raised in tests")
+
+	// Version 1 called the name "Title"
+	registry.RegisterUpcaster("TestRenamed", 1, func(payload map[string]any) (map[string]any, error) {
+		payload["Name"] = payload["Title"]
+		delete(payload, "Title")
+		return payload, nil
+	})
+	// Version 2 had no count, which starts at one
+	registry.RegisterUpcaster("TestRenamed", 2, func(payload map[string]any) (map[string]any, error) {
+		payload["Count"] = 1
+		return payload, nil
+	})
+
+	occurredOn := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		envelope ddd.EventEnvelope
+		want     testRenamedEvent
+		wantErr  bool
+	}{
+		{
+			name:     "Test Version 1 Is Upcast Twice",
+			envelope: ddd.EventEnvelope{Type: "TestRenamed", Version: 1, Payload: []byte(`{"Title":"old"}`)},
+			want:     testRenamedEvent{Name: "old", Count: 1},
+		},
+		{
+			name:     "Test Version 2 Is Upcast Once",
+			envelope: ddd.EventEnvelope{Type: "TestRenamed", Version: 2, Payload: []byte(`{"Name":"newer"}`)},
+			want:     testRenamedEvent{Name: "newer", Count: 1},
+		},
+		{
+			name:     "Test Current Version Is Decoded As Is",
+			envelope: ddd.EventEnvelope{Type: "TestRenamed", Version: 3, Payload: []byte(`{"Name":"current","Count":7}`)},
+			want:     testRenamedEvent{Name: "current", Count: 7},
+		},
+		{
+			name:     "Test Future Version Fails",
+			envelope: ddd.EventEnvelope{Type: "TestRenamed", Version: 4, Payload: []byte(`{}`)},
+			wantErr:  true,
+		},
+		{
+			name:     "Test Unregistered Type Fails",
+			envelope: ddd.EventEnvelope{Type: "TestUnknown", Version: 1, Payload: []byte(`{}`)},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.envelope.OccurredOn = occurredOn
+			got, err := registry.Decode(tt.envelope)
+			if err != nil {
+				if !tt.wantErr {
+					t.Errorf("Decode() failed: %v", err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Fatal("Decode() succeeded unexpectedly")
+			}
+
+			event := got.(*testRenamedEvent)
+			if event.Name != tt.want.Name || event.Count != tt.want.Count {
+				t.Errorf("Decode() = %+v, want %+v", *event, tt.want)
+			}
+			if !event.OccurredOn().Equal(occurredOn) {
+				t.Errorf("OccurredOn() = %v, want %v", event.OccurredOn(), occurredOn)
+			}
+		})
+	}
+
+	envelope, err := registry.Encode("aggregate", testRenamedEvent{Name: "new"})
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if envelope.Version != 3 {
+		t.Errorf("Encode() version = %d, want the current version 3", envelope.Version)
+	}
+}
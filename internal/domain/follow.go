@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"blog/pkg/ddd"
+
+	"github.com/google/uuid"
+)
+
+// Follow is a user following another user, whose posts then appear in the follower's
+// feed
+type Follow struct {
+	*ddd.AggregateBase
+	followerID UserID
+	followeeID UserID
+	createdAt  time.Time
+}
+
+func NewFollow(followerID, followeeID UserID) (*Follow, error) {
+	if followerID == followeeID {
+		return nil, ErrCannotFollowSelf
+	}
+
+	now := time.Now()
+
+	follow := &Follow{
+		AggregateBase: &ddd.AggregateBase{},
+		followerID:    followerID,
+		followeeID:    followeeID,
+		createdAt:     now,
+	}
+
+	newID := NewFollowID(uuid.New().String())
+	follow.SetID(newID)
+
+	event := NewUserFollowedEvent(follow.GetID(), followerID, followeeID, now)
+	follow.RecordEvent(event)
+
+	return follow, nil
+}
+
+func (a Follow) GetID() FollowID {
+	return FollowID(a.AggregateBase.GetID())
+}
+
+func (a *Follow) SetID(id FollowID) {
+	if id == "" {
+		return
+	}
+	a.AggregateBase.SetID(string(id))
+}
+
+func (a Follow) FollowerID() UserID   { return a.followerID }
+func (a Follow) FolloweeID() UserID   { return a.followeeID }
+func (a Follow) CreatedAt() time.Time { return a.createdAt }
+
+func (a *Follow) Unfollow() {
+	event := NewUserUnfollowedEvent(a.GetID(), a.followerID, a.followeeID)
+	a.RecordEvent(event)
+}
+
+func RebuildFollow(
+	id FollowID,
+	followerID UserID,
+	followeeID UserID,
+	createdAt time.Time,
+) *Follow {
+	follow := &Follow{
+		AggregateBase: &ddd.AggregateBase{},
+		followerID:    followerID,
+		followeeID:    followeeID,
+		createdAt:     createdAt,
+	}
+
+	follow.SetID(id)
+	return follow
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+const (
+	UserFollowedEventType   EventType = "UserFollowed"
+	UserUnfollowedEventType EventType = "UserUnfollowed"
+)
+
+type UserFollowedEvent struct {
+	ddd.EventBase
+
+	FollowID   FollowID
+	FollowerID UserID
+	FolloweeID UserID
+	CreatedAt  time.Time
+}
+
+func NewUserFollowedEvent(
+	id FollowID,
+	followerID UserID,
+	followeeID UserID,
+	createdAt time.Time,
+) *UserFollowedEvent {
+	return &UserFollowedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
+		FollowID:   id,
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		CreatedAt:  createdAt,
+	}
+}
+
+func (e UserFollowedEvent) EventType() string { return string(UserFollowedEventType) }
+
+type UserUnfollowedEvent struct {
+	ddd.EventBase
+
+	FollowID   FollowID
+	FollowerID UserID
+	FolloweeID UserID
+}
+
+func NewUserUnfollowedEvent(
+	id FollowID,
+	followerID UserID,
+	followeeID UserID,
+) *UserUnfollowedEvent {
+	return &UserUnfollowedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
+		FollowID:   id,
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	}
+}
+
+func (e UserUnfollowedEvent) EventType() string { return string(UserUnfollowedEventType) }
+
+func init() {
+	ddd.EventRegistry.Register(
+		UserFollowedEvent{},
+		"Raised when a user follows another user",
+	)
+
+	ddd.EventRegistry.Register(
+		UserUnfollowedEvent{},
+		"Raised when a user stops following another user",
+	)
+}
@@ -0,0 +1,11 @@
+package domain
+
+type FollowID string
+
+func NewFollowID(id string) FollowID {
+	return FollowID(id)
+}
+
+func (id FollowID) String() string {
+	return string(id)
+}
@@ -0,0 +1,18 @@
+package domain
+
+type FollowRepository interface {
+	// Find returns the follower's follow of the followee, or ErrFollowNotFound
+	Find(followerID, followeeID UserID) (*Follow, error)
+	// FindFollowers returns the follows of the user, newest first
+	FindFollowers(userID UserID) ([]Follow, error)
+	// FindFollowing returns the user's follows of others, newest first
+	FindFollowing(userID UserID) ([]Follow, error)
+	CountFollowers(userID UserID) (int, error)
+	CountFollowing(userID UserID) (int, error)
+	// Create fails with ErrAlreadyFollowing if the follower already follows the
+	// followee
+	Create(follow *Follow) (*Follow, error)
+	Delete(follow *Follow) error
+	// DeleteByUser removes the user's follows in both directions
+	DeleteByUser(userID UserID) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewFollow(t *testing.T) {
+	follow, err := NewFollow("alice", "bob")
+	if err != nil {
+		t.Fatalf("NewFollow() error = %v", err)
+	}
+	if follow.FollowerID() != "alice" || follow.FolloweeID() != "bob" {
+		t.Errorf("NewFollow() = %#v", follow)
+	}
+	if _, ok := follow.GetUncommittedEvents()[0].(*UserFollowedEvent); !ok {
+		t.Errorf("NewFollow() recorded %T", follow.GetUncommittedEvents()[0])
+	}
+
+	if _, err := NewFollow("alice", "alice"); !errors.Is(err, ErrCannotFollowSelf) {
+		t.Errorf("NewFollow() of yourself error = %v, want ErrCannotFollowSelf", err)
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// EmailChange is a change of email address waiting for the new address to be confirmed.
+// Only a hash of the confirmation token is kept, the token itself is sent to the new
+// address
+type EmailChange struct {
+	NewEmail  string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type IdentityKind string
+
+const (
+	IdentityKindEmail    IdentityKind = "email"
+	IdentityKindUsername IdentityKind = "username"
+)
+
+func (ik IdentityKind) String() string {
+	return string(ik)
+}
+
+// IdentityChange is one entry in the history of a user's email addresses and usernames.
+// Old usernames stay reserved until ReservedUntil, so nobody else can take them while
+// links to the old profile URL still redirect
+type IdentityChange struct {
+	UserID        UserID
+	Kind          IdentityKind
+	OldValue      string
+	NewValue      string
+	ChangedAt     time.Time
+	ReservedUntil *time.Time
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"blog/pkg/ddd"
+
+	"github.com/google/uuid"
+)
+
+// Impersonation is an admin acting as another user, kept as an audit record of who
+// impersonated whom, why, and for how long
+type Impersonation struct {
+	*ddd.AggregateBase
+	adminID   UserID
+	userID    UserID
+	reason    string
+	startedAt time.Time
+	endedAt   *time.Time
+}
+
+// StartImpersonation begins an impersonation of the user by the admin. Checking that
+// the admin is allowed to impersonate, and that the user isn't an admin, is left to
+// the caller as it depends on their roles
+func StartImpersonation(adminID, userID UserID, reason string) (*Impersonation, error) {
+	if adminID == userID {
+		return nil, ErrCannotImpersonateSelf
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrMissingReason
+	}
+
+	now := time.Now()
+
+	impersonation := &Impersonation{
+		AggregateBase: &ddd.AggregateBase{},
+		adminID:       adminID,
+		userID:        userID,
+		reason:        reason,
+		startedAt:     now,
+		endedAt:       nil,
+	}
+
+	newID := NewImpersonationID(uuid.New().String())
+	impersonation.SetID(newID)
+
+	event := NewImpersonationStartedEvent(impersonation.GetID(), adminID, userID, reason, now)
+	impersonation.RecordEvent(event)
+
+	return impersonation, nil
+}
+
+func (a Impersonation) GetID() ImpersonationID {
+	return ImpersonationID(a.AggregateBase.GetID())
+}
+
+func (a *Impersonation) SetID(id ImpersonationID) {
+	if id == "" {
+		return
+	}
+	a.AggregateBase.SetID(string(id))
+}
+
+func (a Impersonation) AdminID() UserID      { return a.adminID }
+func (a Impersonation) UserID() UserID       { return a.userID }
+func (a Impersonation) Reason() string       { return a.reason }
+func (a Impersonation) StartedAt() time.Time { return a.startedAt }
+func (a Impersonation) EndedAt() *time.Time  { return a.endedAt }
+func (a Impersonation) Active() bool         { return a.endedAt == nil }
+
+// Stop ends the impersonation, handing the session back to the admin
+func (a *Impersonation) Stop(at time.Time) error {
+	if a.endedAt != nil {
+		return ErrImpersonationEnded
+	}
+
+	a.endedAt = &at
+
+	event := NewImpersonationStoppedEvent(a.GetID(), a.adminID, a.userID, at.Sub(a.startedAt))
+	a.RecordEvent(event)
+
+	return nil
+}
+
+func RebuildImpersonation(
+	id ImpersonationID,
+	adminID UserID,
+	userID UserID,
+	reason string,
+	startedAt time.Time,
+	endedAt *time.Time,
+) *Impersonation {
+	impersonation := &Impersonation{
+		AggregateBase: &ddd.AggregateBase{},
+		adminID:       adminID,
+		userID:        userID,
+		reason:        reason,
+		startedAt:     startedAt,
+		endedAt:       endedAt,
+	}
+	impersonation.SetID(id)
+
+	return impersonation
+}
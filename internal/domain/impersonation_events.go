@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+const (
+	ImpersonationStartedEventType EventType = "ImpersonationStarted"
+	ImpersonationStoppedEventType EventType = "ImpersonationStopped"
+)
+
+type ImpersonationStartedEvent struct {
+	ddd.EventBase
+
+	ImpersonationID ImpersonationID
+	AdminID         UserID
+	UserID          UserID
+	Reason          string
+	StartedAt       time.Time
+}
+
+func NewImpersonationStartedEvent(
+	id ImpersonationID,
+	adminID UserID,
+	userID UserID,
+	reason string,
+	startedAt time.Time,
+) *ImpersonationStartedEvent {
+	return &ImpersonationStartedEvent{
+		EventBase:       ddd.NewEventBase(time.Now()),
+		ImpersonationID: id,
+		AdminID:         adminID,
+		UserID:          userID,
+		Reason:          reason,
+		StartedAt:       startedAt,
+	}
+}
+
+func (e ImpersonationStartedEvent) EventType() string {
+	return string(ImpersonationStartedEventType)
+}
+
+type ImpersonationStoppedEvent struct {
+	ddd.EventBase
+
+	ImpersonationID ImpersonationID
+	AdminID         UserID
+	UserID          UserID
+	Duration        time.Duration
+}
+
+func NewImpersonationStoppedEvent(
+	id ImpersonationID,
+	adminID UserID,
+	userID UserID,
+	duration time.Duration,
+) *ImpersonationStoppedEvent {
+	return &ImpersonationStoppedEvent{
+		EventBase:       ddd.NewEventBase(time.Now()),
+		ImpersonationID: id,
+		AdminID:         adminID,
+		UserID:          userID,
+		Duration:        duration,
+	}
+}
+
+func (e ImpersonationStoppedEvent) EventType() string {
+	return string(ImpersonationStoppedEventType)
+}
+
+func init() {
+	ddd.EventRegistry.Register(
+		ImpersonationStartedEvent{},
+		"Raised when an admin starts impersonating a user",
+	)
+
+	ddd.EventRegistry.Register(
+		ImpersonationStoppedEvent{},
+		"Raised when an admin stops impersonating a user",
+	)
+}
@@ -0,0 +1,11 @@
+package domain
+
+type ImpersonationID string
+
+func NewImpersonationID(id string) ImpersonationID {
+	return ImpersonationID(id)
+}
+
+func (id ImpersonationID) String() string {
+	return string(id)
+}
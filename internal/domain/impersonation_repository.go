@@ -0,0 +1,9 @@
+package domain
+
+type ImpersonationRepository interface {
+	All() ([]Impersonation, error)
+	FindByID(id ImpersonationID) (*Impersonation, error)
+	FindByUser(userID UserID) ([]Impersonation, error)
+	Create(impersonation *Impersonation) (*Impersonation, error)
+	End(impersonation *Impersonation) error
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartImpersonation(t *testing.T) {
+	tests := []struct {
+		name    string
+		adminID UserID
+		userID  UserID
+		reason  string
+		wantErr error
+	}{
+		{
+			name:    "Test Impersonating Another User",
+			adminID: "admin",
+			userID:  "user",
+			reason:  "debugging a broken profile page",
+		},
+		{
+			name:    "Test Impersonating Yourself Fails",
+			adminID: "admin",
+			userID:  "admin",
+			reason:  "debugging",
+			wantErr: ErrCannotImpersonateSelf,
+		},
+		{
+			name:    "Test Missing Reason Fails",
+			adminID: "admin",
+			userID:  "user",
+			reason:  "  ",
+			wantErr: ErrMissingReason,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impersonation, gotErr := StartImpersonation(tt.adminID, tt.userID, tt.reason)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("StartImpersonation() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !impersonation.Active() || impersonation.AdminID() != tt.adminID {
+				t.Errorf("StartImpersonation() = %#v", impersonation)
+			}
+			if _, ok := impersonation.GetUncommittedEvents()[0].(*ImpersonationStartedEvent); !ok {
+				t.Errorf("StartImpersonation() recorded %T", impersonation.GetUncommittedEvents()[0])
+			}
+		})
+	}
+}
+
+func TestImpersonationStop(t *testing.T) {
+	impersonation, err := StartImpersonation("admin", "user", "debugging")
+	if err != nil {
+		t.Fatalf("StartImpersonation() failed: %v", err)
+	}
+	impersonation.MarkEventsAsCommitted()
+
+	endedAt := impersonation.StartedAt().Add(time.Minute)
+	if err := impersonation.Stop(endedAt); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+	if impersonation.Active() || !impersonation.EndedAt().Equal(endedAt) {
+		t.Errorf("Stop() left ended at %v", impersonation.EndedAt())
+	}
+
+	stopped, ok := impersonation.GetUncommittedEvents()[0].(*ImpersonationStoppedEvent)
+	if !ok || stopped.Duration != time.Minute {
+		t.Errorf("Stop() recorded %#v", impersonation.GetUncommittedEvents()[0])
+	}
+
+	if err := impersonation.Stop(endedAt); !errors.Is(err, ErrImpersonationEnded) {
+		t.Errorf("Stop() twice error = %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// LoginAttempts tracks the failed login attempts made against a single throttle key,
+// such as a username or a client IP
+type LoginAttempts struct {
+	Failures      int
+	LastFailureAt time.Time
+	BlockedUntil  time.Time
+}
+
+// Blocked reports whether further attempts should be refused at the given time
+func (a LoginAttempts) Blocked(at time.Time) bool {
+	return a.BlockedUntil.After(at)
+}
+
+// LoginThrottleStore persists login attempts by key
+// Get returns empty LoginAttempts when nothing has been recorded for the key
+//
+// RecordFailure counts a failure against the key at the given time, forgetting the
+// failures before it if the last was before resetBefore, and blocks the key for the
+// backoff the new count calls for. It does so in one step, so failures recorded at the
+// same time are all counted, and returns the attempts it saved
+type LoginThrottleStore interface {
+	Get(key string) (LoginAttempts, error)
+	RecordFailure(
+		key string,
+		at, resetBefore time.Time,
+		backoff func(failures int) time.Duration,
+	) (LoginAttempts, error)
+	Delete(key string) error
+}
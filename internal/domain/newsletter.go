@@ -0,0 +1,299 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"blog/pkg/ddd"
+
+	"github.com/google/uuid"
+)
+
+type NewsletterSubscriberStatus string
+
+const (
+	// NewsletterSubscriberPending subscribers haven't confirmed their address yet
+	NewsletterSubscriberPending      NewsletterSubscriberStatus = "pending"
+	NewsletterSubscriberActive       NewsletterSubscriberStatus = "active"
+	NewsletterSubscriberUnsubscribed NewsletterSubscriberStatus = "unsubscribed"
+	// NewsletterSubscriberDisabled subscribers' address bounced or complained, so it is
+	// never emailed again
+	NewsletterSubscriberDisabled NewsletterSubscriberStatus = "disabled"
+)
+
+func (s NewsletterSubscriberStatus) String() string {
+	return string(s)
+}
+
+// NewsletterFeedbackType is what the mail provider reported about an address
+type NewsletterFeedbackType string
+
+const (
+	NewsletterFeedbackBounce    NewsletterFeedbackType = "bounce"
+	NewsletterFeedbackComplaint NewsletterFeedbackType = "complaint"
+)
+
+func (t NewsletterFeedbackType) String() string {
+	return string(t)
+}
+
+// NewsletterFeedbackTypes returns every kind of feedback a mail provider can report
+func NewsletterFeedbackTypes() []NewsletterFeedbackType {
+	return []NewsletterFeedbackType{
+		NewsletterFeedbackBounce,
+		NewsletterFeedbackComplaint,
+	}
+}
+
+func (t NewsletterFeedbackType) Valid() bool {
+	return slices.Contains(NewsletterFeedbackTypes(), t)
+}
+
+// NormaliseNewsletterEmail trims and lowercases an address, so the same reader can't
+// subscribe twice with different casing
+func NormaliseNewsletterEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NewsletterSubscriber is a reader without an account who gets new posts by email,
+// either every post on the blog or only one author's. Subscribers confirm their address
+// before anything is sent to it. Only a hash of the confirmation token is kept, the
+// token itself is sent to the address
+type NewsletterSubscriber struct {
+	*ddd.AggregateBase
+	email          string
+	authorID       *UserID
+	status         NewsletterSubscriberStatus
+	tokenHash      string
+	tokenExpiresAt *time.Time
+	createdAt      time.Time
+	confirmedAt    *time.Time
+	disabledReason NewsletterFeedbackType
+}
+
+// NewNewsletterSubscriber subscribes the address to every post, or to the author's
+// posts when an author is given, pending confirmation with the token
+func NewNewsletterSubscriber(
+	email string,
+	authorID *UserID,
+	tokenHash string,
+	tokenExpiresAt time.Time,
+) *NewsletterSubscriber {
+	now := time.Now()
+
+	subscriber := &NewsletterSubscriber{
+		AggregateBase:  &ddd.AggregateBase{},
+		email:          NormaliseNewsletterEmail(email),
+		authorID:       authorID,
+		status:         NewsletterSubscriberPending,
+		tokenHash:      tokenHash,
+		tokenExpiresAt: &tokenExpiresAt,
+		createdAt:      now,
+		confirmedAt:    nil,
+		disabledReason: "",
+	}
+
+	newID := NewNewsletterSubscriberID(uuid.New().String())
+	subscriber.SetID(newID)
+
+	event := NewNewsletterSubscribedEvent(subscriber.GetID(), authorID, now)
+	subscriber.RecordEvent(event)
+
+	return subscriber
+}
+
+func (a NewsletterSubscriber) GetID() NewsletterSubscriberID {
+	return NewsletterSubscriberID(a.AggregateBase.GetID())
+}
+
+func (a *NewsletterSubscriber) SetID(id NewsletterSubscriberID) {
+	if id == "" {
+		return
+	}
+	a.AggregateBase.SetID(string(id))
+}
+
+func (a NewsletterSubscriber) Email() string                          { return a.email }
+func (a NewsletterSubscriber) AuthorID() *UserID                      { return a.authorID }
+func (a NewsletterSubscriber) Status() NewsletterSubscriberStatus     { return a.status }
+func (a NewsletterSubscriber) TokenHash() string                      { return a.tokenHash }
+func (a NewsletterSubscriber) TokenExpiresAt() *time.Time             { return a.tokenExpiresAt }
+func (a NewsletterSubscriber) CreatedAt() time.Time                   { return a.createdAt }
+func (a NewsletterSubscriber) ConfirmedAt() *time.Time                { return a.confirmedAt }
+func (a NewsletterSubscriber) DisabledReason() NewsletterFeedbackType { return a.disabledReason }
+func (a NewsletterSubscriber) Active() bool                           { return a.status == NewsletterSubscriberActive }
+func (a NewsletterSubscriber) Disabled() bool                         { return a.status == NewsletterSubscriberDisabled }
+
+// Follows reports whether the subscriber gets the author's posts, which they do when
+// they subscribed to the whole blog
+func (a NewsletterSubscriber) Follows(authorID UserID) bool {
+	return a.authorID == nil || *a.authorID == authorID
+}
+
+// Resubscribe asks an unconfirmed or unsubscribed address to confirm again with a new
+// token. Active subscribers have nothing to confirm, and disabled addresses can't come
+// back
+func (a *NewsletterSubscriber) Resubscribe(tokenHash string, tokenExpiresAt time.Time) error {
+	switch a.status {
+	case NewsletterSubscriberActive:
+		return ErrAlreadySubscribedToNewsletter
+	case NewsletterSubscriberDisabled:
+		return ErrNewsletterAddressDisabled
+	}
+
+	a.status = NewsletterSubscriberPending
+	a.tokenHash = tokenHash
+	a.tokenExpiresAt = &tokenExpiresAt
+
+	event := NewNewsletterSubscribedEvent(a.GetID(), a.authorID, time.Now())
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// Confirm activates the subscription if the token is the one sent to the address and
+// hasn't expired
+func (a *NewsletterSubscriber) Confirm(tokenHash string, at time.Time) error {
+	if a.status != NewsletterSubscriberPending || a.tokenHash == "" ||
+		a.tokenHash != tokenHash {
+		return ErrInvalidNewsletterConfirmationToken
+	}
+	if a.tokenExpiresAt != nil && at.After(*a.tokenExpiresAt) {
+		return ErrNewsletterConfirmationExpired
+	}
+
+	a.status = NewsletterSubscriberActive
+	a.tokenHash = ""
+	a.tokenExpiresAt = nil
+	a.confirmedAt = &at
+
+	event := NewNewsletterSubscriptionConfirmedEvent(a.GetID(), at)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// Unsubscribe stops the emails. Unsubscribing twice, or after the address was
+// disabled, does nothing
+func (a *NewsletterSubscriber) Unsubscribe(at time.Time) {
+	if a.status == NewsletterSubscriberUnsubscribed || a.status == NewsletterSubscriberDisabled {
+		return
+	}
+
+	a.status = NewsletterSubscriberUnsubscribed
+	a.tokenHash = ""
+	a.tokenExpiresAt = nil
+
+	event := NewNewsletterUnsubscribedEvent(a.GetID(), at)
+	a.RecordEvent(event)
+}
+
+// Disable stops all email to the address for good after it bounced or complained.
+// Disabling an address again keeps the first reason
+func (a *NewsletterSubscriber) Disable(reason NewsletterFeedbackType, at time.Time) error {
+	if !reason.Valid() {
+		return ErrUnknownNewsletterFeedbackType
+	}
+	if a.status == NewsletterSubscriberDisabled {
+		return nil
+	}
+
+	a.status = NewsletterSubscriberDisabled
+	a.disabledReason = reason
+	a.tokenHash = ""
+	a.tokenExpiresAt = nil
+
+	event := NewNewsletterSubscriberDisabledEvent(a.GetID(), reason, at)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+func RebuildNewsletterSubscriber(
+	id NewsletterSubscriberID,
+	email string,
+	authorID *UserID,
+	status NewsletterSubscriberStatus,
+	tokenHash string,
+	tokenExpiresAt *time.Time,
+	createdAt time.Time,
+	confirmedAt *time.Time,
+	disabledReason NewsletterFeedbackType,
+) *NewsletterSubscriber {
+	subscriber := &NewsletterSubscriber{
+		AggregateBase:  &ddd.AggregateBase{},
+		email:          email,
+		authorID:       authorID,
+		status:         status,
+		tokenHash:      tokenHash,
+		tokenExpiresAt: tokenExpiresAt,
+		createdAt:      createdAt,
+		confirmedAt:    confirmedAt,
+		disabledReason: disabledReason,
+	}
+
+	subscriber.SetID(id)
+	return subscriber
+}
+
+type NewsletterDeliveryStatus string
+
+const (
+	NewsletterDeliveryPending NewsletterDeliveryStatus = "pending"
+	NewsletterDeliverySent    NewsletterDeliveryStatus = "sent"
+	// NewsletterDeliverySkipped deliveries weren't sent because the subscriber left or
+	// the post was archived before the send
+	NewsletterDeliverySkipped NewsletterDeliveryStatus = "skipped"
+	// NewsletterDeliveryFailed deliveries failed too many times to try again
+	NewsletterDeliveryFailed NewsletterDeliveryStatus = "failed"
+)
+
+func (s NewsletterDeliveryStatus) String() string {
+	return string(s)
+}
+
+// NewsletterDelivery is a post queued to be emailed to a subscriber. Each post is
+// queued once per subscriber
+type NewsletterDelivery struct {
+	SubscriberID NewsletterSubscriberID
+	PostID       PostID
+	Status       NewsletterDeliveryStatus
+	Attempts     int
+	LastError    string
+	QueuedAt     time.Time
+	SentAt       *time.Time
+}
+
+func NewNewsletterDelivery(
+	subscriberID NewsletterSubscriberID,
+	postID PostID,
+	queuedAt time.Time,
+) NewsletterDelivery {
+	return NewsletterDelivery{
+		SubscriberID: subscriberID,
+		PostID:       postID,
+		Status:       NewsletterDeliveryPending,
+		QueuedAt:     queuedAt,
+	}
+}
+
+func (d *NewsletterDelivery) MarkSent(at time.Time) {
+	d.Status = NewsletterDeliverySent
+	d.Attempts++
+	d.LastError = ""
+	d.SentAt = &at
+}
+
+func (d *NewsletterDelivery) Skip() {
+	d.Status = NewsletterDeliverySkipped
+}
+
+// RecordFailure counts a failed send, giving up once maxAttempts sends have failed
+func (d *NewsletterDelivery) RecordFailure(err error, maxAttempts int) {
+	d.Attempts++
+	d.LastError = err.Error()
+	if d.Attempts >= maxAttempts {
+		d.Status = NewsletterDeliveryFailed
+	}
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+const (
+	NewsletterSubscribedEventType            EventType = "NewsletterSubscribed"
+	NewsletterSubscriptionConfirmedEventType EventType = "NewsletterSubscriptionConfirmed"
+	NewsletterUnsubscribedEventType          EventType = "NewsletterUnsubscribed"
+	NewsletterSubscriberDisabledEventType    EventType = "NewsletterSubscriberDisabled"
+)
+
+// NewsletterSubscribedEvent carries no address, so readers' emails stay out of the logs
+type NewsletterSubscribedEvent struct {
+	ddd.EventBase
+
+	SubscriberID NewsletterSubscriberID
+	AuthorID     *UserID
+	SubscribedAt time.Time
+}
+
+func NewNewsletterSubscribedEvent(
+	id NewsletterSubscriberID,
+	authorID *UserID,
+	subscribedAt time.Time,
+) *NewsletterSubscribedEvent {
+	return &NewsletterSubscribedEvent{
+		EventBase:    ddd.NewEventBase(time.Now()),
+		SubscriberID: id,
+		AuthorID:     authorID,
+		SubscribedAt: subscribedAt,
+	}
+}
+
+func (e NewsletterSubscribedEvent) EventType() string {
+	return string(NewsletterSubscribedEventType)
+}
+
+type NewsletterSubscriptionConfirmedEvent struct {
+	ddd.EventBase
+
+	SubscriberID NewsletterSubscriberID
+	ConfirmedAt  time.Time
+}
+
+func NewNewsletterSubscriptionConfirmedEvent(
+	id NewsletterSubscriberID,
+	confirmedAt time.Time,
+) *NewsletterSubscriptionConfirmedEvent {
+	return &NewsletterSubscriptionConfirmedEvent{
+		EventBase:    ddd.NewEventBase(time.Now()),
+		SubscriberID: id,
+		ConfirmedAt:  confirmedAt,
+	}
+}
+
+func (e NewsletterSubscriptionConfirmedEvent) EventType() string {
+	return string(NewsletterSubscriptionConfirmedEventType)
+}
+
+type NewsletterUnsubscribedEvent struct {
+	ddd.EventBase
+
+	SubscriberID   NewsletterSubscriberID
+	UnsubscribedAt time.Time
+}
+
+func NewNewsletterUnsubscribedEvent(
+	id NewsletterSubscriberID,
+	unsubscribedAt time.Time,
+) *NewsletterUnsubscribedEvent {
+	return &NewsletterUnsubscribedEvent{
+		EventBase:      ddd.NewEventBase(time.Now()),
+		SubscriberID:   id,
+		UnsubscribedAt: unsubscribedAt,
+	}
+}
+
+func (e NewsletterUnsubscribedEvent) EventType() string {
+	return string(NewsletterUnsubscribedEventType)
+}
+
+type NewsletterSubscriberDisabledEvent struct {
+	ddd.EventBase
+
+	SubscriberID NewsletterSubscriberID
+	Reason       NewsletterFeedbackType
+	DisabledAt   time.Time
+}
+
+func NewNewsletterSubscriberDisabledEvent(
+	id NewsletterSubscriberID,
+	reason NewsletterFeedbackType,
+	disabledAt time.Time,
+) *NewsletterSubscriberDisabledEvent {
+	return &NewsletterSubscriberDisabledEvent{
+		EventBase:    ddd.NewEventBase(time.Now()),
+		SubscriberID: id,
+		Reason:       reason,
+		DisabledAt:   disabledAt,
+	}
+}
+
+func (e NewsletterSubscriberDisabledEvent) EventType() string {
+	return string(NewsletterSubscriberDisabledEventType)
+}
+
+func init() {
+	ddd.EventRegistry.Register(
+		NewsletterSubscribedEvent{},
+		"Raised when a reader subscribes to the newsletter, before they confirm",
+	)
+
+	ddd.EventRegistry.Register(
+		NewsletterSubscriptionConfirmedEvent{},
+		"Raised when a newsletter subscriber confirms their address",
+	)
+
+	ddd.EventRegistry.Register(
+		NewsletterUnsubscribedEvent{},
+		"Raised when a newsletter subscriber unsubscribes",
+	)
+
+	ddd.EventRegistry.Register(
+		NewsletterSubscriberDisabledEvent{},
+		"Raised when a newsletter address is disabled after a bounce or complaint",
+	)
+}
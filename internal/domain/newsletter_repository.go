@@ -0,0 +1,20 @@
+package domain
+
+type NewsletterRepository interface {
+	FindByID(id NewsletterSubscriberID) (*NewsletterSubscriber, error)
+	// FindByEmailAndAuthor returns the address's subscription to the author, or to the
+	// whole blog when the author is nil
+	FindByEmailAndAuthor(email string, authorID *UserID) (*NewsletterSubscriber, error)
+	FindByEmail(email string) ([]NewsletterSubscriber, error)
+	FindByTokenHash(tokenHash string) (*NewsletterSubscriber, error)
+	// FindActiveForAuthor lists the confirmed subscribers who get the author's posts,
+	// both the author's own and the whole blog's
+	FindActiveForAuthor(authorID UserID) ([]NewsletterSubscriber, error)
+	Save(subscriber *NewsletterSubscriber) error
+	// QueueDeliveries queues posts for subscribers, skipping any already queued
+	QueueDeliveries(deliveries []NewsletterDelivery) error
+	// FindPendingDeliveries returns up to limit deliveries waiting to be sent, oldest
+	// first
+	FindPendingDeliveries(limit int) ([]NewsletterDelivery, error)
+	SaveDelivery(delivery *NewsletterDelivery) error
+}
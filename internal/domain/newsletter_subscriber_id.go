@@ -0,0 +1,11 @@
+package domain
+
+type NewsletterSubscriberID string
+
+func NewNewsletterSubscriberID(id string) NewsletterSubscriberID {
+	return NewsletterSubscriberID(id)
+}
+
+func (id NewsletterSubscriberID) String() string {
+	return string(id)
+}
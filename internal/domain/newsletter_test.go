@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewsletterSubscriberConfirm(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		tokenHash string
+		at        time.Time
+		wantErr   error
+	}{
+		{
+			name:      "Test Confirm",
+			tokenHash: "hash",
+			at:        now,
+		},
+		{
+			name:      "Test Wrong Token Fails",
+			tokenHash: "other",
+			at:        now,
+			wantErr:   ErrInvalidNewsletterConfirmationToken,
+		},
+		{
+			name:      "Test Expired Token Fails",
+			tokenHash: "hash",
+			at:        now.Add(2 * time.Hour),
+			wantErr:   ErrNewsletterConfirmationExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriber := NewNewsletterSubscriber(" Reader@Example.com ", nil, "hash", now.Add(time.Hour))
+			if subscriber.Email() != "reader@example.com" {
+				t.Errorf("NewNewsletterSubscriber() email = %q", subscriber.Email())
+			}
+
+			gotErr := subscriber.Confirm(tt.tokenHash, tt.at)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("Confirm() error = %v, want %v", gotErr, tt.wantErr)
+			}
+
+			if got := subscriber.Active(); got != (tt.wantErr == nil) {
+				t.Errorf("Active() = %v after Confirm()", got)
+			}
+		})
+	}
+}
+
+func TestNewsletterSubscriberConfirmTwiceFails(t *testing.T) {
+	subscriber := NewNewsletterSubscriber("reader@example.com", nil, "hash", time.Now().Add(time.Hour))
+	if err := subscriber.Confirm("hash", time.Now()); err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+
+	if err := subscriber.Confirm("hash", time.Now()); !errors.Is(err, ErrInvalidNewsletterConfirmationToken) {
+		t.Errorf("Confirm() again error = %v, want ErrInvalidNewsletterConfirmationToken", err)
+	}
+}
+
+func TestNewsletterSubscriberResubscribe(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	subscriber := NewNewsletterSubscriber("reader@example.com", nil, "hash", expiresAt)
+	if err := subscriber.Confirm("hash", time.Now()); err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if err := subscriber.Resubscribe("new", expiresAt); !errors.Is(err, ErrAlreadySubscribedToNewsletter) {
+		t.Errorf("Resubscribe() while active error = %v, want ErrAlreadySubscribedToNewsletter", err)
+	}
+
+	subscriber.Unsubscribe(time.Now())
+	if err := subscriber.Resubscribe("new", expiresAt); err != nil {
+		t.Fatalf("Resubscribe() after unsubscribing error = %v", err)
+	}
+	if subscriber.Status() != NewsletterSubscriberPending || subscriber.TokenHash() != "new" {
+		t.Errorf("Resubscribe() left status %s with token %q", subscriber.Status(), subscriber.TokenHash())
+	}
+
+	if err := subscriber.Disable("spam", time.Now()); !errors.Is(err, ErrUnknownNewsletterFeedbackType) {
+		t.Errorf("Disable() error = %v, want ErrUnknownNewsletterFeedbackType", err)
+	}
+	if err := subscriber.Disable(NewsletterFeedbackBounce, time.Now()); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if subscriber.TokenHash() != "" {
+		t.Error("Disable() kept the confirmation token")
+	}
+	if err := subscriber.Resubscribe("newer", expiresAt); !errors.Is(err, ErrNewsletterAddressDisabled) {
+		t.Errorf("Resubscribe() while disabled error = %v, want ErrNewsletterAddressDisabled", err)
+	}
+
+	// Unsubscribing doesn't bring a disabled address back either
+	subscriber.Unsubscribe(time.Now())
+	if !subscriber.Disabled() {
+		t.Errorf("Unsubscribe() changed a disabled address to %s", subscriber.Status())
+	}
+}
+
+func TestNewsletterSubscriberFollows(t *testing.T) {
+	alice := NewUserID("alice")
+
+	wholeBlog := NewNewsletterSubscriber("reader@example.com", nil, "hash", time.Now())
+	aliceOnly := NewNewsletterSubscriber("reader@example.com", &alice, "hash", time.Now())
+
+	if !wholeBlog.Follows("bob") {
+		t.Error("whole blog subscriber doesn't follow bob")
+	}
+	if !aliceOnly.Follows("alice") || aliceOnly.Follows("bob") {
+		t.Error("author subscriber should only follow alice")
+	}
+}
+
+func TestNewsletterDeliveryRecordFailure(t *testing.T) {
+	delivery := NewNewsletterDelivery("subscriber", "post", time.Now())
+
+	delivery.RecordFailure(errors.New("connection refused"), 2)
+	if delivery.Status != NewsletterDeliveryPending || delivery.Attempts != 1 {
+		t.Fatalf("RecordFailure() left status %s after %d attempts", delivery.Status, delivery.Attempts)
+	}
+
+	delivery.RecordFailure(errors.New("connection refused"), 2)
+	if delivery.Status != NewsletterDeliveryFailed {
+		t.Errorf("RecordFailure() left status %s after the last attempt", delivery.Status)
+	}
+}
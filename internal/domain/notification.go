@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"time"
+
+	"blog/pkg/ddd"
+
+	"github.com/google/uuid"
+)
+
+// Notification tells a user that someone else acted on their content, e.g. commented
+// on their post. The comment is empty for notifications about ratings
+type Notification struct {
+	*ddd.AggregateBase
+	recipientID      UserID
+	notificationType NotificationType
+	actorID          UserID
+	postID           PostID
+	commentID        CommentID
+	createdAt        time.Time
+	readAt           *time.Time
+}
+
+func NewNotification(
+	recipientID UserID,
+	notificationType NotificationType,
+	actorID UserID,
+	postID PostID,
+	commentID CommentID,
+) (*Notification, error) {
+	if !notificationType.Valid() {
+		return nil, ErrUnknownNotificationType
+	}
+	if recipientID == actorID {
+		return nil, ErrCannotNotifySelf
+	}
+
+	now := time.Now()
+
+	notification := &Notification{
+		AggregateBase:    &ddd.AggregateBase{},
+		recipientID:      recipientID,
+		notificationType: notificationType,
+		actorID:          actorID,
+		postID:           postID,
+		commentID:        commentID,
+		createdAt:        now,
+		readAt:           nil,
+	}
+
+	newID := NewNotificationID(uuid.New().String())
+	notification.SetID(newID)
+
+	event := NewNotificationCreatedEvent(
+		notification.GetID(),
+		recipientID,
+		notificationType,
+		actorID,
+		postID,
+		commentID,
+		now,
+	)
+	notification.RecordEvent(event)
+
+	return notification, nil
+}
+
+func (a Notification) GetID() NotificationID {
+	return NotificationID(a.AggregateBase.GetID())
+}
+
+func (a *Notification) SetID(id NotificationID) {
+	if id == "" {
+		return
+	}
+	a.AggregateBase.SetID(string(id))
+}
+
+func (a Notification) RecipientID() UserID    { return a.recipientID }
+func (a Notification) Type() NotificationType { return a.notificationType }
+func (a Notification) ActorID() UserID        { return a.actorID }
+func (a Notification) PostID() PostID         { return a.postID }
+func (a Notification) CommentID() CommentID   { return a.commentID }
+func (a Notification) CreatedAt() time.Time   { return a.createdAt }
+func (a Notification) ReadAt() *time.Time     { return a.readAt }
+func (a Notification) Read() bool             { return a.readAt != nil }
+
+// OwnerID returns the user the notification was sent to, for authorization
+func (a Notification) OwnerID() UserID { return a.recipientID }
+
+// MarkRead marks the notification read at the given time. Marking a read notification
+// again does nothing, so it keeps the time it was first read
+func (a *Notification) MarkRead(at time.Time) {
+	if a.readAt != nil {
+		return
+	}
+
+	a.readAt = &at
+
+	event := NewNotificationReadEvent(a.GetID(), a.recipientID, at)
+	a.RecordEvent(event)
+}
+
+func RebuildNotification(
+	id NotificationID,
+	recipientID UserID,
+	notificationType NotificationType,
+	actorID UserID,
+	postID PostID,
+	commentID CommentID,
+	createdAt time.Time,
+	readAt *time.Time,
+) *Notification {
+	notification := &Notification{
+		AggregateBase:    &ddd.AggregateBase{},
+		recipientID:      recipientID,
+		notificationType: notificationType,
+		actorID:          actorID,
+		postID:           postID,
+		commentID:        commentID,
+		createdAt:        createdAt,
+		readAt:           readAt,
+	}
+
+	notification.SetID(id)
+	return notification
+}
+
+// NotificationPreferences are the types of notification a user has muted. Users start
+// with nothing muted
+type NotificationPreferences struct {
+	userID UserID
+	muted  []NotificationType
+}
+
+// NewNotificationPreferences returns ErrUnknownNotificationType if any of the muted
+// types doesn't exist. Duplicates are dropped
+func NewNotificationPreferences(
+	userID UserID,
+	muted []NotificationType,
+) (*NotificationPreferences, error) {
+	preferences := &NotificationPreferences{
+		userID: userID,
+		muted:  []NotificationType{},
+	}
+
+	for _, notificationType := range muted {
+		if !notificationType.Valid() {
+			return nil, ErrUnknownNotificationType
+		}
+		if !preferences.Mutes(notificationType) {
+			preferences.muted = append(preferences.muted, notificationType)
+		}
+	}
+
+	return preferences, nil
+}
+
+func (p NotificationPreferences) UserID() UserID            { return p.userID }
+func (p NotificationPreferences) Muted() []NotificationType { return p.muted }
+
+// Mutes reports whether the user doesn't want notifications of the type
+func (p NotificationPreferences) Mutes(notificationType NotificationType) bool {
+	for _, muted := range p.muted {
+		if muted == notificationType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+const (
+	NotificationCreatedEventType EventType = "NotificationCreated"
+	NotificationReadEventType    EventType = "NotificationRead"
+)
+
+type NotificationCreatedEvent struct {
+	ddd.EventBase
+
+	NotificationID NotificationID
+	RecipientID    UserID
+	Type           NotificationType
+	ActorID        UserID
+	PostID         PostID
+	CommentID      CommentID
+	CreatedAt      time.Time
+}
+
+func NewNotificationCreatedEvent(
+	id NotificationID,
+	recipientID UserID,
+	notificationType NotificationType,
+	actorID UserID,
+	postID PostID,
+	commentID CommentID,
+	createdAt time.Time,
+) *NotificationCreatedEvent {
+	return &NotificationCreatedEvent{
+		EventBase:      ddd.NewEventBase(time.Now()),
+		NotificationID: id,
+		RecipientID:    recipientID,
+		Type:           notificationType,
+		ActorID:        actorID,
+		PostID:         postID,
+		CommentID:      commentID,
+		CreatedAt:      createdAt,
+	}
+}
+
+func (e NotificationCreatedEvent) EventType() string {
+	return string(NotificationCreatedEventType)
+}
+
+type NotificationReadEvent struct {
+	ddd.EventBase
+
+	NotificationID NotificationID
+	RecipientID    UserID
+	ReadAt         time.Time
+}
+
+func NewNotificationReadEvent(
+	id NotificationID,
+	recipientID UserID,
+	readAt time.Time,
+) *NotificationReadEvent {
+	return &NotificationReadEvent{
+		EventBase:      ddd.NewEventBase(time.Now()),
+		NotificationID: id,
+		RecipientID:    recipientID,
+		ReadAt:         readAt,
+	}
+}
+
+func (e NotificationReadEvent) EventType() string { return string(NotificationReadEventType) }
+
+func init() {
+	ddd.EventRegistry.Register(
+		NotificationCreatedEvent{},
+		"Raised when a user is notified of activity on their content",
+	)
+
+	ddd.EventRegistry.Register(
+		NotificationReadEvent{},
+		"Raised when a user reads a notification",
+	)
+}
@@ -0,0 +1,11 @@
+package domain
+
+type NotificationID string
+
+func NewNotificationID(id string) NotificationID {
+	return NotificationID(id)
+}
+
+func (id NotificationID) String() string {
+	return string(id)
+}
@@ -0,0 +1,17 @@
+package domain
+
+type NotificationRepository interface {
+	FindByID(id NotificationID) (*Notification, error)
+	// FindByRecipient lists the user's notifications newest first, only the unread ones
+	// when unreadOnly is set
+	FindByRecipient(recipientID UserID, unreadOnly bool) ([]Notification, error)
+	CountUnread(recipientID UserID) (int, error)
+	Create(notification *Notification) (*Notification, error)
+	MarkRead(notification *Notification) error
+	// FindPreferences returns the user's preferences, with nothing muted if they've
+	// never changed them
+	FindPreferences(userID UserID) (*NotificationPreferences, error)
+	SavePreferences(preferences *NotificationPreferences) error
+	// DeleteByUser removes the notifications sent to the user and their preferences
+	DeleteByUser(userID UserID) error
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewNotification(t *testing.T) {
+	tests := []struct {
+		name             string
+		recipientID      UserID
+		notificationType NotificationType
+		wantErr          error
+	}{
+		{
+			name:             "Test Comment Notification",
+			recipientID:      "alice",
+			notificationType: NotificationTypePostCommented,
+		},
+		{
+			name:             "Test Own Activity Fails",
+			recipientID:      "bob",
+			notificationType: NotificationTypePostCommented,
+			wantErr:          ErrCannotNotifySelf,
+		},
+		{
+			name:             "Test Unknown Type Fails",
+			recipientID:      "alice",
+			notificationType: "post_shared",
+			wantErr:          ErrUnknownNotificationType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification, gotErr := NewNotification(
+				tt.recipientID,
+				tt.notificationType,
+				"bob",
+				"post",
+				"comment",
+			)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("NewNotification() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if notification.Read() {
+				t.Error("NewNotification() is already read")
+			}
+			if _, ok := notification.GetUncommittedEvents()[0].(*NotificationCreatedEvent); !ok {
+				t.Errorf("NewNotification() recorded %T", notification.GetUncommittedEvents()[0])
+			}
+		})
+	}
+}
+
+func TestNotificationMarkRead(t *testing.T) {
+	notification, err := NewNotification("alice", NotificationTypePostLiked, "bob", "post", "")
+	if err != nil {
+		t.Fatalf("NewNotification() failed: %v", err)
+	}
+	notification.MarkEventsAsCommitted()
+
+	readAt := time.Now()
+	notification.MarkRead(readAt)
+	notification.MarkRead(readAt.Add(time.Hour))
+
+	if notification.ReadAt() == nil || !notification.ReadAt().Equal(readAt) {
+		t.Errorf("MarkRead() read at %v, want %v", notification.ReadAt(), readAt)
+	}
+	if got := len(notification.GetUncommittedEvents()); got != 1 {
+		t.Errorf("MarkRead() twice recorded %d events, want 1", got)
+	}
+}
+
+func TestNewNotificationPreferences(t *testing.T) {
+	preferences, err := NewNotificationPreferences("alice", []NotificationType{
+		NotificationTypePostDisliked,
+		NotificationTypePostDisliked,
+	})
+	if err != nil {
+		t.Fatalf("NewNotificationPreferences() error = %v", err)
+	}
+	if len(preferences.Muted()) != 1 || !preferences.Mutes(NotificationTypePostDisliked) {
+		t.Errorf("NewNotificationPreferences() muted = %v", preferences.Muted())
+	}
+	if preferences.Mutes(NotificationTypePostLiked) {
+		t.Error("NewNotificationPreferences() mutes a type that wasn't muted")
+	}
+
+	_, err = NewNotificationPreferences("alice", []NotificationType{"post_shared"})
+	if !errors.Is(err, ErrUnknownNotificationType) {
+		t.Errorf("NewNotificationPreferences() error = %v, want ErrUnknownNotificationType", err)
+	}
+}
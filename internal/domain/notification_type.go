@@ -0,0 +1,51 @@
+package domain
+
+import "slices"
+
+type NotificationType string
+
+const (
+	NotificationTypePostCommented  NotificationType = "post_commented"
+	NotificationTypeCommentReplied NotificationType = "comment_replied"
+	NotificationTypePostLiked      NotificationType = "post_liked"
+	NotificationTypePostDisliked   NotificationType = "post_disliked"
+	// NotificationTypePostArchived tells commenters that a post they commented on was
+	// archived, along with their comments
+	NotificationTypePostArchived NotificationType = "post_archived"
+	// NotificationTypePostPopular tells an author their post has been liked enough times
+	// to become popular
+	NotificationTypePostPopular NotificationType = "post_popular"
+	// NotificationTypeAnnouncementCommented tells admins of comments on posts by other
+	// admins, which are the site's announcements
+	NotificationTypeAnnouncementCommented NotificationType = "announcement_commented"
+)
+
+func (t NotificationType) String() string {
+	return string(t)
+}
+
+// NotificationTypes returns every type of notification, each of which users can mute
+func NotificationTypes() []NotificationType {
+	return []NotificationType{
+		NotificationTypePostCommented,
+		NotificationTypeCommentReplied,
+		NotificationTypePostLiked,
+		NotificationTypePostDisliked,
+		NotificationTypePostArchived,
+		NotificationTypePostPopular,
+		NotificationTypeAnnouncementCommented,
+	}
+}
+
+func (t NotificationType) Valid() bool {
+	return slices.Contains(NotificationTypes(), t)
+}
+
+// NotificationTypeForRating returns the type of notification a post's author gets when
+// the post is rated
+func NotificationTypeForRating(ratingType RatingType) NotificationType {
+	if ratingType == RatingTypeDislike {
+		return NotificationTypePostDisliked
+	}
+	return NotificationTypePostLiked
+}
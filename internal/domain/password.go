@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes is the longest password bcrypt will hash
+const maxPasswordBytes = 72
+
+// minPersonalInfoLength is the shortest username or email name a password is checked
+// against, so a two letter username doesn't rule out every password containing it
+const minPersonalInfoLength = 3
+
+// PasswordHasher hashes passwords for storage and checks passwords against stored hashes
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Compare returns an error unless the password matches the hash
+	Compare(passwordHash, password string) error
+	// NeedsRehash reports whether the hash was made with an outdated algorithm or cost
+	NeedsRehash(passwordHash string) bool
+}
+
+// BreachedPasswordList looks up passwords known from data breaches. Implementations
+// compare SHA-1 hashes, k-anonymity style, so the password itself is never stored
+type BreachedPasswordList interface {
+	Contains(password string) (bool, error)
+}
+
+// PasswordPolicy is what a new password must satisfy
+type PasswordPolicy struct {
+	// MinLength is the fewest characters a password may have
+	MinLength int
+	// MinCharacterClasses is how many of lowercase letters, uppercase letters, digits
+	// and symbols the password must mix
+	MinCharacterClasses int
+	// AllowPersonalInfo lets the password contain the username or the email's name
+	AllowPersonalInfo bool
+}
+
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:           8,
+		MinCharacterClasses: 2,
+		AllowPersonalInfo:   false,
+	}
+}
+
+// Check returns a PasswordPolicyError listing every rule the password breaks, or nil
+// if it satisfies the policy
+func (p PasswordPolicy) Check(password, username, email string) error {
+	violations := []error{}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, ErrPasswordTooShort)
+	}
+	if len(password) > maxPasswordBytes {
+		violations = append(violations, ErrPasswordTooLong)
+	}
+	if characterClasses(password) < p.MinCharacterClasses {
+		violations = append(violations, ErrPasswordTooSimple)
+	}
+	if !p.AllowPersonalInfo && containsPersonalInfo(password, username, email) {
+		violations = append(violations, ErrPasswordContainsPersonalInfo)
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// PasswordPolicyError lists the rules a password breaks. It matches ErrWeakPassword and
+// each of its violations with errors.Is
+type PasswordPolicyError struct {
+	Violations []error
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := []string{}
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Error())
+	}
+	return ErrWeakPassword.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	if target == ErrWeakPassword {
+		return true
+	}
+	for _, violation := range e.Violations {
+		if violation == target {
+			return true
+		}
+	}
+	return false
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
+
+// containsPersonalInfo reports whether the password contains the username or the part
+// of the email before the @, ignoring case
+func containsPersonalInfo(password, username, email string) bool {
+	password = strings.ToLower(password)
+
+	name, _, _ := strings.Cut(email, "@")
+	for _, info := range []string{username, name} {
+		info = strings.ToLower(info)
+		if utf8.RuneCountInString(info) >= minPersonalInfoLength && strings.Contains(password, info) {
+			return true
+		}
+	}
+
+	return false
+}
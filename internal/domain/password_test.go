@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:           10,
+		MinCharacterClasses: 3,
+		AllowPersonalInfo:   false,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErrs []error
+	}{
+		{
+			name:     "Test Strong Password",
+			password: "Correct-Horse-42",
+		},
+		{
+			name:     "Test Short Password Fails",
+			password: "Ab1!",
+			wantErrs: []error{ErrPasswordTooShort},
+		},
+		{
+			name:     "Test Too Few Character Classes Fails",
+			password: "lowercaseonly",
+			wantErrs: []error{ErrPasswordTooSimple},
+		},
+		{
+			name:     "Test Username Fails",
+			password: "xxAlice2024!",
+			wantErrs: []error{ErrPasswordContainsPersonalInfo},
+		},
+		{
+			name:     "Test Email Name Fails",
+			password: "Wonderland99",
+			wantErrs: []error{ErrPasswordContainsPersonalInfo},
+		},
+		{
+			name:     "Test Every Violation Is Reported",
+			password: "alice",
+			wantErrs: []error{ErrPasswordTooShort, ErrPasswordTooSimple, ErrPasswordContainsPersonalInfo},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotErr := policy.Check(tt.password, "alice", "wonderland@example.com")
+			if len(tt.wantErrs) == 0 {
+				if gotErr != nil {
+					t.Fatalf("Check() error = %v, want nil", gotErr)
+				}
+				return
+			}
+
+			if !errors.Is(gotErr, ErrWeakPassword) {
+				t.Fatalf("Check() error = %v, want ErrWeakPassword", gotErr)
+			}
+			for _, wantErr := range tt.wantErrs {
+				if !errors.Is(gotErr, wantErr) {
+					t.Errorf("Check() error = %v, want %v", gotErr, wantErr)
+				}
+			}
+		})
+	}
+}
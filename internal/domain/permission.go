@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+type Permission string
+
+const (
+	PermissionCreatePost     Permission = "posts:create"
+	PermissionEditOwnPost    Permission = "posts:edit:own"
+	PermissionEditAnyPost    Permission = "posts:edit:any"
+	PermissionArchiveOwnPost Permission = "posts:archive:own"
+	PermissionArchiveAnyPost Permission = "posts:archive:any"
+
+	PermissionCreateComment     Permission = "comments:create"
+	PermissionEditOwnComment    Permission = "comments:edit:own"
+	PermissionEditAnyComment    Permission = "comments:edit:any"
+	PermissionArchiveOwnComment Permission = "comments:archive:own"
+	PermissionArchiveAnyComment Permission = "comments:archive:any"
+
+	PermissionCreateRating    Permission = "ratings:create"
+	PermissionChangeOwnRating Permission = "ratings:change:own"
+	PermissionRemoveOwnRating Permission = "ratings:remove:own"
+	PermissionRemoveAnyRating Permission = "ratings:remove:any"
+
+	PermissionManageUsers      Permission = "users:manage"
+	PermissionImpersonateUsers Permission = "users:impersonate"
+)
+
+func (p Permission) String() string {
+	return string(p)
+}
+
+// Permissions returns every permission that can be granted to a role
+func Permissions() []Permission {
+	return []Permission{
+		PermissionCreatePost,
+		PermissionEditOwnPost,
+		PermissionEditAnyPost,
+		PermissionArchiveOwnPost,
+		PermissionArchiveAnyPost,
+		PermissionCreateComment,
+		PermissionEditOwnComment,
+		PermissionEditAnyComment,
+		PermissionArchiveOwnComment,
+		PermissionArchiveAnyComment,
+		PermissionCreateRating,
+		PermissionChangeOwnRating,
+		PermissionRemoveOwnRating,
+		PermissionRemoveAnyRating,
+		PermissionManageUsers,
+		PermissionImpersonateUsers,
+	}
+}
+
+func (p Permission) Valid() bool {
+	return slices.Contains(Permissions(), p)
+}
+
+// BuiltInRoles returns the roles every installation starts with
+// The sqlite migrations seed the same roles
+func BuiltInRoles() []*Role {
+	return []*Role{
+		RebuildRole(
+			UserRoleCommenter,
+			"Can comment on and rate posts",
+			[]Permission{
+				PermissionCreateComment,
+				PermissionEditOwnComment,
+				PermissionArchiveOwnComment,
+				PermissionCreateRating,
+				PermissionChangeOwnRating,
+				PermissionRemoveOwnRating,
+			},
+			true,
+			time.Time{},
+		),
+		RebuildRole(
+			UserRoleAuthor,
+			"Can write posts",
+			[]Permission{
+				PermissionCreatePost,
+				PermissionEditOwnPost,
+				PermissionArchiveOwnPost,
+			},
+			true,
+			time.Time{},
+		),
+		RebuildRole(
+			UserRoleEditor,
+			"Can moderate other users' posts and comments",
+			[]Permission{
+				PermissionEditAnyPost,
+				PermissionArchiveAnyPost,
+				PermissionArchiveAnyComment,
+			},
+			true,
+			time.Time{},
+		),
+		RebuildRole(
+			UserRoleAdmin,
+			"Can do everything, including managing users and roles",
+			[]Permission{
+				PermissionCreatePost,
+				PermissionEditAnyPost,
+				PermissionArchiveAnyPost,
+				PermissionCreateComment,
+				PermissionEditAnyComment,
+				PermissionArchiveAnyComment,
+				PermissionCreateRating,
+				PermissionChangeOwnRating,
+				PermissionRemoveAnyRating,
+				PermissionManageUsers,
+				PermissionImpersonateUsers,
+			},
+			true,
+			time.Time{},
+		),
+	}
+}
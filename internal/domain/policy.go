@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"fmt"
+
+	"blog/pkg/ddd/specifications"
+)
+
+type Action string
+
+const (
+	ActionCreatePost     Action = "CreatePost"
+	ActionEditPost       Action = "EditPost"
+	ActionArchivePost    Action = "ArchivePost"
+	ActionCreateComment  Action = "CreateComment"
+	ActionEditComment    Action = "EditComment"
+	ActionArchiveComment Action = "ArchiveComment"
+	ActionCreateRating   Action = "CreateRating"
+	ActionChangeRating   Action = "ChangeRating"
+	ActionRemoveRating   Action = "RemoveRating"
+	ActionManageUsers    Action = "ManageUsers"
+	ActionImpersonate    Action = "Impersonate"
+)
+
+func (a Action) String() string {
+	return string(a)
+}
+
+// OwnedResource is implemented by aggregates that belong to a user
+type OwnedResource interface {
+	OwnerID() UserID
+}
+
+// Actor is the user an action is being performed by, along with what they're permitted to do
+type Actor struct {
+	id          UserID
+	permissions map[Permission]bool
+}
+
+func NewActor(id UserID, permissions []Permission) Actor {
+	setPermissions := map[Permission]bool{}
+	for _, permission := range permissions {
+		setPermissions[permission] = true
+	}
+
+	return Actor{
+		id:          id,
+		permissions: setPermissions,
+	}
+}
+
+func (a Actor) ID() UserID { return a.id }
+
+func (a Actor) HasPermission(permission Permission) bool {
+	return a.permissions[permission]
+}
+
+// AccessRequest is what policy rules are evaluated against
+type AccessRequest struct {
+	Actor    Actor
+	Action   Action
+	Resource OwnedResource
+}
+
+type hasPermissionSpecification struct {
+	permission Permission
+}
+
+// HasPermission is satisfied when the actor has been granted the permission
+func HasPermission(permission Permission) specifications.Specification[AccessRequest] {
+	return hasPermissionSpecification{permission: permission}
+}
+
+func (s hasPermissionSpecification) IsSatisfiedBy(request AccessRequest) bool {
+	return request.Actor.HasPermission(s.permission)
+}
+
+func (s hasPermissionSpecification) String() string {
+	return fmt.Sprintf("has permission %s", s.permission)
+}
+
+type isOwnerSpecification struct{}
+
+// IsOwner is satisfied when the resource belongs to the actor
+func IsOwner() specifications.Specification[AccessRequest] {
+	return isOwnerSpecification{}
+}
+
+func (s isOwnerSpecification) IsSatisfiedBy(request AccessRequest) bool {
+	return request.Resource != nil && request.Resource.OwnerID() == request.Actor.ID()
+}
+
+func (s isOwnerSpecification) String() string {
+	return "owns resource"
+}
+
+// Policy decides which actors may perform which actions, with a rule per action
+// Actions without a rule are always denied
+type Policy struct {
+	rules map[Action]specifications.Specification[AccessRequest]
+}
+
+func NewPolicy(rules map[Action]specifications.Specification[AccessRequest]) *Policy {
+	return &Policy{
+		rules: rules,
+	}
+}
+
+// ownOrAny allows the action on the actor's own resources with the first permission,
+// and on anyone's with the second
+func ownOrAny(ownPermission, anyPermission Permission) specifications.Specification[AccessRequest] {
+	return specifications.Or(
+		specifications.And(HasPermission(ownPermission), IsOwner()),
+		HasPermission(anyPermission),
+	)
+}
+
+// DefaultPolicy returns the rules for the built-in actions
+func DefaultPolicy() *Policy {
+	return NewPolicy(map[Action]specifications.Specification[AccessRequest]{
+		ActionCreatePost:     HasPermission(PermissionCreatePost),
+		ActionEditPost:       ownOrAny(PermissionEditOwnPost, PermissionEditAnyPost),
+		ActionArchivePost:    ownOrAny(PermissionArchiveOwnPost, PermissionArchiveAnyPost),
+		ActionCreateComment:  HasPermission(PermissionCreateComment),
+		ActionEditComment:    ownOrAny(PermissionEditOwnComment, PermissionEditAnyComment),
+		ActionArchiveComment: ownOrAny(PermissionArchiveOwnComment, PermissionArchiveAnyComment),
+		ActionCreateRating:   HasPermission(PermissionCreateRating),
+		ActionChangeRating:   specifications.And(HasPermission(PermissionChangeOwnRating), IsOwner()),
+		ActionRemoveRating:   ownOrAny(PermissionRemoveOwnRating, PermissionRemoveAnyRating),
+		ActionManageUsers:    HasPermission(PermissionManageUsers),
+		ActionImpersonate:    HasPermission(PermissionImpersonateUsers),
+	})
+}
+
+// Authorize returns an error wrapping ErrForbidden unless the actor may perform the action
+// on the resource. resource may be nil for actions that don't target an existing aggregate
+func (p *Policy) Authorize(actor Actor, action Action, resource OwnedResource) error {
+	rule, ok := p.rules[action]
+	if !ok {
+		return fmt.Errorf("%w: no rule for %s", ErrForbidden, action)
+	}
+
+	request := AccessRequest{
+		Actor:    actor,
+		Action:   action,
+		Resource: resource,
+	}
+	if !rule.IsSatisfiedBy(request) {
+		return fmt.Errorf("%w: %s requires %s", ErrForbidden, action, rule)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func builtInActor(id UserID, roleNames ...UserRole) Actor {
+	roles := []Role{}
+	for _, role := range BuiltInRoles() {
+		if slices.Contains(roleNames, role.Name()) {
+			roles = append(roles, *role)
+		}
+	}
+	return NewActor(id, PermissionsForRoles(roles))
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	policy := DefaultPolicy()
+
+	author := builtInActor("author", UserRoleCommenter, UserRoleAuthor)
+	otherAuthor := builtInActor("other", UserRoleCommenter, UserRoleAuthor)
+	editor := builtInActor("editor", UserRoleCommenter, UserRoleEditor)
+	commenter := builtInActor("commenter", UserRoleCommenter)
+	admin := builtInActor("admin", UserRoleAdmin)
+
+	post := RebuildPost("post", "author", "title", "content", time.Now(), nil, nil, nil)
+	comment := RebuildComment("comment", "post", "commenter", "content", time.Now(), nil, nil)
+
+	tests := []struct {
+		name     string
+		actor    Actor
+		action   Action
+		resource OwnedResource
+		wantErr  bool
+	}{
+		{"author may create posts", author, ActionCreatePost, nil, false},
+		{"commenter may not create posts", commenter, ActionCreatePost, nil, true},
+		{"author may edit own post", author, ActionEditPost, post, false},
+		{"author may not edit another's post", otherAuthor, ActionEditPost, post, true},
+		{"editor may edit any post", editor, ActionEditPost, post, false},
+		{"editor may archive any post", editor, ActionArchivePost, post, false},
+		{"commenter may edit own comment", commenter, ActionEditComment, comment, false},
+		{"author may not edit another's comment", author, ActionEditComment, comment, true},
+		{"editor may not edit another's comment", editor, ActionEditComment, comment, true},
+		{"editor may archive any comment", editor, ActionArchiveComment, comment, false},
+		{"ownership rules deny without a resource", author, ActionEditPost, nil, true},
+		{"admin may manage users", admin, ActionManageUsers, nil, false},
+		{"editor may not manage users", editor, ActionManageUsers, nil, true},
+		{"actions without a rule are denied", admin, Action("Unknown"), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotErr := policy.Authorize(tt.actor, tt.action, tt.resource)
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("Authorize() failed: %v", gotErr)
+				}
+				if !errors.Is(gotErr, ErrForbidden) {
+					t.Errorf("Authorize() = %v, want ErrForbidden", gotErr)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Fatal("Authorize() succeeded unexpectedly")
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ type Post struct {
 	createdAt    time.Time
 	lastEditedAt *time.Time
 	archivedAt   *time.Time
+	popularAt    *time.Time
 }
 
 func NewPost(authorID UserID, title string, content string) (*Post, error) {
@@ -37,6 +38,7 @@ func NewPost(authorID UserID, title string, content string) (*Post, error) {
 		createdAt:     now,
 		lastEditedAt:  nil,
 		archivedAt:    nil,
+		popularAt:     nil,
 	}
 
 	newID := NewPostID(uuid.New().String())
@@ -66,6 +68,16 @@ func (a Post) CreatedAt() time.Time     { return a.createdAt }
 func (a Post) LastEditedAt() *time.Time { return a.lastEditedAt }
 func (a Post) Archived() bool           { return a.archivedAt != nil }
 func (a Post) ArchivedAt() *time.Time   { return a.archivedAt }
+func (a Post) Popular() bool            { return a.popularAt != nil }
+func (a Post) PopularAt() *time.Time    { return a.popularAt }
+
+// OwnerID returns the user the post belongs to, for authorization
+func (a Post) OwnerID() UserID { return a.authorID }
+
+// Position returns where the post sits in a list of posts ordered newest first
+func (a Post) Position() PostPosition {
+	return PostPosition{CreatedAt: a.createdAt, ID: a.GetID()}
+}
 
 func (a *Post) EditTitle(title string) error {
 	if title == "" {
@@ -101,6 +113,32 @@ func (a *Post) Archive() {
 	a.RecordEvent(event)
 }
 
+// Restore brings back an archived post. Restoring a post that isn't archived does
+// nothing
+func (a *Post) Restore() {
+	if a.archivedAt == nil {
+		return
+	}
+	a.archivedAt = nil
+
+	event := NewPostRestoredEvent(a.GetID(), time.Now())
+	a.RecordEvent(event)
+}
+
+// MarkPopular makes the post popular, as it has the given number of likes, the last of
+// them from likedBy. A post is only made popular once, so marking a popular post does
+// nothing
+func (a *Post) MarkPopular(likedBy UserID, likes int) {
+	if a.popularAt != nil {
+		return
+	}
+	now := time.Now()
+	a.popularAt = &now
+
+	event := NewPostBecamePopularEvent(a.GetID(), likedBy, likes)
+	a.RecordEvent(event)
+}
+
 func RebuildPost(
 	id PostID,
 	authorID UserID,
@@ -109,6 +147,7 @@ func RebuildPost(
 	createdAt time.Time,
 	lastEditedAt *time.Time,
 	archivedAt *time.Time,
+	popularAt *time.Time,
 ) *Post {
 	post := &Post{
 		AggregateBase: &ddd.AggregateBase{},
@@ -118,7 +157,24 @@ func RebuildPost(
 		createdAt:     createdAt,
 		lastEditedAt:  lastEditedAt,
 		archivedAt:    archivedAt,
+		popularAt:     popularAt,
 	}
 	post.SetID(id)
 	return post
 }
+
+// PostPosition is a post's place in a list of posts ordered newest first. Posts created
+// at the same time are ordered by ID
+type PostPosition struct {
+	CreatedAt time.Time
+	ID        PostID
+}
+
+// Before reports whether the position comes before the other in a list ordered newest
+// first
+func (p PostPosition) Before(other PostPosition) bool {
+	if !p.CreatedAt.Equal(other.CreatedAt) {
+		return p.CreatedAt.After(other.CreatedAt)
+	}
+	return p.ID > other.ID
+}
@@ -11,16 +11,19 @@ const (
 	PostTitleEditedEventType   EventType = "PostTitleEdited"
 	PostContentEditedEventType EventType = "PostContentEdited"
 	PostArchivedEventType      EventType = "PostArchived"
+	PostRestoredEventType      EventType = "PostRestored"
+	PostBecamePopularEventType EventType = "PostBecamePopular"
 )
 
 type PostCreatedEvent struct {
+	ddd.EventBase
+
 	PostID       PostID
 	Title        string
 	Content      string
 	CreatedAt    time.Time
 	LastEditedAt *time.Time
 	ArchivedAt   *time.Time
-	occurredOn   time.Time
 }
 
 func NewPostCreatedEvent(
@@ -31,69 +34,106 @@ func NewPostCreatedEvent(
 	archivedAt *time.Time,
 ) *PostCreatedEvent {
 	return &PostCreatedEvent{
+		EventBase:    ddd.NewEventBase(time.Now()),
 		PostID:       id,
 		Title:        title,
 		Content:      content,
 		CreatedAt:    created,
 		LastEditedAt: lastEdited,
 		ArchivedAt:   archivedAt,
-		occurredOn:   time.Now(),
 	}
 }
 
-func (e PostCreatedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e PostCreatedEvent) EventType() string     { return string(PostCreatedEventType) }
+func (e PostCreatedEvent) EventType() string { return string(PostCreatedEventType) }
 
 type PostTitleEditedEvent struct {
-	PostID     PostID
-	NewTitle   string
-	occurredOn time.Time
+	ddd.EventBase
+
+	PostID   PostID
+	NewTitle string
 }
 
 func NewPostTitleEditedEvent(id PostID, newTitle string) *PostTitleEditedEvent {
 	return &PostTitleEditedEvent{
-		PostID:     id,
-		NewTitle:   newTitle,
-		occurredOn: time.Now(),
+		EventBase: ddd.NewEventBase(time.Now()),
+		PostID:    id,
+		NewTitle:  newTitle,
 	}
 }
 
-func (e PostTitleEditedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e PostTitleEditedEvent) EventType() string     { return string(PostTitleEditedEventType) }
+func (e PostTitleEditedEvent) EventType() string { return string(PostTitleEditedEventType) }
 
 type PostContentEditedEvent struct {
+	ddd.EventBase
+
 	PostID     PostID
 	NewContent string
-	occurredOn time.Time
 }
 
 func NewPostContentEditedEvent(id PostID, newContent string) *PostContentEditedEvent {
 	return &PostContentEditedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
 		PostID:     id,
 		NewContent: newContent,
-		occurredOn: time.Now(),
 	}
 }
 
-func (e PostContentEditedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e PostContentEditedEvent) EventType() string     { return string(PostContentEditedEventType) }
+func (e PostContentEditedEvent) EventType() string { return string(PostContentEditedEventType) }
 
 type PostArchivedEvent struct {
+	ddd.EventBase
+
 	PostID     PostID
 	ArchivedAt time.Time
-	occurredOn time.Time
 }
 
 func NewPostArchivedEvent(id PostID, archivedAt time.Time) *PostArchivedEvent {
 	return &PostArchivedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
 		PostID:     id,
 		ArchivedAt: archivedAt,
-		occurredOn: time.Now(),
 	}
 }
 
-func (e PostArchivedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e PostArchivedEvent) EventType() string     { return string(PostArchivedEventType) }
+func (e PostArchivedEvent) EventType() string { return string(PostArchivedEventType) }
+
+type PostRestoredEvent struct {
+	ddd.EventBase
+
+	PostID     PostID
+	RestoredAt time.Time
+}
+
+func NewPostRestoredEvent(id PostID, restoredAt time.Time) *PostRestoredEvent {
+	return &PostRestoredEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
+		PostID:     id,
+		RestoredAt: restoredAt,
+	}
+}
+
+func (e PostRestoredEvent) EventType() string { return string(PostRestoredEventType) }
+
+// PostBecamePopularEvent is raised when the post is made popular, by the first like that
+// takes it to the popular threshold or past it
+type PostBecamePopularEvent struct {
+	ddd.EventBase
+
+	PostID  PostID
+	LikedBy UserID
+	Likes   int
+}
+
+func NewPostBecamePopularEvent(id PostID, likedBy UserID, likes int) *PostBecamePopularEvent {
+	return &PostBecamePopularEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		PostID:    id,
+		LikedBy:   likedBy,
+		Likes:     likes,
+	}
+}
+
+func (e PostBecamePopularEvent) EventType() string { return string(PostBecamePopularEventType) }
 
 func init() {
 	ddd.EventRegistry.Register(
@@ -115,4 +155,14 @@ func init() {
 		PostArchivedEvent{},
 		"Raised when a post is archived",
 	)
+
+	ddd.EventRegistry.Register(
+		PostRestoredEvent{},
+		"Raised when an archived post is restored",
+	)
+
+	ddd.EventRegistry.Register(
+		PostBecamePopularEvent{},
+		"Raised when a post is liked enough times to become popular",
+	)
 }
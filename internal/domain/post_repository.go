@@ -4,9 +4,15 @@ type PostRepository interface {
 	All() ([]Post, error)
 	FindByID(id PostID) (*Post, error)
 	FindByAuthor(authorID UserID) ([]Post, error)
+	// FindPublishedByAuthors returns up to limit unarchived posts by any of the authors,
+	// newest first, starting after the position. A nil position starts at the newest
+	FindPublishedByAuthors(authorIDs []UserID, after *PostPosition, limit int) ([]Post, error)
 	Exists(id PostID) (bool, error)
 	Create(post *Post) (*Post, error)
-	UpdateTitle(id PostID, newTitle string) error
-	UpdateContent(id PostID, newContent string) error
-	Archive(id PostID) error
+	UpdateTitle(post *Post) error
+	UpdateContent(post *Post) error
+	Archive(post *Post) error
+	Restore(post *Post) error
+	MarkPopular(post *Post) error
+	DeleteByAuthor(authorID UserID) error
 }
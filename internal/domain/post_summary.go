@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// PostSummary is a post as it's listed, with its author's username and counts of its
+// comments and ratings. Summaries are a read model kept up to date from events, so
+// they can trail the posts they summarise by a little
+type PostSummary struct {
+	PostID         PostID
+	AuthorID       UserID
+	AuthorUsername string
+	Title          string
+	// CommentCount doesn't count archived comments
+	CommentCount int
+	Likes        int
+	Dislikes     int
+	CreatedAt    time.Time
+	// LastActivityAt is when the post was last edited, commented on or rated
+	LastActivityAt time.Time
+	ArchivedAt     *time.Time
+}
+
+// Touch moves the summary's last activity on to the given time, unless it's later
+func (s *PostSummary) Touch(at time.Time) {
+	if at.After(s.LastActivityAt) {
+		s.LastActivityAt = at
+	}
+}
+
+// PostSummaryComment records which post a comment is on, for counting the post's
+// comments, as not every comment event says
+type PostSummaryComment struct {
+	CommentID   CommentID
+	PostID      PostID
+	CommenterID UserID
+	Archived    bool
+}
+
+// PostSummaryRating records which post a rating is on, for counting the post's ratings,
+// as not every rating event says
+type PostSummaryRating struct {
+	RatingID   RatingID
+	PostID     PostID
+	UserID     UserID
+	RatingType RatingType
+}
+
+// PostSummaryStore keeps the post summaries. The counts are kept by the store, from the
+// comments and ratings recorded with it
+type PostSummaryStore interface {
+	// All returns every summary, most recently active first
+	All() ([]PostSummary, error)
+	// FindByID returns the post's summary, or ErrPostNotFound
+	FindByID(postID PostID) (*PostSummary, error)
+	// Save adds or replaces the summary, apart from its counts
+	Save(summary *PostSummary) error
+	// RenameAuthor changes the author's username on each of their posts' summaries
+	RenameAuthor(authorID UserID, username string) error
+	// FindComment returns the recorded comment, or ErrCommentNotFound
+	FindComment(commentID CommentID) (*PostSummaryComment, error)
+	// SaveComment adds or replaces the comment, recounting its post's comments
+	SaveComment(comment PostSummaryComment) error
+	// FindRating returns the recorded rating, or ErrRatingNotFound
+	FindRating(ratingID RatingID) (*PostSummaryRating, error)
+	// SaveRating adds or replaces the rating, recounting its post's ratings
+	SaveRating(rating PostSummaryRating) error
+	// DeleteRating removes the rating, recounting its post's ratings
+	DeleteRating(ratingID RatingID) error
+	// DeleteByUser removes the summaries of the user's posts along with the comments and
+	// ratings on them, and the user's comments and ratings on other posts
+	DeleteByUser(userID UserID) error
+	// Reset removes every summary, comment and rating, returning the position of the
+	// event the summaries are rebuilt after. A store with posts from before their events
+	// were kept summarizes every post again as it is, rather than being left empty
+	Reset() (int64, error)
+}
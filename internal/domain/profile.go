@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+type LinkKind string
+
+const (
+	LinkKindWebsite  LinkKind = "website"
+	LinkKindGitHub   LinkKind = "github"
+	LinkKindX        LinkKind = "x"
+	LinkKindMastodon LinkKind = "mastodon"
+	LinkKindBluesky  LinkKind = "bluesky"
+	LinkKindLinkedIn LinkKind = "linkedin"
+)
+
+func (lk LinkKind) String() string {
+	return string(lk)
+}
+
+// linkHosts lists the hosts a social link must point at. Websites and Mastodon, which
+// is federated, can point anywhere
+var linkHosts = map[LinkKind][]string{
+	LinkKindWebsite:  nil,
+	LinkKindMastodon: nil,
+	LinkKindGitHub:   {"github.com"},
+	LinkKindX:        {"x.com", "twitter.com"},
+	LinkKindBluesky:  {"bsky.app"},
+	LinkKindLinkedIn: {"linkedin.com"},
+}
+
+const (
+	maxDisplayNameLength = 50
+	maxDescriptionLength = 255
+	maxLocationLength    = 100
+	maxProfileLinks      = 5
+)
+
+// ProfileLink is a link to the user's website or one of their social accounts
+type ProfileLink struct {
+	Kind LinkKind
+	URL  string
+}
+
+// NewProfileLink validates the URL as an absolute http(s) URL, on the right host for
+// social links
+func NewProfileLink(kind LinkKind, rawURL string) (ProfileLink, error) {
+	hosts, known := linkHosts[kind]
+	if !known {
+		return ProfileLink{}, ErrInvalidProfileLink
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ProfileLink{}, ErrInvalidProfileLink
+	}
+
+	if hosts != nil {
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		matched := false
+		for _, h := range hosts {
+			if host == h {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return ProfileLink{}, ErrInvalidProfileLink
+		}
+	}
+
+	return ProfileLink{Kind: kind, URL: u.String()}, nil
+}
+
+// Profile is the public information a user shares about themselves
+type Profile struct {
+	DisplayName string
+	Description string
+	Location    string
+	Links       []ProfileLink
+}
+
+func NewProfile(
+	displayName, description, location string,
+	links []ProfileLink,
+) (Profile, error) {
+	if utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+		return Profile{}, ErrDisplayNameTooLong
+	}
+	if len(description) > maxDescriptionLength {
+		return Profile{}, ErrDescriptionTooLong
+	}
+	if utf8.RuneCountInString(location) > maxLocationLength {
+		return Profile{}, ErrLocationTooLong
+	}
+	if len(links) > maxProfileLinks {
+		return Profile{}, ErrTooManyProfileLinks
+	}
+
+	return Profile{
+		DisplayName: strings.TrimSpace(displayName),
+		Description: description,
+		Location:    strings.TrimSpace(location),
+		Links:       append([]ProfileLink{}, links...),
+	}, nil
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewProfileLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    LinkKind
+		url     string
+		wantErr error
+	}{
+		{
+			name: "Test Website On Any Host",
+			kind: LinkKindWebsite,
+			url:  "https://example.com/about",
+		},
+		{
+			name: "Test GitHub With www Prefix",
+			kind: LinkKindGitHub,
+			url:  "https://www.github.com/alice",
+		},
+		{
+			name:    "Test GitHub On Another Host Fails",
+			kind:    LinkKindGitHub,
+			url:     "https://gitlab.com/alice",
+			wantErr: ErrInvalidProfileLink,
+		},
+		{
+			name:    "Test Non HTTP Scheme Fails",
+			kind:    LinkKindWebsite,
+			url:     "javascript:alert(1)",
+			wantErr: ErrInvalidProfileLink,
+		},
+		{
+			name:    "Test Unknown Kind Fails",
+			kind:    LinkKind("myspace"),
+			url:     "https://myspace.com/alice",
+			wantErr: ErrInvalidProfileLink,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, gotErr := NewProfileLink(tt.kind, tt.url)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Errorf("NewProfileLink() error = %v, want %v", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUpdateProfile(t *testing.T) {
+	user := newTestUser(t)
+
+	link, err := NewProfileLink(LinkKindGitHub, "https://github.com/alice")
+	if err != nil {
+		t.Fatalf("NewProfileLink() failed: %v", err)
+	}
+
+	profile, err := NewProfile(" Alice ", "Writes about Go", "Berlin", []ProfileLink{link})
+	if err != nil {
+		t.Fatalf("NewProfile() failed: %v", err)
+	}
+
+	user.UpdateProfile(profile)
+
+	if user.DisplayName() != "Alice" || user.Location() != "Berlin" || len(user.Links()) != 1 {
+		t.Errorf("UpdateProfile() left profile %#v", user.Profile())
+	}
+	if _, ok := user.GetUncommittedEvents()[0].(*UserProfileUpdatedEvent); !ok {
+		t.Errorf("UpdateProfile() recorded %T", user.GetUncommittedEvents()[0])
+	}
+
+	if err := user.RemoveAvatar(); !errors.Is(err, ErrAvatarNotFound) {
+		t.Errorf("RemoveAvatar() without avatar error = %v", err)
+	}
+}
@@ -55,6 +55,9 @@ func (a Rating) RatingType() RatingType { return a.ratingType }
 func (a Rating) CreatedAt() time.Time   { return a.createdAt }
 func (a Rating) UpdatedAt() *time.Time  { return a.updatedAt }
 
+// OwnerID returns the user the rating belongs to, for authorization
+func (a Rating) OwnerID() UserID { return a.userID }
+
 func (a *Rating) ChangeRating(ratingType RatingType) {
 	now := time.Now()
 	a.ratingType = ratingType
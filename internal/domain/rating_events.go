@@ -13,13 +13,14 @@ const (
 )
 
 type RatingCreatedEvent struct {
+	ddd.EventBase
+
 	RatingID   RatingID
 	PostID     PostID
 	UserID     UserID
 	RatingType RatingType
 	CreatedAt  time.Time
 	UpdatedAt  *time.Time
-	occurredOn time.Time
 }
 
 func NewRatingCreatedEvent(
@@ -31,24 +32,24 @@ func NewRatingCreatedEvent(
 	updatedAt *time.Time,
 ) *RatingCreatedEvent {
 	return &RatingCreatedEvent{
+		EventBase:  ddd.NewEventBase(time.Now()),
 		RatingID:   id,
 		PostID:     postID,
 		UserID:     userID,
 		RatingType: ratingType,
 		CreatedAt:  createdAt,
 		UpdatedAt:  updatedAt,
-		occurredOn: time.Now(),
 	}
 }
 
-func (e RatingCreatedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e RatingCreatedEvent) EventType() string     { return string(RatingCreatedEventType) }
+func (e RatingCreatedEvent) EventType() string { return string(RatingCreatedEventType) }
 
 type RatingChangedEvent struct {
+	ddd.EventBase
+
 	RatingID      RatingID
 	NewRatingType RatingType
 	UpdatedAt     time.Time
-	occurredOn    time.Time
 }
 
 func NewRatingChangedEvent(
@@ -57,32 +58,31 @@ func NewRatingChangedEvent(
 	updatedAt time.Time,
 ) *RatingChangedEvent {
 	return &RatingChangedEvent{
+		EventBase:     ddd.NewEventBase(time.Now()),
 		RatingID:      id,
 		NewRatingType: newRatingType,
 		UpdatedAt:     updatedAt,
-		occurredOn:    time.Now(),
 	}
 }
 
-func (e RatingChangedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e RatingChangedEvent) EventType() string     { return string(RatingChangedEventType) }
+func (e RatingChangedEvent) EventType() string { return string(RatingChangedEventType) }
 
 type RatingRemovedEvent struct {
-	RatingID   RatingID
-	occurredOn time.Time
+	ddd.EventBase
+
+	RatingID RatingID
 }
 
 func NewRatingRemovedEvent(
 	id RatingID,
 ) *RatingRemovedEvent {
 	return &RatingRemovedEvent{
-		RatingID:   id,
-		occurredOn: time.Now(),
+		EventBase: ddd.NewEventBase(time.Now()),
+		RatingID:  id,
 	}
 }
 
-func (e RatingRemovedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e RatingRemovedEvent) EventType() string     { return string(RatingRemovedEventType) }
+func (e RatingRemovedEvent) EventType() string { return string(RatingRemovedEventType) }
 
 func init() {
 	ddd.EventRegistry.Register(
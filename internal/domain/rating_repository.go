@@ -8,6 +8,7 @@ type RatingRepository interface {
 	Exists(id RatingID) (bool, error)
 	ExistsOnPostByUser(postID PostID, userID UserID) (bool, error)
 	Create(rating *Rating) (*Rating, error)
-	ChangeRating(id RatingID, newRatingType RatingType) error
-	RemoveRating(id RatingID) error
+	ChangeRating(rating *Rating) error
+	RemoveRating(rating *Rating) error
+	DeleteByUser(userID UserID) error
 }
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,31}$`)
+
+type Role struct {
+	*ddd.AggregateBase
+	description string
+	permissions map[Permission]bool
+	builtIn     bool
+	createdAt   time.Time
+}
+
+func NewRole(name UserRole, description string, permissions []Permission) (*Role, error) {
+	if !roleNamePattern.MatchString(name.String()) {
+		return nil, ErrInvalidRoleName
+	}
+
+	if err := validatePermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	role := &Role{
+		AggregateBase: &ddd.AggregateBase{},
+		description:   description,
+		permissions:   permissionSet(permissions),
+		builtIn:       false,
+		createdAt:     now,
+	}
+	role.SetID(name)
+
+	event := NewRoleCreatedEvent(name, description, role.Permissions(), now)
+	role.RecordEvent(event)
+
+	return role, nil
+}
+
+// Roles are identified by their name, which is what users are assigned
+func (a Role) GetID() UserRole {
+	return UserRole(a.AggregateBase.GetID())
+}
+
+func (a *Role) SetID(name UserRole) {
+	if name == "" {
+		return
+	}
+	a.AggregateBase.SetID(string(name))
+}
+
+func (a Role) Name() UserRole       { return a.GetID() }
+func (a Role) Description() string  { return a.description }
+func (a Role) BuiltIn() bool        { return a.builtIn }
+func (a Role) CreatedAt() time.Time { return a.createdAt }
+
+func (a Role) Permissions() []Permission {
+	permissions := []Permission{}
+	for k, v := range a.permissions {
+		if v {
+			permissions = append(permissions, k)
+		}
+	}
+	slices.Sort(permissions)
+	return permissions
+}
+
+func (a Role) HasPermission(permission Permission) bool {
+	return a.permissions[permission]
+}
+
+func (a *Role) SetPermissions(permissions []Permission) error {
+	if err := validatePermissions(permissions); err != nil {
+		return err
+	}
+
+	// The admin role must always be able to manage users, or nobody could fix its permissions
+	if a.GetID() == UserRoleAdmin && !slices.Contains(permissions, PermissionManageUsers) {
+		return ErrAdminRoleRequiresManageUsers
+	}
+
+	a.permissions = permissionSet(permissions)
+
+	event := NewRolePermissionsChangedEvent(a.GetID(), a.Permissions())
+	a.RecordEvent(event)
+
+	return nil
+}
+
+func (a *Role) Delete() error {
+	if a.builtIn {
+		return ErrBuiltInRole
+	}
+
+	event := NewRoleDeletedEvent(a.GetID())
+	a.RecordEvent(event)
+
+	return nil
+}
+
+func RebuildRole(
+	name UserRole,
+	description string,
+	permissions []Permission,
+	builtIn bool,
+	createdAt time.Time,
+) *Role {
+	role := &Role{
+		AggregateBase: &ddd.AggregateBase{},
+		description:   description,
+		permissions:   permissionSet(permissions),
+		builtIn:       builtIn,
+		createdAt:     createdAt,
+	}
+	role.SetID(name)
+
+	return role
+}
+
+// PermissionsForRoles returns the combined permissions granted by the given roles
+func PermissionsForRoles(roles []Role) []Permission {
+	seen := map[Permission]bool{}
+	permissions := []Permission{}
+	for _, role := range roles {
+		for _, permission := range role.Permissions() {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+func permissionSet(permissions []Permission) map[Permission]bool {
+	set := map[Permission]bool{}
+	for _, permission := range permissions {
+		set[permission] = true
+	}
+	return set
+}
+
+func validatePermissions(permissions []Permission) error {
+	for _, permission := range permissions {
+		if !permission.Valid() {
+			return ErrUnknownPermission
+		}
+	}
+	return nil
+}
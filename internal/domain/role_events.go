@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+const (
+	RoleCreatedEventType            EventType = "RoleCreated"
+	RolePermissionsChangedEventType EventType = "RolePermissionsChanged"
+	RoleDeletedEventType            EventType = "RoleDeleted"
+)
+
+type RoleCreatedEvent struct {
+	ddd.EventBase
+
+	RoleName    UserRole
+	Description string
+	Permissions []Permission
+	CreatedAt   time.Time
+}
+
+func NewRoleCreatedEvent(
+	name UserRole,
+	description string,
+	permissions []Permission,
+	createdAt time.Time,
+) *RoleCreatedEvent {
+	return &RoleCreatedEvent{
+		EventBase:   ddd.NewEventBase(time.Now()),
+		RoleName:    name,
+		Description: description,
+		Permissions: permissions,
+		CreatedAt:   createdAt,
+	}
+}
+
+func (e RoleCreatedEvent) EventType() string { return string(RoleCreatedEventType) }
+
+type RolePermissionsChangedEvent struct {
+	ddd.EventBase
+
+	RoleName    UserRole
+	Permissions []Permission
+}
+
+func NewRolePermissionsChangedEvent(
+	name UserRole,
+	permissions []Permission,
+) *RolePermissionsChangedEvent {
+	return &RolePermissionsChangedEvent{
+		EventBase:   ddd.NewEventBase(time.Now()),
+		RoleName:    name,
+		Permissions: permissions,
+	}
+}
+
+func (e RolePermissionsChangedEvent) EventType() string {
+	return string(RolePermissionsChangedEventType)
+}
+
+type RoleDeletedEvent struct {
+	ddd.EventBase
+
+	RoleName UserRole
+}
+
+func NewRoleDeletedEvent(name UserRole) *RoleDeletedEvent {
+	return &RoleDeletedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		RoleName:  name,
+	}
+}
+
+func (e RoleDeletedEvent) EventType() string { return string(RoleDeletedEventType) }
+
+func init() {
+	ddd.EventRegistry.Register(
+		RoleCreatedEvent{},
+		"Raised when a new role is created",
+	)
+
+	ddd.EventRegistry.Register(
+		RolePermissionsChangedEvent{},
+		"Raised when the permissions granted by a role change",
+	)
+
+	ddd.EventRegistry.Register(
+		RoleDeletedEvent{},
+		"Raised when a role is deleted",
+	)
+}
@@ -0,0 +1,12 @@
+package domain
+
+type RoleRepository interface {
+	All() ([]Role, error)
+	FindByName(name UserRole) (*Role, error)
+	FindByNames(names []UserRole) ([]Role, error)
+	Exists(name UserRole) (bool, error)
+	InUse(name UserRole) (bool, error)
+	Create(role *Role) (*Role, error)
+	UpdatePermissions(role *Role) error
+	Delete(role *Role) error
+}
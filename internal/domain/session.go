@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session describes an authenticated login on a single device. The session
+// data itself is owned by the session manager, this only tracks who it
+// belongs to and where it came from.
+type Session struct {
+	id             SessionID
+	userID         UserID
+	impersonatorID UserID
+	userAgent      string
+	device         string
+	ipAddress      string
+	createdAt      time.Time
+	lastSeenAt     time.Time
+	expiresAt      time.Time
+}
+
+func NewSession(
+	userID UserID,
+	userAgent, device, ipAddress string,
+	expiresAt time.Time,
+) *Session {
+	now := time.Now()
+
+	return &Session{
+		id:         NewSessionID(uuid.New().String()),
+		userID:     userID,
+		userAgent:  userAgent,
+		device:     device,
+		ipAddress:  ipAddress,
+		createdAt:  now,
+		lastSeenAt: now,
+		expiresAt:  expiresAt,
+	}
+}
+
+// NewImpersonationSession starts a session in which the impersonator acts as the user
+func NewImpersonationSession(
+	userID, impersonatorID UserID,
+	userAgent, device, ipAddress string,
+	expiresAt time.Time,
+) *Session {
+	session := NewSession(userID, userAgent, device, ipAddress, expiresAt)
+	session.impersonatorID = impersonatorID
+	return session
+}
+
+func (s Session) ID() SessionID             { return s.id }
+func (s Session) UserID() UserID            { return s.userID }
+func (s Session) UserAgent() string         { return s.userAgent }
+func (s Session) Device() string            { return s.device }
+func (s Session) IPAddress() string         { return s.ipAddress }
+func (s Session) CreatedAt() time.Time      { return s.createdAt }
+func (s Session) LastSeenAt() time.Time     { return s.lastSeenAt }
+func (s Session) ExpiresAt() time.Time      { return s.expiresAt }
+func (s Session) Expired(at time.Time) bool { return !s.expiresAt.After(at) }
+
+// ImpersonatorID returns the admin acting as the user, empty unless this is an
+// impersonation session
+func (s Session) ImpersonatorID() UserID { return s.impersonatorID }
+func (s Session) Impersonated() bool     { return s.impersonatorID != "" }
+
+func RebuildSession(
+	id SessionID,
+	userID UserID,
+	impersonatorID UserID,
+	userAgent string,
+	device string,
+	ipAddress string,
+	createdAt time.Time,
+	lastSeenAt time.Time,
+	expiresAt time.Time,
+) *Session {
+	return &Session{
+		id:             id,
+		userID:         userID,
+		impersonatorID: impersonatorID,
+		userAgent:      userAgent,
+		device:         device,
+		ipAddress:      ipAddress,
+		createdAt:      createdAt,
+		lastSeenAt:     lastSeenAt,
+		expiresAt:      expiresAt,
+	}
+}
@@ -0,0 +1,11 @@
+package domain
+
+type SessionID string
+
+func NewSessionID(id string) SessionID {
+	return SessionID(id)
+}
+
+func (id SessionID) String() string {
+	return string(id)
+}
@@ -0,0 +1,10 @@
+package domain
+
+type SessionRepository interface {
+	FindByID(id SessionID) (*Session, error)
+	FindByToken(token string) (*Session, error)
+	FindByUser(userID UserID) ([]Session, error)
+	Create(token string, session *Session) (*Session, error)
+	Revoke(id SessionID) error
+	RevokeAllForUser(userID UserID) error
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"crypto/subtle"
 	"time"
 
 	"blog/pkg/ddd"
@@ -10,12 +11,22 @@ import (
 
 type User struct {
 	*ddd.AggregateBase
-	email        string
-	passwordHash string
-	username     string
-	description  string
-	userRoles    map[UserRole]bool
-	joinDate     time.Time
+	email               string
+	passwordHash        string
+	username            string
+	profile             Profile
+	avatarVersion       string
+	userRoles           map[UserRole]bool
+	joinDate            time.Time
+	lockedUntil         *time.Time
+	status              UserStatus
+	statusReason        string
+	suspendedUntil      *time.Time
+	deletionRequestedAt *time.Time
+	deleteAfter         *time.Time
+	anonymisedAt        *time.Time
+	pendingEmailChange  *EmailChange
+	usernameChangedAt   *time.Time
 }
 
 func NewUser(
@@ -38,9 +49,10 @@ func NewUser(
 		email:         email,
 		passwordHash:  passwordHash,
 		username:      username,
-		description:   description,
+		profile:       Profile{Description: description},
 		userRoles:     setRoles,
 		joinDate:      now,
+		status:        UserStatusActive,
 	}
 
 	newID := NewUserID(uuid.New().String())
@@ -74,9 +86,31 @@ func (a *User) SetID(id UserID) {
 func (a User) Email() string        { return a.email }
 func (a User) Username() string     { return a.username }
 func (a User) PasswordHash() string { return a.passwordHash }
-func (a User) Description() string  { return a.description }
+func (a User) Description() string  { return a.profile.Description }
 func (a User) JoinDate() time.Time  { return a.joinDate }
 
+func (a User) LockedUntil() *time.Time { return a.lockedUntil }
+
+func (a User) Profile() Profile      { return a.profile }
+func (a User) DisplayName() string   { return a.profile.DisplayName }
+func (a User) Location() string      { return a.profile.Location }
+func (a User) AvatarVersion() string { return a.avatarVersion }
+
+func (a User) Links() []ProfileLink {
+	return append([]ProfileLink{}, a.profile.Links...)
+}
+
+func (a User) Status() UserStatus         { return a.status }
+func (a User) StatusReason() string       { return a.statusReason }
+func (a User) SuspendedUntil() *time.Time { return a.suspendedUntil }
+
+func (a User) DeletionRequestedAt() *time.Time { return a.deletionRequestedAt }
+func (a User) DeleteAfter() *time.Time         { return a.deleteAfter }
+func (a User) AnonymisedAt() *time.Time        { return a.anonymisedAt }
+
+func (a User) PendingEmailChange() *EmailChange { return a.pendingEmailChange }
+func (a User) UsernameChangedAt() *time.Time    { return a.usernameChangedAt }
+
 func (a User) UserRoles() []UserRole {
 	roleSlice := []UserRole{}
 	for k, v := range a.userRoles {
@@ -87,16 +121,10 @@ func (a User) UserRoles() []UserRole {
 	return roleSlice
 }
 
-func (a User) CanCreatePost() bool {
-	return a.userRoles[UserRoleAuthor]
-}
-
-func (a User) CanComment() bool {
-	return a.userRoles[UserRoleCommenter]
-}
-
-func (a User) IsAdmin() bool {
-	return a.userRoles[UserRoleAdmin]
+// Actor returns the user as an actor for authorization, with the permissions of the given
+// roles, which should be the user's own
+func (a User) Actor(roles []Role) Actor {
+	return NewActor(a.GetID(), PermissionsForRoles(roles))
 }
 
 func (a *User) AddRole(role UserRole) {
@@ -113,18 +141,112 @@ func (a *User) RemoveRole(role UserRole) {
 	a.RecordEvent(event)
 }
 
-func (a *User) UpdateDescription(newDescription string) error {
-	if len(newDescription) > 255 {
-		return ErrDescriptionTooLong
+// UpdateProfile replaces the user's public profile
+func (a *User) UpdateProfile(profile Profile) {
+	a.profile = profile
+
+	event := NewUserProfileUpdatedEvent(
+		a.GetID(),
+		profile.DisplayName,
+		profile.Description,
+		profile.Location,
+		profile.Links,
+	)
+	a.RecordEvent(event)
+}
+
+// SetAvatar points the user at a newly stored avatar version
+func (a *User) SetAvatar(version string) {
+	a.avatarVersion = version
+
+	event := NewUserAvatarChangedEvent(a.GetID(), version)
+	a.RecordEvent(event)
+}
+
+func (a *User) RemoveAvatar() error {
+	if a.avatarVersion == "" {
+		return ErrAvatarNotFound
+	}
+	a.avatarVersion = ""
+
+	event := NewUserAvatarRemovedEvent(a.GetID())
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// RequestEmailChange holds the new address as pending until it is confirmed with the
+// token whose hash is given. A new request replaces any earlier one
+func (a *User) RequestEmailChange(newEmail, tokenHash string, expiresAt time.Time) error {
+	if newEmail == a.email {
+		return ErrEmailUnchanged
 	}
-	a.description = newDescription
 
-	event := NewUserDescriptionUpdatedEvent(a.GetID(), newDescription)
+	a.pendingEmailChange = &EmailChange{
+		NewEmail:  newEmail,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}
+
+	event := NewUserEmailChangeRequestedEvent(a.GetID(), a.email, newEmail, expiresAt)
 	a.RecordEvent(event)
 
 	return nil
 }
 
+// ConfirmEmailChange switches the user to the pending address if the token hash matches
+// and the confirmation hasn't expired by the given time
+func (a *User) ConfirmEmailChange(tokenHash string, at time.Time) error {
+	if a.pendingEmailChange == nil {
+		return ErrNoEmailChangePending
+	}
+	if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(a.pendingEmailChange.TokenHash)) != 1 {
+		return ErrInvalidEmailChangeToken
+	}
+	if at.After(a.pendingEmailChange.ExpiresAt) {
+		return ErrEmailChangeExpired
+	}
+
+	oldEmail := a.email
+	a.email = a.pendingEmailChange.NewEmail
+	a.pendingEmailChange = nil
+
+	event := NewUserEmailChangedEvent(a.GetID(), oldEmail, a.email)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// ChangeUsername renames the user, at most once per cooldown
+func (a *User) ChangeUsername(newUsername string, at time.Time, cooldown time.Duration) error {
+	if newUsername == a.username {
+		return ErrUsernameUnchanged
+	}
+	if next := a.NextUsernameChange(cooldown); next != nil && at.Before(*next) {
+		return ErrUsernameChangeTooSoon
+	}
+
+	oldUsername := a.username
+	a.username = newUsername
+	a.usernameChangedAt = &at
+
+	event := NewUserUsernameChangedEvent(a.GetID(), oldUsername, newUsername)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// NextUsernameChange returns when the user may next change their username, or nil if
+// they may change it now
+func (a User) NextUsernameChange(cooldown time.Duration) *time.Time {
+	if a.usernameChangedAt == nil {
+		return nil
+	}
+
+	next := a.usernameChangedAt.Add(cooldown)
+	return &next
+}
+
 func (a *User) UpdatePasswordHash(passwordHash string) error {
 	a.passwordHash = passwordHash
 
@@ -134,14 +256,218 @@ func (a *User) UpdatePasswordHash(passwordHash string) error {
 	return nil
 }
 
+// RehashPassword replaces the password hash with one of the same password made with the
+// current algorithm and cost
+func (a *User) RehashPassword(passwordHash string) {
+	a.passwordHash = passwordHash
+
+	event := NewUserPasswordRehashedEvent(a.GetID())
+	a.RecordEvent(event)
+}
+
+// IsLockedOut reports whether the account is locked at the given time
+func (a User) IsLockedOut(at time.Time) bool {
+	return a.lockedUntil != nil && a.lockedUntil.After(at)
+}
+
+// LockOut temporarily locks the account until the given time
+func (a *User) LockOut(until time.Time, failedAttempts int) {
+	a.lockedUntil = &until
+
+	event := NewUserLockedOutEvent(a.GetID(), until, failedAttempts)
+	a.RecordEvent(event)
+}
+
+// Unlock lifts an account lock, whether or not it has expired yet
+func (a *User) Unlock() {
+	if a.lockedUntil == nil {
+		return
+	}
+	a.lockedUntil = nil
+
+	event := NewUserUnlockedEvent(a.GetID())
+	a.RecordEvent(event)
+}
+
+// IsSuspended reports whether the user is serving a suspension at the given time
+func (a User) IsSuspended(at time.Time) bool {
+	return a.status == UserStatusSuspended &&
+		a.suspendedUntil != nil &&
+		a.suspendedUntil.After(at)
+}
+
+func (a User) IsBanned() bool { return a.status == UserStatusBanned }
+
+// IsDeactivated reports whether the user has asked for their account to be deleted, or
+// it already has been
+func (a User) IsDeactivated() bool {
+	return a.deletionRequestedAt != nil || a.anonymisedAt != nil
+}
+
+// CheckStanding returns ErrUserBanned, ErrUserSuspended or ErrUserDeactivated, in that
+// order, if the user may not log in or act at the given time. A suspension that has run
+// out no longer counts, even if it hasn't been lifted yet
+func (a User) CheckStanding(at time.Time) error {
+	if a.IsBanned() {
+		return ErrUserBanned
+	}
+	if a.IsSuspended(at) {
+		return ErrUserSuspended
+	}
+	if a.IsDeactivated() {
+		return ErrUserDeactivated
+	}
+	return nil
+}
+
+// Suspend stops the user from logging in or acting until the given time. Suspending a
+// user that is already suspended replaces the suspension
+func (a *User) Suspend(until time.Time, reason string) error {
+	if a.IsBanned() {
+		return ErrUserBanned
+	}
+	if reason == "" {
+		return ErrMissingReason
+	}
+	if !until.After(time.Now()) {
+		return ErrSuspensionInPast
+	}
+
+	a.status = UserStatusSuspended
+	a.statusReason = reason
+	a.suspendedUntil = &until
+
+	event := NewUserSuspendedEvent(a.GetID(), until, reason)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// Ban stops the user from logging in or acting until they are reinstated
+func (a *User) Ban(reason string) error {
+	if a.IsBanned() {
+		return ErrUserBanned
+	}
+	if reason == "" {
+		return ErrMissingReason
+	}
+
+	a.status = UserStatusBanned
+	a.statusReason = reason
+	a.suspendedUntil = nil
+
+	event := NewUserBannedEvent(a.GetID(), reason)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// Reinstate lifts a suspension or ban
+func (a *User) Reinstate() error {
+	if a.status == UserStatusActive {
+		return ErrUserNotRestricted
+	}
+
+	previousStatus := a.status
+	a.status = UserStatusActive
+	a.statusReason = ""
+	a.suspendedUntil = nil
+
+	event := NewUserReinstatedEvent(a.GetID(), previousStatus)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// RequestDeletion deactivates the account, scheduling it to be anonymised once the
+// grace period is over
+func (a *User) RequestDeletion(gracePeriod time.Duration) error {
+	if a.anonymisedAt != nil {
+		return ErrUserAnonymised
+	}
+	if a.deletionRequestedAt != nil {
+		return ErrDeletionRequested
+	}
+
+	now := time.Now()
+	deleteAfter := now.Add(gracePeriod)
+	a.deletionRequestedAt = &now
+	a.deleteAfter = &deleteAfter
+
+	event := NewUserDeletionRequestedEvent(a.GetID(), deleteAfter)
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// CancelDeletion reactivates an account during its deletion grace period
+func (a *User) CancelDeletion() error {
+	if a.anonymisedAt != nil {
+		return ErrUserAnonymised
+	}
+	if a.deletionRequestedAt == nil {
+		return ErrNoDeletionRequest
+	}
+
+	a.deletionRequestedAt = nil
+	a.deleteAfter = nil
+
+	event := NewUserDeletionCancelledEvent(a.GetID())
+	a.RecordEvent(event)
+
+	return nil
+}
+
+// Anonymise strips the personal data from an account whose deletion grace period ended
+// before the given time. The account is kept, under the given username, so content
+// that isn't removed still has an author
+func (a *User) Anonymise(username string, at time.Time, contentRemoved bool) error {
+	if a.anonymisedAt != nil {
+		return ErrUserAnonymised
+	}
+	if a.deleteAfter == nil {
+		return ErrNoDeletionRequest
+	}
+	if a.deleteAfter.After(at) {
+		return ErrDeletionNotDue
+	}
+
+	now := time.Now()
+	a.username = username
+	a.email = username + "@deleted.invalid"
+	a.passwordHash = ""
+	a.profile = Profile{}
+	a.avatarVersion = ""
+	a.userRoles = map[UserRole]bool{}
+	a.lockedUntil = nil
+	a.pendingEmailChange = nil
+	a.deleteAfter = nil
+	a.anonymisedAt = &now
+
+	event := NewUserAnonymisedEvent(a.GetID(), username, contentRemoved)
+	a.RecordEvent(event)
+
+	return nil
+}
+
 func RebuildUser(
 	id UserID,
 	email string,
 	passwordHash string,
 	username string,
-	description string,
+	profile Profile,
+	avatarVersion string,
 	userRoles []UserRole,
 	joinDate time.Time,
+	lockedUntil *time.Time,
+	status UserStatus,
+	statusReason string,
+	suspendedUntil *time.Time,
+	deletionRequestedAt *time.Time,
+	deleteAfter *time.Time,
+	anonymisedAt *time.Time,
+	pendingEmailChange *EmailChange,
+	usernameChangedAt *time.Time,
 ) *User {
 	setRoles := map[UserRole]bool{}
 	for _, role := range userRoles {
@@ -149,13 +475,23 @@ func RebuildUser(
 	}
 
 	user := &User{
-		AggregateBase: &ddd.AggregateBase{},
-		email:         email,
-		passwordHash:  passwordHash,
-		username:      username,
-		description:   description,
-		userRoles:     setRoles,
-		joinDate:      joinDate,
+		AggregateBase:       &ddd.AggregateBase{},
+		email:               email,
+		passwordHash:        passwordHash,
+		username:            username,
+		profile:             profile,
+		avatarVersion:       avatarVersion,
+		userRoles:           setRoles,
+		joinDate:            joinDate,
+		lockedUntil:         lockedUntil,
+		status:              status,
+		statusReason:        statusReason,
+		suspendedUntil:      suspendedUntil,
+		deletionRequestedAt: deletionRequestedAt,
+		deleteAfter:         deleteAfter,
+		anonymisedAt:        anonymisedAt,
+		pendingEmailChange:  pendingEmailChange,
+		usernameChangedAt:   usernameChangedAt,
 	}
 	user.SetID(id)
 
@@ -7,14 +7,30 @@ import (
 )
 
 const (
-	UserCreatedEventType            EventType = "UserCreated"
-	UserRoleAddedEventType          EventType = "UserRoleAdded"
-	UserRoleRemovedEventType        EventType = "UserRoleRemoved"
-	UserDescriptionUpdatedEventType EventType = "UserDescriptionUpdated"
-	UserPasswordUpdatedEventType    EventType = "UserPasswordUpdated"
+	UserCreatedEventType              EventType = "UserCreated"
+	UserRoleAddedEventType            EventType = "UserRoleAdded"
+	UserRoleRemovedEventType          EventType = "UserRoleRemoved"
+	UserProfileUpdatedEventType       EventType = "UserProfileUpdated"
+	UserAvatarChangedEventType        EventType = "UserAvatarChanged"
+	UserAvatarRemovedEventType        EventType = "UserAvatarRemoved"
+	UserPasswordUpdatedEventType      EventType = "UserPasswordUpdated"
+	UserPasswordRehashedEventType     EventType = "UserPasswordRehashed"
+	UserLockedOutEventType            EventType = "UserLockedOut"
+	UserUnlockedEventType             EventType = "UserUnlocked"
+	UserSuspendedEventType            EventType = "UserSuspended"
+	UserBannedEventType               EventType = "UserBanned"
+	UserReinstatedEventType           EventType = "UserReinstated"
+	UserDeletionRequestedEventType    EventType = "UserDeletionRequested"
+	UserDeletionCancelledEventType    EventType = "UserDeletionCancelled"
+	UserAnonymisedEventType           EventType = "UserAnonymised"
+	UserEmailChangeRequestedEventType EventType = "UserEmailChangeRequested"
+	UserEmailChangedEventType         EventType = "UserEmailChanged"
+	UserUsernameChangedEventType      EventType = "UserUsernameChanged"
 )
 
 type UserCreatedEvent struct {
+	ddd.EventBase
+
 	UserID       UserID
 	Email        string
 	PasswordHash string
@@ -22,7 +38,6 @@ type UserCreatedEvent struct {
 	Description  string
 	UserRoles    []UserRole
 	JoinDate     time.Time
-	occurredOn   time.Time
 }
 
 func NewUserCreatedEvent(
@@ -32,6 +47,7 @@ func NewUserCreatedEvent(
 	joinDate time.Time,
 ) *UserCreatedEvent {
 	return &UserCreatedEvent{
+		EventBase:    ddd.NewEventBase(time.Now()),
 		UserID:       id,
 		Email:        email,
 		PasswordHash: passwordHash,
@@ -39,85 +55,361 @@ func NewUserCreatedEvent(
 		Description:  description,
 		UserRoles:    userRoles,
 		JoinDate:     joinDate,
-		occurredOn:   time.Now(),
 	}
 }
 
-func (e UserCreatedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e UserCreatedEvent) EventType() string     { return string(UserCreatedEventType) }
+func (e UserCreatedEvent) EventType() string { return string(UserCreatedEventType) }
 
 type UserRoleAddedEvent struct {
-	UserID     UserID
-	Role       UserRole
-	occurredOn time.Time
+	ddd.EventBase
+
+	UserID UserID
+	Role   UserRole
 }
 
 func NewUserRoleAddedEvent(id UserID, role UserRole) *UserRoleAddedEvent {
 	return &UserRoleAddedEvent{
-		UserID:     id,
-		Role:       role,
-		occurredOn: time.Now(),
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+		Role:      role,
 	}
 }
 
-func (e UserRoleAddedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e UserRoleAddedEvent) EventType() string     { return string(UserRoleAddedEventType) }
+func (e UserRoleAddedEvent) EventType() string { return string(UserRoleAddedEventType) }
 
 type UserRoleRemovedEvent struct {
-	UserID     UserID
-	Role       UserRole
-	occurredOn time.Time
+	ddd.EventBase
+
+	UserID UserID
+	Role   UserRole
 }
 
 func NewUserRoleRemovedEvent(id UserID, role UserRole) *UserRoleRemovedEvent {
 	return &UserRoleRemovedEvent{
-		UserID:     id,
-		Role:       role,
-		occurredOn: time.Now(),
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+		Role:      role,
 	}
 }
 
-func (e UserRoleRemovedEvent) OccurredOn() time.Time { return e.occurredOn }
-func (e UserRoleRemovedEvent) EventType() string     { return string(UserRoleRemovedEventType) }
+func (e UserRoleRemovedEvent) EventType() string { return string(UserRoleRemovedEventType) }
+
+type UserProfileUpdatedEvent struct {
+	ddd.EventBase
 
-type UserDescriptionUpdatedEvent struct {
 	UserID      UserID
+	DisplayName string
 	Description string
-	occurredOn  time.Time
+	Location    string
+	Links       []ProfileLink
 }
 
-func NewUserDescriptionUpdatedEvent(id UserID, description string) *UserDescriptionUpdatedEvent {
-	return &UserDescriptionUpdatedEvent{
+func NewUserProfileUpdatedEvent(
+	id UserID,
+	displayName, description, location string,
+	links []ProfileLink,
+) *UserProfileUpdatedEvent {
+	return &UserProfileUpdatedEvent{
+		EventBase:   ddd.NewEventBase(time.Now()),
 		UserID:      id,
+		DisplayName: displayName,
 		Description: description,
-		occurredOn:  time.Now(),
+		Location:    location,
+		Links:       links,
+	}
+}
+
+func (e UserProfileUpdatedEvent) EventType() string { return string(UserProfileUpdatedEventType) }
+
+type UserAvatarChangedEvent struct {
+	ddd.EventBase
+
+	UserID  UserID
+	Version string
+}
+
+func NewUserAvatarChangedEvent(id UserID, version string) *UserAvatarChangedEvent {
+	return &UserAvatarChangedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+		Version:   version,
 	}
 }
 
-func (e UserDescriptionUpdatedEvent) OccurredOn() time.Time { return e.occurredOn }
+func (e UserAvatarChangedEvent) EventType() string { return string(UserAvatarChangedEventType) }
+
+type UserAvatarRemovedEvent struct {
+	ddd.EventBase
+
+	UserID UserID
+}
 
-func (e UserDescriptionUpdatedEvent) EventType() string {
-	return string(UserDescriptionUpdatedEventType)
+func NewUserAvatarRemovedEvent(id UserID) *UserAvatarRemovedEvent {
+	return &UserAvatarRemovedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+	}
 }
 
+func (e UserAvatarRemovedEvent) EventType() string { return string(UserAvatarRemovedEventType) }
+
 type UserPasswordUpdatedEvent struct {
-	UserID     UserID
-	Password   string
-	occurredOn time.Time
+	ddd.EventBase
+
+	UserID   UserID
+	Password string
 }
 
 func NewUserPasswordUpdatedEvent(id UserID, description string) *UserPasswordUpdatedEvent {
 	return &UserPasswordUpdatedEvent{
-		UserID:     id,
-		Password:   description,
-		occurredOn: time.Now(),
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+		Password:  description,
 	}
 }
 
-func (e UserPasswordUpdatedEvent) OccurredOn() time.Time { return e.occurredOn }
-
 func (e UserPasswordUpdatedEvent) EventType() string { return string(UserPasswordUpdatedEventType) }
 
+// UserPasswordRehashedEvent doesn't carry the new hash, as the password itself hasn't
+// changed
+type UserPasswordRehashedEvent struct {
+	ddd.EventBase
+
+	UserID UserID
+}
+
+func NewUserPasswordRehashedEvent(id UserID) *UserPasswordRehashedEvent {
+	return &UserPasswordRehashedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+	}
+}
+
+func (e UserPasswordRehashedEvent) EventType() string {
+	return string(UserPasswordRehashedEventType)
+}
+
+type UserLockedOutEvent struct {
+	ddd.EventBase
+
+	UserID         UserID
+	LockedUntil    time.Time
+	FailedAttempts int
+}
+
+func NewUserLockedOutEvent(
+	id UserID,
+	lockedUntil time.Time,
+	failedAttempts int,
+) *UserLockedOutEvent {
+	return &UserLockedOutEvent{
+		EventBase:      ddd.NewEventBase(time.Now()),
+		UserID:         id,
+		LockedUntil:    lockedUntil,
+		FailedAttempts: failedAttempts,
+	}
+}
+
+func (e UserLockedOutEvent) EventType() string { return string(UserLockedOutEventType) }
+
+type UserUnlockedEvent struct {
+	ddd.EventBase
+
+	UserID UserID
+}
+
+func NewUserUnlockedEvent(id UserID) *UserUnlockedEvent {
+	return &UserUnlockedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+	}
+}
+
+func (e UserUnlockedEvent) EventType() string { return string(UserUnlockedEventType) }
+
+type UserSuspendedEvent struct {
+	ddd.EventBase
+
+	UserID         UserID
+	SuspendedUntil time.Time
+	Reason         string
+}
+
+func NewUserSuspendedEvent(
+	id UserID,
+	suspendedUntil time.Time,
+	reason string,
+) *UserSuspendedEvent {
+	return &UserSuspendedEvent{
+		EventBase:      ddd.NewEventBase(time.Now()),
+		UserID:         id,
+		SuspendedUntil: suspendedUntil,
+		Reason:         reason,
+	}
+}
+
+func (e UserSuspendedEvent) EventType() string { return string(UserSuspendedEventType) }
+
+type UserBannedEvent struct {
+	ddd.EventBase
+
+	UserID UserID
+	Reason string
+}
+
+func NewUserBannedEvent(id UserID, reason string) *UserBannedEvent {
+	return &UserBannedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+		Reason:    reason,
+	}
+}
+
+func (e UserBannedEvent) EventType() string { return string(UserBannedEventType) }
+
+type UserReinstatedEvent struct {
+	ddd.EventBase
+
+	UserID         UserID
+	PreviousStatus UserStatus
+}
+
+func NewUserReinstatedEvent(id UserID, previousStatus UserStatus) *UserReinstatedEvent {
+	return &UserReinstatedEvent{
+		EventBase:      ddd.NewEventBase(time.Now()),
+		UserID:         id,
+		PreviousStatus: previousStatus,
+	}
+}
+
+func (e UserReinstatedEvent) EventType() string { return string(UserReinstatedEventType) }
+
+type UserDeletionRequestedEvent struct {
+	ddd.EventBase
+
+	UserID      UserID
+	DeleteAfter time.Time
+}
+
+func NewUserDeletionRequestedEvent(id UserID, deleteAfter time.Time) *UserDeletionRequestedEvent {
+	return &UserDeletionRequestedEvent{
+		EventBase:   ddd.NewEventBase(time.Now()),
+		UserID:      id,
+		DeleteAfter: deleteAfter,
+	}
+}
+
+func (e UserDeletionRequestedEvent) EventType() string {
+	return string(UserDeletionRequestedEventType)
+}
+
+type UserDeletionCancelledEvent struct {
+	ddd.EventBase
+
+	UserID UserID
+}
+
+func NewUserDeletionCancelledEvent(id UserID) *UserDeletionCancelledEvent {
+	return &UserDeletionCancelledEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+	}
+}
+
+func (e UserDeletionCancelledEvent) EventType() string {
+	return string(UserDeletionCancelledEventType)
+}
+
+type UserAnonymisedEvent struct {
+	ddd.EventBase
+
+	UserID         UserID
+	Username       string
+	ContentRemoved bool
+}
+
+func NewUserAnonymisedEvent(
+	id UserID,
+	username string,
+	contentRemoved bool,
+) *UserAnonymisedEvent {
+	return &UserAnonymisedEvent{
+		EventBase:      ddd.NewEventBase(time.Now()),
+		UserID:         id,
+		Username:       username,
+		ContentRemoved: contentRemoved,
+	}
+}
+
+func (e UserAnonymisedEvent) EventType() string { return string(UserAnonymisedEventType) }
+
+type UserEmailChangeRequestedEvent struct {
+	ddd.EventBase
+
+	UserID    UserID
+	OldEmail  string
+	NewEmail  string
+	ExpiresAt time.Time
+}
+
+func NewUserEmailChangeRequestedEvent(
+	id UserID,
+	oldEmail, newEmail string,
+	expiresAt time.Time,
+) *UserEmailChangeRequestedEvent {
+	return &UserEmailChangeRequestedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+		OldEmail:  oldEmail,
+		NewEmail:  newEmail,
+		ExpiresAt: expiresAt,
+	}
+}
+
+func (e UserEmailChangeRequestedEvent) EventType() string {
+	return string(UserEmailChangeRequestedEventType)
+}
+
+type UserEmailChangedEvent struct {
+	ddd.EventBase
+
+	UserID   UserID
+	OldEmail string
+	NewEmail string
+}
+
+func NewUserEmailChangedEvent(id UserID, oldEmail, newEmail string) *UserEmailChangedEvent {
+	return &UserEmailChangedEvent{
+		EventBase: ddd.NewEventBase(time.Now()),
+		UserID:    id,
+		OldEmail:  oldEmail,
+		NewEmail:  newEmail,
+	}
+}
+
+func (e UserEmailChangedEvent) EventType() string { return string(UserEmailChangedEventType) }
+
+type UserUsernameChangedEvent struct {
+	ddd.EventBase
+
+	UserID      UserID
+	OldUsername string
+	NewUsername string
+}
+
+func NewUserUsernameChangedEvent(id UserID, oldUsername, newUsername string) *UserUsernameChangedEvent {
+	return &UserUsernameChangedEvent{
+		EventBase:   ddd.NewEventBase(time.Now()),
+		UserID:      id,
+		OldUsername: oldUsername,
+		NewUsername: newUsername,
+	}
+}
+
+func (e UserUsernameChangedEvent) EventType() string {
+	return string(UserUsernameChangedEventType)
+}
+
 func init() {
 	ddd.EventRegistry.Register(
 		UserCreatedEvent{},
@@ -135,12 +427,82 @@ func init() {
 	)
 
 	ddd.EventRegistry.Register(
-		UserDescriptionUpdatedEvent{},
-		"Raised when a user's description is updated",
+		UserProfileUpdatedEvent{},
+		"Raised when a user's display name, description, location or links are updated",
+	)
+
+	ddd.EventRegistry.Register(
+		UserAvatarChangedEvent{},
+		"Raised when a user uploads a new avatar",
+	)
+
+	ddd.EventRegistry.Register(
+		UserAvatarRemovedEvent{},
+		"Raised when a user removes their avatar",
 	)
 
 	ddd.EventRegistry.Register(
 		UserPasswordUpdatedEvent{},
 		"Raised when a user's password is updated",
 	)
+
+	ddd.EventRegistry.Register(
+		UserPasswordRehashedEvent{},
+		"Raised when a user's password is rehashed with the current algorithm and cost",
+	)
+
+	ddd.EventRegistry.Register(
+		UserLockedOutEvent{},
+		"Raised when a user's account is locked after repeated failed logins",
+	)
+
+	ddd.EventRegistry.Register(
+		UserUnlockedEvent{},
+		"Raised when a user's account lock is lifted",
+	)
+
+	ddd.EventRegistry.Register(
+		UserSuspendedEvent{},
+		"Raised when a user is suspended until a given time",
+	)
+
+	ddd.EventRegistry.Register(
+		UserBannedEvent{},
+		"Raised when a user is banned",
+	)
+
+	ddd.EventRegistry.Register(
+		UserReinstatedEvent{},
+		"Raised when a user's suspension or ban is lifted",
+	)
+
+	ddd.EventRegistry.Register(
+		UserDeletionRequestedEvent{},
+		"Raised when a user deactivates their account pending deletion",
+	)
+
+	ddd.EventRegistry.Register(
+		UserDeletionCancelledEvent{},
+		"Raised when a user reactivates their account during the deletion grace period",
+	)
+
+	ddd.EventRegistry.Register(
+		UserAnonymisedEvent{},
+		"Raised when a deleted user's personal data is anonymised",
+	)
+
+	ddd.EventRegistry.Register(
+		UserEmailChangeRequestedEvent{},
+		"Raised when a user asks to change their email, before the new address is confirmed",
+	)
+
+	ddd.EventRegistry.Register(
+		UserEmailChangedEvent{},
+		"Raised when a user confirms their new email address",
+	)
+
+	ddd.EventRegistry.Register(
+		UserUsernameChangedEvent{},
+		"Raised when a user changes their username",
+	)
 }
@@ -1,15 +1,47 @@
 package domain
 
+import "time"
+
 type UserRepository interface {
 	All() ([]User, error)
 	FindByID(id UserID) (*User, error)
 	FindByEmail(email string) (*User, error)
 	FindByUsername(username string) (*User, error)
 	Exists(id UserID) (bool, error)
+	// UsernameExists also counts usernames that are still reserved after being changed
 	UsernameExists(username string) (bool, error)
 	EmailExists(email string) (bool, error)
 	Create(user *User) (*User, error)
-	UpdateRoles(id UserID, roles []UserRole) error
-	UpdateDescription(id UserID, newDescription string) error
-	UpdatePasswordHash(id UserID, passwordHash string) error
+	UpdateRoles(user *User) error
+	UpdateProfile(user *User) error
+	UpdateAvatar(user *User) error
+	UpdatePasswordHash(user *User) error
+	UpdateLockedUntil(user *User) error
+	UpdateStatus(user *User) error
+	// FindExpiredSuspensions returns suspended users whose suspension ended before the
+	// given time
+	FindExpiredSuspensions(at time.Time) ([]User, error)
+	UpdateDeletion(user *User) error
+	// FindDueForAnonymisation returns users whose deletion grace period ended before the
+	// given time
+	FindDueForAnonymisation(at time.Time) ([]User, error)
+	// Anonymise persists an anonymised user, dropping their roles and identity history
+	Anonymise(user *User) error
+	// UpdatePendingEmailChange stores the user's pending email change, or clears it if
+	// they have none
+	UpdatePendingEmailChange(user *User) error
+	FindByEmailChangeToken(tokenHash string) (*User, error)
+	// ChangeEmail switches the user to change.NewValue and records the change, failing
+	// with ErrEmailTaken if another user has the address. The check and the update are
+	// one transaction, so two users can't both claim the same address
+	ChangeEmail(user *User, change IdentityChange) error
+	// ChangeUsername renames the user to change.NewValue, reserving the old username
+	// until change.ReservedUntil. Like ChangeEmail it is atomic, failing with
+	// ErrUsernameTaken if the new username is in use or reserved
+	ChangeUsername(user *User, change IdentityChange) error
+	// FindIdentityHistory returns the user's email and username changes, oldest first
+	FindIdentityHistory(id UserID) ([]IdentityChange, error)
+	// FindByReservedUsername returns the user who changed away from the username, if it
+	// is still reserved at the given time
+	FindByReservedUsername(username string, at time.Time) (*User, error)
 }
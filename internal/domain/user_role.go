@@ -5,6 +5,7 @@ type UserRole string
 const (
 	UserRoleAuthor    UserRole = "AUTHOR"
 	UserRoleCommenter UserRole = "COMMENTER"
+	UserRoleEditor    UserRole = "EDITOR"
 	UserRoleAdmin     UserRole = "ADMIN"
 )
 
@@ -0,0 +1,13 @@
+package domain
+
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "ACTIVE"
+	UserStatusSuspended UserStatus = "SUSPENDED"
+	UserStatusBanned    UserStatus = "BANNED"
+)
+
+func (us UserStatus) String() string {
+	return string(us)
+}
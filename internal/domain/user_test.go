@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestUser(t *testing.T) *User {
+	t.Helper()
+
+	user, err := NewUser("a@b.com", "alice", "hash", "", []UserRole{UserRoleCommenter})
+	if err != nil {
+		t.Fatalf("NewUser() failed: %v", err)
+	}
+	user.MarkEventsAsCommitted()
+
+	return user
+}
+
+func TestUserSuspend(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		until   time.Time
+		reason  string
+		wantErr error
+	}{
+		{
+			name:   "Test Suspension In The Future",
+			until:  now.Add(time.Hour),
+			reason: "spam",
+		},
+		{
+			name:    "Test Suspension In The Past Fails",
+			until:   now.Add(-time.Hour),
+			reason:  "spam",
+			wantErr: ErrSuspensionInPast,
+		},
+		{
+			name:    "Test Missing Reason Fails",
+			until:   now.Add(time.Hour),
+			wantErr: ErrMissingReason,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser(t)
+
+			gotErr := user.Suspend(tt.until, tt.reason)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("Suspend() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if user.Status() != UserStatusActive || len(user.GetUncommittedEvents()) != 0 {
+					t.Errorf("Suspend() changed the user after failing")
+				}
+				return
+			}
+
+			if !errors.Is(user.CheckStanding(now), ErrUserSuspended) {
+				t.Errorf("CheckStanding() during suspension = %v", user.CheckStanding(now))
+			}
+			if err := user.CheckStanding(tt.until.Add(time.Second)); err != nil {
+				t.Errorf("CheckStanding() after suspension = %v", err)
+			}
+			if _, ok := user.GetUncommittedEvents()[0].(*UserSuspendedEvent); !ok {
+				t.Errorf("Suspend() recorded %T", user.GetUncommittedEvents()[0])
+			}
+		})
+	}
+}
+
+func TestUserBanAndReinstate(t *testing.T) {
+	user := newTestUser(t)
+
+	if err := user.Reinstate(); !errors.Is(err, ErrUserNotRestricted) {
+		t.Fatalf("Reinstate() on active user error = %v", err)
+	}
+
+	if err := user.Ban("abuse"); err != nil {
+		t.Fatalf("Ban() failed: %v", err)
+	}
+	if !errors.Is(user.CheckStanding(time.Now()), ErrUserBanned) {
+		t.Errorf("CheckStanding() after ban = %v", user.CheckStanding(time.Now()))
+	}
+
+	// A banned user can't be suspended, or banned again
+	if err := user.Suspend(time.Now().Add(time.Hour), "spam"); !errors.Is(err, ErrUserBanned) {
+		t.Errorf("Suspend() on banned user error = %v", err)
+	}
+	if err := user.Ban("abuse"); !errors.Is(err, ErrUserBanned) {
+		t.Errorf("Ban() on banned user error = %v", err)
+	}
+
+	if err := user.Reinstate(); err != nil {
+		t.Fatalf("Reinstate() failed: %v", err)
+	}
+	if user.Status() != UserStatusActive || user.StatusReason() != "" {
+		t.Errorf("Reinstate() left status %s (%q)", user.Status(), user.StatusReason())
+	}
+
+	events := user.GetUncommittedEvents()
+	reinstated, ok := events[len(events)-1].(*UserReinstatedEvent)
+	if !ok || reinstated.PreviousStatus != UserStatusBanned {
+		t.Errorf("Reinstate() recorded %#v", events[len(events)-1])
+	}
+}
+
+func TestUserDeletion(t *testing.T) {
+	user := newTestUser(t)
+
+	if err := user.RequestDeletion(time.Hour); err != nil {
+		t.Fatalf("RequestDeletion() failed: %v", err)
+	}
+	if !errors.Is(user.CheckStanding(time.Now()), ErrUserDeactivated) {
+		t.Errorf("CheckStanding() after deletion request = %v", user.CheckStanding(time.Now()))
+	}
+	if err := user.RequestDeletion(time.Hour); !errors.Is(err, ErrDeletionRequested) {
+		t.Errorf("RequestDeletion() twice error = %v", err)
+	}
+
+	// The account can't be anonymised during the grace period
+	if err := user.Anonymise("deleted-user-0000", time.Now(), false); !errors.Is(err, ErrDeletionNotDue) {
+		t.Fatalf("Anonymise() during grace period error = %v", err)
+	}
+
+	if err := user.Anonymise("deleted-user-0000", time.Now().Add(2*time.Hour), false); err != nil {
+		t.Fatalf("Anonymise() failed: %v", err)
+	}
+	if user.Username() != "deleted-user-0000" || user.PasswordHash() != "" ||
+		user.Description() != "" || len(user.UserRoles()) != 0 {
+		t.Errorf("Anonymise() left personal data: %#v", user)
+	}
+	if err := user.CancelDeletion(); !errors.Is(err, ErrUserAnonymised) {
+		t.Errorf("CancelDeletion() after anonymising error = %v", err)
+	}
+}
+
+func TestUserConfirmEmailChange(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		tokenHash string
+		at        time.Time
+		wantErr   error
+	}{
+		{
+			name:      "Test Matching Token",
+			tokenHash: "hash",
+			at:        now,
+		},
+		{
+			name:      "Test Wrong Token Fails",
+			tokenHash: "other",
+			at:        now,
+			wantErr:   ErrInvalidEmailChangeToken,
+		},
+		{
+			name:      "Test Expired Token Fails",
+			tokenHash: "hash",
+			at:        now.Add(2 * time.Hour),
+			wantErr:   ErrEmailChangeExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser(t)
+
+			if err := user.RequestEmailChange("new@b.com", "hash", now.Add(time.Hour)); err != nil {
+				t.Fatalf("RequestEmailChange() failed: %v", err)
+			}
+
+			gotErr := user.ConfirmEmailChange(tt.tokenHash, tt.at)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("ConfirmEmailChange() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if user.Email() != "a@b.com" || user.PendingEmailChange() == nil {
+					t.Errorf("ConfirmEmailChange() changed the user after failing")
+				}
+				return
+			}
+
+			if user.Email() != "new@b.com" || user.PendingEmailChange() != nil {
+				t.Errorf("ConfirmEmailChange() left email %s, pending %v", user.Email(), user.PendingEmailChange())
+			}
+		})
+	}
+}
+
+func TestUserChangeUsername(t *testing.T) {
+	user := newTestUser(t)
+	now := time.Now()
+	cooldown := 24 * time.Hour
+
+	if err := user.ChangeUsername("alice", now, cooldown); !errors.Is(err, ErrUsernameUnchanged) {
+		t.Errorf("ChangeUsername() to same username error = %v", err)
+	}
+
+	if err := user.ChangeUsername("alicia", now, cooldown); err != nil {
+		t.Fatalf("ChangeUsername() failed: %v", err)
+	}
+
+	// The cooldown starts from the last change
+	if err := user.ChangeUsername("ali", now.Add(time.Hour), cooldown); !errors.Is(err, ErrUsernameChangeTooSoon) {
+		t.Errorf("ChangeUsername() during cooldown error = %v", err)
+	}
+	if err := user.ChangeUsername("ali", now.Add(cooldown), cooldown); err != nil {
+		t.Errorf("ChangeUsername() after cooldown failed: %v", err)
+	}
+
+	changed, ok := user.GetUncommittedEvents()[0].(*UserUsernameChangedEvent)
+	if !ok || changed.OldUsername != "alice" || changed.NewUsername != "alicia" {
+		t.Errorf("ChangeUsername() recorded %#v", user.GetUncommittedEvents()[0])
+	}
+}
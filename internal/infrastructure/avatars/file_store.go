@@ -0,0 +1,212 @@
+package avatars
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"blog/internal/domain"
+)
+
+// maxSourceDimension bounds the width and height of an uploaded image, so a small file
+// can't decode into an enormous bitmap
+const maxSourceDimension = 4096
+
+// pathComponent matches the user IDs and versions that are safe to use as directory names
+var pathComponent = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// FileStore keeps avatars on disk as <dir>/<user id>/<version>/<size>.png
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		dir: dir,
+	}, nil
+}
+
+// Save crops the image to a centred square and writes it out at every avatar size.
+// Earlier versions are removed once the new one is in place
+func (s FileStore) Save(userID domain.UserID, version string, r io.Reader) error {
+	if !pathComponent.MatchString(userID.String()) || !pathComponent.MatchString(version) {
+		return fmt.Errorf("invalid avatar path %s/%s", userID, version)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return domain.ErrInvalidAvatar
+	}
+	if config.Width > maxSourceDimension || config.Height > maxSourceDimension {
+		return domain.ErrInvalidAvatar
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return domain.ErrInvalidAvatar
+	}
+
+	square := cropSquare(src)
+
+	// Write every size into a scratch directory first, so a half written version is
+	// never served
+	userDir := filepath.Join(s.dir, userID.String())
+	if err := os.MkdirAll(userDir, 0o755); err != nil {
+		return err
+	}
+
+	scratch, err := os.MkdirTemp(userDir, ".upload-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scratch)
+
+	for _, size := range domain.AvatarSizes {
+		if err := writePNG(filepath.Join(scratch, sizeFilename(size)), resize(square, size)); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(scratch, filepath.Join(userDir, version)); err != nil {
+		return err
+	}
+
+	return s.removeVersionsExcept(userDir, version)
+}
+
+func (s FileStore) Open(userID domain.UserID, version string, size int) (io.ReadCloser, error) {
+	if !pathComponent.MatchString(userID.String()) ||
+		!pathComponent.MatchString(version) ||
+		!slices.Contains(domain.AvatarSizes, size) {
+		return nil, domain.ErrAvatarNotFound
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, userID.String(), version, sizeFilename(size)))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, domain.ErrAvatarNotFound
+		}
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (s FileStore) Delete(userID domain.UserID) error {
+	if !pathComponent.MatchString(userID.String()) {
+		return fmt.Errorf("invalid avatar path %s", userID)
+	}
+
+	return os.RemoveAll(filepath.Join(s.dir, userID.String()))
+}
+
+func (s FileStore) removeVersionsExcept(userDir, version string) error {
+	entries, err := os.ReadDir(userDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == version || !pathComponent.MatchString(entry.Name()) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(userDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sizeFilename(size int) string {
+	return strconv.Itoa(size) + ".png"
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// cropSquare copies the largest centred square out of the image
+func cropSquare(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+
+	origin := image.Point{
+		X: bounds.Min.X + (bounds.Dx()-side)/2,
+		Y: bounds.Min.Y + (bounds.Dy()-side)/2,
+	}
+
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), src, origin, draw.Src)
+
+	return square
+}
+
+// resize scales a square image to size x size. Each output pixel averages the block of
+// source pixels it covers, which is good enough for downscaling photos to thumbnails
+func resize(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for y := range size {
+		y0 := y * side / size
+		y1 := max((y+1)*side/size, y0+1)
+
+		for x := range size {
+			x0 := x * side / size
+			x1 := max((x+1)*side/size, x0+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
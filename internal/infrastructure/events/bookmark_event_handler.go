@@ -0,0 +1,157 @@
+package events
+
+import (
+	"errors"
+	"log"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type BookmarkEventHandler struct{}
+
+func NewBookmarkEventHandler() *BookmarkEventHandler {
+	return &BookmarkEventHandler{}
+}
+
+func (h BookmarkEventHandler) Register(dispatcher ddd.EventDispatcher) {
+	dispatcher.Subscribe(
+		domain.BookmarkCreatedEventType.String(),
+		"events.BookmarkEventHandler.HandleBookmarkCreated",
+		h.HandleBookmarkCreated,
+	)
+
+	dispatcher.Subscribe(
+		domain.BookmarkNoteUpdatedEventType.String(),
+		"events.BookmarkEventHandler.HandleBookmarkNoteUpdated",
+		h.HandleBookmarkNoteUpdated,
+	)
+
+	dispatcher.Subscribe(
+		domain.BookmarkMovedEventType.String(),
+		"events.BookmarkEventHandler.HandleBookmarkMoved",
+		h.HandleBookmarkMoved,
+	)
+
+	dispatcher.Subscribe(
+		domain.BookmarkRemovedEventType.String(),
+		"events.BookmarkEventHandler.HandleBookmarkRemoved",
+		h.HandleBookmarkRemoved,
+	)
+
+	dispatcher.Subscribe(
+		domain.BookmarkFolderCreatedEventType.String(),
+		"events.BookmarkEventHandler.HandleBookmarkFolderCreated",
+		h.HandleBookmarkFolderCreated,
+	)
+
+	dispatcher.Subscribe(
+		domain.BookmarkFolderRenamedEventType.String(),
+		"events.BookmarkEventHandler.HandleBookmarkFolderRenamed",
+		h.HandleBookmarkFolderRenamed,
+	)
+
+	dispatcher.Subscribe(
+		domain.BookmarkFolderDeletedEventType.String(),
+		"events.BookmarkEventHandler.HandleBookmarkFolderDeleted",
+		h.HandleBookmarkFolderDeleted,
+	)
+}
+
+func (h BookmarkEventHandler) HandleBookmarkCreated(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.BookmarkCreatedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"BookmarkCreatedEvent handled for ID: %s",
+		e.BookmarkID.String(),
+	)
+
+	return nil
+}
+
+func (h BookmarkEventHandler) HandleBookmarkNoteUpdated(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.BookmarkNoteUpdatedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"BookmarkNoteUpdatedEvent handled for ID: %s",
+		e.BookmarkID.String(),
+	)
+
+	return nil
+}
+
+func (h BookmarkEventHandler) HandleBookmarkMoved(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.BookmarkMovedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"BookmarkMovedEvent handled for ID: %s",
+		e.BookmarkID.String(),
+	)
+
+	return nil
+}
+
+func (h BookmarkEventHandler) HandleBookmarkRemoved(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.BookmarkRemovedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"BookmarkRemovedEvent handled for ID: %s",
+		e.BookmarkID.String(),
+	)
+
+	return nil
+}
+
+func (h BookmarkEventHandler) HandleBookmarkFolderCreated(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.BookmarkFolderCreatedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"BookmarkFolderCreatedEvent handled for ID: %s",
+		e.FolderID.String(),
+	)
+
+	return nil
+}
+
+func (h BookmarkEventHandler) HandleBookmarkFolderRenamed(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.BookmarkFolderRenamedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"BookmarkFolderRenamedEvent handled for ID: %s",
+		e.FolderID.String(),
+	)
+
+	return nil
+}
+
+func (h BookmarkEventHandler) HandleBookmarkFolderDeleted(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.BookmarkFolderDeletedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"BookmarkFolderDeletedEvent handled for ID: %s",
+		e.FolderID.String(),
+	)
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"context"
 	"errors"
 	"log"
 
@@ -8,41 +9,65 @@ import (
 	"blog/pkg/ddd"
 )
 
-type CommentEventHandler struct{}
+// CommentNotifier notifies users of new comments on posts they wrote or commented on
+type CommentNotifier interface {
+	NotifyCommented(ctx context.Context, commentID, postID, commenterID string) error
+}
+
+type CommentEventHandler struct {
+	notifier CommentNotifier
+}
 
-func NewCommentEventHandler() *CommentEventHandler {
-	return &CommentEventHandler{}
+func NewCommentEventHandler(notifier CommentNotifier) *CommentEventHandler {
+	return &CommentEventHandler{
+		notifier: notifier,
+	}
 }
 
 func (h CommentEventHandler) Register(dispatcher ddd.EventDispatcher) {
-	dispatcher.Subscribe(
+	dispatcher.SubscribeContext(
 		domain.CommentCreatedEventType.String(),
+		"events.CommentEventHandler.HandleCommentCreated",
 		h.HandleCommentCreated,
 	)
 
 	dispatcher.Subscribe(
 		domain.CommentEditedEventType.String(),
+		"events.CommentEventHandler.HandleCommentEdited",
 		h.HandleCommentEdited,
 	)
 
 	dispatcher.Subscribe(
 		domain.CommentArchivedEventType.String(),
+		"events.CommentEventHandler.HandleCommentArchived",
 		h.HandleCommentArchived,
 	)
+
+	dispatcher.Subscribe(
+		domain.CommentRestoredEventType.String(),
+		"events.CommentEventHandler.HandleCommentRestored",
+		h.HandleCommentRestored,
+	)
 }
 
-func (h CommentEventHandler) HandleCommentCreated(event ddd.DomainEvent) error {
+func (h CommentEventHandler) HandleCommentCreated(ctx context.Context, event ddd.DomainEvent) error {
 	e, ok := event.(*domain.CommentCreatedEvent)
 	if !ok {
 		return errors.New("invalid event type")
 	}
 
 	log.Printf(
-		"CommentCreatedEvent handled for ID: %s",
+		"CommentCreatedEvent handled for ID: %s, Correlation: %s",
 		e.CommentID.String(),
+		ddd.EventMetadataFrom(ctx).CorrelationID,
 	)
 
-	return nil
+	return h.notifier.NotifyCommented(
+		ctx,
+		e.CommentID.String(),
+		e.PostID.String(),
+		e.CommenterID.String(),
+	)
 }
 
 func (h CommentEventHandler) HandleCommentEdited(event ddd.DomainEvent) error {
@@ -72,3 +97,17 @@ func (h CommentEventHandler) HandleCommentArchived(event ddd.DomainEvent) error
 
 	return nil
 }
+
+func (h CommentEventHandler) HandleCommentRestored(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.CommentRestoredEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"CommentRestoredEvent handled for ID: %s",
+		e.CommentID.String(),
+	)
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"errors"
+	"log"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type FollowEventHandler struct{}
+
+func NewFollowEventHandler() *FollowEventHandler {
+	return &FollowEventHandler{}
+}
+
+func (h FollowEventHandler) Register(dispatcher ddd.EventDispatcher) {
+	dispatcher.Subscribe(
+		domain.UserFollowedEventType.String(),
+		"events.FollowEventHandler.HandleUserFollowed",
+		h.HandleUserFollowed,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserUnfollowedEventType.String(),
+		"events.FollowEventHandler.HandleUserUnfollowed",
+		h.HandleUserUnfollowed,
+	)
+}
+
+func (h FollowEventHandler) HandleUserFollowed(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserFollowedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserFollowedEvent handled for Follow: %s, Follower: %s, Followee: %s",
+		e.FollowID.String(),
+		e.FollowerID.String(),
+		e.FolloweeID.String(),
+	)
+
+	return nil
+}
+
+func (h FollowEventHandler) HandleUserUnfollowed(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserUnfollowedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserUnfollowedEvent handled for Follow: %s, Follower: %s, Followee: %s",
+		e.FollowID.String(),
+		e.FollowerID.String(),
+		e.FolloweeID.String(),
+	)
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"errors"
+	"log"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type ImpersonationEventHandler struct{}
+
+func NewImpersonationEventHandler() *ImpersonationEventHandler {
+	return &ImpersonationEventHandler{}
+}
+
+func (h ImpersonationEventHandler) Register(dispatcher ddd.EventDispatcher) {
+	dispatcher.Subscribe(
+		domain.ImpersonationStartedEventType.String(),
+		"events.ImpersonationEventHandler.HandleImpersonationStarted",
+		h.HandleImpersonationStarted,
+	)
+
+	dispatcher.Subscribe(
+		domain.ImpersonationStoppedEventType.String(),
+		"events.ImpersonationEventHandler.HandleImpersonationStopped",
+		h.HandleImpersonationStopped,
+	)
+}
+
+func (h ImpersonationEventHandler) HandleImpersonationStarted(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.ImpersonationStartedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"ImpersonationStartedEvent handled for Impersonation: %s, Admin: %s, User: %s, Reason: %s",
+		e.ImpersonationID.String(),
+		e.AdminID.String(),
+		e.UserID.String(),
+		e.Reason,
+	)
+
+	return nil
+}
+
+func (h ImpersonationEventHandler) HandleImpersonationStopped(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.ImpersonationStoppedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"ImpersonationStoppedEvent handled for Impersonation: %s, Admin: %s, User: %s, Duration: %s",
+		e.ImpersonationID.String(),
+		e.AdminID.String(),
+		e.UserID.String(),
+		e.Duration,
+	)
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package events
+
+import (
+	"errors"
+	"log"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type NewsletterEventHandler struct{}
+
+func NewNewsletterEventHandler() *NewsletterEventHandler {
+	return &NewsletterEventHandler{}
+}
+
+func (h NewsletterEventHandler) Register(dispatcher ddd.EventDispatcher) {
+	dispatcher.Subscribe(
+		domain.NewsletterSubscribedEventType.String(),
+		"events.NewsletterEventHandler.HandleNewsletterSubscribed",
+		h.HandleNewsletterSubscribed,
+	)
+
+	dispatcher.Subscribe(
+		domain.NewsletterSubscriptionConfirmedEventType.String(),
+		"events.NewsletterEventHandler.HandleNewsletterSubscriptionConfirmed",
+		h.HandleNewsletterSubscriptionConfirmed,
+	)
+
+	dispatcher.Subscribe(
+		domain.NewsletterUnsubscribedEventType.String(),
+		"events.NewsletterEventHandler.HandleNewsletterUnsubscribed",
+		h.HandleNewsletterUnsubscribed,
+	)
+
+	dispatcher.Subscribe(
+		domain.NewsletterSubscriberDisabledEventType.String(),
+		"events.NewsletterEventHandler.HandleNewsletterSubscriberDisabled",
+		h.HandleNewsletterSubscriberDisabled,
+	)
+}
+
+func (h NewsletterEventHandler) HandleNewsletterSubscribed(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.NewsletterSubscribedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"NewsletterSubscribedEvent handled for ID: %s",
+		e.SubscriberID.String(),
+	)
+
+	return nil
+}
+
+func (h NewsletterEventHandler) HandleNewsletterSubscriptionConfirmed(
+	event ddd.DomainEvent,
+) error {
+	e, ok := event.(*domain.NewsletterSubscriptionConfirmedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"NewsletterSubscriptionConfirmedEvent handled for ID: %s",
+		e.SubscriberID.String(),
+	)
+
+	return nil
+}
+
+func (h NewsletterEventHandler) HandleNewsletterUnsubscribed(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.NewsletterUnsubscribedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"NewsletterUnsubscribedEvent handled for ID: %s",
+		e.SubscriberID.String(),
+	)
+
+	return nil
+}
+
+func (h NewsletterEventHandler) HandleNewsletterSubscriberDisabled(
+	event ddd.DomainEvent,
+) error {
+	e, ok := event.(*domain.NewsletterSubscriberDisabledEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"NewsletterSubscriberDisabledEvent handled for ID: %s, Reason: %s",
+		e.SubscriberID.String(),
+		e.Reason.String(),
+	)
+
+	return nil
+}
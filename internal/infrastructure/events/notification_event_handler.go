@@ -0,0 +1,59 @@
+package events
+
+import (
+	"errors"
+	"log"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type NotificationEventHandler struct{}
+
+func NewNotificationEventHandler() *NotificationEventHandler {
+	return &NotificationEventHandler{}
+}
+
+func (h NotificationEventHandler) Register(dispatcher ddd.EventDispatcher) {
+	dispatcher.Subscribe(
+		domain.NotificationCreatedEventType.String(),
+		"events.NotificationEventHandler.HandleNotificationCreated",
+		h.HandleNotificationCreated,
+	)
+
+	dispatcher.Subscribe(
+		domain.NotificationReadEventType.String(),
+		"events.NotificationEventHandler.HandleNotificationRead",
+		h.HandleNotificationRead,
+	)
+}
+
+func (h NotificationEventHandler) HandleNotificationCreated(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.NotificationCreatedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"NotificationCreatedEvent handled for ID: %s, Type: %s, Recipient: %s",
+		e.NotificationID.String(),
+		e.Type.String(),
+		e.RecipientID.String(),
+	)
+
+	return nil
+}
+
+func (h NotificationEventHandler) HandleNotificationRead(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.NotificationReadEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"NotificationReadEvent handled for ID: %s",
+		e.NotificationID.String(),
+	)
+
+	return nil
+}
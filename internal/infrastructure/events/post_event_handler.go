@@ -1,53 +1,86 @@
 package events
 
 import (
+	"context"
 	"errors"
 	"log"
+	"time"
 
 	"blog/internal/domain"
 	"blog/pkg/ddd"
 )
 
-type PostEventHandler struct{}
+// NewsletterQueue queues new posts to be emailed to newsletter subscribers
+type NewsletterQueue interface {
+	QueuePost(postID string) error
+}
+
+type PostEventHandler struct {
+	newsletter NewsletterQueue
+}
+
+func NewPostEventHandler(newsletter NewsletterQueue) *PostEventHandler {
+	return &PostEventHandler{
+		newsletter: newsletter,
+	}
+}
 
-func NewPostEventHandler() *PostEventHandler {
-	return &PostEventHandler{}
+// newsletterRetryPolicy gives queueing a new post for the newsletter longer than other
+// handlers to succeed, as subscribers miss the post if it's dead-lettered
+var newsletterRetryPolicy = ddd.RetryPolicy{
+	MaxAttempts:  5,
+	InitialDelay: 500 * time.Millisecond,
+	MaxDelay:     10 * time.Second,
+	Jitter:       0.2,
 }
 
 func (h PostEventHandler) Register(dispatcher ddd.EventDispatcher) {
-	dispatcher.Subscribe(
+	ddd.SubscribeWithPolicy(
+		dispatcher,
 		domain.PostCreatedEventType.String(),
+		"events.PostEventHandler.HandlePostCreated",
+		newsletterRetryPolicy,
 		h.HandlePostCreated,
 	)
 
 	dispatcher.Subscribe(
 		domain.PostTitleEditedEventType.String(),
+		"events.PostEventHandler.HandlePostTitleEdited",
 		h.HandlePostTitleEdited,
 	)
 
 	dispatcher.Subscribe(
 		domain.PostContentEditedEventType.String(),
+		"events.PostEventHandler.HandlePostContentEdited",
 		h.HandlePostContentEdited,
 	)
 
 	dispatcher.Subscribe(
 		domain.PostArchivedEventType.String(),
+		"events.PostEventHandler.HandlePostArchived",
 		h.HandlePostArchived,
 	)
+
+	dispatcher.Subscribe(
+		domain.PostRestoredEventType.String(),
+		"events.PostEventHandler.HandlePostRestored",
+		h.HandlePostRestored,
+	)
 }
 
-func (h PostEventHandler) HandlePostCreated(event ddd.DomainEvent) error {
+func (h PostEventHandler) HandlePostCreated(ctx context.Context, event ddd.DomainEvent) error {
 	e, ok := event.(*domain.PostCreatedEvent)
 	if !ok {
 		return errors.New("invalid event type")
 	}
 
 	log.Printf(
-		"PostCreatedEvent handled for ID: %s",
+		"PostCreatedEvent handled for ID: %s, Correlation: %s",
 		e.PostID.String(),
+		ddd.EventMetadataFrom(ctx).CorrelationID,
 	)
 
-	return nil
+	return h.newsletter.QueuePost(e.PostID.String())
 }
 
 func (h PostEventHandler) HandlePostTitleEdited(event ddd.DomainEvent) error {
@@ -91,3 +124,17 @@ func (h PostEventHandler) HandlePostArchived(event ddd.DomainEvent) error {
 
 	return nil
 }
+
+func (h PostEventHandler) HandlePostRestored(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.PostRestoredEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"PostRestoredEvent handled for ID: %s",
+		e.PostID.String(),
+	)
+
+	return nil
+}
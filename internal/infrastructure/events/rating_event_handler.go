@@ -1,6 +1,7 @@
 package events
 
 import (
+	"context"
 	"errors"
 	"log"
 
@@ -8,41 +9,59 @@ import (
 	"blog/pkg/ddd"
 )
 
-type RatingEventHandler struct{}
+// RatingNotifier notifies authors that their posts were rated
+type RatingNotifier interface {
+	NotifyRated(ctx context.Context, postID, raterID, ratingType string) error
+}
+
+type RatingEventHandler struct {
+	notifier RatingNotifier
+}
 
-func NewRatingEventHandler() *RatingEventHandler {
-	return &RatingEventHandler{}
+func NewRatingEventHandler(notifier RatingNotifier) *RatingEventHandler {
+	return &RatingEventHandler{
+		notifier: notifier,
+	}
 }
 
 func (h RatingEventHandler) Register(dispatcher ddd.EventDispatcher) {
-	dispatcher.Subscribe(
+	dispatcher.SubscribeContext(
 		domain.RatingCreatedEventType.String(),
+		"events.RatingEventHandler.HandleRatingCreated",
 		h.HandleRatingCreated,
 	)
 
 	dispatcher.Subscribe(
 		domain.RatingChangedEventType.String(),
+		"events.RatingEventHandler.HandleRatingChanged",
 		h.HandleRatingChanged,
 	)
 
 	dispatcher.Subscribe(
 		domain.RatingRemovedEventType.String(),
+		"events.RatingEventHandler.HandleRatingRemoved",
 		h.HandleRatingRemoved,
 	)
 }
 
-func (h RatingEventHandler) HandleRatingCreated(event ddd.DomainEvent) error {
+func (h RatingEventHandler) HandleRatingCreated(ctx context.Context, event ddd.DomainEvent) error {
 	e, ok := event.(*domain.RatingCreatedEvent)
 	if !ok {
 		return errors.New("invalid event type")
 	}
 
 	log.Printf(
-		"RatingCreatedEvent handled for ID: %s",
+		"RatingCreatedEvent handled for ID: %s, Correlation: %s",
 		e.RatingID.String(),
+		ddd.EventMetadataFrom(ctx).CorrelationID,
 	)
 
-	return nil
+	return h.notifier.NotifyRated(
+		ctx,
+		e.PostID.String(),
+		e.UserID.String(),
+		e.RatingType.String(),
+	)
 }
 
 func (h RatingEventHandler) HandleRatingChanged(event ddd.DomainEvent) error {
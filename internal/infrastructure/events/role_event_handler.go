@@ -0,0 +1,79 @@
+package events
+
+import (
+	"errors"
+	"log"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+)
+
+type RoleEventHandler struct{}
+
+func NewRoleEventHandler() *RoleEventHandler {
+	return &RoleEventHandler{}
+}
+
+func (h RoleEventHandler) Register(dispatcher ddd.EventDispatcher) {
+	dispatcher.Subscribe(
+		domain.RoleCreatedEventType.String(),
+		"events.RoleEventHandler.HandleRoleCreated",
+		h.HandleRoleCreated,
+	)
+
+	dispatcher.Subscribe(
+		domain.RolePermissionsChangedEventType.String(),
+		"events.RoleEventHandler.HandleRolePermissionsChanged",
+		h.HandleRolePermissionsChanged,
+	)
+
+	dispatcher.Subscribe(
+		domain.RoleDeletedEventType.String(),
+		"events.RoleEventHandler.HandleRoleDeleted",
+		h.HandleRoleDeleted,
+	)
+}
+
+func (h RoleEventHandler) HandleRoleCreated(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.RoleCreatedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"RoleCreatedEvent handled for Role: %s, Permissions: %v",
+		e.RoleName.String(),
+		e.Permissions,
+	)
+
+	return nil
+}
+
+func (h RoleEventHandler) HandleRolePermissionsChanged(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.RolePermissionsChangedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"RolePermissionsChangedEvent handled for Role: %s, Permissions: %v",
+		e.RoleName.String(),
+		e.Permissions,
+	)
+
+	return nil
+}
+
+func (h RoleEventHandler) HandleRoleDeleted(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.RoleDeletedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"RoleDeletedEvent handled for Role: %s",
+		e.RoleName.String(),
+	)
+
+	return nil
+}
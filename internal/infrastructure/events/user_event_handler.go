@@ -17,28 +17,105 @@ func NewUserEventHandler() *UserEventHandler {
 func (h UserEventHandler) Register(dispatcher ddd.EventDispatcher) {
 	dispatcher.Subscribe(
 		domain.UserCreatedEventType.String(),
+		"events.UserEventHandler.HandleUserCreated",
 		h.HandleUserCreated,
 	)
 
 	dispatcher.Subscribe(
 		domain.UserRoleAddedEventType.String(),
+		"events.UserEventHandler.HandleUserRoleAdded",
 		h.HandleUserRoleAdded,
 	)
 
 	dispatcher.Subscribe(
 		domain.UserRoleRemovedEventType.String(),
+		"events.UserEventHandler.HandleUserRoleRemoved",
 		h.HandleUserRoleRemoved,
 	)
 
 	dispatcher.Subscribe(
-		domain.UserDescriptionUpdatedEventType.String(),
-		h.HandleUserDescriptionUpdated,
+		domain.UserProfileUpdatedEventType.String(),
+		"events.UserEventHandler.HandleUserProfileUpdated",
+		h.HandleUserProfileUpdated,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserAvatarChangedEventType.String(),
+		"events.UserEventHandler.HandleUserAvatarChanged",
+		h.HandleUserAvatarChanged,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserAvatarRemovedEventType.String(),
+		"events.UserEventHandler.HandleUserAvatarRemoved",
+		h.HandleUserAvatarRemoved,
 	)
 
 	dispatcher.Subscribe(
 		domain.UserPasswordUpdatedEventType.String(),
+		"events.UserEventHandler.HandleUserPasswordUpdated",
 		h.HandleUserPasswordUpdated,
 	)
+
+	dispatcher.Subscribe(
+		domain.UserPasswordRehashedEventType.String(),
+		"events.UserEventHandler.HandleUserPasswordRehashed",
+		h.HandleUserPasswordRehashed,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserSuspendedEventType.String(),
+		"events.UserEventHandler.HandleUserSuspended",
+		h.HandleUserSuspended,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserBannedEventType.String(),
+		"events.UserEventHandler.HandleUserBanned",
+		h.HandleUserBanned,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserReinstatedEventType.String(),
+		"events.UserEventHandler.HandleUserReinstated",
+		h.HandleUserReinstated,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserDeletionRequestedEventType.String(),
+		"events.UserEventHandler.HandleUserDeletionRequested",
+		h.HandleUserDeletionRequested,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserDeletionCancelledEventType.String(),
+		"events.UserEventHandler.HandleUserDeletionCancelled",
+		h.HandleUserDeletionCancelled,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserAnonymisedEventType.String(),
+		"events.UserEventHandler.HandleUserAnonymised",
+		h.HandleUserAnonymised,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserEmailChangeRequestedEventType.String(),
+		"events.UserEventHandler.HandleUserEmailChangeRequested",
+		h.HandleUserEmailChangeRequested,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserEmailChangedEventType.String(),
+		"events.UserEventHandler.HandleUserEmailChanged",
+		h.HandleUserEmailChanged,
+	)
+
+	dispatcher.Subscribe(
+		domain.UserUsernameChangedEventType.String(),
+		"events.UserEventHandler.HandleUserUsernameChanged",
+		h.HandleUserUsernameChanged,
+	)
 }
 
 func (h UserEventHandler) HandleUserCreated(event ddd.DomainEvent) error {
@@ -83,14 +160,42 @@ func (h UserEventHandler) HandleUserRoleRemoved(event ddd.DomainEvent) error {
 	return nil
 }
 
-func (h UserEventHandler) HandleUserDescriptionUpdated(event ddd.DomainEvent) error {
-	e, ok := event.(*domain.UserDescriptionUpdatedEvent)
+func (h UserEventHandler) HandleUserProfileUpdated(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserProfileUpdatedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserProfileUpdatedEvent handled for ID: %s",
+		e.UserID.String(),
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserAvatarChanged(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserAvatarChangedEvent)
 	if !ok {
 		return errors.New("invalid event type")
 	}
 
 	log.Printf(
-		"UserDescriptionUpdatedEvent handled for ID: %s",
+		"UserAvatarChangedEvent handled for ID: %s",
+		e.UserID.String(),
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserAvatarRemoved(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserAvatarRemovedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserAvatarRemovedEvent handled for ID: %s",
 		e.UserID.String(),
 	)
 
@@ -110,3 +215,149 @@ func (h UserEventHandler) HandleUserPasswordUpdated(event ddd.DomainEvent) error
 
 	return nil
 }
+
+func (h UserEventHandler) HandleUserPasswordRehashed(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserPasswordRehashedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserPasswordRehashedEvent handled for ID: %s",
+		e.UserID.String(),
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserSuspended(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserSuspendedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserSuspendedEvent handled for ID: %s, Until: %s, Reason: %s",
+		e.UserID.String(),
+		e.SuspendedUntil,
+		e.Reason,
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserBanned(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserBannedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserBannedEvent handled for ID: %s, Reason: %s",
+		e.UserID.String(),
+		e.Reason,
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserReinstated(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserReinstatedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserReinstatedEvent handled for ID: %s, Previous Status: %s",
+		e.UserID.String(),
+		e.PreviousStatus.String(),
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserDeletionRequested(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserDeletionRequestedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserDeletionRequestedEvent handled for ID: %s, Delete After: %s",
+		e.UserID.String(),
+		e.DeleteAfter,
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserDeletionCancelled(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserDeletionCancelledEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserDeletionCancelledEvent handled for ID: %s",
+		e.UserID.String(),
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserAnonymised(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserAnonymisedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserAnonymisedEvent handled for ID: %s, Content Removed: %t",
+		e.UserID.String(),
+		e.ContentRemoved,
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserEmailChangeRequested(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserEmailChangeRequestedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserEmailChangeRequestedEvent handled for ID: %s",
+		e.UserID.String(),
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserEmailChanged(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserEmailChangedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserEmailChangedEvent handled for ID: %s",
+		e.UserID.String(),
+	)
+
+	return nil
+}
+
+func (h UserEventHandler) HandleUserUsernameChanged(event ddd.DomainEvent) error {
+	e, ok := event.(*domain.UserUsernameChangedEvent)
+	if !ok {
+		return errors.New("invalid event type")
+	}
+
+	log.Printf(
+		"UserUsernameChangedEvent handled for ID: %s",
+		e.UserID.String(),
+	)
+
+	return nil
+}
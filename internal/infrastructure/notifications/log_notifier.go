@@ -0,0 +1,38 @@
+package notifications
+
+import (
+	"log"
+	"time"
+)
+
+// LogNotifier writes account emails to the log instead of sending them. It stands in
+// until the application is configured to send mail
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n LogNotifier) SendEmailChangeConfirmation(
+	username, newEmail, token string,
+	expiresAt time.Time,
+) error {
+	log.Printf(
+		"Email to %s: %s, confirm your new email address with token %s before %s",
+		newEmail,
+		username,
+		token,
+		expiresAt.Format(time.RFC1123),
+	)
+	return nil
+}
+
+func (n LogNotifier) SendEmailChangedNotice(username, oldEmail, newEmail string) error {
+	log.Printf(
+		"Email to %s: %s, your account's email address was changed to %s",
+		oldEmail,
+		username,
+		newEmail,
+	)
+	return nil
+}
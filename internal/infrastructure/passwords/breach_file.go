@@ -0,0 +1,152 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+// hashPrefixLength is how many hex characters of a SHA-1 hash make up a range, as in
+// the Pwned Passwords k-anonymity API
+const hashPrefixLength = 5
+
+// BreachFile looks passwords up in a local copy of a breached password list. Each line
+// is an uppercase hex SHA-1 hash, optionally followed by ":" and a count, and the lines
+// are sorted by hash, as in the Pwned Passwords "ordered by hash" download. The file is
+// binary searched, so it is never loaded into memory
+type BreachFile struct {
+	file *os.File
+	size int64
+}
+
+func OpenBreachFile(path string) (*BreachFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &BreachFile{
+		file: file,
+		size: info.Size(),
+	}, nil
+}
+
+func (f *BreachFile) Close() error {
+	return f.file.Close()
+}
+
+// Contains hashes the password and checks its suffix against the range of hashes that
+// share its prefix
+func (f *BreachFile) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	suffixes, err := f.Range(prefix)
+	if err != nil {
+		return false, err
+	}
+
+	for _, candidate := range suffixes {
+		if candidate == suffix {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Range returns the suffixes of every hash in the file starting with the prefix
+func (f *BreachFile) Range(prefix string) ([]string, error) {
+	prefix = strings.ToUpper(prefix)
+
+	// Find the first line at or after the prefix. Lines are sorted, so the line found
+	// from an offset only ever moves forward as the offset does
+	lo, hi := int64(0), f.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		line, err := f.lineFrom(mid)
+		if err != nil {
+			return nil, err
+		}
+
+		if line == "" || hashOf(line) >= prefix {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	reader, err := f.readerFrom(lo)
+	if err != nil {
+		return nil, err
+	}
+
+	suffixes := []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		hash := hashOf(strings.TrimSpace(line))
+		if !strings.HasPrefix(hash, prefix) {
+			break
+		}
+		suffixes = append(suffixes, hash[len(prefix):])
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return suffixes, nil
+}
+
+// lineFrom returns the first whole line starting at or after the offset, or an empty
+// string at the end of the file
+func (f *BreachFile) lineFrom(offset int64) (string, error) {
+	reader, err := f.readerFrom(offset)
+	if err != nil {
+		return "", err
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// readerFrom returns a reader positioned at the start of the first whole line at or
+// after the offset
+func (f *BreachFile) readerFrom(offset int64) (*bufio.Reader, error) {
+	if offset == 0 {
+		return bufio.NewReader(io.NewSectionReader(f.file, 0, f.size)), nil
+	}
+
+	// Start a byte early, so an offset that is already at the start of a line only
+	// skips the newline before it
+	reader := bufio.NewReader(io.NewSectionReader(f.file, offset-1, f.size-offset+1))
+	if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+func hashOf(line string) string {
+	hash, _, _ := strings.Cut(line, ":")
+	return strings.ToUpper(hash)
+}
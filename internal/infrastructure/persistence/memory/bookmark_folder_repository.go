@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type BookmarkFolderRepository struct {
+	mu      sync.RWMutex
+	folders map[domain.BookmarkFolderID]domain.BookmarkFolder
+	outbox  *dddmemory.InMemoryOutboxStore
+}
+
+func NewBookmarkFolderRepository(outbox *dddmemory.InMemoryOutboxStore) *BookmarkFolderRepository {
+	return &BookmarkFolderRepository{
+		folders: map[domain.BookmarkFolderID]domain.BookmarkFolder{},
+		outbox:  outbox,
+	}
+}
+
+func (r *BookmarkFolderRepository) FindByID(
+	id domain.BookmarkFolderID,
+) (*domain.BookmarkFolder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	folder, exists := r.folders[id]
+	if !exists {
+		return nil, domain.ErrBookmarkFolderNotFound
+	}
+
+	return &folder, nil
+}
+
+func (r *BookmarkFolderRepository) FindByUser(
+	userID domain.UserID,
+) ([]domain.BookmarkFolder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	folders := []domain.BookmarkFolder{}
+	for k := range r.folders {
+		if r.folders[k].UserID() == userID {
+			folders = append(folders, r.folders[k])
+		}
+	}
+	slices.SortFunc(folders, func(a, b domain.BookmarkFolder) int {
+		return strings.Compare(strings.ToLower(a.Name()), strings.ToLower(b.Name()))
+	})
+
+	return folders, nil
+}
+
+func (r *BookmarkFolderRepository) Create(
+	folder *domain.BookmarkFolder,
+) (*domain.BookmarkFolder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(folder.UserID(), folder.Name(), folder.GetID()) {
+		return nil, domain.ErrBookmarkFolderNameTaken
+	}
+
+	if err := r.outbox.Append(folder.GetID().String(), folder); err != nil {
+		return nil, err
+	}
+	r.folders[folder.GetID()] = *folder
+
+	f := r.folders[folder.GetID()]
+	return &f, nil
+}
+
+func (r *BookmarkFolderRepository) Rename(folder *domain.BookmarkFolder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.folders[folder.GetID()]; !exists {
+		return domain.ErrBookmarkFolderNotFound
+	}
+	if r.nameTaken(folder.UserID(), folder.Name(), folder.GetID()) {
+		return domain.ErrBookmarkFolderNameTaken
+	}
+
+	if err := r.outbox.Append(folder.GetID().String(), folder); err != nil {
+		return err
+	}
+	r.folders[folder.GetID()] = *folder
+
+	return nil
+}
+
+func (r *BookmarkFolderRepository) Delete(folder *domain.BookmarkFolder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(folder.GetID().String(), folder); err != nil {
+		return err
+	}
+	delete(r.folders, folder.GetID())
+
+	return nil
+}
+
+func (r *BookmarkFolderRepository) DeleteByUser(userID domain.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, folder := range r.folders {
+		if folder.UserID() == userID {
+			delete(r.folders, id)
+		}
+	}
+
+	return nil
+}
+
+// nameTaken reports whether another of the user's folders has the name, ignoring case
+// like the sqlite column does
+func (r *BookmarkFolderRepository) nameTaken(
+	userID domain.UserID,
+	name string,
+	except domain.BookmarkFolderID,
+) bool {
+	for id, folder := range r.folders {
+		if id != except && folder.UserID() == userID && strings.EqualFold(folder.Name(), name) {
+			return true
+		}
+	}
+	return false
+}
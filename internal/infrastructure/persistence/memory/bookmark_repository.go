@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type BookmarkRepository struct {
+	mu        sync.RWMutex
+	bookmarks map[domain.BookmarkID]domain.Bookmark
+	outbox    *dddmemory.InMemoryOutboxStore
+}
+
+func NewBookmarkRepository(outbox *dddmemory.InMemoryOutboxStore) *BookmarkRepository {
+	return &BookmarkRepository{
+		bookmarks: map[domain.BookmarkID]domain.Bookmark{},
+		outbox:    outbox,
+	}
+}
+
+func (r *BookmarkRepository) All() ([]domain.Bookmark, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bookmarks := []domain.Bookmark{}
+	for k := range r.bookmarks {
+		bookmarks = append(bookmarks, r.bookmarks[k])
+	}
+
+	return bookmarks, nil
+}
+
+func (r *BookmarkRepository) FindByID(id domain.BookmarkID) (*domain.Bookmark, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bookmark, exists := r.bookmarks[id]
+	if !exists {
+		return nil, domain.ErrBookmarkNotFound
+	}
+
+	return &bookmark, nil
+}
+
+func (r *BookmarkRepository) FindByUser(userID domain.UserID) ([]domain.Bookmark, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bookmarks := []domain.Bookmark{}
+	for k := range r.bookmarks {
+		if r.bookmarks[k].UserID() == userID {
+			bookmarks = append(bookmarks, r.bookmarks[k])
+		}
+	}
+	sortBookmarks(bookmarks)
+
+	return bookmarks, nil
+}
+
+func (r *BookmarkRepository) FindByFolder(
+	folderID domain.BookmarkFolderID,
+) ([]domain.Bookmark, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bookmarks := []domain.Bookmark{}
+	for k := range r.bookmarks {
+		if r.bookmarks[k].FolderID() == folderID {
+			bookmarks = append(bookmarks, r.bookmarks[k])
+		}
+	}
+	sortBookmarks(bookmarks)
+
+	return bookmarks, nil
+}
+
+func (r *BookmarkRepository) Exists(id domain.BookmarkID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.bookmarks[id]
+	return exists, nil
+}
+
+func (r *BookmarkRepository) ExistsOnPostByUser(
+	postID domain.PostID,
+	userID domain.UserID,
+) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for k := range r.bookmarks {
+		if r.bookmarks[k].PostID() == postID && r.bookmarks[k].UserID() == userID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *BookmarkRepository) Create(bookmark *domain.Bookmark) (*domain.Bookmark, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k := range r.bookmarks {
+		if r.bookmarks[k].PostID() == bookmark.PostID() &&
+			r.bookmarks[k].UserID() == bookmark.UserID() {
+			return nil, domain.ErrAlreadyBookmarked
+		}
+	}
+
+	if err := r.outbox.Append(bookmark.GetID().String(), bookmark); err != nil {
+		return nil, err
+	}
+	r.bookmarks[bookmark.GetID()] = *bookmark
+
+	b := r.bookmarks[bookmark.GetID()]
+	return &b, nil
+}
+
+func (r *BookmarkRepository) UpdateNote(bookmark *domain.Bookmark) error {
+	return r.update(bookmark)
+}
+
+func (r *BookmarkRepository) UpdateFolder(bookmark *domain.Bookmark) error {
+	return r.update(bookmark)
+}
+
+func (r *BookmarkRepository) update(bookmark *domain.Bookmark) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.bookmarks[bookmark.GetID()]; !exists {
+		return domain.ErrBookmarkNotFound
+	}
+
+	if err := r.outbox.Append(bookmark.GetID().String(), bookmark); err != nil {
+		return err
+	}
+	r.bookmarks[bookmark.GetID()] = *bookmark
+
+	return nil
+}
+
+func (r *BookmarkRepository) RemoveBookmark(bookmark *domain.Bookmark) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.bookmarks[bookmark.GetID()]; !exists {
+		return domain.ErrBookmarkNotFound
+	}
+
+	if err := r.outbox.Append(bookmark.GetID().String(), bookmark); err != nil {
+		return err
+	}
+	delete(r.bookmarks, bookmark.GetID())
+
+	return nil
+}
+
+func (r *BookmarkRepository) DeleteByUser(userID domain.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, bookmark := range r.bookmarks {
+		if bookmark.UserID() == userID {
+			delete(r.bookmarks, id)
+		}
+	}
+
+	return nil
+}
+
+// sortBookmarks orders bookmarks newest first
+func sortBookmarks(bookmarks []domain.Bookmark) {
+	slices.SortFunc(bookmarks, func(a, b domain.Bookmark) int {
+		return b.CreatedAt().Compare(a.CreatedAt())
+	})
+}
@@ -1,110 +1,135 @@
 package memory
 
 import (
-	"errors"
 	"sync"
 
 	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
 )
 
+// CommentRepository keeps only the event stream of each comment, rebuilding comments
+// from their streams whenever they're read, so nothing it returns shares state with
+// what it stores
 type CommentRepository struct {
-	mu       sync.RWMutex
-	comments map[domain.CommentID]domain.Comment
+	mu     sync.RWMutex
+	events *dddmemory.InMemoryEventStore
+	ids    map[domain.CommentID]bool
+	outbox *dddmemory.InMemoryOutboxStore
 }
 
-func NewCommentRepository() *CommentRepository {
+func NewCommentRepository(outbox *dddmemory.InMemoryOutboxStore) *CommentRepository {
 	return &CommentRepository{
-		comments: map[domain.CommentID]domain.Comment{},
+		events: dddmemory.NewInMemoryEventStore(),
+		ids:    map[domain.CommentID]bool{},
+		outbox: outbox,
 	}
 }
 
 func (r *CommentRepository) All() ([]domain.Comment, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	comments := []domain.Comment{}
-	for k := range r.comments {
-		comments = append(comments, r.comments[k])
-	}
-
-	return comments, nil
+	return r.find(func(comment *domain.Comment) bool {
+		return true
+	})
 }
 
 func (r *CommentRepository) FindByID(id domain.CommentID) (*domain.Comment, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	comment, exists := r.comments[id]
-	if !exists {
-		return nil, errors.New("no rows")
+	history, err := r.events.Load(id.String())
+	if err != nil {
+		return nil, err
 	}
 
-	return &comment, nil
+	return domain.LoadComment(history)
 }
 
 func (r *CommentRepository) FindByUser(userID domain.UserID) ([]domain.Comment, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	comments := []domain.Comment{}
-	for k := range r.comments {
-		if r.comments[k].CommenterID() == userID {
-			comments = append(comments, r.comments[k])
-		}
-	}
-
-	return comments, nil
+	return r.find(func(comment *domain.Comment) bool {
+		return comment.CommenterID() == userID
+	})
 }
 
 func (r *CommentRepository) FindByPost(postID domain.PostID) ([]domain.Comment, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	comments := []domain.Comment{}
-	for k := range r.comments {
-		if r.comments[k].PostID() == postID {
-			comments = append(comments, r.comments[k])
-		}
-	}
-
-	return comments, nil
+	return r.find(func(comment *domain.Comment) bool {
+		return comment.PostID() == postID
+	})
 }
 
 func (r *CommentRepository) Exists(id domain.CommentID) (bool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	_, exists := r.comments[id]
-	return exists, nil
+	return r.ids[id], nil
 }
 
 func (r *CommentRepository) Create(comment *domain.Comment) (*domain.Comment, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	if err := r.save(comment); err != nil {
+		return nil, err
+	}
 
-	r.comments[comment.GetID()] = *comment
+	return r.FindByID(comment.GetID())
+}
+
+func (r *CommentRepository) UpdateContent(comment *domain.Comment) error {
+	return r.save(comment)
+}
 
-	c := r.comments[comment.GetID()]
-	return &c, nil
+func (r *CommentRepository) Archive(comment *domain.Comment) error {
+	return r.save(comment)
 }
 
-func (r *CommentRepository) UpdateContent(id domain.CommentID, newContent string) error {
+func (r *CommentRepository) Restore(comment *domain.Comment) error {
+	return r.save(comment)
+}
+
+func (r *CommentRepository) DeleteByUser(userID domain.UserID) error {
+	comments, err := r.FindByUser(userID)
+	if err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	c := r.comments[id]
-	c.Edit(newContent)
-	r.comments[id] = c
+	for i := range comments {
+		r.events.Delete(comments[i].GetID().String())
+		delete(r.ids, comments[i].GetID())
+	}
 
 	return nil
 }
 
-func (r *CommentRepository) Archive(id domain.CommentID) error {
+func (r *CommentRepository) save(comment *domain.Comment) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	c := r.comments[id]
-	c.Archive()
-	r.comments[id] = c
+	err := r.events.Append(
+		comment.GetID().String(),
+		comment.Version(),
+		comment.GetUncommittedEvents(),
+	)
+	if err != nil {
+		return err
+	}
+	if err := r.outbox.Append(comment.GetID().String(), comment); err != nil {
+		return err
+	}
 
+	r.ids[comment.GetID()] = true
 	return nil
 }
+
+// find rebuilds each comment from its stream, returning those that match
+func (r *CommentRepository) find(match func(comment *domain.Comment) bool) ([]domain.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := []domain.Comment{}
+	for id := range r.ids {
+		comment, err := r.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if match(comment) {
+			comments = append(comments, *comment)
+		}
+	}
+
+	return comments, nil
+}
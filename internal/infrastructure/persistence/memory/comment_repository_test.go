@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"testing"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+func TestChangingALoadedCommentLeavesTheStoredOne(t *testing.T) {
+	repo := NewCommentRepository(dddmemory.NewInMemoryOutboxStore())
+
+	comment, err := domain.NewComment("post", "bob", "Nice post")
+	if err != nil {
+		t.Fatalf("NewComment() failed: %v", err)
+	}
+	if _, err := repo.Create(comment); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	loaded, err := repo.FindByID(comment.GetID())
+	if err != nil {
+		t.Fatalf("FindByID() failed: %v", err)
+	}
+	if err := loaded.Edit("Edited"); err != nil {
+		t.Fatalf("Edit() failed: %v", err)
+	}
+	comment.Archive()
+
+	stored, err := repo.FindByID(comment.GetID())
+	if err != nil {
+		t.Fatalf("FindByID() failed: %v", err)
+	}
+	if stored.Content() != "Nice post" || stored.Archived() {
+		t.Errorf("stored comment = %q, archived %v, want the unchanged comment",
+			stored.Content(), stored.Archived())
+	}
+	if stored.Version() != 1 || len(stored.GetUncommittedEvents()) != 0 {
+		t.Errorf("stored comment is at version %d with %d uncommitted events, want 1 and 0",
+			stored.Version(), len(stored.GetUncommittedEvents()))
+	}
+
+	comments, err := repo.FindByPost("post")
+	if err != nil {
+		t.Fatalf("FindByPost() failed: %v", err)
+	}
+	if len(comments) != 1 || comments[0].Content() != "Nice post" {
+		t.Errorf("FindByPost() = %d comments, want the unchanged comment", len(comments))
+	}
+}
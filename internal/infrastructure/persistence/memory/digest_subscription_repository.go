@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"sync"
+
+	"blog/internal/domain"
+)
+
+type DigestSubscriptionRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[domain.UserID]domain.DigestSubscription
+}
+
+func NewDigestSubscriptionRepository() *DigestSubscriptionRepository {
+	return &DigestSubscriptionRepository{
+		subscriptions: map[domain.UserID]domain.DigestSubscription{},
+	}
+}
+
+func (r *DigestSubscriptionRepository) Find(
+	userID domain.UserID,
+) (*domain.DigestSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, ok := r.subscriptions[userID]
+	if !ok {
+		return domain.NewDigestSubscription(userID), nil
+	}
+
+	return &subscription, nil
+}
+
+func (r *DigestSubscriptionRepository) FindSubscribed() ([]domain.DigestSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriptions := []domain.DigestSubscription{}
+	for _, subscription := range r.subscriptions {
+		if subscription.Frequency() != domain.DigestFrequencyOff {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+
+	return subscriptions, nil
+}
+
+func (r *DigestSubscriptionRepository) Save(subscription *domain.DigestSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[subscription.UserID()] = *domain.RebuildDigestSubscription(
+		subscription.UserID(),
+		subscription.Frequency(),
+		subscription.LastSentAt(),
+	)
+
+	return nil
+}
+
+func (r *DigestSubscriptionRepository) DeleteByUser(userID domain.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.subscriptions, userID)
+
+	return nil
+}
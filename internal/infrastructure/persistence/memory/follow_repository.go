@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type FollowRepository struct {
+	mu      sync.RWMutex
+	follows map[domain.FollowID]domain.Follow
+	outbox  *dddmemory.InMemoryOutboxStore
+}
+
+func NewFollowRepository(outbox *dddmemory.InMemoryOutboxStore) *FollowRepository {
+	return &FollowRepository{
+		follows: map[domain.FollowID]domain.Follow{},
+		outbox:  outbox,
+	}
+}
+
+func (r *FollowRepository) Find(followerID, followeeID domain.UserID) (*domain.Follow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for k := range r.follows {
+		follow := r.follows[k]
+		if follow.FollowerID() == followerID && follow.FolloweeID() == followeeID {
+			return &follow, nil
+		}
+	}
+
+	return nil, domain.ErrFollowNotFound
+}
+
+func (r *FollowRepository) FindFollowers(userID domain.UserID) ([]domain.Follow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	follows := []domain.Follow{}
+	for k := range r.follows {
+		if r.follows[k].FolloweeID() == userID {
+			follows = append(follows, r.follows[k])
+		}
+	}
+	sortFollows(follows)
+
+	return follows, nil
+}
+
+func (r *FollowRepository) FindFollowing(userID domain.UserID) ([]domain.Follow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	follows := []domain.Follow{}
+	for k := range r.follows {
+		if r.follows[k].FollowerID() == userID {
+			follows = append(follows, r.follows[k])
+		}
+	}
+	sortFollows(follows)
+
+	return follows, nil
+}
+
+func (r *FollowRepository) CountFollowers(userID domain.UserID) (int, error) {
+	follows, err := r.FindFollowers(userID)
+	return len(follows), err
+}
+
+func (r *FollowRepository) CountFollowing(userID domain.UserID) (int, error) {
+	follows, err := r.FindFollowing(userID)
+	return len(follows), err
+}
+
+func (r *FollowRepository) Create(follow *domain.Follow) (*domain.Follow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k := range r.follows {
+		if r.follows[k].FollowerID() == follow.FollowerID() &&
+			r.follows[k].FolloweeID() == follow.FolloweeID() {
+			return nil, domain.ErrAlreadyFollowing
+		}
+	}
+
+	if err := r.outbox.Append(follow.GetID().String(), follow); err != nil {
+		return nil, err
+	}
+	r.follows[follow.GetID()] = *domain.RebuildFollow(
+		follow.GetID(),
+		follow.FollowerID(),
+		follow.FolloweeID(),
+		follow.CreatedAt(),
+	)
+
+	return follow, nil
+}
+
+func (r *FollowRepository) Delete(follow *domain.Follow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(follow.GetID().String(), follow); err != nil {
+		return err
+	}
+	delete(r.follows, follow.GetID())
+
+	return nil
+}
+
+func (r *FollowRepository) DeleteByUser(userID domain.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, follow := range r.follows {
+		if follow.FollowerID() == userID || follow.FolloweeID() == userID {
+			delete(r.follows, id)
+		}
+	}
+
+	return nil
+}
+
+// sortFollows orders follows newest first
+func sortFollows(follows []domain.Follow) {
+	slices.SortFunc(follows, func(a, b domain.Follow) int {
+		return b.CreatedAt().Compare(a.CreatedAt())
+	})
+}
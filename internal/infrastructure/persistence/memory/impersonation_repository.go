@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type ImpersonationRepository struct {
+	mu             sync.RWMutex
+	impersonations map[domain.ImpersonationID]domain.Impersonation
+	outbox         *dddmemory.InMemoryOutboxStore
+}
+
+func NewImpersonationRepository(
+	outbox *dddmemory.InMemoryOutboxStore,
+) *ImpersonationRepository {
+	return &ImpersonationRepository{
+		impersonations: map[domain.ImpersonationID]domain.Impersonation{},
+		outbox:         outbox,
+	}
+}
+
+func (r *ImpersonationRepository) All() ([]domain.Impersonation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	impersonations := []domain.Impersonation{}
+	for k := range r.impersonations {
+		impersonations = append(impersonations, r.impersonations[k])
+	}
+	sortImpersonations(impersonations)
+
+	return impersonations, nil
+}
+
+func (r *ImpersonationRepository) FindByID(
+	id domain.ImpersonationID,
+) (*domain.Impersonation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	impersonation, exists := r.impersonations[id]
+	if !exists {
+		return nil, domain.ErrImpersonationNotFound
+	}
+
+	return &impersonation, nil
+}
+
+func (r *ImpersonationRepository) FindByUser(
+	userID domain.UserID,
+) ([]domain.Impersonation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	impersonations := []domain.Impersonation{}
+	for k := range r.impersonations {
+		if r.impersonations[k].UserID() == userID {
+			impersonations = append(impersonations, r.impersonations[k])
+		}
+	}
+	sortImpersonations(impersonations)
+
+	return impersonations, nil
+}
+
+func (r *ImpersonationRepository) Create(
+	impersonation *domain.Impersonation,
+) (*domain.Impersonation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(impersonation.GetID().String(), impersonation); err != nil {
+		return nil, err
+	}
+	r.impersonations[impersonation.GetID()] = *domain.RebuildImpersonation(
+		impersonation.GetID(),
+		impersonation.AdminID(),
+		impersonation.UserID(),
+		impersonation.Reason(),
+		impersonation.StartedAt(),
+		impersonation.EndedAt(),
+	)
+
+	return impersonation, nil
+}
+
+func (r *ImpersonationRepository) End(impersonation *domain.Impersonation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.impersonations[impersonation.GetID()]
+	if !exists {
+		return domain.ErrImpersonationNotFound
+	}
+	if !stored.Active() {
+		return domain.ErrImpersonationEnded
+	}
+
+	if err := r.outbox.Append(impersonation.GetID().String(), impersonation); err != nil {
+		return err
+	}
+	r.impersonations[impersonation.GetID()] = *impersonation
+
+	return nil
+}
+
+// sortImpersonations orders impersonations newest first
+func sortImpersonations(impersonations []domain.Impersonation) {
+	slices.SortFunc(impersonations, func(a, b domain.Impersonation) int {
+		return b.StartedAt().Compare(a.StartedAt())
+	})
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"blog/internal/domain"
+)
+
+type LoginThrottleStore struct {
+	mu       sync.RWMutex
+	attempts map[string]domain.LoginAttempts
+}
+
+func NewLoginThrottleStore() *LoginThrottleStore {
+	return &LoginThrottleStore{
+		attempts: map[string]domain.LoginAttempts{},
+	}
+}
+
+func (s *LoginThrottleStore) Get(key string) (domain.LoginAttempts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.attempts[key], nil
+}
+
+// RecordFailure counts the failure under the lock, so concurrent failures are all counted
+func (s *LoginThrottleStore) RecordFailure(
+	key string,
+	at, resetBefore time.Time,
+	backoff func(failures int) time.Duration,
+) (domain.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.attempts[key]
+	if attempts.LastFailureAt.Before(resetBefore) {
+		attempts = domain.LoginAttempts{}
+	}
+
+	attempts.Failures++
+	attempts.LastFailureAt = at
+	attempts.BlockedUntil = at.Add(backoff(attempts.Failures))
+	s.attempts[key] = attempts
+
+	return attempts, nil
+}
+
+func (s *LoginThrottleStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+
+	return nil
+}
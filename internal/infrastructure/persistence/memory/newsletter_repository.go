@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type newsletterDeliveryKey struct {
+	subscriberID domain.NewsletterSubscriberID
+	postID       domain.PostID
+}
+
+type NewsletterRepository struct {
+	mu          sync.RWMutex
+	subscribers map[domain.NewsletterSubscriberID]domain.NewsletterSubscriber
+	deliveries  map[newsletterDeliveryKey]domain.NewsletterDelivery
+	outbox      *dddmemory.InMemoryOutboxStore
+}
+
+func NewNewsletterRepository(outbox *dddmemory.InMemoryOutboxStore) *NewsletterRepository {
+	return &NewsletterRepository{
+		subscribers: map[domain.NewsletterSubscriberID]domain.NewsletterSubscriber{},
+		deliveries:  map[newsletterDeliveryKey]domain.NewsletterDelivery{},
+		outbox:      outbox,
+	}
+}
+
+func (r *NewsletterRepository) FindByID(
+	id domain.NewsletterSubscriberID,
+) (*domain.NewsletterSubscriber, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriber, ok := r.subscribers[id]
+	if !ok {
+		return nil, domain.ErrNewsletterSubscriberNotFound
+	}
+
+	return &subscriber, nil
+}
+
+func (r *NewsletterRepository) FindByEmailAndAuthor(
+	email string,
+	authorID *domain.UserID,
+) (*domain.NewsletterSubscriber, error) {
+	return r.findOne(func(subscriber domain.NewsletterSubscriber) bool {
+		if subscriber.Email() != domain.NormaliseNewsletterEmail(email) {
+			return false
+		}
+		if subscriber.AuthorID() == nil || authorID == nil {
+			return subscriber.AuthorID() == nil && authorID == nil
+		}
+		return *subscriber.AuthorID() == *authorID
+	})
+}
+
+func (r *NewsletterRepository) FindByEmail(
+	email string,
+) ([]domain.NewsletterSubscriber, error) {
+	return r.findMany(func(subscriber domain.NewsletterSubscriber) bool {
+		return subscriber.Email() == domain.NormaliseNewsletterEmail(email)
+	})
+}
+
+func (r *NewsletterRepository) FindByTokenHash(
+	tokenHash string,
+) (*domain.NewsletterSubscriber, error) {
+	return r.findOne(func(subscriber domain.NewsletterSubscriber) bool {
+		return tokenHash != "" && subscriber.TokenHash() == tokenHash
+	})
+}
+
+func (r *NewsletterRepository) FindActiveForAuthor(
+	authorID domain.UserID,
+) ([]domain.NewsletterSubscriber, error) {
+	return r.findMany(func(subscriber domain.NewsletterSubscriber) bool {
+		return subscriber.Active() && subscriber.Follows(authorID)
+	})
+}
+
+func (r *NewsletterRepository) Save(subscriber *domain.NewsletterSubscriber) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(subscriber.GetID().String(), subscriber); err != nil {
+		return err
+	}
+	r.subscribers[subscriber.GetID()] = *domain.RebuildNewsletterSubscriber(
+		subscriber.GetID(),
+		subscriber.Email(),
+		subscriber.AuthorID(),
+		subscriber.Status(),
+		subscriber.TokenHash(),
+		subscriber.TokenExpiresAt(),
+		subscriber.CreatedAt(),
+		subscriber.ConfirmedAt(),
+		subscriber.DisabledReason(),
+	)
+
+	return nil
+}
+
+func (r *NewsletterRepository) QueueDeliveries(deliveries []domain.NewsletterDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, delivery := range deliveries {
+		key := newsletterDeliveryKey{delivery.SubscriberID, delivery.PostID}
+		if _, ok := r.deliveries[key]; ok {
+			continue
+		}
+		r.deliveries[key] = delivery
+	}
+
+	return nil
+}
+
+func (r *NewsletterRepository) FindPendingDeliveries(
+	limit int,
+) ([]domain.NewsletterDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := []domain.NewsletterDelivery{}
+	for _, delivery := range r.deliveries {
+		if delivery.Status == domain.NewsletterDeliveryPending {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	slices.SortFunc(deliveries, func(a, b domain.NewsletterDelivery) int {
+		return a.QueuedAt.Compare(b.QueuedAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (r *NewsletterRepository) SaveDelivery(delivery *domain.NewsletterDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := newsletterDeliveryKey{delivery.SubscriberID, delivery.PostID}
+	if _, ok := r.deliveries[key]; ok {
+		r.deliveries[key] = *delivery
+	}
+
+	return nil
+}
+
+func (r *NewsletterRepository) findOne(
+	match func(domain.NewsletterSubscriber) bool,
+) (*domain.NewsletterSubscriber, error) {
+	subscribers, err := r.findMany(match)
+	if err != nil {
+		return nil, err
+	}
+	if len(subscribers) == 0 {
+		return nil, domain.ErrNewsletterSubscriberNotFound
+	}
+
+	return &subscribers[0], nil
+}
+
+func (r *NewsletterRepository) findMany(
+	match func(domain.NewsletterSubscriber) bool,
+) ([]domain.NewsletterSubscriber, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscribers := []domain.NewsletterSubscriber{}
+	for _, subscriber := range r.subscribers {
+		if match(subscriber) {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+
+	return subscribers, nil
+}
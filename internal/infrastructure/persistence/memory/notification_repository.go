@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type NotificationRepository struct {
+	mu            sync.RWMutex
+	notifications map[domain.NotificationID]domain.Notification
+	mutes         map[domain.UserID][]domain.NotificationType
+	outbox        *dddmemory.InMemoryOutboxStore
+}
+
+func NewNotificationRepository(outbox *dddmemory.InMemoryOutboxStore) *NotificationRepository {
+	return &NotificationRepository{
+		notifications: map[domain.NotificationID]domain.Notification{},
+		mutes:         map[domain.UserID][]domain.NotificationType{},
+		outbox:        outbox,
+	}
+}
+
+func (r *NotificationRepository) FindByID(
+	id domain.NotificationID,
+) (*domain.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notification, ok := r.notifications[id]
+	if !ok {
+		return nil, domain.ErrNotificationNotFound
+	}
+
+	return &notification, nil
+}
+
+func (r *NotificationRepository) FindByRecipient(
+	recipientID domain.UserID,
+	unreadOnly bool,
+) ([]domain.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notifications := []domain.Notification{}
+	for k := range r.notifications {
+		notification := r.notifications[k]
+		if notification.RecipientID() != recipientID {
+			continue
+		}
+		if unreadOnly && notification.Read() {
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+
+	slices.SortFunc(notifications, func(a, b domain.Notification) int {
+		return b.CreatedAt().Compare(a.CreatedAt())
+	})
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) CountUnread(recipientID domain.UserID) (int, error) {
+	notifications, err := r.FindByRecipient(recipientID, true)
+	return len(notifications), err
+}
+
+func (r *NotificationRepository) Create(
+	notification *domain.Notification,
+) (*domain.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(notification.GetID().String(), notification); err != nil {
+		return nil, err
+	}
+	r.notifications[notification.GetID()] = *domain.RebuildNotification(
+		notification.GetID(),
+		notification.RecipientID(),
+		notification.Type(),
+		notification.ActorID(),
+		notification.PostID(),
+		notification.CommentID(),
+		notification.CreatedAt(),
+		notification.ReadAt(),
+	)
+
+	return notification, nil
+}
+
+func (r *NotificationRepository) MarkRead(notification *domain.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.notifications[notification.GetID()]
+	if !ok || stored.Read() {
+		return nil
+	}
+
+	if err := r.outbox.Append(notification.GetID().String(), notification); err != nil {
+		return err
+	}
+	r.notifications[notification.GetID()] = *notification
+
+	return nil
+}
+
+func (r *NotificationRepository) FindPreferences(
+	userID domain.UserID,
+) (*domain.NotificationPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return domain.NewNotificationPreferences(userID, r.mutes[userID])
+}
+
+func (r *NotificationRepository) SavePreferences(
+	preferences *domain.NotificationPreferences,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mutes[preferences.UserID()] = slices.Clone(preferences.Muted())
+
+	return nil
+}
+
+func (r *NotificationRepository) DeleteByUser(userID domain.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, notification := range r.notifications {
+		if notification.RecipientID() == userID {
+			delete(r.notifications, id)
+		}
+	}
+	delete(r.mutes, userID)
+
+	return nil
+}
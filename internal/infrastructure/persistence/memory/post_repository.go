@@ -2,19 +2,23 @@ package memory
 
 import (
 	"errors"
+	"slices"
 	"sync"
 
 	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
 )
 
 type PostRepository struct {
-	mu    sync.RWMutex
-	posts map[domain.PostID]domain.Post
+	mu     sync.RWMutex
+	posts  map[domain.PostID]domain.Post
+	outbox *dddmemory.InMemoryOutboxStore
 }
 
-func NewPostRepository() *PostRepository {
+func NewPostRepository(outbox *dddmemory.InMemoryOutboxStore) *PostRepository {
 	return &PostRepository{
-		posts: map[domain.PostID]domain.Post{},
+		posts:  map[domain.PostID]domain.Post{},
+		outbox: outbox,
 	}
 }
 
@@ -56,6 +60,40 @@ func (r *PostRepository) FindByAuthor(userID domain.UserID) ([]domain.Post, erro
 	return posts, nil
 }
 
+func (r *PostRepository) FindPublishedByAuthors(
+	authorIDs []domain.UserID,
+	after *domain.PostPosition,
+	limit int,
+) ([]domain.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := []domain.Post{}
+	for k := range r.posts {
+		post := r.posts[k]
+		if post.Archived() || !slices.Contains(authorIDs, post.AuthorID()) {
+			continue
+		}
+		if after != nil && !after.Before(post.Position()) {
+			continue
+		}
+		posts = append(posts, post)
+	}
+
+	slices.SortFunc(posts, func(a, b domain.Post) int {
+		if a.Position().Before(b.Position()) {
+			return -1
+		}
+		return 1
+	})
+
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+
+	return posts, nil
+}
+
 func (r *PostRepository) Exists(id domain.PostID) (bool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -67,41 +105,72 @@ func (r *PostRepository) Create(post *domain.Post) (*domain.Post, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if err := r.outbox.Append(post.GetID().String(), post); err != nil {
+		return nil, err
+	}
 	r.posts[post.GetID()] = *post
 
 	p := r.posts[post.GetID()]
 	return &p, nil
 }
 
-func (r *PostRepository) UpdateTitle(id domain.PostID, newTitle string) error {
+func (r *PostRepository) UpdateTitle(post *domain.Post) error {
+	return r.update(post)
+}
+
+func (r *PostRepository) UpdateContent(post *domain.Post) error {
+	return r.update(post)
+}
+
+func (r *PostRepository) Archive(post *domain.Post) error {
+	return r.update(post)
+}
+
+func (r *PostRepository) Restore(post *domain.Post) error {
+	return r.update(post)
+}
+
+// MarkPopular saves the post as popular. A post that has already been made popular,
+// e.g. by another like handled at the same time, is left as it is without its events
+func (r *PostRepository) MarkPopular(post *domain.Post) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	p := r.posts[id]
-	p.EditTitle(newTitle)
-	r.posts[id] = p
+	if stored, exists := r.posts[post.GetID()]; exists && stored.Popular() {
+		post.MarkEventsAsCommitted()
+		return nil
+	}
+
+	if err := r.outbox.Append(post.GetID().String(), post); err != nil {
+		return err
+	}
+	r.posts[post.GetID()] = *post
 
 	return nil
 }
 
-func (r *PostRepository) UpdateContent(id domain.PostID, newContent string) error {
+func (r *PostRepository) DeleteByAuthor(authorID domain.UserID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	p := r.posts[id]
-	p.EditContent(newContent)
-	r.posts[id] = p
+	for id, p := range r.posts {
+		if p.AuthorID() == authorID {
+			delete(r.posts, id)
+		}
+	}
 
 	return nil
 }
 
-func (r *PostRepository) Archive(id domain.PostID) error {
+// update stores the post as it now is, adding its events to the outbox
+func (r *PostRepository) update(post *domain.Post) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	p := r.posts[id]
-	p.Archive()
-	r.posts[id] = p
+	if err := r.outbox.Append(post.GetID().String(), post); err != nil {
+		return err
+	}
+	r.posts[post.GetID()] = *post
 
 	return nil
 }
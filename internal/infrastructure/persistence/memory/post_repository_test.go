@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"testing"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+func TestMarkingAPostPopularTwiceAddsOneEvent(t *testing.T) {
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	repo := NewPostRepository(outbox)
+
+	post, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	if _, err := repo.Create(post); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// As if by two likes handled at the same time, each loading the post before the
+	// other saved it
+	var loaded []*domain.Post
+	for range 2 {
+		post, err := repo.FindByID(post.GetID())
+		if err != nil {
+			t.Fatalf("FindByID() failed: %v", err)
+		}
+		loaded = append(loaded, post)
+	}
+	for _, post := range loaded {
+		post.MarkPopular("bob", 10)
+		if err := repo.MarkPopular(post); err != nil {
+			t.Fatalf("MarkPopular() failed: %v", err)
+		}
+	}
+
+	var events int
+	for _, message := range outbox.Messages() {
+		if message.EventType == domain.PostBecamePopularEventType.String() {
+			events++
+		}
+	}
+	if events != 1 {
+		t.Errorf("outbox has %d %s events, want 1", events, domain.PostBecamePopularEventType)
+	}
+}
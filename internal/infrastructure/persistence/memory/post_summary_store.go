@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"blog/internal/domain"
+)
+
+type PostSummaryStore struct {
+	mu        sync.RWMutex
+	summaries map[domain.PostID]domain.PostSummary
+	comments  map[domain.CommentID]domain.PostSummaryComment
+	ratings   map[domain.RatingID]domain.PostSummaryRating
+}
+
+func NewPostSummaryStore() *PostSummaryStore {
+	return &PostSummaryStore{
+		summaries: map[domain.PostID]domain.PostSummary{},
+		comments:  map[domain.CommentID]domain.PostSummaryComment{},
+		ratings:   map[domain.RatingID]domain.PostSummaryRating{},
+	}
+}
+
+func (s *PostSummaryStore) All() ([]domain.PostSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := []domain.PostSummary{}
+	for _, summary := range s.summaries {
+		summaries = append(summaries, s.counted(summary))
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].LastActivityAt.After(summaries[j].LastActivityAt)
+	})
+	return summaries, nil
+}
+
+func (s *PostSummaryStore) FindByID(postID domain.PostID) (*domain.PostSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summary, exists := s.summaries[postID]
+	if !exists {
+		return nil, domain.ErrPostNotFound
+	}
+
+	summary = s.counted(summary)
+	return &summary, nil
+}
+
+func (s *PostSummaryStore) Save(summary *domain.PostSummary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.summaries[summary.PostID] = *summary
+	return nil
+}
+
+func (s *PostSummaryStore) RenameAuthor(authorID domain.UserID, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for postID, summary := range s.summaries {
+		if summary.AuthorID == authorID {
+			summary.AuthorUsername = username
+			s.summaries[postID] = summary
+		}
+	}
+	return nil
+}
+
+func (s *PostSummaryStore) FindComment(
+	commentID domain.CommentID,
+) (*domain.PostSummaryComment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, exists := s.comments[commentID]
+	if !exists {
+		return nil, domain.ErrCommentNotFound
+	}
+	return &comment, nil
+}
+
+func (s *PostSummaryStore) SaveComment(comment domain.PostSummaryComment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.comments[comment.CommentID] = comment
+	return nil
+}
+
+func (s *PostSummaryStore) FindRating(ratingID domain.RatingID) (*domain.PostSummaryRating, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rating, exists := s.ratings[ratingID]
+	if !exists {
+		return nil, domain.ErrRatingNotFound
+	}
+	return &rating, nil
+}
+
+func (s *PostSummaryStore) SaveRating(rating domain.PostSummaryRating) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ratings[rating.RatingID] = rating
+	return nil
+}
+
+func (s *PostSummaryStore) DeleteRating(ratingID domain.RatingID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.ratings, ratingID)
+	return nil
+}
+
+func (s *PostSummaryStore) DeleteByUser(userID domain.UserID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := map[domain.PostID]bool{}
+	for postID, summary := range s.summaries {
+		if summary.AuthorID == userID {
+			deleted[postID] = true
+			delete(s.summaries, postID)
+		}
+	}
+	for commentID, comment := range s.comments {
+		if comment.CommenterID == userID || deleted[comment.PostID] {
+			delete(s.comments, commentID)
+		}
+	}
+	for ratingID, rating := range s.ratings {
+		if rating.UserID == userID || deleted[rating.PostID] {
+			delete(s.ratings, ratingID)
+		}
+	}
+	return nil
+}
+
+// Reset empties the store. The memory repositories add every event to the outbox, so
+// the summaries can be rebuilt from the first
+func (s *PostSummaryStore) Reset() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.summaries = map[domain.PostID]domain.PostSummary{}
+	s.comments = map[domain.CommentID]domain.PostSummaryComment{}
+	s.ratings = map[domain.RatingID]domain.PostSummaryRating{}
+	return 0, nil
+}
+
+// counted fills in the summary's counts from the comments and ratings on its post
+func (s *PostSummaryStore) counted(summary domain.PostSummary) domain.PostSummary {
+	summary.CommentCount, summary.Likes, summary.Dislikes = 0, 0, 0
+	for _, comment := range s.comments {
+		if comment.PostID == summary.PostID && !comment.Archived {
+			summary.CommentCount++
+		}
+	}
+	for _, rating := range s.ratings {
+		if rating.PostID != summary.PostID {
+			continue
+		}
+		switch rating.RatingType {
+		case domain.RatingTypeLike:
+			summary.Likes++
+		case domain.RatingTypeDislike:
+			summary.Dislikes++
+		}
+	}
+	return summary
+}
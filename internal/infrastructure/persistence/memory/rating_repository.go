@@ -5,16 +5,19 @@ import (
 	"sync"
 
 	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
 )
 
 type RatingRepository struct {
 	mu      sync.RWMutex
 	ratings map[domain.RatingID]domain.Rating
+	outbox  *dddmemory.InMemoryOutboxStore
 }
 
-func NewRatingRepository() *RatingRepository {
+func NewRatingRepository(outbox *dddmemory.InMemoryOutboxStore) *RatingRepository {
 	return &RatingRepository{
 		ratings: map[domain.RatingID]domain.Rating{},
+		outbox:  outbox,
 	}
 }
 
@@ -97,32 +100,52 @@ func (r *RatingRepository) Create(rating *domain.Rating) (*domain.Rating, error)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if err := r.outbox.Append(rating.GetID().String(), rating); err != nil {
+		return nil, err
+	}
 	r.ratings[rating.GetID()] = *rating
 
 	c := r.ratings[rating.GetID()]
 	return &c, nil
 }
 
-func (r *RatingRepository) ChangeRating(id domain.RatingID, newRatingType domain.RatingType) error {
+func (r *RatingRepository) ChangeRating(rating *domain.Rating) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	c := r.ratings[id]
-	c.ChangeRating(newRatingType)
-	r.ratings[id] = c
+	if err := r.outbox.Append(rating.GetID().String(), rating); err != nil {
+		return err
+	}
+	r.ratings[rating.GetID()] = *rating
 
 	return nil
 }
 
-func (r *RatingRepository) RemoveRating(id domain.RatingID) error {
+func (r *RatingRepository) RemoveRating(rating *domain.Rating) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.ratings[id]; !exists {
+	if _, exists := r.ratings[rating.GetID()]; !exists {
 		return errors.New("doesn't exist")
 	}
 
-	delete(r.ratings, id)
+	if err := r.outbox.Append(rating.GetID().String(), rating); err != nil {
+		return err
+	}
+	delete(r.ratings, rating.GetID())
+
+	return nil
+}
+
+func (r *RatingRepository) DeleteByUser(userID domain.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, rating := range r.ratings {
+		if rating.UserID() == userID {
+			delete(r.ratings, id)
+		}
+	}
 
 	return nil
 }
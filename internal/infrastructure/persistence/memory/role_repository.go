@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+type RoleRepository struct {
+	mu       sync.RWMutex
+	roles    map[domain.UserRole]domain.Role
+	userRepo *UserRepository
+	outbox   *dddmemory.InMemoryOutboxStore
+}
+
+// NewRoleRepository creates a role repository seeded with the built-in roles
+// The user repository is used to tell whether a role is still assigned
+func NewRoleRepository(
+	userRepo *UserRepository,
+	outbox *dddmemory.InMemoryOutboxStore,
+) *RoleRepository {
+	roles := map[domain.UserRole]domain.Role{}
+	for _, role := range domain.BuiltInRoles() {
+		roles[role.Name()] = *role
+	}
+
+	return &RoleRepository{
+		roles:    roles,
+		userRepo: userRepo,
+		outbox:   outbox,
+	}
+}
+
+func (r *RoleRepository) All() ([]domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := []domain.Role{}
+	for k := range r.roles {
+		roles = append(roles, r.roles[k])
+	}
+
+	return roles, nil
+}
+
+func (r *RoleRepository) FindByName(name domain.UserRole) (*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	role, exists := r.roles[name]
+	if !exists {
+		return nil, domain.ErrRoleNotFound
+	}
+
+	return &role, nil
+}
+
+func (r *RoleRepository) FindByNames(names []domain.UserRole) ([]domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := []domain.Role{}
+	for _, name := range names {
+		if role, exists := r.roles[name]; exists {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles, nil
+}
+
+func (r *RoleRepository) Exists(name domain.UserRole) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, exists := r.roles[name]
+	return exists, nil
+}
+
+func (r *RoleRepository) InUse(name domain.UserRole) (bool, error) {
+	users, err := r.userRepo.All()
+	if err != nil {
+		return false, err
+	}
+
+	for _, user := range users {
+		if slices.Contains(user.UserRoles(), name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *RoleRepository) Create(role *domain.Role) (*domain.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(role.Name().String(), role); err != nil {
+		return nil, err
+	}
+	r.roles[role.Name()] = *role
+
+	ro := r.roles[role.Name()]
+	return &ro, nil
+}
+
+func (r *RoleRepository) UpdatePermissions(role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(role.Name().String(), role); err != nil {
+		return err
+	}
+	r.roles[role.Name()] = *role
+
+	return nil
+}
+
+func (r *RoleRepository) Delete(role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(role.Name().String(), role); err != nil {
+		return err
+	}
+	delete(r.roles, role.Name())
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"time"
+
+	"blog/internal/domain"
+)
+
+type SessionRepository struct {
+	store *SessionStore
+}
+
+func NewSessionRepository(store *SessionStore) *SessionRepository {
+	return &SessionRepository{
+		store: store,
+	}
+}
+
+func (r *SessionRepository) FindByID(id domain.SessionID) (*domain.Session, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := time.Now()
+	for _, entry := range r.store.sessions {
+		if entry.session != nil && entry.session.ID() == id && entry.expiry.After(now) {
+			s := *entry.session
+			return &s, nil
+		}
+	}
+
+	return nil, domain.ErrSessionNotFound
+}
+
+func (r *SessionRepository) FindByToken(token string) (*domain.Session, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entry, exists := r.store.sessions[token]
+	if !exists || entry.session == nil || !entry.expiry.After(time.Now()) {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	s := *entry.session
+	return &s, nil
+}
+
+func (r *SessionRepository) FindByUser(userID domain.UserID) ([]domain.Session, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := time.Now()
+	sessions := []domain.Session{}
+	for _, entry := range r.store.sessions {
+		if entry.session != nil && entry.session.UserID() == userID && entry.expiry.After(now) {
+			sessions = append(sessions, *entry.session)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *SessionRepository) Create(token string, session *domain.Session) (*domain.Session, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entry := r.store.sessions[token]
+	if entry.expiry.IsZero() {
+		entry.expiry = session.ExpiresAt()
+	}
+	s := *session
+	entry.session = &s
+	r.store.sessions[token] = entry
+
+	return session, nil
+}
+
+func (r *SessionRepository) Revoke(id domain.SessionID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for token, entry := range r.store.sessions {
+		if entry.session != nil && entry.session.ID() == id {
+			delete(r.store.sessions, token)
+		}
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllForUser(userID domain.UserID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for token, entry := range r.store.sessions {
+		if entry.session != nil && entry.session.UserID() == userID {
+			delete(r.store.sessions, token)
+		}
+	}
+
+	return nil
+}
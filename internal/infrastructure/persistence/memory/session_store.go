@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"blog/internal/domain"
+)
+
+type sessionEntry struct {
+	data    []byte
+	expiry  time.Time
+	session *domain.Session
+}
+
+// SessionStore is an in-memory scs.Store that also keeps the per-session
+// metadata used by SessionRepository.
+type SessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]sessionEntry
+}
+
+func NewSessionStore() *SessionStore {
+	return &SessionStore{
+		sessions: map[string]sessionEntry{},
+	}
+}
+
+func (s *SessionStore) Find(token string) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.sessions[token]
+	if !exists || !entry.expiry.After(time.Now()) {
+		return nil, false, nil
+	}
+
+	return entry.data, true, nil
+}
+
+func (s *SessionStore) Commit(token string, b []byte, expiry time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.sessions[token]
+	entry.data = b
+	entry.expiry = expiry
+	s.sessions[token] = entry
+
+	return nil
+}
+
+func (s *SessionStore) Delete(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, token)
+
+	return nil
+}
+
+func (s *SessionStore) All() (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	sessions := map[string][]byte{}
+	for token, entry := range s.sessions {
+		if entry.expiry.After(now) {
+			sessions[token] = entry.data
+		}
+	}
+
+	return sessions, nil
+}
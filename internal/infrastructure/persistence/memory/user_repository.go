@@ -3,18 +3,23 @@ package memory
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"blog/internal/domain"
+	dddmemory "blog/pkg/ddd/memory"
 )
 
 type UserRepository struct {
-	mu    sync.RWMutex
-	users map[domain.UserID]domain.User
+	mu      sync.RWMutex
+	users   map[domain.UserID]domain.User
+	history []domain.IdentityChange
+	outbox  *dddmemory.InMemoryOutboxStore
 }
 
-func NewUserRepository() *UserRepository {
+func NewUserRepository(outbox *dddmemory.InMemoryOutboxStore) *UserRepository {
 	return &UserRepository{
-		users: map[domain.UserID]domain.User{},
+		users:  map[domain.UserID]domain.User{},
+		outbox: outbox,
 	}
 }
 
@@ -79,13 +84,7 @@ func (r *UserRepository) UsernameExists(username string) (bool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	for _, v := range r.users {
-		if v.Username() == username {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return r.usernameTaken(username, time.Now()), nil
 }
 
 func (r *UserRepository) EmailExists(email string) (bool, error) {
@@ -105,43 +104,213 @@ func (r *UserRepository) Create(user *domain.User) (*domain.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if err := r.outbox.Append(user.GetID().String(), user); err != nil {
+		return nil, err
+	}
 	r.users[user.GetID()] = *user
 
 	u := r.users[user.GetID()]
 	return &u, nil
 }
 
-func (r *UserRepository) UpdateRoles(id domain.UserID, roles []domain.UserRole) error {
+func (r *UserRepository) UpdateRoles(user *domain.User) error {
+	return r.update(user)
+}
+
+func (r *UserRepository) UpdateProfile(user *domain.User) error {
+	return r.update(user)
+}
+
+func (r *UserRepository) UpdateAvatar(user *domain.User) error {
+	return r.update(user)
+}
+
+func (r *UserRepository) UpdatePasswordHash(user *domain.User) error {
+	return r.update(user)
+}
+
+func (r *UserRepository) UpdateLockedUntil(user *domain.User) error {
+	return r.update(user)
+}
+
+func (r *UserRepository) UpdateStatus(user *domain.User) error {
+	return r.update(user)
+}
+
+func (r *UserRepository) FindExpiredSuspensions(at time.Time) ([]domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []domain.User{}
+	for _, v := range r.users {
+		if v.Status() == domain.UserStatusSuspended &&
+			v.SuspendedUntil() != nil &&
+			!v.SuspendedUntil().After(at) {
+			users = append(users, v)
+		}
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) UpdateDeletion(user *domain.User) error {
+	return r.update(user)
+}
+
+func (r *UserRepository) FindDueForAnonymisation(at time.Time) ([]domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []domain.User{}
+	for _, v := range r.users {
+		if v.AnonymisedAt() == nil && v.DeleteAfter() != nil && !v.DeleteAfter().After(at) {
+			users = append(users, v)
+		}
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) Anonymise(user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.outbox.Append(user.GetID().String(), user); err != nil {
+		return err
+	}
+	r.users[user.GetID()] = *user
+
+	history := []domain.IdentityChange{}
+	for _, change := range r.history {
+		if change.UserID != user.GetID() {
+			history = append(history, change)
+		}
+	}
+	r.history = history
+
+	return nil
+}
+
+func (r *UserRepository) UpdatePendingEmailChange(user *domain.User) error {
+	return r.update(user)
+}
+
+func (r *UserRepository) FindByEmailChangeToken(tokenHash string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.users {
+		if change := v.PendingEmailChange(); change != nil && change.TokenHash == tokenHash {
+			return &v, nil
+		}
+	}
+
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *UserRepository) ChangeEmail(user *domain.User, change domain.IdentityChange) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	u := r.users[id]
-	for _, r := range roles {
-		u.AddRole(r)
+	for k, v := range r.users {
+		if k != user.GetID() && v.Email() == change.NewValue {
+			return domain.ErrEmailTaken
+		}
 	}
-	r.users[id] = u
+
+	if err := r.outbox.Append(user.GetID().String(), user); err != nil {
+		return err
+	}
+	r.users[user.GetID()] = *user
+	r.history = append(r.history, change)
 
 	return nil
 }
 
-func (r *UserRepository) UpdateDescription(id domain.UserID, newDescription string) error {
+func (r *UserRepository) ChangeUsername(user *domain.User, change domain.IdentityChange) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	u := r.users[id]
-	u.UpdateDescription(newDescription)
-	r.users[id] = u
+	if r.usernameTaken(change.NewValue, change.ChangedAt) {
+		return domain.ErrUsernameTaken
+	}
+
+	if err := r.outbox.Append(user.GetID().String(), user); err != nil {
+		return err
+	}
+	r.users[user.GetID()] = *user
+	r.history = append(r.history, change)
 
 	return nil
 }
 
-func (r *UserRepository) UpdatePasswordHash(id domain.UserID, newPasswordHash string) error {
+func (r *UserRepository) FindIdentityHistory(id domain.UserID) ([]domain.IdentityChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changes := []domain.IdentityChange{}
+	for _, change := range r.history {
+		if change.UserID == id {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+func (r *UserRepository) FindByReservedUsername(
+	username string,
+	at time.Time,
+) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.history) - 1; i >= 0; i-- {
+		change := r.history[i]
+		if isReservation(change, username, at) {
+			u := r.users[change.UserID]
+			return &u, nil
+		}
+	}
+
+	return nil, domain.ErrUserNotFound
+}
+
+// usernameTaken reports whether a user has the username or it is still reserved at the
+// given time. The caller must hold the lock
+func (r *UserRepository) usernameTaken(username string, at time.Time) bool {
+	for _, v := range r.users {
+		if v.Username() == username {
+			return true
+		}
+	}
+
+	for _, change := range r.history {
+		if isReservation(change, username, at) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isReservation(change domain.IdentityChange, username string, at time.Time) bool {
+	return change.Kind == domain.IdentityKindUsername &&
+		change.OldValue == username &&
+		change.ReservedUntil != nil &&
+		change.ReservedUntil.After(at)
+}
+
+// update stores the user as it now is, like the sqlite repository's targeted updates,
+// adding its events to the outbox
+func (r *UserRepository) update(user *domain.User) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	u := r.users[id]
-	u.UpdatePasswordHash(newPasswordHash)
-	r.users[id] = u
+	if err := r.outbox.Append(user.GetID().String(), user); err != nil {
+		return err
+	}
+	r.users[user.GetID()] = *user
 
 	return nil
 }
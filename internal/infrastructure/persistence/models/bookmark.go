@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Bookmark struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	PostID    string     `db:"post_id"`
+	FolderID  *string    `db:"folder_id"`
+	Note      string     `db:"note"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+}
+
+type BookmarkFolder struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
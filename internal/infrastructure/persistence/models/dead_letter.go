@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type DeadLetter struct {
+	ID           int64      `db:"id"`
+	Subscription string     `db:"subscription"`
+	EventType    string     `db:"event_type"`
+	EventVersion int        `db:"event_version"`
+	Metadata     string     `db:"metadata"`
+	Payload      string     `db:"payload"`
+	OccurredAt   time.Time  `db:"occurred_at"`
+	Attempts     int        `db:"attempts"`
+	LastError    string     `db:"last_error"`
+	Status       string     `db:"status"`
+	FailedAt     time.Time  `db:"failed_at"`
+	ResolvedAt   *time.Time `db:"resolved_at"`
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+type DigestSubscription struct {
+	UserID     string     `db:"user_id"`
+	Frequency  string     `db:"frequency"`
+	LastSentAt *time.Time `db:"last_sent_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Event struct {
+	ID           int64     `db:"id"`
+	AggregateID  string    `db:"aggregate_id"`
+	Version      int       `db:"version"`
+	EventType    string    `db:"event_type"`
+	EventVersion int       `db:"event_version"`
+	Metadata     string    `db:"metadata"`
+	Payload      string    `db:"payload"`
+	OccurredAt   time.Time `db:"occurred_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type Follow struct {
+	ID         string    `db:"id"`
+	FollowerID string    `db:"follower_id"`
+	FolloweeID string    `db:"followee_id"`
+	CreatedAt  time.Time `db:"created_at"`
+}
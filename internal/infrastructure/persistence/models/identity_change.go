@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type IdentityChange struct {
+	ID            int64      `db:"id"`
+	UserID        string     `db:"user_id"`
+	Kind          string     `db:"kind"`
+	OldValue      string     `db:"old_value"`
+	NewValue      string     `db:"new_value"`
+	ChangedAt     time.Time  `db:"changed_at"`
+	ReservedUntil *time.Time `db:"reserved_until"`
+}
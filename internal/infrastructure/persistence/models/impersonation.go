@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Impersonation struct {
+	ID        string     `db:"id"`
+	AdminID   string     `db:"admin_id"`
+	UserID    string     `db:"user_id"`
+	Reason    string     `db:"reason"`
+	StartedAt time.Time  `db:"started_at"`
+	EndedAt   *time.Time `db:"ended_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type LoginAttempts struct {
+	Key           string    `db:"key"`
+	Failures      int       `db:"failures"`
+	LastFailureAt time.Time `db:"last_failure_at"`
+	BlockedUntil  time.Time `db:"blocked_until"`
+}
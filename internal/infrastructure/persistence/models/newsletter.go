@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type NewsletterSubscriber struct {
+	ID             string     `db:"id"`
+	Email          string     `db:"email"`
+	AuthorID       *string    `db:"author_id"`
+	Status         string     `db:"status"`
+	TokenHash      *string    `db:"token_hash"`
+	TokenExpiresAt *time.Time `db:"token_expires_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	ConfirmedAt    *time.Time `db:"confirmed_at"`
+	DisabledReason *string    `db:"disabled_reason"`
+}
+
+type NewsletterDelivery struct {
+	SubscriberID string     `db:"subscriber_id"`
+	PostID       string     `db:"post_id"`
+	Status       string     `db:"status"`
+	Attempts     int        `db:"attempts"`
+	LastError    string     `db:"last_error"`
+	QueuedAt     time.Time  `db:"queued_at"`
+	SentAt       *time.Time `db:"sent_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID          string     `db:"id"`
+	RecipientID string     `db:"recipient_id"`
+	Type        string     `db:"type"`
+	ActorID     string     `db:"actor_id"`
+	PostID      string     `db:"post_id"`
+	CommentID   *string    `db:"comment_id"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ReadAt      *time.Time `db:"read_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type OutboxMessage struct {
+	ID            int64      `db:"id"`
+	AggregateID   string     `db:"aggregate_id"`
+	EventType     string     `db:"event_type"`
+	EventVersion  int        `db:"event_version"`
+	Metadata      string     `db:"metadata"`
+	Payload       string     `db:"payload"`
+	OccurredAt    time.Time  `db:"occurred_at"`
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	LastError     string     `db:"last_error"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	PublishedAt   *time.Time `db:"published_at"`
+}
@@ -10,4 +10,5 @@ type Post struct {
 	CreatedAt    time.Time  `db:"created_at"`
 	LastEditedAt *time.Time `db:"last_edited_at"`
 	ArchivedAt   *time.Time `db:"archived_at"`
+	PopularAt    *time.Time `db:"popular_at"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type PostSummary struct {
+	PostID         string     `db:"post_id"`
+	AuthorID       string     `db:"author_id"`
+	AuthorUsername string     `db:"author_username"`
+	Title          string     `db:"title"`
+	CommentCount   int        `db:"comment_count"`
+	Likes          int        `db:"likes"`
+	Dislikes       int        `db:"dislikes"`
+	CreatedAt      time.Time  `db:"created_at"`
+	LastActivityAt time.Time  `db:"last_activity_at"`
+	ArchivedAt     *time.Time `db:"archived_at"`
+}
+
+type PostSummaryComment struct {
+	CommentID   string `db:"comment_id"`
+	PostID      string `db:"post_id"`
+	CommenterID string `db:"commenter_id"`
+	Archived    bool   `db:"archived"`
+}
+
+type PostSummaryRating struct {
+	RatingID   string `db:"rating_id"`
+	PostID     string `db:"post_id"`
+	UserID     string `db:"user_id"`
+	RatingType string `db:"rating_type"`
+}
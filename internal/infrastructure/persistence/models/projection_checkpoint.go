@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type ProjectionCheckpoint struct {
+	Projection string    `db:"projection"`
+	Position   int64     `db:"position"`
+	LastError  string    `db:"last_error"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
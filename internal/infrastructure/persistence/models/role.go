@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Role struct {
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	BuiltIn     bool      `db:"built_in"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type RolePermission struct {
+	RoleName   string `db:"role_name"`
+	Permission string `db:"permission"`
+}
+
+type UserRole struct {
+	UserID   string `db:"user_id"`
+	RoleName string `db:"role_name"`
+}
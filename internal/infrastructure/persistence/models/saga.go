@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Saga struct {
+	ID               string     `db:"id"`
+	SagaType         string     `db:"saga_type"`
+	CorrelationKey   string     `db:"correlation_key"`
+	Status           string     `db:"status"`
+	CompletedSteps   string     `db:"completed_steps"`
+	CompensatedSteps string     `db:"compensated_steps"`
+	Data             string     `db:"data"`
+	Metadata         string     `db:"metadata"`
+	Attempts         int        `db:"attempts"`
+	LastError        string     `db:"last_error"`
+	TimeoutAt        *time.Time `db:"timeout_at"`
+	StartedAt        time.Time  `db:"started_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+	Version          int        `db:"version"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type Session struct {
+	Token          string    `db:"token"`
+	Data           []byte    `db:"data"`
+	Expiry         time.Time `db:"expiry"`
+	ID             *string   `db:"id"`
+	UserID         *string   `db:"user_id"`
+	UserAgent      string    `db:"user_agent"`
+	Device         string    `db:"device"`
+	IPAddress      string    `db:"ip_address"`
+	CreatedAt      time.Time `db:"created_at"`
+	LastSeenAt     time.Time `db:"last_seen_at"`
+	ImpersonatorID string    `db:"impersonator_id"`
+}
@@ -3,11 +3,25 @@ package models
 import "time"
 
 type User struct {
-	ID           string    `db:"id"`
-	Email        string    `db:"email"`
-	PasswordHash string    `db:"password_hash"`
-	Username     string    `db:"username"`
-	Description  string    `db:"description"`
-	UserRoles    string    `db:"user_roles"`
-	JoinDate     time.Time `db:"join_date"`
+	ID                   string     `db:"id"`
+	Email                string     `db:"email"`
+	PasswordHash         string     `db:"password_hash"`
+	Username             string     `db:"username"`
+	DisplayName          string     `db:"display_name"`
+	Description          string     `db:"description"`
+	Location             string     `db:"location"`
+	Links                string     `db:"links"`
+	AvatarVersion        string     `db:"avatar_version"`
+	JoinDate             time.Time  `db:"join_date"`
+	LockedUntil          *time.Time `db:"locked_until"`
+	Status               string     `db:"status"`
+	StatusReason         string     `db:"status_reason"`
+	SuspendedUntil       *time.Time `db:"suspended_until"`
+	DeletionRequestedAt  *time.Time `db:"deletion_requested_at"`
+	DeleteAfter          *time.Time `db:"delete_after"`
+	AnonymisedAt         *time.Time `db:"anonymised_at"`
+	PendingEmail         string     `db:"pending_email"`
+	EmailChangeTokenHash string     `db:"email_change_token_hash"`
+	EmailChangeExpiresAt *time.Time `db:"email_change_expires_at"`
+	UsernameChangedAt    *time.Time `db:"username_changed_at"`
 }
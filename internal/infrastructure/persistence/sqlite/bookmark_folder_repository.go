@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type BookmarkFolderRepository struct {
+	db *sqlx.DB
+}
+
+func NewBookmarkFolderRepository(db *sqlx.DB) *BookmarkFolderRepository {
+	return &BookmarkFolderRepository{
+		db: db,
+	}
+}
+
+func (r BookmarkFolderRepository) FindByID(
+	id domain.BookmarkFolderID,
+) (*domain.BookmarkFolder, error) {
+	var dbFolder models.BookmarkFolder
+	err := r.db.Get(&dbFolder, "SELECT * FROM bookmark_folders WHERE id=?", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrBookmarkFolderNotFound
+		}
+		return nil, err
+	}
+
+	folder := dbBookmarkFolderToDomainBookmarkFolder(dbFolder)
+	return folder, nil
+}
+
+func (r BookmarkFolderRepository) FindByUser(userID domain.UserID) ([]domain.BookmarkFolder, error) {
+	var dbFolders []models.BookmarkFolder
+	err := r.db.Select(
+		&dbFolders,
+		"SELECT * FROM bookmark_folders WHERE user_id=? ORDER BY name",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := []domain.BookmarkFolder{}
+	for _, folder := range dbFolders {
+		folders = append(folders, *dbBookmarkFolderToDomainBookmarkFolder(folder))
+	}
+	return folders, nil
+}
+
+func (r BookmarkFolderRepository) Create(
+	folder *domain.BookmarkFolder,
+) (*domain.BookmarkFolder, error) {
+	err := withEvents(r.db, folder.GetID().String(), folder, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO
+			bookmark_folders (id, user_id, name, created_at)
+			VALUES (?, ?, ?, ?)
+		`,
+			folder.GetID().String(),
+			folder.UserID().String(),
+			folder.Name(),
+			folder.CreatedAt().UTC(),
+		)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrBookmarkFolderNameTaken
+		}
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+func (r BookmarkFolderRepository) Rename(folder *domain.BookmarkFolder) error {
+	err := withEvents(r.db, folder.GetID().String(), folder, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE bookmark_folders
+			SET name = ?
+			WHERE id = ?
+		`,
+			folder.Name(),
+			folder.GetID().String(),
+		)
+		return err
+	})
+	if isUniqueViolation(err) {
+		return domain.ErrBookmarkFolderNameTaken
+	}
+	return err
+}
+
+func (r BookmarkFolderRepository) Delete(folder *domain.BookmarkFolder) error {
+	return withEvents(r.db, folder.GetID().String(), folder, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM bookmark_folders
+			WHERE id = ?
+		`,
+			folder.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r BookmarkFolderRepository) DeleteByUser(userID domain.UserID) error {
+	_, err := r.db.Exec(`
+		DELETE FROM bookmark_folders
+		WHERE user_id = ?
+	`,
+		userID.String(),
+	)
+	return err
+}
+
+func dbBookmarkFolderToDomainBookmarkFolder(dbFolder models.BookmarkFolder) *domain.BookmarkFolder {
+	return domain.RebuildBookmarkFolder(
+		domain.NewBookmarkFolderID(dbFolder.ID),
+		domain.NewUserID(dbFolder.UserID),
+		dbFolder.Name,
+		dbFolder.CreatedAt,
+	)
+}
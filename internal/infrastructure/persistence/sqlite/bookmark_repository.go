@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type BookmarkRepository struct {
+	db *sqlx.DB
+}
+
+func NewBookmarkRepository(db *sqlx.DB) *BookmarkRepository {
+	return &BookmarkRepository{
+		db: db,
+	}
+}
+
+func (r BookmarkRepository) All() ([]domain.Bookmark, error) {
+	var dbBookmarks []models.Bookmark
+	err := r.db.Select(&dbBookmarks, "SELECT * FROM bookmarks")
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks := dbBookmarksToDomainBookmarks(dbBookmarks)
+	return bookmarks, nil
+}
+
+func (r BookmarkRepository) FindByID(id domain.BookmarkID) (*domain.Bookmark, error) {
+	var dbBookmark models.Bookmark
+	err := r.db.Get(&dbBookmark, "SELECT * FROM bookmarks WHERE id=?", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrBookmarkNotFound
+		}
+		return nil, err
+	}
+
+	bookmark := dbBookmarkToDomainBookmark(dbBookmark)
+	return bookmark, nil
+}
+
+func (r BookmarkRepository) FindByUser(userID domain.UserID) ([]domain.Bookmark, error) {
+	var dbBookmarks []models.Bookmark
+	err := r.db.Select(
+		&dbBookmarks,
+		"SELECT * FROM bookmarks WHERE user_id=? ORDER BY created_at DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks := dbBookmarksToDomainBookmarks(dbBookmarks)
+	return bookmarks, nil
+}
+
+func (r BookmarkRepository) FindByFolder(folderID domain.BookmarkFolderID) ([]domain.Bookmark, error) {
+	var dbBookmarks []models.Bookmark
+	err := r.db.Select(
+		&dbBookmarks,
+		"SELECT * FROM bookmarks WHERE folder_id=? ORDER BY created_at DESC",
+		folderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks := dbBookmarksToDomainBookmarks(dbBookmarks)
+	return bookmarks, nil
+}
+
+func (r BookmarkRepository) Exists(id domain.BookmarkID) (bool, error) {
+	var count int
+	err := r.db.Get(&count, "SELECT COUNT(*) FROM bookmarks WHERE id=?", id)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r BookmarkRepository) ExistsOnPostByUser(
+	postID domain.PostID,
+	userID domain.UserID,
+) (bool, error) {
+	var count int
+	err := r.db.Get(
+		&count,
+		"SELECT COUNT(*) FROM bookmarks WHERE post_id=? AND user_id=?",
+		postID,
+		userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r BookmarkRepository) Create(bookmark *domain.Bookmark) (*domain.Bookmark, error) {
+	err := withEvents(r.db, bookmark.GetID().String(), bookmark, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO
+			bookmarks (id, user_id, post_id, folder_id, note, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`,
+			bookmark.GetID().String(),
+			bookmark.UserID().String(),
+			bookmark.PostID().String(),
+			nullableFolderID(bookmark.FolderID()),
+			bookmark.Note(),
+			bookmark.CreatedAt().UTC(),
+		)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrAlreadyBookmarked
+		}
+		return nil, err
+	}
+
+	return bookmark, nil
+}
+
+func (r BookmarkRepository) UpdateNote(bookmark *domain.Bookmark) error {
+	return withEvents(r.db, bookmark.GetID().String(), bookmark, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE bookmarks
+			SET note = ?, updated_at = ?
+			WHERE id = ?
+		`,
+			bookmark.Note(),
+			time.Now().UTC(),
+			bookmark.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r BookmarkRepository) UpdateFolder(bookmark *domain.Bookmark) error {
+	return withEvents(r.db, bookmark.GetID().String(), bookmark, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE bookmarks
+			SET folder_id = ?, updated_at = ?
+			WHERE id = ?
+		`,
+			nullableFolderID(bookmark.FolderID()),
+			time.Now().UTC(),
+			bookmark.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r BookmarkRepository) RemoveBookmark(bookmark *domain.Bookmark) error {
+	return withEvents(r.db, bookmark.GetID().String(), bookmark, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM bookmarks
+			WHERE id = ?
+		`,
+			bookmark.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r BookmarkRepository) DeleteByUser(userID domain.UserID) error {
+	_, err := r.db.Exec(`
+		DELETE FROM bookmarks
+		WHERE user_id = ?
+	`,
+		userID.String(),
+	)
+	return err
+}
+
+// nullableFolderID stores unfiled bookmarks with a NULL folder, so the foreign key
+// only applies to filed ones
+func nullableFolderID(folderID domain.BookmarkFolderID) *string {
+	if folderID == "" {
+		return nil
+	}
+	id := folderID.String()
+	return &id
+}
+
+func dbBookmarkToDomainBookmark(dbBookmark models.Bookmark) *domain.Bookmark {
+	folderID := domain.BookmarkFolderID("")
+	if dbBookmark.FolderID != nil {
+		folderID = domain.NewBookmarkFolderID(*dbBookmark.FolderID)
+	}
+
+	return domain.RebuildBookmark(
+		domain.NewBookmarkID(dbBookmark.ID),
+		domain.NewUserID(dbBookmark.UserID),
+		domain.NewPostID(dbBookmark.PostID),
+		folderID,
+		dbBookmark.Note,
+		dbBookmark.CreatedAt,
+		dbBookmark.UpdatedAt,
+	)
+}
+
+func dbBookmarksToDomainBookmarks(dbBookmarks []models.Bookmark) []domain.Bookmark {
+	bookmarks := []domain.Bookmark{}
+	for _, bookmark := range dbBookmarks {
+		bookmarks = append(bookmarks, *dbBookmarkToDomainBookmark(bookmark))
+	}
+	return bookmarks
+}
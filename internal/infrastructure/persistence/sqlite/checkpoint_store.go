@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"blog/internal/infrastructure/persistence/models"
+	"blog/pkg/ddd"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CheckpointStore struct {
+	db *sqlx.DB
+}
+
+func NewCheckpointStore(db *sqlx.DB) *CheckpointStore {
+	return &CheckpointStore{
+		db: db,
+	}
+}
+
+func (s CheckpointStore) Find(projection string) (ddd.ProjectionCheckpoint, error) {
+	var dbCheckpoint models.ProjectionCheckpoint
+	err := s.db.Get(
+		&dbCheckpoint,
+		"SELECT * FROM projection_checkpoints WHERE projection=?",
+		projection,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ddd.ProjectionCheckpoint{Projection: projection}, nil
+		}
+		return ddd.ProjectionCheckpoint{}, err
+	}
+
+	return ddd.ProjectionCheckpoint{
+		Projection: dbCheckpoint.Projection,
+		Position:   dbCheckpoint.Position,
+		LastError:  dbCheckpoint.LastError,
+		UpdatedAt:  dbCheckpoint.UpdatedAt,
+	}, nil
+}
+
+func (s CheckpointStore) Save(checkpoint ddd.ProjectionCheckpoint) error {
+	_, err := s.db.Exec(`
+		INSERT INTO projection_checkpoints (projection, position, last_error, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(projection) DO UPDATE
+		SET position = excluded.position,
+			last_error = excluded.last_error,
+			updated_at = excluded.updated_at
+	`,
+		checkpoint.Projection,
+		checkpoint.Position,
+		checkpoint.LastError,
+		checkpoint.UpdatedAt.UTC(),
+	)
+	return err
+}
@@ -1,23 +1,24 @@
 package sqlite
 
 import (
-	"database/sql"
-	"errors"
-	"time"
-
 	"blog/internal/domain"
 	"blog/internal/infrastructure/persistence/models"
+	"blog/pkg/ddd"
 
 	"github.com/jmoiron/sqlx"
 )
 
+// CommentRepository loads comments from their event streams. The comments table is kept
+// up to date in the same transaction as each append, and answers the queries
 type CommentRepository struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	events *EventStore
 }
 
 func NewCommentRepository(db *sqlx.DB) *CommentRepository {
 	return &CommentRepository{
-		db: db,
+		db:     db,
+		events: NewEventStore(db),
 	}
 }
 
@@ -33,17 +34,12 @@ func (r CommentRepository) All() ([]domain.Comment, error) {
 }
 
 func (r CommentRepository) FindByID(id domain.CommentID) (*domain.Comment, error) {
-	var dbComment models.Comment
-	err := r.db.Get(&dbComment, "SELECT * FROM comments WHERE id=?", id)
+	history, err := r.events.Load(id.String())
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, domain.ErrCommentNotFound
-		}
 		return nil, err
 	}
 
-	comment := dbCommentToDomainComment(dbComment)
-	return comment, nil
+	return domain.LoadComment(history)
 }
 
 func (r *CommentRepository) FindByUser(userID domain.UserID) ([]domain.Comment, error) {
@@ -78,19 +74,22 @@ func (r *CommentRepository) Exists(id domain.CommentID) (bool, error) {
 }
 
 func (r *CommentRepository) Create(comment *domain.Comment) (*domain.Comment, error) {
-	_, err := r.db.Exec(`
-		INSERT INTO 
-		comments (id, post_id, commenter_id, content, created_at, last_updated_at, archived_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`,
-		comment.GetID().String(),
-		comment.PostID().String(),
-		comment.CommenterID().String(),
-		comment.Content(),
-		comment.CreatedAt(),
-		comment.LastUpdatedAt(),
-		comment.ArchivedAt(),
-	)
+	err := r.save(comment, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO 
+			comments (id, post_id, commenter_id, content, created_at, last_updated_at, archived_at) 
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`,
+			comment.GetID().String(),
+			comment.PostID().String(),
+			comment.CommenterID().String(),
+			comment.Content(),
+			comment.CreatedAt(),
+			comment.LastUpdatedAt(),
+			comment.ArchivedAt(),
+		)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -98,29 +97,89 @@ func (r *CommentRepository) Create(comment *domain.Comment) (*domain.Comment, er
 	return comment, nil
 }
 
-func (r *CommentRepository) UpdateContent(id domain.CommentID, newContent string) error {
-	_, err := r.db.Exec(`
-		UPDATE comments
-		SET content = ?, last_updated_at = ?
-		WHERE id = ?
+func (r *CommentRepository) UpdateContent(comment *domain.Comment) error {
+	return r.save(comment, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE comments
+			SET content = ?, last_updated_at = ?
+			WHERE id = ?
+		`,
+			comment.Content(),
+			comment.LastUpdatedAt(),
+			comment.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r *CommentRepository) Archive(comment *domain.Comment) error {
+	return r.save(comment, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE comments
+			SET archived_at = ?
+			WHERE id = ?
+		`,
+			comment.ArchivedAt(),
+			comment.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r *CommentRepository) Restore(comment *domain.Comment) error {
+	return r.save(comment, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE comments
+			SET archived_at = NULL
+			WHERE id = ?
+		`,
+			comment.GetID().String(),
+		)
+		return err
+	})
+}
+
+// DeleteByUser removes the user's comments along with their streams
+func (r *CommentRepository) DeleteByUser(userID domain.UserID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM events
+		WHERE aggregate_id IN (SELECT id FROM comments WHERE commenter_id = ?)
 	`,
-		newContent,
-		time.Now(),
-		id.String(),
+		userID.String(),
 	)
-	return err
-}
+	if err != nil {
+		return err
+	}
 
-func (r *CommentRepository) Archive(id domain.CommentID) error {
-	_, err := r.db.Exec(`
-		UPDATE comments
-		SET archived_at = ?
-		WHERE id = ?
+	_, err = tx.Exec(`
+		DELETE FROM comments
+		WHERE commenter_id = ?
 	`,
-		time.Now(),
-		id.String(),
+		userID.String(),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// save appends the comment's new events to its stream and applies the change to the
+// comments table, in one transaction along with the outbox
+func (r *CommentRepository) save(comment *domain.Comment, fn func(tx *sqlx.Tx) error) error {
+	id := comment.GetID().String()
+	return withEnvelopes(r.db, id, comment, func(tx *sqlx.Tx, envelopes []ddd.EventEnvelope) error {
+		if err := appendToStream(tx, id, comment.Version(), envelopes); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
 }
 
 func dbCommentToDomainComment(dbComment models.Comment) *domain.Comment {
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 type DB struct {
@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// newTestDB opens a database in a temporary directory with every migration applied
+func newTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	db, err := sqlx.Connect("sqlite3", "file:"+filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatalf("Connect() failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := filepath.Glob(filepath.Join("migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatalf("Glob() failed: %v", err)
+	}
+	for _, migration := range migrations {
+		query, err := os.ReadFile(migration)
+		if err != nil {
+			t.Fatalf("ReadFile() failed: %v", err)
+		}
+		if _, err := db.Exec(string(query)); err != nil {
+			t.Fatalf("applying %s failed: %v", migration, err)
+		}
+	}
+
+	return db
+}
@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"blog/internal/infrastructure/persistence/models"
+	"blog/pkg/ddd"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DeadLetterStore struct {
+	db *sqlx.DB
+}
+
+func NewDeadLetterStore(db *sqlx.DB) *DeadLetterStore {
+	return &DeadLetterStore{
+		db: db,
+	}
+}
+
+func (s DeadLetterStore) Add(letter ddd.DeadLetter) (ddd.DeadLetter, error) {
+	metadata, err := encodeEventMetadata(letter.Metadata)
+	if err != nil {
+		return ddd.DeadLetter{}, err
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO dead_letters (
+			subscription, event_type, event_version, metadata, payload, occurred_at,
+			attempts, last_error, status, failed_at, resolved_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		letter.Subscription,
+		letter.EventType,
+		letter.EventVersion,
+		metadata,
+		string(letter.Payload),
+		letter.OccurredOn.UTC(),
+		letter.Attempts,
+		letter.LastError,
+		letter.Status.String(),
+		letter.FailedAt.UTC(),
+		utcOrNil(letter.ResolvedAt),
+	)
+	if err != nil {
+		return ddd.DeadLetter{}, err
+	}
+
+	letter.ID, err = result.LastInsertId()
+	if err != nil {
+		return ddd.DeadLetter{}, err
+	}
+	return letter, nil
+}
+
+func (s DeadLetterStore) FindByID(id int64) (ddd.DeadLetter, error) {
+	var dbLetter models.DeadLetter
+	err := s.db.Get(&dbLetter, "SELECT * FROM dead_letters WHERE id=?", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ddd.DeadLetter{}, ddd.ErrDeadLetterNotFound
+		}
+		return ddd.DeadLetter{}, err
+	}
+
+	return dbDeadLetterToDeadLetter(dbLetter), nil
+}
+
+func (s DeadLetterStore) List(status ddd.DeadLetterStatus) ([]ddd.DeadLetter, error) {
+	var dbLetters []models.DeadLetter
+	err := s.db.Select(&dbLetters, `
+		SELECT * FROM dead_letters
+		WHERE ? = '' OR status = ?
+		ORDER BY failed_at DESC, id DESC
+	`,
+		status.String(),
+		status.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := []ddd.DeadLetter{}
+	for _, dbLetter := range dbLetters {
+		letters = append(letters, dbDeadLetterToDeadLetter(dbLetter))
+	}
+	return letters, nil
+}
+
+func (s DeadLetterStore) Update(letter ddd.DeadLetter) error {
+	result, err := s.db.Exec(`
+		UPDATE dead_letters
+		SET attempts = ?, last_error = ?, status = ?, failed_at = ?, resolved_at = ?
+		WHERE id = ?
+	`,
+		letter.Attempts,
+		letter.LastError,
+		letter.Status.String(),
+		letter.FailedAt.UTC(),
+		utcOrNil(letter.ResolvedAt),
+		letter.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ddd.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+func (s DeadLetterStore) CountByStatus() (map[ddd.DeadLetterStatus]int, error) {
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	err := s.db.Select(&rows, "SELECT status, COUNT(*) AS count FROM dead_letters GROUP BY status")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[ddd.DeadLetterStatus]int{}
+	for _, row := range rows {
+		counts[ddd.DeadLetterStatus(row.Status)] = row.Count
+	}
+	return counts, nil
+}
+
+func dbDeadLetterToDeadLetter(dbLetter models.DeadLetter) ddd.DeadLetter {
+	return ddd.DeadLetter{
+		ID:           dbLetter.ID,
+		Subscription: dbLetter.Subscription,
+		EventType:    dbLetter.EventType,
+		EventVersion: dbLetter.EventVersion,
+		Metadata:     decodeEventMetadata(dbLetter.Metadata),
+		Payload:      []byte(dbLetter.Payload),
+		OccurredOn:   dbLetter.OccurredAt,
+		Attempts:     dbLetter.Attempts,
+		LastError:    dbLetter.LastError,
+		Status:       ddd.DeadLetterStatus(dbLetter.Status),
+		FailedAt:     dbLetter.FailedAt,
+		ResolvedAt:   dbLetter.ResolvedAt,
+	}
+}
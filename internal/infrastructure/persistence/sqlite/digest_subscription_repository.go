@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DigestSubscriptionRepository struct {
+	db *sqlx.DB
+}
+
+func NewDigestSubscriptionRepository(db *sqlx.DB) *DigestSubscriptionRepository {
+	return &DigestSubscriptionRepository{
+		db: db,
+	}
+}
+
+func (r DigestSubscriptionRepository) Find(
+	userID domain.UserID,
+) (*domain.DigestSubscription, error) {
+	var dbSubscription models.DigestSubscription
+	err := r.db.Get(
+		&dbSubscription,
+		"SELECT * FROM digest_subscriptions WHERE user_id=?",
+		userID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.NewDigestSubscription(userID), nil
+		}
+		return nil, err
+	}
+
+	subscription := dbSubscriptionToDomainSubscription(dbSubscription)
+	return subscription, nil
+}
+
+func (r DigestSubscriptionRepository) FindSubscribed() ([]domain.DigestSubscription, error) {
+	var dbSubscriptions []models.DigestSubscription
+	err := r.db.Select(
+		&dbSubscriptions,
+		"SELECT * FROM digest_subscriptions WHERE frequency<>?",
+		domain.DigestFrequencyOff.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := []domain.DigestSubscription{}
+	for _, dbSubscription := range dbSubscriptions {
+		subscriptions = append(
+			subscriptions,
+			*dbSubscriptionToDomainSubscription(dbSubscription),
+		)
+	}
+
+	return subscriptions, nil
+}
+
+func (r DigestSubscriptionRepository) Save(subscription *domain.DigestSubscription) error {
+	var lastSentAt *time.Time
+	if subscription.LastSentAt() != nil {
+		utc := subscription.LastSentAt().UTC()
+		lastSentAt = &utc
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO digest_subscriptions (user_id, frequency, last_sent_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE
+		SET frequency = excluded.frequency,
+			last_sent_at = excluded.last_sent_at
+	`,
+		subscription.UserID().String(),
+		subscription.Frequency().String(),
+		lastSentAt,
+	)
+	return err
+}
+
+func (r DigestSubscriptionRepository) DeleteByUser(userID domain.UserID) error {
+	_, err := r.db.Exec("DELETE FROM digest_subscriptions WHERE user_id=?", userID.String())
+	return err
+}
+
+func dbSubscriptionToDomainSubscription(
+	dbSubscription models.DigestSubscription,
+) *domain.DigestSubscription {
+	return domain.RebuildDigestSubscription(
+		domain.NewUserID(dbSubscription.UserID),
+		domain.DigestFrequency(dbSubscription.Frequency),
+		dbSubscription.LastSentAt,
+	)
+}
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"blog/internal/infrastructure/persistence/models"
+	"blog/pkg/ddd"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type EventStore struct {
+	db *sqlx.DB
+}
+
+func NewEventStore(db *sqlx.DB) *EventStore {
+	return &EventStore{
+		db: db,
+	}
+}
+
+func (s EventStore) Append(aggregateID string, expectedVersion int, events []ddd.DomainEvent) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	envelopes, err := ddd.EventRegistry.EncodeEvents(aggregateID, ddd.EventMetadata{}, events)
+	if err != nil {
+		return err
+	}
+
+	if err := appendToStream(tx, aggregateID, expectedVersion, envelopes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s EventStore) Load(aggregateID string) ([]ddd.DomainEvent, error) {
+	var dbEvents []models.Event
+	err := s.db.Select(&dbEvents, `
+		SELECT * FROM events
+		WHERE aggregate_id = ?
+		ORDER BY version
+	`,
+		aggregateID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []ddd.DomainEvent{}
+	for _, dbEvent := range dbEvents {
+		event, err := ddd.EventRegistry.Decode(ddd.EventEnvelope{
+			Type:        dbEvent.EventType,
+			Version:     dbEvent.EventVersion,
+			OccurredOn:  dbEvent.OccurredAt,
+			AggregateID: dbEvent.AggregateID,
+			Metadata:    decodeEventMetadata(dbEvent.Metadata),
+			Payload:     []byte(dbEvent.Payload),
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// appendToStream adds the encoded events to the aggregate's stream within the
+// transaction. Two writers that both read the same version are stopped by the unique
+// version, so only one of them commits
+func appendToStream(
+	tx *sqlx.Tx,
+	aggregateID string,
+	expectedVersion int,
+	envelopes []ddd.EventEnvelope,
+) error {
+	var version int
+	err := tx.Get(&version, "SELECT COALESCE(MAX(version), 0) FROM events WHERE aggregate_id = ?", aggregateID)
+	if err != nil {
+		return err
+	}
+	if version != expectedVersion {
+		return ddd.NewConcurrencyConflictError(aggregateID, expectedVersion, version)
+	}
+
+	for i, envelope := range envelopes {
+		metadata, err := encodeEventMetadata(envelope.Metadata)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO events (aggregate_id, version, event_type, event_version, metadata, payload, occurred_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`,
+			envelope.AggregateID,
+			expectedVersion+i+1,
+			envelope.Type,
+			envelope.Version,
+			metadata,
+			string(envelope.Payload),
+			envelope.OccurredOn.UTC(),
+		)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("%w: stream %s was appended to concurrently", ddd.ErrConcurrencyConflict, aggregateID)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
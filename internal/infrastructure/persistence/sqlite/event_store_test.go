@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+	dddtesting "blog/pkg/ddd/testing"
+)
+
+func TestEventStore(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	dddtesting.RunEventStoreTests(t,
+		func(t *testing.T) ddd.EventStore { return NewEventStore(newTestDB(t)) },
+		domain.NewCommentCreatedEvent("comment", "post", "bob", "Nice post", createdAt, nil, nil),
+		domain.NewCommentEditedEvent("comment", "Edited", createdAt.Add(time.Minute)),
+		domain.NewCommentArchivedEvent("comment", createdAt.Add(time.Hour)),
+	)
+}
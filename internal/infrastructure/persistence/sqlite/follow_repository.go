@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type FollowRepository struct {
+	db *sqlx.DB
+}
+
+func NewFollowRepository(db *sqlx.DB) *FollowRepository {
+	return &FollowRepository{
+		db: db,
+	}
+}
+
+func (r FollowRepository) Find(followerID, followeeID domain.UserID) (*domain.Follow, error) {
+	var dbFollow models.Follow
+	err := r.db.Get(
+		&dbFollow,
+		"SELECT * FROM follows WHERE follower_id=? AND followee_id=?",
+		followerID,
+		followeeID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFollowNotFound
+		}
+		return nil, err
+	}
+
+	follow := dbFollowToDomainFollow(dbFollow)
+	return follow, nil
+}
+
+func (r FollowRepository) FindFollowers(userID domain.UserID) ([]domain.Follow, error) {
+	var dbFollows []models.Follow
+	err := r.db.Select(
+		&dbFollows,
+		"SELECT * FROM follows WHERE followee_id=? ORDER BY created_at DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	follows := dbFollowsToDomainFollows(dbFollows)
+	return follows, nil
+}
+
+func (r FollowRepository) FindFollowing(userID domain.UserID) ([]domain.Follow, error) {
+	var dbFollows []models.Follow
+	err := r.db.Select(
+		&dbFollows,
+		"SELECT * FROM follows WHERE follower_id=? ORDER BY created_at DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	follows := dbFollowsToDomainFollows(dbFollows)
+	return follows, nil
+}
+
+func (r FollowRepository) CountFollowers(userID domain.UserID) (int, error) {
+	var count int
+	err := r.db.Get(&count, "SELECT COUNT(*) FROM follows WHERE followee_id=?", userID)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r FollowRepository) CountFollowing(userID domain.UserID) (int, error) {
+	var count int
+	err := r.db.Get(&count, "SELECT COUNT(*) FROM follows WHERE follower_id=?", userID)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r FollowRepository) Create(follow *domain.Follow) (*domain.Follow, error) {
+	err := withEvents(r.db, follow.GetID().String(), follow, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO
+			follows (id, follower_id, followee_id, created_at)
+			VALUES (?, ?, ?, ?)
+		`,
+			follow.GetID().String(),
+			follow.FollowerID().String(),
+			follow.FolloweeID().String(),
+			follow.CreatedAt().UTC(),
+		)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrAlreadyFollowing
+		}
+		return nil, err
+	}
+
+	return follow, nil
+}
+
+func (r FollowRepository) Delete(follow *domain.Follow) error {
+	return withEvents(r.db, follow.GetID().String(), follow, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("DELETE FROM follows WHERE id=?", follow.GetID().String())
+		return err
+	})
+}
+
+func (r FollowRepository) DeleteByUser(userID domain.UserID) error {
+	_, err := r.db.Exec(
+		"DELETE FROM follows WHERE follower_id=? OR followee_id=?",
+		userID.String(),
+		userID.String(),
+	)
+	return err
+}
+
+func dbFollowToDomainFollow(dbFollow models.Follow) *domain.Follow {
+	return domain.RebuildFollow(
+		domain.NewFollowID(dbFollow.ID),
+		domain.NewUserID(dbFollow.FollowerID),
+		domain.NewUserID(dbFollow.FolloweeID),
+		dbFollow.CreatedAt,
+	)
+}
+
+func dbFollowsToDomainFollows(dbFollows []models.Follow) []domain.Follow {
+	follows := []domain.Follow{}
+	for _, follow := range dbFollows {
+		follows = append(follows, *dbFollowToDomainFollow(follow))
+	}
+	return follows
+}
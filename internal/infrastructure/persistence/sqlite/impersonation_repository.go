@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ImpersonationRepository struct {
+	db *sqlx.DB
+}
+
+func NewImpersonationRepository(db *sqlx.DB) *ImpersonationRepository {
+	return &ImpersonationRepository{
+		db: db,
+	}
+}
+
+func (r ImpersonationRepository) All() ([]domain.Impersonation, error) {
+	var dbImpersonations []models.Impersonation
+	err := r.db.Select(&dbImpersonations, "SELECT * FROM impersonations ORDER BY started_at DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	impersonations := dbImpersonationsToDomainImpersonations(dbImpersonations)
+	return impersonations, nil
+}
+
+func (r ImpersonationRepository) FindByID(id domain.ImpersonationID) (*domain.Impersonation, error) {
+	var dbImpersonation models.Impersonation
+	err := r.db.Get(&dbImpersonation, "SELECT * FROM impersonations WHERE id=?", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrImpersonationNotFound
+		}
+		return nil, err
+	}
+
+	impersonation := dbImpersonationToDomainImpersonation(dbImpersonation)
+	return impersonation, nil
+}
+
+func (r ImpersonationRepository) FindByUser(userID domain.UserID) ([]domain.Impersonation, error) {
+	var dbImpersonations []models.Impersonation
+	err := r.db.Select(
+		&dbImpersonations,
+		"SELECT * FROM impersonations WHERE user_id=? ORDER BY started_at DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonations := dbImpersonationsToDomainImpersonations(dbImpersonations)
+	return impersonations, nil
+}
+
+func (r ImpersonationRepository) Create(
+	impersonation *domain.Impersonation,
+) (*domain.Impersonation, error) {
+	err := withEvents(r.db, impersonation.GetID().String(), impersonation, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO
+			impersonations (id, admin_id, user_id, reason, started_at, ended_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`,
+			impersonation.GetID().String(),
+			impersonation.AdminID().String(),
+			impersonation.UserID().String(),
+			impersonation.Reason(),
+			impersonation.StartedAt().UTC(),
+			nil,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return impersonation, nil
+}
+
+// End records when the impersonation stopped. It only ever ends once, so an
+// impersonation that has already ended is reported as such
+func (r ImpersonationRepository) End(impersonation *domain.Impersonation) error {
+	return withEvents(r.db, impersonation.GetID().String(), impersonation, func(tx *sqlx.Tx) error {
+		result, err := tx.Exec(
+			"UPDATE impersonations SET ended_at=? WHERE id=? AND ended_at IS NULL",
+			impersonation.EndedAt().UTC(),
+			impersonation.GetID().String(),
+		)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return domain.ErrImpersonationEnded
+		}
+
+		return nil
+	})
+}
+
+func dbImpersonationToDomainImpersonation(
+	dbImpersonation models.Impersonation,
+) *domain.Impersonation {
+	return domain.RebuildImpersonation(
+		domain.NewImpersonationID(dbImpersonation.ID),
+		domain.NewUserID(dbImpersonation.AdminID),
+		domain.NewUserID(dbImpersonation.UserID),
+		dbImpersonation.Reason,
+		dbImpersonation.StartedAt,
+		dbImpersonation.EndedAt,
+	)
+}
+
+func dbImpersonationsToDomainImpersonations(
+	dbImpersonations []models.Impersonation,
+) []domain.Impersonation {
+	impersonations := []domain.Impersonation{}
+	for _, impersonation := range dbImpersonations {
+		impersonations = append(impersonations, *dbImpersonationToDomainImpersonation(impersonation))
+	}
+	return impersonations
+}
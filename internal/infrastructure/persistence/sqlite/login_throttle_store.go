@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type LoginThrottleStore struct {
+	db *sqlx.DB
+}
+
+func NewLoginThrottleStore(db *sqlx.DB) *LoginThrottleStore {
+	return &LoginThrottleStore{
+		db: db,
+	}
+}
+
+func (s LoginThrottleStore) Get(key string) (domain.LoginAttempts, error) {
+	var dbAttempts models.LoginAttempts
+	err := s.db.Get(&dbAttempts, "SELECT * FROM login_attempts WHERE key=?", key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.LoginAttempts{}, nil
+		}
+		return domain.LoginAttempts{}, err
+	}
+
+	return domain.LoginAttempts{
+		Failures:      dbAttempts.Failures,
+		LastFailureAt: dbAttempts.LastFailureAt,
+		BlockedUntil:  dbAttempts.BlockedUntil,
+	}, nil
+}
+
+// RecordFailure increments the failures in the database rather than saving a count read
+// before, and blocks the key in the same transaction, so concurrent failures are all
+// counted
+func (s LoginThrottleStore) RecordFailure(
+	key string,
+	at, resetBefore time.Time,
+	backoff func(failures int) time.Duration,
+) (domain.LoginAttempts, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return domain.LoginAttempts{}, err
+	}
+	defer tx.Rollback()
+
+	var failures int
+	err = tx.Get(&failures, `
+		INSERT INTO login_attempts (key, failures, last_failure_at, blocked_until)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT(key) DO UPDATE
+		SET failures = CASE
+				WHEN last_failure_at < ? THEN 1
+				ELSE failures + 1
+			END,
+			last_failure_at = excluded.last_failure_at
+		RETURNING failures
+	`,
+		key,
+		at.UTC(),
+		at.UTC(),
+		resetBefore.UTC(),
+	)
+	if err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	attempts := domain.LoginAttempts{
+		Failures:      failures,
+		LastFailureAt: at,
+		BlockedUntil:  at.Add(backoff(failures)),
+	}
+	_, err = tx.Exec(
+		"UPDATE login_attempts SET blocked_until=? WHERE key=?",
+		attempts.BlockedUntil.UTC(),
+		key,
+	)
+	if err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	return attempts, tx.Commit()
+}
+
+func (s LoginThrottleStore) Delete(key string) error {
+	_, err := s.db.Exec("DELETE FROM login_attempts WHERE key=?", key)
+	return err
+}
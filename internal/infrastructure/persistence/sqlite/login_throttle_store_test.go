@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoginThrottleStoreCountsConcurrentFailures(t *testing.T) {
+	store := NewLoginThrottleStore(newTestDB(t))
+	backoff := func(failures int) time.Duration { return time.Duration(failures) * time.Second }
+
+	const failures = 20
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for range failures {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.RecordFailure("username:alice", now, now.Add(-time.Hour), backoff)
+			if err != nil {
+				t.Errorf("RecordFailure() failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	attempts, err := store.Get("username:alice")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if attempts.Failures != failures {
+		t.Errorf("failures = %d, want %d", attempts.Failures, failures)
+	}
+	if want := now.Add(backoff(failures)); !attempts.BlockedUntil.Equal(want) {
+		t.Errorf("blocked until %s, want %s", attempts.BlockedUntil, want)
+	}
+
+	// Failures from before the reset are forgotten
+	later := now.Add(2 * time.Hour)
+	attempts, err = store.RecordFailure("username:alice", later, later.Add(-time.Hour), backoff)
+	if err != nil {
+		t.Fatalf("RecordFailure() failed: %v", err)
+	}
+	if attempts.Failures != 1 || !attempts.BlockedUntil.Equal(later.Add(time.Second)) {
+		t.Errorf("attempts = %+v, want 1 failure blocking for a second", attempts)
+	}
+}
@@ -0,0 +1,16 @@
+CREATE TABLE sessions (
+  token TEXT PRIMARY KEY,
+  data BLOB NOT NULL,
+  expiry DATETIME NOT NULL,
+  id TEXT UNIQUE,
+  user_id TEXT,
+  user_agent TEXT NOT NULL DEFAULT '',
+  device TEXT NOT NULL DEFAULT '',
+  ip_address TEXT NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_seen_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX sessions_expiry_idx ON sessions(expiry);
+CREATE INDEX sessions_user_id_idx ON sessions(user_id);
@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN locked_until DATETIME;
+
+CREATE TABLE login_attempts (
+  key TEXT PRIMARY KEY,
+  failures INTEGER NOT NULL DEFAULT 0,
+  last_failure_at DATETIME NOT NULL,
+  blocked_until DATETIME NOT NULL
+);
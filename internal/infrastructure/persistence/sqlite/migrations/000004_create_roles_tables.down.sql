@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN user_roles TEXT NOT NULL DEFAULT '';
+
+UPDATE users
+SET user_roles = COALESCE(
+  (SELECT GROUP_CONCAT(role_name, ';') FROM user_roles WHERE user_roles.user_id = users.id),
+  ''
+);
+
+DROP INDEX IF EXISTS user_roles_role_name_idx;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
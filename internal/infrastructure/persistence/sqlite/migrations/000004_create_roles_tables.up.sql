@@ -0,0 +1,74 @@
+CREATE TABLE roles (
+  name TEXT PRIMARY KEY,
+  description TEXT NOT NULL DEFAULT '',
+  built_in BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+  role_name TEXT NOT NULL,
+  permission TEXT NOT NULL,
+  PRIMARY KEY (role_name, permission),
+  FOREIGN KEY (role_name) REFERENCES roles(name) ON DELETE CASCADE
+);
+
+CREATE TABLE user_roles (
+  user_id TEXT NOT NULL,
+  role_name TEXT NOT NULL,
+  PRIMARY KEY (user_id, role_name),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY (role_name) REFERENCES roles(name) ON DELETE CASCADE
+);
+
+CREATE INDEX user_roles_role_name_idx ON user_roles(role_name);
+
+-- Built-in roles, matching domain.BuiltInRoles
+INSERT INTO roles (name, description, built_in) VALUES
+  ('COMMENTER', 'Can comment on and rate posts', TRUE),
+  ('AUTHOR', 'Can write posts', TRUE),
+  ('EDITOR', 'Can moderate other users'' posts and comments', TRUE),
+  ('ADMIN', 'Can do everything, including managing users and roles', TRUE);
+
+INSERT INTO role_permissions (role_name, permission) VALUES
+  ('COMMENTER', 'comments:create'),
+  ('COMMENTER', 'comments:edit:own'),
+  ('COMMENTER', 'comments:archive:own'),
+  ('COMMENTER', 'ratings:create'),
+  ('COMMENTER', 'ratings:change:own'),
+  ('COMMENTER', 'ratings:remove:own'),
+  ('AUTHOR', 'posts:create'),
+  ('AUTHOR', 'posts:edit:own'),
+  ('AUTHOR', 'posts:archive:own'),
+  ('EDITOR', 'posts:edit:any'),
+  ('EDITOR', 'posts:archive:any'),
+  ('EDITOR', 'comments:archive:any'),
+  ('ADMIN', 'posts:create'),
+  ('ADMIN', 'posts:edit:any'),
+  ('ADMIN', 'posts:archive:any'),
+  ('ADMIN', 'comments:create'),
+  ('ADMIN', 'comments:edit:any'),
+  ('ADMIN', 'comments:archive:any'),
+  ('ADMIN', 'ratings:create'),
+  ('ADMIN', 'ratings:change:own'),
+  ('ADMIN', 'ratings:remove:any'),
+  ('ADMIN', 'users:manage');
+
+-- Split the ;-joined users.user_roles column into the join table
+WITH RECURSIVE split(user_id, role_name, rest) AS (
+  SELECT id, '', REPLACE(user_roles, ',', ';') || ';' FROM users
+  UNION ALL
+  SELECT
+    user_id,
+    TRIM(SUBSTR(rest, 1, INSTR(rest, ';') - 1)),
+    SUBSTR(rest, INSTR(rest, ';') + 1)
+  FROM split
+  WHERE rest <> ''
+)
+INSERT OR IGNORE INTO user_roles (user_id, role_name)
+SELECT user_id, role_name FROM split WHERE role_name <> '';
+
+-- Keep any roles that were assigned without ever being defined, so no assignment is lost
+INSERT OR IGNORE INTO roles (name, description, built_in)
+SELECT DISTINCT role_name, '', FALSE FROM user_roles;
+
+ALTER TABLE users DROP COLUMN user_roles;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_suspended_until;
+
+ALTER TABLE users DROP COLUMN suspended_until;
+ALTER TABLE users DROP COLUMN status_reason;
+ALTER TABLE users DROP COLUMN status;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN status TEXT NOT NULL DEFAULT 'ACTIVE';
+ALTER TABLE users ADD COLUMN status_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN suspended_until DATETIME;
+
+CREATE INDEX idx_users_suspended_until ON users(suspended_until) WHERE status = 'SUSPENDED';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_delete_after;
+
+ALTER TABLE users DROP COLUMN anonymised_at;
+ALTER TABLE users DROP COLUMN delete_after;
+ALTER TABLE users DROP COLUMN deletion_requested_at;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN deletion_requested_at DATETIME;
+ALTER TABLE users ADD COLUMN delete_after DATETIME;
+ALTER TABLE users ADD COLUMN anonymised_at DATETIME;
+
+CREATE INDEX idx_users_delete_after ON users(delete_after) WHERE delete_after IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN avatar_version;
+ALTER TABLE users DROP COLUMN links;
+ALTER TABLE users DROP COLUMN location;
+ALTER TABLE users DROP COLUMN display_name;
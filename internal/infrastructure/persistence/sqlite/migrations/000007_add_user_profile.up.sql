@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN location TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN links TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE users ADD COLUMN avatar_version TEXT NOT NULL DEFAULT '';
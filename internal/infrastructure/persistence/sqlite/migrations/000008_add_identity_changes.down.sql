@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_identity_changes_reserved_usernames;
+DROP INDEX IF EXISTS idx_identity_changes_user_id;
+DROP TABLE IF EXISTS identity_changes;
+
+DROP INDEX IF EXISTS idx_users_email_change_token_hash;
+
+ALTER TABLE users DROP COLUMN username_changed_at;
+ALTER TABLE users DROP COLUMN email_change_expires_at;
+ALTER TABLE users DROP COLUMN email_change_token_hash;
+ALTER TABLE users DROP COLUMN pending_email;
@@ -0,0 +1,22 @@
+ALTER TABLE users ADD COLUMN pending_email TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN email_change_token_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN email_change_expires_at DATETIME;
+ALTER TABLE users ADD COLUMN username_changed_at DATETIME;
+
+CREATE UNIQUE INDEX idx_users_email_change_token_hash ON users(email_change_token_hash)
+  WHERE email_change_token_hash != '';
+
+CREATE TABLE identity_changes (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  old_value TEXT NOT NULL,
+  new_value TEXT NOT NULL,
+  changed_at DATETIME NOT NULL,
+  reserved_until DATETIME,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_identity_changes_user_id ON identity_changes(user_id);
+CREATE INDEX idx_identity_changes_reserved_usernames ON identity_changes(old_value, reserved_until)
+  WHERE kind = 'username';
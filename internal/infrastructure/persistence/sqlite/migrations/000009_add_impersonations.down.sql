@@ -0,0 +1,7 @@
+DELETE FROM role_permissions WHERE permission = 'users:impersonate';
+
+DROP INDEX IF EXISTS idx_impersonations_started_at;
+DROP INDEX IF EXISTS idx_impersonations_user_id;
+DROP TABLE IF EXISTS impersonations;
+
+ALTER TABLE sessions DROP COLUMN impersonator_id;
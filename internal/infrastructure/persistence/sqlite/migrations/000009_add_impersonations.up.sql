@@ -0,0 +1,17 @@
+ALTER TABLE sessions ADD COLUMN impersonator_id TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE impersonations (
+  id TEXT PRIMARY KEY,
+  admin_id TEXT NOT NULL,
+  user_id TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  started_at DATETIME NOT NULL,
+  ended_at DATETIME
+);
+
+CREATE INDEX idx_impersonations_user_id ON impersonations(user_id);
+CREATE INDEX idx_impersonations_started_at ON impersonations(started_at);
+
+-- Admins can impersonate by default, matching domain.BuiltInRoles
+INSERT OR IGNORE INTO role_permissions (role_name, permission) VALUES
+  ('ADMIN', 'users:impersonate');
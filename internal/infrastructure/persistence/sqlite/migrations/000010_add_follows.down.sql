@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_posts_author_id_created_at;
+
+DROP INDEX IF EXISTS idx_follows_followee_id;
+DROP TABLE IF EXISTS follows;
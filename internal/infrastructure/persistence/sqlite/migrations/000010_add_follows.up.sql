@@ -0,0 +1,14 @@
+CREATE TABLE follows (
+  id TEXT PRIMARY KEY,
+  follower_id TEXT NOT NULL,
+  followee_id TEXT NOT NULL,
+  created_at DATETIME NOT NULL,
+  UNIQUE (follower_id, followee_id),
+  FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_follows_followee_id ON follows(followee_id);
+
+-- The feed reads each followed author's posts newest first
+CREATE INDEX idx_posts_author_id_created_at ON posts(author_id, created_at);
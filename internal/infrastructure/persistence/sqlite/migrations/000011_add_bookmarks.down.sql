@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bookmarks_folder_id;
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS bookmark_folders;
@@ -0,0 +1,25 @@
+CREATE TABLE bookmark_folders (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  name TEXT NOT NULL COLLATE NOCASE,
+  created_at DATETIME NOT NULL,
+  UNIQUE (user_id, name),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- post_id has no foreign key, a bookmark outlives its post so the reader can see it's
+-- no longer available
+CREATE TABLE bookmarks (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  post_id TEXT NOT NULL,
+  folder_id TEXT,
+  note TEXT NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME,
+  UNIQUE (user_id, post_id),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY (folder_id) REFERENCES bookmark_folders(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_bookmarks_folder_id ON bookmarks(folder_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_mutes;
+
+DROP INDEX IF EXISTS idx_notifications_recipient_id_created_at;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,21 @@
+CREATE TABLE notifications (
+  id TEXT PRIMARY KEY,
+  recipient_id TEXT NOT NULL,
+  type TEXT NOT NULL,
+  actor_id TEXT NOT NULL,
+  post_id TEXT NOT NULL,
+  comment_id TEXT,
+  created_at DATETIME NOT NULL,
+  read_at DATETIME,
+  FOREIGN KEY (recipient_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- The inbox lists a user's notifications newest first and counts the unread ones
+CREATE INDEX idx_notifications_recipient_id_created_at ON notifications(recipient_id, created_at);
+
+CREATE TABLE notification_mutes (
+  user_id TEXT NOT NULL,
+  type TEXT NOT NULL,
+  PRIMARY KEY (user_id, type),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
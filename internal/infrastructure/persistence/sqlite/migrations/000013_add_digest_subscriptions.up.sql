@@ -0,0 +1,6 @@
+CREATE TABLE digest_subscriptions (
+  user_id TEXT PRIMARY KEY,
+  frequency TEXT NOT NULL,
+  last_sent_at DATETIME,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,35 @@
+CREATE TABLE newsletter_subscribers (
+  id TEXT PRIMARY KEY,
+  email TEXT NOT NULL,
+  author_id TEXT,
+  status TEXT NOT NULL,
+  token_hash TEXT,
+  token_expires_at DATETIME,
+  created_at DATETIME NOT NULL,
+  confirmed_at DATETIME,
+  disabled_reason TEXT,
+  FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- An address subscribes once to the whole blog, with no author, and once per author
+CREATE UNIQUE INDEX idx_newsletter_subscribers_email_author_id
+  ON newsletter_subscribers(email, IFNULL(author_id, ''));
+
+CREATE INDEX idx_newsletter_subscribers_token_hash ON newsletter_subscribers(token_hash);
+
+CREATE TABLE newsletter_deliveries (
+  subscriber_id TEXT NOT NULL,
+  post_id TEXT NOT NULL,
+  status TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  queued_at DATETIME NOT NULL,
+  sent_at DATETIME,
+  PRIMARY KEY (subscriber_id, post_id),
+  FOREIGN KEY (subscriber_id) REFERENCES newsletter_subscribers(id) ON DELETE CASCADE,
+  FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- The sender works through pending deliveries oldest first
+CREATE INDEX idx_newsletter_deliveries_status_queued_at
+  ON newsletter_deliveries(status, queued_at);
@@ -0,0 +1,18 @@
+CREATE TABLE outbox (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  aggregate_id TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  occurred_at DATETIME NOT NULL,
+  status TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  next_attempt_at DATETIME NOT NULL,
+  published_at DATETIME
+);
+
+-- The relay works through pending messages in the order they were written
+CREATE INDEX idx_outbox_status_id ON outbox(status, id);
+
+-- An aggregate's messages are held back while an earlier one is waiting for a retry
+CREATE INDEX idx_outbox_aggregate_id_status ON outbox(aggregate_id, status);
@@ -0,0 +1,28 @@
+CREATE TABLE events (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  aggregate_id TEXT NOT NULL,
+  version INTEGER NOT NULL,
+  event_type TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  occurred_at DATETIME NOT NULL,
+  UNIQUE (aggregate_id, version)
+);
+
+-- Comments are event sourced. Each existing comment starts its stream with a
+-- CommentCreated event carrying its current state
+INSERT INTO events (aggregate_id, version, event_type, payload, occurred_at)
+SELECT
+  id,
+  1,
+  'CommentCreated',
+  json_object(
+    'CommentID', id,
+    'PostID', post_id,
+    'CommenterID', commenter_id,
+    'Content', content,
+    'CreatedAt', strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    'LastUpdatedAt', strftime('%Y-%m-%dT%H:%M:%fZ', last_updated_at),
+    'ArchivedAt', strftime('%Y-%m-%dT%H:%M:%fZ', archived_at)
+  ),
+  created_at
+FROM comments;
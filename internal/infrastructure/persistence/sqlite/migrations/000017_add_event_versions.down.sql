@@ -0,0 +1,2 @@
+ALTER TABLE events DROP COLUMN event_version;
+ALTER TABLE outbox DROP COLUMN event_version;
@@ -0,0 +1,4 @@
+-- The version of the event's shape its payload was written in. Everything stored so far
+-- is version 1
+ALTER TABLE outbox ADD COLUMN event_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE events ADD COLUMN event_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,16 @@
+CREATE TABLE dead_letters (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  subscription TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  event_version INTEGER NOT NULL,
+  payload TEXT NOT NULL,
+  occurred_at DATETIME NOT NULL,
+  attempts INTEGER NOT NULL,
+  last_error TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  failed_at DATETIME NOT NULL,
+  resolved_at DATETIME
+);
+
+-- Dead letters are listed and counted by status
+CREATE INDEX idx_dead_letters_status_failed_at ON dead_letters(status, failed_at);
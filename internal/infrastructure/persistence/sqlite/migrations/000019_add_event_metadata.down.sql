@@ -0,0 +1,3 @@
+ALTER TABLE dead_letters DROP COLUMN metadata;
+ALTER TABLE events DROP COLUMN metadata;
+ALTER TABLE outbox DROP COLUMN metadata;
@@ -0,0 +1,5 @@
+-- Where each event came from: its ID, the request it was part of, the event or request
+-- that caused it and the acting user, as JSON. Events stored so far have none
+ALTER TABLE outbox ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE events ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE dead_letters ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,21 @@
+CREATE TABLE sagas (
+  id TEXT PRIMARY KEY,
+  saga_type TEXT NOT NULL,
+  correlation_key TEXT NOT NULL,
+  status TEXT NOT NULL,
+  -- The names of the completed and compensated steps, and the steps' data, as JSON
+  completed_steps TEXT NOT NULL DEFAULT '[]',
+  compensated_steps TEXT NOT NULL DEFAULT '[]',
+  data TEXT NOT NULL DEFAULT '{}',
+  metadata TEXT NOT NULL DEFAULT '{}',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  timeout_at DATETIME,
+  started_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  version INTEGER NOT NULL DEFAULT 0,
+  UNIQUE (saga_type, correlation_key)
+);
+
+-- Unfinished sagas are resumed on startup, and running ones checked for timeouts
+CREATE INDEX idx_sagas_status_timeout_at ON sagas(status, timeout_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS projection_checkpoints;
+DROP TABLE IF EXISTS post_summary_ratings;
+DROP TABLE IF EXISTS post_summary_comments;
+DROP TABLE IF EXISTS post_summaries;
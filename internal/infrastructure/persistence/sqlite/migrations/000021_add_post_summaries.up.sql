@@ -0,0 +1,84 @@
+CREATE TABLE post_summaries (
+  post_id TEXT PRIMARY KEY,
+  author_id TEXT NOT NULL,
+  author_username TEXT NOT NULL,
+  title TEXT NOT NULL,
+  comment_count INTEGER NOT NULL DEFAULT 0,
+  likes INTEGER NOT NULL DEFAULT 0,
+  dislikes INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME NOT NULL,
+  last_activity_at DATETIME NOT NULL,
+  archived_at DATETIME
+);
+
+CREATE INDEX idx_post_summaries_last_activity_at ON post_summaries(last_activity_at);
+CREATE INDEX idx_post_summaries_author_id ON post_summaries(author_id);
+
+-- Which post each comment and rating is on, as not every comment and rating event says,
+-- for counting them
+CREATE TABLE post_summary_comments (
+  comment_id TEXT PRIMARY KEY,
+  post_id TEXT NOT NULL,
+  commenter_id TEXT NOT NULL,
+  archived INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_post_summary_comments_post_id ON post_summary_comments(post_id);
+CREATE INDEX idx_post_summary_comments_commenter_id ON post_summary_comments(commenter_id);
+
+CREATE TABLE post_summary_ratings (
+  rating_id TEXT PRIMARY KEY,
+  post_id TEXT NOT NULL,
+  user_id TEXT NOT NULL,
+  rating_type TEXT NOT NULL
+);
+
+CREATE INDEX idx_post_summary_ratings_post_id ON post_summary_ratings(post_id);
+CREATE INDEX idx_post_summary_ratings_user_id ON post_summary_ratings(user_id);
+
+CREATE TABLE projection_checkpoints (
+  projection TEXT PRIMARY KEY,
+  position INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  updated_at DATETIME NOT NULL
+);
+
+-- The summaries start from the posts as they are, and the projection carries on from
+-- the latest event in the outbox
+INSERT INTO post_summary_comments (comment_id, post_id, commenter_id, archived)
+SELECT id, post_id, commenter_id, archived_at IS NOT NULL
+FROM comments;
+
+INSERT INTO post_summary_ratings (rating_id, post_id, user_id, rating_type)
+SELECT id, post_id, user_id, rating_type
+FROM ratings;
+
+INSERT INTO post_summaries (
+  post_id, author_id, author_username, title, comment_count, likes, dislikes, created_at,
+  last_activity_at, archived_at
+)
+SELECT
+  p.id,
+  p.author_id,
+  COALESCE(u.username, ''),
+  p.title,
+  (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.archived_at IS NULL),
+  (SELECT COUNT(*) FROM ratings r WHERE r.post_id = p.id AND r.rating_type = 'like'),
+  (SELECT COUNT(*) FROM ratings r WHERE r.post_id = p.id AND r.rating_type = 'dislike'),
+  p.created_at,
+  MAX(
+    p.created_at,
+    COALESCE(p.last_edited_at, p.created_at),
+    COALESCE((SELECT MAX(c.created_at) FROM comments c WHERE c.post_id = p.id), p.created_at),
+    COALESCE(
+      (SELECT MAX(COALESCE(r.updated_at, r.created_at)) FROM ratings r WHERE r.post_id = p.id),
+      p.created_at
+    )
+  ),
+  p.archived_at
+FROM posts p
+LEFT JOIN users u ON u.id = p.author_id;
+
+INSERT INTO projection_checkpoints (projection, position, updated_at)
+SELECT 'post_summaries', COALESCE(MAX(id), 0), CURRENT_TIMESTAMP
+FROM outbox;
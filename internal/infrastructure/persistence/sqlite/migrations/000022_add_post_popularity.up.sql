@@ -0,0 +1,3 @@
+-- When the post's likes first reached the popular threshold, so it's only made popular
+-- once. Posts liked so far are made popular by their next like
+ALTER TABLE posts ADD COLUMN popular_at DATETIME;
@@ -0,0 +1,261 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NewsletterRepository struct {
+	db *sqlx.DB
+}
+
+func NewNewsletterRepository(db *sqlx.DB) *NewsletterRepository {
+	return &NewsletterRepository{
+		db: db,
+	}
+}
+
+func (r NewsletterRepository) FindByID(
+	id domain.NewsletterSubscriberID,
+) (*domain.NewsletterSubscriber, error) {
+	return r.findOne("SELECT * FROM newsletter_subscribers WHERE id=?", id.String())
+}
+
+func (r NewsletterRepository) FindByEmailAndAuthor(
+	email string,
+	authorID *domain.UserID,
+) (*domain.NewsletterSubscriber, error) {
+	return r.findOne(
+		"SELECT * FROM newsletter_subscribers WHERE email=? AND IFNULL(author_id, '')=?",
+		domain.NormaliseNewsletterEmail(email),
+		authorIDOrEmpty(authorID),
+	)
+}
+
+func (r NewsletterRepository) FindByEmail(email string) ([]domain.NewsletterSubscriber, error) {
+	return r.findMany(
+		"SELECT * FROM newsletter_subscribers WHERE email=?",
+		domain.NormaliseNewsletterEmail(email),
+	)
+}
+
+func (r NewsletterRepository) FindByTokenHash(
+	tokenHash string,
+) (*domain.NewsletterSubscriber, error) {
+	return r.findOne("SELECT * FROM newsletter_subscribers WHERE token_hash=?", tokenHash)
+}
+
+func (r NewsletterRepository) FindActiveForAuthor(
+	authorID domain.UserID,
+) ([]domain.NewsletterSubscriber, error) {
+	return r.findMany(`
+		SELECT * FROM newsletter_subscribers
+		WHERE status=? AND (author_id IS NULL OR author_id=?)
+	`,
+		domain.NewsletterSubscriberActive.String(),
+		authorID.String(),
+	)
+}
+
+func (r NewsletterRepository) Save(subscriber *domain.NewsletterSubscriber) error {
+	return withEvents(r.db, subscriber.GetID().String(), subscriber, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO newsletter_subscribers (
+				id, email, author_id, status, token_hash, token_expires_at, created_at,
+				confirmed_at, disabled_reason
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE
+			SET status = excluded.status,
+				token_hash = excluded.token_hash,
+				token_expires_at = excluded.token_expires_at,
+				confirmed_at = excluded.confirmed_at,
+				disabled_reason = excluded.disabled_reason
+		`,
+			subscriber.GetID().String(),
+			subscriber.Email(),
+			nullableAuthorID(subscriber.AuthorID()),
+			subscriber.Status().String(),
+			nullableString(subscriber.TokenHash()),
+			utcOrNil(subscriber.TokenExpiresAt()),
+			subscriber.CreatedAt().UTC(),
+			utcOrNil(subscriber.ConfirmedAt()),
+			nullableString(subscriber.DisabledReason().String()),
+		)
+		return err
+	})
+}
+
+func (r NewsletterRepository) QueueDeliveries(deliveries []domain.NewsletterDelivery) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, delivery := range deliveries {
+		_, err := tx.Exec(`
+			INSERT INTO newsletter_deliveries (subscriber_id, post_id, status, queued_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(subscriber_id, post_id) DO NOTHING
+		`,
+			delivery.SubscriberID.String(),
+			delivery.PostID.String(),
+			delivery.Status.String(),
+			delivery.QueuedAt.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r NewsletterRepository) FindPendingDeliveries(
+	limit int,
+) ([]domain.NewsletterDelivery, error) {
+	var dbDeliveries []models.NewsletterDelivery
+	err := r.db.Select(
+		&dbDeliveries,
+		"SELECT * FROM newsletter_deliveries WHERE status=? ORDER BY queued_at LIMIT ?",
+		domain.NewsletterDeliveryPending.String(),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []domain.NewsletterDelivery{}
+	for _, dbDelivery := range dbDeliveries {
+		deliveries = append(deliveries, domain.NewsletterDelivery{
+			SubscriberID: domain.NewNewsletterSubscriberID(dbDelivery.SubscriberID),
+			PostID:       domain.NewPostID(dbDelivery.PostID),
+			Status:       domain.NewsletterDeliveryStatus(dbDelivery.Status),
+			Attempts:     dbDelivery.Attempts,
+			LastError:    dbDelivery.LastError,
+			QueuedAt:     dbDelivery.QueuedAt,
+			SentAt:       dbDelivery.SentAt,
+		})
+	}
+
+	return deliveries, nil
+}
+
+func (r NewsletterRepository) SaveDelivery(delivery *domain.NewsletterDelivery) error {
+	_, err := r.db.Exec(`
+		UPDATE newsletter_deliveries
+		SET status=?, attempts=?, last_error=?, sent_at=?
+		WHERE subscriber_id=? AND post_id=?
+	`,
+		delivery.Status.String(),
+		delivery.Attempts,
+		delivery.LastError,
+		utcOrNil(delivery.SentAt),
+		delivery.SubscriberID.String(),
+		delivery.PostID.String(),
+	)
+	return err
+}
+
+func (r NewsletterRepository) findOne(
+	query string,
+	args ...any,
+) (*domain.NewsletterSubscriber, error) {
+	var dbSubscriber models.NewsletterSubscriber
+	if err := r.db.Get(&dbSubscriber, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNewsletterSubscriberNotFound
+		}
+		return nil, err
+	}
+
+	return dbNewsletterSubscriberToDomain(dbSubscriber), nil
+}
+
+func (r NewsletterRepository) findMany(
+	query string,
+	args ...any,
+) ([]domain.NewsletterSubscriber, error) {
+	var dbSubscribers []models.NewsletterSubscriber
+	if err := r.db.Select(&dbSubscribers, query, args...); err != nil {
+		return nil, err
+	}
+
+	subscribers := []domain.NewsletterSubscriber{}
+	for _, dbSubscriber := range dbSubscribers {
+		subscribers = append(subscribers, *dbNewsletterSubscriberToDomain(dbSubscriber))
+	}
+
+	return subscribers, nil
+}
+
+// nullableAuthorID stores whole-blog subscriptions with a NULL author, so the foreign
+// key only applies to subscriptions to one author
+func nullableAuthorID(authorID *domain.UserID) *string {
+	if authorID == nil {
+		return nil
+	}
+	id := authorID.String()
+	return &id
+}
+
+func authorIDOrEmpty(authorID *domain.UserID) string {
+	if authorID == nil {
+		return ""
+	}
+	return authorID.String()
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func utcOrNil(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func dbNewsletterSubscriberToDomain(
+	dbSubscriber models.NewsletterSubscriber,
+) *domain.NewsletterSubscriber {
+	var authorID *domain.UserID
+	if dbSubscriber.AuthorID != nil {
+		id := domain.NewUserID(*dbSubscriber.AuthorID)
+		authorID = &id
+	}
+
+	tokenHash := ""
+	if dbSubscriber.TokenHash != nil {
+		tokenHash = *dbSubscriber.TokenHash
+	}
+
+	disabledReason := domain.NewsletterFeedbackType("")
+	if dbSubscriber.DisabledReason != nil {
+		disabledReason = domain.NewsletterFeedbackType(*dbSubscriber.DisabledReason)
+	}
+
+	return domain.RebuildNewsletterSubscriber(
+		domain.NewNewsletterSubscriberID(dbSubscriber.ID),
+		dbSubscriber.Email,
+		authorID,
+		domain.NewsletterSubscriberStatus(dbSubscriber.Status),
+		tokenHash,
+		dbSubscriber.TokenExpiresAt,
+		dbSubscriber.CreatedAt,
+		dbSubscriber.ConfirmedAt,
+		disabledReason,
+	)
+}
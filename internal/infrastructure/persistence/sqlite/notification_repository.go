@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+func (r NotificationRepository) FindByID(id domain.NotificationID) (*domain.Notification, error) {
+	var dbNotification models.Notification
+	err := r.db.Get(&dbNotification, "SELECT * FROM notifications WHERE id=?", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotificationNotFound
+		}
+		return nil, err
+	}
+
+	notification := dbNotificationToDomainNotification(dbNotification)
+	return notification, nil
+}
+
+func (r NotificationRepository) FindByRecipient(
+	recipientID domain.UserID,
+	unreadOnly bool,
+) ([]domain.Notification, error) {
+	query := "SELECT * FROM notifications WHERE recipient_id=?"
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC"
+
+	var dbNotifications []models.Notification
+	if err := r.db.Select(&dbNotifications, query, recipientID); err != nil {
+		return nil, err
+	}
+
+	notifications := []domain.Notification{}
+	for _, dbNotification := range dbNotifications {
+		notifications = append(
+			notifications,
+			*dbNotificationToDomainNotification(dbNotification),
+		)
+	}
+
+	return notifications, nil
+}
+
+func (r NotificationRepository) CountUnread(recipientID domain.UserID) (int, error) {
+	var count int
+	err := r.db.Get(
+		&count,
+		"SELECT COUNT(*) FROM notifications WHERE recipient_id=? AND read_at IS NULL",
+		recipientID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r NotificationRepository) Create(
+	notification *domain.Notification,
+) (*domain.Notification, error) {
+	err := withEvents(r.db, notification.GetID().String(), notification, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO
+			notifications (id, recipient_id, type, actor_id, post_id, comment_id, created_at, read_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			notification.GetID().String(),
+			notification.RecipientID().String(),
+			notification.Type().String(),
+			notification.ActorID().String(),
+			notification.PostID().String(),
+			nullableCommentID(notification.CommentID()),
+			notification.CreatedAt().UTC(),
+			nil,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+func (r NotificationRepository) MarkRead(notification *domain.Notification) error {
+	return withEvents(r.db, notification.GetID().String(), notification, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(
+			"UPDATE notifications SET read_at=? WHERE id=? AND read_at IS NULL",
+			notification.ReadAt().UTC(),
+			notification.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r NotificationRepository) FindPreferences(
+	userID domain.UserID,
+) (*domain.NotificationPreferences, error) {
+	var types []string
+	err := r.db.Select(
+		&types,
+		"SELECT type FROM notification_mutes WHERE user_id=? ORDER BY type",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	muted := []domain.NotificationType{}
+	for _, notificationType := range types {
+		muted = append(muted, domain.NotificationType(notificationType))
+	}
+
+	return domain.NewNotificationPreferences(userID, muted)
+}
+
+func (r NotificationRepository) SavePreferences(
+	preferences *domain.NotificationPreferences,
+) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"DELETE FROM notification_mutes WHERE user_id=?",
+		preferences.UserID().String(),
+	); err != nil {
+		return err
+	}
+
+	for _, notificationType := range preferences.Muted() {
+		if _, err := tx.Exec(
+			"INSERT INTO notification_mutes (user_id, type) VALUES (?, ?)",
+			preferences.UserID().String(),
+			notificationType.String(),
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r NotificationRepository) DeleteByUser(userID domain.UserID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"DELETE FROM notifications WHERE recipient_id=?",
+		userID.String(),
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"DELETE FROM notification_mutes WHERE user_id=?",
+		userID.String(),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// nullableCommentID stores notifications about ratings, which have no comment, with a
+// NULL comment_id
+func nullableCommentID(commentID domain.CommentID) *string {
+	if commentID == "" {
+		return nil
+	}
+	id := commentID.String()
+	return &id
+}
+
+func dbNotificationToDomainNotification(
+	dbNotification models.Notification,
+) *domain.Notification {
+	commentID := domain.CommentID("")
+	if dbNotification.CommentID != nil {
+		commentID = domain.NewCommentID(*dbNotification.CommentID)
+	}
+
+	return domain.RebuildNotification(
+		domain.NewNotificationID(dbNotification.ID),
+		domain.NewUserID(dbNotification.RecipientID),
+		domain.NotificationType(dbNotification.Type),
+		domain.NewUserID(dbNotification.ActorID),
+		domain.NewPostID(dbNotification.PostID),
+		commentID,
+		dbNotification.CreatedAt,
+		dbNotification.ReadAt,
+	)
+}
@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"blog/internal/infrastructure/persistence/models"
+	"blog/pkg/ddd"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxStore struct {
+	db *sqlx.DB
+}
+
+func NewOutboxStore(db *sqlx.DB) *OutboxStore {
+	return &OutboxStore{
+		db: db,
+	}
+}
+
+func (s OutboxStore) FindDue(at time.Time, limit int) ([]ddd.OutboxMessage, error) {
+	var dbMessages []models.OutboxMessage
+	err := s.db.Select(&dbMessages, `
+		SELECT * FROM outbox o
+		WHERE o.status = ? AND o.next_attempt_at <= ?
+		AND NOT EXISTS (
+			SELECT 1 FROM outbox earlier
+			WHERE earlier.aggregate_id = o.aggregate_id
+			AND earlier.status = ?
+			AND earlier.next_attempt_at > ?
+			AND earlier.id < o.id
+		)
+		ORDER BY o.id
+		LIMIT ?
+	`,
+		ddd.OutboxStatusPending.String(),
+		at.UTC(),
+		ddd.OutboxStatusPending.String(),
+		at.UTC(),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []ddd.OutboxMessage{}
+	for _, dbMessage := range dbMessages {
+		messages = append(messages, dbOutboxMessageToOutboxMessage(dbMessage))
+	}
+	return messages, nil
+}
+
+func (s OutboxStore) Update(message ddd.OutboxMessage) error {
+	_, err := s.db.Exec(`
+		UPDATE outbox
+		SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, published_at = ?
+		WHERE id = ?
+	`,
+		message.Status.String(),
+		message.Attempts,
+		message.LastError,
+		message.NextAttemptAt.UTC(),
+		utcOrNil(message.PublishedAt),
+		message.ID,
+	)
+	return err
+}
+
+// ReadFrom reads the outbox as an event log. Messages are kept once they're published,
+// so the outbox holds every event stored since it was added, whether or not it has been
+// published yet. Outbox rows must never be deleted, as the post summaries are rebuilt
+// from them
+func (s OutboxStore) ReadFrom(after int64, limit int) ([]ddd.RecordedEvent, error) {
+	var dbMessages []models.OutboxMessage
+	err := s.db.Select(&dbMessages, `
+		SELECT * FROM outbox
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?
+	`,
+		after,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []ddd.RecordedEvent{}
+	for _, dbMessage := range dbMessages {
+		events = append(events, ddd.RecordedEvent{
+			Position: dbMessage.ID,
+			Envelope: dbOutboxMessageToOutboxMessage(dbMessage).Envelope(),
+		})
+	}
+	return events, nil
+}
+
+func (s OutboxStore) Head() (int64, error) {
+	var head int64
+	err := s.db.Get(&head, "SELECT COALESCE(MAX(id), 0) FROM outbox")
+	return head, err
+}
+
+// errAlreadyChanged is returned by the function given to withEvents when the change it
+// makes had already been made, e.g. by a concurrent request, so the aggregate's events
+// would record it twice. The transaction is rolled back and the events are dropped
+var errAlreadyChanged = errors.New("change already made")
+
+// withEvents runs fn in a transaction and adds the events the aggregate has recorded to
+// the outbox before committing, so the change is never stored without its events. The
+// events are marked committed once the transaction is
+func withEvents(
+	db *sqlx.DB,
+	aggregateID string,
+	aggregate ddd.EventAggregate,
+	fn func(tx *sqlx.Tx) error,
+) error {
+	return withEnvelopes(db, aggregateID, aggregate, func(tx *sqlx.Tx, _ []ddd.EventEnvelope) error {
+		return fn(tx)
+	})
+}
+
+// withEnvelopes is withEvents for changes that store the events themselves too, passing
+// fn the encoded events with the same metadata they go to the outbox with
+func withEnvelopes(
+	db *sqlx.DB,
+	aggregateID string,
+	aggregate ddd.EventAggregate,
+	fn func(tx *sqlx.Tx, envelopes []ddd.EventEnvelope) error,
+) error {
+	envelopes, err := ddd.EventRegistry.EncodeEvents(
+		aggregateID,
+		aggregate.EventMetadata(),
+		aggregate.GetUncommittedEvents(),
+	)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx, envelopes); errors.Is(err, errAlreadyChanged) {
+		aggregate.MarkEventsAsCommitted()
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := appendToOutbox(tx, envelopes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	aggregate.MarkEventsAsCommitted()
+	return nil
+}
+
+func appendToOutbox(tx *sqlx.Tx, envelopes []ddd.EventEnvelope) error {
+	for _, envelope := range envelopes {
+		message := ddd.NewOutboxMessage(envelope)
+		metadata, err := encodeEventMetadata(message.Metadata)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO outbox (
+				aggregate_id, event_type, event_version, metadata, payload, occurred_at,
+				status, next_attempt_at
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			message.AggregateID,
+			message.EventType,
+			message.EventVersion,
+			metadata,
+			string(message.Payload),
+			message.OccurredOn.UTC(),
+			message.Status.String(),
+			message.NextAttemptAt.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeEventMetadata(metadata ddd.EventMetadata) (string, error) {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// decodeEventMetadata reads metadata stored by encodeEventMetadata. Metadata only
+// describes where an event came from, so an event whose metadata can't be read is still
+// loaded, without it
+func decodeEventMetadata(encoded string) ddd.EventMetadata {
+	var metadata ddd.EventMetadata
+	_ = json.Unmarshal([]byte(encoded), &metadata)
+	return metadata
+}
+
+func dbOutboxMessageToOutboxMessage(dbMessage models.OutboxMessage) ddd.OutboxMessage {
+	return ddd.OutboxMessage{
+		ID:            dbMessage.ID,
+		AggregateID:   dbMessage.AggregateID,
+		EventType:     dbMessage.EventType,
+		EventVersion:  dbMessage.EventVersion,
+		Metadata:      decodeEventMetadata(dbMessage.Metadata),
+		Payload:       []byte(dbMessage.Payload),
+		OccurredOn:    dbMessage.OccurredAt,
+		Status:        ddd.OutboxStatus(dbMessage.Status),
+		Attempts:      dbMessage.Attempts,
+		LastError:     dbMessage.LastError,
+		NextAttemptAt: dbMessage.NextAttemptAt,
+		PublishedAt:   dbMessage.PublishedAt,
+	}
+}
@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// storeTestPost adds the events of a new post, with the titles it's edited to, to the
+// outbox
+func storeTestPost(t *testing.T, db *sqlx.DB, titles ...string) *domain.Post {
+	t.Helper()
+
+	post, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	for _, title := range titles {
+		if err := post.EditTitle(title); err != nil {
+			t.Fatalf("EditTitle() failed: %v", err)
+		}
+	}
+
+	err = withEvents(db, post.GetID().String(), post, func(tx *sqlx.Tx) error { return nil })
+	if err != nil {
+		t.Fatalf("withEvents() failed: %v", err)
+	}
+	return post
+}
+
+func findDue(t *testing.T, store *OutboxStore, at time.Time) []ddd.OutboxMessage {
+	t.Helper()
+
+	messages, err := store.FindDue(at, 10)
+	if err != nil {
+		t.Fatalf("FindDue() failed: %v", err)
+	}
+	return messages
+}
+
+func TestFindDueHoldsBackAnAggregateWaitingForARetry(t *testing.T) {
+	db := newTestDB(t)
+	store := NewOutboxStore(db)
+
+	edited := storeTestPost(t, db, "New title")
+	other := storeTestPost(t, db)
+
+	now := time.Now()
+	messages := findDue(t, store, now)
+	if len(messages) != 3 {
+		t.Fatalf("FindDue() = %d messages, want 3", len(messages))
+	}
+
+	// The edited post's creation fails, so its edit waits for the retry too
+	failed := messages[0]
+	failed.RecordFailure(errors.New("handler unavailable"), now, 5, time.Minute)
+	if err := store.Update(failed); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+
+	messages = findDue(t, store, now)
+	if len(messages) != 1 || messages[0].AggregateID != other.GetID().String() {
+		t.Fatalf("FindDue() before the retry = %+v, want only the other post's message",
+			messages)
+	}
+
+	messages = findDue(t, store, now.Add(time.Minute))
+	if len(messages) != 3 {
+		t.Fatalf("FindDue() at the retry = %d messages, want 3", len(messages))
+	}
+	for i, eventType := range []domain.EventType{
+		domain.PostCreatedEventType,
+		domain.PostTitleEditedEventType,
+	} {
+		if messages[i].AggregateID != edited.GetID().String() ||
+			messages[i].EventType != eventType.String() {
+			t.Errorf("message %d is %s of %s, want %s of the edited post",
+				i, messages[i].EventType, messages[i].AggregateID, eventType)
+		}
+	}
+}
+
+func TestOutboxRelayMovesMessagesToFailed(t *testing.T) {
+	db := newTestDB(t)
+	store := NewOutboxStore(db)
+
+	dispatcher := dddmemory.NewInMemoryEventDispatcher(zap.NewNop().Sugar())
+	dispatcher.Subscribe(domain.PostCreatedEventType.String(), "fails", func(event ddd.DomainEvent) error {
+		return errors.New("handler unavailable")
+	})
+	relay := ddd.NewOutboxRelay(store, dispatcher, ddd.OutboxRelayConfig{
+		BatchSize:   10,
+		MaxAttempts: 1,
+		RetryDelay:  time.Minute,
+	})
+
+	storeTestPost(t, db, "New title")
+	published, err := relay.Relay(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Relay() failed: %v", err)
+	}
+	if published != 1 {
+		t.Errorf("Relay() published %d messages, want the edit", published)
+	}
+
+	var statuses []string
+	if err := db.Select(&statuses, "SELECT status FROM outbox ORDER BY id"); err != nil {
+		t.Fatalf("Select() failed: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0] != "failed" || statuses[1] != "published" {
+		t.Errorf("statuses = %v, want [failed published]", statuses)
+	}
+	if messages := findDue(t, store, time.Now().Add(time.Hour)); len(messages) != 0 {
+		t.Errorf("FindDue() = %d messages after giving up, want 0", len(messages))
+	}
+}
+
+func TestRolledBackChangeLeavesNoOutboxRows(t *testing.T) {
+	db := newTestDB(t)
+	store := NewOutboxStore(db)
+
+	post, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+
+	failure := errors.New("constraint failed")
+	err = withEvents(db, post.GetID().String(), post, func(tx *sqlx.Tx) error {
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("withEvents() error = %v, want %v", err, failure)
+	}
+
+	if head, err := store.Head(); err != nil || head != 0 {
+		t.Errorf("Head() = %d, %v, want an empty outbox", head, err)
+	}
+
+	// The events stay uncommitted, to be stored when the change is tried again
+	if events := post.GetUncommittedEvents(); len(events) != 1 {
+		t.Errorf("post has %d uncommitted events, want 1", len(events))
+	}
+}
@@ -56,6 +56,38 @@ func (r PostRepository) FindByAuthor(authorID domain.UserID) ([]domain.Post, err
 	return posts, nil
 }
 
+func (r PostRepository) FindPublishedByAuthors(
+	authorIDs []domain.UserID,
+	after *domain.PostPosition,
+	limit int,
+) ([]domain.Post, error) {
+	if len(authorIDs) == 0 {
+		return []domain.Post{}, nil
+	}
+
+	query := "SELECT * FROM posts WHERE author_id IN (?) AND archived_at IS NULL"
+	args := []any{authorIDs}
+	if after != nil {
+		query += " AND (created_at < ? OR (created_at = ? AND id < ?))"
+		args = append(args, after.CreatedAt.UTC(), after.CreatedAt.UTC(), after.ID.String())
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var dbPosts []models.Post
+	if err := r.db.Select(&dbPosts, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	posts := dbPostsToDomainPosts(dbPosts)
+	return posts, nil
+}
+
 func (r PostRepository) Exists(id domain.PostID) (bool, error) {
 	var count int
 	err := r.db.Get(&count, "SELECT COUNT(*) FROM posts WHERE id=?", id)
@@ -66,19 +98,24 @@ func (r PostRepository) Exists(id domain.PostID) (bool, error) {
 }
 
 func (r PostRepository) Create(post *domain.Post) (*domain.Post, error) {
-	_, err := r.db.Exec(`
-		INSERT INTO 
-		posts (id, author_id, title, content, created_at, last_edited_at, archived_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`,
-		post.GetID().String(),
-		post.AuthorID().String(),
-		post.Title(),
-		post.Content(),
-		post.CreatedAt(),
-		post.LastEditedAt(),
-		post.ArchivedAt(),
-	)
+	err := withEvents(r.db, post.GetID().String(), post, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO 
+			posts (id, author_id, title, content, created_at, last_edited_at, archived_at, popular_at) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			post.GetID().String(),
+			post.AuthorID().String(),
+			post.Title(),
+			post.Content(),
+			// Stored in UTC, so the feed can page through posts by comparing timestamps
+			post.CreatedAt().UTC(),
+			post.LastEditedAt(),
+			post.ArchivedAt(),
+			post.PopularAt(),
+		)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -86,42 +123,144 @@ func (r PostRepository) Create(post *domain.Post) (*domain.Post, error) {
 	return post, nil
 }
 
-func (r PostRepository) UpdateTitle(id domain.PostID, newTitle string) error {
-	_, err := r.db.Exec(`
-		UPDATE posts
-		SET title = ?, last_edited_at = ?
-		WHERE id = ?
+func (r PostRepository) UpdateTitle(post *domain.Post) error {
+	return withEvents(r.db, post.GetID().String(), post, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE posts
+			SET title = ?, last_edited_at = ?
+			WHERE id = ?
+		`,
+			post.Title(),
+			time.Now(),
+			post.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r PostRepository) UpdateContent(post *domain.Post) error {
+	return withEvents(r.db, post.GetID().String(), post, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE posts
+			SET content = ?, last_edited_at = ?
+			WHERE id = ?
+		`,
+			post.Content(),
+			time.Now(),
+			post.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r PostRepository) Archive(post *domain.Post) error {
+	return withEvents(r.db, post.GetID().String(), post, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE posts
+			SET archived_at = ?
+			WHERE id = ?
+		`,
+			post.ArchivedAt(),
+			post.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r PostRepository) Restore(post *domain.Post) error {
+	return withEvents(r.db, post.GetID().String(), post, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE posts
+			SET archived_at = NULL
+			WHERE id = ?
+		`,
+			post.GetID().String(),
+		)
+		return err
+	})
+}
+
+// MarkPopular saves the post as popular. A post that has already been made popular,
+// e.g. by another like handled at the same time, is left as it is without its events
+func (r PostRepository) MarkPopular(post *domain.Post) error {
+	return withEvents(r.db, post.GetID().String(), post, func(tx *sqlx.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE posts
+			SET popular_at = ?
+			WHERE id = ? AND popular_at IS NULL
+		`,
+			post.PopularAt(),
+			post.GetID().String(),
+		)
+		if err != nil {
+			return err
+		}
+
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return errAlreadyChanged
+		}
+		return nil
+	})
+}
+
+// DeleteByAuthor removes the author's posts, along with the comments and ratings left
+// on them and the comments' streams
+func (r PostRepository) DeleteByAuthor(authorID domain.UserID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM events
+		WHERE aggregate_id IN (
+			SELECT c.id FROM comments c
+			JOIN posts p ON p.id = c.post_id
+			WHERE p.author_id = ?
+		)
 	`,
-		newTitle,
-		time.Now(),
-		id.String(),
+		authorID.String(),
 	)
-	return err
-}
+	if err != nil {
+		return err
+	}
 
-func (r PostRepository) UpdateContent(id domain.PostID, newContent string) error {
-	_, err := r.db.Exec(`
-		UPDATE posts
-		SET content = ?, last_edited_at = ?
-		WHERE id = ?
+	_, err = tx.Exec(`
+		DELETE FROM comments
+		WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)
 	`,
-		newContent,
-		time.Now(),
-		id.String(),
+		authorID.String(),
 	)
-	return err
-}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM ratings
+		WHERE post_id IN (SELECT id FROM posts WHERE author_id = ?)
+	`,
+		authorID.String(),
+	)
+	if err != nil {
+		return err
+	}
 
-func (r PostRepository) Archive(id domain.PostID) error {
-	_, err := r.db.Exec(`
-		UPDATE posts
-		SET archived_at = ?
-		WHERE id = ?
+	_, err = tx.Exec(`
+		DELETE FROM posts
+		WHERE author_id = ?
 	`,
-		time.Now(),
-		id.String(),
+		authorID.String(),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func dbPostToDomainPost(dbPost models.Post) *domain.Post {
@@ -133,6 +272,7 @@ func dbPostToDomainPost(dbPost models.Post) *domain.Post {
 		dbPost.CreatedAt,
 		dbPost.LastEditedAt,
 		dbPost.ArchivedAt,
+		dbPost.PopularAt,
 	)
 }
 
@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"testing"
+
+	"blog/internal/domain"
+)
+
+func TestMarkingAPostPopularTwiceAddsOneEvent(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostRepository(db)
+
+	post, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	if _, err := repo.Create(post); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// As if by two likes handled at the same time, each loading the post before the
+	// other saved it
+	var loaded []*domain.Post
+	for range 2 {
+		post, err := repo.FindByID(post.GetID())
+		if err != nil {
+			t.Fatalf("FindByID() failed: %v", err)
+		}
+		loaded = append(loaded, post)
+	}
+	for _, post := range loaded {
+		post.MarkPopular("bob", 10)
+		if err := repo.MarkPopular(post); err != nil {
+			t.Fatalf("MarkPopular() failed: %v", err)
+		}
+	}
+
+	var events int
+	err = db.Get(&events, `
+		SELECT COUNT(*) FROM outbox
+		WHERE aggregate_id = ? AND event_type = ?
+	`, post.GetID().String(), domain.PostBecamePopularEventType.String())
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if events != 1 {
+		t.Errorf("outbox has %d %s events, want 1", events, domain.PostBecamePopularEventType)
+	}
+}
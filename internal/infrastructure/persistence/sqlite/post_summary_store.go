@@ -0,0 +1,350 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostSummaryStore struct {
+	db *sqlx.DB
+}
+
+func NewPostSummaryStore(db *sqlx.DB) *PostSummaryStore {
+	return &PostSummaryStore{
+		db: db,
+	}
+}
+
+func (s PostSummaryStore) All() ([]domain.PostSummary, error) {
+	var dbSummaries []models.PostSummary
+	err := s.db.Select(&dbSummaries, `
+		SELECT * FROM post_summaries
+		ORDER BY last_activity_at DESC, post_id DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []domain.PostSummary{}
+	for _, dbSummary := range dbSummaries {
+		summaries = append(summaries, dbPostSummaryToDomainPostSummary(dbSummary))
+	}
+	return summaries, nil
+}
+
+func (s PostSummaryStore) FindByID(postID domain.PostID) (*domain.PostSummary, error) {
+	var dbSummary models.PostSummary
+	err := s.db.Get(&dbSummary, "SELECT * FROM post_summaries WHERE post_id=?", postID.String())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPostNotFound
+		}
+		return nil, err
+	}
+
+	summary := dbPostSummaryToDomainPostSummary(dbSummary)
+	return &summary, nil
+}
+
+func (s PostSummaryStore) Save(summary *domain.PostSummary) error {
+	return s.recounting(func(tx *sqlx.Tx) ([]string, error) {
+		_, err := tx.Exec(`
+			INSERT INTO post_summaries (
+				post_id, author_id, author_username, title, created_at, last_activity_at,
+				archived_at
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(post_id) DO UPDATE
+			SET author_id = excluded.author_id,
+				author_username = excluded.author_username,
+				title = excluded.title,
+				created_at = excluded.created_at,
+				last_activity_at = excluded.last_activity_at,
+				archived_at = excluded.archived_at
+		`,
+			summary.PostID.String(),
+			summary.AuthorID.String(),
+			summary.AuthorUsername,
+			summary.Title,
+			summary.CreatedAt.UTC(),
+			summary.LastActivityAt.UTC(),
+			utcOrNil(summary.ArchivedAt),
+		)
+		return []string{summary.PostID.String()}, err
+	})
+}
+
+func (s PostSummaryStore) RenameAuthor(authorID domain.UserID, username string) error {
+	_, err := s.db.Exec(
+		"UPDATE post_summaries SET author_username = ? WHERE author_id = ?",
+		username,
+		authorID.String(),
+	)
+	return err
+}
+
+func (s PostSummaryStore) FindComment(
+	commentID domain.CommentID,
+) (*domain.PostSummaryComment, error) {
+	var dbComment models.PostSummaryComment
+	err := s.db.Get(
+		&dbComment,
+		"SELECT * FROM post_summary_comments WHERE comment_id=?",
+		commentID.String(),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	return &domain.PostSummaryComment{
+		CommentID:   domain.NewCommentID(dbComment.CommentID),
+		PostID:      domain.NewPostID(dbComment.PostID),
+		CommenterID: domain.NewUserID(dbComment.CommenterID),
+		Archived:    dbComment.Archived,
+	}, nil
+}
+
+func (s PostSummaryStore) SaveComment(comment domain.PostSummaryComment) error {
+	return s.recounting(func(tx *sqlx.Tx) ([]string, error) {
+		_, err := tx.Exec(`
+			INSERT INTO post_summary_comments (comment_id, post_id, commenter_id, archived)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(comment_id) DO UPDATE
+			SET archived = excluded.archived
+		`,
+			comment.CommentID.String(),
+			comment.PostID.String(),
+			comment.CommenterID.String(),
+			comment.Archived,
+		)
+		return []string{comment.PostID.String()}, err
+	})
+}
+
+func (s PostSummaryStore) FindRating(ratingID domain.RatingID) (*domain.PostSummaryRating, error) {
+	var dbRating models.PostSummaryRating
+	err := s.db.Get(
+		&dbRating,
+		"SELECT * FROM post_summary_ratings WHERE rating_id=?",
+		ratingID.String(),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRatingNotFound
+		}
+		return nil, err
+	}
+
+	return &domain.PostSummaryRating{
+		RatingID:   domain.NewRatingID(dbRating.RatingID),
+		PostID:     domain.NewPostID(dbRating.PostID),
+		UserID:     domain.NewUserID(dbRating.UserID),
+		RatingType: domain.RatingType(dbRating.RatingType),
+	}, nil
+}
+
+func (s PostSummaryStore) SaveRating(rating domain.PostSummaryRating) error {
+	return s.recounting(func(tx *sqlx.Tx) ([]string, error) {
+		_, err := tx.Exec(`
+			INSERT INTO post_summary_ratings (rating_id, post_id, user_id, rating_type)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(rating_id) DO UPDATE
+			SET rating_type = excluded.rating_type
+		`,
+			rating.RatingID.String(),
+			rating.PostID.String(),
+			rating.UserID.String(),
+			rating.RatingType.String(),
+		)
+		return []string{rating.PostID.String()}, err
+	})
+}
+
+func (s PostSummaryStore) DeleteRating(ratingID domain.RatingID) error {
+	return s.recounting(func(tx *sqlx.Tx) ([]string, error) {
+		var postIDs []string
+		err := tx.Select(
+			&postIDs,
+			"SELECT post_id FROM post_summary_ratings WHERE rating_id=?",
+			ratingID.String(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec("DELETE FROM post_summary_ratings WHERE rating_id=?", ratingID.String())
+		return postIDs, err
+	})
+}
+
+func (s PostSummaryStore) DeleteByUser(userID domain.UserID) error {
+	return s.recounting(func(tx *sqlx.Tx) ([]string, error) {
+		// The posts the user commented on or rated, which are recounted once the user's
+		// comments and ratings are gone
+		var postIDs []string
+		err := tx.Select(&postIDs, `
+			SELECT post_id FROM post_summary_comments WHERE commenter_id = ?
+			UNION
+			SELECT post_id FROM post_summary_ratings WHERE user_id = ?
+		`,
+			userID.String(),
+			userID.String(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, query := range []string{
+			`DELETE FROM post_summary_comments
+			WHERE commenter_id = ?1
+			OR post_id IN (SELECT post_id FROM post_summaries WHERE author_id = ?1)`,
+			`DELETE FROM post_summary_ratings
+			WHERE user_id = ?1
+			OR post_id IN (SELECT post_id FROM post_summaries WHERE author_id = ?1)`,
+			"DELETE FROM post_summaries WHERE author_id = ?1",
+		} {
+			if _, err := tx.Exec(query, userID.String()); err != nil {
+				return nil, err
+			}
+		}
+
+		return postIDs, nil
+	})
+}
+
+// seedPostSummaries summarizes every post as it is, along with the comments and ratings
+// on them, as the migration adding the summaries did. The outbox only has the events
+// stored since it was added, so the summaries can't be rebuilt from it alone
+var seedPostSummaries = []string{
+	`INSERT INTO post_summary_comments (comment_id, post_id, commenter_id, archived)
+	SELECT id, post_id, commenter_id, archived_at IS NOT NULL
+	FROM comments`,
+	`INSERT INTO post_summary_ratings (rating_id, post_id, user_id, rating_type)
+	SELECT id, post_id, user_id, rating_type
+	FROM ratings`,
+	`INSERT INTO post_summaries (
+		post_id, author_id, author_username, title, comment_count, likes, dislikes,
+		created_at, last_activity_at, archived_at
+	)
+	SELECT
+		p.id,
+		p.author_id,
+		COALESCE(u.username, ''),
+		p.title,
+		(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.archived_at IS NULL),
+		(SELECT COUNT(*) FROM ratings r WHERE r.post_id = p.id AND r.rating_type = 'like'),
+		(SELECT COUNT(*) FROM ratings r WHERE r.post_id = p.id AND r.rating_type = 'dislike'),
+		p.created_at,
+		MAX(
+			p.created_at,
+			COALESCE(p.last_edited_at, p.created_at),
+			COALESCE((SELECT MAX(c.created_at) FROM comments c WHERE c.post_id = p.id), p.created_at),
+			COALESCE(
+				(SELECT MAX(COALESCE(r.updated_at, r.created_at)) FROM ratings r WHERE r.post_id = p.id),
+				p.created_at
+			)
+		),
+		p.archived_at
+	FROM posts p
+	LEFT JOIN users u ON u.id = p.author_id`,
+}
+
+// Reset summarizes every post again from the posts, comments and ratings, returning the
+// position of the latest event in the outbox. The summaries reflect every event stored
+// by then, as the events are stored in the same transaction as the changes they're for
+func (s PostSummaryStore) Reset() (int64, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{
+		"post_summaries",
+		"post_summary_comments",
+		"post_summary_ratings",
+	} {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, query := range seedPostSummaries {
+		if _, err := tx.Exec(query); err != nil {
+			return 0, err
+		}
+	}
+
+	var position int64
+	if err := tx.Get(&position, "SELECT COALESCE(MAX(id), 0) FROM outbox"); err != nil {
+		return 0, err
+	}
+
+	return position, tx.Commit()
+}
+
+// recounting runs fn in a transaction, then recounts the comments and ratings of the
+// posts it returns before committing
+func (s PostSummaryStore) recounting(fn func(tx *sqlx.Tx) ([]string, error)) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	postIDs, err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, postID := range postIDs {
+		_, err := tx.Exec(`
+			UPDATE post_summaries
+			SET comment_count = (
+					SELECT COUNT(*) FROM post_summary_comments c
+					WHERE c.post_id = post_summaries.post_id AND c.archived = 0
+				),
+				likes = (
+					SELECT COUNT(*) FROM post_summary_ratings r
+					WHERE r.post_id = post_summaries.post_id AND r.rating_type = ?
+				),
+				dislikes = (
+					SELECT COUNT(*) FROM post_summary_ratings r
+					WHERE r.post_id = post_summaries.post_id AND r.rating_type = ?
+				)
+			WHERE post_id = ?
+		`,
+			domain.RatingTypeLike.String(),
+			domain.RatingTypeDislike.String(),
+			postID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func dbPostSummaryToDomainPostSummary(dbSummary models.PostSummary) domain.PostSummary {
+	return domain.PostSummary{
+		PostID:         domain.NewPostID(dbSummary.PostID),
+		AuthorID:       domain.NewUserID(dbSummary.AuthorID),
+		AuthorUsername: dbSummary.AuthorUsername,
+		Title:          dbSummary.Title,
+		CommentCount:   dbSummary.CommentCount,
+		Likes:          dbSummary.Likes,
+		Dislikes:       dbSummary.Dislikes,
+		CreatedAt:      dbSummary.CreatedAt,
+		LastActivityAt: dbSummary.LastActivityAt,
+		ArchivedAt:     dbSummary.ArchivedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/pkg/ddd"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// storeTestPostBeforeOutbox adds a post by alice, with a comment and a like, without
+// adding their events to the outbox, as posts made before it was added were
+func storeTestPostBeforeOutbox(t *testing.T, db *sqlx.DB) domain.PostID {
+	t.Helper()
+
+	createdAt := time.Now().Add(-time.Hour).UTC()
+	for _, query := range []string{
+		`INSERT INTO posts (id, author_id, title, content, created_at)
+		VALUES ('old-post', 'alice', 'Old title', 'Content', ?1)`,
+		`INSERT INTO comments (id, post_id, commenter_id, content, created_at)
+		VALUES ('old-comment', 'old-post', 'bob', 'Nice post', ?1)`,
+		`INSERT INTO ratings (id, post_id, user_id, rating_type, created_at)
+		VALUES ('old-rating', 'old-post', 'carol', 'like', ?1)`,
+	} {
+		if _, err := db.Exec(query, createdAt); err != nil {
+			t.Fatalf("Exec() failed: %v", err)
+		}
+	}
+	return "old-post"
+}
+
+func TestRebuildingPostSummariesKeepsPostsFromBeforeTheOutbox(t *testing.T) {
+	db := newTestDB(t)
+	postRepo := NewPostRepository(db)
+	outbox := NewOutboxStore(db)
+	projector := ddd.NewProjector(outbox, NewCheckpointStore(db), 10)
+	projector.Register(application.NewPostSummaryProjection(
+		NewPostSummaryStore(db),
+		postRepo,
+		NewUserRepository(db),
+	))
+
+	oldPostID := storeTestPostBeforeOutbox(t, db)
+	newPost, err := domain.NewPost("alice", "Title", "Content")
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	if _, err := postRepo.Create(newPost); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// Everything in the outbox is already in the summaries the rebuild starts from
+	projected, err := projector.Rebuild(
+		context.Background(),
+		application.PostSummariesProjection,
+		time.Now(),
+	)
+	if err != nil {
+		t.Fatalf("Rebuild() failed: %v", err)
+	}
+	if projected != 0 {
+		t.Errorf("Rebuild() projected %d events, want 0", projected)
+	}
+
+	summaries := NewPostSummaryStore(db)
+	old, err := summaries.FindByID(oldPostID)
+	if err != nil {
+		t.Fatalf("FindByID() failed for the post from before the outbox: %v", err)
+	}
+	if old.Title != "Old title" || old.CommentCount != 1 || old.Likes != 1 {
+		t.Errorf("summary = %+v, want its title, comment and like", old)
+	}
+	if _, err := summaries.FindByID(newPost.GetID()); err != nil {
+		t.Errorf("FindByID() failed for the post in the outbox: %v", err)
+	}
+
+	statuses, err := projector.Status()
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	if got := statuses[0].Checkpoint.Position; got != statuses[0].Head {
+		t.Errorf("checkpoint is at %d, want the outbox's latest event at %d", got, statuses[0].Head)
+	}
+
+	// Events stored after the rebuild are projected as usual
+	if err := newPost.EditTitle("New title"); err != nil {
+		t.Fatalf("EditTitle() failed: %v", err)
+	}
+	if err := postRepo.UpdateTitle(newPost); err != nil {
+		t.Fatalf("UpdateTitle() failed: %v", err)
+	}
+	if projected, err := projector.Run(context.Background(), time.Now()); err != nil || projected != 1 {
+		t.Fatalf("Run() = %d, %v, want 1 event projected", projected, err)
+	}
+	if got, err := summaries.FindByID(newPost.GetID()); err != nil || got.Title != "New title" {
+		t.Errorf("FindByID() = %+v, %v, want the new title", got, err)
+	}
+}
@@ -94,17 +94,20 @@ func (r *RatingRepository) ExistsOnPostByUser(
 }
 
 func (r *RatingRepository) Create(rating *domain.Rating) (*domain.Rating, error) {
-	_, err := r.db.Exec(`
-		INSERT INTO 
-		ratings (id, post_id, user_id, rating_type, created_at) 
-		VALUES (?, ?, ?, ?, ?)
-	`,
-		rating.GetID().String(),
-		rating.PostID().String(),
-		rating.UserID().String(),
-		string(rating.RatingType()),
-		rating.CreatedAt(),
-	)
+	err := withEvents(r.db, rating.GetID().String(), rating, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO 
+			ratings (id, post_id, user_id, rating_type, created_at) 
+			VALUES (?, ?, ?, ?, ?)
+		`,
+			rating.GetID().String(),
+			rating.PostID().String(),
+			rating.UserID().String(),
+			string(rating.RatingType()),
+			rating.CreatedAt(),
+		)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -112,24 +115,38 @@ func (r *RatingRepository) Create(rating *domain.Rating) (*domain.Rating, error)
 	return rating, nil
 }
 
-func (r *RatingRepository) ChangeRating(id domain.RatingID, newRatingType domain.RatingType) error {
-	_, err := r.db.Exec(`
-		UPDATE ratings
-		SET rating_type = ?
-		WHERE id = ?
-	`,
-		string(newRatingType),
-		id.String(),
-	)
-	return err
+func (r *RatingRepository) ChangeRating(rating *domain.Rating) error {
+	return withEvents(r.db, rating.GetID().String(), rating, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE ratings
+			SET rating_type = ?
+			WHERE id = ?
+		`,
+			string(rating.RatingType()),
+			rating.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r *RatingRepository) RemoveRating(rating *domain.Rating) error {
+	return withEvents(r.db, rating.GetID().String(), rating, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM ratings
+			WHERE id = ?
+		`,
+			rating.GetID().String(),
+		)
+		return err
+	})
 }
 
-func (r *RatingRepository) RemoveRating(id domain.RatingID) error {
+func (r *RatingRepository) DeleteByUser(userID domain.UserID) error {
 	_, err := r.db.Exec(`
 		DELETE FROM ratings
-		WHERE id = ?
+		WHERE user_id = ?
 	`,
-		id.String(),
+		userID.String(),
 	)
 	return err
 }
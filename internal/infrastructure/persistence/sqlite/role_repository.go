@@ -0,0 +1,200 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+func (r RoleRepository) All() ([]domain.Role, error) {
+	var dbRoles []models.Role
+	err := r.db.Select(&dbRoles, "SELECT * FROM roles ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+
+	var dbPermissions []models.RolePermission
+	err = r.db.Select(&dbPermissions, "SELECT * FROM role_permissions")
+	if err != nil {
+		return nil, err
+	}
+
+	roles := dbRolesToDomainRoles(dbRoles, dbPermissions)
+	return roles, nil
+}
+
+func (r RoleRepository) FindByName(name domain.UserRole) (*domain.Role, error) {
+	var dbRole models.Role
+	err := r.db.Get(&dbRole, "SELECT * FROM roles WHERE name=?", name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	var dbPermissions []models.RolePermission
+	err = r.db.Select(&dbPermissions, "SELECT * FROM role_permissions WHERE role_name=?", name)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := dbRolesToDomainRoles([]models.Role{dbRole}, dbPermissions)
+	return &roles[0], nil
+}
+
+func (r RoleRepository) FindByNames(names []domain.UserRole) ([]domain.Role, error) {
+	if len(names) == 0 {
+		return []domain.Role{}, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM roles WHERE name IN (?) ORDER BY name", names)
+	if err != nil {
+		return nil, err
+	}
+
+	var dbRoles []models.Role
+	if err := r.db.Select(&dbRoles, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	query, args, err = sqlx.In("SELECT * FROM role_permissions WHERE role_name IN (?)", names)
+	if err != nil {
+		return nil, err
+	}
+
+	var dbPermissions []models.RolePermission
+	if err := r.db.Select(&dbPermissions, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	roles := dbRolesToDomainRoles(dbRoles, dbPermissions)
+	return roles, nil
+}
+
+func (r RoleRepository) Exists(name domain.UserRole) (bool, error) {
+	var count int
+	err := r.db.Get(&count, "SELECT COUNT(*) FROM roles WHERE name=?", name)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r RoleRepository) InUse(name domain.UserRole) (bool, error) {
+	var count int
+	err := r.db.Get(&count, "SELECT COUNT(*) FROM user_roles WHERE role_name=?", name)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r RoleRepository) Create(role *domain.Role) (*domain.Role, error) {
+	err := withEvents(r.db, role.Name().String(), role, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO roles (name, description, built_in, created_at)
+			VALUES (?, ?, ?, ?)
+		`,
+			role.Name().String(),
+			role.Description(),
+			role.BuiltIn(),
+			role.CreatedAt(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return insertRolePermissions(tx, role.Name(), role.Permissions())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r RoleRepository) UpdatePermissions(role *domain.Role) error {
+	return withEvents(r.db, role.Name().String(), role, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(
+			"DELETE FROM role_permissions WHERE role_name=?",
+			role.Name(),
+		); err != nil {
+			return err
+		}
+
+		return insertRolePermissions(tx, role.Name(), role.Permissions())
+	})
+}
+
+func (r RoleRepository) Delete(role *domain.Role) error {
+	return withEvents(r.db, role.Name().String(), role, func(tx *sqlx.Tx) error {
+		// Foreign keys aren't enforced by default, so clean up explicitly
+		if _, err := tx.Exec(
+			"DELETE FROM role_permissions WHERE role_name=?",
+			role.Name(),
+		); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec("DELETE FROM roles WHERE name=?", role.Name())
+		return err
+	})
+}
+
+func insertRolePermissions(
+	tx *sqlx.Tx,
+	name domain.UserRole,
+	permissions []domain.Permission,
+) error {
+	for _, permission := range permissions {
+		_, err := tx.Exec(
+			"INSERT INTO role_permissions (role_name, permission) VALUES (?, ?)",
+			name.String(),
+			permission.String(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dbRolesToDomainRoles(
+	dbRoles []models.Role,
+	dbPermissions []models.RolePermission,
+) []domain.Role {
+	permissionsByRole := map[string][]domain.Permission{}
+	for _, dbPermission := range dbPermissions {
+		permissionsByRole[dbPermission.RoleName] = append(
+			permissionsByRole[dbPermission.RoleName],
+			domain.Permission(dbPermission.Permission),
+		)
+	}
+
+	roles := []domain.Role{}
+	for _, dbRole := range dbRoles {
+		roles = append(roles, *domain.RebuildRole(
+			domain.UserRole(dbRole.Name),
+			dbRole.Description,
+			permissionsByRole[dbRole.Name],
+			dbRole.BuiltIn,
+			dbRole.CreatedAt,
+		))
+	}
+	return roles
+}
@@ -0,0 +1,234 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"blog/internal/infrastructure/persistence/models"
+	"blog/pkg/ddd/services"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SagaStore struct {
+	db *sqlx.DB
+}
+
+func NewSagaStore(db *sqlx.DB) *SagaStore {
+	return &SagaStore{
+		db: db,
+	}
+}
+
+func (s SagaStore) Create(state services.SagaState) error {
+	dbSaga, err := sagaStateToDBSaga(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO sagas (
+			id, saga_type, correlation_key, status, completed_steps, compensated_steps,
+			data, metadata, attempts, last_error, timeout_at, started_at, updated_at, version
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		dbSaga.ID,
+		dbSaga.SagaType,
+		dbSaga.CorrelationKey,
+		dbSaga.Status,
+		dbSaga.CompletedSteps,
+		dbSaga.CompensatedSteps,
+		dbSaga.Data,
+		dbSaga.Metadata,
+		dbSaga.Attempts,
+		dbSaga.LastError,
+		dbSaga.TimeoutAt,
+		dbSaga.StartedAt,
+		dbSaga.UpdatedAt,
+		dbSaga.Version,
+	)
+	if isUniqueViolation(err) {
+		return services.ErrSagaExists
+	}
+	return err
+}
+
+func (s SagaStore) FindByID(id string) (services.SagaState, error) {
+	return s.get("SELECT * FROM sagas WHERE id=?", id)
+}
+
+func (s SagaStore) FindByCorrelation(sagaType, correlationKey string) (services.SagaState, error) {
+	return s.get(
+		"SELECT * FROM sagas WHERE saga_type=? AND correlation_key=?",
+		sagaType,
+		correlationKey,
+	)
+}
+
+func (s SagaStore) Update(state services.SagaState) (services.SagaState, error) {
+	dbSaga, err := sagaStateToDBSaga(state)
+	if err != nil {
+		return services.SagaState{}, err
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE sagas
+		SET status = ?, completed_steps = ?, compensated_steps = ?, data = ?, attempts = ?,
+			last_error = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ?
+	`,
+		dbSaga.Status,
+		dbSaga.CompletedSteps,
+		dbSaga.CompensatedSteps,
+		dbSaga.Data,
+		dbSaga.Attempts,
+		dbSaga.LastError,
+		dbSaga.UpdatedAt,
+		dbSaga.ID,
+		dbSaga.Version,
+	)
+	if err != nil {
+		return services.SagaState{}, err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return services.SagaState{}, err
+	}
+	if updated == 0 {
+		if _, err := s.FindByID(state.ID); err != nil {
+			return services.SagaState{}, err
+		}
+		return services.SagaState{}, services.ErrSagaConflict
+	}
+
+	state.Version++
+	return state, nil
+}
+
+func (s SagaStore) FindUnfinished(idleSince time.Time) ([]services.SagaState, error) {
+	return s.selectSagas(`
+		SELECT * FROM sagas
+		WHERE status IN (?, ?) AND updated_at < ?
+		ORDER BY started_at, id
+	`,
+		services.SagaStatusRunning.String(),
+		services.SagaStatusCompensating.String(),
+		idleSince.UTC(),
+	)
+}
+
+func (s SagaStore) FindTimedOut(at time.Time) ([]services.SagaState, error) {
+	return s.selectSagas(`
+		SELECT * FROM sagas
+		WHERE status = ? AND timeout_at IS NOT NULL AND timeout_at <= ?
+		ORDER BY started_at, id
+	`,
+		services.SagaStatusRunning.String(),
+		at.UTC(),
+	)
+}
+
+func (s SagaStore) get(query string, args ...any) (services.SagaState, error) {
+	var dbSaga models.Saga
+	if err := s.db.Get(&dbSaga, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return services.SagaState{}, services.ErrSagaNotFound
+		}
+		return services.SagaState{}, err
+	}
+
+	return dbSagaToSagaState(dbSaga)
+}
+
+func (s SagaStore) selectSagas(query string, args ...any) ([]services.SagaState, error) {
+	var dbSagas []models.Saga
+	if err := s.db.Select(&dbSagas, query, args...); err != nil {
+		return nil, err
+	}
+
+	sagas := []services.SagaState{}
+	for _, dbSaga := range dbSagas {
+		saga, err := dbSagaToSagaState(dbSaga)
+		if err != nil {
+			return nil, err
+		}
+		sagas = append(sagas, saga)
+	}
+	return sagas, nil
+}
+
+func sagaStateToDBSaga(state services.SagaState) (models.Saga, error) {
+	completedSteps, err := json.Marshal(state.CompletedSteps)
+	if err != nil {
+		return models.Saga{}, err
+	}
+	compensatedSteps, err := json.Marshal(state.CompensatedSteps)
+	if err != nil {
+		return models.Saga{}, err
+	}
+	data, err := json.Marshal(state.Data)
+	if err != nil {
+		return models.Saga{}, err
+	}
+	metadata, err := encodeEventMetadata(state.Metadata)
+	if err != nil {
+		return models.Saga{}, err
+	}
+
+	var timeoutAt *time.Time
+	if !state.TimeoutAt.IsZero() {
+		timeoutAt = utcOrNil(&state.TimeoutAt)
+	}
+
+	return models.Saga{
+		ID:               state.ID,
+		SagaType:         state.SagaType,
+		CorrelationKey:   state.CorrelationKey,
+		Status:           state.Status.String(),
+		CompletedSteps:   string(completedSteps),
+		CompensatedSteps: string(compensatedSteps),
+		Data:             string(data),
+		Metadata:         metadata,
+		Attempts:         state.Attempts,
+		LastError:        state.LastError,
+		TimeoutAt:        timeoutAt,
+		StartedAt:        state.StartedAt.UTC(),
+		UpdatedAt:        state.UpdatedAt.UTC(),
+		Version:          state.Version,
+	}, nil
+}
+
+// dbSagaToSagaState fails on steps or data that can't be read, unlike metadata, as the
+// saga couldn't carry on without them
+func dbSagaToSagaState(dbSaga models.Saga) (services.SagaState, error) {
+	state := services.SagaState{
+		ID:             dbSaga.ID,
+		SagaType:       dbSaga.SagaType,
+		CorrelationKey: dbSaga.CorrelationKey,
+		Status:         services.SagaStatus(dbSaga.Status),
+		Metadata:       decodeEventMetadata(dbSaga.Metadata),
+		Attempts:       dbSaga.Attempts,
+		LastError:      dbSaga.LastError,
+		StartedAt:      dbSaga.StartedAt,
+		UpdatedAt:      dbSaga.UpdatedAt,
+		Version:        dbSaga.Version,
+	}
+	if dbSaga.TimeoutAt != nil {
+		state.TimeoutAt = *dbSaga.TimeoutAt
+	}
+
+	if err := json.Unmarshal([]byte(dbSaga.CompletedSteps), &state.CompletedSteps); err != nil {
+		return services.SagaState{}, err
+	}
+	if err := json.Unmarshal([]byte(dbSaga.CompensatedSteps), &state.CompensatedSteps); err != nil {
+		return services.SagaState{}, err
+	}
+	if err := json.Unmarshal([]byte(dbSaga.Data), &state.Data); err != nil {
+		return services.SagaState{}, err
+	}
+	return state, nil
+}
@@ -0,0 +1,157 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/ddd/services"
+
+	"go.uber.org/zap"
+)
+
+// newTestSaga is a running saga of the type for the key, last saved at the given time
+func newTestSaga(sagaType, key string, updatedAt time.Time) services.SagaState {
+	return services.SagaState{
+		ID:               sagaType + "-" + key,
+		SagaType:         sagaType,
+		CorrelationKey:   key,
+		Status:           services.SagaStatusRunning,
+		CompletedSteps:   []string{},
+		CompensatedSteps: []string{},
+		Data:             map[string]string{"key": key},
+		StartedAt:        updatedAt,
+		UpdatedAt:        updatedAt,
+	}
+}
+
+func TestSagaStoreCatchesConcurrentUpdates(t *testing.T) {
+	store := NewSagaStore(newTestDB(t))
+
+	saga := newTestSaga("order", "order", time.Now())
+	if err := store.Create(saga); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	err := store.Create(newTestSaga("order", "order", time.Now()))
+	if !errors.Is(err, services.ErrSagaExists) {
+		t.Errorf("Create() for the same key error = %v, want %v", err, services.ErrSagaExists)
+	}
+
+	loaded, err := store.FindByCorrelation("order", "order")
+	if err != nil {
+		t.Fatalf("FindByCorrelation() failed: %v", err)
+	}
+	loaded.CompletedSteps = append(loaded.CompletedSteps, "reserve")
+	saved, err := store.Update(loaded)
+	if err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if saved.Version != 1 {
+		t.Errorf("saved version = %d, want 1", saved.Version)
+	}
+
+	// The saga was loaded at the version before the save
+	if _, err := store.Update(saga); !errors.Is(err, services.ErrSagaConflict) {
+		t.Errorf("Update() of a stale saga error = %v, want %v", err, services.ErrSagaConflict)
+	}
+	unknown := newTestSaga("order", "unknown", time.Now())
+	if _, err := store.Update(unknown); !errors.Is(err, services.ErrSagaNotFound) {
+		t.Errorf("Update() of an unknown saga error = %v, want %v", err, services.ErrSagaNotFound)
+	}
+
+	stored, err := store.FindByID(saga.ID)
+	if err != nil {
+		t.Fatalf("FindByID() failed: %v", err)
+	}
+	if stored.Version != 1 || !slices.Equal(stored.CompletedSteps, []string{"reserve"}) ||
+		stored.Data["key"] != "order" {
+		t.Errorf("stored saga = %+v, want the saved one", stored)
+	}
+}
+
+func TestSagaStoreFindsUnfinishedSagasLeftAlone(t *testing.T) {
+	store := NewSagaStore(newTestDB(t))
+
+	now := time.Now()
+	idle := newTestSaga("order", "idle", now.Add(-2*time.Hour))
+	busy := newTestSaga("order", "busy", now)
+	completed := newTestSaga("order", "completed", now.Add(-2*time.Hour))
+	completed.Status = services.SagaStatusCompleted
+	for _, saga := range []services.SagaState{idle, busy, completed} {
+		if err := store.Create(saga); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+
+	unfinished, err := store.FindUnfinished(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("FindUnfinished() failed: %v", err)
+	}
+	if len(unfinished) != 1 || unfinished[0].ID != idle.ID {
+		t.Errorf("FindUnfinished() = %+v, want only the saga left alone", unfinished)
+	}
+
+	unfinished, err = store.FindUnfinished(now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("FindUnfinished() failed: %v", err)
+	}
+	if len(unfinished) != 2 {
+		t.Errorf("FindUnfinished() = %d sagas, want the 2 unfinished", len(unfinished))
+	}
+}
+
+func TestSagaManagerResumesEachSagaInTheStoreOnce(t *testing.T) {
+	store := NewSagaStore(newTestDB(t))
+	dispatcher := dddmemory.NewInMemoryEventDispatcher(zap.NewNop().Sugar())
+	manager := services.NewSagaManager(store, dispatcher)
+
+	var ran []string
+	var steps []services.SagaStep
+	for _, name := range []string{"reserve", "charge"} {
+		steps = append(steps, services.SagaStep{
+			Name: name,
+			Execute: func(ctx context.Context, state *services.SagaState) error {
+				ran = append(ran, name)
+				return nil
+			},
+		})
+	}
+	manager.Register(services.SagaDefinition{
+		Type:      "order",
+		StartedBy: "OrderPlaced",
+		Correlate: func(event ddd.DomainEvent) (string, map[string]string) {
+			return "", nil
+		},
+		Steps:       steps,
+		MaxAttempts: 3,
+	})
+
+	// As if interrupted after reserving, an hour ago
+	saga := newTestSaga("order", "order", time.Now().Add(-time.Hour))
+	saga.CompletedSteps = []string{"reserve"}
+	if err := store.Create(saga); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	for _, want := range []int{1, 0} {
+		resumed, err := manager.Resume(context.Background(), time.Now().Add(-time.Minute))
+		if err != nil || resumed != want {
+			t.Fatalf("Resume() = %d, %v, want %d sagas carried on", resumed, err, want)
+		}
+	}
+	if !slices.Equal(ran, []string{"charge"}) {
+		t.Errorf("ran %v, want only the step left", ran)
+	}
+
+	stored, err := store.FindByID(saga.ID)
+	if err != nil {
+		t.Fatalf("FindByID() failed: %v", err)
+	}
+	if stored.Status != services.SagaStatusCompleted {
+		t.Errorf("saga status = %s, want %s", stored.Status, services.SagaStatusCompleted)
+	}
+}
@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"blog/internal/domain"
+	"blog/internal/infrastructure/persistence/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+func (r SessionRepository) FindByID(id domain.SessionID) (*domain.Session, error) {
+	var dbSession models.Session
+	err := r.db.Get(
+		&dbSession,
+		"SELECT * FROM sessions WHERE id=? AND expiry > ?",
+		id,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	session := dbSessionToDomainSession(dbSession)
+	return session, nil
+}
+
+func (r SessionRepository) FindByToken(token string) (*domain.Session, error) {
+	var dbSession models.Session
+	err := r.db.Get(
+		&dbSession,
+		"SELECT * FROM sessions WHERE token=? AND id IS NOT NULL AND expiry > ?",
+		token,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	session := dbSessionToDomainSession(dbSession)
+	return session, nil
+}
+
+func (r SessionRepository) FindByUser(userID domain.UserID) ([]domain.Session, error) {
+	var dbSessions []models.Session
+	err := r.db.Select(
+		&dbSessions,
+		"SELECT * FROM sessions WHERE user_id=? AND expiry > ? ORDER BY last_seen_at DESC",
+		userID,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := dbSessionsToDomainSessions(dbSessions)
+	return sessions, nil
+}
+
+// Create attaches the session metadata to the session token. The token row is
+// created here if the session manager hasn't committed it yet, in which case
+// the data is filled in on the session manager's next commit.
+func (r SessionRepository) Create(token string, session *domain.Session) (*domain.Session, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO
+		sessions (token, data, expiry, id, user_id, impersonator_id, user_agent, device, ip_address, created_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(token) DO UPDATE
+		SET id = excluded.id,
+			user_id = excluded.user_id,
+			impersonator_id = excluded.impersonator_id,
+			user_agent = excluded.user_agent,
+			device = excluded.device,
+			ip_address = excluded.ip_address,
+			created_at = excluded.created_at,
+			last_seen_at = excluded.last_seen_at
+	`,
+		token,
+		[]byte{},
+		session.ExpiresAt().UTC(),
+		session.ID().String(),
+		session.UserID().String(),
+		session.ImpersonatorID().String(),
+		session.UserAgent(),
+		session.Device(),
+		session.IPAddress(),
+		session.CreatedAt().UTC(),
+		session.LastSeenAt().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (r SessionRepository) Revoke(id domain.SessionID) error {
+	_, err := r.db.Exec("DELETE FROM sessions WHERE id = ?", id.String())
+	return err
+}
+
+func (r SessionRepository) RevokeAllForUser(userID domain.UserID) error {
+	_, err := r.db.Exec("DELETE FROM sessions WHERE user_id = ?", userID.String())
+	return err
+}
+
+func dbSessionToDomainSession(dbSession models.Session) *domain.Session {
+	var id, userID string
+	if dbSession.ID != nil {
+		id = *dbSession.ID
+	}
+	if dbSession.UserID != nil {
+		userID = *dbSession.UserID
+	}
+
+	return domain.RebuildSession(
+		domain.NewSessionID(id),
+		domain.NewUserID(userID),
+		domain.NewUserID(dbSession.ImpersonatorID),
+		dbSession.UserAgent,
+		dbSession.Device,
+		dbSession.IPAddress,
+		dbSession.CreatedAt,
+		dbSession.LastSeenAt,
+		dbSession.Expiry,
+	)
+}
+
+func dbSessionsToDomainSessions(dbSessions []models.Session) []domain.Session {
+	sessions := []domain.Session{}
+	for _, session := range dbSessions {
+		sessions = append(sessions, *dbSessionToDomainSession(session))
+	}
+	return sessions
+}
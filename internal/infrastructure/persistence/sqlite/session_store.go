@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SessionStore is an scs.Store backed by the sessions table, so sessions
+// survive restarts and can be listed per user.
+type SessionStore struct {
+	db          *sqlx.DB
+	stopCleanup chan bool
+}
+
+// NewSessionStore creates a session store and starts a background goroutine
+// that deletes expired sessions every cleanupInterval. Passing 0 disables the
+// cleanup goroutine.
+func NewSessionStore(db *sqlx.DB, cleanupInterval time.Duration) *SessionStore {
+	s := &SessionStore{
+		db: db,
+	}
+
+	if cleanupInterval > 0 {
+		s.stopCleanup = make(chan bool)
+		go s.startCleanup(cleanupInterval)
+	}
+
+	return s
+}
+
+func (s *SessionStore) Find(token string) ([]byte, bool, error) {
+	var data []byte
+	err := s.db.Get(
+		&data,
+		"SELECT data FROM sessions WHERE token=? AND expiry > ?",
+		token,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+func (s *SessionStore) Commit(token string, b []byte, expiry time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO sessions (token, data, expiry, last_seen_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(token) DO UPDATE
+		SET data = excluded.data, expiry = excluded.expiry, last_seen_at = excluded.last_seen_at
+	`,
+		token,
+		b,
+		expiry.UTC(),
+		time.Now().UTC(),
+	)
+	return err
+}
+
+func (s *SessionStore) Delete(token string) error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE token=?", token)
+	return err
+}
+
+func (s *SessionStore) All() (map[string][]byte, error) {
+	var rows []struct {
+		Token string `db:"token"`
+		Data  []byte `db:"data"`
+	}
+	err := s.db.Select(
+		&rows,
+		"SELECT token, data FROM sessions WHERE expiry > ?",
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[string][]byte, len(rows))
+	for _, row := range rows {
+		sessions[row.Token] = row.Data
+	}
+
+	return sessions, nil
+}
+
+// StopCleanup terminates the background cleanup goroutine
+func (s *SessionStore) StopCleanup() {
+	if s.stopCleanup != nil {
+		s.stopCleanup <- true
+	}
+}
+
+func (s *SessionStore) startCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.deleteExpired(); err != nil {
+				log.Printf("SessionStore: failed to delete expired sessions: %v", err)
+			}
+		case <-s.stopCleanup:
+			return
+		}
+	}
+}
+
+func (s *SessionStore) deleteExpired() error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE expiry <= ?", time.Now().UTC())
+	return err
+}
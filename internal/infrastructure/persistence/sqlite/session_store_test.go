@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"blog/internal/domain"
+)
+
+func newTestSession(
+	t *testing.T,
+	repo *SessionRepository,
+	token string,
+	userID domain.UserID,
+) *domain.Session {
+	t.Helper()
+
+	session := domain.NewSession(
+		userID,
+		"curl/8.0",
+		"curl on unknown OS",
+		"10.0.0.1",
+		time.Now().Add(time.Hour),
+	)
+	if _, err := repo.Create(token, session); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	return session
+}
+
+func TestRevokingASessionKeepsTheUsersOthers(t *testing.T) {
+	db := newTestDB(t)
+	store := NewSessionStore(db, 0)
+	repo := NewSessionRepository(db)
+
+	alice := domain.NewUserID("alice")
+	laptop := newTestSession(t, repo, "laptop", alice)
+	newTestSession(t, repo, "phone", alice)
+	if err := store.Commit("laptop", []byte("data"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+
+	if err := repo.Revoke(laptop.ID()); err != nil {
+		t.Fatalf("Revoke() failed: %v", err)
+	}
+
+	// The session manager no longer finds the revoked token
+	if _, found, err := store.Find("laptop"); err != nil || found {
+		t.Errorf("Find() of a revoked token = %v, %v, want false, nil", found, err)
+	}
+	if _, err := repo.FindByID(laptop.ID()); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("FindByID() of a revoked session error = %v, want %v",
+			err, domain.ErrSessionNotFound)
+	}
+
+	sessions, err := repo.FindByUser(alice)
+	if err != nil {
+		t.Fatalf("FindByUser() failed: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("FindByUser() = %d sessions, want 1", len(sessions))
+	}
+}
+
+func TestRevokingAllSessionsKeepsOtherUsers(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSessionRepository(db)
+
+	alice := domain.NewUserID("alice")
+	bob := domain.NewUserID("bob")
+	newTestSession(t, repo, "laptop", alice)
+	newTestSession(t, repo, "phone", alice)
+	newTestSession(t, repo, "desktop", bob)
+
+	if err := repo.RevokeAllForUser(alice); err != nil {
+		t.Fatalf("RevokeAllForUser() failed: %v", err)
+	}
+
+	for userID, want := range map[domain.UserID]int{alice: 0, bob: 1} {
+		sessions, err := repo.FindByUser(userID)
+		if err != nil {
+			t.Fatalf("FindByUser() failed: %v", err)
+		}
+		if len(sessions) != want {
+			t.Errorf("FindByUser(%s) = %d sessions, want %d", userID, len(sessions), want)
+		}
+	}
+}
+
+func TestSessionStoreCleansUpExpiredSessions(t *testing.T) {
+	db := newTestDB(t)
+	store := NewSessionStore(db, 10*time.Millisecond)
+	defer store.StopCleanup()
+
+	if err := store.Commit("expired", []byte("data"), time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	if err := store.Commit("live", []byte("data"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+
+	// An expired session is never found, even before it's cleaned up
+	if _, found, err := store.Find("expired"); err != nil || found {
+		t.Errorf("Find() of an expired token = %v, %v, want false, nil", found, err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var tokens []string
+		if err := db.Select(&tokens, "SELECT token FROM sessions ORDER BY token"); err != nil {
+			t.Fatalf("Select() failed: %v", err)
+		}
+		if len(tokens) == 1 && tokens[0] == "live" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sessions left = %v, want [live]", tokens)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -2,13 +2,15 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
-	"strings"
+	"time"
 
 	"blog/internal/domain"
 	"blog/internal/infrastructure/persistence/models"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
 )
 
 type UserRepository struct {
@@ -28,7 +30,13 @@ func (r UserRepository) All() ([]domain.User, error) {
 		return nil, err
 	}
 
-	users := dbUsersToDomainUsers(dbUsers)
+	var dbUserRoles []models.UserRole
+	err = r.db.Select(&dbUserRoles, "SELECT * FROM user_roles")
+	if err != nil {
+		return nil, err
+	}
+
+	users := dbUsersToDomainUsers(dbUsers, dbUserRoles)
 	return users, nil
 }
 
@@ -42,8 +50,7 @@ func (r UserRepository) FindByID(id domain.UserID) (*domain.User, error) {
 		return nil, err
 	}
 
-	user := dbUserToDomainUser(dbUser)
-	return user, nil
+	return r.withRoles(dbUser)
 }
 
 func (r UserRepository) FindByEmail(email string) (*domain.User, error) {
@@ -56,8 +63,7 @@ func (r UserRepository) FindByEmail(email string) (*domain.User, error) {
 		return nil, err
 	}
 
-	user := dbUserToDomainUser(dbUser)
-	return user, nil
+	return r.withRoles(dbUser)
 }
 
 func (r UserRepository) FindByUsername(username string) (*domain.User, error) {
@@ -70,8 +76,7 @@ func (r UserRepository) FindByUsername(username string) (*domain.User, error) {
 		return nil, err
 	}
 
-	user := dbUserToDomainUser(dbUser)
-	return user, nil
+	return r.withRoles(dbUser)
 }
 
 func (r UserRepository) Exists(id domain.UserID) (bool, error) {
@@ -85,7 +90,17 @@ func (r UserRepository) Exists(id domain.UserID) (bool, error) {
 
 func (r UserRepository) UsernameExists(username string) (bool, error) {
 	var count int
-	err := r.db.Get(&count, "SELECT COUNT(*) FROM users WHERE username=?", username)
+	err := r.db.Get(&count, `
+		SELECT
+			(SELECT COUNT(*) FROM users WHERE username = ?) +
+			(SELECT COUNT(*) FROM identity_changes
+				WHERE kind = ? AND old_value = ? AND reserved_until > ?)
+	`,
+		username,
+		domain.IdentityKindUsername.String(),
+		username,
+		time.Now().UTC(),
+	)
 	if err != nil {
 		return false, err
 	}
@@ -102,21 +117,25 @@ func (r UserRepository) EmailExists(email string) (bool, error) {
 }
 
 func (r UserRepository) Create(user *domain.User) (*domain.User, error) {
-	rolesStr := rolesToString(user.UserRoles())
+	err := withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO 
+			users (id, email, username, password_hash, description, join_date) 
+			VALUES (?, ?, ?, ?, ?, ?)
+		`,
+			user.GetID().String(),
+			user.Email(),
+			user.Username(),
+			user.PasswordHash(),
+			user.Description(),
+			user.JoinDate(),
+		)
+		if err != nil {
+			return err
+		}
 
-	_, err := r.db.Exec(`
-		INSERT INTO 
-		users (id, email, username, password_hash, description, user_roles, join_date) 
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`,
-		user.GetID().String(),
-		user.Email(),
-		user.Username(),
-		user.PasswordHash(),
-		user.Description(),
-		rolesStr,
-		user.JoinDate(),
-	)
+		return insertUserRoles(tx, user.GetID(), user.UserRoles())
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -124,88 +143,503 @@ func (r UserRepository) Create(user *domain.User) (*domain.User, error) {
 	return user, nil
 }
 
-func (r UserRepository) UpdateRoles(id domain.UserID, roles []domain.UserRole) error {
-	rolesStr := rolesToString(roles)
+func (r UserRepository) UpdateRoles(user *domain.User) error {
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(
+			"DELETE FROM user_roles WHERE user_id=?",
+			user.GetID().String(),
+		); err != nil {
+			return err
+		}
 
-	_, err := r.db.Exec(`
-		UPDATE users
-		SET user_roles = ?
-		WHERE id = ?
-	`,
-		rolesStr,
+		return insertUserRoles(tx, user.GetID(), user.UserRoles())
+	})
+}
+
+func (r UserRepository) UpdateProfile(user *domain.User) error {
+	profile := user.Profile()
+	links, err := encodeProfileLinks(profile.Links)
+	if err != nil {
+		return err
+	}
+
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users
+			SET display_name = ?, description = ?, location = ?, links = ?
+			WHERE id = ?
+		`,
+			profile.DisplayName,
+			profile.Description,
+			profile.Location,
+			links,
+			user.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r UserRepository) UpdateAvatar(user *domain.User) error {
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users
+			SET avatar_version = ?
+			WHERE id = ?
+		`,
+			user.AvatarVersion(),
+			user.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r UserRepository) UpdatePasswordHash(user *domain.User) error {
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users
+			SET password_hash = ?
+			WHERE id = ?
+		`,
+			user.PasswordHash(),
+			user.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r UserRepository) UpdateLockedUntil(user *domain.User) error {
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users
+			SET locked_until = ?
+			WHERE id = ?
+		`,
+			user.LockedUntil(),
+			user.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r UserRepository) UpdateStatus(user *domain.User) error {
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users
+			SET status = ?, status_reason = ?, suspended_until = ?
+			WHERE id = ?
+		`,
+			user.Status().String(),
+			user.StatusReason(),
+			// Stored in UTC so FindExpiredSuspensions can compare them as text
+			utcOrNil(user.SuspendedUntil()),
+			user.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r UserRepository) FindExpiredSuspensions(at time.Time) ([]domain.User, error) {
+	var dbUsers []models.User
+	err := r.db.Select(
+		&dbUsers,
+		"SELECT * FROM users WHERE status=? AND suspended_until<=?",
+		domain.UserStatusSuspended.String(),
+		at.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []domain.User{}
+	for _, dbUser := range dbUsers {
+		user, err := r.withRoles(dbUser)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	return users, nil
+}
+
+func (r UserRepository) UpdateDeletion(user *domain.User) error {
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users
+			SET deletion_requested_at = ?, delete_after = ?
+			WHERE id = ?
+		`,
+			user.DeletionRequestedAt(),
+			// Stored in UTC so FindDueForAnonymisation can compare them as text
+			utcOrNil(user.DeleteAfter()),
+			user.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r UserRepository) FindDueForAnonymisation(at time.Time) ([]domain.User, error) {
+	var dbUsers []models.User
+	err := r.db.Select(
+		&dbUsers,
+		"SELECT * FROM users WHERE anonymised_at IS NULL AND delete_after<=?",
+		at.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []domain.User{}
+	for _, dbUser := range dbUsers {
+		user, err := r.withRoles(dbUser)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	return users, nil
+}
+
+func (r UserRepository) Anonymise(user *domain.User) error {
+	links, err := encodeProfileLinks(user.Links())
+	if err != nil {
+		return err
+	}
+
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users
+			SET email = ?, username = ?, password_hash = ?, display_name = ?, description = ?,
+				location = ?, links = ?, avatar_version = ?, locked_until = ?, delete_after = ?,
+				anonymised_at = ?, pending_email = '', email_change_token_hash = '',
+				email_change_expires_at = NULL
+			WHERE id = ?
+		`,
+			user.Email(),
+			user.Username(),
+			user.PasswordHash(),
+			user.DisplayName(),
+			user.Description(),
+			user.Location(),
+			links,
+			user.AvatarVersion(),
+			user.LockedUntil(),
+			user.DeleteAfter(),
+			user.AnonymisedAt(),
+			user.GetID().String(),
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM user_roles WHERE user_id=?", user.GetID().String()); err != nil {
+			return err
+		}
+
+		// The history holds the old emails and usernames, so it goes too
+		_, err = tx.Exec("DELETE FROM identity_changes WHERE user_id=?", user.GetID().String())
+		return err
+	})
+}
+
+func (r UserRepository) UpdatePendingEmailChange(user *domain.User) error {
+	var (
+		newEmail  string
+		tokenHash string
+		expiresAt *time.Time
+	)
+	if change := user.PendingEmailChange(); change != nil {
+		newEmail = change.NewEmail
+		tokenHash = change.TokenHash
+		expiresAt = &change.ExpiresAt
+	}
+
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users
+			SET pending_email = ?, email_change_token_hash = ?, email_change_expires_at = ?
+			WHERE id = ?
+		`,
+			newEmail,
+			tokenHash,
+			expiresAt,
+			user.GetID().String(),
+		)
+		return err
+	})
+}
+
+func (r UserRepository) FindByEmailChangeToken(tokenHash string) (*domain.User, error) {
+	var dbUser models.User
+	err := r.db.Get(
+		&dbUser,
+		"SELECT * FROM users WHERE email_change_token_hash=? AND email_change_token_hash != ''",
+		tokenHash,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return r.withRoles(dbUser)
+}
+
+func (r UserRepository) ChangeEmail(user *domain.User, change domain.IdentityChange) error {
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		// The unique index on email rejects an address another user already has
+		_, err := tx.Exec(`
+			UPDATE users
+			SET email = ?, pending_email = '', email_change_token_hash = '',
+				email_change_expires_at = NULL
+			WHERE id = ?
+		`,
+			change.NewValue,
+			user.GetID().String(),
+		)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return domain.ErrEmailTaken
+			}
+			return err
+		}
+
+		return insertIdentityChange(tx, change)
+	})
+}
+
+func (r UserRepository) ChangeUsername(user *domain.User, change domain.IdentityChange) error {
+	return withEvents(r.db, user.GetID().String(), user, func(tx *sqlx.Tx) error {
+		// Update first: the unique index rejects a username another user has, and the
+		// write holds the database's write lock, so no other rename can reserve or take
+		// the username between the check below and the commit
+		_, err := tx.Exec(`
+			UPDATE users
+			SET username = ?, username_changed_at = ?
+			WHERE id = ?
+		`,
+			change.NewValue,
+			change.ChangedAt.UTC(),
+			user.GetID().String(),
+		)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return domain.ErrUsernameTaken
+			}
+			return err
+		}
+
+		var reserved int
+		err = tx.Get(&reserved, `
+			SELECT COUNT(*) FROM identity_changes
+			WHERE kind = ? AND old_value = ? AND reserved_until > ?
+		`,
+			domain.IdentityKindUsername.String(),
+			change.NewValue,
+			change.ChangedAt.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+		if reserved > 0 {
+			return domain.ErrUsernameTaken
+		}
+
+		return insertIdentityChange(tx, change)
+	})
+}
+
+func (r UserRepository) FindIdentityHistory(id domain.UserID) ([]domain.IdentityChange, error) {
+	var dbChanges []models.IdentityChange
+	err := r.db.Select(
+		&dbChanges,
+		"SELECT * FROM identity_changes WHERE user_id=? ORDER BY id",
 		id.String(),
 	)
-	return err
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []domain.IdentityChange{}
+	for _, dbChange := range dbChanges {
+		changes = append(changes, domain.IdentityChange{
+			UserID:        domain.NewUserID(dbChange.UserID),
+			Kind:          domain.IdentityKind(dbChange.Kind),
+			OldValue:      dbChange.OldValue,
+			NewValue:      dbChange.NewValue,
+			ChangedAt:     dbChange.ChangedAt,
+			ReservedUntil: dbChange.ReservedUntil,
+		})
+	}
+
+	return changes, nil
 }
 
-func (r UserRepository) UpdateDescription(id domain.UserID, newDescription string) error {
-	_, err := r.db.Exec(`
-		UPDATE users
-		SET description = ?
-		WHERE id = ?
+func (r UserRepository) FindByReservedUsername(
+	username string,
+	at time.Time,
+) (*domain.User, error) {
+	var dbUser models.User
+	err := r.db.Get(&dbUser, `
+		SELECT users.* FROM users
+		JOIN identity_changes ON identity_changes.user_id = users.id
+		WHERE identity_changes.kind = ?
+			AND identity_changes.old_value = ?
+			AND identity_changes.reserved_until > ?
+		ORDER BY identity_changes.id DESC
+		LIMIT 1
 	`,
-		newDescription,
-		id.String(),
+		domain.IdentityKindUsername.String(),
+		username,
+		at.UTC(),
 	)
-	return err
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return r.withRoles(dbUser)
 }
 
-func (r UserRepository) UpdatePasswordHash(id domain.UserID, passwordHash string) error {
-	_, err := r.db.Exec(`
-		UPDATE users
-		SET password_hash = ?
-		WHERE id = ?
+// withRoles loads the roles assigned to the user and rebuilds the aggregate
+func (r UserRepository) withRoles(dbUser models.User) (*domain.User, error) {
+	var dbUserRoles []models.UserRole
+	err := r.db.Select(&dbUserRoles, "SELECT * FROM user_roles WHERE user_id=?", dbUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user := dbUserToDomainUser(dbUser, dbUserRoles)
+	return user, nil
+}
+
+// insertIdentityChange records the change, storing times in UTC so reservations can be
+// compared as text
+func insertIdentityChange(tx *sqlx.Tx, change domain.IdentityChange) error {
+	var reservedUntil *time.Time
+	if change.ReservedUntil != nil {
+		utc := change.ReservedUntil.UTC()
+		reservedUntil = &utc
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO identity_changes
+		(user_id, kind, old_value, new_value, changed_at, reserved_until)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`,
-		passwordHash,
-		id.String(),
+		change.UserID.String(),
+		change.Kind.String(),
+		change.OldValue,
+		change.NewValue,
+		change.ChangedAt.UTC(),
+		reservedUntil,
 	)
 	return err
 }
 
-func dbUserToDomainUser(dbUser models.User) *domain.User {
-	roles := stringToRoles(dbUser.UserRoles)
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+func insertUserRoles(tx *sqlx.Tx, id domain.UserID, roles []domain.UserRole) error {
+	for _, role := range roles {
+		_, err := tx.Exec(
+			"INSERT INTO user_roles (user_id, role_name) VALUES (?, ?)",
+			id.String(),
+			role.String(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dbUserToDomainUser(dbUser models.User, dbUserRoles []models.UserRole) *domain.User {
+	roles := []domain.UserRole{}
+	for _, dbUserRole := range dbUserRoles {
+		if dbUserRole.UserID == dbUser.ID {
+			roles = append(roles, domain.UserRole(dbUserRole.RoleName))
+		}
+	}
+
+	var pendingEmailChange *domain.EmailChange
+	if dbUser.PendingEmail != "" && dbUser.EmailChangeExpiresAt != nil {
+		pendingEmailChange = &domain.EmailChange{
+			NewEmail:  dbUser.PendingEmail,
+			TokenHash: dbUser.EmailChangeTokenHash,
+			ExpiresAt: *dbUser.EmailChangeExpiresAt,
+		}
+	}
 
 	return domain.RebuildUser(
 		domain.NewUserID(dbUser.ID),
 		dbUser.Email,
 		dbUser.PasswordHash,
 		dbUser.Username,
-		dbUser.Description,
+		domain.Profile{
+			DisplayName: dbUser.DisplayName,
+			Description: dbUser.Description,
+			Location:    dbUser.Location,
+			Links:       decodeProfileLinks(dbUser.Links),
+		},
+		dbUser.AvatarVersion,
 		roles,
 		dbUser.JoinDate,
+		dbUser.LockedUntil,
+		domain.UserStatus(dbUser.Status),
+		dbUser.StatusReason,
+		dbUser.SuspendedUntil,
+		dbUser.DeletionRequestedAt,
+		dbUser.DeleteAfter,
+		dbUser.AnonymisedAt,
+		pendingEmailChange,
+		dbUser.UsernameChangedAt,
 	)
 }
 
-func dbUsersToDomainUsers(dbUsers []models.User) []domain.User {
-	users := []domain.User{}
-	for _, user := range dbUsers {
-		users = append(users, *dbUserToDomainUser(user))
-	}
-	return users
+type profileLink struct {
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
 }
 
-func rolesToString(roles []domain.UserRole) string {
-	roleStrs := make([]string, len(roles))
-	for i, role := range roles {
-		roleStrs[i] = string(role)
+// encodeProfileLinks stores the links as a JSON array, as they're only ever read
+// alongside the rest of the profile
+func encodeProfileLinks(links []domain.ProfileLink) (string, error) {
+	dbLinks := []profileLink{}
+	for _, link := range links {
+		dbLinks = append(dbLinks, profileLink{Kind: link.Kind.String(), URL: link.URL})
 	}
-	return strings.Join(roleStrs, ";")
-}
 
-func stringToRoles(rolesStr string) []domain.UserRole {
-	if rolesStr == "" {
-		return []domain.UserRole{}
+	encoded, err := json.Marshal(dbLinks)
+	if err != nil {
+		return "", err
 	}
+	return string(encoded), nil
+}
 
-	roleStrs := strings.Split(rolesStr, ";")
-	roles := make([]domain.UserRole, 0, len(roleStrs))
+func decodeProfileLinks(encoded string) []domain.ProfileLink {
+	var dbLinks []profileLink
+	if err := json.Unmarshal([]byte(encoded), &dbLinks); err != nil {
+		return []domain.ProfileLink{}
+	}
 
-	for _, roleStr := range roleStrs {
-		roleStr = strings.TrimSpace(roleStr)
-		if roleStr != "" {
-			roles = append(roles, domain.UserRole(roleStr))
-		}
+	links := []domain.ProfileLink{}
+	for _, link := range dbLinks {
+		links = append(links, domain.ProfileLink{Kind: domain.LinkKind(link.Kind), URL: link.URL})
 	}
+	return links
+}
 
-	return roles
+func dbUsersToDomainUsers(dbUsers []models.User, dbUserRoles []models.UserRole) []domain.User {
+	users := []domain.User{}
+	for _, user := range dbUsers {
+		users = append(users, *dbUserToDomainUser(user, dbUserRoles))
+	}
+	return users
 }
@@ -2,38 +2,166 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
 	"blog/internal/application"
+	"blog/internal/domain"
 	"blog/internal/interfaces/http/middleware"
 	"blog/internal/interfaces/http/requests"
+	"blog/pkg/ddd"
 
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-chi/chi/v5"
 )
 
 type AdminHandler struct {
-	userService    *application.UserService
-	postService    *application.PostService
-	commentService *application.CommentService
-	sessionManager *scs.SessionManager
+	userService          *application.UserService
+	postService          *application.PostService
+	commentService       *application.CommentService
+	sessionService       *application.SessionService
+	roleService          *application.RoleService
+	impersonationService *application.ImpersonationService
+	deadLetterService    *application.DeadLetterService
+	eventService         *application.EventService
+	projectionService    *application.ProjectionService
+	authorizer           *application.Authorizer
+	sessionManager       *scs.SessionManager
+}
+
+func NewAdminHandler(
+	userService *application.UserService,
+	postService *application.PostService,
+	commentService *application.CommentService,
+	sessionService *application.SessionService,
+	roleService *application.RoleService,
+	impersonationService *application.ImpersonationService,
+	deadLetterService *application.DeadLetterService,
+	eventService *application.EventService,
+	projectionService *application.ProjectionService,
+	authorizer *application.Authorizer,
+	sessionManager *scs.SessionManager,
+) *AdminHandler {
+	return &AdminHandler{
+		userService:          userService,
+		postService:          postService,
+		commentService:       commentService,
+		sessionService:       sessionService,
+		roleService:          roleService,
+		impersonationService: impersonationService,
+		deadLetterService:    deadLetterService,
+		eventService:         eventService,
+		projectionService:    projectionService,
+		authorizer:           authorizer,
+		sessionManager:       sessionManager,
+	}
 }
 
 func (h AdminHandler) Register(mux chi.Router) {
 	mux.Route("/admin", func(r chi.Router) {
+		// An admin impersonating a user must stop before going back to admin work
+		r.Use(middleware.BlockImpersonation(h.sessionManager))
+
 		// Admin authorized routes
-		r.Use(middleware.RequireAdminAuth(h.sessionManager, h.userService))
+		r.Use(middleware.RequireAuthorization(
+			h.sessionManager,
+			h.authorizer,
+			domain.ActionManageUsers,
+		))
 
 		r.Route("/users", func(r chi.Router) {
 			// Set user roles
 			r.Post("/{id}/roles", h.SetUserRoles)
 
-			// Update user description
-			r.Post("/{id}/description", h.UpdateUserDescription)
+			// Update a user's profile
+			r.Put("/{id}/profile", h.UpdateUserProfile)
+
+			// Remove a user's avatar
+			r.Delete("/{id}/avatar", h.RemoveUserAvatar)
 
 			// Update user password
 			r.Post("/{id}/password", h.UpdateUserPassword)
+
+			// Unlock a locked out user
+			r.Post("/{id}/unlock", h.UnlockUser)
+
+			// Suspend a user until a given time
+			r.Post("/{id}/suspend", h.SuspendUser)
+
+			// Ban a user
+			r.Post("/{id}/ban", h.BanUser)
+
+			// Lift a user's suspension or ban
+			r.Post("/{id}/reinstate", h.ReinstateUser)
+
+			// List user sessions
+			r.Get("/{id}/sessions", h.GetUserSessions)
+
+			// Revoke all user sessions
+			r.Delete("/{id}/sessions", h.RevokeAllUserSessions)
+
+			// Revoke a single user session
+			r.Delete("/{id}/sessions/{sessionId}", h.RevokeUserSession)
+
+			// Act as the user, to see what they see
+			r.Post("/{id}/impersonate", h.ImpersonateUser)
+		})
+
+		// List impersonations, optionally of a single user
+		r.Get("/impersonations", h.GetImpersonations)
+
+		r.Route("/roles", func(r chi.Router) {
+			// List roles
+			r.Get("/", h.GetRoles)
+
+			// Create a role
+			r.Post("/", h.CreateRole)
+
+			// Get a role
+			r.Get("/{name}", h.GetRole)
+
+			// Replace a role's permissions
+			r.Put("/{name}/permissions", h.SetRolePermissions)
+
+			// Delete a role
+			r.Delete("/{name}", h.DeleteRole)
+		})
+
+		// List grantable permissions
+		r.Get("/permissions", h.GetPermissions)
+
+		// Count event dispatches and handler runs, with their failures and durations
+		r.Get("/events/metrics", h.GetEventMetrics)
+
+		// List what runs for each type of event: the dispatcher's handlers, and the event
+		// coordinator's handlers, routes and sagas
+		r.Get("/events/topology", h.GetEventTopology)
+
+		r.Route("/events/dead-letters", func(r chi.Router) {
+			// List dead-lettered events, optionally with a single status
+			r.Get("/", h.GetDeadLetters)
+
+			// Count dead-lettered events by status
+			r.Get("/stats", h.GetDeadLetterStats)
+
+			// Get a dead-lettered event
+			r.Get("/{id}", h.GetDeadLetter)
+
+			// Run a dead-lettered event through its handler again
+			r.Post("/{id}/replay", h.ReplayDeadLetter)
+
+			// Give up on a dead-lettered event
+			r.Delete("/{id}", h.DiscardDeadLetter)
+		})
+
+		r.Route("/projections", func(r chi.Router) {
+			// List the read models, and how far behind the event log they are
+			r.Get("/", h.GetProjections)
+
+			// Empty a read model and project every event again
+			r.Post("/{name}/rebuild", h.RebuildProjection)
 		})
 	})
 }
@@ -62,7 +190,12 @@ func (h AdminHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update the user's roles
-	if err := h.userService.SetUserRoles(userID, req.UserRoles); err != nil {
+	if err := h.userService.SetUserRoles(r.Context(), userID, req.UserRoles); err != nil {
+		if errors.Is(err, domain.ErrUnknownRole) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
 		log.Println("SetUserRoles: failed to set user's roles")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -71,17 +204,17 @@ func (h AdminHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h AdminHandler) UpdateUserDescription(w http.ResponseWriter, r *http.Request) {
+func (h AdminHandler) UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
 	// Decode the request and validate it
-	var req requests.UpdateUserDescriptionRequest
+	var req requests.UpdateProfileRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Println("UpdateUserDescription: failed to decode request")
+		log.Println("UpdateUserProfile: failed to decode request")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	if errors := req.Validate(); errors != nil {
-		log.Println("UpdateUserDescription: invalid request data")
+		log.Println("UpdateUserProfile: invalid request data")
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(errors.Error()))
 		return
@@ -89,21 +222,43 @@ func (h AdminHandler) UpdateUserDescription(w http.ResponseWriter, r *http.Reque
 
 	userID := chi.URLParam(r, "id")
 	if userID == "" {
-		log.Println("UpdateUserDescription: missing user_id")
+		log.Println("UpdateUserProfile: missing user_id")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	// Update the user's description
-	if err := h.userService.UpdateDescription(userID, req.Description); err != nil {
-		log.Println("UpdateUserDescription: failed to update user's description")
-		w.WriteHeader(http.StatusInternalServerError)
+	// Update the user's profile
+	if err := h.userService.UpdateProfile(
+		r.Context(),
+		userID,
+		req.DisplayName,
+		req.Description,
+		req.Location,
+		toProfileLinkDTOs(req.Links),
+	); err != nil {
+		writeProfileError(w, "UpdateUserProfile", err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h AdminHandler) RemoveUserAvatar(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		log.Println("RemoveUserAvatar: missing user_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.RemoveAvatar(r.Context(), userID); err != nil {
+		writeProfileError(w, "RemoveUserAvatar", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h AdminHandler) UpdateUserPassword(w http.ResponseWriter, r *http.Request) {
 	// Decode the request and validate it
 	var req requests.UpdateUserPasswordRequest
@@ -128,7 +283,12 @@ func (h AdminHandler) UpdateUserPassword(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Update the user's password
-	if err := h.userService.UpdatePassword(userID, req.Password); err != nil {
+	if err := h.userService.UpdatePassword(r.Context(), userID, req.Password); err != nil {
+		if errors.Is(err, domain.ErrWeakPassword) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
 		log.Println("UpdateUserPassword: failed to update user's password")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -136,3 +296,565 @@ func (h AdminHandler) UpdateUserPassword(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h AdminHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		log.Println("UnlockUser: missing user_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Lift the user's lock
+	if err := h.userService.UnlockUser(r.Context(), userID); err != nil {
+		log.Println("UnlockUser: failed to unlock user")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.SuspendUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("SuspendUser: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("SuspendUser: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		log.Println("SuspendUser: missing user_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.SuspendUser(r.Context(), userID, req.Until, req.Reason); err != nil {
+		writeUserStatusError(w, "SuspendUser", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) BanUser(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.BanUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("BanUser: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("BanUser: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		log.Println("BanUser: missing user_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.BanUser(r.Context(), userID, req.Reason); err != nil {
+		writeUserStatusError(w, "BanUser", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) ReinstateUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		log.Println("ReinstateUser: missing user_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.ReinstateUser(r.Context(), userID); err != nil {
+		writeUserStatusError(w, "ReinstateUser", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) GetUserSessions(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		log.Println("GetUserSessions: missing user_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// None of the user's sessions is the one making the request, which is the admin's
+	sessions, err := h.sessionService.GetUserSessions(userID, "")
+	if err != nil {
+		log.Println("GetUserSessions: failed to get user's sessions")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(sessions)
+	if err != nil {
+		log.Println("GetUserSessions: failed to marshal sessions")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h AdminHandler) RevokeUserSession(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	sessionID := chi.URLParam(r, "sessionId")
+	if userID == "" || sessionID == "" {
+		log.Println("RevokeUserSession: missing user_id or session_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Revoke the user's session
+	if err := h.sessionService.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		log.Println("RevokeUserSession: failed to revoke user's session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) RevokeAllUserSessions(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		log.Println("RevokeAllUserSessions: missing user_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Revoke all of the user's sessions
+	if err := h.sessionService.RevokeAllSessions(userID); err != nil {
+		log.Println("RevokeAllUserSessions: failed to revoke user's sessions")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.StartImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("ImpersonateUser: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("ImpersonateUser: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		log.Println("ImpersonateUser: missing user_id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	adminID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	// Start the impersonation
+	impersonation, err := h.impersonationService.StartImpersonation(
+		r.Context(), adminID, userID, req.Reason,
+	)
+	if err != nil {
+		writeImpersonationError(w, "ImpersonateUser", err)
+		return
+	}
+
+	// Switch the session over to the user under a new token, so the admin's own session
+	// token can't be used as the user
+	if err := h.sessionManager.RenewToken(r.Context()); err != nil {
+		log.Println("ImpersonateUser: failed to renew session token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	h.sessionManager.Put(r.Context(), "user_id", impersonation.UserID)
+	h.sessionManager.Put(r.Context(), "impersonator_id", impersonation.AdminID)
+	h.sessionManager.Put(r.Context(), "impersonation_id", impersonation.ID)
+
+	// Record the session as an impersonation, so it shows up in the user's sessions
+	if _, err := h.sessionService.StartImpersonationSession(
+		h.sessionManager.Token(r.Context()),
+		impersonation.UserID,
+		impersonation.AdminID,
+		r.UserAgent(),
+		clientIP(r),
+		h.sessionManager.Deadline(r.Context()),
+	); err != nil {
+		log.Println("ImpersonateUser: failed to start session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(impersonation)
+	if err != nil {
+		log.Println("ImpersonateUser: failed to marshal impersonation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h AdminHandler) GetImpersonations(w http.ResponseWriter, r *http.Request) {
+	impersonations, err := h.impersonationService.GetImpersonations(r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Println("GetImpersonations: failed to get impersonations")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(impersonations)
+	if err != nil {
+		log.Println("GetImpersonations: failed to marshal impersonations")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h AdminHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roleService.GetRoles()
+	if err != nil {
+		log.Println("GetRoles: failed to get roles")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(roles)
+	if err != nil {
+		log.Println("GetRoles: failed to marshal roles")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h AdminHandler) GetRole(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		log.Println("GetRole: missing name")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.roleService.GetRole(name)
+	if err != nil {
+		if errors.Is(err, domain.ErrRoleNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		log.Println("GetRole: failed to get role")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(role)
+	if err != nil {
+		log.Println("GetRole: failed to marshal role")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h AdminHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("CreateRole: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("CreateRole: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	role, err := h.roleService.CreateRole(r.Context(), req.Name, req.Description, req.Permissions)
+	if err != nil {
+		writeRoleError(w, "CreateRole", err)
+		return
+	}
+
+	data, err := json.Marshal(role)
+	if err != nil {
+		log.Println("CreateRole: failed to marshal role")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
+func (h AdminHandler) SetRolePermissions(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.SetRolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("SetRolePermissions: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("SetRolePermissions: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		log.Println("SetRolePermissions: missing name")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := h.roleService.SetRolePermissions(r.Context(), name, req.Permissions); err != nil {
+		writeRoleError(w, "SetRolePermissions", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		log.Println("DeleteRole: missing name")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := h.roleService.DeleteRole(r.Context(), name); err != nil {
+		writeRoleError(w, "DeleteRole", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(h.roleService.GetPermissions())
+	if err != nil {
+		log.Println("GetPermissions: failed to marshal permissions")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h AdminHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	letters, err := h.deadLetterService.GetDeadLetters(r.URL.Query().Get("status"))
+	if err != nil {
+		writeDeadLetterError(w, "GetDeadLetters", err)
+		return
+	}
+
+	writeJSON(w, "GetDeadLetters", http.StatusOK, letters)
+}
+
+func (h AdminHandler) GetDeadLetterStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.deadLetterService.GetDeadLetterStats()
+	if err != nil {
+		writeDeadLetterError(w, "GetDeadLetterStats", err)
+		return
+	}
+
+	writeJSON(w, "GetDeadLetterStats", http.StatusOK, stats)
+}
+
+func (h AdminHandler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		log.Println("GetDeadLetter: invalid id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	letter, err := h.deadLetterService.GetDeadLetter(id)
+	if err != nil {
+		writeDeadLetterError(w, "GetDeadLetter", err)
+		return
+	}
+
+	writeJSON(w, "GetDeadLetter", http.StatusOK, letter)
+}
+
+func (h AdminHandler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		log.Println("ReplayDeadLetter: invalid id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	letter, err := h.deadLetterService.ReplayDeadLetter(r.Context(), id)
+	if err != nil {
+		writeDeadLetterError(w, "ReplayDeadLetter", err)
+		return
+	}
+
+	writeJSON(w, "ReplayDeadLetter", http.StatusOK, letter)
+}
+
+func (h AdminHandler) DiscardDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		log.Println("DiscardDeadLetter: invalid id")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := h.deadLetterService.DiscardDeadLetter(id); err != nil {
+		writeDeadLetterError(w, "DiscardDeadLetter", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h AdminHandler) GetEventMetrics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, "GetEventMetrics", http.StatusOK, h.eventService.GetEventMetrics())
+}
+
+func (h AdminHandler) GetEventTopology(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, "GetEventTopology", http.StatusOK, h.eventService.GetEventTopology())
+}
+
+func (h AdminHandler) GetProjections(w http.ResponseWriter, r *http.Request) {
+	projections, err := h.projectionService.GetProjections()
+	if err != nil {
+		log.Printf("GetProjections: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, "GetProjections", http.StatusOK, projections)
+}
+
+func (h AdminHandler) RebuildProjection(w http.ResponseWriter, r *http.Request) {
+	rebuild, err := h.projectionService.RebuildProjection(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		if errors.Is(err, ddd.ErrProjectionNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		log.Printf("RebuildProjection: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, "RebuildProjection", http.StatusOK, rebuild)
+}
+
+// writeUserStatusError maps suspension and ban errors onto status codes
+func writeUserStatusError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, domain.ErrMissingReason),
+		errors.Is(err, domain.ErrSuspensionInPast):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrUserBanned),
+		errors.Is(err, domain.ErrUserNotRestricted):
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// writeRoleError maps role management errors onto status codes
+func writeRoleError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRoleNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidRoleName),
+		errors.Is(err, domain.ErrUnknownPermission),
+		errors.Is(err, domain.ErrAdminRoleRequiresManageUsers):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrRoleAlreadyExists),
+		errors.Is(err, domain.ErrRoleInUse),
+		errors.Is(err, domain.ErrBuiltInRole):
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// writeDeadLetterError maps dead letter errors onto status codes. A replay that fails
+// again is reported with the handler's error, as the dead letter is still pending
+func writeDeadLetterError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, ddd.ErrDeadLetterNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, ddd.ErrUnknownDeadLetterStatus):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, ddd.ErrDeadLetterResolved),
+		errors.Is(err, ddd.ErrUnknownSubscription):
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, ddd.ErrReplayFailed):
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
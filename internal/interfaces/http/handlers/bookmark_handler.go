@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/interfaces/http/middleware"
+	"blog/internal/interfaces/http/requests"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+type BookmarkHandler struct {
+	bookmarkService *application.BookmarkService
+	sessionManager  *scs.SessionManager
+}
+
+func NewBookmarkHandler(
+	bookmarkService *application.BookmarkService,
+	sessionManager *scs.SessionManager,
+) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkService: bookmarkService,
+		sessionManager:  sessionManager,
+	}
+}
+
+func (h BookmarkHandler) Register(mux chi.Router) {
+	mux.Route("/bookmarks", func(r chi.Router) {
+		// Protected routes
+		r.Use(middleware.RequireAuth(h.sessionManager))
+
+		// List bookmarks, optionally only those in ?folder_id=
+		r.Get("/", h.GetBookmarks)
+
+		// Bookmark a post
+		r.Post("/", h.CreateBookmark)
+
+		// List folders
+		r.Get("/folders", h.GetFolders)
+
+		// Create a folder
+		r.Post("/folders", h.CreateFolder)
+
+		// Rename a folder
+		r.Put("/folders/{id}", h.RenameFolder)
+
+		// Delete a folder, unfiling its bookmarks
+		r.Delete("/folders/{id}", h.DeleteFolder)
+
+		// Get a bookmark
+		r.Get("/{id}", h.GetBookmark)
+
+		// Move a bookmark or change its note
+		r.Put("/{id}", h.UpdateBookmark)
+
+		// Remove a bookmark
+		r.Delete("/{id}", h.RemoveBookmark)
+	})
+}
+
+func (h BookmarkHandler) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	bookmarks, err := h.bookmarkService.GetBookmarks(userID, r.URL.Query().Get("folder_id"))
+	if err != nil {
+		writeBookmarkError(w, "GetBookmarks", err)
+		return
+	}
+
+	writeJSON(w, "GetBookmarks", http.StatusOK, bookmarks)
+}
+
+func (h BookmarkHandler) GetBookmark(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	bookmark, err := h.bookmarkService.GetBookmark(userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeBookmarkError(w, "GetBookmark", err)
+		return
+	}
+
+	writeJSON(w, "GetBookmark", http.StatusOK, bookmark)
+}
+
+func (h BookmarkHandler) CreateBookmark(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.CreateBookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("CreateBookmark: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("CreateBookmark: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	bookmark, err := h.bookmarkService.CreateBookmark(
+		r.Context(), userID, req.PostID, req.FolderID, req.Note,
+	)
+	if err != nil {
+		writeBookmarkError(w, "CreateBookmark", err)
+		return
+	}
+
+	writeJSON(w, "CreateBookmark", http.StatusCreated, bookmark)
+}
+
+func (h BookmarkHandler) UpdateBookmark(w http.ResponseWriter, r *http.Request) {
+	var req requests.UpdateBookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("UpdateBookmark: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	bookmark, err := h.bookmarkService.UpdateBookmark(
+		r.Context(),
+		userID,
+		chi.URLParam(r, "id"),
+		req.FolderID,
+		req.Note,
+	)
+	if err != nil {
+		writeBookmarkError(w, "UpdateBookmark", err)
+		return
+	}
+
+	writeJSON(w, "UpdateBookmark", http.StatusOK, bookmark)
+}
+
+func (h BookmarkHandler) RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	if err := h.bookmarkService.RemoveBookmark(
+		r.Context(),
+		userID,
+		chi.URLParam(r, "id"),
+	); err != nil {
+		writeBookmarkError(w, "RemoveBookmark", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h BookmarkHandler) GetFolders(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	folders, err := h.bookmarkService.GetFolders(userID)
+	if err != nil {
+		writeBookmarkError(w, "GetFolders", err)
+		return
+	}
+
+	writeJSON(w, "GetFolders", http.StatusOK, folders)
+}
+
+func (h BookmarkHandler) CreateFolder(w http.ResponseWriter, r *http.Request) {
+	var req requests.BookmarkFolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("CreateFolder: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("CreateFolder: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	folder, err := h.bookmarkService.CreateFolder(r.Context(), userID, req.Name)
+	if err != nil {
+		writeBookmarkError(w, "CreateFolder", err)
+		return
+	}
+
+	writeJSON(w, "CreateFolder", http.StatusCreated, folder)
+}
+
+func (h BookmarkHandler) RenameFolder(w http.ResponseWriter, r *http.Request) {
+	var req requests.BookmarkFolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("RenameFolder: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("RenameFolder: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	folder, err := h.bookmarkService.RenameFolder(
+		r.Context(), userID, chi.URLParam(r, "id"), req.Name,
+	)
+	if err != nil {
+		writeBookmarkError(w, "RenameFolder", err)
+		return
+	}
+
+	writeJSON(w, "RenameFolder", http.StatusOK, folder)
+}
+
+func (h BookmarkHandler) DeleteFolder(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	if err := h.bookmarkService.DeleteFolder(
+		r.Context(),
+		userID,
+		chi.URLParam(r, "id"),
+	); err != nil {
+		writeBookmarkError(w, "DeleteFolder", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeBookmarkError maps bookmark errors onto status codes
+func writeBookmarkError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrBookmarkNotFound),
+		errors.Is(err, domain.ErrBookmarkFolderNotFound),
+		errors.Is(err, domain.ErrPostNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrBookmarkNoteTooLong),
+		errors.Is(err, domain.ErrBookmarkFolderNameEmpty),
+		errors.Is(err, domain.ErrBookmarkFolderNameTooLong):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrAlreadyBookmarked),
+		errors.Is(err, domain.ErrBookmarkFolderNameTaken):
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
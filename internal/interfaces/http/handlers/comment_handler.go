@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 
 	"blog/internal/application"
+	"blog/internal/domain"
 	"blog/internal/interfaces/http/middleware"
 	"blog/internal/interfaces/http/requests"
+	"blog/pkg/ddd"
 
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-chi/chi/v5"
@@ -159,8 +162,13 @@ func (h CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
 	// Create the comment
-	comment, err := h.commentService.CreateComment(postID, userID, req.Content)
+	comment, err := h.commentService.CreateComment(r.Context(), postID, userID, req.Content)
 	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("CreateComment: create denied")
+			writeForbidden(w)
+			return
+		}
 		log.Println("CreateComment: failed to create comment")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -206,22 +214,18 @@ func (h CommentHandler) EditComment(w http.ResponseWriter, r *http.Request) {
 	// Get the userID from the session
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
-	// Get the comment and make sure that the user editing it is the owner
-	comment, err := h.commentService.GetComment(commentID)
-	if err != nil {
-		log.Println("EditComment: failed to get comment")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if comment.CommenterID != userID {
-		log.Println("EditComment: non-owner attempting to edit comment")
-		w.WriteHeader(http.StatusForbidden)
-		return
-	}
-
 	// Edit the comment
-	if err := h.commentService.EditComment(commentID, req.Content); err != nil {
+	if err := h.commentService.EditComment(r.Context(), userID, commentID, req.Content); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("EditComment: edit denied")
+			writeForbidden(w)
+			return
+		}
+		if errors.Is(err, ddd.ErrConcurrencyConflict) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte("the comment was changed by another request, try again"))
+			return
+		}
 		log.Println("EditComment: failed to edit comment")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -241,22 +245,18 @@ func (h CommentHandler) ArchiveComment(w http.ResponseWriter, r *http.Request) {
 	// Get the userID from the session
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
-	// Get the comment and make sure that the user archiving it is the owner
-	comment, err := h.commentService.GetComment(commentID)
-	if err != nil {
-		log.Println("ArchiveComment: failed to get comment")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if comment.CommenterID != userID {
-		log.Println("ArchiveComment: non-owner attempting to archive comment")
-		w.WriteHeader(http.StatusForbidden)
-		return
-	}
-
 	// Archive the comment
-	if err := h.commentService.ArchiveComment(commentID); err != nil {
+	if err := h.commentService.ArchiveComment(r.Context(), userID, commentID); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("ArchiveComment: archive denied")
+			writeForbidden(w)
+			return
+		}
+		if errors.Is(err, ddd.ErrConcurrencyConflict) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte("the comment was changed by another request, try again"))
+			return
+		}
 		log.Println("ArchiveComment: failed to archive comment")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/interfaces/http/middleware"
+	"blog/internal/interfaces/http/requests"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+type DigestHandler struct {
+	digestService  *application.DigestService
+	sessionManager *scs.SessionManager
+}
+
+func NewDigestHandler(
+	digestService *application.DigestService,
+	sessionManager *scs.SessionManager,
+) *DigestHandler {
+	return &DigestHandler{
+		digestService:  digestService,
+		sessionManager: sessionManager,
+	}
+}
+
+func (h DigestHandler) Register(mux chi.Router) {
+	mux.Route("/digests", func(r chi.Router) {
+		// Public routes, signed with the token in the link instead of a session.
+		// Mail clients offering one-click unsubscribe POST to the same link
+		r.Get("/unsubscribe", h.Unsubscribe)
+		r.Post("/unsubscribe", h.Unsubscribe)
+
+		r.Group(func(r chi.Router) {
+			// Protected routes
+			r.Use(middleware.RequireAuth(h.sessionManager))
+
+			// Get how often the user gets digests
+			r.Get("/settings", h.GetSettings)
+
+			// Change how often the user gets digests
+			r.Put("/settings", h.UpdateSettings)
+		})
+	})
+}
+
+func (h DigestHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	settings, err := h.digestService.GetSettings(userID)
+	if err != nil {
+		writeDigestError(w, "GetSettings", err)
+		return
+	}
+
+	writeJSON(w, "GetSettings", http.StatusOK, settings)
+}
+
+func (h DigestHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.DigestSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("UpdateSettings: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("UpdateSettings: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	settings, err := h.digestService.UpdateSettings(userID, req.Frequency)
+	if err != nil {
+		writeDigestError(w, "UpdateSettings", err)
+		return
+	}
+
+	writeJSON(w, "UpdateSettings", http.StatusOK, settings)
+}
+
+func (h DigestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := h.digestService.Unsubscribe(r.URL.Query().Get("token")); err != nil {
+		writeDigestError(w, "Unsubscribe", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("You have been unsubscribed from email digests"))
+}
+
+// writeDigestError maps digest errors onto status codes
+func writeDigestError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUnknownDigestFrequency),
+		errors.Is(err, domain.ErrInvalidUnsubscribeToken):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/interfaces/http/middleware"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+type FeedHandler struct {
+	feedService    application.FeedService
+	sessionManager *scs.SessionManager
+}
+
+func NewFeedHandler(
+	feedService application.FeedService,
+	sessionManager *scs.SessionManager,
+) *FeedHandler {
+	return &FeedHandler{
+		feedService:    feedService,
+		sessionManager: sessionManager,
+	}
+}
+
+func (h FeedHandler) Register(mux chi.Router) {
+	mux.Route("/feed", func(r chi.Router) {
+		// Protected routes
+		r.Use(middleware.RequireAuth(h.sessionManager))
+
+		// Get a page of posts by the authors the user follows
+		r.Get("/", h.GetFeed)
+	})
+}
+
+func (h FeedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("limit must be a positive number"))
+			return
+		}
+	}
+
+	page, err := h.feedService.GetFeed(userID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidFeedCursor) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		log.Printf("GetFeed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		log.Println("GetFeed: failed to marshal feed")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
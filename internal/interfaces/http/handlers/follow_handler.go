@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/interfaces/http/middleware"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+type FollowHandler struct {
+	followService  *application.FollowService
+	sessionManager *scs.SessionManager
+}
+
+func NewFollowHandler(
+	followService *application.FollowService,
+	sessionManager *scs.SessionManager,
+) *FollowHandler {
+	return &FollowHandler{
+		followService:  followService,
+		sessionManager: sessionManager,
+	}
+}
+
+// Register adds the routes one by one rather than under a /users/{id} subrouter, which
+// would swallow the other /users routes
+func (h FollowHandler) Register(mux chi.Router) {
+	// List the user's followers
+	mux.Get("/users/{id}/followers", h.GetFollowers)
+
+	// List the users the user follows
+	mux.Get("/users/{id}/following", h.GetFollowing)
+
+	mux.Group(func(r chi.Router) {
+		// Protected routes
+		r.Use(middleware.RequireAuth(h.sessionManager))
+
+		// Follow a user
+		r.Post("/users/{id}/follow", h.FollowUser)
+
+		// Stop following a user
+		r.Delete("/users/{id}/follow", h.UnfollowUser)
+	})
+}
+
+func (h FollowHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	follows, err := h.followService.GetFollowers(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFollowError(w, "GetFollowers", err)
+		return
+	}
+
+	data, err := json.Marshal(follows)
+	if err != nil {
+		log.Println("GetFollowers: failed to marshal follows")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h FollowHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	follows, err := h.followService.GetFollowing(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFollowError(w, "GetFollowing", err)
+		return
+	}
+
+	data, err := json.Marshal(follows)
+	if err != nil {
+		log.Println("GetFollowing: failed to marshal follows")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h FollowHandler) FollowUser(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	follow, err := h.followService.Follow(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeFollowError(w, "FollowUser", err)
+		return
+	}
+
+	data, err := json.Marshal(follow)
+	if err != nil {
+		log.Println("FollowUser: failed to marshal follow")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
+func (h FollowHandler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	if err := h.followService.Unfollow(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		writeFollowError(w, "UnfollowUser", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeFollowError maps follow errors onto status codes
+func writeFollowError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		writeForbidden(w)
+	case errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrFollowNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrCannotFollowSelf):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrAlreadyFollowing):
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/interfaces/http/middleware"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+// ImpersonationHandler serves the routes an admin uses while impersonating a user. They
+// sit outside /admin, as the session belongs to the impersonated user until it's stopped
+type ImpersonationHandler struct {
+	impersonationService *application.ImpersonationService
+	sessionService       *application.SessionService
+	sessionManager       *scs.SessionManager
+}
+
+func NewImpersonationHandler(
+	impersonationService *application.ImpersonationService,
+	sessionService *application.SessionService,
+	sessionManager *scs.SessionManager,
+) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+		sessionService:       sessionService,
+		sessionManager:       sessionManager,
+	}
+}
+
+func (h ImpersonationHandler) Register(mux chi.Router) {
+	mux.Route("/impersonation", func(r chi.Router) {
+		// Protected routes
+		r.Use(middleware.RequireAuth(h.sessionManager))
+
+		// Get the impersonation the session is in
+		r.Get("/", h.GetImpersonation)
+
+		// Stop impersonating and go back to the admin's own session
+		r.Post("/stop", h.StopImpersonation)
+	})
+}
+
+func (h ImpersonationHandler) GetImpersonation(w http.ResponseWriter, r *http.Request) {
+	impersonationID := h.sessionManager.GetString(r.Context(), "impersonation_id")
+	if impersonationID == "" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(domain.ErrNotImpersonating.Error()))
+		return
+	}
+
+	impersonation, err := h.impersonationService.GetImpersonation(impersonationID)
+	if err != nil {
+		writeImpersonationError(w, "GetImpersonation", err)
+		return
+	}
+
+	data, err := json.Marshal(impersonation)
+	if err != nil {
+		log.Println("GetImpersonation: failed to marshal impersonation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h ImpersonationHandler) StopImpersonation(w http.ResponseWriter, r *http.Request) {
+	impersonationID := h.sessionManager.GetString(r.Context(), "impersonation_id")
+	if impersonationID == "" {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(domain.ErrNotImpersonating.Error()))
+		return
+	}
+
+	impersonation, err := h.impersonationService.StopImpersonation(r.Context(), impersonationID)
+	if err != nil {
+		writeImpersonationError(w, "StopImpersonation", err)
+		return
+	}
+
+	// Hand the session back to the admin under a new token, so the impersonation
+	// session can't be picked up again
+	if err := h.sessionManager.RenewToken(r.Context()); err != nil {
+		log.Println("StopImpersonation: failed to renew session token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	h.sessionManager.Remove(r.Context(), "impersonator_id")
+	h.sessionManager.Remove(r.Context(), "impersonation_id")
+	h.sessionManager.Put(r.Context(), "user_id", impersonation.AdminID)
+
+	if _, err := h.sessionService.StartSession(
+		h.sessionManager.Token(r.Context()),
+		impersonation.AdminID,
+		r.UserAgent(),
+		clientIP(r),
+		h.sessionManager.Deadline(r.Context()),
+	); err != nil {
+		log.Println("StopImpersonation: failed to start session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(impersonation)
+	if err != nil {
+		log.Println("StopImpersonation: failed to marshal impersonation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeImpersonationError maps impersonation errors onto status codes
+func writeImpersonationError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrForbidden),
+		errors.Is(err, domain.ErrCannotImpersonateAdmin):
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrImpersonationNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, domain.ErrCannotImpersonateSelf),
+		errors.Is(err, domain.ErrMissingReason):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrUserAnonymised),
+		errors.Is(err, domain.ErrImpersonationEnded):
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/interfaces/http/requests"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type NewsletterHandler struct {
+	newsletterService *application.NewsletterService
+}
+
+func NewNewsletterHandler(newsletterService *application.NewsletterService) *NewsletterHandler {
+	return &NewsletterHandler{
+		newsletterService: newsletterService,
+	}
+}
+
+func (h NewsletterHandler) Register(mux chi.Router) {
+	// Newsletter subscribers have no accounts, so every route is public. Links in emails
+	// carry a token instead of a session
+	mux.Route("/newsletter", func(r chi.Router) {
+		// Subscribe an address, pending confirmation
+		r.Post("/subscriptions", h.Subscribe)
+
+		// Confirm an address from the link in the confirmation email
+		r.Get("/confirm", h.Confirm)
+
+		// Unsubscribe from the link in a newsletter email. Mail clients offering
+		// one-click unsubscribe POST to the same link
+		r.Get("/unsubscribe", h.Unsubscribe)
+		r.Post("/unsubscribe", h.Unsubscribe)
+
+		// Bounces and complaints from the mail provider, signed with the webhook secret
+		r.Post("/feedback", h.RecordFeedback)
+	})
+}
+
+func (h NewsletterHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.NewsletterSubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("Subscribe: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("Subscribe: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	if err := h.newsletterService.Subscribe(r.Context(), req.Email, req.AuthorID); err != nil {
+		writeNewsletterError(w, "Subscribe", err)
+		return
+	}
+
+	// The subscription waits for the address to be confirmed
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h NewsletterHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	if err := h.newsletterService.Confirm(r.Context(), r.URL.Query().Get("token")); err != nil {
+		writeNewsletterError(w, "Confirm", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("Your newsletter subscription is confirmed"))
+}
+
+func (h NewsletterHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := h.newsletterService.Unsubscribe(r.Context(), r.URL.Query().Get("token")); err != nil {
+		writeNewsletterError(w, "Unsubscribe", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("You have been unsubscribed from the newsletter"))
+}
+
+func (h NewsletterHandler) RecordFeedback(w http.ResponseWriter, r *http.Request) {
+	secret, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	// Decode the request and validate it
+	var req requests.NewsletterFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("RecordFeedback: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("RecordFeedback: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	disabled, err := h.newsletterService.RecordFeedback(r.Context(), secret, req.Email, req.Type)
+	if err != nil {
+		writeNewsletterError(w, "RecordFeedback", err)
+		return
+	}
+
+	writeJSON(w, "RecordFeedback", http.StatusOK, map[string]int{
+		"disabled": disabled,
+	})
+}
+
+// writeNewsletterError maps newsletter errors onto status codes
+func writeNewsletterError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("author not found"))
+	case errors.Is(err, domain.ErrInvalidNewsletterConfirmationToken),
+		errors.Is(err, domain.ErrNewsletterConfirmationExpired),
+		errors.Is(err, domain.ErrInvalidUnsubscribeToken),
+		errors.Is(err, domain.ErrUnknownNewsletterFeedbackType):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrInvalidNewsletterWebhookSecret):
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/interfaces/http/middleware"
+	"blog/internal/interfaces/http/requests"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+type NotificationHandler struct {
+	notificationService *application.NotificationService
+	sessionManager      *scs.SessionManager
+}
+
+func NewNotificationHandler(
+	notificationService *application.NotificationService,
+	sessionManager *scs.SessionManager,
+) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		sessionManager:      sessionManager,
+	}
+}
+
+func (h NotificationHandler) Register(mux chi.Router) {
+	mux.Route("/notifications", func(r chi.Router) {
+		// Protected routes
+		r.Use(middleware.RequireAuth(h.sessionManager))
+
+		// List notifications with the unread count, only unread ones with ?unread=true
+		r.Get("/", h.GetNotifications)
+
+		// Mark the given notifications read, or all of them
+		r.Post("/read", h.MarkManyRead)
+
+		// Get the muted notification types
+		r.Get("/preferences", h.GetPreferences)
+
+		// Replace the muted notification types
+		r.Put("/preferences", h.UpdatePreferences)
+
+		// Mark a notification read
+		r.Post("/{id}/read", h.MarkRead)
+	})
+}
+
+func (h NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	unreadOnly := false
+	if value := r.URL.Query().Get("unread"); value != "" {
+		var err error
+		unreadOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("unread must be true or false"))
+			return
+		}
+	}
+
+	inbox, err := h.notificationService.GetNotifications(userID, unreadOnly)
+	if err != nil {
+		writeNotificationError(w, "GetNotifications", err)
+		return
+	}
+
+	writeJSON(w, "GetNotifications", http.StatusOK, inbox)
+}
+
+func (h NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	notification, err := h.notificationService.MarkRead(userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeNotificationError(w, "MarkRead", err)
+		return
+	}
+
+	writeJSON(w, "MarkRead", http.StatusOK, notification)
+}
+
+func (h NotificationHandler) MarkManyRead(w http.ResponseWriter, r *http.Request) {
+	// The body is optional, as without it every notification is marked read
+	var req requests.MarkNotificationsReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Println("MarkManyRead: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	marked, err := h.notificationService.MarkManyRead(userID, req.IDs)
+	if err != nil {
+		writeNotificationError(w, "MarkManyRead", err)
+		return
+	}
+
+	writeJSON(w, "MarkManyRead", http.StatusOK, map[string]int{"marked": marked})
+}
+
+func (h NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	preferences, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		writeNotificationError(w, "GetPreferences", err)
+		return
+	}
+
+	writeJSON(w, "GetPreferences", http.StatusOK, preferences)
+}
+
+func (h NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req requests.NotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("UpdatePreferences: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	preferences, err := h.notificationService.UpdatePreferences(userID, req.Muted)
+	if err != nil {
+		writeNotificationError(w, "UpdatePreferences", err)
+		return
+	}
+
+	writeJSON(w, "UpdatePreferences", http.StatusOK, preferences)
+}
+
+// writeNotificationError maps notification errors onto status codes
+func writeNotificationError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotificationNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrUnknownNotificationType):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 
 	"blog/internal/application"
+	"blog/internal/domain"
 	"blog/internal/interfaces/http/middleware"
 	"blog/internal/interfaces/http/requests"
 
@@ -124,8 +126,13 @@ func (h PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
 	// Create the post
-	post, err := h.postService.CreatePost(userID, req.Title, req.Content)
+	post, err := h.postService.CreatePost(r.Context(), userID, req.Title, req.Content)
 	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("CreatePost: create denied")
+			writeForbidden(w)
+			return
+		}
 		log.Println("CreatePost: failed to create post")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -170,22 +177,13 @@ func (h PostHandler) UpdatePostTitle(w http.ResponseWriter, r *http.Request) {
 	// Get the userID from the session
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
-	// Get the post and make sure that the user editing it is the owner
-	post, err := h.postService.GetPost(id)
-	if err != nil {
-		log.Println("UpdatePostTitle: failed to get post")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if post.AuthorID != userID {
-		log.Println("UpdatePostTitle: non-author attempting to edit post")
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
 	// Update the post title
-	if err := h.postService.UpdatePostTitle(id, req.Title); err != nil {
+	if err := h.postService.UpdatePostTitle(r.Context(), userID, id, req.Title); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("UpdatePostTitle: update denied")
+			writeForbidden(w)
+			return
+		}
 		log.Println("UpdatePostTitle: failed to update post title")
 		w.WriteHeader(http.StatusBadRequest)
 		return
@@ -221,22 +219,13 @@ func (h PostHandler) UpdatePostContent(w http.ResponseWriter, r *http.Request) {
 	// Get the userID from the session
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
-	// Get the post and make sure that the user editing it is the owner
-	post, err := h.postService.GetPost(id)
-	if err != nil {
-		log.Println("UpdatePostContent: failed to get post")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if post.AuthorID != userID {
-		log.Println("UpdatePostContent: non-author attempting to edit post")
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
 	// Update the post content
-	if err := h.postService.UpdatePostContent(id, req.Content); err != nil {
+	if err := h.postService.UpdatePostContent(r.Context(), userID, id, req.Content); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("UpdatePostContent: update denied")
+			writeForbidden(w)
+			return
+		}
 		log.Println("UpdatePostContent: failed to update post content")
 		w.WriteHeader(http.StatusBadRequest)
 		return
@@ -256,22 +245,13 @@ func (h PostHandler) ArchivePost(w http.ResponseWriter, r *http.Request) {
 	// Get the userID from the session
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
-	// Get the post and make sure that the user editing it is the owner
-	post, err := h.postService.GetPost(id)
-	if err != nil {
-		log.Println("ArchivePost: failed to get post")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if post.AuthorID != userID {
-		log.Println("ArchivePost: non-author attempting to archive post")
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
 	// Archive the post
-	if err := h.postService.ArchivePost(id); err != nil {
+	if err := h.postService.ArchivePost(r.Context(), userID, id); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("ArchivePost: archive denied")
+			writeForbidden(w)
+			return
+		}
 		log.Println("ArchivePost: failed to archive post")
 		w.WriteHeader(http.StatusBadRequest)
 		return
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ProfileHandler serves the public profile pages and avatar images. Its routes sit
+// outside the API so profiles have short, shareable URLs
+type ProfileHandler struct {
+	userService *application.UserService
+}
+
+func NewProfileHandler(userService *application.UserService) *ProfileHandler {
+	return &ProfileHandler{
+		userService: userService,
+	}
+}
+
+func (h ProfileHandler) Register(mux chi.Router) {
+	// Get a user's public profile
+	mux.Get("/u/{username}", h.GetProfile)
+
+	// Get one size of a user's avatar
+	mux.Get("/avatars/{userID}/{version}/{size}.png", h.GetAvatar)
+}
+
+type profileResponse struct {
+	application.ProfileDTO
+	Avatars map[string]string `json:"avatars"`
+}
+
+func (h ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+
+	profile, err := h.userService.GetProfile(username)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// Links to a username that has since been changed follow the user. The
+			// redirect isn't permanent, as the old username is only reserved for a while
+			if current, err := h.userService.ResolveRenamedUsername(username); err == nil {
+				http.Redirect(w, r, "/u/"+url.PathEscape(current), http.StatusFound)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		log.Println("GetProfile: failed to get profile")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(profileResponse{
+		ProfileDTO: *profile,
+		Avatars:    avatarURLs(profile.UserID, profile.AvatarVersion),
+	})
+	if err != nil {
+		log.Println("GetProfile: failed to marshal profile")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h ProfileHandler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	size, err := strconv.Atoi(chi.URLParam(r, "size"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	avatar, err := h.userService.OpenAvatar(
+		chi.URLParam(r, "userID"),
+		chi.URLParam(r, "version"),
+		size,
+	)
+	if err != nil {
+		if errors.Is(err, domain.ErrAvatarNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		log.Println("GetAvatar: failed to open avatar")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer avatar.Close()
+
+	// A new upload gets a new version, so each URL's image never changes
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if _, err := io.Copy(w, avatar); err != nil {
+		log.Println("GetAvatar: failed to write avatar")
+	}
+}
+
+// avatarURLs lists the URL of each avatar size, keyed by size. It's empty if the user
+// hasn't uploaded an avatar
+func avatarURLs(userID, version string) map[string]string {
+	urls := map[string]string{}
+	if version == "" {
+		return urls
+	}
+
+	for _, size := range domain.AvatarSizes {
+		urls[strconv.Itoa(size)] = fmt.Sprintf("/avatars/%s/%s/%d.png", userID, version, size)
+	}
+	return urls
+}
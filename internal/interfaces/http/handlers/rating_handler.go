@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 
 	"blog/internal/application"
+	"blog/internal/domain"
 	"blog/internal/interfaces/http/middleware"
 	"blog/internal/interfaces/http/requests"
 
@@ -130,8 +132,13 @@ func (h RatingHandler) CreateRating(w http.ResponseWriter, r *http.Request) {
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
 	// Create the rating
-	rating, err := h.ratingService.CreateRating(req.PostID, userID, req.RatingType)
+	rating, err := h.ratingService.CreateRating(r.Context(), req.PostID, userID, req.RatingType)
 	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("CreateRating: create denied")
+			writeForbidden(w)
+			return
+		}
 		log.Println("CreateRating: failed to create rating")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -168,22 +175,13 @@ func (h RatingHandler) ChangeRating(w http.ResponseWriter, r *http.Request) {
 	// Get the userID making the request
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
-	// Validate the rating belongs to the user
-	rating, err := h.ratingService.GetRating(req.RatingID)
-	if err != nil {
-		log.Println("ChangeRating: failed to get rating")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if rating.UserID != userID {
-		log.Println("ChangeRating: wrong user attempting to change rating")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
 	// Update the rating
-	if err := h.ratingService.UpdateRating(req.RatingID, req.RatingType); err != nil {
+	if err := h.ratingService.UpdateRating(r.Context(), userID, req.RatingID, req.RatingType); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("ChangeRating: change denied")
+			writeForbidden(w)
+			return
+		}
 		log.Println("ChangeRating: failed to update rating")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -211,22 +209,13 @@ func (h RatingHandler) RemoveRating(w http.ResponseWriter, r *http.Request) {
 	// Get the userID making the request
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
-	// Validate the rating belongs to the user
-	rating, err := h.ratingService.GetRating(req.RatingID)
-	if err != nil {
-		log.Println("RemoveRating: failed to get rating")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if rating.UserID != userID {
-		log.Println("RemoveRating: wrong user attempting to remove rating")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
 	// Remove the rating
-	if err := h.ratingService.RemoveRating(req.RatingID); err != nil {
+	if err := h.ratingService.RemoveRating(r.Context(), userID, req.RatingID); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			log.Println("RemoveRating: remove denied")
+			writeForbidden(w)
+			return
+		}
 		log.Println("RemoveRating: failed to remove rating")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
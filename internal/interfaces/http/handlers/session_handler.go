@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"blog/internal/application"
+	"blog/internal/domain"
+	"blog/internal/interfaces/http/middleware"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+type SessionHandler struct {
+	sessionService *application.SessionService
+	sessionManager *scs.SessionManager
+}
+
+func NewSessionHandler(
+	sessionService *application.SessionService,
+	sessionManager *scs.SessionManager,
+) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		sessionManager: sessionManager,
+	}
+}
+
+func (h SessionHandler) Register(mux chi.Router) {
+	mux.Route("/users/me/sessions", func(r chi.Router) {
+		// Protected routes
+		r.Use(middleware.RequireAuth(h.sessionManager))
+
+		// List the active sessions
+		r.Get("/", h.GetSessions)
+
+		r.Group(func(r chi.Router) {
+			// An admin impersonating the user can't sign them out of their devices
+			r.Use(middleware.BlockImpersonation(h.sessionManager))
+
+			// Revoke all sessions
+			r.Delete("/", h.RevokeAllSessions)
+
+			// Revoke a single session
+			r.Delete("/{id}", h.RevokeSession)
+		})
+	})
+}
+
+func (h SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+	token := h.sessionManager.Token(r.Context())
+
+	sessions, err := h.sessionService.GetUserSessions(userID, token)
+	if err != nil {
+		log.Println("GetSessions: failed to get sessions")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(sessions)
+	if err != nil {
+		log.Println("GetSessions: failed to marshal sessions")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("missing session id"))
+		return
+	}
+
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	// Revoking the session making the request is the same as logging out
+	if current, err := h.sessionService.GetSessionByToken(
+		h.sessionManager.Token(r.Context()),
+	); err == nil && current.ID == sessionID {
+		if err := h.sessionManager.Destroy(r.Context()); err != nil {
+			log.Println("RevokeSession: failed to destroy current session")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		log.Println("RevokeSession: failed to revoke session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h SessionHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	if err := h.sessionService.RevokeAllSessions(userID); err != nil {
+		log.Println("RevokeAllSessions: failed to revoke sessions")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The current session was revoked as well, so make sure it isn't committed again
+	if err := h.sessionManager.Destroy(r.Context()); err != nil {
+		log.Println("RevokeAllSessions: failed to destroy current session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
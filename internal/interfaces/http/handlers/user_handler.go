@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"archive/zip"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"blog/internal/application"
+	"blog/internal/domain"
 	"blog/internal/interfaces/http/middleware"
 	"blog/internal/interfaces/http/requests"
 
@@ -13,18 +20,27 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+// maxAvatarUploadSize caps the size of an avatar upload, in bytes
+const maxAvatarUploadSize = 5 << 20
+
 type UserHandler struct {
-	userService    *application.UserService
-	sessionManager *scs.SessionManager
+	userService          *application.UserService
+	sessionService       *application.SessionService
+	impersonationService *application.ImpersonationService
+	sessionManager       *scs.SessionManager
 }
 
 func NewUserHandler(
 	userService *application.UserService,
+	sessionService *application.SessionService,
+	impersonationService *application.ImpersonationService,
 	sessionManager *scs.SessionManager,
 ) *UserHandler {
 	return &UserHandler{
-		userService:    userService,
-		sessionManager: sessionManager,
+		userService:          userService,
+		sessionService:       sessionService,
+		impersonationService: impersonationService,
+		sessionManager:       sessionManager,
 	}
 }
 
@@ -45,15 +61,44 @@ func (h UserHandler) Register(mux chi.Router) {
 		// Get users
 		r.Get("/", h.GetUsers)
 
+		// Confirm an email change with the token sent to the new address
+		r.Post("/email/confirm", h.ConfirmEmailChange)
+
 		r.Group(func(r chi.Router) {
 			// Protected routes
 			r.Use(middleware.RequireAuth(h.sessionManager))
 
-			// Update user description
-			r.Post("/description", h.UpdateUserDescription)
+			// Update the user's profile
+			r.Put("/me/profile", h.UpdateProfile)
+
+			// Upload a new avatar
+			r.Put("/me/avatar", h.UploadAvatar)
+
+			// Remove the user's avatar
+			r.Delete("/me/avatar", h.RemoveAvatar)
+
+			// List the user's past emails and usernames
+			r.Get("/me/history", h.GetIdentityHistory)
+
+			r.Group(func(r chi.Router) {
+				// Sensitive routes, which an admin impersonating the user can't use
+				r.Use(middleware.BlockImpersonation(h.sessionManager))
+
+				// Change the user's email, once the new address is confirmed
+				r.Put("/me/email", h.ChangeEmail)
 
-			// Update user password
-			r.Post("/password", h.UpdateUserPassword)
+				// Change the user's username
+				r.Put("/me/username", h.ChangeUsername)
+
+				// Update user password
+				r.Post("/password", h.UpdateUserPassword)
+
+				// Download everything stored about the user
+				r.Get("/me/export", h.ExportUserData)
+
+				// Delete the user's account after a grace period
+				r.Delete("/me", h.DeleteAccount)
+			})
 		})
 	})
 }
@@ -76,12 +121,23 @@ func (h UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 
 	// Register the user
 	user, err := h.userService.CreateUser(
+		r.Context(),
 		req.Email,
 		req.Password,
 		req.Username,
 		[]string{"COMMENTER"},
 	)
 	if err != nil {
+		if errors.Is(err, domain.ErrEmailTaken) || errors.Is(err, domain.ErrUsernameTaken) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		if errors.Is(err, domain.ErrWeakPassword) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
 		log.Println("RegisterUser: failed to create user")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -118,21 +174,70 @@ func (h UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate the password and get the user if valid
-	user, err := h.userService.ValidatePassword(req.Username, req.Password)
+	user, err := h.userService.Login(r.Context(), req.Username, req.Password, clientIP(r))
 	if err != nil {
-		log.Println("LoginUser: failed to get user")
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("incorrect username or password"))
+		var throttledErr *application.LoginThrottledError
+		switch {
+		case errors.As(err, &throttledErr):
+			log.Println("LoginUser: login throttled")
+			retryAfter := int(math.Ceil(throttledErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte("too many failed login attempts"))
+		case errors.Is(err, domain.ErrUserLockedOut):
+			log.Println("LoginUser: user locked out")
+			w.WriteHeader(http.StatusLocked)
+			w.Write([]byte("account temporarily locked"))
+		case errors.Is(err, domain.ErrUserSuspended), errors.Is(err, domain.ErrUserBanned):
+			log.Println("LoginUser: user suspended or banned")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(err.Error()))
+		case errors.Is(err, application.ErrInvalidCredentials):
+			log.Println("LoginUser: failed to get user")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("incorrect username or password"))
+		default:
+			log.Println("LoginUser: failed to validate credentials")
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Renew the session token to prevent session fixation
+	if err := h.sessionManager.RenewToken(r.Context()); err != nil {
+		log.Println("LoginUser: failed to renew session token")
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	// Add the user ID to the session
 	h.sessionManager.Put(r.Context(), "user_id", user.ID)
 
+	// Record the device the session was started from
+	if _, err := h.sessionService.StartSession(
+		h.sessionManager.Token(r.Context()),
+		user.ID,
+		r.UserAgent(),
+		clientIP(r),
+		h.sessionManager.Deadline(r.Context()),
+	); err != nil {
+		log.Println("LoginUser: failed to start session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
 
 func (h UserHandler) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	// Logging out of an impersonation session ends the impersonation
+	if impersonationID := h.sessionManager.GetString(r.Context(), "impersonation_id"); impersonationID != "" {
+		if _, err := h.impersonationService.StopImpersonation(r.Context(), impersonationID); err != nil &&
+			!errors.Is(err, domain.ErrImpersonationEnded) {
+			log.Printf("LogoutUser: failed to stop impersonation: %v", err)
+		}
+	}
+
 	if err := h.sessionManager.Destroy(r.Context()); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -199,7 +304,7 @@ func (h UserHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
 	// Update the user's roles
-	if err := h.userService.SetUserRoles(userID, req.UserRoles); err != nil {
+	if err := h.userService.SetUserRoles(r.Context(), userID, req.UserRoles); err != nil {
 		log.Println("SetUserRoles: failed to set user's roles")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -208,17 +313,17 @@ func (h UserHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h UserHandler) UpdateUserDescription(w http.ResponseWriter, r *http.Request) {
+func (h UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	// Decode the request and validate it
-	var req requests.UpdateUserDescriptionRequest
+	var req requests.UpdateProfileRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Println("UpdateUserDescription: failed to decode request")
+		log.Println("UpdateProfile: failed to decode request")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	if errors := req.Validate(); errors != nil {
-		log.Println("UpdateUserDescription: invalid request data")
+		log.Println("UpdateProfile: invalid request data")
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(errors.Error()))
 		return
@@ -227,16 +332,73 @@ func (h UserHandler) UpdateUserDescription(w http.ResponseWriter, r *http.Reques
 	// Get the userID making the request
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
-	// Update the user's description
-	if err := h.userService.UpdateDescription(userID, req.Description); err != nil {
-		log.Println("UpdateUserDescription: failed to update user's description")
-		w.WriteHeader(http.StatusInternalServerError)
+	// Update the user's profile
+	if err := h.userService.UpdateProfile(
+		r.Context(),
+		userID,
+		req.DisplayName,
+		req.Description,
+		req.Location,
+		toProfileLinkDTOs(req.Links),
+	); err != nil {
+		writeProfileError(w, "UpdateProfile", err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAvatarUploadSize)
+
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			log.Println("UploadAvatar: upload too large")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		log.Println("UploadAvatar: missing avatar file")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// Get the userID making the request
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	user, err := h.userService.SetAvatar(r.Context(), userID, file)
+	if err != nil {
+		writeProfileError(w, "UploadAvatar", err)
+		return
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"avatars": avatarURLs(user.ID, user.AvatarVersion),
+	})
+	if err != nil {
+		log.Println("UploadAvatar: failed to marshal response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h UserHandler) RemoveAvatar(w http.ResponseWriter, r *http.Request) {
+	// Get the userID making the request
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	if err := h.userService.RemoveAvatar(r.Context(), userID); err != nil {
+		writeProfileError(w, "RemoveAvatar", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h UserHandler) UpdateUserPassword(w http.ResponseWriter, r *http.Request) {
 	// Decode the request and validate it
 	var req requests.UpdateUserPasswordRequest
@@ -257,7 +419,12 @@ func (h UserHandler) UpdateUserPassword(w http.ResponseWriter, r *http.Request)
 	userID := h.sessionManager.GetString(r.Context(), "user_id")
 
 	// Update the user's password
-	if err := h.userService.UpdatePassword(userID, req.Password); err != nil {
+	if err := h.userService.UpdatePassword(r.Context(), userID, req.Password); err != nil {
+		if errors.Is(err, domain.ErrWeakPassword) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
 		log.Println("UpdateUserPassword: failed to update user's password")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -265,3 +432,288 @@ func (h UserHandler) UpdateUserPassword(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h UserHandler) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	// Get the userID making the request
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	export, err := h.userService.ExportUserData(userID)
+	if err != nil {
+		log.Println("ExportUserData: failed to export user data")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/zip")
+	w.Header().Add(
+		"Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s-export.zip"`, export.Profile.Username),
+	)
+
+	// Each part of the export goes in its own JSON file
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"history.json", export.History},
+		{"posts.json", export.Posts},
+		{"comments.json", export.Comments},
+		{"ratings.json", export.Ratings},
+		{"bookmarks.json", export.Bookmarks},
+		{"bookmark_folders.json", export.BookmarkFolders},
+	}
+
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			log.Println("ExportUserData: failed to add file to archive")
+			return
+		}
+
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			log.Println("ExportUserData: failed to write file to archive")
+			return
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		log.Println("ExportUserData: failed to finish archive")
+	}
+}
+
+func (h UserHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("DeleteAccount: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("DeleteAccount: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	// Get the userID making the request
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	user, err := h.userService.RequestAccountDeletion(r.Context(), userID, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidCredentials):
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("incorrect password"))
+		case errors.Is(err, domain.ErrDeletionRequested):
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(err.Error()))
+		default:
+			log.Println("DeleteAccount: failed to request account deletion")
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// The account's sessions are gone, including this one
+	if err := h.sessionManager.Destroy(r.Context()); err != nil {
+		log.Println("DeleteAccount: failed to destroy session")
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"delete_after": user.DeleteAfter,
+	})
+	if err != nil {
+		log.Println("DeleteAccount: failed to marshal response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(data)
+}
+
+func (h UserHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("ChangeEmail: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("ChangeEmail: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	// Get the userID making the request
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	if err := h.userService.RequestEmailChange(
+		r.Context(),
+		userID,
+		req.Password,
+		req.Email,
+	); err != nil {
+		writeAccountChangeError(w, "ChangeEmail", err)
+		return
+	}
+
+	// The change waits for the new address to be confirmed
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h UserHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.ConfirmEmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("ConfirmEmailChange: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("ConfirmEmailChange: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	if err := h.userService.ConfirmEmailChange(r.Context(), req.Token); err != nil {
+		writeAccountChangeError(w, "ConfirmEmailChange", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h UserHandler) ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	// Decode the request and validate it
+	var req requests.ChangeUsernameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("ChangeUsername: failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if errors := req.Validate(); errors != nil {
+		log.Println("ChangeUsername: invalid request data")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errors.Error()))
+		return
+	}
+
+	// Get the userID making the request
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	user, err := h.userService.ChangeUsername(r.Context(), userID, req.Username)
+	if err != nil {
+		if errors.Is(err, domain.ErrUsernameChangeTooSoon) {
+			// Tell the user when they can try again
+			if next, nextErr := h.userService.NextUsernameChange(userID); nextErr == nil && next != nil {
+				retryAfter := int(math.Ceil(time.Until(*next).Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			}
+		}
+		writeAccountChangeError(w, "ChangeUsername", err)
+		return
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"username": user.Username,
+	})
+	if err != nil {
+		log.Println("ChangeUsername: failed to marshal response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h UserHandler) GetIdentityHistory(w http.ResponseWriter, r *http.Request) {
+	// Get the userID making the request
+	userID := h.sessionManager.GetString(r.Context(), "user_id")
+
+	history, err := h.userService.GetIdentityHistory(userID)
+	if err != nil {
+		log.Println("GetIdentityHistory: failed to get history")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		log.Println("GetIdentityHistory: failed to marshal history")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeAccountChangeError responds to a failed email or username change
+func writeAccountChangeError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, application.ErrInvalidCredentials):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("incorrect password"))
+	case errors.Is(err, domain.ErrEmailTaken), errors.Is(err, domain.ErrUsernameTaken):
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrEmailUnchanged),
+		errors.Is(err, domain.ErrUsernameUnchanged),
+		errors.Is(err, domain.ErrNoEmailChangePending),
+		errors.Is(err, domain.ErrInvalidEmailChangeToken):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrEmailChangeExpired):
+		w.WriteHeader(http.StatusGone)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrUsernameChangeTooSoon):
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// writeProfileError responds to a failed profile or avatar change
+func writeProfileError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrAvatarNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+	case errors.Is(err, domain.ErrDisplayNameTooLong),
+		errors.Is(err, domain.ErrDescriptionTooLong),
+		errors.Is(err, domain.ErrLocationTooLong),
+		errors.Is(err, domain.ErrTooManyProfileLinks),
+		errors.Is(err, domain.ErrInvalidProfileLink),
+		errors.Is(err, domain.ErrInvalidAvatar):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+	default:
+		log.Printf("%s: %v", caller, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func toProfileLinkDTOs(links []requests.ProfileLinkRequest) []application.ProfileLinkDTO {
+	linkDTOs := []application.ProfileLinkDTO{}
+	for _, link := range links {
+		linkDTOs = append(linkDTOs, application.ProfileLinkDTO{Kind: link.Kind, URL: link.URL})
+	}
+	return linkDTOs
+}
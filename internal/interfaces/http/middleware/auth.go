@@ -1,10 +1,11 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
-	"slices"
 
 	"blog/internal/application"
+	"blog/internal/domain"
 
 	"github.com/alexedwards/scs/v2"
 )
@@ -25,9 +26,11 @@ func RequireAuth(sessionManager *scs.SessionManager) func(http.Handler) http.Han
 	}
 }
 
-func RequireAdminAuth(
+// RequireAuthorization only lets the request through if the session's user may perform the action
+func RequireAuthorization(
 	sessionManager *scs.SessionManager,
-	userService *application.UserService,
+	authorizer *application.Authorizer,
+	action domain.Action,
 ) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -38,18 +41,17 @@ func RequireAdminAuth(
 				return
 			}
 
-			// Get the user and validate that they've got the admin permission
-			user, err := userService.GetUserByID(userID)
-			if err != nil {
+			// Check the action against the policy
+			if err := authorizer.Authorize(userID, action, nil); err != nil {
+				if errors.Is(err, domain.ErrForbidden) {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte("forbidden"))
+					return
+				}
 				w.WriteHeader(http.StatusInternalServerError)
 				return
 			}
 
-			if !slices.Contains(user.UserRoles, "ADMIN") {
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
-
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"blog/pkg/ddd"
+
+	"github.com/alexedwards/scs/v2"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// EventMetadata puts the metadata for the events the request causes in its context: the
+// request ID as their correlation and the session's user as their actor. It has to run
+// after the request ID and session are loaded
+func EventMetadata(sessionManager *scs.SessionManager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := ddd.WithEventMetadata(r.Context(), ddd.EventMetadata{
+				CorrelationID: chimiddleware.GetReqID(r.Context()),
+				ActorID:       sessionManager.GetString(r.Context(), "user_id"),
+			})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
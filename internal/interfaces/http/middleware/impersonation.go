@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"blog/internal/domain"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// LogImpersonation logs the admin and the user they're acting as for every request made
+// in an impersonation session
+func LogImpersonation(sessionManager *scs.SessionManager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			impersonatorID := sessionManager.GetString(r.Context(), "impersonator_id")
+			if impersonatorID != "" {
+				log.Printf(
+					"Impersonation: admin %s acting as user %s: %s %s",
+					impersonatorID,
+					sessionManager.GetString(r.Context(), "user_id"),
+					r.Method,
+					r.URL.Path,
+				)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BlockImpersonation keeps sensitive actions, such as changing the password or deleting
+// the account, to the user themselves
+func BlockImpersonation(sessionManager *scs.SessionManager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if sessionManager.GetString(r.Context(), "impersonator_id") != "" {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(domain.ErrForbiddenWhileImpersonating.Error()))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package requests
+
+import "blog/pkg/ddd/validation"
+
+type CreateBookmarkRequest struct {
+	PostID   string `json:"post_id"`
+	FolderID string `json:"folder_id"`
+	Note     string `json:"note"`
+}
+
+func (r CreateBookmarkRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.PostID, "post_id"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+// UpdateBookmarkRequest replaces the bookmark's folder and note. An empty folder_id
+// unfiles the bookmark. Both are optional, so there's nothing to validate
+type UpdateBookmarkRequest struct {
+	FolderID string `json:"folder_id"`
+	Note     string `json:"note"`
+}
+
+type BookmarkFolderRequest struct {
+	Name string `json:"name"`
+}
+
+func (r BookmarkFolderRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Name, "name"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
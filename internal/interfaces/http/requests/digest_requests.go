@@ -0,0 +1,24 @@
+package requests
+
+import "blog/pkg/ddd/validation"
+
+// DigestSettingsRequest sets how often the user gets email digests: off, daily or
+// weekly
+type DigestSettingsRequest struct {
+	Frequency string `json:"frequency"`
+}
+
+func (r DigestSettingsRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Frequency, "frequency"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package requests
+
+import "blog/pkg/ddd/validation"
+
+// NewsletterSubscribeRequest subscribes an address to every new post, or only to the
+// author's when an author ID is given
+type NewsletterSubscribeRequest struct {
+	Email    string `json:"email"`
+	AuthorID string `json:"author_id"`
+}
+
+func (r NewsletterSubscribeRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Email, "email"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.Email(r.Email, "email"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+// NewsletterFeedbackRequest is a bounce or complaint reported by the mail provider
+type NewsletterFeedbackRequest struct {
+	Email string `json:"email"`
+	Type  string `json:"type"`
+}
+
+func (r NewsletterFeedbackRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Email, "email"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.Required(r.Type, "type"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package requests
+
+// MarkNotificationsReadRequest lists the notifications to mark read. With no IDs, or
+// no body at all, every unread notification is marked read
+type MarkNotificationsReadRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// NotificationPreferencesRequest replaces the types of notification the user has
+// muted. The service rejects unknown types
+type NotificationPreferencesRequest struct {
+	Muted []string `json:"muted"`
+}
@@ -0,0 +1,56 @@
+package requests
+
+import "blog/pkg/ddd/validation"
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+func (r CreateRoleRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Name, "name"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.Pattern(
+		r.Name,
+		"name",
+		`^[A-Z][A-Z0-9_]{1,31}$`,
+		"must be 2-32 uppercase letters, digits or underscores",
+	); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.MaxLength(r.Description, "description", 255); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+type SetRolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+func (r SetRolePermissionsRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.RequiredStringSlice(r.Permissions, "permissions"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
@@ -1,6 +1,11 @@
 package requests
 
-import "blog/pkg/ddd/validation"
+import (
+	"fmt"
+	"time"
+
+	"blog/pkg/ddd/validation"
+)
 
 type RegisterUserRequest struct {
 	Email    string `json:"email"`
@@ -32,10 +37,6 @@ func (r RegisterUserRequest) Validate() *validation.Errors {
 		errors.ValidationErrors = append(errors.ValidationErrors, *err)
 	}
 
-	if err := v.MinLength(r.Password, "password", 8); err != nil {
-		errors.ValidationErrors = append(errors.ValidationErrors, *err)
-	}
-
 	if errors.HasErrors() {
 		return errors
 	}
@@ -90,18 +91,48 @@ func (r SetUserRolesRequest) Validate() *validation.Errors {
 	return nil
 }
 
-type UpdateUserDescriptionRequest struct {
-	Description string `json:"description"`
+type ProfileLinkRequest struct {
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+}
+
+type UpdateProfileRequest struct {
+	DisplayName string               `json:"display_name"`
+	Description string               `json:"description"`
+	Location    string               `json:"location"`
+	Links       []ProfileLinkRequest `json:"links"`
 }
 
-func (r UpdateUserDescriptionRequest) Validate() *validation.Errors {
+func (r UpdateProfileRequest) Validate() *validation.Errors {
 	v := validation.New()
 	errors := validation.NewErrors()
 
-	if err := v.Required(r.Description, "description"); err != nil {
+	if err := v.MaxLength(r.DisplayName, "display_name", 50); err != nil {
 		errors.ValidationErrors = append(errors.ValidationErrors, *err)
 	}
 
+	if err := v.MaxLength(r.Description, "description", 255); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.MaxLength(r.Location, "location", 100); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.MaxValue(len(r.Links), "links", 5); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	for i, link := range r.Links {
+		if err := v.Required(link.Kind, fmt.Sprintf("links[%d].kind", i)); err != nil {
+			errors.ValidationErrors = append(errors.ValidationErrors, *err)
+		}
+
+		if err := v.Required(link.URL, fmt.Sprintf("links[%d].url", i)); err != nil {
+			errors.ValidationErrors = append(errors.ValidationErrors, *err)
+		}
+	}
+
 	if errors.HasErrors() {
 		return errors
 	}
@@ -121,7 +152,166 @@ func (r UpdateUserPasswordRequest) Validate() *validation.Errors {
 		errors.ValidationErrors = append(errors.ValidationErrors, *err)
 	}
 
-	if err := v.MinLength(r.Password, "password", 8); err != nil {
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+type SuspendUserRequest struct {
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason"`
+}
+
+func (r SuspendUserRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.RequiredTime(r.Until, "until"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.Required(r.Reason, "reason"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.MaxLength(r.Reason, "reason", 255); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+type BanUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (r BanUserRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Reason, "reason"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.MaxLength(r.Reason, "reason", 255); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+func (r DeleteAccountRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Password, "password"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (r ChangeEmailRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Email, "email"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.Email(r.Email, "email"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.Required(r.Password, "password"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token"`
+}
+
+func (r ConfirmEmailChangeRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Token, "token"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+type ChangeUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+func (r ChangeUsernameRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Username, "username"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.MinLength(r.Username, "username", 3); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if errors.HasErrors() {
+		return errors
+	}
+
+	return nil
+}
+
+type StartImpersonationRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (r StartImpersonationRequest) Validate() *validation.Errors {
+	v := validation.New()
+	errors := validation.NewErrors()
+
+	if err := v.Required(r.Reason, "reason"); err != nil {
+		errors.ValidationErrors = append(errors.ValidationErrors, *err)
+	}
+
+	if err := v.MaxLength(r.Reason, "reason", 255); err != nil {
 		errors.ValidationErrors = append(errors.ValidationErrors, *err)
 	}
 
@@ -6,6 +6,7 @@ import (
 
 	"blog/internal/application"
 	"blog/internal/interfaces/http/handlers"
+	authmiddleware "blog/internal/interfaces/http/middleware"
 
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-chi/chi/v5"
@@ -17,8 +18,23 @@ func NewRouter(
 	userService *application.UserService,
 	commentService *application.CommentService,
 	ratingService *application.RatingService,
+	sessionService *application.SessionService,
+	roleService *application.RoleService,
+	impersonationService *application.ImpersonationService,
+	followService *application.FollowService,
+	feedService application.FeedService,
+	bookmarkService *application.BookmarkService,
+	notificationService *application.NotificationService,
+	digestService *application.DigestService,
+	newsletterService *application.NewsletterService,
+	deadLetterService *application.DeadLetterService,
+	eventService *application.EventService,
+	projectionService *application.ProjectionService,
+	authorizer *application.Authorizer,
+	sessionStore scs.Store,
 ) *chi.Mux {
 	sessionManager := scs.New()
+	sessionManager.Store = sessionStore
 	sessionManager.Lifetime = 24 * time.Hour
 
 	r := chi.NewRouter()
@@ -27,22 +43,76 @@ func NewRouter(
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
 	r.Use(sessionManager.LoadAndSave)
+	r.Use(authmiddleware.LogImpersonation(sessionManager))
+	r.Use(authmiddleware.EventMetadata(sessionManager))
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
+	// Public profiles and avatars
+	profileHandler := handlers.NewProfileHandler(userService)
+	profileHandler.Register(r)
+
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
 		postHandler := handlers.NewPostHandler(postService, sessionManager)
 		postHandler.Register(r)
 
-		userHandler := handlers.NewUserHandler(userService, sessionManager)
+		userHandler := handlers.NewUserHandler(
+			userService,
+			sessionService,
+			impersonationService,
+			sessionManager,
+		)
 		userHandler.Register(r)
 
+		sessionHandler := handlers.NewSessionHandler(sessionService, sessionManager)
+		sessionHandler.Register(r)
+
+		impersonationHandler := handlers.NewImpersonationHandler(
+			impersonationService,
+			sessionService,
+			sessionManager,
+		)
+		impersonationHandler.Register(r)
+
+		followHandler := handlers.NewFollowHandler(followService, sessionManager)
+		followHandler.Register(r)
+
+		feedHandler := handlers.NewFeedHandler(feedService, sessionManager)
+		feedHandler.Register(r)
+
+		bookmarkHandler := handlers.NewBookmarkHandler(bookmarkService, sessionManager)
+		bookmarkHandler.Register(r)
+
+		notificationHandler := handlers.NewNotificationHandler(notificationService, sessionManager)
+		notificationHandler.Register(r)
+
+		digestHandler := handlers.NewDigestHandler(digestService, sessionManager)
+		digestHandler.Register(r)
+
+		newsletterHandler := handlers.NewNewsletterHandler(newsletterService)
+		newsletterHandler.Register(r)
+
 		ratingHandler := handlers.NewRatingHandler(ratingService, sessionManager)
 		ratingHandler.Register(r)
+
+		adminHandler := handlers.NewAdminHandler(
+			userService,
+			postService,
+			commentService,
+			sessionService,
+			roleService,
+			impersonationService,
+			deadLetterService,
+			eventService,
+			projectionService,
+			authorizer,
+			sessionManager,
+		)
+		adminHandler.Register(r)
 	})
 
 	return r
@@ -9,6 +9,7 @@ import (
 type AggregateBase struct {
 	id     string        // Aggregate ID
 	events []DomainEvent // Uncommitted events
+	cause  EventMetadata // Metadata of what caused the uncommitted events
 	mu     sync.Mutex    // Thread safety
 }
 
@@ -34,6 +35,23 @@ func (a *AggregateBase) MarkEventsAsCommitted() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.events = []DomainEvent{}
+	a.cause = EventMetadata{}
+}
+
+// SetEventMetadata records the metadata of the request or event that caused the
+// aggregate's changes, so the events it raises can be traced back to it
+// This should be called before the aggregate is saved
+func (a *AggregateBase) SetEventMetadata(cause EventMetadata) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cause = cause
+}
+
+// EventMetadata returns the metadata of what caused the uncommitted events
+func (a *AggregateBase) EventMetadata() EventMetadata {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cause
 }
 
 // GetID returns the aggregate's unique identifier
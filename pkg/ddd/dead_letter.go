@@ -0,0 +1,142 @@
+package ddd
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDeadLetterNotFound is returned when there is no dead letter with the ID
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrDeadLetterResolved is returned when replaying or discarding a dead letter that
+	// has already been replayed or discarded
+	ErrDeadLetterResolved = errors.New("dead letter is already resolved")
+	// ErrUnknownDeadLetterStatus is returned when listing dead letters by a status that
+	// doesn't exist
+	ErrUnknownDeadLetterStatus = errors.New("unknown dead letter status")
+)
+
+// DeadLetterStatus is whether a dead letter is still waiting for someone to deal with it
+type DeadLetterStatus string
+
+const (
+	DeadLetterStatusPending   DeadLetterStatus = "pending"
+	DeadLetterStatusReplayed  DeadLetterStatus = "replayed"
+	DeadLetterStatusDiscarded DeadLetterStatus = "discarded"
+)
+
+func (s DeadLetterStatus) String() string {
+	return string(s)
+}
+
+// ParseDeadLetterStatus returns the status with the name, or ErrUnknownDeadLetterStatus
+func ParseDeadLetterStatus(status string) (DeadLetterStatus, error) {
+	switch DeadLetterStatus(status) {
+	case DeadLetterStatusPending, DeadLetterStatusReplayed, DeadLetterStatusDiscarded:
+		return DeadLetterStatus(status), nil
+	default:
+		return "", ErrUnknownDeadLetterStatus
+	}
+}
+
+// DeadLetter is an event that one of its handlers kept failing on, kept so it can be
+// looked into and replayed to that handler or discarded
+type DeadLetter struct {
+	ID int64
+	// Subscription names the handler that failed, see RetryingEventDispatcher
+	Subscription string
+	EventType    string
+	EventVersion int
+	Metadata     EventMetadata
+	Payload      []byte
+	OccurredOn   time.Time
+	Attempts     int
+	LastError    string
+	Status       DeadLetterStatus
+	FailedAt     time.Time
+	ResolvedAt   *time.Time
+}
+
+// NewDeadLetter encodes the event the subscription gave up on, with the metadata it was
+// dispatched with. The ID is assigned when the dead letter is stored
+func NewDeadLetter(
+	subscription string,
+	event DomainEvent,
+	metadata EventMetadata,
+	attempts int,
+	err error,
+	at time.Time,
+) (DeadLetter, error) {
+	envelope, encodeErr := EventRegistry.Encode("", event)
+	if encodeErr != nil {
+		return DeadLetter{}, encodeErr
+	}
+
+	return DeadLetter{
+		Subscription: subscription,
+		EventType:    envelope.Type,
+		EventVersion: envelope.Version,
+		Metadata:     metadata,
+		Payload:      envelope.Payload,
+		OccurredOn:   envelope.OccurredOn,
+		Attempts:     attempts,
+		LastError:    err.Error(),
+		Status:       DeadLetterStatusPending,
+		FailedAt:     at,
+	}, nil
+}
+
+// Event decodes the dead letter back into the event it was made from
+func (l DeadLetter) Event() (DomainEvent, error) {
+	return EventRegistry.Decode(EventEnvelope{
+		Type:       l.EventType,
+		Version:    l.EventVersion,
+		OccurredOn: l.OccurredOn,
+		Metadata:   l.Metadata,
+		Payload:    l.Payload,
+	})
+}
+
+// MarkReplayed records that the event was handled when replayed
+func (l *DeadLetter) MarkReplayed(at time.Time) error {
+	if l.Status != DeadLetterStatusPending {
+		return ErrDeadLetterResolved
+	}
+	l.Status = DeadLetterStatusReplayed
+	l.Attempts++
+	l.LastError = ""
+	l.ResolvedAt = &at
+	return nil
+}
+
+// RecordReplayFailure counts a replay that failed, leaving the dead letter pending
+func (l *DeadLetter) RecordReplayFailure(err error, at time.Time) {
+	l.Attempts++
+	l.LastError = err.Error()
+	l.FailedAt = at
+}
+
+// Discard gives up on the event
+func (l *DeadLetter) Discard(at time.Time) error {
+	if l.Status != DeadLetterStatusPending {
+		return ErrDeadLetterResolved
+	}
+	l.Status = DeadLetterStatusDiscarded
+	l.ResolvedAt = &at
+	return nil
+}
+
+// DeadLetterStore keeps the events that handlers gave up on
+type DeadLetterStore interface {
+	// Add stores the dead letter, returning it with its ID
+	Add(letter DeadLetter) (DeadLetter, error)
+	// FindByID returns the dead letter, or ErrDeadLetterNotFound
+	FindByID(id int64) (DeadLetter, error)
+	// List returns the dead letters with the status, or all of them if the status is
+	// empty, most recently failed first
+	List(status DeadLetterStatus) ([]DeadLetter, error)
+	// Update saves the dead letter's status and attempts
+	Update(letter DeadLetter) error
+	// CountByStatus returns how many dead letters have each status
+	CountByStatus() (map[DeadLetterStatus]int, error)
+}
@@ -0,0 +1,186 @@
+package ddd
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// HandlerInfo describes the subscription a handler middleware wraps
+type HandlerInfo struct {
+	EventType string
+	// Name is the name the handler was subscribed under
+	Name string
+}
+
+// DispatchFunc dispatches an event, for the aggregate with the ID if it isn't empty
+type DispatchFunc func(ctx context.Context, aggregateID string, event DomainEvent) error
+
+// DispatchMiddleware wraps the dispatch of every event
+type DispatchMiddleware func(next DispatchFunc) DispatchFunc
+
+// HandlerMiddleware wraps a subscribed handler, so it runs each time the handler does
+type HandlerMiddleware func(info HandlerInfo, next ContextEventHandlerFunc) ContextEventHandlerFunc
+
+// EventMiddleware is a dispatcher middleware, wrapping the dispatch of events, the
+// handlers they're dispatched to, or both. Either may be nil
+type EventMiddleware struct {
+	Dispatch DispatchMiddleware
+	Handle   HandlerMiddleware
+}
+
+// MiddlewareEventDispatcher wraps another dispatcher, running every event through a
+// chain of middlewares on its way to the dispatcher and every handler through a chain
+// on its way to being subscribed. The first middleware is the outermost, and handlers
+// can be given middlewares of their own with SubscribeWith, which run inside the
+// dispatcher's
+//
+// Handlers are subscribed to the wrapped dispatcher under the name they were given, so
+// one that keeps track of them by name, as RetryingEventDispatcher does, sees the same
+// name however they're wrapped. A retrying dispatcher should be wrapped, rather than wrap
+// this one, so the handler middlewares run for each attempt
+type MiddlewareEventDispatcher struct {
+	dispatcher  EventDispatcher
+	middlewares []EventMiddleware
+	dispatch    DispatchFunc
+}
+
+// NewMiddlewareEventDispatcher wraps the dispatcher with the middlewares
+func NewMiddlewareEventDispatcher(
+	dispatcher EventDispatcher,
+	middlewares ...EventMiddleware,
+) *MiddlewareEventDispatcher {
+	d := &MiddlewareEventDispatcher{
+		dispatcher:  dispatcher,
+		middlewares: middlewares,
+	}
+
+	d.dispatch = d.dispatchTo
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i].Dispatch != nil {
+			d.dispatch = middlewares[i].Dispatch(d.dispatch)
+		}
+	}
+	return d
+}
+
+// Subscribe registers a handler for a specific event type
+func (d *MiddlewareEventDispatcher) Subscribe(eventType, name string, handler EventHandlerFunc) {
+	info := HandlerInfo{EventType: eventType, Name: name}
+	d.subscribe(info, nil, handler.WithContext(), nil)
+}
+
+// SubscribeContext registers a handler for a specific event type that takes the context
+func (d *MiddlewareEventDispatcher) SubscribeContext(
+	eventType, name string,
+	handler ContextEventHandlerFunc,
+) {
+	d.SubscribeWith(eventType, name, handler)
+}
+
+// SubscribeWith registers a handler for a specific event type, wrapped in its own
+// middlewares as well as the dispatcher's
+func (d *MiddlewareEventDispatcher) SubscribeWith(
+	eventType, name string,
+	handler ContextEventHandlerFunc,
+	middlewares ...HandlerMiddleware,
+) {
+	info := HandlerInfo{EventType: eventType, Name: name}
+	d.subscribe(info, nil, handler, middlewares)
+}
+
+// SubscribeWithPolicy registers a handler for a specific event type, retried by the
+// given policy if the wrapped dispatcher retries handlers
+func (d *MiddlewareEventDispatcher) SubscribeWithPolicy(
+	eventType, name string,
+	policy RetryPolicy,
+	handler ContextEventHandlerFunc,
+) {
+	info := HandlerInfo{EventType: eventType, Name: name}
+	d.subscribe(info, &policy, handler, nil)
+}
+
+func (d *MiddlewareEventDispatcher) subscribe(
+	info HandlerInfo,
+	policy *RetryPolicy,
+	handler ContextEventHandlerFunc,
+	middlewares []HandlerMiddleware,
+) {
+	handler = ChainHandler(info, handler, middlewares...)
+	for i := len(d.middlewares) - 1; i >= 0; i-- {
+		if d.middlewares[i].Handle != nil {
+			handler = d.middlewares[i].Handle(info, handler)
+		}
+	}
+
+	if policy != nil {
+		SubscribeWithPolicy(d.dispatcher, info.EventType, info.Name, *policy, handler)
+		return
+	}
+	d.dispatcher.SubscribeContext(info.EventType, info.Name, handler)
+}
+
+// Dispatch runs the event through the middlewares to the wrapped dispatcher
+func (d *MiddlewareEventDispatcher) Dispatch(ctx context.Context, event DomainEvent) error {
+	return d.dispatch(ctx, "", event)
+}
+
+// DispatchFor runs the event through the middlewares to the wrapped dispatcher, in order
+// with the aggregate's other events if it keeps them in order
+func (d *MiddlewareEventDispatcher) DispatchFor(
+	ctx context.Context,
+	aggregateID string,
+	event DomainEvent,
+) error {
+	return d.dispatch(ctx, aggregateID, event)
+}
+
+func (d *MiddlewareEventDispatcher) dispatchTo(
+	ctx context.Context,
+	aggregateID string,
+	event DomainEvent,
+) error {
+	if dispatcher, ok := d.dispatcher.(AggregateEventDispatcher); ok && aggregateID != "" {
+		return dispatcher.DispatchFor(ctx, aggregateID, event)
+	}
+	return d.dispatcher.Dispatch(ctx, event)
+}
+
+// SubscribeWith subscribes the handler wrapped in the middlewares, to run inside the
+// dispatcher's own if it has any
+func SubscribeWith(
+	dispatcher EventDispatcher,
+	eventType, name string,
+	handler ContextEventHandlerFunc,
+	middlewares ...HandlerMiddleware,
+) {
+	info := HandlerInfo{EventType: eventType, Name: name}
+	dispatcher.SubscribeContext(eventType, name, ChainHandler(info, handler, middlewares...))
+}
+
+// ChainHandler wraps the handler in the middlewares, the first being the outermost
+func ChainHandler(
+	info HandlerInfo,
+	handler ContextEventHandlerFunc,
+	middlewares ...HandlerMiddleware,
+) ContextEventHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](info, handler)
+	}
+	return handler
+}
+
+// HandlerName names the handler after its function, e.g.
+// events.CommentEventHandler.HandleCommentCreated for a method value. Closures get
+// names like func1, numbered in the order they're declared, so the name is for
+// describing a handler rather than subscribing it
+func HandlerName(handler any) string {
+	name := "handler"
+	if fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()); fn != nil {
+		name = fn.Name()
+		name = name[strings.LastIndex(name, "/")+1:]
+		name = strings.TrimSuffix(name, "-fm")
+	}
+	return name
+}
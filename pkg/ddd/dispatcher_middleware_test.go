@@ -0,0 +1,222 @@
+package ddd_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+
+	"go.uber.org/zap"
+)
+
+// middlewareTestEvent is a registered event the middleware tests dispatch
+type middlewareTestEvent struct{}
+
+func (e middlewareTestEvent) OccurredOn() time.Time { return time.Time{} }
+func (e middlewareTestEvent) EventType() string     { return "MiddlewareTest" }
+
+// unregisteredTestEvent is never registered with the EventRegistry
+type unregisteredTestEvent struct{}
+
+func (e unregisteredTestEvent) OccurredOn() time.Time { return time.Time{} }
+func (e unregisteredTestEvent) EventType() string     { return "UnregisteredTest" }
+
+func init() {
+	ddd.EventRegistry.Register(middlewareTestEvent{}, "An event the middleware tests dispatch")
+}
+
+// middlewareTest records what the middlewares and the handler did, in order
+type middlewareTest struct {
+	calls []string
+}
+
+func (m *middlewareTest) record(call string) {
+	m.calls = append(m.calls, call)
+}
+
+// dispatching records the dispatch of each event as it goes in and comes back out
+func (m *middlewareTest) dispatching(name string) ddd.EventMiddleware {
+	return ddd.EventMiddleware{
+		Dispatch: func(next ddd.DispatchFunc) ddd.DispatchFunc {
+			return func(ctx context.Context, aggregateID string, event ddd.DomainEvent) error {
+				m.record(name + " dispatch")
+				defer m.record(name + " dispatched")
+				return next(ctx, aggregateID, event)
+			}
+		},
+	}
+}
+
+// handling records each run of a handler, with the name it was subscribed under
+func (m *middlewareTest) handling(name string) ddd.HandlerMiddleware {
+	return func(info ddd.HandlerInfo, next ddd.ContextEventHandlerFunc) ddd.ContextEventHandlerFunc {
+		return func(ctx context.Context, event ddd.DomainEvent) error {
+			m.record(name + " handle " + info.Name)
+			return next(ctx, event)
+		}
+	}
+}
+
+func (m *middlewareTest) both(name string) ddd.EventMiddleware {
+	return ddd.EventMiddleware{
+		Dispatch: m.dispatching(name).Dispatch,
+		Handle:   m.handling(name),
+	}
+}
+
+func (m *middlewareTest) handler(ctx context.Context, event ddd.DomainEvent) error {
+	m.record("handler")
+	return nil
+}
+
+func newMiddlewareDispatcher(middlewares ...ddd.EventMiddleware) *ddd.MiddlewareEventDispatcher {
+	return ddd.NewMiddlewareEventDispatcher(
+		dddmemory.NewInMemoryEventDispatcher(zap.NewNop().Sugar()),
+		middlewares...,
+	)
+}
+
+func TestMiddlewareEventDispatcherRunsTheMiddlewaresInOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		// middlewares returns the dispatcher's middlewares and the handler's own
+		middlewares func(m *middlewareTest) ([]ddd.EventMiddleware, []ddd.HandlerMiddleware)
+		want        []string
+	}{
+		{
+			name: "none",
+			middlewares: func(m *middlewareTest) ([]ddd.EventMiddleware, []ddd.HandlerMiddleware) {
+				return nil, nil
+			},
+			want: []string{"handler"},
+		},
+		{
+			name: "dispatch middlewares, the first outermost",
+			middlewares: func(m *middlewareTest) ([]ddd.EventMiddleware, []ddd.HandlerMiddleware) {
+				return []ddd.EventMiddleware{m.dispatching("a"), m.dispatching("b")}, nil
+			},
+			want: []string{"a dispatch", "b dispatch", "handler", "b dispatched", "a dispatched"},
+		},
+		{
+			name: "handler middlewares, the first outermost",
+			middlewares: func(m *middlewareTest) ([]ddd.EventMiddleware, []ddd.HandlerMiddleware) {
+				return []ddd.EventMiddleware{{Handle: m.handling("a")}, {Handle: m.handling("b")}}, nil
+			},
+			want: []string{"a handle records", "b handle records", "handler"},
+		},
+		{
+			name: "handlers run within the dispatch",
+			middlewares: func(m *middlewareTest) ([]ddd.EventMiddleware, []ddd.HandlerMiddleware) {
+				return []ddd.EventMiddleware{m.both("a"), {}, m.both("b")}, nil
+			},
+			want: []string{
+				"a dispatch", "b dispatch",
+				"a handle records", "b handle records", "handler",
+				"b dispatched", "a dispatched",
+			},
+		},
+		{
+			name: "the handler's own middlewares inside the dispatcher's",
+			middlewares: func(m *middlewareTest) ([]ddd.EventMiddleware, []ddd.HandlerMiddleware) {
+				return []ddd.EventMiddleware{{Handle: m.handling("a")}},
+					[]ddd.HandlerMiddleware{m.handling("own"), m.handling("own too")}
+			},
+			want: []string{"a handle records", "own handle records", "own too handle records", "handler"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := &middlewareTest{}
+			middlewares, own := tt.middlewares(test)
+			dispatcher := newMiddlewareDispatcher(middlewares...)
+			dispatcher.SubscribeWith("MiddlewareTest", "records", test.handler, own...)
+
+			if err := dispatcher.Dispatch(context.Background(), middlewareTestEvent{}); err != nil {
+				t.Fatalf("Dispatch() failed: %v", err)
+			}
+			if !slices.Equal(test.calls, tt.want) {
+				t.Errorf("calls = %q, want %q", test.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoveryMiddlewareTurnsAPanicIntoAnError(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler ddd.ContextEventHandlerFunc
+		wantErr string
+	}{
+		{
+			name:    "succeeds",
+			handler: func(ctx context.Context, event ddd.DomainEvent) error { return nil },
+		},
+		{
+			name: "fails",
+			handler: func(ctx context.Context, event ddd.DomainEvent) error {
+				return errors.New("unavailable")
+			},
+			wantErr: "unavailable",
+		},
+		{
+			name: "panics",
+			handler: func(ctx context.Context, event ddd.DomainEvent) error {
+				panic("broke")
+			},
+			wantErr: "handler breaks panicked: broke",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := &middlewareTest{}
+			dispatcher := newMiddlewareDispatcher(ddd.RecoveryMiddleware())
+			dispatcher.SubscribeContext("MiddlewareTest", "breaks", tt.handler)
+			dispatcher.SubscribeContext("MiddlewareTest", "records", test.handler)
+
+			err := dispatcher.Dispatch(context.Background(), middlewareTestEvent{})
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Dispatch() failed: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Dispatch() error = %v, want %q", err, tt.wantErr)
+			}
+			if !slices.Equal(test.calls, []string{"handler"}) {
+				t.Errorf("calls = %q, want the other handler run", test.calls)
+			}
+		})
+	}
+}
+
+func TestValidationMiddlewareRejectsUnregisteredEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   ddd.DomainEvent
+		handled bool
+	}{
+		{"registered", middlewareTestEvent{}, true},
+		{"unregistered", unregisteredTestEvent{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := &middlewareTest{}
+			dispatcher := newMiddlewareDispatcher(ddd.ValidationMiddleware())
+			dispatcher.SubscribeContext(tt.event.EventType(), "records", test.handler)
+
+			err := dispatcher.Dispatch(context.Background(), tt.event)
+			if tt.handled && err != nil {
+				t.Errorf("Dispatch() failed: %v", err)
+			}
+			if !tt.handled && (err == nil || !strings.Contains(err.Error(), "unregistered event type")) {
+				t.Errorf("Dispatch() error = %v, want the event refused as unregistered", err)
+			}
+			if handled := len(test.calls) > 0; handled != tt.handled {
+				t.Errorf("handled = %v, want %v", handled, tt.handled)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package ddd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// EventEnvelope is a domain event encoded for storage or transport. The payload is the
+// event's exported fields as JSON, in the shape of the given version
+type EventEnvelope struct {
+	Type        string          `json:"type"`
+	Version     int             `json:"version"`
+	OccurredOn  time.Time       `json:"occurred_on"`
+	AggregateID string          `json:"aggregate_id"`
+	Metadata    EventMetadata   `json:"metadata"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// Upcaster migrates an event's payload from one version of its shape to the next, e.g.
+// by renaming or filling in a field. Numbers in the payload are json.Number
+type Upcaster func(payload map[string]any) (map[string]any, error)
+
+// RegisterUpcaster adds the upcaster from fromVersion of the event's shape to the next,
+// which becomes the event's current version. Upcasters must be registered in order,
+// starting from version 1, after the event itself
+// Panics if the event isn't registered or the upcaster is out of order
+func (r *eventRegistry) RegisterUpcaster(eventType string, fromVersion int, upcaster Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metadata, exists := r.events[eventType]
+	if !exists {
+		panic(fmt.Sprintf("event type %s is not registered", eventType))
+	}
+	if fromVersion != metadata.Version {
+		panic(fmt.Sprintf(
+			"event type %s is at version %d, can't upcast from version %d",
+			eventType,
+			metadata.Version,
+			fromVersion,
+		))
+	}
+
+	r.upcasters[eventType] = append(r.upcasters[eventType], upcaster)
+	metadata.Version++
+	r.events[eventType] = metadata
+}
+
+// Encode wraps the event in an envelope at its current version
+func (r *eventRegistry) Encode(aggregateID string, event DomainEvent) (EventEnvelope, error) {
+	metadata, exists := r.GetMetadata(event.EventType())
+	if !exists {
+		return EventEnvelope{}, fmt.Errorf("unregistered event type: %s", event.EventType())
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return EventEnvelope{}, fmt.Errorf("encoding %s: %w", event.EventType(), err)
+	}
+
+	return EventEnvelope{
+		Type:        event.EventType(),
+		Version:     metadata.Version,
+		OccurredOn:  event.OccurredOn(),
+		AggregateID: aggregateID,
+		Payload:     payload,
+	}, nil
+}
+
+// EncodeEvents wraps each of the events raised by the aggregate in an envelope, in
+// order, with metadata for an event caused by the given cause
+func (r *eventRegistry) EncodeEvents(
+	aggregateID string,
+	cause EventMetadata,
+	events []DomainEvent,
+) ([]EventEnvelope, error) {
+	envelopes := make([]EventEnvelope, 0, len(events))
+	for _, event := range events {
+		envelope, err := r.Encode(aggregateID, event)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Metadata = cause.Caused()
+		envelopes = append(envelopes, envelope)
+	}
+	return envelopes, nil
+}
+
+// Decode rebuilds an event of the registered type from its envelope, upcasting older
+// versions to the current shape. The event is returned as a pointer, as domain events
+// are raised, with the time it occurred restored
+func (r *eventRegistry) Decode(envelope EventEnvelope) (DomainEvent, error) {
+	metadata, exists := r.GetMetadata(envelope.Type)
+	if !exists {
+		return nil, fmt.Errorf("unregistered event type: %s", envelope.Type)
+	}
+
+	payload, err := r.upcast(envelope, metadata.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	exampleType := reflect.TypeOf(metadata.Example)
+	if exampleType.Kind() == reflect.Ptr {
+		exampleType = exampleType.Elem()
+	}
+
+	event := reflect.New(exampleType)
+	if err := json.Unmarshal(payload, event.Interface()); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", envelope.Type, err)
+	}
+	if restorer, ok := event.Interface().(OccurredOnRestorer); ok {
+		restorer.RestoreOccurredOn(envelope.OccurredOn)
+	}
+
+	decoded, ok := event.Interface().(DomainEvent)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a domain event", exampleType)
+	}
+	return decoded, nil
+}
+
+// upcast runs the envelope's payload through the upcasters from its version to the
+// current one
+func (r *eventRegistry) upcast(envelope EventEnvelope, currentVersion int) (json.RawMessage, error) {
+	switch {
+	case envelope.Version == currentVersion:
+		return envelope.Payload, nil
+	case envelope.Version < 1 || envelope.Version > currentVersion:
+		return nil, fmt.Errorf(
+			"%s version %d is unknown, the current version is %d",
+			envelope.Type,
+			envelope.Version,
+			currentVersion,
+		)
+	}
+
+	r.mu.RLock()
+	upcasters := r.upcasters[envelope.Type][envelope.Version-1 : currentVersion-1]
+	r.mu.RUnlock()
+
+	decoder := json.NewDecoder(bytes.NewReader(envelope.Payload))
+	decoder.UseNumber()
+	var payload map[string]any
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decoding %s version %d: %w", envelope.Type, envelope.Version, err)
+	}
+
+	for i, upcaster := range upcasters {
+		upcasted, err := upcaster(payload)
+		if err != nil {
+			return nil, fmt.Errorf("upcasting %s from version %d: %w", envelope.Type, envelope.Version+i, err)
+		}
+		payload = upcasted
+	}
+
+	return json.Marshal(payload)
+}
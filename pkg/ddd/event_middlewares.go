@@ -0,0 +1,162 @@
+package ddd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ValidationMiddleware refuses to dispatch events that aren't registered with the
+// EventRegistry, as they couldn't be stored or replayed
+func ValidationMiddleware() EventMiddleware {
+	return EventMiddleware{
+		Dispatch: func(next DispatchFunc) DispatchFunc {
+			return func(ctx context.Context, aggregateID string, event DomainEvent) error {
+				if err := EventRegistry.ValidateEvent(event); err != nil {
+					return fmt.Errorf("invalid event: %w", err)
+				}
+				return next(ctx, aggregateID, event)
+			}
+		},
+	}
+}
+
+// RecoveryMiddleware turns a handler's panic into an error, so it fails like any other
+// handler rather than taking the dispatcher down with it
+func RecoveryMiddleware() EventMiddleware {
+	return EventMiddleware{
+		Handle: func(info HandlerInfo, next ContextEventHandlerFunc) ContextEventHandlerFunc {
+			return func(ctx context.Context, event DomainEvent) (err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("handler %s panicked: %v", info.Name, r)
+					}
+				}()
+				return next(ctx, event)
+			}
+		},
+	}
+}
+
+// LoggingMiddleware logs each event dispatched and each time a handler runs, with the
+// event's metadata. Failed handlers are logged as warnings, and the rest at debug level
+func LoggingMiddleware(logger *zap.SugaredLogger) EventMiddleware {
+	return EventMiddleware{
+		Dispatch: func(next DispatchFunc) DispatchFunc {
+			return func(ctx context.Context, aggregateID string, event DomainEvent) error {
+				err := next(ctx, aggregateID, event)
+				fields := append(
+					metadataFields(ctx),
+					"event_type", event.EventType(),
+					"aggregate_id", aggregateID,
+				)
+				if err != nil {
+					logger.Warnw("Failed to dispatch event", append(fields, "error", err)...)
+				} else {
+					logger.Debugw("Dispatched event", fields...)
+				}
+				return err
+			}
+		},
+		Handle: func(info HandlerInfo, next ContextEventHandlerFunc) ContextEventHandlerFunc {
+			return func(ctx context.Context, event DomainEvent) error {
+				start := time.Now()
+				err := next(ctx, event)
+				fields := append(
+					metadataFields(ctx),
+					"event_type", info.EventType,
+					"handler", info.Name,
+					"duration", time.Since(start),
+				)
+				if err != nil {
+					logger.Warnw("Event handler failed", append(fields, "error", err)...)
+				} else {
+					logger.Debugw("Event handled", fields...)
+				}
+				return err
+			}
+		},
+	}
+}
+
+func metadataFields(ctx context.Context) []any {
+	metadata := EventMetadataFrom(ctx)
+	return []any{
+		"event_id", metadata.EventID,
+		"correlation_id", metadata.CorrelationID,
+		"causation_id", metadata.CausationID,
+		"actor_id", metadata.ActorID,
+	}
+}
+
+// EventMetrics records how long dispatching events and running their handlers takes,
+// and how often they fail
+type EventMetrics interface {
+	ObserveDispatch(eventType string, duration time.Duration, err error)
+	ObserveHandler(info HandlerInfo, duration time.Duration, err error)
+}
+
+// EventMetric counts the times an event type was dispatched, or one of its handlers
+// ran, and how long it took
+type EventMetric struct {
+	EventType string
+	// Handler is empty for the dispatch of the event itself
+	Handler       string
+	Count         int
+	Failures      int
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+}
+
+// MetricsMiddleware times each dispatch and each handler run in the metrics
+func MetricsMiddleware(metrics EventMetrics) EventMiddleware {
+	return EventMiddleware{
+		Dispatch: func(next DispatchFunc) DispatchFunc {
+			return func(ctx context.Context, aggregateID string, event DomainEvent) error {
+				start := time.Now()
+				err := next(ctx, aggregateID, event)
+				metrics.ObserveDispatch(event.EventType(), time.Since(start), err)
+				return err
+			}
+		},
+		Handle: func(info HandlerInfo, next ContextEventHandlerFunc) ContextEventHandlerFunc {
+			return func(ctx context.Context, event DomainEvent) error {
+				start := time.Now()
+				err := next(ctx, event)
+				metrics.ObserveHandler(info, time.Since(start), err)
+				return err
+			}
+		},
+	}
+}
+
+// TracingMiddleware starts a span for each dispatch, and a span within it each time a
+// handler runs, even when the handler runs after the dispatch has returned
+func TracingMiddleware(tracer Tracer) EventMiddleware {
+	return EventMiddleware{
+		Dispatch: func(next DispatchFunc) DispatchFunc {
+			return func(ctx context.Context, aggregateID string, event DomainEvent) error {
+				ctx, span := tracer.Start(ctx, "dispatch "+event.EventType(), map[string]string{
+					"event_type":   event.EventType(),
+					"aggregate_id": aggregateID,
+				})
+				err := next(ctx, aggregateID, event)
+				span.End(err)
+				return err
+			}
+		},
+		Handle: func(info HandlerInfo, next ContextEventHandlerFunc) ContextEventHandlerFunc {
+			return func(ctx context.Context, event DomainEvent) error {
+				ctx, span := tracer.Start(ctx, "handle "+info.EventType, map[string]string{
+					"event_type": info.EventType,
+					"handler":    info.Name,
+				})
+				err := next(ctx, event)
+				span.End(err)
+				return err
+			}
+		},
+	}
+}
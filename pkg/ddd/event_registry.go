@@ -11,17 +11,26 @@ type EventRegistryMetadata struct {
 	Type        string
 	Description string
 	Example     interface{}
+	Version     int // Current version of the event's shape, raised by each upcaster
 }
 
 // eventRegistry maintains a thread-safe registry of all domain events
 type eventRegistry struct {
-	events map[string]EventRegistryMetadata
-	mu     sync.RWMutex
+	events    map[string]EventRegistryMetadata
+	upcasters map[string][]Upcaster // Indexed by the version each one upcasts from, less one
+	mu        sync.RWMutex
 }
 
 // Global event registry instance
-var EventRegistry = &eventRegistry{
-	events: make(map[string]EventRegistryMetadata),
+var EventRegistry = NewEventRegistry()
+
+// NewEventRegistry creates an empty registry. Most code should use the global
+// EventRegistry, which the domain registers its events with
+func NewEventRegistry() *eventRegistry {
+	return &eventRegistry{
+		events:    make(map[string]EventRegistryMetadata),
+		upcasters: make(map[string][]Upcaster),
+	}
 }
 
 // GetEventRegistry returns the global event registry instance
@@ -44,6 +53,7 @@ func (r *eventRegistry) Register(event DomainEvent, description string) {
 		Type:        eventType,
 		Description: description,
 		Example:     event,
+		Version:     1,
 	}
 }
 
@@ -95,29 +105,10 @@ func (r *eventRegistry) MustValidateEvent(event DomainEvent) {
 	}
 }
 
-// ValidatingEventDispatcher wraps an EventDispatcher to validate events before dispatch
-type ValidatingEventDispatcher struct {
-	dispatcher EventDispatcher
-}
-
-// NewValidatingEventDispatcher creates a new validating event dispatcher
-func NewValidatingEventDispatcher(dispatcher EventDispatcher) *ValidatingEventDispatcher {
-	return &ValidatingEventDispatcher{
-		dispatcher: dispatcher,
-	}
-}
-
-// Dispatch validates the event before dispatching it
-func (v *ValidatingEventDispatcher) Dispatch(event DomainEvent) {
-	if err := EventRegistry.ValidateEvent(event); err != nil {
-		panic(fmt.Errorf("invalid event: %w", err))
-	}
-	v.dispatcher.Dispatch(event)
-}
-
-// Subscribe delegates to the wrapped dispatcher
-func (v *ValidatingEventDispatcher) Subscribe(eventType string, handler EventHandlerFunc) {
-	v.dispatcher.Subscribe(eventType, handler)
+// NewValidatingEventDispatcher wraps the dispatcher to refuse events that aren't
+// registered, see ValidationMiddleware
+func NewValidatingEventDispatcher(dispatcher EventDispatcher) *MiddlewareEventDispatcher {
+	return NewMiddlewareEventDispatcher(dispatcher, ValidationMiddleware())
 }
 
 // EventRegistrar defines an interface for components that can register their events
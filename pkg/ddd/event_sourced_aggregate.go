@@ -0,0 +1,66 @@
+package ddd
+
+import "sync"
+
+// ApplyFunc changes an aggregate's state to reflect an event. It returns an error for
+// events the aggregate doesn't know how to apply
+type ApplyFunc func(event DomainEvent) error
+
+// EventSourcedAggregate is the base for aggregates whose state is rebuilt from their
+// events rather than stored. Every change is made by raising an event, which is applied
+// to the aggregate and recorded to be appended to its stream
+//
+// The aggregate passes its own apply method to Raise and Replay, rather than storing it,
+// so copies of the aggregate apply events to themselves
+type EventSourcedAggregate struct {
+	*AggregateBase
+	version int // Version of the stream the aggregate was loaded from
+	mu      sync.Mutex
+}
+
+// NewEventSourcedAggregate creates the base for a new aggregate, with an empty stream
+func NewEventSourcedAggregate() *EventSourcedAggregate {
+	return &EventSourcedAggregate{
+		AggregateBase: &AggregateBase{},
+	}
+}
+
+// Raise applies the event to the aggregate and records it as uncommitted
+func (a *EventSourcedAggregate) Raise(apply ApplyFunc, event DomainEvent) error {
+	if err := apply(event); err != nil {
+		return err
+	}
+	a.RecordEvent(event)
+	return nil
+}
+
+// Replay rebuilds the aggregate's state from the events already in its stream
+func (a *EventSourcedAggregate) Replay(apply ApplyFunc, history []DomainEvent) error {
+	for _, event := range history {
+		if err := apply(event); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.version += len(history)
+	return nil
+}
+
+// Version returns the version of the stream the aggregate was loaded from, not counting
+// uncommitted events. It's the version expected when those events are appended
+func (a *EventSourcedAggregate) Version() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.version
+}
+
+// MarkEventsAsCommitted clears the uncommitted events once they have been appended to
+// the stream, moving the aggregate on to the stream's new version
+func (a *EventSourcedAggregate) MarkEventsAsCommitted() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.version += len(a.GetUncommittedEvents())
+	a.AggregateBase.MarkEventsAsCommitted()
+}
@@ -0,0 +1,32 @@
+package ddd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrConcurrencyConflict is returned when events are appended to a stream that has
+// moved on since the aggregate was loaded
+var ErrConcurrencyConflict = errors.New("concurrency conflict")
+
+// EventStore keeps each aggregate's events as an append-only stream. A stream's version
+// is the number of events in it
+type EventStore interface {
+	// Append adds the events to the end of the aggregate's stream. It fails with
+	// ErrConcurrencyConflict unless the stream is still at expectedVersion
+	Append(aggregateID string, expectedVersion int, events []DomainEvent) error
+	// Load returns the aggregate's events in the order they were appended. A stream that
+	// doesn't exist has no events
+	Load(aggregateID string) ([]DomainEvent, error)
+}
+
+// NewConcurrencyConflictError describes a stream that isn't at the version expected
+func NewConcurrencyConflictError(aggregateID string, expectedVersion, actualVersion int) error {
+	return fmt.Errorf(
+		"%w: stream %s is at version %d, expected %d",
+		ErrConcurrencyConflict,
+		aggregateID,
+		actualVersion,
+		expectedVersion,
+	)
+}
@@ -1,6 +1,9 @@
 package ddd
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // DomainEvent represents something important that happened in the domain
 // All domain events must implement this interface
@@ -9,19 +12,70 @@ type DomainEvent interface {
 	EventType() string
 }
 
+// EventBase keeps the time an event occurred. Events embed it to implement OccurredOn,
+// keeping the time unexported so nothing else can change it
+type EventBase struct {
+	occurredOn time.Time
+}
+
+// NewEventBase creates an event base for an event that occurred at occurredOn
+func NewEventBase(occurredOn time.Time) EventBase {
+	return EventBase{occurredOn: occurredOn}
+}
+
+// OccurredOn returns when the event occurred
+func (b EventBase) OccurredOn() time.Time { return b.occurredOn }
+
+// RestoreOccurredOn sets when a decoded event occurred, which JSON skips because it's
+// unexported
+func (b *EventBase) RestoreOccurredOn(occurredOn time.Time) { b.occurredOn = occurredOn }
+
+// OccurredOnRestorer is implemented by events whose time the codec restores on decoding,
+// which events get by embedding EventBase
+type OccurredOnRestorer interface {
+	RestoreOccurredOn(occurredOn time.Time)
+}
+
 // EventDispatcher handles the dispatching of domain events to registered handlers
+// The context is passed on to the handlers, carrying the event's metadata
+//
+// Each handler is subscribed under a name of its own for the event type, which should
+// stay the same from one run to the next, as dispatchers that keep track of handlers,
+// such as RetryingEventDispatcher, record it
 type EventDispatcher interface {
-	Dispatch(event DomainEvent) error
-	Subscribe(eventType string, handler EventHandlerFunc)
+	Dispatch(ctx context.Context, event DomainEvent) error
+	Subscribe(eventType, name string, handler EventHandlerFunc)
+	SubscribeContext(eventType, name string, handler ContextEventHandlerFunc)
+}
+
+// AggregateEventDispatcher is an EventDispatcher that delivers each aggregate's events
+// in the order they were dispatched, so it needs to know which aggregate raised each one
+type AggregateEventDispatcher interface {
+	EventDispatcher
+	DispatchFor(ctx context.Context, aggregateID string, event DomainEvent) error
 }
 
 // EventHandler processes specific types of domain events
+// Handlers that don't need the context are subscribed with Subscribe, which adapts them
 type EventHandlerFunc func(event DomainEvent) error
 
+// ContextEventHandlerFunc processes specific types of domain events with the context
+// they were dispatched with, which carries the event's metadata along with the
+// cancellation and deadline of whatever dispatched it
+type ContextEventHandlerFunc func(ctx context.Context, event DomainEvent) error
+
+// WithContext adapts the handler to take a context, which it ignores
+func (h EventHandlerFunc) WithContext() ContextEventHandlerFunc {
+	return func(ctx context.Context, event DomainEvent) error {
+		return h(event)
+	}
+}
+
 // EventAggregate represents an aggregate that maintains a list of uncommitted events
 // Aggregates should embed AggregateBase to implement this interface
 type EventAggregate interface {
 	GetUncommittedEvents() []DomainEvent // Returns uncommitted events
 	MarkEventsAsCommitted()              // Clears event list after dispatch
 	RecordEvent(event DomainEvent)       // Records a new event
+	EventMetadata() EventMetadata        // Returns what caused the uncommitted events
 }
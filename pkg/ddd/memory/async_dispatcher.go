@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"blog/pkg/ddd"
+
+	"go.uber.org/zap"
+)
+
+// ErrDispatcherShutDown is returned when an event is dispatched after Shutdown
+var ErrDispatcherShutDown = errors.New("event dispatcher is shut down")
+
+// AsyncEventDispatcherConfig controls the queues and workers of an AsyncEventDispatcher
+type AsyncEventDispatcherConfig struct {
+	// Workers is how many workers handle the events
+	Workers int
+	// QueueSize is how many events each worker holds before Dispatch blocks
+	QueueSize int
+}
+
+// DefaultAsyncEventDispatcherConfig returns the settings used when none are configured
+func DefaultAsyncEventDispatcherConfig() AsyncEventDispatcherConfig {
+	return AsyncEventDispatcherConfig{
+		Workers:   4,
+		QueueSize: 100,
+	}
+}
+
+type queuedEvent struct {
+	ctx         context.Context
+	aggregateID string
+	event       ddd.DomainEvent
+}
+
+// AsyncEventDispatcher is an in-memory implementation of EventDispatcher that runs
+// handlers in the background, on a fixed set of workers each with a bounded queue.
+// Dispatch returns once the event is queued, and blocks while the queue is full until
+// there's room, its context ends or the dispatcher shuts down
+//
+// Events of every type are spread over the workers by aggregate ID, so all of an
+// aggregate's events are handled in the order they were dispatched, whatever their
+// types. In exchange a slow handler holds up the other aggregates sharing its worker.
+// Events dispatched without an aggregate ID all go to the same worker. A handler that
+// fails or panics is logged without affecting the event's other handlers
+//
+// Handlers get the context the event was dispatched with, carrying its metadata, but
+// not its cancellation, as they run after Dispatch has returned
+type AsyncEventDispatcher struct {
+	handlers   map[string][]ddd.ContextEventHandlerFunc
+	handlersMu sync.RWMutex
+
+	// Shutting down is guarded separately from the handlers, which the workers read.
+	// Sends happen outside the lock, so a Dispatch blocked on a full queue holds up
+	// neither the workers nor Shutdown, which closes the queues once the sends in flight
+	// are done
+	queues     []chan queuedEvent
+	shutDown   bool
+	shutDownMu sync.RWMutex
+	sending  sync.WaitGroup
+	done     chan struct{}
+	drained  chan struct{}
+
+	config  AsyncEventDispatcherConfig
+	logger  *zap.SugaredLogger
+	workers sync.WaitGroup
+}
+
+// NewAsyncEventDispatcher creates a dispatcher and starts its workers
+func NewAsyncEventDispatcher(log *zap.SugaredLogger, config AsyncEventDispatcherConfig) *AsyncEventDispatcher {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 1
+	}
+
+	d := &AsyncEventDispatcher{
+		handlers: make(map[string][]ddd.ContextEventHandlerFunc),
+		queues:   make([]chan queuedEvent, config.Workers),
+		done:     make(chan struct{}),
+		drained:  make(chan struct{}),
+		config:   config,
+		logger:   log,
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan queuedEvent, config.QueueSize)
+		d.workers.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+// Subscribe registers a handler for a specific event type. The dispatcher doesn't keep track of handlers by name
+func (d *AsyncEventDispatcher) Subscribe(eventType, name string, handler ddd.EventHandlerFunc) {
+	d.SubscribeContext(eventType, name, handler.WithContext())
+}
+
+// SubscribeContext registers a handler for a specific event type that takes the context
+func (d *AsyncEventDispatcher) SubscribeContext(
+	eventType, name string,
+	handler ddd.ContextEventHandlerFunc,
+) {
+	d.handlersMu.Lock()
+	defer d.handlersMu.Unlock()
+
+	d.handlers[eventType] = append(d.handlers[eventType], handler)
+}
+
+// Dispatch queues the event for its handlers, with no aggregate to order it by
+func (d *AsyncEventDispatcher) Dispatch(ctx context.Context, event ddd.DomainEvent) error {
+	return d.DispatchFor(ctx, "", event)
+}
+
+// DispatchFor queues the event for its handlers, after the events of any type already
+// queued for the same aggregate
+func (d *AsyncEventDispatcher) DispatchFor(
+	ctx context.Context,
+	aggregateID string,
+	event ddd.DomainEvent,
+) error {
+	if err := ddd.GetEventRegistry().ValidateEvent(event); err != nil {
+		d.logger.Warnw("Event not registered in global registry",
+			"event_type", event.EventType(),
+			"error", err,
+		)
+	}
+
+	d.handlersMu.RLock()
+	subscribed := len(d.handlers[event.EventType()]) > 0
+	d.handlersMu.RUnlock()
+
+	d.shutDownMu.RLock()
+	if d.shutDown {
+		d.shutDownMu.RUnlock()
+		return ErrDispatcherShutDown
+	}
+	if !subscribed {
+		d.shutDownMu.RUnlock()
+		return nil
+	}
+	d.sending.Add(1)
+	d.shutDownMu.RUnlock()
+	defer d.sending.Done()
+
+	queued := queuedEvent{
+		ctx:         context.WithoutCancel(ctx),
+		aggregateID: aggregateID,
+		event:       event,
+	}
+	select {
+	case d.queues[shard(aggregateID, len(d.queues))] <- queued:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-d.done:
+		return ErrDispatcherShutDown
+	}
+}
+
+// Shutdown stops accepting events and waits for the workers to handle the events
+// already queued. A Dispatch blocked on a full queue returns ErrDispatcherShutDown. It
+// returns the context's error if the context ends first, leaving the rest of the queued
+// events to be handled in the background
+func (d *AsyncEventDispatcher) Shutdown(ctx context.Context) error {
+	d.shutDownMu.Lock()
+	if !d.shutDown {
+		d.shutDown = true
+		close(d.done)
+		go d.drain()
+	}
+	d.shutDownMu.Unlock()
+
+	select {
+	case <-d.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain closes the queues once nothing is sending to them, which no Dispatch starts to
+// after shutdown, and waits for the workers to handle what's left in them
+func (d *AsyncEventDispatcher) drain() {
+	d.sending.Wait()
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.workers.Wait()
+	close(d.drained)
+}
+
+func (d *AsyncEventDispatcher) work(queue <-chan queuedEvent) {
+	defer d.workers.Done()
+
+	for queued := range queue {
+		eventType := queued.event.EventType()
+		d.handlersMu.RLock()
+		handlers := d.handlers[eventType]
+		d.handlersMu.RUnlock()
+
+		for _, handler := range handlers {
+			if err := d.handle(queued.ctx, handler, queued.event); err != nil {
+				d.logger.Errorw("Error handling event",
+					"event_type", eventType,
+					"aggregate_id", queued.aggregateID,
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// handle runs the handler, turning a panic into an error so the worker carries on
+func (d *AsyncEventDispatcher) handle(
+	ctx context.Context,
+	handler ddd.ContextEventHandlerFunc,
+	event ddd.DomainEvent,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// shard picks the worker for an aggregate, always the same one for the same aggregate
+func shard(aggregateID string, workers int) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(aggregateID))
+	return int(hash.Sum32() % uint32(workers))
+}
@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+
+	"go.uber.org/zap"
+)
+
+// asyncTestEvent is the Nth event an aggregate raises
+type asyncTestEvent struct {
+	Aggregate string
+	N         int
+}
+
+func (e asyncTestEvent) OccurredOn() time.Time { return time.Time{} }
+func (e asyncTestEvent) EventType() string     { return "AsyncTest" }
+
+// asyncOtherTestEvent is an aggregate's Nth event, of another type
+type asyncOtherTestEvent asyncTestEvent
+
+func (e asyncOtherTestEvent) OccurredOn() time.Time { return time.Time{} }
+func (e asyncOtherTestEvent) EventType() string     { return "AsyncOtherTest" }
+
+// asyncTest records the events a dispatcher's handler is given. If the test holds them,
+// the handler reports each event it starts and waits for them to be released
+type asyncTest struct {
+	dispatcher *AsyncEventDispatcher
+	started    chan asyncTestEvent
+	release    chan struct{}
+
+	mu      sync.Mutex
+	handled []asyncTestEvent
+}
+
+func newAsyncTest(t *testing.T, config AsyncEventDispatcherConfig, hold bool) *asyncTest {
+	t.Helper()
+
+	test := &asyncTest{
+		dispatcher: NewAsyncEventDispatcher(zap.NewNop().Sugar(), config),
+		started:    make(chan asyncTestEvent, 10),
+		release:    make(chan struct{}),
+	}
+	if !hold {
+		close(test.release)
+	}
+	test.dispatcher.Subscribe("AsyncTest", "records", func(event ddd.DomainEvent) error {
+		if hold {
+			test.started <- event.(asyncTestEvent)
+			<-test.release
+		}
+
+		test.mu.Lock()
+		defer test.mu.Unlock()
+		test.handled = append(test.handled, event.(asyncTestEvent))
+		return nil
+	})
+	test.dispatcher.Subscribe("AsyncOtherTest", "records", func(event ddd.DomainEvent) error {
+		test.mu.Lock()
+		defer test.mu.Unlock()
+		test.handled = append(test.handled, asyncTestEvent(event.(asyncOtherTestEvent)))
+		return nil
+	})
+	t.Cleanup(func() {
+		select {
+		case <-test.release:
+		default:
+			close(test.release)
+		}
+		test.dispatcher.Shutdown(context.Background())
+	})
+	return test
+}
+
+func (a *asyncTest) dispatch(t *testing.T, aggregate string, n int) {
+	t.Helper()
+
+	event := asyncTestEvent{Aggregate: aggregate, N: n}
+	if err := a.dispatcher.DispatchFor(context.Background(), aggregate, event); err != nil {
+		t.Fatalf("DispatchFor() failed: %v", err)
+	}
+}
+
+// dispatchInBackground dispatches the event, returning where DispatchFor's error will be
+// sent once it returns
+func (a *asyncTest) dispatchInBackground(ctx context.Context, aggregate string, n int) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- a.dispatcher.DispatchFor(ctx, aggregate, asyncTestEvent{Aggregate: aggregate, N: n})
+	}()
+	return result
+}
+
+func (a *asyncTest) shutdown(t *testing.T) {
+	t.Helper()
+
+	if err := a.dispatcher.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+}
+
+func (a *asyncTest) handledEvents() []asyncTestEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]asyncTestEvent{}, a.handled...)
+}
+
+// assertBlocked fails if DispatchFor has returned, after giving it time to
+func assertBlocked(t *testing.T, result <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-result:
+		t.Fatalf("DispatchFor() = %v, want it to block while the queue is full", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestAsyncEventDispatcherKeepsEachAggregatesOrder(t *testing.T) {
+	test := newAsyncTest(t, AsyncEventDispatcherConfig{Workers: 4, QueueSize: 2}, false)
+
+	aggregates := []string{"a", "b", "c", "d", "e"}
+	for n := 0; n < 50; n++ {
+		for _, aggregate := range aggregates {
+			test.dispatch(t, aggregate, n)
+		}
+	}
+	test.shutdown(t)
+
+	next := map[string]int{}
+	for _, event := range test.handledEvents() {
+		if event.N != next[event.Aggregate] {
+			t.Fatalf("%s's event %d was handled after event %d",
+				event.Aggregate, event.N, next[event.Aggregate]-1)
+		}
+		next[event.Aggregate]++
+	}
+	for _, aggregate := range aggregates {
+		if next[aggregate] != 50 {
+			t.Errorf("%d of %s's events were handled, want 50", next[aggregate], aggregate)
+		}
+	}
+}
+
+func TestAsyncEventDispatcherKeepsEachAggregatesOrderAcrossEventTypes(t *testing.T) {
+	test := newAsyncTest(t, AsyncEventDispatcherConfig{Workers: 4, QueueSize: 2}, false)
+
+	aggregates := []string{"a", "b", "c", "d", "e"}
+	for n := 0; n < 50; n++ {
+		for _, aggregate := range aggregates {
+			var event ddd.DomainEvent = asyncTestEvent{Aggregate: aggregate, N: n}
+			if n%2 == 1 {
+				event = asyncOtherTestEvent{Aggregate: aggregate, N: n}
+			}
+			if err := test.dispatcher.DispatchFor(context.Background(), aggregate, event); err != nil {
+				t.Fatalf("DispatchFor() failed: %v", err)
+			}
+		}
+	}
+	test.shutdown(t)
+
+	next := map[string]int{}
+	for _, event := range test.handledEvents() {
+		if event.N != next[event.Aggregate] {
+			t.Fatalf("%s's event %d was handled after event %d",
+				event.Aggregate, event.N, next[event.Aggregate]-1)
+		}
+		next[event.Aggregate]++
+	}
+	for _, aggregate := range aggregates {
+		if next[aggregate] != 50 {
+			t.Errorf("%d of %s's events were handled, want 50", next[aggregate], aggregate)
+		}
+	}
+}
+
+func TestAsyncEventDispatcherDrainsQueuedEventsOnShutdown(t *testing.T) {
+	test := newAsyncTest(t, AsyncEventDispatcherConfig{Workers: 1, QueueSize: 10}, true)
+
+	for n := 0; n < 5; n++ {
+		test.dispatch(t, "a", n)
+	}
+	<-test.started
+
+	// Shutdown gives up waiting while the first event is held, but stops accepting events
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := test.dispatcher.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Shutdown() error = %v, want %v", err, context.Canceled)
+	}
+	err := test.dispatcher.DispatchFor(context.Background(), "a", asyncTestEvent{Aggregate: "a", N: 5})
+	if !errors.Is(err, ErrDispatcherShutDown) {
+		t.Errorf("DispatchFor() after Shutdown error = %v, want %v", err, ErrDispatcherShutDown)
+	}
+
+	close(test.release)
+	test.shutdown(t)
+
+	handled := test.handledEvents()
+	if len(handled) != 5 {
+		t.Fatalf("%d events were handled, want the 5 queued before Shutdown", len(handled))
+	}
+	for n, event := range handled {
+		if event.N != n {
+			t.Errorf("handled event %d is %+v, want event %d", n, event, n)
+		}
+	}
+}
+
+func TestAsyncEventDispatcherBlocksWhileTheQueueIsFull(t *testing.T) {
+	test := newAsyncTest(t, AsyncEventDispatcherConfig{Workers: 1, QueueSize: 1}, true)
+
+	// The worker holds the first event, and the second fills the queue
+	test.dispatch(t, "a", 0)
+	<-test.started
+	test.dispatch(t, "a", 1)
+
+	// A Dispatch waiting for room gives up when its context ends
+	ctx, cancel := context.WithCancel(context.Background())
+	result := test.dispatchInBackground(ctx, "a", 2)
+	assertBlocked(t, result)
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("DispatchFor() error = %v, want %v", err, context.Canceled)
+	}
+
+	// or when the dispatcher shuts down, which it doesn't hold up
+	result = test.dispatchInBackground(context.Background(), "a", 3)
+	assertBlocked(t, result)
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := test.dispatcher.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Shutdown() error = %v, want %v", err, context.Canceled)
+	}
+	if err := <-result; !errors.Is(err, ErrDispatcherShutDown) {
+		t.Errorf("DispatchFor() error = %v, want %v", err, ErrDispatcherShutDown)
+	}
+
+	close(test.release)
+	test.shutdown(t)
+
+	if handled := test.handledEvents(); len(handled) != 2 {
+		t.Errorf("%d events were handled, want the 2 queued", len(handled))
+	}
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"sync"
+
+	"blog/pkg/ddd"
+)
+
+// InMemoryCheckpointStore is a simple in-memory implementation of ddd.CheckpointStore
+type InMemoryCheckpointStore struct {
+	checkpoints map[string]ddd.ProjectionCheckpoint
+	mu          sync.RWMutex
+}
+
+// NewInMemoryCheckpointStore creates a new, empty in-memory checkpoint store
+func NewInMemoryCheckpointStore() *InMemoryCheckpointStore {
+	return &InMemoryCheckpointStore{
+		checkpoints: make(map[string]ddd.ProjectionCheckpoint),
+	}
+}
+
+// Find implements ddd.CheckpointStore interface
+func (s *InMemoryCheckpointStore) Find(projection string) (ddd.ProjectionCheckpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	checkpoint, exists := s.checkpoints[projection]
+	if !exists {
+		return ddd.ProjectionCheckpoint{Projection: projection}, nil
+	}
+	return checkpoint, nil
+}
+
+// Save implements ddd.CheckpointStore interface
+func (s *InMemoryCheckpointStore) Save(checkpoint ddd.ProjectionCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[checkpoint.Projection] = checkpoint
+	return nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"blog/pkg/ddd"
+)
+
+// InMemoryDeadLetterStore is a simple in-memory implementation of DeadLetterStore
+type InMemoryDeadLetterStore struct {
+	letters map[int64]ddd.DeadLetter
+	nextID  int64
+	mu      sync.RWMutex
+}
+
+// NewInMemoryDeadLetterStore creates a new, empty in-memory dead letter store
+func NewInMemoryDeadLetterStore() *InMemoryDeadLetterStore {
+	return &InMemoryDeadLetterStore{
+		letters: make(map[int64]ddd.DeadLetter),
+		nextID:  1,
+	}
+}
+
+// Add implements ddd.DeadLetterStore interface
+func (s *InMemoryDeadLetterStore) Add(letter ddd.DeadLetter) (ddd.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letter.ID = s.nextID
+	s.nextID++
+	s.letters[letter.ID] = letter
+	return letter, nil
+}
+
+// FindByID implements ddd.DeadLetterStore interface
+func (s *InMemoryDeadLetterStore) FindByID(id int64) (ddd.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letter, exists := s.letters[id]
+	if !exists {
+		return ddd.DeadLetter{}, ddd.ErrDeadLetterNotFound
+	}
+	return letter, nil
+}
+
+// List implements ddd.DeadLetterStore interface
+func (s *InMemoryDeadLetterStore) List(status ddd.DeadLetterStatus) ([]ddd.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letters := []ddd.DeadLetter{}
+	for _, letter := range s.letters {
+		if status == "" || letter.Status == status {
+			letters = append(letters, letter)
+		}
+	}
+
+	sort.Slice(letters, func(i, j int) bool {
+		if !letters[i].FailedAt.Equal(letters[j].FailedAt) {
+			return letters[i].FailedAt.After(letters[j].FailedAt)
+		}
+		return letters[i].ID > letters[j].ID
+	})
+	return letters, nil
+}
+
+// Update implements ddd.DeadLetterStore interface
+func (s *InMemoryDeadLetterStore) Update(letter ddd.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.letters[letter.ID]; !exists {
+		return ddd.ErrDeadLetterNotFound
+	}
+	s.letters[letter.ID] = letter
+	return nil
+}
+
+// CountByStatus implements ddd.DeadLetterStore interface
+func (s *InMemoryDeadLetterStore) CountByStatus() (map[ddd.DeadLetterStatus]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[ddd.DeadLetterStatus]int{}
+	for _, letter := range s.letters {
+		counts[letter.Status]++
+	}
+	return counts, nil
+}
@@ -1,32 +1,51 @@
 package memory
 
 import (
+	"context"
+	"errors"
+	"sync"
+
 	"blog/pkg/ddd"
 
 	"go.uber.org/zap"
 )
 
 // InMemoryEventDispatcher is a simple in-memory implementation of EventDispatcher
+// Handlers run synchronously, in the goroutine that dispatches the event
 type InMemoryEventDispatcher struct {
-	handlers map[string][]ddd.EventHandlerFunc
+	handlers map[string][]ddd.ContextEventHandlerFunc
 	logger   *zap.SugaredLogger
+	mu       sync.RWMutex
 }
 
 // NewInMemoryEventDispatcher creates a new in-memory event dispatcher
 func NewInMemoryEventDispatcher(log *zap.SugaredLogger) *InMemoryEventDispatcher {
 	return &InMemoryEventDispatcher{
-		handlers: make(map[string][]ddd.EventHandlerFunc),
+		handlers: make(map[string][]ddd.ContextEventHandlerFunc),
 		logger:   log,
 	}
 }
 
-// Subscribe registers a handler for a specific event type
-func (d *InMemoryEventDispatcher) Subscribe(eventType string, handler ddd.EventHandlerFunc) {
+// Subscribe registers a handler for a specific event type. The dispatcher doesn't keep
+// track of handlers by name
+func (d *InMemoryEventDispatcher) Subscribe(eventType, name string, handler ddd.EventHandlerFunc) {
+	d.SubscribeContext(eventType, name, handler.WithContext())
+}
+
+// SubscribeContext registers a handler for a specific event type that takes the context
+func (d *InMemoryEventDispatcher) SubscribeContext(
+	eventType, name string,
+	handler ddd.ContextEventHandlerFunc,
+) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	d.handlers[eventType] = append(d.handlers[eventType], handler)
 }
 
 // Dispatch sends an event to all registered handlers for its type
-func (d *InMemoryEventDispatcher) Dispatch(event ddd.DomainEvent) error {
+// A failing handler doesn't stop the others, and the errors of all that failed are
+// returned together
+func (d *InMemoryEventDispatcher) Dispatch(ctx context.Context, event ddd.DomainEvent) error {
 	// Validate event is registered if using the global registry
 	registry := ddd.GetEventRegistry()
 	if err := registry.ValidateEvent(event); err != nil {
@@ -38,16 +57,21 @@ func (d *InMemoryEventDispatcher) Dispatch(event ddd.DomainEvent) error {
 		// For now, we'll log and continue
 	}
 
-	for _, handler := range d.handlers[event.EventType()] {
-		if err := handler(event); err != nil {
+	d.mu.RLock()
+	handlers := d.handlers[event.EventType()]
+	d.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
 			d.logger.Errorw("Error handling event",
 				"event_type", event.EventType(),
 				"error", err,
 			)
 
-			return err
+			errs = append(errs, err)
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
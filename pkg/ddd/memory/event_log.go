@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"sync"
+
+	"blog/pkg/ddd"
+)
+
+// InMemoryEventLog is a simple in-memory implementation of ddd.EventLog
+type InMemoryEventLog struct {
+	events []ddd.RecordedEvent
+	mu     sync.RWMutex
+}
+
+// NewInMemoryEventLog creates a new, empty in-memory event log
+func NewInMemoryEventLog() *InMemoryEventLog {
+	return &InMemoryEventLog{}
+}
+
+// Append encodes the aggregate's events and adds them to the end of the log
+func (l *InMemoryEventLog) Append(aggregateID string, events ...ddd.DomainEvent) error {
+	envelopes, err := ddd.EventRegistry.EncodeEvents(aggregateID, ddd.EventMetadata{}, events)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, envelope := range envelopes {
+		l.events = append(l.events, ddd.RecordedEvent{
+			Position: int64(len(l.events) + 1),
+			Envelope: envelope,
+		})
+	}
+	return nil
+}
+
+// ReadFrom implements ddd.EventLog interface
+func (l *InMemoryEventLog) ReadFrom(after int64, limit int) ([]ddd.RecordedEvent, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	events := []ddd.RecordedEvent{}
+	for _, event := range l.events {
+		if event.Position > after && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// Head implements ddd.EventLog interface
+func (l *InMemoryEventLog) Head() (int64, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return int64(len(l.events)), nil
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+type metricKey struct {
+	eventType string
+	handler   string
+}
+
+// InMemoryEventMetrics is an in-memory implementation of EventMetrics, counting since
+// the process started
+type InMemoryEventMetrics struct {
+	metrics map[metricKey]*ddd.EventMetric
+	mu      sync.Mutex
+}
+
+// NewInMemoryEventMetrics creates a new, empty set of event metrics
+func NewInMemoryEventMetrics() *InMemoryEventMetrics {
+	return &InMemoryEventMetrics{
+		metrics: make(map[metricKey]*ddd.EventMetric),
+	}
+}
+
+// ObserveDispatch implements ddd.EventMetrics interface
+func (m *InMemoryEventMetrics) ObserveDispatch(eventType string, duration time.Duration, err error) {
+	m.observe(metricKey{eventType: eventType}, duration, err)
+}
+
+// ObserveHandler implements ddd.EventMetrics interface
+func (m *InMemoryEventMetrics) ObserveHandler(info ddd.HandlerInfo, duration time.Duration, err error) {
+	m.observe(metricKey{eventType: info.EventType, handler: info.Name}, duration, err)
+}
+
+func (m *InMemoryEventMetrics) observe(key metricKey, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metric, exists := m.metrics[key]
+	if !exists {
+		metric = &ddd.EventMetric{EventType: key.eventType, Handler: key.handler}
+		m.metrics[key] = metric
+	}
+
+	metric.Count++
+	if err != nil {
+		metric.Failures++
+	}
+	metric.TotalDuration += duration
+	metric.MaxDuration = max(metric.MaxDuration, duration)
+}
+
+// Snapshot returns the metrics so far, by event type, with each type's dispatches
+// before its handlers
+func (m *InMemoryEventMetrics) Snapshot() []ddd.EventMetric {
+	m.mu.Lock()
+	metrics := make([]ddd.EventMetric, 0, len(m.metrics))
+	for _, metric := range m.metrics {
+		metrics = append(metrics, *metric)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].EventType != metrics[j].EventType {
+			return metrics[i].EventType < metrics[j].EventType
+		}
+		return metrics[i].Handler < metrics[j].Handler
+	})
+	return metrics
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"sync"
+
+	"blog/pkg/ddd"
+)
+
+// InMemoryEventStore is a simple in-memory implementation of EventStore
+type InMemoryEventStore struct {
+	streams map[string][]ddd.DomainEvent
+	mu      sync.RWMutex
+}
+
+// NewInMemoryEventStore creates a new, empty in-memory event store
+func NewInMemoryEventStore() *InMemoryEventStore {
+	return &InMemoryEventStore{
+		streams: make(map[string][]ddd.DomainEvent),
+	}
+}
+
+// Append implements ddd.EventStore interface
+func (s *InMemoryEventStore) Append(aggregateID string, expectedVersion int, events []ddd.DomainEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream := s.streams[aggregateID]
+	if len(stream) != expectedVersion {
+		return ddd.NewConcurrencyConflictError(aggregateID, expectedVersion, len(stream))
+	}
+
+	if len(events) > 0 {
+		s.streams[aggregateID] = append(stream, events...)
+	}
+	return nil
+}
+
+// Load implements ddd.EventStore interface
+func (s *InMemoryEventStore) Load(aggregateID string) ([]ddd.DomainEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ddd.DomainEvent{}, s.streams[aggregateID]...), nil
+}
+
+// Delete removes the aggregate's stream
+func (s *InMemoryEventStore) Delete(aggregateID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.streams, aggregateID)
+}
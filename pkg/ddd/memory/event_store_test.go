@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+	dddtesting "blog/pkg/ddd/testing"
+)
+
+// storeTestEvent is the Nth event of an aggregate's stream
+type storeTestEvent struct {
+	N int
+}
+
+func (e storeTestEvent) OccurredOn() time.Time { return time.Time{} }
+func (e storeTestEvent) EventType() string     { return "StoreTest" }
+
+func TestInMemoryEventStore(t *testing.T) {
+	dddtesting.RunEventStoreTests(t,
+		func(t *testing.T) ddd.EventStore { return NewInMemoryEventStore() },
+		storeTestEvent{N: 1},
+		storeTestEvent{N: 2},
+		storeTestEvent{N: 3},
+	)
+}
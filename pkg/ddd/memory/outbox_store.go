@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+// InMemoryOutboxStore is a simple in-memory implementation of ddd.OutboxStore, for the
+// in-memory repositories to add the events of the aggregates they save to
+type InMemoryOutboxStore struct {
+	messages []ddd.OutboxMessage
+	mu       sync.RWMutex
+}
+
+// NewInMemoryOutboxStore creates a new, empty in-memory outbox
+func NewInMemoryOutboxStore() *InMemoryOutboxStore {
+	return &InMemoryOutboxStore{}
+}
+
+// Append encodes the events the aggregate has recorded and adds them to the outbox,
+// marking them committed. Nothing is added if any of them can't be encoded
+func (s *InMemoryOutboxStore) Append(aggregateID string, aggregate ddd.EventAggregate) error {
+	envelopes, err := ddd.EventRegistry.EncodeEvents(
+		aggregateID,
+		aggregate.EventMetadata(),
+		aggregate.GetUncommittedEvents(),
+	)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, envelope := range envelopes {
+		message := ddd.NewOutboxMessage(envelope)
+		message.ID = int64(len(s.messages) + 1)
+		s.messages = append(s.messages, message)
+	}
+
+	aggregate.MarkEventsAsCommitted()
+	return nil
+}
+
+// FindDue implements ddd.OutboxStore interface
+func (s *InMemoryOutboxStore) FindDue(at time.Time, limit int) ([]ddd.OutboxMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := []ddd.OutboxMessage{}
+	waiting := map[string]bool{}
+	for _, message := range s.messages {
+		if message.Status != ddd.OutboxStatusPending {
+			continue
+		}
+		if message.NextAttemptAt.After(at) {
+			waiting[message.AggregateID] = true
+			continue
+		}
+		if !waiting[message.AggregateID] && len(messages) < limit {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+// Update implements ddd.OutboxStore interface
+func (s *InMemoryOutboxStore) Update(message ddd.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if message.ID < 1 || message.ID > int64(len(s.messages)) {
+		return nil
+	}
+	s.messages[message.ID-1] = message
+	return nil
+}
+
+// Messages returns every message in the outbox, oldest first
+func (s *InMemoryOutboxStore) Messages() []ddd.OutboxMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ddd.OutboxMessage{}, s.messages...)
+}
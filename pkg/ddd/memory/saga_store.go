@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"blog/pkg/ddd/services"
+)
+
+// InMemorySagaStore is a simple in-memory implementation of services.SagaStore
+type InMemorySagaStore struct {
+	sagas map[string]services.SagaState
+	mu    sync.RWMutex
+}
+
+// NewInMemorySagaStore creates a new, empty in-memory saga store
+func NewInMemorySagaStore() *InMemorySagaStore {
+	return &InMemorySagaStore{
+		sagas: make(map[string]services.SagaState),
+	}
+}
+
+// Create implements services.SagaStore interface
+func (s *InMemorySagaStore) Create(state services.SagaState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, saga := range s.sagas {
+		if saga.SagaType == state.SagaType && saga.CorrelationKey == state.CorrelationKey {
+			return services.ErrSagaExists
+		}
+	}
+	s.sagas[state.ID] = copySagaState(state)
+	return nil
+}
+
+// FindByID implements services.SagaStore interface
+func (s *InMemorySagaStore) FindByID(id string) (services.SagaState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	saga, exists := s.sagas[id]
+	if !exists {
+		return services.SagaState{}, services.ErrSagaNotFound
+	}
+	return copySagaState(saga), nil
+}
+
+// FindByCorrelation implements services.SagaStore interface
+func (s *InMemorySagaStore) FindByCorrelation(
+	sagaType, correlationKey string,
+) (services.SagaState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, saga := range s.sagas {
+		if saga.SagaType == sagaType && saga.CorrelationKey == correlationKey {
+			return copySagaState(saga), nil
+		}
+	}
+	return services.SagaState{}, services.ErrSagaNotFound
+}
+
+// Update implements services.SagaStore interface
+func (s *InMemorySagaStore) Update(state services.SagaState) (services.SagaState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saga, exists := s.sagas[state.ID]
+	if !exists {
+		return services.SagaState{}, services.ErrSagaNotFound
+	}
+	if saga.Version != state.Version {
+		return services.SagaState{}, services.ErrSagaConflict
+	}
+
+	state.Version++
+	s.sagas[state.ID] = copySagaState(state)
+	return state, nil
+}
+
+// FindUnfinished implements services.SagaStore interface
+func (s *InMemorySagaStore) FindUnfinished(idleSince time.Time) ([]services.SagaState, error) {
+	return s.find(func(saga services.SagaState) bool {
+		return !saga.Status.Finished() && saga.UpdatedAt.Before(idleSince)
+	}), nil
+}
+
+// FindTimedOut implements services.SagaStore interface
+func (s *InMemorySagaStore) FindTimedOut(at time.Time) ([]services.SagaState, error) {
+	return s.find(func(saga services.SagaState) bool {
+		return saga.Status == services.SagaStatusRunning &&
+			!saga.TimeoutAt.IsZero() &&
+			!saga.TimeoutAt.After(at)
+	}), nil
+}
+
+func (s *InMemorySagaStore) find(matches func(saga services.SagaState) bool) []services.SagaState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sagas := []services.SagaState{}
+	for _, saga := range s.sagas {
+		if matches(saga) {
+			sagas = append(sagas, copySagaState(saga))
+		}
+	}
+
+	sort.Slice(sagas, func(i, j int) bool {
+		return sagas[i].StartedAt.Before(sagas[j].StartedAt)
+	})
+	return sagas
+}
+
+// copySagaState copies the saga's slices and data, so the stored saga doesn't change
+// along with the one the manager is running
+func copySagaState(state services.SagaState) services.SagaState {
+	state.CompletedSteps = slices.Clone(state.CompletedSteps)
+	state.CompensatedSteps = slices.Clone(state.CompensatedSteps)
+	state.Data = maps.Clone(state.Data)
+	return state
+}
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -80,7 +81,7 @@ func (uow *UnitOfWork) Commit() error {
 	// Dispatch events after successful persistence
 	if uow.eventDispatcher != nil && len(allEvents) > 0 {
 		for _, event := range allEvents {
-			uow.eventDispatcher.Dispatch(event)
+			uow.eventDispatcher.Dispatch(context.Background(), event)
 		}
 	}
 
@@ -169,7 +170,7 @@ func (euow *EventOnlyUnitOfWork) Commit() error {
 	// Dispatch events
 	if euow.eventDispatcher != nil && len(allEvents) > 0 {
 		for _, event := range allEvents {
-			euow.eventDispatcher.Dispatch(event)
+			euow.eventDispatcher.Dispatch(context.Background(), event)
 		}
 	}
 
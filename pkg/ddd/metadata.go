@@ -0,0 +1,54 @@
+package ddd
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// EventMetadata describes where an event came from. Events caused by the same request
+// share its correlation ID, and each event's causation ID is the ID of the event, or
+// the request, that caused it
+//
+// The metadata of whatever is being handled travels in the context: the request while
+// it's served, and the event while its handlers run
+type EventMetadata struct {
+	EventID       string `json:"event_id,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	CausationID   string `json:"causation_id,omitempty"`
+	// ActorID is the user whose action caused the event, empty for background work
+	ActorID string `json:"actor_id,omitempty"`
+}
+
+// Caused returns the metadata for a new event caused by what this metadata describes.
+// The event gets its own ID, and starts a new correlation if there isn't one
+func (m EventMetadata) Caused() EventMetadata {
+	caused := EventMetadata{
+		EventID:       uuid.NewString(),
+		CorrelationID: m.CorrelationID,
+		CausationID:   m.EventID,
+		ActorID:       m.ActorID,
+	}
+	if caused.CausationID == "" {
+		// Caused directly by the request
+		caused.CausationID = m.CorrelationID
+	}
+	if caused.CorrelationID == "" {
+		caused.CorrelationID = caused.EventID
+	}
+	return caused
+}
+
+type eventMetadataKey struct{}
+
+// WithEventMetadata returns a copy of the context carrying the metadata
+func WithEventMetadata(ctx context.Context, metadata EventMetadata) context.Context {
+	return context.WithValue(ctx, eventMetadataKey{}, metadata)
+}
+
+// EventMetadataFrom returns the metadata the context carries, which is empty if it
+// carries none
+func EventMetadataFrom(ctx context.Context) EventMetadata {
+	metadata, _ := ctx.Value(eventMetadataKey{}).(EventMetadata)
+	return metadata
+}
@@ -0,0 +1,206 @@
+package ddd
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxStatus is where an outbox message is in being published
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusPublished OutboxStatus = "published"
+	OutboxStatusFailed    OutboxStatus = "failed"
+)
+
+func (s OutboxStatus) String() string {
+	return string(s)
+}
+
+// OutboxMessage is a domain event stored alongside the change that raised it, waiting
+// to be published to the event dispatcher
+type OutboxMessage struct {
+	ID            int64
+	AggregateID   string
+	EventType     string
+	EventVersion  int
+	Metadata      EventMetadata
+	Payload       []byte
+	OccurredOn    time.Time
+	Status        OutboxStatus
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	PublishedAt   *time.Time
+}
+
+// NewOutboxMessage makes an outbox message of the encoded event. The ID is assigned
+// when the message is stored
+func NewOutboxMessage(envelope EventEnvelope) OutboxMessage {
+	return OutboxMessage{
+		AggregateID:   envelope.AggregateID,
+		EventType:     envelope.Type,
+		EventVersion:  envelope.Version,
+		Metadata:      envelope.Metadata,
+		Payload:       envelope.Payload,
+		OccurredOn:    envelope.OccurredOn,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: envelope.OccurredOn,
+	}
+}
+
+// Envelope returns the encoded event the message was made from
+func (m OutboxMessage) Envelope() EventEnvelope {
+	return EventEnvelope{
+		Type:        m.EventType,
+		Version:     m.EventVersion,
+		OccurredOn:  m.OccurredOn,
+		AggregateID: m.AggregateID,
+		Metadata:    m.Metadata,
+		Payload:     m.Payload,
+	}
+}
+
+// Event decodes the message back into the event it was made from
+func (m OutboxMessage) Event() (DomainEvent, error) {
+	return EventRegistry.Decode(m.Envelope())
+}
+
+// MarkPublished records that the message reached the dispatcher
+func (m *OutboxMessage) MarkPublished(at time.Time) {
+	m.Status = OutboxStatusPublished
+	m.Attempts++
+	m.LastError = ""
+	m.PublishedAt = &at
+}
+
+// RecordFailure counts a failed attempt and schedules the next one, giving up on the
+// message once it has been tried maxAttempts times
+func (m *OutboxMessage) RecordFailure(err error, at time.Time, maxAttempts int, retryDelay time.Duration) {
+	m.Attempts++
+	m.LastError = err.Error()
+	if m.Attempts >= maxAttempts {
+		m.Status = OutboxStatusFailed
+		return
+	}
+	m.NextAttemptAt = at.Add(retryDelay << (m.Attempts - 1))
+}
+
+// OutboxStore reads and updates the messages waiting in the outbox. Messages are
+// written by the repositories, in the same transaction as the change that raised them
+//
+// Published messages are marked rather than deleted. A store that is also read as an
+// EventLog, for a Projector, must never delete them, or clean up old ones, as the
+// projections would miss their events on the next rebuild
+type OutboxStore interface {
+	// FindDue returns up to limit pending messages due for an attempt at the given time,
+	// oldest first. A message isn't due while an earlier message for the same aggregate
+	// is waiting for a retry, so each aggregate's events are published in order
+	FindDue(at time.Time, limit int) ([]OutboxMessage, error)
+	// Update saves the message's status and attempts
+	Update(message OutboxMessage) error
+}
+
+// OutboxRelayConfig controls how the relay publishes outbox messages
+type OutboxRelayConfig struct {
+	// BatchSize is the most messages published in one run
+	BatchSize int
+	// MaxAttempts is how many times a message is tried before it's marked failed
+	MaxAttempts int
+	// RetryDelay is the wait after the first failed attempt, doubled after each one
+	// that follows
+	RetryDelay time.Duration
+}
+
+// DefaultOutboxRelayConfig returns the relay settings used when none are configured
+func DefaultOutboxRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		BatchSize:   100,
+		MaxAttempts: 10,
+		RetryDelay:  5 * time.Second,
+	}
+}
+
+// OutboxRelay publishes outbox messages to the event dispatcher. A message is only
+// marked published after the dispatcher has accepted it, so with a dispatcher that runs
+// the handlers before returning, events are delivered at least once: a message that was
+// dispatched but not marked, because the process stopped in between, is dispatched again
+// on the next run. Handlers must tolerate repeats
+//
+// The relay never deletes messages, so the outbox can also be read as an EventLog. Any
+// cleanup of published messages belongs here, and is only safe for an outbox nothing
+// projects from
+//
+// A dispatcher that runs handlers in the background accepts a message once it's queued,
+// so its events are delivered at most once: a message is marked published while its
+// event is still queued, and is lost if the process stops before the handlers have run.
+// Handler failures are then up to the dispatcher rather than retried by the relay
+type OutboxRelay struct {
+	store      OutboxStore
+	dispatcher EventDispatcher
+	config     OutboxRelayConfig
+}
+
+// NewOutboxRelay creates a relay that publishes from the store to the dispatcher
+func NewOutboxRelay(
+	store OutboxStore,
+	dispatcher EventDispatcher,
+	config OutboxRelayConfig,
+) *OutboxRelay {
+	return &OutboxRelay{
+		store:      store,
+		dispatcher: dispatcher,
+		config:     config,
+	}
+}
+
+// Relay publishes the messages due at the given time, returning how many were published.
+// A message that can't be decoded or dispatched is retried later, and holds back the
+// rest of its aggregate's messages until it succeeds or is given up on
+//
+// Each message is dispatched with its event's metadata in the context
+func (r *OutboxRelay) Relay(ctx context.Context, at time.Time) (int, error) {
+	messages, err := r.store.FindDue(at, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	heldBack := map[string]bool{}
+	for i := range messages {
+		message := &messages[i]
+		if heldBack[message.AggregateID] {
+			continue
+		}
+
+		if err := r.publish(ctx, message); err != nil {
+			message.RecordFailure(err, at, r.config.MaxAttempts, r.config.RetryDelay)
+			if message.Status == OutboxStatusPending {
+				heldBack[message.AggregateID] = true
+			}
+		} else {
+			message.MarkPublished(at)
+			published++
+		}
+
+		if err := r.store.Update(*message); err != nil {
+			return published, err
+		}
+	}
+
+	return published, nil
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, message *OutboxMessage) error {
+	event, err := message.Event()
+	if err != nil {
+		return err
+	}
+
+	ctx = WithEventMetadata(ctx, message.Metadata)
+	if dispatcher, ok := r.dispatcher.(AggregateEventDispatcher); ok {
+		return dispatcher.DispatchFor(ctx, message.AggregateID, event)
+	}
+	return r.dispatcher.Dispatch(ctx, event)
+}
@@ -0,0 +1,161 @@
+package ddd_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+// outboxTestEvent is an event the relay tests store and publish, told apart by name
+type outboxTestEvent struct {
+	Name string    `json:"name"`
+	At   time.Time `json:"at"`
+}
+
+func (e outboxTestEvent) OccurredOn() time.Time { return e.At }
+func (e outboxTestEvent) EventType() string     { return "OutboxTest" }
+
+func init() {
+	ddd.EventRegistry.Register(outboxTestEvent{}, "An event the outbox relay tests publish")
+}
+
+// flakyDispatcher records the names of the events it's given, failing those in failing
+type flakyDispatcher struct {
+	failing    map[string]bool
+	dispatched []string
+}
+
+func (d *flakyDispatcher) Dispatch(ctx context.Context, event ddd.DomainEvent) error {
+	name := event.(*outboxTestEvent).Name
+	if d.failing[name] {
+		return errors.New("handler unavailable")
+	}
+	d.dispatched = append(d.dispatched, name)
+	return nil
+}
+
+func (d *flakyDispatcher) Subscribe(eventType, name string, handler ddd.EventHandlerFunc) {}
+
+func (d *flakyDispatcher) SubscribeContext(
+	eventType, name string,
+	handler ddd.ContextEventHandlerFunc,
+) {
+}
+
+var outboxTestStart = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// appendOutboxTestEvents adds an event for each name to the outbox, raised by the
+// aggregate
+func appendOutboxTestEvents(
+	t *testing.T,
+	outbox *dddmemory.InMemoryOutboxStore,
+	aggregateID string,
+	names ...string,
+) {
+	t.Helper()
+
+	aggregate := &ddd.AggregateBase{}
+	for _, name := range names {
+		aggregate.RecordEvent(outboxTestEvent{Name: name, At: outboxTestStart})
+	}
+	if err := outbox.Append(aggregateID, aggregate); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+}
+
+func relayAt(t *testing.T, relay *ddd.OutboxRelay, at time.Time) int {
+	t.Helper()
+
+	published, err := relay.Relay(context.Background(), at)
+	if err != nil {
+		t.Fatalf("Relay() failed: %v", err)
+	}
+	return published
+}
+
+func TestOutboxRelayHoldsBackAnAggregateAfterAFailure(t *testing.T) {
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	dispatcher := &flakyDispatcher{failing: map[string]bool{"a1": true}}
+	relay := ddd.NewOutboxRelay(outbox, dispatcher, ddd.OutboxRelayConfig{
+		BatchSize:   10,
+		MaxAttempts: 3,
+		RetryDelay:  time.Second,
+	})
+
+	appendOutboxTestEvents(t, outbox, "a", "a1", "a2")
+	appendOutboxTestEvents(t, outbox, "b", "b1")
+
+	// a2 waits behind a1, while b's events carry on
+	if published := relayAt(t, relay, outboxTestStart); published != 1 {
+		t.Errorf("Relay() published %d messages, want 1", published)
+	}
+	if !slices.Equal(dispatcher.dispatched, []string{"b1"}) {
+		t.Errorf("dispatched %v, want [b1]", dispatcher.dispatched)
+	}
+
+	// Nothing of a's is due before a1's retry
+	if published := relayAt(t, relay, outboxTestStart.Add(time.Second/2)); published != 0 {
+		t.Errorf("Relay() before the retry published %d messages, want 0", published)
+	}
+
+	delete(dispatcher.failing, "a1")
+	if published := relayAt(t, relay, outboxTestStart.Add(time.Second)); published != 2 {
+		t.Errorf("Relay() at the retry published %d messages, want 2", published)
+	}
+	if !slices.Equal(dispatcher.dispatched, []string{"b1", "a1", "a2"}) {
+		t.Errorf("dispatched %v, want [b1 a1 a2]", dispatcher.dispatched)
+	}
+}
+
+func TestOutboxRelayBacksOffThenGivesUp(t *testing.T) {
+	outbox := dddmemory.NewInMemoryOutboxStore()
+	dispatcher := &flakyDispatcher{failing: map[string]bool{"a1": true}}
+	relay := ddd.NewOutboxRelay(outbox, dispatcher, ddd.OutboxRelayConfig{
+		BatchSize:   10,
+		MaxAttempts: 3,
+		RetryDelay:  time.Second,
+	})
+
+	appendOutboxTestEvents(t, outbox, "a", "a1", "a2")
+
+	// The delay doubles after each failed attempt
+	at := outboxTestStart
+	for attempt, wantDelay := range []time.Duration{time.Second, 2 * time.Second} {
+		relayAt(t, relay, at)
+
+		message := outbox.Messages()[0]
+		if message.Status != ddd.OutboxStatusPending || message.Attempts != attempt+1 {
+			t.Fatalf("after attempt %d the message is %s with %d attempts, want pending with %d",
+				attempt+1, message.Status, message.Attempts, attempt+1)
+		}
+		if want := at.Add(wantDelay); !message.NextAttemptAt.Equal(want) {
+			t.Errorf("after attempt %d the next attempt is at %s, want %s",
+				attempt+1, message.NextAttemptAt, want)
+		}
+		at = message.NextAttemptAt
+	}
+
+	// The last attempt moves a1 to failed, which no longer holds back a2
+	if published := relayAt(t, relay, at); published != 1 {
+		t.Errorf("Relay() published %d messages, want 1", published)
+	}
+
+	messages := outbox.Messages()
+	if messages[0].Status != ddd.OutboxStatusFailed || messages[0].LastError == "" {
+		t.Errorf("a1 is %s with error %q, want failed with its error",
+			messages[0].Status, messages[0].LastError)
+	}
+	if messages[1].Status != ddd.OutboxStatusPublished {
+		t.Errorf("a2 is %s, want published", messages[1].Status)
+	}
+
+	// A failed message isn't tried again
+	if published := relayAt(t, relay, at.Add(time.Hour)); published != 0 {
+		t.Errorf("Relay() published %d messages after giving up, want 0", published)
+	}
+}
@@ -0,0 +1,253 @@
+package ddd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrProjectionNotFound is returned when rebuilding a projection that isn't registered
+var ErrProjectionNotFound = errors.New("projection not found")
+
+// Projection builds a read model from events, such as a table shaped for a list page
+// that would otherwise take a query per row. Its events are given to it in the order
+// they were recorded, from the projection's checkpoint onwards
+//
+// An event can be given again, after a failure or a restart before the checkpoint
+// was saved, so Project must be idempotent
+type Projection interface {
+	// Name identifies the projection, and its checkpoint
+	Name() string
+	// EventTypes are the types of event the projection is given
+	EventTypes() []string
+	// Project applies the event to the read model
+	Project(ctx context.Context, event DomainEvent) error
+	// Reset empties the read model, so it can be rebuilt from the first event, returning
+	// 0. A read model kept from before its events were, which the event log can't rebuild,
+	// is instead rebuilt from the state the events change, returning the position of the
+	// last event it reflects, so it's rebuilt from the events after it
+	Reset(ctx context.Context) (int64, error)
+}
+
+// RecordedEvent is an encoded event at its position in the event log
+type RecordedEvent struct {
+	Position int64
+	Envelope EventEnvelope
+}
+
+// EventLog is every event that has been recorded, in the order they were recorded.
+// Positions increase with each event, but needn't be consecutive
+type EventLog interface {
+	// ReadFrom returns up to limit events after the position, in order. Position 0 is
+	// before the first event
+	ReadFrom(after int64, limit int) ([]RecordedEvent, error)
+	// Head returns the position of the latest event, or 0 if there are none
+	Head() (int64, error)
+}
+
+// ProjectionCheckpoint is how far through the event log a projection has got
+type ProjectionCheckpoint struct {
+	Projection string
+	// Position is the position of the last event the projection has dealt with, or 0
+	// if it hasn't dealt with any
+	Position int64
+	// LastError is why the projection stopped before the next event, if it did
+	LastError string
+	UpdatedAt time.Time
+}
+
+// CheckpointStore keeps each projection's checkpoint
+type CheckpointStore interface {
+	// Find returns the projection's checkpoint, at position 0 if it has none
+	Find(projection string) (ProjectionCheckpoint, error)
+	// Save adds or replaces the projection's checkpoint
+	Save(checkpoint ProjectionCheckpoint) error
+}
+
+// ProjectionStatus is a projection along with how far it has got through the event log
+type ProjectionStatus struct {
+	Name       string
+	EventTypes []string
+	Checkpoint ProjectionCheckpoint
+	// Head is the position of the latest event in the log
+	Head int64
+}
+
+// Projector keeps projections up to date with the event log. Each run reads the events
+// after a projection's checkpoint, gives it the ones it handles and moves the checkpoint
+// on after each batch. A projection that fails stops at the failing event, which it's
+// given again on the next run, so a read model never skips an event
+type Projector struct {
+	log         EventLog
+	checkpoints CheckpointStore
+	batchSize   int
+	projections map[string]Projection
+	names       []string
+	mu          sync.Mutex
+}
+
+// NewProjector creates a projector reading batchSize events at a time from the log, and
+// keeping the projections' checkpoints in the store
+func NewProjector(log EventLog, checkpoints CheckpointStore, batchSize int) *Projector {
+	return &Projector{
+		log:         log,
+		checkpoints: checkpoints,
+		batchSize:   batchSize,
+		projections: make(map[string]Projection),
+	}
+}
+
+// Register adds a projection for the projector to keep up to date. It panics if a
+// projection with the name is already registered
+func (p *Projector) Register(projection Projection) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.projections[projection.Name()]; exists {
+		panic(fmt.Sprintf("projection %s already registered", projection.Name()))
+	}
+	p.projections[projection.Name()] = projection
+	p.names = append(p.names, projection.Name())
+}
+
+// Run catches every projection up with the event log, returning how many events were
+// projected. A failing projection doesn't hold back the others
+func (p *Projector) Run(ctx context.Context, at time.Time) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	projected := 0
+	var errs []error
+	for _, name := range p.names {
+		caughtUp, err := p.catchUp(ctx, p.projections[name], at)
+		projected += caughtUp
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return projected, errors.Join(errs...)
+}
+
+// Rebuild resets the projection's read model and projects the event log again from
+// where the reset left it, the first event if it was emptied, returning how many events
+// were projected. Until it finishes, the read model is only partly rebuilt
+func (p *Projector) Rebuild(ctx context.Context, name string, at time.Time) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	projection, exists := p.projections[name]
+	if !exists {
+		return 0, ErrProjectionNotFound
+	}
+
+	position, err := projection.Reset(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	checkpoint := ProjectionCheckpoint{Projection: name, Position: position, UpdatedAt: at}
+	if err := p.checkpoints.Save(checkpoint); err != nil {
+		return 0, err
+	}
+
+	return p.catchUp(ctx, projection, at)
+}
+
+// Status returns each projection's checkpoint, in the order they were registered
+func (p *Projector) Status() ([]ProjectionStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	head, err := p.log.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []ProjectionStatus{}
+	for _, name := range p.names {
+		checkpoint, err := p.checkpoints.Find(name)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, ProjectionStatus{
+			Name:       name,
+			EventTypes: p.projections[name].EventTypes(),
+			Checkpoint: checkpoint,
+			Head:       head,
+		})
+	}
+
+	return statuses, nil
+}
+
+func (p *Projector) catchUp(ctx context.Context, projection Projection, at time.Time) (int, error) {
+	name := projection.Name()
+	checkpoint, err := p.checkpoints.Find(name)
+	if err != nil {
+		return 0, err
+	}
+	checkpoint.Projection = name
+
+	handled := map[string]bool{}
+	for _, eventType := range projection.EventTypes() {
+		handled[eventType] = true
+	}
+
+	projected := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return projected, err
+		}
+
+		events, err := p.log.ReadFrom(checkpoint.Position, p.batchSize)
+		if err != nil {
+			return projected, err
+		}
+		if len(events) == 0 {
+			return projected, nil
+		}
+
+		for _, recorded := range events {
+			if handled[recorded.Envelope.Type] {
+				if err := p.project(ctx, projection, recorded.Envelope); err != nil {
+					err = fmt.Errorf(
+						"projection %s failed at position %d: %w",
+						name,
+						recorded.Position,
+						err,
+					)
+					checkpoint.LastError = err.Error()
+					checkpoint.UpdatedAt = at
+					return projected, errors.Join(err, p.checkpoints.Save(checkpoint))
+				}
+				projected++
+			}
+			checkpoint.Position = recorded.Position
+		}
+
+		checkpoint.LastError = ""
+		checkpoint.UpdatedAt = at
+		if err := p.checkpoints.Save(checkpoint); err != nil {
+			return projected, err
+		}
+
+		if len(events) < p.batchSize {
+			return projected, nil
+		}
+	}
+}
+
+// project decodes the event and gives it to the projection, with the event's metadata
+// in the context
+func (p *Projector) project(ctx context.Context, projection Projection, envelope EventEnvelope) error {
+	event, err := EventRegistry.Decode(envelope)
+	if err != nil {
+		return err
+	}
+
+	return projection.Project(WithEventMetadata(ctx, envelope.Metadata), event)
+}
@@ -0,0 +1,258 @@
+package ddd_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+)
+
+// projectionTestEvent is an event the projector tests project, told apart by name
+type projectionTestEvent struct {
+	Name string `json:"name"`
+}
+
+func (e projectionTestEvent) OccurredOn() time.Time { return time.Time{} }
+func (e projectionTestEvent) EventType() string     { return "ProjectionTest" }
+
+func init() {
+	ddd.EventRegistry.Register(projectionTestEvent{}, "An event the projector tests project")
+}
+
+// recordingProjection records the names of the events it projects, failing on those in
+// failing. Reset forgets them and returns resetTo
+type recordingProjection struct {
+	failing   map[string]bool
+	projected []string
+	resets    int
+	resetTo   int64
+}
+
+func (p *recordingProjection) Name() string         { return "recording" }
+func (p *recordingProjection) EventTypes() []string { return []string{"ProjectionTest"} }
+
+func (p *recordingProjection) Project(ctx context.Context, event ddd.DomainEvent) error {
+	name := event.(*projectionTestEvent).Name
+	if p.failing[name] {
+		return errors.New(name + " can't be projected")
+	}
+	p.projected = append(p.projected, name)
+	return nil
+}
+
+func (p *recordingProjection) Reset(ctx context.Context) (int64, error) {
+	p.resets++
+	p.projected = nil
+	return p.resetTo, nil
+}
+
+type projectorTest struct {
+	projector   *ddd.Projector
+	log         *dddmemory.InMemoryEventLog
+	checkpoints *dddmemory.InMemoryCheckpointStore
+	projection  *recordingProjection
+}
+
+func newProjectorTest(batchSize int) *projectorTest {
+	test := &projectorTest{
+		log:         dddmemory.NewInMemoryEventLog(),
+		checkpoints: dddmemory.NewInMemoryCheckpointStore(),
+		projection:  &recordingProjection{failing: map[string]bool{}},
+	}
+	test.projector = ddd.NewProjector(test.log, test.checkpoints, batchSize)
+	test.projector.Register(test.projection)
+	return test
+}
+
+// record adds an event to the log for each name, or one the projection doesn't handle
+// for each empty name
+func (p *projectorTest) record(t *testing.T, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		var event ddd.DomainEvent = projectionTestEvent{Name: name}
+		if name == "" {
+			event = middlewareTestEvent{}
+		}
+		if err := p.log.Append("aggregate", event); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+}
+
+func (p *projectorTest) checkpoint(t *testing.T) ddd.ProjectionCheckpoint {
+	t.Helper()
+
+	checkpoint, err := p.checkpoints.Find("recording")
+	if err != nil {
+		t.Fatalf("Find() failed: %v", err)
+	}
+	return checkpoint
+}
+
+func (p *projectorTest) assertProjected(t *testing.T, want ...string) {
+	t.Helper()
+
+	if !slices.Equal(p.projection.projected, want) {
+		t.Errorf("projected %q, want %q", p.projection.projected, want)
+	}
+}
+
+func TestProjectorMovesTheCheckpointOn(t *testing.T) {
+	test := newProjectorTest(10)
+	test.record(t, "a", "b", "", "c")
+
+	projected, err := test.projector.Run(context.Background(), time.Now())
+	if err != nil || projected != 3 {
+		t.Fatalf("Run() = %d, %v, want 3 events projected", projected, err)
+	}
+	test.assertProjected(t, "a", "b", "c")
+
+	// Events the projection doesn't handle move the checkpoint on too
+	if got := test.checkpoint(t); got.Position != 4 || got.LastError != "" {
+		t.Errorf("checkpoint = %+v, want it at 4", got)
+	}
+
+	test.record(t, "d")
+	projected, err = test.projector.Run(context.Background(), time.Now())
+	if err != nil || projected != 1 {
+		t.Fatalf("Run() = %d, %v, want 1 event projected", projected, err)
+	}
+	projected, err = test.projector.Run(context.Background(), time.Now())
+	if err != nil || projected != 0 {
+		t.Fatalf("Run() = %d, %v, want no events projected", projected, err)
+	}
+	test.assertProjected(t, "a", "b", "c", "d")
+
+	statuses, err := test.projector.Status()
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Checkpoint.Position != 5 || statuses[0].Head != 5 {
+		t.Errorf("Status() = %+v, want the checkpoint at the head, 5", statuses)
+	}
+}
+
+func TestProjectorStopsAtTheFailingEvent(t *testing.T) {
+	test := newProjectorTest(10)
+	test.projection.failing["b"] = true
+	test.record(t, "a", "b", "c")
+
+	for range 2 {
+		projected, err := test.projector.Run(context.Background(), time.Now())
+		if err == nil || !strings.Contains(err.Error(), "position 2") {
+			t.Fatalf("Run() error = %v, want the failure at position 2", err)
+		}
+		if projected > 1 {
+			t.Errorf("Run() projected %d events, want at most the one before the failure", projected)
+		}
+
+		checkpoint := test.checkpoint(t)
+		if checkpoint.Position != 1 || !strings.Contains(checkpoint.LastError, "b can't be projected") {
+			t.Errorf("checkpoint = %+v, want it before the failing event with its error", checkpoint)
+		}
+	}
+	test.assertProjected(t, "a")
+
+	// The failing event is given again once the projection can deal with it
+	delete(test.projection.failing, "b")
+	projected, err := test.projector.Run(context.Background(), time.Now())
+	if err != nil || projected != 2 {
+		t.Fatalf("Run() = %d, %v, want 2 events projected", projected, err)
+	}
+	test.assertProjected(t, "a", "b", "c")
+	if got := test.checkpoint(t); got.Position != 3 || got.LastError != "" {
+		t.Errorf("checkpoint = %+v, want it at 3 without an error", got)
+	}
+}
+
+func TestProjectorRebuildsFromWhereTheResetLeftIt(t *testing.T) {
+	tests := []struct {
+		name    string
+		resetTo int64
+		want    []string
+	}{
+		{"emptied", 0, []string{"a", "b", "c"}},
+		{"rebuilt from the state", 2, []string{"c"}},
+		{"rebuilt up to the head", 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newProjectorTest(10)
+			test.record(t, "a", "b", "c")
+			if _, err := test.projector.Run(context.Background(), time.Now()); err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+
+			test.projection.resetTo = tt.resetTo
+			projected, err := test.projector.Rebuild(context.Background(), "recording", time.Now())
+			if err != nil {
+				t.Fatalf("Rebuild() failed: %v", err)
+			}
+			if projected != len(tt.want) || test.projection.resets != 1 {
+				t.Errorf("Rebuild() projected %d events after %d resets, want %d after 1",
+					projected, test.projection.resets, len(tt.want))
+			}
+			test.assertProjected(t, tt.want...)
+			if got := test.checkpoint(t); got.Position != 3 {
+				t.Errorf("checkpoint = %+v, want it at 3", got)
+			}
+		})
+	}
+}
+
+func TestProjectorRebuildsOnlyRegisteredProjections(t *testing.T) {
+	test := newProjectorTest(10)
+
+	_, err := test.projector.Rebuild(context.Background(), "unknown", time.Now())
+	if !errors.Is(err, ddd.ErrProjectionNotFound) {
+		t.Errorf("Rebuild() error = %v, want %v", err, ddd.ErrProjectionNotFound)
+	}
+}
+
+func TestProjectorReadsTheLogInBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		// failing is the event the projection fails on, if any
+		failing        string
+		wantProjected  []string
+		wantCheckpoint int64
+	}{
+		{"one at a time", 1, "", []string{"a", "b", "c", "d", "e"}, 6},
+		{"batches ending on the last event", 3, "", []string{"a", "b", "c", "d", "e"}, 6},
+		{"a smaller last batch", 4, "", []string{"a", "b", "c", "d", "e"}, 6},
+		{"one batch", 10, "", []string{"a", "b", "c", "d", "e"}, 6},
+		{"failing first in a batch", 2, "c", []string{"a", "b"}, 2},
+		{"failing within a batch", 2, "d", []string{"a", "b", "c"}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newProjectorTest(tt.batchSize)
+			test.record(t, "a", "b", "c", "", "d", "e")
+			if tt.failing != "" {
+				test.projection.failing[tt.failing] = true
+			}
+
+			projected, err := test.projector.Run(context.Background(), time.Now())
+			if tt.failing == "" && err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if tt.failing != "" && err == nil {
+				t.Fatalf("Run() succeeded, want %s's failure", tt.failing)
+			}
+			if projected != len(tt.wantProjected) {
+				t.Errorf("Run() projected %d events, want %d", projected, len(tt.wantProjected))
+			}
+			test.assertProjected(t, tt.wantProjected...)
+			if got := test.checkpoint(t); got.Position != tt.wantCheckpoint {
+				t.Errorf("checkpoint = %+v, want it at %d", got, tt.wantCheckpoint)
+			}
+		})
+	}
+}
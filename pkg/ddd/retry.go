@@ -0,0 +1,60 @@
+package ddd
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how many times a failing event handler is tried, and how long
+// to wait between tries, before its event is dead-lettered
+type RetryPolicy struct {
+	// MaxAttempts is how many times the handler is tried, including the first
+	MaxAttempts int
+	// InitialDelay is the wait after the first failed attempt, doubled after each one
+	// that follows
+	InitialDelay time.Duration
+	// MaxDelay caps the wait between attempts
+	MaxDelay time.Duration
+	// Jitter is the fraction of each wait, from 0 to 1, that is randomly taken off it,
+	// so handlers failing together don't all retry at once
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     5 * time.Second,
+		Jitter:       0.2,
+	}
+}
+
+// Attempts returns how many times the handler is tried, which is at least once
+func (p RetryPolicy) Attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// Backoff returns how long to wait after the given failed attempt, counting from 1
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if p.InitialDelay <= 0 || attempt < 1 {
+		return 0
+	}
+
+	delay := p.InitialDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	jitter := min(max(p.Jitter, 0), 1)
+	return delay - time.Duration(jitter*rand.Float64()*float64(delay))
+}
@@ -0,0 +1,293 @@
+package ddd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnknownSubscription is returned when replaying a dead letter whose handler is no
+	// longer subscribed
+	ErrUnknownSubscription = errors.New("unknown subscription")
+	// ErrReplayFailed is returned when the handler fails again on a replayed dead letter
+	ErrReplayFailed = errors.New("replayed event failed")
+)
+
+// RetryPolicySubscriber is an EventDispatcher that retries failing handlers by the
+// policy they were subscribed with
+type RetryPolicySubscriber interface {
+	EventDispatcher
+	SubscribeWithPolicy(eventType, name string, policy RetryPolicy, handler ContextEventHandlerFunc)
+}
+
+// SubscribeWithPolicy subscribes the handler with its own retry policy if the
+// dispatcher retries handlers, and as usual if it doesn't
+func SubscribeWithPolicy(
+	dispatcher EventDispatcher,
+	eventType, name string,
+	policy RetryPolicy,
+	handler ContextEventHandlerFunc,
+) {
+	if subscriber, ok := dispatcher.(RetryPolicySubscriber); ok {
+		subscriber.SubscribeWithPolicy(eventType, name, policy, handler)
+		return
+	}
+	dispatcher.SubscribeContext(eventType, name, handler)
+}
+
+type subscription struct {
+	eventType   string
+	handlerName string
+	policy      RetryPolicy
+	handler     ContextEventHandlerFunc
+}
+
+// Subscription describes a handler subscribed to a RetryingEventDispatcher
+type Subscription struct {
+	// Name is the subscription's name, as its dead letters record it
+	Name      string
+	EventType string
+	// Handler is the name the handler was subscribed under
+	Handler string
+	Policy  RetryPolicy
+}
+
+// RetryingEventDispatcher wraps another dispatcher, retrying each handler that fails by
+// its subscription's policy. An event a handler still fails on is added to the dead
+// letter store for that handler alone, and isn't reported to the dispatcher as failed,
+// so the event's other handlers aren't run again for it. Retries wait in whatever
+// goroutine the wrapped dispatcher runs the handler in. If the context the event was
+// dispatched with ends, they stop early and the handler's error is returned rather than
+// the event dead-lettered, so it can be dispatched again
+//
+// Each subscription is named after its event type and the name its handler was
+// subscribed under, e.g. CommentCreated:events.CommentEventHandler.HandleCommentCreated,
+// so a dead letter is replayed to the same handler after a restart. Subscribing a
+// handler without a name, or under a name already subscribed to the event type, panics
+type RetryingEventDispatcher struct {
+	dispatcher    EventDispatcher
+	deadLetters   DeadLetterStore
+	policy        RetryPolicy
+	subscriptions map[string]subscription
+	mu            sync.RWMutex
+}
+
+// NewRetryingEventDispatcher wraps the dispatcher, retrying handlers subscribed without
+// a policy of their own by the given one
+func NewRetryingEventDispatcher(
+	dispatcher EventDispatcher,
+	deadLetters DeadLetterStore,
+	policy RetryPolicy,
+) *RetryingEventDispatcher {
+	return &RetryingEventDispatcher{
+		dispatcher:    dispatcher,
+		deadLetters:   deadLetters,
+		policy:        policy,
+		subscriptions: make(map[string]subscription),
+	}
+}
+
+// Subscribe registers a handler for a specific event type, retried by the default policy
+func (d *RetryingEventDispatcher) Subscribe(eventType, name string, handler EventHandlerFunc) {
+	d.subscribe(eventType, name, d.policy, handler.WithContext())
+}
+
+// SubscribeContext registers a handler for a specific event type, retried by the default
+// policy
+func (d *RetryingEventDispatcher) SubscribeContext(
+	eventType, name string,
+	handler ContextEventHandlerFunc,
+) {
+	d.subscribe(eventType, name, d.policy, handler)
+}
+
+// SubscribeWithPolicy registers a handler for a specific event type, retried by the
+// given policy
+func (d *RetryingEventDispatcher) SubscribeWithPolicy(
+	eventType, name string,
+	policy RetryPolicy,
+	handler ContextEventHandlerFunc,
+) {
+	d.subscribe(eventType, name, policy, handler)
+}
+
+// subscribe panics if the handler has no name, or the name is already subscribed to the
+// event type, as its dead letters could then be replayed to another handler
+func (d *RetryingEventDispatcher) subscribe(
+	eventType, name string,
+	policy RetryPolicy,
+	handler ContextEventHandlerFunc,
+) {
+	if name == "" {
+		panic(fmt.Sprintf("%s handler subscribed without a name", eventType))
+	}
+
+	key := eventType + ":" + name
+	d.mu.Lock()
+	if _, exists := d.subscriptions[key]; exists {
+		d.mu.Unlock()
+		panic(fmt.Sprintf("subscription %s already registered", key))
+	}
+	d.subscriptions[key] = subscription{
+		eventType:   eventType,
+		handlerName: name,
+		policy:      policy,
+		handler:     handler,
+	}
+	d.mu.Unlock()
+
+	d.dispatcher.SubscribeContext(eventType, name, d.retry(key, policy, handler))
+}
+
+// Subscriptions lists the subscribed handlers by event type, then by name
+func (d *RetryingEventDispatcher) Subscriptions() []Subscription {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	subscriptions := make([]Subscription, 0, len(d.subscriptions))
+	for name, subscription := range d.subscriptions {
+		subscriptions = append(subscriptions, Subscription{
+			Name:      name,
+			EventType: subscription.eventType,
+			Handler:   subscription.handlerName,
+			Policy:    subscription.policy,
+		})
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].EventType != subscriptions[j].EventType {
+			return subscriptions[i].EventType < subscriptions[j].EventType
+		}
+		return subscriptions[i].Name < subscriptions[j].Name
+	})
+	return subscriptions
+}
+
+// Dispatch sends the event to the wrapped dispatcher
+func (d *RetryingEventDispatcher) Dispatch(ctx context.Context, event DomainEvent) error {
+	return d.dispatcher.Dispatch(ctx, event)
+}
+
+// DispatchFor sends the event to the wrapped dispatcher, in order with the aggregate's
+// other events if it keeps them in order
+func (d *RetryingEventDispatcher) DispatchFor(
+	ctx context.Context,
+	aggregateID string,
+	event DomainEvent,
+) error {
+	if dispatcher, ok := d.dispatcher.(AggregateEventDispatcher); ok {
+		return dispatcher.DispatchFor(ctx, aggregateID, event)
+	}
+	return d.dispatcher.Dispatch(ctx, event)
+}
+
+// Replay runs the dead letter's event through its handler once more, with the metadata
+// it was first dispatched with, marking it replayed if the handler succeeds. A handler
+// that fails again leaves it pending, with the failure recorded, and ErrReplayFailed is
+// returned
+func (d *RetryingEventDispatcher) Replay(ctx context.Context, id int64, at time.Time) (DeadLetter, error) {
+	letter, err := d.deadLetters.FindByID(id)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+	if letter.Status != DeadLetterStatusPending {
+		return letter, ErrDeadLetterResolved
+	}
+
+	d.mu.RLock()
+	subscription, exists := d.subscriptions[letter.Subscription]
+	d.mu.RUnlock()
+	if !exists {
+		return letter, fmt.Errorf("%w: %s", ErrUnknownSubscription, letter.Subscription)
+	}
+
+	event, err := letter.Event()
+	if err != nil {
+		return letter, err
+	}
+
+	handleErr := handleSafely(WithEventMetadata(ctx, letter.Metadata), subscription.handler, event)
+	if handleErr != nil {
+		letter.RecordReplayFailure(handleErr, at)
+	} else if err := letter.MarkReplayed(at); err != nil {
+		return letter, err
+	}
+
+	if err := d.deadLetters.Update(letter); err != nil {
+		return letter, err
+	}
+	if handleErr != nil {
+		return letter, fmt.Errorf("%w: %v", ErrReplayFailed, handleErr)
+	}
+	return letter, nil
+}
+
+// retry wraps the handler to run it until it succeeds or runs out of attempts, and then
+// to dead-letter the event. An event whose context ended isn't dead-lettered, as the
+// handler wasn't given all its attempts, or may have failed because of it
+func (d *RetryingEventDispatcher) retry(
+	name string,
+	policy RetryPolicy,
+	handler ContextEventHandlerFunc,
+) ContextEventHandlerFunc {
+	return func(ctx context.Context, event DomainEvent) error {
+		var err error
+		attempts := 0
+		for attempts < policy.Attempts() {
+			attempts++
+			if err = handleSafely(ctx, handler, event); err == nil {
+				return nil
+			}
+			if attempts < policy.Attempts() && !wait(ctx, policy.Backoff(attempts)) {
+				break
+			}
+		}
+		if ctx.Err() != nil {
+			return errors.Join(err, ctx.Err())
+		}
+
+		letter, encodeErr := NewDeadLetter(
+			name,
+			event,
+			EventMetadataFrom(ctx),
+			attempts,
+			err,
+			time.Now(),
+		)
+		if encodeErr != nil {
+			return errors.Join(err, encodeErr)
+		}
+		if _, addErr := d.deadLetters.Add(letter); addErr != nil {
+			return errors.Join(err, addErr)
+		}
+		return nil
+	}
+}
+
+// handleSafely runs the handler, turning a panic into an error so it's retried like any
+// other failure
+func handleSafely(ctx context.Context, handler ContextEventHandlerFunc, event DomainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// wait sleeps for the delay, returning false if the context ends first
+func wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
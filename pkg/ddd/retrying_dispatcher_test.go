@@ -0,0 +1,278 @@
+package ddd_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+
+	"go.uber.org/zap"
+)
+
+// retryTestEvent is an event the retrying dispatcher tests dispatch
+type retryTestEvent struct {
+	Name string `json:"name"`
+}
+
+func (e retryTestEvent) OccurredOn() time.Time { return time.Time{} }
+func (e retryTestEvent) EventType() string     { return "RetryTest" }
+
+func init() {
+	ddd.EventRegistry.Register(retryTestEvent{}, "An event the retrying dispatcher tests dispatch")
+}
+
+// noDelay retries straight away
+var noDelay = ddd.RetryPolicy{MaxAttempts: 3}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ddd.RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"no delay", ddd.RetryPolicy{}, 3, 0},
+		{"before the first attempt", ddd.RetryPolicy{InitialDelay: time.Second}, 0, 0},
+		{"after the first attempt", ddd.RetryPolicy{InitialDelay: time.Second}, 1, time.Second},
+		{"doubled after each attempt", ddd.RetryPolicy{InitialDelay: time.Second}, 4, 8 * time.Second},
+		{
+			"capped",
+			ddd.RetryPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second},
+			4,
+			5 * time.Second,
+		},
+		{
+			"capped after many attempts",
+			ddd.RetryPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second},
+			100,
+			5 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Backoff(tt.attempt); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyJitterTakesOffUpToItsFraction(t *testing.T) {
+	tests := []struct {
+		name     string
+		jitter   float64
+		shortest time.Duration
+	}{
+		{"none", 0, time.Second},
+		{"a fifth", 0.2, 800 * time.Millisecond},
+		{"more than all of it", 1.5, 0},
+		{"negative", -1, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := ddd.RetryPolicy{InitialDelay: time.Second, Jitter: tt.jitter}
+			for range 1000 {
+				if got := policy.Backoff(1); got < tt.shortest || got > time.Second {
+					t.Fatalf("Backoff(1) = %v, want from %v to %v", got, tt.shortest, time.Second)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicyTriesAtLeastOnce(t *testing.T) {
+	for maxAttempts, want := range map[int]int{-1: 1, 0: 1, 1: 1, 5: 5} {
+		if got := (ddd.RetryPolicy{MaxAttempts: maxAttempts}).Attempts(); got != want {
+			t.Errorf("Attempts() with MaxAttempts %d = %d, want %d", maxAttempts, got, want)
+		}
+	}
+}
+
+// retryTest runs handlers in a retrying dispatcher wrapping an in-memory one
+type retryTest struct {
+	dispatcher  *ddd.RetryingEventDispatcher
+	deadLetters *dddmemory.InMemoryDeadLetterStore
+}
+
+func newRetryTest(policy ddd.RetryPolicy) *retryTest {
+	deadLetters := dddmemory.NewInMemoryDeadLetterStore()
+	return &retryTest{
+		dispatcher: ddd.NewRetryingEventDispatcher(
+			dddmemory.NewInMemoryEventDispatcher(zap.NewNop().Sugar()),
+			deadLetters,
+			policy,
+		),
+		deadLetters: deadLetters,
+	}
+}
+
+// failing subscribes a handler under the name that fails the given number of times, or
+// panics instead if panics is set, and returns how many times it has been run
+func (r *retryTest) failing(name string, failures int, panics bool) *int {
+	runs := 0
+	handler := func(ctx context.Context, event ddd.DomainEvent) error {
+		runs++
+		if runs > failures {
+			return nil
+		}
+		if panics {
+			panic(name + " broke")
+		}
+		return errors.New(name + " unavailable")
+	}
+	r.dispatcher.SubscribeContext("RetryTest", name, handler)
+	return &runs
+}
+
+func (r *retryTest) pending(t *testing.T) []ddd.DeadLetter {
+	t.Helper()
+
+	letters, err := r.deadLetters.List(ddd.DeadLetterStatusPending)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	return letters
+}
+
+func TestRetryingEventDispatcherRetriesAFailingHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		panics      bool
+		wantRuns    int
+		deadLetters int
+		lastError   string
+	}{
+		{"succeeds first time", 0, false, 1, 0, ""},
+		{"succeeds on the last attempt", 2, false, 3, 0, ""},
+		{"fails every attempt", 3, false, 3, 1, "fails unavailable"},
+		{"panics every attempt", 3, true, 3, 1, "handler panicked: fails broke"},
+		{"panics then succeeds", 1, true, 2, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newRetryTest(noDelay)
+			runs := test.failing("fails", tt.failures, tt.panics)
+			others := test.failing("works", 0, false)
+
+			// The event isn't reported as failed once it's dead-lettered
+			err := test.dispatcher.Dispatch(context.Background(), retryTestEvent{Name: "event"})
+			if err != nil {
+				t.Fatalf("Dispatch() failed: %v", err)
+			}
+			if *runs != tt.wantRuns {
+				t.Errorf("handler ran %d times, want %d", *runs, tt.wantRuns)
+			}
+			if *others != 1 {
+				t.Errorf("the other handler ran %d times, want once", *others)
+			}
+
+			letters := test.pending(t)
+			if len(letters) != tt.deadLetters {
+				t.Fatalf("%d dead letters, want %d", len(letters), tt.deadLetters)
+			}
+			for _, letter := range letters {
+				if letter.Subscription != "RetryTest:fails" || letter.Attempts != 3 ||
+					letter.LastError != tt.lastError {
+					t.Errorf("dead letter = %+v, want the failing handler's after 3 attempts", letter)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryingEventDispatcherLeavesAnEventWhoseContextEndedToBeDispatchedAgain(t *testing.T) {
+	test := newRetryTest(ddd.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Hour})
+	runs := test.failing("fails", 3, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	// The handler fails, and the context ends while waiting to retry it
+	err := test.dispatcher.Dispatch(ctx, retryTestEvent{Name: "event"})
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "fails unavailable") {
+		t.Errorf("Dispatch() error = %v, want the handler's error and %v", err, context.Canceled)
+	}
+	if *runs != 1 {
+		t.Errorf("handler ran %d times, want once", *runs)
+	}
+	if letters := test.pending(t); len(letters) != 0 {
+		t.Errorf("%d dead letters, want none", len(letters))
+	}
+}
+
+func TestRetryingEventDispatcherReplaysADeadLetterToItsHandler(t *testing.T) {
+	test := newRetryTest(ddd.RetryPolicy{MaxAttempts: 1})
+	runs := test.failing("fails", 2, false)
+	others := test.failing("works", 0, false)
+
+	ctx := ddd.WithEventMetadata(context.Background(), ddd.EventMetadata{CorrelationID: "request"})
+	if err := test.dispatcher.Dispatch(ctx, retryTestEvent{Name: "event"}); err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+	letters := test.pending(t)
+	if len(letters) != 1 || letters[0].Metadata.CorrelationID != "request" {
+		t.Fatalf("dead letters = %+v, want one with the event's metadata", letters)
+	}
+	id := letters[0].ID
+
+	// The handler fails again, leaving the dead letter pending
+	letter, err := test.dispatcher.Replay(context.Background(), id, time.Now())
+	if !errors.Is(err, ddd.ErrReplayFailed) {
+		t.Errorf("Replay() error = %v, want %v", err, ddd.ErrReplayFailed)
+	}
+	if letter.Status != ddd.DeadLetterStatusPending || letter.Attempts != 2 {
+		t.Errorf("dead letter = %+v, want it pending after 2 attempts", letter)
+	}
+
+	letter, err = test.dispatcher.Replay(context.Background(), id, time.Now())
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if letter.Status != ddd.DeadLetterStatusReplayed || letter.ResolvedAt == nil {
+		t.Errorf("dead letter = %+v, want it replayed", letter)
+	}
+	if *runs != 3 || *others != 1 {
+		t.Errorf("handlers ran %d and %d times, want only the failing one replayed", *runs, *others)
+	}
+
+	_, err = test.dispatcher.Replay(context.Background(), id, time.Now())
+	if !errors.Is(err, ddd.ErrDeadLetterResolved) {
+		t.Errorf("Replay() of a replayed dead letter error = %v, want %v", err, ddd.ErrDeadLetterResolved)
+	}
+}
+
+func TestRetryingEventDispatcherDoesntReplayToAnUnknownHandler(t *testing.T) {
+	deadLetters := dddmemory.NewInMemoryDeadLetterStore()
+	letter, err := ddd.NewDeadLetter(
+		"RetryTest:removed",
+		retryTestEvent{Name: "event"},
+		ddd.EventMetadata{},
+		3,
+		errors.New("removed unavailable"),
+		time.Now(),
+	)
+	if err != nil {
+		t.Fatalf("NewDeadLetter() failed: %v", err)
+	}
+	if letter, err = deadLetters.Add(letter); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	dispatcher := ddd.NewRetryingEventDispatcher(
+		dddmemory.NewInMemoryEventDispatcher(zap.NewNop().Sugar()),
+		deadLetters,
+		noDelay,
+	)
+	_, err = dispatcher.Replay(context.Background(), letter.ID, time.Now())
+	if !errors.Is(err, ddd.ErrUnknownSubscription) {
+		t.Errorf("Replay() error = %v, want %v", err, ddd.ErrUnknownSubscription)
+	}
+}
@@ -3,19 +3,54 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"blog/pkg/ddd"
 )
 
+// EventCoordinatorName names the coordinator's subscriptions to a dispatcher, as dead
+// letters record them
+const EventCoordinatorName = "services.EventCoordinator.Coordinate"
+
 // EventCoordinator coordinates complex event processing workflows
+//
+// Each event is handled with a context carrying its own metadata, caused by the metadata
+// Coordinate was called with, and the events a saga returns are caused by the event the
+// saga handled. Handlers and sagas can read it with ddd.EventMetadataFrom
 type EventCoordinator struct {
-	handlers      map[string][]ddd.EventHandlerFunc
+	handlers      map[string][]coordinatedHandler
 	sagas         map[string][]Saga
+	routers       []*EventRouter
 	errorHandlers map[string]ErrorHandler
 	mu            sync.RWMutex
 }
 
+// coordinatedHandler is a handler with the name it's listed under, see ddd.HandlerName
+type coordinatedHandler struct {
+	name    string
+	handler ddd.ContextEventHandlerFunc
+}
+
+// SubscriptionKind is what runs for an event in a coordinator
+type SubscriptionKind string
+
+const (
+	SubscriptionKindHandler SubscriptionKind = "handler"
+	SubscriptionKindRoute   SubscriptionKind = "route"
+	SubscriptionKindSaga    SubscriptionKind = "saga"
+)
+
+// Subscription describes a handler, route or saga that runs for a type of event
+type Subscription struct {
+	EventType string
+	Kind      SubscriptionKind
+	Name      string
+	// Condition describes when a route runs
+	Condition string
+}
+
 // Saga represents a long-running business process that can handle events
 type Saga interface {
 	Handle(ctx context.Context, event ddd.DomainEvent) ([]ddd.DomainEvent, error)
@@ -28,7 +63,7 @@ type ErrorHandler func(event ddd.DomainEvent, err error) error
 // NewEventCoordinator creates a new event coordinator
 func NewEventCoordinator() *EventCoordinator {
 	return &EventCoordinator{
-		handlers:      make(map[string][]ddd.EventHandlerFunc),
+		handlers:      make(map[string][]coordinatedHandler),
 		sagas:         make(map[string][]Saga),
 		errorHandlers: make(map[string]ErrorHandler),
 	}
@@ -36,9 +71,36 @@ func NewEventCoordinator() *EventCoordinator {
 
 // RegisterHandler registers an event handler for a specific event type
 func (ec *EventCoordinator) RegisterHandler(eventType string, handler ddd.EventHandlerFunc) {
+	ec.registerHandler(eventType, ddd.HandlerName(handler), handler.WithContext())
+}
+
+// RegisterContextHandler registers an event handler that takes the context for a specific
+// event type
+func (ec *EventCoordinator) RegisterContextHandler(
+	eventType string,
+	handler ddd.ContextEventHandlerFunc,
+) {
+	ec.registerHandler(eventType, ddd.HandlerName(handler), handler)
+}
+
+func (ec *EventCoordinator) registerHandler(
+	eventType, name string,
+	handler ddd.ContextEventHandlerFunc,
+) {
 	ec.mu.Lock()
 	defer ec.mu.Unlock()
-	ec.handlers[eventType] = append(ec.handlers[eventType], handler)
+	ec.handlers[eventType] = append(ec.handlers[eventType], coordinatedHandler{
+		name:    name,
+		handler: handler,
+	})
+}
+
+// RegisterRouter runs each event through the router's matching routes, after the
+// event's handlers and before its sagas
+func (ec *EventCoordinator) RegisterRouter(router *EventRouter) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.routers = append(ec.routers, router)
 }
 
 // RegisterSaga registers a saga for handling events
@@ -58,11 +120,102 @@ func (ec *EventCoordinator) RegisterErrorHandler(eventType string, handler Error
 	ec.errorHandlers[eventType] = handler
 }
 
+// Subscribe subscribes the coordinator to the dispatcher for each of its event types,
+// under EventCoordinatorName. Event types registered afterwards aren't subscribed
+//
+// A failing handler, route or saga fails the event, so a retrying dispatcher runs the
+// event's handlers, routes and sagas again. They should be safe to repeat
+func (ec *EventCoordinator) Subscribe(dispatcher ddd.EventDispatcher) {
+	for _, eventType := range ec.EventTypes() {
+		dispatcher.SubscribeContext(eventType, EventCoordinatorName, ec.handle)
+	}
+}
+
+// handle coordinates an event dispatched to the coordinator, whose context already
+// carries the event's metadata
+func (ec *EventCoordinator) handle(ctx context.Context, event ddd.DomainEvent) error {
+	return ec.coordinate([]coordinatedEvent{{ctx: ctx, event: event}})
+}
+
+// EventTypes returns the types of event the coordinator has handlers, routes or sagas
+// for
+func (ec *EventCoordinator) EventTypes() []string {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	seen := map[string]bool{}
+	for eventType := range ec.handlers {
+		seen[eventType] = true
+	}
+	for eventType := range ec.sagas {
+		seen[eventType] = true
+	}
+	for _, router := range ec.routers {
+		for _, eventType := range router.EventTypes() {
+			seen[eventType] = true
+		}
+	}
+
+	eventTypes := make([]string, 0, len(seen))
+	for eventType := range seen {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
+
+// Subscriptions lists the handlers, routes and sagas by event type, each type's in the
+// order they run
+func (ec *EventCoordinator) Subscriptions() []Subscription {
+	eventTypes := ec.EventTypes()
+
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	subscriptions := []Subscription{}
+	for _, eventType := range eventTypes {
+		for _, handler := range ec.handlers[eventType] {
+			subscriptions = append(subscriptions, Subscription{
+				EventType: eventType,
+				Kind:      SubscriptionKindHandler,
+				Name:      handler.name,
+			})
+		}
+		for _, router := range ec.routers {
+			subscriptions = append(subscriptions, router.subscriptionsFor(eventType)...)
+		}
+		for _, saga := range ec.sagas[eventType] {
+			subscriptions = append(subscriptions, Subscription{
+				EventType: eventType,
+				Kind:      SubscriptionKindSaga,
+				Name:      sagaName(saga),
+			})
+		}
+	}
+	return subscriptions
+}
+
+// sagaName names the saga after its type, e.g. services.SagaManager
+func sagaName(saga Saga) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", saga), "*")
+}
+
+// coordinatedEvent is an event with the context it's handled in
+type coordinatedEvent struct {
+	ctx   context.Context
+	event ddd.DomainEvent
+}
+
 // Coordinate processes events through handlers and sagas, handling errors appropriately
 func (ec *EventCoordinator) Coordinate(ctx context.Context, events ...ddd.DomainEvent) error {
-	var resultEvents []ddd.DomainEvent
+	return ec.coordinate(causedBy(ctx, events))
+}
 
-	for _, event := range events {
+func (ec *EventCoordinator) coordinate(events []coordinatedEvent) error {
+	var resultEvents []coordinatedEvent
+
+	for _, coordinated := range events {
+		ctx, event := coordinated.ctx, coordinated.event
 		if err := ec.handleEvent(ctx, event); err != nil {
 			if errHandler, exists := ec.errorHandlers[event.EventType()]; exists {
 				if handlerErr := errHandler(event, err); handlerErr != nil {
@@ -77,28 +230,48 @@ func (ec *EventCoordinator) Coordinate(ctx context.Context, events ...ddd.Domain
 		if err != nil {
 			return fmt.Errorf("saga handling failed: %w", err)
 		}
-		resultEvents = append(resultEvents, sagaEvents...)
+		resultEvents = append(resultEvents, causedBy(ctx, sagaEvents)...)
 	}
 
 	if len(resultEvents) > 0 {
-		return ec.Coordinate(ctx, resultEvents...)
+		return ec.coordinate(resultEvents)
 	}
 
 	return nil
 }
 
-// handleEvent processes an event through all registered handlers
+// causedBy gives each event a context carrying metadata caused by the context's
+func causedBy(ctx context.Context, events []ddd.DomainEvent) []coordinatedEvent {
+	cause := ddd.EventMetadataFrom(ctx)
+	coordinated := make([]coordinatedEvent, 0, len(events))
+	for _, event := range events {
+		coordinated = append(coordinated, coordinatedEvent{
+			ctx:   ddd.WithEventMetadata(ctx, cause.Caused()),
+			event: event,
+		})
+	}
+	return coordinated
+}
+
+// handleEvent processes an event through all registered handlers, then routers
 func (ec *EventCoordinator) handleEvent(ctx context.Context, event ddd.DomainEvent) error {
 	ec.mu.RLock()
 	handlers := ec.handlers[event.EventType()]
+	routers := ec.routers
 	ec.mu.RUnlock()
 
 	for _, handler := range handlers {
-		if err := handler(event); err != nil {
+		if err := handler.handler(ctx, event); err != nil {
 			return fmt.Errorf("handler failed for event %s: %w", event.EventType(), err)
 		}
 	}
 
+	for _, router := range routers {
+		if err := router.Route(ctx, event); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -228,9 +401,28 @@ type EventRouter struct {
 }
 
 // EventRoute represents a conditional route for an event
+//
+// Condition decides whether the route runs, or Match does when it needs the context or
+// can fail, e.g. to look something up. A failing match fails the event like a failing
+// handler. A route with neither always runs. Name, which defaults to the handler's, and
+// Description list the route in the router's subscriptions
 type EventRoute struct {
-	Condition func(event ddd.DomainEvent) bool
-	Handler   ddd.EventHandlerFunc
+	Name        string
+	Description string
+	Condition   func(event ddd.DomainEvent) bool
+	Match       func(ctx context.Context, event ddd.DomainEvent) (bool, error)
+	Handler     ddd.ContextEventHandlerFunc
+}
+
+// matches reports whether the route runs for the event
+func (r EventRoute) matches(ctx context.Context, event ddd.DomainEvent) (bool, error) {
+	if r.Match != nil {
+		return r.Match(ctx, event)
+	}
+	if r.Condition != nil {
+		return r.Condition(event), nil
+	}
+	return true, nil
 }
 
 // NewEventRouter creates a new event router
@@ -246,24 +438,88 @@ func (er *EventRouter) AddRoute(
 	condition func(ddd.DomainEvent) bool,
 	handler ddd.EventHandlerFunc,
 ) {
-	er.mu.Lock()
-	defer er.mu.Unlock()
+	er.AddEventRoute(eventType, EventRoute{
+		Name:      ddd.HandlerName(handler),
+		Condition: condition,
+		Handler:   handler.WithContext(),
+	})
+}
 
-	er.routes[eventType] = append(er.routes[eventType], EventRoute{
+// AddContextRoute adds a conditional route for an event type to a handler that takes the
+// context
+func (er *EventRouter) AddContextRoute(
+	eventType string,
+	condition func(ddd.DomainEvent) bool,
+	handler ddd.ContextEventHandlerFunc,
+) {
+	er.AddEventRoute(eventType, EventRoute{
 		Condition: condition,
 		Handler:   handler,
 	})
 }
 
+// AddEventRoute adds a route for an event type
+func (er *EventRouter) AddEventRoute(eventType string, route EventRoute) {
+	if route.Name == "" {
+		route.Name = ddd.HandlerName(route.Handler)
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	er.routes[eventType] = append(er.routes[eventType], route)
+}
+
+// EventTypes returns the types of event the router has routes for
+func (er *EventRouter) EventTypes() []string {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+
+	eventTypes := make([]string, 0, len(er.routes))
+	for eventType := range er.routes {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
+
+// Subscriptions lists the routes by event type, each type's in the order they run
+func (er *EventRouter) Subscriptions() []Subscription {
+	subscriptions := []Subscription{}
+	for _, eventType := range er.EventTypes() {
+		subscriptions = append(subscriptions, er.subscriptionsFor(eventType)...)
+	}
+	return subscriptions
+}
+
+func (er *EventRouter) subscriptionsFor(eventType string) []Subscription {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+
+	subscriptions := []Subscription{}
+	for _, route := range er.routes[eventType] {
+		subscriptions = append(subscriptions, Subscription{
+			EventType: eventType,
+			Kind:      SubscriptionKindRoute,
+			Name:      route.Name,
+			Condition: route.Description,
+		})
+	}
+	return subscriptions
+}
+
 // Route processes an event through all matching routes
-func (er *EventRouter) Route(event ddd.DomainEvent) error {
+func (er *EventRouter) Route(ctx context.Context, event ddd.DomainEvent) error {
 	er.mu.RLock()
 	routes := er.routes[event.EventType()]
 	er.mu.RUnlock()
 
 	for _, route := range routes {
-		if route.Condition(event) {
-			if err := route.Handler(event); err != nil {
+		matches, err := route.matches(ctx, event)
+		if err != nil {
+			return fmt.Errorf("route %s condition failed: %w", route.Name, err)
+		}
+		if matches {
+			if err := route.Handler(ctx, event); err != nil {
 				return fmt.Errorf("route handler failed: %w", err)
 			}
 		}
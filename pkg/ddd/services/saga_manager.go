@@ -0,0 +1,409 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"blog/pkg/ddd"
+
+	"github.com/google/uuid"
+)
+
+// SagaTimedOutEventType is the type of the event fired for a saga that is still running
+// when its timeout passes
+const SagaTimedOutEventType = "SagaTimedOut"
+
+// SagaManagerName names the saga manager's subscriptions to a dispatcher, as dead
+// letters record them
+const SagaManagerName = "services.SagaManager.HandleEvent"
+
+// SagaTimedOutEvent is fired for a saga that is still running when its timeout passes.
+// The saga's manager compensates it
+type SagaTimedOutEvent struct {
+	ddd.EventBase
+
+	SagaID         string
+	SagaType       string
+	CorrelationKey string
+	TimeoutAt      time.Time
+}
+
+func NewSagaTimedOutEvent(state SagaState, at time.Time) *SagaTimedOutEvent {
+	return &SagaTimedOutEvent{
+		EventBase:      ddd.NewEventBase(at),
+		SagaID:         state.ID,
+		SagaType:       state.SagaType,
+		CorrelationKey: state.CorrelationKey,
+		TimeoutAt:      state.TimeoutAt,
+	}
+}
+
+func (e SagaTimedOutEvent) EventType() string { return SagaTimedOutEventType }
+
+func init() {
+	ddd.EventRegistry.Register(
+		SagaTimedOutEvent{},
+		"Raised when a saga is still running when its timeout passes",
+	)
+}
+
+// SagaStep is one step of a persistent saga. Its changes to the state's Data are saved
+// when it succeeds
+//
+// A step can be run again after it has made its changes, when the process stops before
+// they are saved, so steps should be safe to repeat
+type SagaStep struct {
+	Name    string
+	Execute func(ctx context.Context, state *SagaState) error
+	// Compensate undoes the step once it has completed, and may be nil if there is
+	// nothing to undo
+	Compensate func(ctx context.Context, state *SagaState) error
+}
+
+// SagaDefinition describes a persistent saga, a process run step by step in response to
+// an event, whose progress is kept in a SagaStore
+type SagaDefinition struct {
+	Type string
+	// StartedBy is the type of event that starts the saga
+	StartedBy string
+	// Correlate returns the key the saga is run for, and the data it starts with. An
+	// empty key ignores the event
+	Correlate func(event ddd.DomainEvent) (string, map[string]string)
+	Steps     []SagaStep
+	// MaxAttempts is how many times a failing step is tried before the saga gives up and
+	// compensates, counting the attempts made on resuming it
+	MaxAttempts int
+	// Timeout is how long the saga has to complete before it is compensated, or no
+	// limit if it is zero
+	Timeout time.Duration
+}
+
+// SagaManager runs persistent sagas, keeping their progress in a SagaStore. Each saga
+// runs its steps in order and saves its state after each one, so a saga interrupted by
+// a restart is carried on by Resume rather than starting again. A failing step fails
+// the event, to be retried by the dispatcher, until it has used up its attempts, and
+// then the saga undoes the steps it completed in reverse order
+//
+// Sagas still running when their timeout passes are found by FireTimeouts, which
+// dispatches a SagaTimedOutEvent for each so the manager compensates it. The manager
+// is a Saga itself, so it can be run by an EventCoordinator rather than subscribed to
+// a dispatcher
+type SagaManager struct {
+	store       SagaStore
+	dispatcher  ddd.EventDispatcher
+	definitions map[string]SagaDefinition
+	startedBy   map[string][]string
+	mu          sync.RWMutex
+}
+
+// NewSagaManager creates a saga manager keeping sagas in the store, and dispatching
+// their timeouts with the dispatcher
+func NewSagaManager(store SagaStore, dispatcher ddd.EventDispatcher) *SagaManager {
+	return &SagaManager{
+		store:       store,
+		dispatcher:  dispatcher,
+		definitions: make(map[string]SagaDefinition),
+		startedBy:   make(map[string][]string),
+	}
+}
+
+// Register adds a saga for the manager to run. It panics if a saga of the type is
+// already registered
+func (m *SagaManager) Register(definition SagaDefinition) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.definitions[definition.Type]; exists {
+		panic(fmt.Sprintf("saga %s already registered", definition.Type))
+	}
+	m.definitions[definition.Type] = definition
+	m.startedBy[definition.StartedBy] = append(m.startedBy[definition.StartedBy], definition.Type)
+}
+
+// Subscribe subscribes the manager to the events that start its sagas, and to their
+// timeouts, under SagaManagerName
+func (m *SagaManager) Subscribe(dispatcher ddd.EventDispatcher) {
+	for _, eventType := range m.GetHandledEventTypes() {
+		dispatcher.SubscribeContext(eventType, SagaManagerName, m.HandleEvent)
+	}
+}
+
+// GetHandledEventTypes implements Saga, returning the types of event that start the
+// registered sagas, and SagaTimedOutEventType
+func (m *SagaManager) GetHandledEventTypes() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	eventTypes := []string{}
+	for eventType := range m.startedBy {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return append(eventTypes, SagaTimedOutEventType)
+}
+
+// Handle implements Saga. The sagas raise no events of their own
+func (m *SagaManager) Handle(ctx context.Context, event ddd.DomainEvent) ([]ddd.DomainEvent, error) {
+	return nil, m.HandleEvent(ctx, event)
+}
+
+// HandleEvent starts the sagas the event starts, carrying on any that are already
+// running for the same key, or compensates the saga that timed out
+func (m *SagaManager) HandleEvent(ctx context.Context, event ddd.DomainEvent) error {
+	if timedOut, ok := event.(*SagaTimedOutEvent); ok {
+		return m.handleTimeout(ctx, timedOut)
+	}
+
+	m.mu.RLock()
+	sagaTypes := m.startedBy[event.EventType()]
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, sagaType := range sagaTypes {
+		definition, _ := m.lookup(sagaType)
+		if err := m.start(ctx, definition, event); err != nil {
+			errs = append(errs, fmt.Errorf("saga %s failed: %w", sagaType, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *SagaManager) start(ctx context.Context, definition SagaDefinition, event ddd.DomainEvent) error {
+	key, data := definition.Correlate(event)
+	if key == "" {
+		return nil
+	}
+
+	state, err := m.store.FindByCorrelation(definition.Type, key)
+	if errors.Is(err, ErrSagaNotFound) {
+		now := time.Now()
+		state = SagaState{
+			ID:               uuid.NewString(),
+			SagaType:         definition.Type,
+			CorrelationKey:   key,
+			Status:           SagaStatusRunning,
+			CompletedSteps:   []string{},
+			CompensatedSteps: []string{},
+			Data:             data,
+			Metadata:         ddd.EventMetadataFrom(ctx),
+			StartedAt:        now,
+			UpdatedAt:        now,
+		}
+		if state.Data == nil {
+			state.Data = map[string]string{}
+		}
+		if definition.Timeout > 0 {
+			state.TimeoutAt = now.Add(definition.Timeout)
+		}
+		if err := m.store.Create(state); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	// A saga that has already finished for the key is done with the event, which must
+	// have been delivered again
+	if state.Status.Finished() {
+		return nil
+	}
+	return m.run(ctx, definition, state)
+}
+
+func (m *SagaManager) handleTimeout(ctx context.Context, event *SagaTimedOutEvent) error {
+	definition, registered := m.lookup(event.SagaType)
+	if !registered {
+		return nil
+	}
+
+	state, err := m.store.FindByID(event.SagaID)
+	if err != nil {
+		return err
+	}
+
+	// The saga may have finished, or started compensating, since it was found
+	if state.Status != SagaStatusRunning {
+		return nil
+	}
+
+	state.Status = SagaStatusCompensating
+	state.LastError = fmt.Sprintf("timed out at %s", event.TimeoutAt.UTC().Format(time.RFC3339))
+	if state, err = m.save(state); err != nil {
+		return err
+	}
+	return m.run(ctx, definition, state)
+}
+
+// Resume carries on the sagas that were interrupted, e.g. by a restart, in the context
+// of the event that started each. Only sagas that haven't been saved since idleSince are
+// carried on, so a saga still being run by the handler of the event that started it is
+// left to it. Each saga is claimed by saving it before its steps run, and one saved by
+// someone else in the meantime is skipped. Sagas of unregistered types are left alone.
+// It returns how many sagas it carried on, and the errors of those that failed again
+func (m *SagaManager) Resume(ctx context.Context, idleSince time.Time) (int, error) {
+	states, err := m.store.FindUnfinished(idleSince)
+	if err != nil {
+		return 0, err
+	}
+
+	resumed := 0
+	var errs []error
+	for _, state := range states {
+		definition, registered := m.lookup(state.SagaType)
+		if !registered {
+			continue
+		}
+
+		claimed, err := m.save(state)
+		if errors.Is(err, ErrSagaConflict) {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("saga %s %s failed: %w", state.SagaType, state.ID, err))
+			continue
+		}
+
+		resumed++
+		sagaCtx := ddd.WithEventMetadata(ctx, state.Metadata)
+		if err := m.run(sagaCtx, definition, claimed); err != nil {
+			errs = append(errs, fmt.Errorf("saga %s %s failed: %w", state.SagaType, state.ID, err))
+		}
+	}
+	return resumed, errors.Join(errs...)
+}
+
+// FireTimeouts dispatches a SagaTimedOutEvent for each saga that is still running at the
+// time, caused by the event that started the saga. A saga whose timeout has not been
+// handled yet, e.g. as the dispatcher queues events, is fired again. It returns how many
+// timeouts it fired
+func (m *SagaManager) FireTimeouts(ctx context.Context, at time.Time) (int, error) {
+	states, err := m.store.FindTimedOut(at)
+	if err != nil {
+		return 0, err
+	}
+
+	fired := 0
+	for _, state := range states {
+		if _, registered := m.lookup(state.SagaType); !registered {
+			continue
+		}
+
+		eventCtx := ddd.WithEventMetadata(ctx, state.Metadata.Caused())
+		if err := m.dispatcher.Dispatch(eventCtx, NewSagaTimedOutEvent(state, at)); err != nil {
+			return fired, err
+		}
+		fired++
+	}
+	return fired, nil
+}
+
+// run carries the saga on from wherever it got to
+func (m *SagaManager) run(ctx context.Context, definition SagaDefinition, state SagaState) error {
+	var err error
+	if state.Status == SagaStatusRunning {
+		if state, err = m.runSteps(ctx, definition, state); err != nil {
+			return err
+		}
+	}
+	if state.Status == SagaStatusCompensating {
+		if _, err = m.compensate(ctx, definition, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSteps runs the steps that haven't completed. A failing step is saved with the
+// error, and returned unless it has used up its attempts, in which case the saga starts
+// compensating
+func (m *SagaManager) runSteps(
+	ctx context.Context,
+	definition SagaDefinition,
+	state SagaState,
+) (SagaState, error) {
+	var err error
+	for _, step := range definition.Steps {
+		if state.Completed(step.Name) {
+			continue
+		}
+
+		if stepErr := step.Execute(ctx, &state); stepErr != nil {
+			state.Attempts++
+			state.LastError = fmt.Sprintf("step %s failed: %v", step.Name, stepErr)
+			if state.Attempts < max(definition.MaxAttempts, 1) {
+				if _, err := m.save(state); err != nil {
+					return state, err
+				}
+				return state, fmt.Errorf("step %s failed: %w", step.Name, stepErr)
+			}
+
+			state.Status = SagaStatusCompensating
+			return m.save(state)
+		}
+
+		state.CompletedSteps = append(state.CompletedSteps, step.Name)
+		state.Attempts = 0
+		state.LastError = ""
+		if state, err = m.save(state); err != nil {
+			return state, err
+		}
+	}
+
+	state.Status = SagaStatusCompleted
+	return m.save(state)
+}
+
+// compensate undoes the completed steps in reverse order, stopping at the first that
+// fails so it can be tried again
+func (m *SagaManager) compensate(
+	ctx context.Context,
+	definition SagaDefinition,
+	state SagaState,
+) (SagaState, error) {
+	steps := map[string]SagaStep{}
+	for _, step := range definition.Steps {
+		steps[step.Name] = step
+	}
+
+	var err error
+	for i := len(state.CompletedSteps) - 1; i >= 0; i-- {
+		step := steps[state.CompletedSteps[i]]
+		if state.Compensated(step.Name) {
+			continue
+		}
+
+		if step.Compensate != nil {
+			if compensateErr := step.Compensate(ctx, &state); compensateErr != nil {
+				state.LastError = fmt.Sprintf("compensation for step %s failed: %v", step.Name, compensateErr)
+				if _, err := m.save(state); err != nil {
+					return state, err
+				}
+				return state, fmt.Errorf("compensation for step %s failed: %w", step.Name, compensateErr)
+			}
+		}
+
+		state.CompensatedSteps = append(state.CompensatedSteps, step.Name)
+		if state, err = m.save(state); err != nil {
+			return state, err
+		}
+	}
+
+	state.Status = SagaStatusCompensated
+	return m.save(state)
+}
+
+func (m *SagaManager) save(state SagaState) (SagaState, error) {
+	state.UpdatedAt = time.Now()
+	return m.store.Update(state)
+}
+
+func (m *SagaManager) lookup(sagaType string) (SagaDefinition, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	definition, exists := m.definitions[sagaType]
+	return definition, exists
+}
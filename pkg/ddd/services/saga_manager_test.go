@@ -0,0 +1,254 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/pkg/ddd"
+	dddmemory "blog/pkg/ddd/memory"
+	"blog/pkg/ddd/services"
+
+	"go.uber.org/zap"
+)
+
+// sagaTestEvent starts the test saga for its order
+type sagaTestEvent struct {
+	OrderID string
+}
+
+func (e sagaTestEvent) OccurredOn() time.Time { return time.Time{} }
+func (e sagaTestEvent) EventType() string     { return "SagaTest" }
+
+// sagaTest runs a saga of three steps, reserve, charge and ship, in a manager subscribed
+// to the dispatcher, recording each step run and each one undone
+type sagaTest struct {
+	manager    *services.SagaManager
+	store      services.SagaStore
+	dispatcher ddd.EventDispatcher
+
+	// failures is how many more times each step fails before it succeeds
+	failures map[string]int
+	ran      []string
+}
+
+func newSagaTest(t *testing.T, store services.SagaStore, maxAttempts int, timeout time.Duration) *sagaTest {
+	t.Helper()
+
+	test := &sagaTest{
+		store:      store,
+		dispatcher: dddmemory.NewInMemoryEventDispatcher(zap.NewNop().Sugar()),
+		failures:   map[string]int{},
+	}
+
+	var steps []services.SagaStep
+	for _, name := range []string{"reserve", "charge", "ship"} {
+		steps = append(steps, services.SagaStep{
+			Name: name,
+			Execute: func(ctx context.Context, state *services.SagaState) error {
+				if test.failures[name] > 0 {
+					test.failures[name]--
+					return errors.New(name + " unavailable")
+				}
+				test.ran = append(test.ran, name)
+				return nil
+			},
+			Compensate: func(ctx context.Context, state *services.SagaState) error {
+				test.ran = append(test.ran, "undo "+name)
+				return nil
+			},
+		})
+	}
+
+	test.manager = services.NewSagaManager(store, test.dispatcher)
+	test.manager.Register(services.SagaDefinition{
+		Type:      "order",
+		StartedBy: "SagaTest",
+		Correlate: func(event ddd.DomainEvent) (string, map[string]string) {
+			orderID := event.(sagaTestEvent).OrderID
+			return orderID, map[string]string{"order_id": orderID}
+		},
+		Steps:       steps,
+		MaxAttempts: maxAttempts,
+		Timeout:     timeout,
+	})
+	test.manager.Subscribe(test.dispatcher)
+	return test
+}
+
+func (s *sagaTest) start() error {
+	return s.dispatcher.Dispatch(context.Background(), sagaTestEvent{OrderID: "order"})
+}
+
+func (s *sagaTest) saga(t *testing.T) services.SagaState {
+	t.Helper()
+
+	state, err := s.store.FindByCorrelation("order", "order")
+	if err != nil {
+		t.Fatalf("FindByCorrelation() failed: %v", err)
+	}
+	return state
+}
+
+func (s *sagaTest) assertRan(t *testing.T, want ...string) {
+	t.Helper()
+
+	if !slices.Equal(s.ran, want) {
+		t.Errorf("ran %v, want %v", s.ran, want)
+	}
+}
+
+func TestSagaManagerRunsTheStepsOfTheSagaAnEventStarts(t *testing.T) {
+	test := newSagaTest(t, dddmemory.NewInMemorySagaStore(), 3, 0)
+
+	if err := test.start(); err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+	test.assertRan(t, "reserve", "charge", "ship")
+
+	saga := test.saga(t)
+	if saga.Status != services.SagaStatusCompleted {
+		t.Errorf("saga status = %s, want %s", saga.Status, services.SagaStatusCompleted)
+	}
+	if saga.Data["order_id"] != "order" {
+		t.Errorf("saga data = %v, want the order it was started for", saga.Data)
+	}
+
+	// The event delivered again finds the saga finished
+	if err := test.start(); err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+	test.assertRan(t, "reserve", "charge", "ship")
+}
+
+func TestSagaManagerCompensatesInReverseOrderOnceAStepUsesUpItsAttempts(t *testing.T) {
+	test := newSagaTest(t, dddmemory.NewInMemorySagaStore(), 2, 0)
+	test.failures["ship"] = 2
+
+	// The first failure fails the event so it's retried
+	if err := test.start(); err == nil {
+		t.Fatal("Dispatch() succeeded, want the failed step's error")
+	}
+	saga := test.saga(t)
+	if saga.Status != services.SagaStatusRunning || saga.Attempts != 1 ||
+		!strings.Contains(saga.LastError, "ship unavailable") {
+		t.Errorf("saga = %+v, want it running after one failed attempt at ship", saga)
+	}
+
+	// The second uses up its attempts, and the completed steps are undone
+	if err := test.start(); err != nil {
+		t.Fatalf("Dispatch() failed: %v", err)
+	}
+	test.assertRan(t, "reserve", "charge", "undo charge", "undo reserve")
+
+	saga = test.saga(t)
+	if saga.Status != services.SagaStatusCompensated {
+		t.Errorf("saga status = %s, want %s", saga.Status, services.SagaStatusCompensated)
+	}
+	if want := []string{"charge", "reserve"}; !slices.Equal(saga.CompensatedSteps, want) {
+		t.Errorf("compensated steps = %v, want %v", saga.CompensatedSteps, want)
+	}
+}
+
+func TestSagaManagerCompensatesASagaThatTimesOut(t *testing.T) {
+	test := newSagaTest(t, dddmemory.NewInMemorySagaStore(), 10, time.Minute)
+	test.failures["charge"] = 1
+
+	if err := test.start(); err == nil {
+		t.Fatal("Dispatch() succeeded, want the failed step's error")
+	}
+
+	// Nothing has timed out yet
+	fired, err := test.manager.FireTimeouts(context.Background(), time.Now())
+	if err != nil || fired != 0 {
+		t.Fatalf("FireTimeouts() = %d, %v, want no timeouts", fired, err)
+	}
+
+	fired, err = test.manager.FireTimeouts(context.Background(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("FireTimeouts() failed: %v", err)
+	}
+	if fired != 1 {
+		t.Errorf("FireTimeouts() fired %d timeouts, want 1", fired)
+	}
+	test.assertRan(t, "reserve", "undo reserve")
+
+	saga := test.saga(t)
+	if saga.Status != services.SagaStatusCompensated || !strings.Contains(saga.LastError, "timed out") {
+		t.Errorf("saga = %+v, want it compensated after timing out", saga)
+	}
+
+	// A compensated saga isn't timed out again
+	fired, err = test.manager.FireTimeouts(context.Background(), time.Now().Add(time.Hour))
+	if err != nil || fired != 0 {
+		t.Errorf("FireTimeouts() = %d, %v, want no timeouts", fired, err)
+	}
+}
+
+func TestSagaManagerResumesEachInterruptedSagaOnce(t *testing.T) {
+	test := newSagaTest(t, dddmemory.NewInMemorySagaStore(), 3, 0)
+	test.failures["charge"] = 1
+
+	if err := test.start(); err == nil {
+		t.Fatal("Dispatch() succeeded, want the failed step's error")
+	}
+
+	// A saga saved since the given time is left to the handler running it
+	resumed, err := test.manager.Resume(context.Background(), time.Now().Add(-time.Hour))
+	if err != nil || resumed != 0 {
+		t.Fatalf("Resume() = %d, %v, want no sagas carried on", resumed, err)
+	}
+	test.assertRan(t, "reserve")
+
+	resumed, err = test.manager.Resume(context.Background(), time.Now())
+	if err != nil || resumed != 1 {
+		t.Fatalf("Resume() = %d, %v, want 1 saga carried on", resumed, err)
+	}
+	test.assertRan(t, "reserve", "charge", "ship")
+
+	resumed, err = test.manager.Resume(context.Background(), time.Now())
+	if err != nil || resumed != 0 {
+		t.Errorf("Resume() = %d, %v, want no sagas carried on", resumed, err)
+	}
+	test.assertRan(t, "reserve", "charge", "ship")
+}
+
+// racingSagaStore saves each unfinished saga it finds, as if the handler running it
+// saved it just after it was found
+type racingSagaStore struct {
+	*dddmemory.InMemorySagaStore
+}
+
+func (s racingSagaStore) FindUnfinished(idleSince time.Time) ([]services.SagaState, error) {
+	states, err := s.InMemorySagaStore.FindUnfinished(idleSince)
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		if _, err := s.Update(state); err != nil {
+			return nil, err
+		}
+	}
+	return states, nil
+}
+
+func TestSagaManagerLeavesASagaSavedSinceItWasFoundToWhoeverSavedIt(t *testing.T) {
+	test := newSagaTest(t, racingSagaStore{dddmemory.NewInMemorySagaStore()}, 3, 0)
+	test.failures["charge"] = 1
+
+	if err := test.start(); err == nil {
+		t.Fatal("Dispatch() succeeded, want the failed step's error")
+	}
+
+	resumed, err := test.manager.Resume(context.Background(), time.Now())
+	if err != nil || resumed != 0 {
+		t.Fatalf("Resume() = %d, %v, want no sagas carried on", resumed, err)
+	}
+	test.assertRan(t, "reserve")
+	if saga := test.saga(t); saga.Status != services.SagaStatusRunning {
+		t.Errorf("saga status = %s, want %s", saga.Status, services.SagaStatusRunning)
+	}
+}
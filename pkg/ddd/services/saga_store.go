@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"blog/pkg/ddd"
+)
+
+var (
+	// ErrSagaNotFound is returned when there is no saga with the ID or correlation key
+	ErrSagaNotFound = errors.New("saga not found")
+	// ErrSagaExists is returned when starting a saga of a type that is already running
+	// for the correlation key
+	ErrSagaExists = errors.New("saga already exists")
+	// ErrSagaConflict is returned when saving a saga that was saved by someone else
+	// since it was loaded
+	ErrSagaConflict = errors.New("saga was changed concurrently")
+)
+
+// SagaStatus is how far a saga has got
+type SagaStatus string
+
+const (
+	// SagaStatusRunning sagas are running their steps
+	SagaStatusRunning SagaStatus = "running"
+	// SagaStatusCompensating sagas gave up on a step or timed out, and are undoing the
+	// steps they completed
+	SagaStatusCompensating SagaStatus = "compensating"
+	SagaStatusCompleted    SagaStatus = "completed"
+	SagaStatusCompensated  SagaStatus = "compensated"
+)
+
+func (s SagaStatus) String() string {
+	return string(s)
+}
+
+// Finished reports whether the saga has nothing left to do
+func (s SagaStatus) Finished() bool {
+	return s == SagaStatusCompleted || s == SagaStatusCompensated
+}
+
+// SagaState is the progress of one run of a saga, kept in a SagaStore so the saga can
+// carry on where it left off after a restart
+type SagaState struct {
+	ID       string
+	SagaType string
+	// CorrelationKey tells runs of the same saga apart, e.g. the ID of the post being
+	// archived. Only one run of a saga type is kept per key
+	CorrelationKey string
+	Status         SagaStatus
+	// CompletedSteps are the names of the steps that succeeded, in order
+	CompletedSteps []string
+	// CompensatedSteps are the names of the completed steps that have been undone, in
+	// the order they were undone
+	CompensatedSteps []string
+	// Data is what the steps need to remember, e.g. what a step changed so it can be
+	// compensated
+	Data map[string]string
+	// Metadata is the metadata of the event that started the saga
+	Metadata ddd.EventMetadata
+	// Attempts counts the failed attempts at the current step
+	Attempts  int
+	LastError string
+	// TimeoutAt is when the saga is compensated if it still hasn't completed
+	TimeoutAt time.Time
+	StartedAt time.Time
+	UpdatedAt time.Time
+	// Version counts the saves, so concurrent saves are caught
+	Version int
+}
+
+// Completed reports whether the step has completed
+func (s SagaState) Completed(step string) bool {
+	return slices.Contains(s.CompletedSteps, step)
+}
+
+// Compensated reports whether the step has been undone
+func (s SagaState) Compensated(step string) bool {
+	return slices.Contains(s.CompensatedSteps, step)
+}
+
+// SagaStore keeps the progress of sagas
+type SagaStore interface {
+	// Create stores a new saga, or returns ErrSagaExists if one of its type is already
+	// stored for the correlation key
+	Create(state SagaState) error
+	// FindByID returns the saga, or ErrSagaNotFound
+	FindByID(id string) (SagaState, error)
+	// FindByCorrelation returns the saga of the type for the key, or ErrSagaNotFound
+	FindByCorrelation(sagaType, correlationKey string) (SagaState, error)
+	// Update saves the saga's progress and bumps its version, or returns
+	// ErrSagaConflict if its version isn't the stored one
+	Update(state SagaState) (SagaState, error)
+	// FindUnfinished returns the sagas that are running or compensating and haven't been
+	// saved since the given time, oldest first
+	FindUnfinished(idleSince time.Time) ([]SagaState, error)
+	// FindTimedOut returns the running sagas whose timeout is at or before the time,
+	// oldest first
+	FindTimedOut(at time.Time) ([]SagaState, error)
+}
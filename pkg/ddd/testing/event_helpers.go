@@ -305,7 +305,8 @@ func (ath *AggregateTestHelper) AssertNoEvents() {
 
 // MockEvent is a simple implementation of DomainEvent for testing
 type MockEvent struct {
-	occurredOn  time.Time
+	ddd.EventBase
+
 	Type        string
 	AggregateId string
 	Data        map[string]interface{}
@@ -314,18 +315,13 @@ type MockEvent struct {
 // NewMockEvent creates a new mock event
 func NewMockEvent(eventType, aggregateID string, data map[string]interface{}) *MockEvent {
 	return &MockEvent{
-		occurredOn:  time.Now(),
+		EventBase:   ddd.NewEventBase(time.Now()),
 		Type:        eventType,
 		AggregateId: aggregateID,
 		Data:        data,
 	}
 }
 
-// OccurredOn returns when the event occurred
-func (e MockEvent) OccurredOn() time.Time {
-	return e.occurredOn
-}
-
 // EventType returns the event type
 func (e MockEvent) EventType() string {
 	return e.Type
@@ -0,0 +1,102 @@
+package testing
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"blog/pkg/ddd"
+)
+
+// RunEventStoreTests checks that the stores made by newStore keep each aggregate's
+// stream as an EventStore must. The events are appended to streams of their own, and
+// must be registered for stores that encode them. Loaded events are compared with those
+// appended by type and JSON encoding, as a store may decode them as pointers
+func RunEventStoreTests(
+	t *testing.T,
+	newStore func(t *testing.T) ddd.EventStore,
+	events ...ddd.DomainEvent,
+) {
+	t.Helper()
+
+	if len(events) < 2 {
+		t.Fatalf("RunEventStoreTests() needs at least 2 events, got %d", len(events))
+	}
+
+	t.Run("appended events load in order", func(t *testing.T) {
+		store := newStore(t)
+
+		appendEvents(t, store, "first", 0, events[:1])
+		appendEvents(t, store, "first", 1, events[1:])
+		appendEvents(t, store, "second", 0, events[:1])
+
+		assertStream(t, store, "first", events)
+		assertStream(t, store, "second", events[:1])
+	})
+
+	t.Run("a stale expected version conflicts", func(t *testing.T) {
+		store := newStore(t)
+
+		appendEvents(t, store, "stream", 0, events[:1])
+		for _, expectedVersion := range []int{0, 2} {
+			err := store.Append("stream", expectedVersion, events[1:])
+			if !errors.Is(err, ddd.ErrConcurrencyConflict) {
+				t.Errorf("Append() at version %d error = %v, want %v",
+					expectedVersion, err, ddd.ErrConcurrencyConflict)
+			}
+		}
+
+		assertStream(t, store, "stream", events[:1])
+	})
+
+	t.Run("an unknown stream has no events", func(t *testing.T) {
+		store := newStore(t)
+
+		assertStream(t, store, "unknown", nil)
+		if err := store.Append("unknown", 1, events); !errors.Is(err, ddd.ErrConcurrencyConflict) {
+			t.Errorf("Append() at version 1 error = %v, want %v", err, ddd.ErrConcurrencyConflict)
+		}
+		assertStream(t, store, "unknown", nil)
+	})
+}
+
+func appendEvents(
+	t *testing.T,
+	store ddd.EventStore,
+	aggregateID string,
+	expectedVersion int,
+	events []ddd.DomainEvent,
+) {
+	t.Helper()
+
+	if err := store.Append(aggregateID, expectedVersion, events); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+}
+
+func assertStream(t *testing.T, store ddd.EventStore, aggregateID string, want []ddd.DomainEvent) {
+	t.Helper()
+
+	loaded, err := store.Load(aggregateID)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(loaded) != len(want) {
+		t.Fatalf("Load() = %d events, want %d", len(loaded), len(want))
+	}
+	for i := range want {
+		if loaded[i].EventType() != want[i].EventType() || encode(t, loaded[i]) != encode(t, want[i]) {
+			t.Errorf("event %d = %+v, want %+v", i, loaded[i], want[i])
+		}
+	}
+}
+
+func encode(t *testing.T, event ddd.DomainEvent) string {
+	t.Helper()
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,93 @@
+package ddd
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Tracer starts spans, timing a piece of work within the trace of whatever the context
+// is part of
+type Tracer interface {
+	// Start starts a span within the span the context carries, if any, returning a
+	// context carrying the new span
+	Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span)
+}
+
+// Span is a piece of work being timed
+type Span interface {
+	// End ends the span, recording the error the work failed with, if any
+	End(err error)
+}
+
+// LogTracer is a Tracer that logs each span when it ends. A trace is identified by the
+// correlation ID of the event metadata the context carries, so the spans of everything
+// one request caused share it
+type LogTracer struct {
+	logger *zap.SugaredLogger
+}
+
+// NewLogTracer creates a tracer logging to the logger
+func NewLogTracer(logger *zap.SugaredLogger) *LogTracer {
+	return &LogTracer{
+		logger: logger,
+	}
+}
+
+type logSpan struct {
+	logger     *zap.SugaredLogger
+	name       string
+	traceID    string
+	spanID     string
+	parentID   string
+	attributes map[string]string
+	start      time.Time
+}
+
+type logSpanKey struct{}
+
+// Start implements Tracer
+func (t *LogTracer) Start(
+	ctx context.Context,
+	name string,
+	attributes map[string]string,
+) (context.Context, Span) {
+	span := &logSpan{
+		logger:     t.logger,
+		name:       name,
+		spanID:     uuid.NewString(),
+		attributes: attributes,
+		start:      time.Now(),
+	}
+
+	if parent, ok := ctx.Value(logSpanKey{}).(*logSpan); ok {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else if span.traceID = EventMetadataFrom(ctx).CorrelationID; span.traceID == "" {
+		span.traceID = span.spanID
+	}
+
+	return context.WithValue(ctx, logSpanKey{}, span), span
+}
+
+// End implements Span
+func (s *logSpan) End(err error) {
+	fields := []any{
+		"span", s.name,
+		"trace_id", s.traceID,
+		"span_id", s.spanID,
+		"parent_span_id", s.parentID,
+		"duration", time.Since(s.start),
+	}
+	for key, value := range s.attributes {
+		fields = append(fields, key, value)
+	}
+
+	if err != nil {
+		s.logger.Infow("Span failed", append(fields, "error", err)...)
+		return
+	}
+	s.logger.Infow("Span", fields...)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Validatable interface for request DTOs that can be validated
@@ -43,6 +44,18 @@ func (v *Validator) RequiredStringSlice(value []string, fieldName string) *Error
 	return nil
 }
 
+// RequiredTime validates that a time field has been set
+func (v *Validator) RequiredTime(value time.Time, fieldName string) *Error {
+	if value.IsZero() {
+		return &Error{
+			Field:   fieldName,
+			Message: "is required",
+			Code:    "required",
+		}
+	}
+	return nil
+}
+
 // MinLength validates minimum string length
 func (v *Validator) MinLength(value, fieldName string, min int) *Error {
 	if len(strings.TrimSpace(value)) < min {
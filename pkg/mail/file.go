@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer writes emails to a directory as .eml files instead of sending them
+// It's meant for local runs, where the files can be opened in any mail client
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a mailer that writes to the directory, creating it if needed
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+func (m *FileMailer) Send(message Message) error {
+	now := time.Now()
+
+	email, err := compose(m.from, message, now)
+	if err != nil {
+		return err
+	}
+
+	// Name files by time so they list in the order they were sent
+	name := fmt.Sprintf(
+		"%s-%s.eml",
+		now.UTC().Format("20060102T150405.000000000"),
+		safeFileName(message.To),
+	)
+
+	return os.WriteFile(filepath.Join(m.dir, name), email, 0o644)
+}
+
+// safeFileName keeps letters, digits and a few separators, so an address can't point
+// the file outside the directory
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '@', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
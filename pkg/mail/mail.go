@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
+	"time"
+)
+
+// Message is an email with HTML and plain text alternatives of the same content
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+	// Headers are any extra headers, such as List-Unsubscribe
+	Headers map[string]string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(message Message) error
+}
+
+// compose builds the raw multipart/alternative email. Clients show the last
+// alternative they can display, so the plain text part goes first
+func compose(from string, message Message, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	for _, alternative := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	} {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alternative.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := quotedprintable.NewWriter(part)
+		if _, err := encoder.Write([]byte(alternative.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var email bytes.Buffer
+	writeHeader(&email, "From", from)
+	writeHeader(&email, "To", message.To)
+	writeHeader(&email, "Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	writeHeader(&email, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&email, "MIME-Version", "1.0")
+
+	// Sort the extra headers so the same message always composes the same way
+	names := make([]string, 0, len(message.Headers))
+	for name := range message.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeHeader(&email, name, message.Headers[name])
+	}
+
+	writeHeader(
+		&email,
+		"Content-Type",
+		fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary()),
+	)
+	email.WriteString("\r\n")
+	email.Write(body.Bytes())
+
+	return email.Bytes(), nil
+}
+
+func writeHeader(email *bytes.Buffer, name, value string) {
+	fmt.Fprintf(email, "%s: %s\r\n", name, value)
+}
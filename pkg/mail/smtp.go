@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	addr   string
+	from   string
+	sender string
+	auth   smtp.Auth
+}
+
+// NewSMTPMailer creates a mailer for the server, logging in with the username and
+// password unless the username is empty. from may include a name, as in
+// "Blog <noreply@example.com>"
+func NewSMTPMailer(host string, port int, username, password, from string) (*SMTPMailer, error) {
+	// The envelope needs the bare address
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr:   net.JoinHostPort(host, strconv.Itoa(port)),
+		from:   address.String(),
+		sender: address.Address,
+		auth:   auth,
+	}, nil
+}
+
+func (m *SMTPMailer) Send(message Message) error {
+	email, err := compose(m.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, m.sender, []string{message.To}, email)
+}